@@ -6,6 +6,9 @@ module.exports = {
     plugins: [
         "@semantic-release/commit-analyzer",
         "@semantic-release/release-notes-generator",
-        "@semantic-release/github"
+        "@semantic-release/github",
+        ["@semantic-release/exec", {
+            publishCmd: "./build/release/tag-modules.sh ${nextRelease.version}"
+        }]
     ]
 };
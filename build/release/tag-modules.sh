@@ -0,0 +1,37 @@
+#!/bin/sh
+# Tags the nested modules (e.g. pkg/saml) with the version of the released root module.
+# Called by semantic-release after the root module was tagged (see .releaserc.js):
+# the nested modules are pinned to the released root module (and to each other) on a release commit,
+# which is tagged with <dir>/v<version>, e.g. pkg/saml/v3.5.0, as required for modules in subdirectories.
+# The release commit is only referenced by the tags, the main branch keeps the replace directives for development.
+set -eu
+
+if [ -z "${1:-}" ]; then
+    echo "usage: $0 <version>"
+    exit 3
+fi
+VERSION=v$1
+ROOT=github.com/zitadel/zitadel-go
+
+MODULES=$(find pkg cmd -mindepth 2 -name go.mod -exec dirname {} \; | sort)
+
+for dir in $MODULES; do
+    (
+        cd "$dir"
+        go mod edit -require="$ROOT/v3@$VERSION"
+        for dep in $MODULES; do
+            if grep -q "^	$ROOT/$dep/v3 " go.mod; then
+                go mod edit -require="$ROOT/$dep/v3@$VERSION"
+            fi
+        done
+    )
+done
+
+git config user.name "${GIT_AUTHOR_NAME:-semantic-release-bot}"
+git config user.email "${GIT_AUTHOR_EMAIL:-semantic-release-bot@martynus.net}"
+git commit -m "chore(release): pin nested modules to $VERSION [skip ci]" -- $(echo "$MODULES" | sed 's|$|/go.mod|')
+for dir in $MODULES; do
+    git tag "$dir/$VERSION"
+    git push origin "$dir/$VERSION"
+done
+git checkout -- .
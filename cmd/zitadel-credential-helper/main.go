@@ -0,0 +1,124 @@
+// Command zitadel-credential-helper implements the credential helper protocol of the credhelper package.
+// Tools execute it to obtain ZITADEL access tokens of a service user:
+//
+//	echo '{"issuer":"https://my-instance.zitadel.cloud"}' | zitadel-credential-helper get
+//
+// The helper only issues tokens of the configured issuer, requests for any other issuer are rejected.
+// The issuer and credentials are configured by environment variables (or the corresponding flags before the action):
+//
+//	ZITADEL_ISSUER            issuer of the tokens, e.g. https://my-instance.zitadel.cloud (-issuer),
+//	                          defaults to the issuer of the key file
+//	ZITADEL_KEY_FILE          path to the key.json of the service user (-key)
+//	ZITADEL_CLIENT_ID         client_id for the client credentials grant (-client-id)
+//	ZITADEL_CLIENT_SECRET     client_secret for the client credentials grant (-client-secret)
+//	ZITADEL_CREDENTIAL_CACHE  cache of the tokens: `keyring` (default), `file` or `none` (-cache)
+//	ZITADEL_CREDENTIAL_DIR    directory of the `file` cache (-cache-dir)
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+
+	oidcclient "github.com/zitadel/oidc/v3/pkg/client"
+	"github.com/zitadel/oidc/v3/pkg/oidc"
+
+	"github.com/zitadel/zitadel-go/pkg/credhelper/keyring/v3"
+	"github.com/zitadel/zitadel-go/v3/pkg/client"
+	"github.com/zitadel/zitadel-go/v3/pkg/credhelper"
+)
+
+var (
+	issuer       = flag.String("issuer", os.Getenv("ZITADEL_ISSUER"), "issuer of the tokens (default: issuer of the key file)")
+	keyPath      = flag.String("key", os.Getenv("ZITADEL_KEY_FILE"), "path to the key.json of the service user")
+	clientID     = flag.String("client-id", os.Getenv("ZITADEL_CLIENT_ID"), "client_id of the service user")
+	clientSecret = flag.String("client-secret", os.Getenv("ZITADEL_CLIENT_SECRET"), "client_secret of the service user")
+	cache        = flag.String("cache", envOrDefault("ZITADEL_CREDENTIAL_CACHE", "keyring"), "cache of the tokens: keyring, file or none")
+	cacheDir     = flag.String("cache-dir", os.Getenv("ZITADEL_CREDENTIAL_DIR"), "directory of the file cache (default: user cache dir)")
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags] get|erase < request.json\n", filepath.Base(os.Args[0]))
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	if err := run(ctx); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context) error {
+	authenticator, identity, keyIssuer, err := authentication()
+	if err != nil {
+		return err
+	}
+	pinned := *issuer
+	if pinned == "" {
+		pinned = keyIssuer
+	}
+	if pinned == "" {
+		return errors.New("no issuer configured, set ZITADEL_ISSUER")
+	}
+	var opts []credhelper.HelperOption
+	switch *cache {
+	case "keyring":
+		opts = append(opts, credhelper.WithCache(keyring.NewTokenCache("")))
+	case "file":
+		dir := *cacheDir
+		if dir == "" {
+			userDir, err := os.UserCacheDir()
+			if err != nil {
+				return err
+			}
+			dir = filepath.Join(userDir, "zitadel", "tokens")
+		}
+		fileCache, err := client.NewFileTokenCache(dir)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, credhelper.WithCache(fileCache))
+	case "none":
+	default:
+		return fmt.Errorf("unknown cache %q", *cache)
+	}
+	return credhelper.NewHelper(authenticator, identity, pinned, opts...).Run(ctx, flag.Args(), os.Stdin, os.Stdout)
+}
+
+// authentication returns the configured credentials, their identity used as cache key
+// and the issuer of the key file (if any).
+// The scopes `openid` and the ZITADEL API audience are always requested.
+func authentication() (_ credhelper.Authenticator, identity, issuer string, _ error) {
+	switch {
+	case *keyPath != "":
+		file, err := oidcclient.ConfigFromKeyFile(*keyPath)
+		if err != nil {
+			return nil, "", "", err
+		}
+		return func(scopes ...string) client.TokenSourceInitializer {
+			return client.JWTAuthentication(file, append([]string{oidc.ScopeOpenID, client.ScopeZitadelAPI()}, scopes...)...)
+		}, file.UserID, file.Issuer, nil
+	case *clientID != "":
+		return func(scopes ...string) client.TokenSourceInitializer {
+			return client.ClientCredentialsAuthentication(*clientID, *clientSecret, scopes...)
+		}, *clientID, "", nil
+	default:
+		return nil, "", "", errors.New("no credentials configured, set ZITADEL_KEY_FILE or ZITADEL_CLIENT_ID and ZITADEL_CLIENT_SECRET")
+	}
+}
+
+func envOrDefault(key, def string) string {
+	if value, ok := os.LookupEnv(key); ok {
+		return value
+	}
+	return def
+}
@@ -0,0 +1,140 @@
+// Command zitadel-seed provisions a complete demo environment against a ZITADEL instance from one YAML file,
+// so new developers get a working setup in minutes:
+//
+//	zitadel-seed -domain my-instance.zitadel.cloud -file seed.yaml
+//
+// The file extends the description of the provision package (organizations, projects, roles, applications and
+// identity providers) by human users with their project roles and actions (targets and executions),
+// see seed.example.yaml. Environment variables (e.g. `${SEED_PASSWORD}`) are expanded.
+// Seeding is idempotent: existing resources are matched by their name and updated, existing users are kept.
+//
+// The instance and credentials are configured by environment variables (or the corresponding flags):
+//
+//	ZITADEL_DOMAIN    domain of the instance (-domain)
+//	ZITADEL_INSECURE  port of an instance without TLS, e.g. `8080` for a local instance (-insecure)
+//	ZITADEL_KEY_FILE  path to the key.json of a service user with the IAM_OWNER role (-key)
+//	ZITADEL_PAT       personal access token of a service user with the IAM_OWNER role (-pat)
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+
+	oidcclient "github.com/zitadel/oidc/v3/pkg/client"
+	"github.com/zitadel/oidc/v3/pkg/oidc"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client"
+	"github.com/zitadel/zitadel-go/v3/pkg/zitadel"
+)
+
+func main() {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	err := newCLI(os.Stdout, os.Stderr, os.Getenv).run(ctx, os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// cli runs the command with its arguments, writing the applied changes to stdout
+// and taking the defaults of the flags from the environment.
+type cli struct {
+	stdout io.Writer
+	stderr io.Writer
+	getenv func(string) string
+	// clientOptions are passed to the client in addition to the credentials
+	clientOptions []client.Option
+}
+
+func newCLI(stdout, stderr io.Writer, getenv func(string) string) *cli {
+	return &cli{stdout: stdout, stderr: stderr, getenv: getenv}
+}
+
+// options are the flags of the command.
+type options struct {
+	file     string
+	domain   string
+	insecure string
+	keyPath  string
+	pat      string
+}
+
+// parse parses the flags, usage and parse errors are written to stderr and returned as [flag.ErrHelp].
+func (c *cli) parse(args []string) (*options, error) {
+	fs := flag.NewFlagSet("zitadel-seed", flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: %s [flags]\n", filepath.Base(os.Args[0]))
+		fs.PrintDefaults()
+	}
+	opts := new(options)
+	fs.StringVar(&opts.file, "file", "seed.yaml", "path to the seed file")
+	fs.StringVar(&opts.domain, "domain", c.getenv("ZITADEL_DOMAIN"), "domain of the ZITADEL instance")
+	fs.StringVar(&opts.insecure, "insecure", c.getenv("ZITADEL_INSECURE"), "port of an instance without TLS (e.g. 8080)")
+	fs.StringVar(&opts.keyPath, "key", c.getenv("ZITADEL_KEY_FILE"), "path to the key.json of the service user")
+	fs.StringVar(&opts.pat, "pat", c.getenv("ZITADEL_PAT"), "personal access token of the service user")
+	if err := fs.Parse(args); err != nil {
+		return nil, flag.ErrHelp
+	}
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return nil, flag.ErrHelp
+	}
+	return opts, nil
+}
+
+func (c *cli) run(ctx context.Context, args []string) error {
+	opts, err := c.parse(args)
+	if err != nil {
+		return err
+	}
+	if opts.domain == "" {
+		return errors.New("no instance configured, set ZITADEL_DOMAIN")
+	}
+	seed, err := loadFile(opts.file, c.getenv)
+	if err != nil {
+		return err
+	}
+	auth, err := authentication(opts)
+	if err != nil {
+		return err
+	}
+	var zitadelOpts []zitadel.Option
+	if opts.insecure != "" {
+		zitadelOpts = append(zitadelOpts, zitadel.WithInsecure(opts.insecure))
+	}
+	z := zitadel.New(opts.domain, zitadelOpts...)
+	zc, err := client.New(ctx, z, append([]client.Option{client.WithAuth(auth)}, c.clientOptions...)...)
+	if err != nil {
+		return err
+	}
+	defer zc.Close()
+	return newSeeder(zc, c.stdout).seed(ctx, seed)
+}
+
+// authentication returns the configured credentials of the service user.
+func authentication(opts *options) (client.TokenSourceInitializer, error) {
+	switch {
+	case opts.keyPath != "":
+		keyFile, err := oidcclient.ConfigFromKeyFile(opts.keyPath)
+		if err != nil {
+			return nil, err
+		}
+		return client.JWTAuthentication(keyFile, oidc.ScopeOpenID, client.ScopeZitadelAPI()), nil
+	case opts.pat != "":
+		return client.PAT(opts.pat), nil
+	default:
+		return nil, errors.New("no credentials configured, set ZITADEL_KEY_FILE or ZITADEL_PAT")
+	}
+}
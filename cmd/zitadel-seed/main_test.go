@@ -0,0 +1,259 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client"
+)
+
+// instance is a stateful fake of the APIs used by the seeder, serving the organization `ACME`
+// with the project `portal`.
+type instance struct {
+	t  *testing.T
+	mu sync.Mutex
+	// users are the IDs of the users by username
+	users map[string]string
+	// grants are the role keys of the user grants by user ID
+	grants map[string][]string
+	// targets are the configurations of the targets by ID
+	targets    map[string]json.RawMessage
+	executions int
+}
+
+func newInstance(t *testing.T) *instance {
+	return &instance{
+		t:       t,
+		users:   make(map[string]string),
+		grants:  make(map[string][]string),
+		targets: make(map[string]json.RawMessage),
+	}
+}
+
+func (i *instance) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	var req map[string]any
+	if r.Body != nil && r.ContentLength != 0 {
+		require.NoError(i.t, json.NewDecoder(r.Body).Decode(&req))
+	}
+	var resp any = map[string]any{}
+	switch path := r.URL.Path; {
+	case path == "/v2/organizations/_search":
+		resp = map[string]any{"result": []any{map[string]any{"id": "org1", "name": "ACME"}}}
+	case path == "/management/v1/idps/templates/_search":
+	case path == "/management/v1/projects/_search":
+		resp = map[string]any{"result": []any{map[string]any{"id": "project1", "name": "portal"}}}
+	case path == "/v2/users":
+		var result []any
+		for username, id := range i.users {
+			if strings.Contains(fmt.Sprint(req["queries"]), "userName:"+username) {
+				result = append(result, map[string]any{"userId": id, "username": username})
+			}
+		}
+		resp = map[string]any{"result": result}
+	case path == "/v2/users/human":
+		id := fmt.Sprintf("user%d", len(i.users)+1)
+		i.users[req["username"].(string)] = id
+		resp = map[string]any{"userId": id}
+	case path == "/management/v1/users/grants/_search":
+		var result []any
+		for userID, roles := range i.grants {
+			if strings.Contains(fmt.Sprint(req["queries"]), "userId:"+userID) {
+				result = append(result, map[string]any{"id": "grant-" + userID, "userId": userID, "projectId": "project1", "roleKeys": roles})
+			}
+		}
+		resp = map[string]any{"result": result}
+	case strings.HasPrefix(path, "/management/v1/users/") && strings.Contains(path, "/grants"):
+		userID := strings.Split(path, "/")[4]
+		i.grants[userID] = stringSlice(req["roleKeys"])
+	case path == "/resources/v3alpha/actions/targets/_search":
+		var result []any
+		for id, config := range i.targets {
+			result = append(result, map[string]any{"details": map[string]any{"id": id}, "config": config})
+		}
+		resp = map[string]any{"result": result}
+	case path == "/resources/v3alpha/actions/targets":
+		id := fmt.Sprintf("target%d", len(i.targets)+1)
+		i.targets[id] = marshal(i.t, req)
+		resp = map[string]any{"details": map[string]any{"id": id}}
+	case strings.HasPrefix(path, "/resources/v3alpha/actions/targets/"):
+		i.targets[strings.TrimPrefix(path, "/resources/v3alpha/actions/targets/")] = marshal(i.t, req)
+	case path == "/resources/v3alpha/actions/executions":
+		i.executions++
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	w.Write(marshal(i.t, resp))
+}
+
+func marshal(t *testing.T, v any) json.RawMessage {
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	return data
+}
+
+func stringSlice(v any) []string {
+	var s []string
+	for _, e := range v.([]any) {
+		s = append(s, e.(string))
+	}
+	return s
+}
+
+// newTestCLI returns a [cli] seeding the instance, the domain and credentials are taken from the environment.
+func newTestCLI(t *testing.T, i *instance, env map[string]string) (*cli, *bytes.Buffer) {
+	server := httptest.NewServer(i)
+	t.Cleanup(server.Close)
+	host, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	require.NoError(t, err)
+	if env == nil {
+		env = map[string]string{"ZITADEL_DOMAIN": host, "ZITADEL_INSECURE": port, "ZITADEL_PAT": "token"}
+	}
+	out := new(bytes.Buffer)
+	c := newCLI(out, out, func(key string) string { return env[key] })
+	c.clientOptions = []client.Option{client.WithTransport(client.TransportREST)}
+	return c, out
+}
+
+func TestCLI_parse(t *testing.T) {
+	env := map[string]string{"ZITADEL_DOMAIN": "env.example.com", "ZITADEL_PAT": "env-token"}
+	tests := []struct {
+		name    string
+		args    []string
+		want    *options
+		wantErr error
+	}{
+		{
+			name: "environment",
+			want: &options{file: "seed.yaml", domain: "env.example.com", pat: "env-token"},
+		},
+		{
+			name: "flags",
+			args: []string{"-file", "demo.yaml", "-domain", "flag.example.com", "-insecure", "8080", "-key", "key.json"},
+			want: &options{file: "demo.yaml", domain: "flag.example.com", insecure: "8080", keyPath: "key.json", pat: "env-token"},
+		},
+		{
+			name:    "unknown flag",
+			args:    []string{"-unknown"},
+			wantErr: flag.ErrHelp,
+		},
+		{
+			name:    "argument",
+			args:    []string{"seed.yaml"},
+			wantErr: flag.ErrHelp,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stderr := new(bytes.Buffer)
+			got, err := newCLI(new(bytes.Buffer), stderr, func(key string) string { return env[key] }).parse(tt.args)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Contains(t, stderr.String(), "usage:")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCLI_run(t *testing.T) {
+	file := writeFile(t, `
+organizations:
+  - name: ACME
+users:
+  - organization: ACME
+    username: alice
+    email: alice@example.com
+    password: ${SEED_PASSWORD}
+    grants:
+      - project: portal
+        roles: [admin]
+actions:
+  - name: audit
+    endpoint: https://example.com/hook
+    conditions: [event:user.human.*]
+`)
+	i := newInstance(t)
+	c, out := newTestCLI(t, i, nil)
+
+	require.NoError(t, c.run(context.Background(), []string{"-file", file}))
+	assert.Equal(t, `create user ACME/alice
+create grant ACME/alice/portal (admin)
+create target audit
+set execution event:user.human.* -> audit
+`, out.String())
+
+	// re-applying the same file only sets the executions again
+	out.Reset()
+	require.NoError(t, c.run(context.Background(), []string{"-file", file}))
+	assert.Equal(t, "set execution event:user.human.* -> audit\n", out.String())
+	assert.Len(t, i.users, 1)
+	assert.Equal(t, map[string][]string{"user1": {"admin"}}, i.grants)
+	assert.Len(t, i.targets, 1)
+	assert.Equal(t, 2, i.executions)
+
+	// changed roles and targets are updated
+	out.Reset()
+	file = writeFile(t, `
+users:
+  - organization: ACME
+    username: alice
+    email: alice@example.com
+    grants:
+      - project: portal
+        roles: [admin, viewer]
+actions:
+  - name: audit
+    endpoint: https://example.com/hook
+    timeout: 5s
+`)
+	require.NoError(t, c.run(context.Background(), []string{"-file", file}))
+	assert.Equal(t, `update grant ACME/alice/portal (admin, viewer)
+update target audit
+`, out.String())
+	assert.Len(t, i.users, 1)
+	assert.Equal(t, map[string][]string{"user1": {"admin", "viewer"}}, i.grants)
+	assert.Len(t, i.targets, 1)
+}
+
+func TestCLI_run_configuration(t *testing.T) {
+	file := writeFile(t, "organizations: []")
+	tests := []struct {
+		name    string
+		env     map[string]string
+		wantErr string
+	}{
+		{
+			name:    "no instance",
+			env:     map[string]string{"ZITADEL_PAT": "token"},
+			wantErr: "no instance configured, set ZITADEL_DOMAIN",
+		},
+		{
+			name:    "no credentials",
+			env:     map[string]string{"ZITADEL_DOMAIN": "zitadel.example.com"},
+			wantErr: "no credentials configured, set ZITADEL_KEY_FILE or ZITADEL_PAT",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := newTestCLI(t, newInstance(t), tt.env)
+			assert.EqualError(t, c.run(context.Background(), []string{"-file", file}), tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,352 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/actions"
+	"github.com/zitadel/zitadel-go/v3/pkg/client"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/management"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/object"
+	objectV2 "github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/object/v2"
+	orgV2 "github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/org/v2"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/project"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/user"
+	"github.com/zitadel/zitadel-go/v3/pkg/management/users"
+	"github.com/zitadel/zitadel-go/v3/pkg/provision"
+)
+
+// Seed is the content of the seed file.
+type Seed struct {
+	provision.Spec `yaml:",inline"`
+	Users          []User   `yaml:"users,omitempty"`
+	Actions        []Action `yaml:"actions,omitempty"`
+}
+
+// User is a human user, which is created if there is no user with the username in the organization.
+type User struct {
+	Organization string  `yaml:"organization"`
+	Username     string  `yaml:"username"`
+	Email        string  `yaml:"email"`
+	GivenName    string  `yaml:"givenName"`
+	FamilyName   string  `yaml:"familyName"`
+	Password     string  `yaml:"password"`
+	Grants       []Grant `yaml:"grants,omitempty"`
+}
+
+// Grant are the roles of a [User] on a project of the organization of the user.
+type Grant struct {
+	Project string   `yaml:"project"`
+	Roles   []string `yaml:"roles"`
+}
+
+// Action is a target, which is called by ZITADEL for the conditions.
+type Action struct {
+	Name     string `yaml:"name"`
+	Endpoint string `yaml:"endpoint"`
+	// Type is `webhook` (default), `call` or `async`.
+	Type             string        `yaml:"type,omitempty"`
+	Timeout          time.Duration `yaml:"timeout,omitempty"`
+	InterruptOnError bool          `yaml:"interruptOnError,omitempty"`
+	// Conditions are `request`, `response` (all methods), `request:/zitadel.user.v2.UserService/AddHumanUser` (a method),
+	// `request:zitadel.user.v2.UserService` (a service), `function:preaccesstoken`, `event` (all events),
+	// `event:user.human.added` (an event type) or `event:user.human.*` (an event group).
+	Conditions []string `yaml:"conditions"`
+}
+
+// loadFile reads and validates the seed file, environment variables are expanded using getenv.
+func loadFile(path string, getenv func(string) string) (*Seed, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	seed := new(Seed)
+	if err = yaml.Unmarshal([]byte(os.Expand(string(data), getenv)), seed); err != nil {
+		return nil, err
+	}
+	if err = seed.Validate(); err != nil {
+		return nil, err
+	}
+	for _, u := range seed.Users {
+		if u.Organization == "" || u.Username == "" || u.Email == "" {
+			return nil, fmt.Errorf("user %q: organization, username and email are required", u.Username)
+		}
+	}
+	for _, a := range seed.Actions {
+		if _, err = targetType(a.Type); err != nil {
+			return nil, fmt.Errorf("action %q: %w", a.Name, err)
+		}
+		for _, condition := range a.Conditions {
+			if _, err = parseCondition(condition); err != nil {
+				return nil, fmt.Errorf("action %q: %w", a.Name, err)
+			}
+		}
+	}
+	return seed, nil
+}
+
+type seeder struct {
+	client *client.Client
+	out    io.Writer
+}
+
+func newSeeder(c *client.Client, out io.Writer) *seeder {
+	return &seeder{client: c, out: out}
+}
+
+func (s *seeder) seed(ctx context.Context, seed *Seed) error {
+	result, err := provision.New(s.client).Reconcile(ctx, &seed.Spec)
+	if result != nil {
+		for _, change := range result.Applied {
+			fmt.Fprintln(s.out, change)
+		}
+		for _, credential := range result.Credentials {
+			fmt.Fprintf(s.out, "credentials of %s: client_id=%s client_secret=%s\n", credential.Path, credential.ClientID, credential.ClientSecret)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	for _, u := range seed.Users {
+		if err = s.seedUser(ctx, u); err != nil {
+			return fmt.Errorf("user %s: %w", u.Username, err)
+		}
+	}
+	if len(seed.Actions) == 0 {
+		return nil
+	}
+	a := actions.New(s.client)
+	for _, action := range seed.Actions {
+		if err = s.seedAction(ctx, a, action); err != nil {
+			return fmt.Errorf("action %s: %w", action.Name, err)
+		}
+	}
+	return nil
+}
+
+func (s *seeder) seedUser(ctx context.Context, u User) error {
+	orgID, err := s.findOrganization(ctx, u.Organization)
+	if err != nil {
+		return err
+	}
+	existing, err := users.New(s.client).ListUsers(ctx, users.InOrganization(orgID), users.WithUsername(u.Username), users.WithLimit(1))
+	if err != nil {
+		return err
+	}
+	var userID string
+	if len(existing) > 0 {
+		userID = existing[0].ID
+	} else {
+		var opts []users.Option
+		if u.Password != "" {
+			opts = append(opts, users.WithPassword(u.Password, false))
+		}
+		created, err := users.New(s.client).CreateHumanUser(ctx, users.HumanUser{
+			OrganizationID: orgID,
+			Username:       u.Username,
+			Email:          u.Email,
+			GivenName:      u.GivenName,
+			FamilyName:     u.FamilyName,
+		}, append(opts, users.WithVerifiedEmail())...)
+		if err != nil {
+			return err
+		}
+		userID = created.UserID
+		fmt.Fprintf(s.out, "create user %s/%s\n", u.Organization, u.Username)
+	}
+	mgmt := s.client.ForOrganization(orgID).ManagementService()
+	for _, grant := range u.Grants {
+		if err = s.seedGrant(ctx, mgmt, u, userID, grant); err != nil {
+			return fmt.Errorf("grant %s: %w", grant.Project, err)
+		}
+	}
+	return nil
+}
+
+func (s *seeder) seedGrant(ctx context.Context, mgmt management.ManagementServiceClient, u User, userID string, grant Grant) error {
+	projectID, err := findProject(ctx, mgmt, grant.Project)
+	if err != nil {
+		return err
+	}
+	resp, err := mgmt.ListUserGrants(ctx, &management.ListUserGrantRequest{
+		Queries: []*user.UserGrantQuery{
+			{Query: &user.UserGrantQuery_UserIdQuery{UserIdQuery: &user.UserGrantUserIDQuery{UserId: userID}}},
+			{Query: &user.UserGrantQuery_ProjectIdQuery{ProjectIdQuery: &user.UserGrantProjectIDQuery{ProjectId: projectID}}},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	path := u.Organization + "/" + u.Username + "/" + grant.Project
+	for _, existing := range resp.GetResult() {
+		if existing.GetProjectGrantId() != "" {
+			continue
+		}
+		if equalUnordered(existing.GetRoleKeys(), grant.Roles) {
+			return nil
+		}
+		_, err = mgmt.UpdateUserGrant(ctx, &management.UpdateUserGrantRequest{
+			UserId:   userID,
+			GrantId:  existing.GetId(),
+			RoleKeys: grant.Roles,
+		})
+		if err == nil {
+			fmt.Fprintf(s.out, "update grant %s (%s)\n", path, strings.Join(grant.Roles, ", "))
+		}
+		return err
+	}
+	_, err = mgmt.AddUserGrant(ctx, &management.AddUserGrantRequest{
+		UserId:    userID,
+		ProjectId: projectID,
+		RoleKeys:  grant.Roles,
+	})
+	if err == nil {
+		fmt.Fprintf(s.out, "create grant %s (%s)\n", path, strings.Join(grant.Roles, ", "))
+	}
+	return err
+}
+
+func (s *seeder) seedAction(ctx context.Context, a *actions.Client, action Action) error {
+	typ, err := targetType(action.Type)
+	if err != nil {
+		return err
+	}
+	target := &actions.Target{
+		Name:             action.Name,
+		Endpoint:         action.Endpoint,
+		Type:             typ,
+		InterruptOnError: action.InterruptOnError,
+		Timeout:          action.Timeout,
+	}
+	if target.Timeout == 0 {
+		target.Timeout = 10 * time.Second
+	}
+	targets, err := a.ListTargets(ctx)
+	if err != nil {
+		return err
+	}
+	idx := slices.IndexFunc(targets, func(t *actions.Target) bool { return t.Name == action.Name })
+	if idx < 0 {
+		if target.ID, err = a.CreateTarget(ctx, target); err != nil {
+			return err
+		}
+		fmt.Fprintf(s.out, "create target %s\n", action.Name)
+	} else if target.ID = targets[idx].ID; *target != *targets[idx] {
+		if err = a.UpdateTarget(ctx, target); err != nil {
+			return err
+		}
+		fmt.Fprintf(s.out, "update target %s\n", action.Name)
+	}
+	for _, c := range action.Conditions {
+		condition, err := parseCondition(c)
+		if err != nil {
+			return err
+		}
+		if err = a.SetExecution(ctx, condition, actions.Targets(target.ID)...); err != nil {
+			return fmt.Errorf("execution %s: %w", c, err)
+		}
+		fmt.Fprintf(s.out, "set execution %s -> %s\n", c, action.Name)
+	}
+	return nil
+}
+
+// findOrganization returns the ID of the organization with the name.
+func (s *seeder) findOrganization(ctx context.Context, name string) (string, error) {
+	resp, err := s.client.OrganizationServiceV2().ListOrganizations(ctx, &orgV2.ListOrganizationsRequest{
+		Queries: []*orgV2.SearchQuery{{
+			Query: &orgV2.SearchQuery_NameQuery{NameQuery: &orgV2.OrganizationNameQuery{
+				Name:   name,
+				Method: objectV2.TextQueryMethod_TEXT_QUERY_METHOD_EQUALS,
+			}},
+		}},
+	})
+	if err != nil {
+		return "", err
+	}
+	for _, org := range resp.GetResult() {
+		if org.GetName() == name {
+			return org.GetId(), nil
+		}
+	}
+	return "", fmt.Errorf("organization %s not found", name)
+}
+
+// findProject returns the ID of the project with the name in the organization of mgmt.
+func findProject(ctx context.Context, mgmt management.ManagementServiceClient, name string) (string, error) {
+	resp, err := mgmt.ListProjects(ctx, &management.ListProjectsRequest{
+		Queries: []*project.ProjectQuery{{
+			Query: &project.ProjectQuery_NameQuery{NameQuery: &project.ProjectNameQuery{
+				Name:   name,
+				Method: object.TextQueryMethod_TEXT_QUERY_METHOD_EQUALS,
+			}},
+		}},
+	})
+	if err != nil {
+		return "", err
+	}
+	for _, p := range resp.GetResult() {
+		if p.GetName() == name {
+			return p.GetId(), nil
+		}
+	}
+	return "", fmt.Errorf("project %s not found", name)
+}
+
+func targetType(typ string) (actions.TargetType, error) {
+	switch typ {
+	case "", "webhook":
+		return actions.TargetTypeWebhook, nil
+	case "call":
+		return actions.TargetTypeCall, nil
+	case "async":
+		return actions.TargetTypeAsync, nil
+	default:
+		return 0, fmt.Errorf("unknown target type %q", typ)
+	}
+}
+
+// parseCondition parses the conditions described by [Action.Conditions].
+func parseCondition(condition string) (actions.Condition, error) {
+	kind, value, _ := strings.Cut(condition, ":")
+	switch kind {
+	case "request", "response":
+		all, method, service := actions.AllRequests, actions.Request, actions.RequestService
+		if kind == "response" {
+			all, method, service = actions.AllResponses, actions.Response, actions.ResponseService
+		}
+		switch {
+		case value == "":
+			return all(), nil
+		case strings.HasPrefix(value, "/"):
+			return method(value), nil
+		default:
+			return service(value), nil
+		}
+	case "function":
+		if value == "" {
+			break
+		}
+		return actions.Function(value), nil
+	case "event":
+		if value == "" {
+			return actions.AllEvents(), nil
+		}
+		if group, ok := strings.CutSuffix(value, ".*"); ok {
+			return actions.EventGroup(group), nil
+		}
+		return actions.Event(value), nil
+	}
+	return actions.Condition{}, errors.New("invalid condition " + condition)
+}
+
+func equalUnordered(a, b []string) bool {
+	return len(a) == len(b) && !slices.ContainsFunc(a, func(s string) bool { return !slices.Contains(b, s) })
+}
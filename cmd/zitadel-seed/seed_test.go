@@ -0,0 +1,124 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/actions"
+)
+
+// writeFile writes the seed file into a temporary directory and returns its path.
+func writeFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "seed.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoadFile(t *testing.T) {
+	env := map[string]string{"SEED_PASSWORD": "Password1!"}
+	getenv := func(key string) string { return env[key] }
+
+	t.Run("example", func(t *testing.T) {
+		seed, err := loadFile("seed.example.yaml", getenv)
+		require.NoError(t, err)
+		require.Len(t, seed.Organizations, 1)
+		assert.Equal(t, "ACME", seed.Organizations[0].Name)
+		require.Len(t, seed.Users, 3)
+		assert.Equal(t, User{
+			Organization: "ACME",
+			Username:     "alice",
+			Email:        "alice@acme.example.com",
+			GivenName:    "Alice",
+			FamilyName:   "Admin",
+			Password:     "Password1!",
+			Grants:       []Grant{{Project: "portal", Roles: []string{"admin"}}},
+		}, seed.Users[0])
+		require.Len(t, seed.Actions, 1)
+		assert.Equal(t, "user-audit", seed.Actions[0].Name)
+	})
+
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{
+			name:    "invalid yaml",
+			content: "users: {",
+			wantErr: "yaml",
+		},
+		{
+			name: "duplicate organization",
+			content: `
+organizations:
+  - name: ACME
+  - name: ACME
+`,
+			wantErr: "ACME",
+		},
+		{
+			name: "user without email",
+			content: `
+users:
+  - organization: ACME
+    username: alice
+`,
+			wantErr: `user "alice": organization, username and email are required`,
+		},
+		{
+			name: "unknown target type",
+			content: `
+actions:
+  - name: audit
+    endpoint: https://example.com
+    type: grpc
+`,
+			wantErr: `action "audit": unknown target type "grpc"`,
+		},
+		{
+			name: "invalid condition",
+			content: `
+actions:
+  - name: audit
+    endpoint: https://example.com
+    conditions: [function]
+`,
+			wantErr: `action "audit": invalid condition function`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadFile(writeFile(t, tt.content), getenv)
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestParseCondition(t *testing.T) {
+	tests := []struct {
+		condition string
+		want      actions.Condition
+	}{
+		{"request", actions.AllRequests()},
+		{"request:/zitadel.user.v2.UserService/AddHumanUser", actions.Request("/zitadel.user.v2.UserService/AddHumanUser")},
+		{"request:zitadel.user.v2.UserService", actions.RequestService("zitadel.user.v2.UserService")},
+		{"response", actions.AllResponses()},
+		{"response:/zitadel.user.v2.UserService/AddHumanUser", actions.Response("/zitadel.user.v2.UserService/AddHumanUser")},
+		{"response:zitadel.user.v2.UserService", actions.ResponseService("zitadel.user.v2.UserService")},
+		{"function:preaccesstoken", actions.Function("preaccesstoken")},
+		{"event", actions.AllEvents()},
+		{"event:user.human.added", actions.Event("user.human.added")},
+		{"event:user.human.*", actions.EventGroup("user.human")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.condition, func(t *testing.T) {
+			got, err := parseCondition(tt.condition)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
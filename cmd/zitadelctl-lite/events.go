@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/events"
+)
+
+func (c *cli) eventsCommand(ctx context.Context, args []string) error {
+	return c.subcommand(ctx, "events", map[string]command{
+		"tail": c.tailEvents,
+	}, args)
+}
+
+// tailEvents prints the events as they occur until interrupted,
+// as JSON lines or table rows.
+func (c *cli) tailEvents(ctx context.Context, args []string) error {
+	fs := c.flagSet("events tail", "")
+	var filter events.Filter
+	aggregateTypes, eventTypes := new(stringList), new(stringList)
+	fs.Var(aggregateTypes, "aggregate-type", "type of the aggregates, e.g. user, can be repeated")
+	fs.Var(eventTypes, "type", "type of the events, e.g. user.human.added, can be repeated")
+	fs.StringVar(&filter.AggregateID, "aggregate", "", "ID of the aggregate, e.g. of a user")
+	fs.StringVar(&filter.ResourceOwner, "org", "", "ID of the organization owning the aggregates")
+	fs.StringVar(&filter.EditorUserID, "editor", "", "ID of the user who caused the events")
+	since := fs.Duration("since", 0, "also print the events of the past duration, e.g. 1h")
+	interval := fs.Duration("interval", 2*time.Second, "polling interval")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if c.format != "json" && c.format != "table" {
+		return fmt.Errorf("unknown output format %q", c.format)
+	}
+	filter.AggregateTypes, filter.EventTypes = *aggregateTypes, *eventTypes
+
+	zc, err := c.newClient(ctx)
+	if err != nil {
+		return err
+	}
+	defer zc.Close()
+	subscriber := events.New(zc,
+		events.WithFrom(time.Now().Add(-*since)),
+		events.WithInterval(*interval),
+		events.WithErrorHandler(func(err error) { fmt.Fprintln(c.stderr, err) }),
+	)
+	stream, err := subscriber.Subscribe(ctx, filter)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(c.stdout)
+	// the rows are printed as the events arrive, so the columns have a fixed width
+	const row = "%-25s  %-32s  %8s  %-40s  %s\n"
+	if c.format == "table" {
+		fmt.Fprintf(c.stdout, row, "CREATED", "AGGREGATE", "SEQUENCE", "TYPE", "EDITOR")
+	}
+	for e := range stream {
+		if c.format == "json" {
+			if err = enc.Encode(e); err != nil {
+				return err
+			}
+			continue
+		}
+		fmt.Fprintf(c.stdout, row, e.CreationDate.Format(time.RFC3339), e.AggregateType+":"+e.AggregateID,
+			strconv.FormatUint(e.Sequence, 10), e.Type, e.EditorName)
+	}
+	return nil
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	oidcclient "github.com/zitadel/oidc/v3/pkg/client"
+	"github.com/zitadel/oidc/v3/pkg/client/rp"
+	"github.com/zitadel/oidc/v3/pkg/oidc"
+	"golang.org/x/oauth2"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/auth"
+	"github.com/zitadel/zitadel-go/v3/pkg/zitadel"
+)
+
+// config is the instance and credentials stored by the login.
+// Only one of the credentials is set: the path of a key file, a personal access token
+// or the client ID of the device authorization, whose tokens are stored in the token cache.
+type config struct {
+	Domain   string `json:"domain"`
+	Insecure string `json:"insecure,omitempty"`
+	KeyFile  string `json:"keyFile,omitempty"`
+	PAT      string `json:"pat,omitempty"`
+	ClientID string `json:"clientId,omitempty"`
+}
+
+// configDir returns the directory of the config and token cache.
+func configDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "zitadelctl-lite"), nil
+}
+
+// loadConfig reads the stored config and applies the flags and environment variables,
+// which take precedence. The config is empty if there was no login.
+func (c *cli) loadConfig() (*config, error) {
+	cfg := new(config)
+	dir, err := configDir()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "config.json"))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if err == nil {
+		if err = json.Unmarshal(data, cfg); err != nil {
+			return nil, fmt.Errorf("invalid config %s: %w", filepath.Join(dir, "config.json"), err)
+		}
+	}
+	if c.domain != "" {
+		cfg.Domain, cfg.Insecure = c.domain, c.insecure
+	}
+	if c.keyPath != "" || c.pat != "" {
+		cfg.KeyFile, cfg.PAT, cfg.ClientID = c.keyPath, c.pat, ""
+	}
+	return cfg, nil
+}
+
+// save stores the config readable by the user only, as it may contain a personal access token.
+func (c *config) save() error {
+	dir, err := configDir()
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, "config.json"), data, 0o600)
+}
+
+func tokenCache() (*client.FileTokenCache, error) {
+	dir, err := configDir()
+	if err != nil {
+		return nil, err
+	}
+	return client.NewFileTokenCache(filepath.Join(dir, "tokens"))
+}
+
+// authentication returns the configured credentials.
+func authentication(cfg *config) (client.TokenSourceInitializer, error) {
+	switch {
+	case cfg.KeyFile != "":
+		keyFile, err := oidcclient.ConfigFromKeyFile(cfg.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		return client.JWTAuthentication(keyFile, oidc.ScopeOpenID, client.ScopeZitadelAPI()), nil
+	case cfg.PAT != "":
+		return client.PAT(cfg.PAT), nil
+	case cfg.ClientID != "":
+		cache, err := tokenCache()
+		if err != nil {
+			return nil, err
+		}
+		return deviceAuthentication(cfg.ClientID, cache), nil
+	default:
+		return nil, errors.New("no credentials configured, run login or set ZITADEL_KEY_FILE or ZITADEL_PAT")
+	}
+}
+
+// deviceAuthentication uses the tokens of the device authorization stored in the cache,
+// the access token is refreshed using the refresh token once expired.
+func deviceAuthentication(clientID string, cache client.TokenCache) client.TokenSourceInitializer {
+	return func(ctx context.Context, issuer string) (oauth2.TokenSource, error) {
+		token, err := cache.Load(issuer)
+		if errors.Is(err, client.ErrTokenNotCached) {
+			return nil, errors.New("not logged in, run login -device")
+		}
+		if err != nil {
+			return nil, err
+		}
+		discovery, err := oidcclient.Discover(ctx, issuer, http.DefaultClient)
+		if err != nil {
+			return nil, err
+		}
+		conf := &oauth2.Config{
+			ClientID: clientID,
+			Endpoint: oauth2.Endpoint{TokenURL: discovery.TokenEndpoint, AuthStyle: oauth2.AuthStyleInParams},
+		}
+		return client.NewCachedTokenSource(conf.TokenSource(ctx, token), cache, issuer), nil
+	}
+}
+
+func (c *cli) login(ctx context.Context, args []string) error {
+	fs := c.flagSet("login", "")
+	device := fs.Bool("device", false, "log in as human user by the device authorization (requires -client-id)")
+	clientID := fs.String("client-id", "", "client ID of a native application with the device code grant")
+	loginPAT := fs.String("pat", "", "personal access token of a service user")
+	loginKey := fs.String("key", "", "path to the key.json of a service user")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	cfg := &config{Domain: c.domain, Insecure: c.insecure}
+	z, err := instance(cfg)
+	if err != nil {
+		return err
+	}
+	switch {
+	case *device:
+		if *clientID == "" {
+			return errors.New("-device requires -client-id")
+		}
+		cfg.ClientID = *clientID
+		if err = c.deviceLogin(ctx, z.Origin(), *clientID); err != nil {
+			return err
+		}
+	case *loginPAT != "":
+		cfg.PAT = *loginPAT
+	case *loginKey != "":
+		if cfg.KeyFile, err = filepath.Abs(*loginKey); err != nil {
+			return err
+		}
+	default:
+		return errors.New("one of -device, -pat or -key is required")
+	}
+
+	credentials, err := authentication(cfg)
+	if err != nil {
+		return err
+	}
+	zc, err := client.New(ctx, z, append([]client.Option{client.WithAuth(credentials)}, c.clientOptions...)...)
+	if err != nil {
+		return err
+	}
+	defer zc.Close()
+	me, err := zc.AuthService().GetMyUser(ctx, &auth.GetMyUserRequest{})
+	if err != nil {
+		return fmt.Errorf("credentials were not accepted: %w", err)
+	}
+	if err = cfg.save(); err != nil {
+		return err
+	}
+	fmt.Fprintf(c.stderr, "logged in to %s as %s\n", cfg.Domain, me.GetUser().GetPreferredLoginName())
+	return nil
+}
+
+// deviceLogin runs the device authorization and stores the tokens in the cache.
+func (c *cli) deviceLogin(ctx context.Context, issuer, clientID string) error {
+	scopes := []string{oidc.ScopeOpenID, oidc.ScopeProfile, oidc.ScopeOfflineAccess, client.ScopeZitadelAPI()}
+	relyingParty, err := rp.NewRelyingPartyOIDC(ctx, issuer, clientID, "", "", scopes)
+	if err != nil {
+		return err
+	}
+	authorization, err := rp.DeviceAuthorization(ctx, scopes, relyingParty, nil)
+	if err != nil {
+		return err
+	}
+	verificationURI := authorization.VerificationURIComplete
+	if verificationURI == "" {
+		verificationURI = authorization.VerificationURI
+	}
+	fmt.Fprintf(c.stderr, "open %s and confirm the code %s\n", verificationURI, authorization.UserCode)
+	resp, err := rp.DeviceAccessToken(ctx, authorization.DeviceCode, time.Duration(authorization.Interval)*time.Second, relyingParty)
+	if err != nil {
+		return err
+	}
+	cache, err := tokenCache()
+	if err != nil {
+		return err
+	}
+	return cache.Store(issuer, &oauth2.Token{
+		AccessToken:  resp.AccessToken,
+		TokenType:    resp.TokenType,
+		RefreshToken: resp.RefreshToken,
+		Expiry:       time.Now().Add(time.Duration(resp.ExpiresIn) * time.Second),
+	})
+}
+
+func (c *cli) logout(_ context.Context, args []string) error {
+	if err := c.flagSet("logout", "").Parse(args); err != nil {
+		return err
+	}
+	dir, err := configDir()
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(dir)
+}
+
+// instance returns the instance of the config.
+func instance(cfg *config) (*zitadel.Zitadel, error) {
+	if cfg.Domain == "" {
+		return nil, errors.New("no instance configured, run login or set ZITADEL_DOMAIN")
+	}
+	var opts []zitadel.Option
+	if cfg.Insecure != "" {
+		opts = append(opts, zitadel.WithInsecure(cfg.Insecure))
+	}
+	return zitadel.New(cfg.Domain, opts...), nil
+}
@@ -0,0 +1,186 @@
+// Command zitadelctl-lite runs common administrative operations against a ZITADEL instance,
+// so the SDK can be used without writing code:
+//
+//	zitadelctl-lite -domain my-instance.zitadel.cloud login -device -client-id 123456789@project
+//	zitadelctl-lite users list -email alice@example.com
+//	zitadelctl-lite -o json projects create "My Project"
+//
+// Commands:
+//
+//	login     store the instance and credentials (device authorization, personal access token or key file)
+//	logout    remove the stored credentials
+//	users     list, get, create, update or delete users
+//	orgs      list organizations
+//	projects  create a project
+//	apps      create an application of a project
+//	events    tail the events of the instance
+//	tokens    diagnose why an API rejects an access or ID token
+//
+// Run a command with -h to print its flags. The output is a table by default, or JSON with -o json.
+//
+// The instance and credentials are taken from the login, or the environment variables (or the corresponding flags):
+//
+//	ZITADEL_DOMAIN    domain of the instance (-domain)
+//	ZITADEL_INSECURE  port of an instance without TLS, e.g. `8080` for a local instance (-insecure)
+//	ZITADEL_KEY_FILE  path to the key.json of a service user (-key)
+//	ZITADEL_PAT       personal access token of a service user (-pat)
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client"
+)
+
+// command runs a command with its arguments (without the command name).
+type command func(ctx context.Context, args []string) error
+
+func main() {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	err := newCLI(os.Stdin, os.Stdout, os.Stderr, os.Getenv).run(ctx, os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// cli runs the commands, writing their results to stdout and messages (e.g. the usage) to stderr.
+// The defaults of the global flags are taken from the environment.
+type cli struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+	getenv func(string) string
+	// clientOptions are passed to the client in addition to the credentials
+	clientOptions []client.Option
+
+	// global flags
+	domain   string
+	insecure string
+	keyPath  string
+	pat      string
+	format   string
+}
+
+func newCLI(stdin io.Reader, stdout, stderr io.Writer, getenv func(string) string) *cli {
+	return &cli{stdin: stdin, stdout: stdout, stderr: stderr, getenv: getenv}
+}
+
+func (c *cli) commands() map[string]command {
+	return map[string]command{
+		"login":    c.login,
+		"logout":   c.logout,
+		"users":    c.usersCommand,
+		"orgs":     c.orgsCommand,
+		"projects": c.projectsCommand,
+		"apps":     c.appsCommand,
+		"events":   c.eventsCommand,
+		"tokens":   c.tokensCommand,
+	}
+}
+
+// run parses the global flags and runs the command of the arguments.
+func (c *cli) run(ctx context.Context, args []string) error {
+	commands := c.commands()
+	fs := flag.NewFlagSet(filepath.Base(os.Args[0]), flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	fs.Usage = func() {
+		names := make([]string, 0, len(commands))
+		for name := range commands {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		fmt.Fprintf(fs.Output(), "usage: %s [flags] %s [args]\n", filepath.Base(os.Args[0]), strings.Join(names, "|"))
+		fs.PrintDefaults()
+	}
+	fs.StringVar(&c.domain, "domain", c.getenv("ZITADEL_DOMAIN"), "domain of the ZITADEL instance")
+	fs.StringVar(&c.insecure, "insecure", c.getenv("ZITADEL_INSECURE"), "port of an instance without TLS (e.g. 8080)")
+	fs.StringVar(&c.keyPath, "key", c.getenv("ZITADEL_KEY_FILE"), "path to the key.json of the service user")
+	fs.StringVar(&c.pat, "pat", c.getenv("ZITADEL_PAT"), "personal access token of the service user")
+	fs.StringVar(&c.format, "o", "table", "output format: table or json")
+	if err := fs.Parse(args); err != nil {
+		return flag.ErrHelp
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+	cmd, ok := commands[fs.Arg(0)]
+	if !ok {
+		fmt.Fprintf(c.stderr, "unknown command %q\n", fs.Arg(0))
+		fs.Usage()
+		return flag.ErrHelp
+	}
+	return cmd(ctx, fs.Args()[1:])
+}
+
+// newClient creates a client of the configured instance authenticated by the configured credentials.
+func (c *cli) newClient(ctx context.Context) (*client.Client, error) {
+	cfg, err := c.loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	z, err := instance(cfg)
+	if err != nil {
+		return nil, err
+	}
+	auth, err := authentication(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return client.New(ctx, z, append([]client.Option{client.WithAuth(auth)}, c.clientOptions...)...)
+}
+
+// subcommand dispatches the first argument to the subcommands.
+func (c *cli) subcommand(ctx context.Context, name string, subcommands map[string]command, args []string) error {
+	names := make([]string, 0, len(subcommands))
+	for sub := range subcommands {
+		names = append(names, sub)
+	}
+	sort.Strings(names)
+	if len(args) == 0 {
+		return fmt.Errorf("usage: %s %s", name, strings.Join(names, "|"))
+	}
+	cmd, ok := subcommands[args[0]]
+	if !ok {
+		return fmt.Errorf("unknown command %q, usage: %s %s", args[0], name, strings.Join(names, "|"))
+	}
+	return cmd(ctx, args[1:])
+}
+
+// flagSet creates the flags of a (sub)command, the usage describes the positional arguments.
+func (c *cli) flagSet(name, usage string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: %s %s [flags] %s\n", filepath.Base(os.Args[0]), name, usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// stringList is a flag which can be set multiple times.
+type stringList []string
+
+func (s *stringList) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringList) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
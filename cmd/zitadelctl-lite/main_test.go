@@ -0,0 +1,302 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client"
+)
+
+// fakeInstance is a fake of the APIs used by the users and orgs commands,
+// it records the requests as `METHOD path`.
+type fakeInstance struct {
+	t        *testing.T
+	mu       sync.Mutex
+	requests []string
+	bodies   []map[string]any
+}
+
+func (i *fakeInstance) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	assert.Equal(i.t, "Bearer token", r.Header.Get("Authorization"))
+	var req map[string]any
+	if r.Body != nil && r.ContentLength != 0 {
+		require.NoError(i.t, json.NewDecoder(r.Body).Decode(&req))
+	}
+	i.requests = append(i.requests, r.Method+" "+r.URL.Path)
+	i.bodies = append(i.bodies, req)
+	alice := map[string]any{
+		"userId":   "user1",
+		"username": "alice",
+		"state":    "USER_STATE_ACTIVE",
+		"human": map[string]any{
+			"profile": map[string]any{"givenName": "Alice", "familyName": "Admin", "displayName": "Alice Admin"},
+			"email":   map[string]any{"email": "alice@example.com"},
+		},
+	}
+	var resp any = map[string]any{}
+	switch path := r.URL.Path; {
+	case r.Method == http.MethodPost && path == "/v2/users":
+		resp = map[string]any{"result": []any{alice, map[string]any{
+			"userId":   "user2",
+			"username": "backend",
+			"state":    "USER_STATE_ACTIVE",
+			"machine":  map[string]any{"name": "Backend"},
+		}}}
+	case r.Method == http.MethodPost && path == "/v2/users/human":
+		resp = map[string]any{"userId": "user3"}
+	case r.Method == http.MethodGet && path == "/v2/users/user1":
+		resp = map[string]any{"user": alice}
+	case r.Method == http.MethodDelete && strings.HasPrefix(path, "/v2/users/"):
+	case path == "/v2/organizations/_search":
+		resp = map[string]any{"result": []any{map[string]any{
+			"id":            "org1",
+			"name":          "ACME",
+			"state":         "ORGANIZATION_STATE_ACTIVE",
+			"primaryDomain": "acme.example.com",
+		}}}
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	data, err := json.Marshal(resp)
+	require.NoError(i.t, err)
+	w.Write(data)
+}
+
+// newTestCLI returns a [cli] of the instance, the domain and credentials are taken from the environment.
+// The config directory is empty, so there is no login.
+func newTestCLI(t *testing.T, i *fakeInstance) (c *cli, stdout, stderr *bytes.Buffer) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	server := httptest.NewServer(i)
+	t.Cleanup(server.Close)
+	host, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	require.NoError(t, err)
+	env := map[string]string{"ZITADEL_DOMAIN": host, "ZITADEL_INSECURE": port, "ZITADEL_PAT": "token"}
+	stdout, stderr = new(bytes.Buffer), new(bytes.Buffer)
+	c = newCLI(strings.NewReader(""), stdout, stderr, func(key string) string { return env[key] })
+	c.clientOptions = []client.Option{client.WithTransport(client.TransportREST)}
+	return c, stdout, stderr
+}
+
+func TestCLI_run_usage(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		wantErr    error
+		wantErrMsg string
+		wantStderr string
+	}{
+		{
+			name:       "no command",
+			wantErr:    flag.ErrHelp,
+			wantStderr: "apps|events|login|logout|orgs|projects|tokens|users [args]",
+		},
+		{
+			name:       "unknown command",
+			args:       []string{"groups"},
+			wantErr:    flag.ErrHelp,
+			wantStderr: `unknown command "groups"`,
+		},
+		{
+			name:       "unknown flag",
+			args:       []string{"-unknown", "users"},
+			wantErr:    flag.ErrHelp,
+			wantStderr: "flag provided but not defined: -unknown",
+		},
+		{
+			name:       "help",
+			args:       []string{"-h"},
+			wantErr:    flag.ErrHelp,
+			wantStderr: "output format: table or json",
+		},
+		{
+			name:       "no subcommand",
+			args:       []string{"users"},
+			wantErrMsg: "usage: users create|delete|get|list|update",
+		},
+		{
+			name:       "unknown subcommand",
+			args:       []string{"orgs", "delete"},
+			wantErrMsg: `unknown command "delete", usage: orgs list`,
+		},
+		{
+			name:       "subcommand without argument",
+			args:       []string{"users", "get"},
+			wantErr:    flag.ErrHelp,
+			wantStderr: "users get [flags] <user ID>",
+		},
+		{
+			name:       "subcommand help",
+			args:       []string{"users", "list", "-h"},
+			wantErr:    flag.ErrHelp,
+			wantStderr: "email of the user",
+		},
+		{
+			name:       "missing required flags",
+			args:       []string{"users", "create", "-email", "alice@example.com"},
+			wantErrMsg: "-email, -given-name and -family-name are required",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := &fakeInstance{t: t}
+			c, stdout, stderr := newTestCLI(t, i)
+			err := c.run(context.Background(), tt.args)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.EqualError(t, err, tt.wantErrMsg)
+			}
+			assert.Contains(t, stderr.String(), tt.wantStderr)
+			assert.Empty(t, stdout.String())
+			assert.Empty(t, i.requests, "no request is sent")
+		})
+	}
+}
+
+func TestCLI_run_commands(t *testing.T) {
+	tests := []struct {
+		name         string
+		args         []string
+		wantRequests []string
+		wantStdout   string
+	}{
+		{
+			name:         "list",
+			args:         []string{"users", "list"},
+			wantRequests: []string{"POST /v2/users"},
+			wantStdout: "ID     USERNAME  TYPE     STATE   NAME         EMAIL\n" +
+				"user1  alice     human    active  Alice Admin  alice@example.com\n" +
+				// the empty email column is padded
+				"user2  backend   machine  active  Backend      \n",
+		},
+		{
+			name:         "get",
+			args:         []string{"users", "get", "user1"},
+			wantRequests: []string{"GET /v2/users/user1"},
+			wantStdout: `ID     USERNAME  TYPE   STATE   NAME         EMAIL
+user1  alice     human  active  Alice Admin  alice@example.com
+`,
+		},
+		{
+			name:         "create",
+			args:         []string{"users", "create", "-email", "carol@example.com", "-given-name", "Carol", "-family-name", "Clerk"},
+			wantRequests: []string{"POST /v2/users/human"},
+			wantStdout:   "USER ID\nuser3\n",
+		},
+		{
+			name:         "delete",
+			args:         []string{"users", "delete", "user1", "user2"},
+			wantRequests: []string{"DELETE /v2/users/user1", "DELETE /v2/users/user2"},
+		},
+		{
+			name:         "list organizations",
+			args:         []string{"orgs", "list"},
+			wantRequests: []string{"POST /v2/organizations/_search"},
+			wantStdout: `ID    NAME  STATE   PRIMARY DOMAIN
+org1  ACME  active  acme.example.com
+`,
+		},
+		{
+			name:         "list organizations as json",
+			args:         []string{"-o", "json", "orgs", "list"},
+			wantRequests: []string{"POST /v2/organizations/_search"},
+			wantStdout: `[
+  {
+    "id": "org1",
+    "name": "ACME",
+    "state": "active",
+    "primaryDomain": "acme.example.com"
+  }
+]
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := &fakeInstance{t: t}
+			c, stdout, _ := newTestCLI(t, i)
+			require.NoError(t, c.run(context.Background(), tt.args))
+			assert.Equal(t, tt.wantRequests, i.requests)
+			assert.Equal(t, tt.wantStdout, stdout.String())
+		})
+	}
+}
+
+func TestCLI_run_usersListQueries(t *testing.T) {
+	i := &fakeInstance{t: t}
+	c, stdout, _ := newTestCLI(t, i)
+	require.NoError(t, c.run(context.Background(), []string{"users", "list", "-org", "org1", "-email", "alice@example.com", "-limit", "1"}))
+	assert.Equal(t, `ID     USERNAME  TYPE   STATE   NAME         EMAIL
+user1  alice     human  active  Alice Admin  alice@example.com
+`, stdout.String())
+	require.Len(t, i.bodies, 1)
+	queries := i.bodies[0]["queries"].([]any)
+	require.Len(t, queries, 2)
+	assert.Equal(t, "org1", queries[0].(map[string]any)["organizationIdQuery"].(map[string]any)["organizationId"])
+	assert.Equal(t, "alice@example.com", queries[1].(map[string]any)["emailQuery"].(map[string]any)["emailAddress"])
+}
+
+func TestCLI_loadConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		stored *config
+		env    map[string]string
+		want   *config
+	}{
+		{
+			name: "no login",
+			env:  map[string]string{"ZITADEL_DOMAIN": "env.example.com", "ZITADEL_PAT": "env-token"},
+			want: &config{Domain: "env.example.com", PAT: "env-token"},
+		},
+		{
+			name:   "login",
+			stored: &config{Domain: "login.example.com", ClientID: "client1"},
+			want:   &config{Domain: "login.example.com", ClientID: "client1"},
+		},
+		{
+			name:   "environment takes precedence over the login",
+			stored: &config{Domain: "login.example.com", Insecure: "8080", ClientID: "client1"},
+			env:    map[string]string{"ZITADEL_DOMAIN": "env.example.com", "ZITADEL_KEY_FILE": "key.json"},
+			want:   &config{Domain: "env.example.com", KeyFile: "key.json"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+			if tt.stored != nil {
+				require.NoError(t, tt.stored.save())
+			}
+			c := newCLI(nil, nil, new(bytes.Buffer), func(key string) string { return tt.env[key] })
+			// the global flags are only set once the arguments are parsed
+			require.ErrorIs(t, c.run(context.Background(), nil), flag.ErrHelp)
+			got, err := c.loadConfig()
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("invalid config", func(t *testing.T) {
+		dir := t.TempDir()
+		t.Setenv("XDG_CONFIG_HOME", dir)
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, "zitadelctl-lite"), 0o700))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "zitadelctl-lite", "config.json"), []byte("{"), 0o600))
+		_, err := newCLI(nil, nil, nil, func(string) string { return "" }).loadConfig()
+		assert.ErrorContains(t, err, "invalid config")
+	})
+}
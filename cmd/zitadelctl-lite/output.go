@@ -0,0 +1,40 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// table is the tabular representation of a result.
+type table struct {
+	header []string
+	rows   [][]string
+}
+
+// output writes v as JSON or the table, depending on the output format.
+func (c *cli) output(v any, t table) error {
+	return write(c.stdout, c.format, v, t)
+}
+
+func write(w io.Writer, format string, v any, t table) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case "table":
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		if t.header != nil {
+			fmt.Fprintln(tw, strings.Join(t.header, "\t"))
+		}
+		for _, row := range t.rows {
+			fmt.Fprintln(tw, strings.Join(row, "\t"))
+		}
+		return tw.Flush()
+	default:
+		return fmt.Errorf("unknown output format %q", format)
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrite(t *testing.T) {
+	v := map[string]string{"id": "user1"}
+	tests := []struct {
+		name    string
+		format  string
+		table   table
+		want    string
+		wantErr string
+	}{
+		{
+			name:   "table",
+			format: "table",
+			table:  table{header: []string{"ID", "USERNAME"}, rows: [][]string{{"user1", "alice"}, {"user20", "bob"}}},
+			want:   "ID      USERNAME\nuser1   alice\nuser20  bob\n",
+		},
+		{
+			name:   "table without header",
+			format: "table",
+			table:  table{rows: [][]string{{"user1"}}},
+			want:   "user1\n",
+		},
+		{
+			name:   "json",
+			format: "json",
+			want:   "{\n  \"id\": \"user1\"\n}\n",
+		},
+		{
+			name:    "unknown format",
+			format:  "yaml",
+			wantErr: `unknown output format "yaml"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := new(bytes.Buffer)
+			err := write(out, tt.format, v, tt.table)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, out.String())
+		})
+	}
+}
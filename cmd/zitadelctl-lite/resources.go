@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+
+	objectV2 "github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/object/v2"
+	orgV2 "github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/org/v2"
+	"github.com/zitadel/zitadel-go/v3/pkg/management/projects"
+)
+
+// organization is the output of an organization.
+type organization struct {
+	ID            string `json:"id"`
+	Name          string `json:"name"`
+	State         string `json:"state"`
+	PrimaryDomain string `json:"primaryDomain"`
+}
+
+func (c *cli) orgsCommand(ctx context.Context, args []string) error {
+	return c.subcommand(ctx, "orgs", map[string]command{
+		"list": c.listOrgs,
+	}, args)
+}
+
+func (c *cli) listOrgs(ctx context.Context, args []string) error {
+	fs := c.flagSet("orgs list", "")
+	name := fs.String("name", "", "name of the organizations (contains, case-insensitive)")
+	limit := fs.Uint("limit", 100, "maximum number of organizations")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	req := &orgV2.ListOrganizationsRequest{Query: &objectV2.ListQuery{Limit: uint32(*limit)}}
+	if *name != "" {
+		req.Queries = append(req.Queries, &orgV2.SearchQuery{Query: &orgV2.SearchQuery_NameQuery{
+			NameQuery: &orgV2.OrganizationNameQuery{Name: *name, Method: objectV2.TextQueryMethod_TEXT_QUERY_METHOD_CONTAINS_IGNORE_CASE},
+		}})
+	}
+	zc, err := c.newClient(ctx)
+	if err != nil {
+		return err
+	}
+	defer zc.Close()
+	resp, err := zc.OrganizationServiceV2().ListOrganizations(ctx, req)
+	if err != nil {
+		return err
+	}
+	orgs := make([]organization, 0, len(resp.GetResult()))
+	t := table{header: []string{"ID", "NAME", "STATE", "PRIMARY DOMAIN"}}
+	for _, org := range resp.GetResult() {
+		o := organization{
+			ID:            org.GetId(),
+			Name:          org.GetName(),
+			State:         strings.ToLower(strings.TrimPrefix(org.GetState().String(), "ORGANIZATION_STATE_")),
+			PrimaryDomain: org.GetPrimaryDomain(),
+		}
+		orgs = append(orgs, o)
+		t.rows = append(t.rows, []string{o.ID, o.Name, o.State, o.PrimaryDomain})
+	}
+	return c.output(orgs, t)
+}
+
+func (c *cli) projectsCommand(ctx context.Context, args []string) error {
+	return c.subcommand(ctx, "projects", map[string]command{
+		"create": c.createProject,
+	}, args)
+}
+
+func (c *cli) createProject(ctx context.Context, args []string) error {
+	fs := c.flagSet("projects create", "<name>")
+	spec := projects.ProjectSpec{}
+	fs.StringVar(&spec.OrganizationID, "org", "", "ID of the organization (default: organization of the credentials)")
+	fs.BoolVar(&spec.RoleAssertion, "role-assertion", false, "add the roles of the user to the tokens")
+	fs.BoolVar(&spec.RoleCheck, "role-check", false, "only allow users with a grant of the project to log in")
+	roles := new(stringList)
+	fs.Var(roles, "role", "key of a role to add, can be repeated")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+	spec.Name = fs.Arg(0)
+	for _, key := range *roles {
+		spec.Roles = append(spec.Roles, projects.Role{Key: key, DisplayName: key})
+	}
+	zc, err := c.newClient(ctx)
+	if err != nil {
+		return err
+	}
+	defer zc.Close()
+	created, err := projects.New(zc).Bootstrap(ctx, spec)
+	if err != nil {
+		return err
+	}
+	return c.output(created, table{header: []string{"PROJECT ID"}, rows: [][]string{{created.ProjectID}}})
+}
+
+func (c *cli) appsCommand(ctx context.Context, args []string) error {
+	return c.subcommand(ctx, "apps", map[string]command{
+		"create": c.createApp,
+	}, args)
+}
+
+func (c *cli) createApp(ctx context.Context, args []string) error {
+	fs := c.flagSet("apps create", "<name>")
+	orgID := fs.String("org", "", "ID of the organization owning the project (default: organization of the credentials)")
+	projectID := fs.String("project", "", "ID of the project (required)")
+	appType := fs.String("type", "web", "type of the application: web, spa, native or api")
+	redirects, postLogoutRedirects := new(stringList), new(stringList)
+	fs.Var(redirects, "redirect", "redirect URI of an OIDC application, can be repeated")
+	fs.Var(postLogoutRedirects, "post-logout-redirect", "post logout redirect URI of an OIDC application, can be repeated")
+	devMode := fs.Bool("dev-mode", false, "allow insecure redirect URIs, e.g. http://localhost")
+	jwt := fs.Bool("jwt", false, "issue JWT access tokens")
+	privateKeyJWT := fs.Bool("private-key-jwt", false, "authenticate a web or api application by a key instead of a secret")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 || *projectID == "" {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+	oidcApp := projects.OIDCApp{
+		Name:                   fs.Arg(0),
+		RedirectURIs:           *redirects,
+		PostLogoutRedirectURIs: *postLogoutRedirects,
+		DevMode:                *devMode,
+		JWTAccessToken:         *jwt,
+		PrivateKeyJWT:          *privateKeyJWT,
+	}
+	var app projects.App
+	switch *appType {
+	case "web":
+		oidcApp.Type = projects.OIDCWeb
+		app = oidcApp
+	case "spa":
+		oidcApp.Type = projects.OIDCSinglePage
+		app = oidcApp
+	case "native":
+		oidcApp.Type = projects.OIDCNative
+		app = oidcApp
+	case "api":
+		app = projects.APIApp{Name: fs.Arg(0), PrivateKeyJWT: *privateKeyJWT}
+	default:
+		return fmt.Errorf("unknown application type %q", *appType)
+	}
+	zc, err := c.newClient(ctx)
+	if err != nil {
+		return err
+	}
+	defer zc.Close()
+	created, err := projects.New(zc).AddApplication(ctx, *orgID, *projectID, app)
+	if err != nil {
+		return err
+	}
+	return c.output(created, table{
+		header: []string{"APP ID", "CLIENT ID", "CLIENT SECRET", "COMPLIANCE PROBLEMS"},
+		rows:   [][]string{{created.AppID, created.ClientID, created.ClientSecret, strings.Join(created.ComplianceProblems, ", ")}},
+	})
+}
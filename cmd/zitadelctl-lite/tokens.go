@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	oidcclient "github.com/zitadel/oidc/v3/pkg/client"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/authorization/oauth"
+	"github.com/zitadel/zitadel-go/v3/pkg/management/diagnostics"
+)
+
+func (c *cli) tokensCommand(ctx context.Context, args []string) error {
+	return c.subcommand(ctx, "tokens", map[string]command{
+		"debug": c.debugToken,
+	}, args)
+}
+
+// debugToken diagnoses why an API rejects a token. It only needs the instance, no credentials,
+// except for the introspection, which is authenticated as the API application.
+func (c *cli) debugToken(ctx context.Context, args []string) error {
+	fs := c.flagSet("tokens debug", "<token or - for stdin>")
+	audience, roles := new(stringList), new(stringList)
+	fs.Var(audience, "audience", "expected audience, e.g. the project ID of the API, can be repeated")
+	projectID := fs.String("project", "", "ID of the project of the API")
+	fs.Var(roles, "role", "required role of the project, can be repeated")
+	introspectKey := fs.String("introspect-key", "", "path to the key.json of the API application to introspect the token")
+	introspectClientID := fs.String("introspect-client-id", "", "client ID of the API application to introspect the token")
+	introspectSecret := fs.String("introspect-client-secret", "", "client secret of the API application")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+	token := fs.Arg(0)
+	if token == "-" {
+		data, err := io.ReadAll(c.stdin)
+		if err != nil {
+			return err
+		}
+		token = string(data)
+	}
+	req := diagnostics.TokenRequest{Token: token, Audience: *audience, ProjectID: *projectID, Roles: *roles}
+	switch {
+	case *introspectKey != "":
+		keyFile, err := oidcclient.ConfigFromKeyFile(*introspectKey)
+		if err != nil {
+			return err
+		}
+		req.Introspection = oauth.JWTProfileIntrospectionAuthentication(keyFile)
+	case *introspectClientID != "":
+		req.Introspection = oauth.ClientIDSecretIntrospectionAuthentication(*introspectClientID, *introspectSecret)
+	}
+
+	cfg, err := c.loadConfig()
+	if err != nil {
+		return err
+	}
+	z, err := instance(cfg)
+	if err != nil {
+		return err
+	}
+	report, err := diagnostics.Token(ctx, z, req)
+	if err != nil {
+		return err
+	}
+	if c.format == "json" {
+		err = c.output(report, table{})
+	} else {
+		err = c.printTokenReport(report)
+	}
+	if err != nil {
+		return err
+	}
+	if !report.OK() {
+		return errors.New("the token is rejected")
+	}
+	return nil
+}
+
+func (c *cli) printTokenReport(report *diagnostics.TokenReport) error {
+	findings := table{header: []string{"SEVERITY", "CHECK", "MESSAGE"}}
+	for _, severity := range []diagnostics.Severity{diagnostics.SeverityBlocker, diagnostics.SeverityWarning, diagnostics.SeverityInfo} {
+		for _, finding := range report.Findings {
+			if finding.Severity == severity {
+				findings.rows = append(findings.rows, []string{string(finding.Severity), finding.Check, finding.Message})
+			}
+		}
+	}
+	if err := write(c.stdout, "table", nil, findings); err != nil {
+		return err
+	}
+	if len(report.Claims) == 0 {
+		return nil
+	}
+	claims := table{header: []string{"CLAIM", "VALUE"}}
+	names := make([]string, 0, len(report.Claims))
+	for name := range report.Claims {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		claims.rows = append(claims.rows, []string{name, strings.TrimSpace(fmt.Sprint(report.Claims[name]))})
+	}
+	fmt.Fprintln(c.stdout)
+	return write(c.stdout, "table", nil, claims)
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/management/users"
+)
+
+func (c *cli) usersCommand(ctx context.Context, args []string) error {
+	return c.subcommand(ctx, "users", map[string]command{
+		"list":   c.listUsers,
+		"get":    c.getUser,
+		"create": c.createUser,
+		"update": c.updateUser,
+		"delete": c.deleteUser,
+	}, args)
+}
+
+func (c *cli) listUsers(ctx context.Context, args []string) error {
+	fs := c.flagSet("users list", "")
+	orgID := fs.String("org", "", "ID of the organization")
+	username := fs.String("username", "", "username of the user")
+	email := fs.String("email", "", "email of the user")
+	limit := fs.Int("limit", 100, "maximum number of users")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	queries := []users.Query{users.WithLimit(*limit)}
+	if *orgID != "" {
+		queries = append(queries, users.InOrganization(*orgID))
+	}
+	if *username != "" {
+		queries = append(queries, users.WithUsername(*username))
+	}
+	if *email != "" {
+		queries = append(queries, users.WithEmail(*email))
+	}
+	zc, err := c.newClient(ctx)
+	if err != nil {
+		return err
+	}
+	defer zc.Close()
+	list, err := users.New(zc).ListUsers(ctx, queries...)
+	if err != nil {
+		return err
+	}
+	return c.output(list, usersTable(list...))
+}
+
+func (c *cli) getUser(ctx context.Context, args []string) error {
+	fs := c.flagSet("users get", "<user ID>")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+	zc, err := c.newClient(ctx)
+	if err != nil {
+		return err
+	}
+	defer zc.Close()
+	user, err := users.New(zc).GetUser(ctx, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	return c.output(user, usersTable(user))
+}
+
+func (c *cli) createUser(ctx context.Context, args []string) error {
+	fs := c.flagSet("users create", "")
+	var user users.HumanUser
+	fs.StringVar(&user.OrganizationID, "org", "", "ID of the organization (default: organization of the credentials)")
+	fs.StringVar(&user.Username, "username", "", "username (default: email)")
+	fs.StringVar(&user.Email, "email", "", "email (required)")
+	fs.StringVar(&user.GivenName, "given-name", "", "given name (required)")
+	fs.StringVar(&user.FamilyName, "family-name", "", "family name (required)")
+	password := fs.String("password", "", "initial password, which must be changed on the first login")
+	verified := fs.Bool("verified", false, "mark the email as verified instead of sending a verification code")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if user.Email == "" || user.GivenName == "" || user.FamilyName == "" {
+		return errors.New("-email, -given-name and -family-name are required")
+	}
+	var opts []users.Option
+	if *password != "" {
+		opts = append(opts, users.WithPassword(*password, true))
+	}
+	if *verified {
+		opts = append(opts, users.WithVerifiedEmail())
+	}
+	zc, err := c.newClient(ctx)
+	if err != nil {
+		return err
+	}
+	defer zc.Close()
+	created, err := users.New(zc).CreateHumanUser(ctx, user, opts...)
+	if err != nil {
+		return err
+	}
+	return c.output(created, table{header: []string{"USER ID"}, rows: [][]string{{created.UserID}}})
+}
+
+func (c *cli) updateUser(ctx context.Context, args []string) error {
+	fs := c.flagSet("users update", "<user ID>")
+	fs.String("username", "", "username")
+	fs.String("given-name", "", "given name")
+	fs.String("family-name", "", "family name")
+	fs.String("nick-name", "", "nick name")
+	fs.String("display-name", "", "display name")
+	fs.String("language", "", "preferred language, e.g. de")
+	fs.String("gender", "", "gender: female, male or diverse")
+	fs.String("email", "", "email, which must be verified again")
+	fs.String("phone", "", "phone number in E.164 format, e.g. +41791234567")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+	zc, err := c.newClient(ctx)
+	if err != nil {
+		return err
+	}
+	defer zc.Close()
+	// only the flags explicitly set are updated, so fields can also be cleared
+	update := users.New(zc).Update(fs.Arg(0))
+	fs.Visit(func(f *flag.Flag) {
+		value := f.Value.String()
+		switch f.Name {
+		case "username":
+			update.Username(value)
+		case "given-name":
+			update.GivenName(value)
+		case "family-name":
+			update.FamilyName(value)
+		case "nick-name":
+			update.NickName(value)
+		case "display-name":
+			update.DisplayName(value)
+		case "language":
+			update.PreferredLanguage(value)
+		case "gender":
+			update.Gender(users.Gender(value))
+		case "email":
+			update.Email(value)
+		case "phone":
+			update.Phone(value)
+		}
+	})
+	if _, err = update.Do(ctx); err != nil {
+		return err
+	}
+	user, err := users.New(zc).GetUser(ctx, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	return c.output(user, usersTable(user))
+}
+
+func (c *cli) deleteUser(ctx context.Context, args []string) error {
+	fs := c.flagSet("users delete", "<user ID>...")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+	zc, err := c.newClient(ctx)
+	if err != nil {
+		return err
+	}
+	defer zc.Close()
+	for _, userID := range fs.Args() {
+		if err = users.New(zc).DeleteUser(ctx, userID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func usersTable(list ...*users.User) table {
+	t := table{header: []string{"ID", "USERNAME", "TYPE", "STATE", "NAME", "EMAIL"}}
+	for _, user := range list {
+		row := []string{user.ID, user.Username, "", string(user.State), "", ""}
+		switch {
+		case user.Human != nil:
+			row[2], row[4], row[5] = "human", user.Human.DisplayName, user.Human.Email
+		case user.Machine != nil:
+			row[2], row[4] = "machine", user.Machine.Name
+		}
+		t.rows = append(t.rows, row)
+	}
+	return t
+}
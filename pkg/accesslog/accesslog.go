@@ -0,0 +1,162 @@
+// Package accesslog records structured access logs of HTTP and gRPC requests,
+// annotated with the identity of the caller by the authorization middlewares.
+// The middlewares are provided in pkg/http/middleware and pkg/grpc/middleware.
+package accesslog
+
+import (
+	"context"
+	"log/slog"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/authorization"
+)
+
+type key int
+
+const recordKey key = 1
+
+const (
+	ProtocolHTTP = "http"
+	ProtocolGRPC = "grpc"
+)
+
+// Record is the access log entry of a single request.
+type Record struct {
+	Protocol string
+	// Method is the HTTP method or the full gRPC method name.
+	Method string
+	// Path is the HTTP path, empty for gRPC.
+	Path string
+	// Status is the HTTP status or the gRPC status code.
+	Status  int
+	Latency time.Duration
+	// UserID, OrganizationID and TokenType are set by the authorization middlewares, see [Annotate].
+	UserID         string
+	OrganizationID string
+	TokenType      string
+	// Failed is set for HTTP status 5xx and gRPC errors (for client errors see [WithClientErrors]).
+	Failed bool
+}
+
+// Logger writes the [Record] of the requests to an [slog.Logger].
+type Logger struct {
+	logger       *slog.Logger
+	level        slog.Level
+	sampleRate   float64
+	clientErrors bool
+	sample       func() float64
+}
+
+// Option allows customization of the [Logger].
+type Option func(*Logger)
+
+// WithLevel sets the level of the log entries of successful requests (default Info).
+// Failed requests are always logged with level Error.
+func WithLevel(level slog.Level) Option {
+	return func(l *Logger) {
+		l.level = level
+	}
+}
+
+// WithSampleRate logs only the fraction (0 < rate <= 1) of successful requests (default 1, meaning all).
+// Failed requests are always logged.
+func WithSampleRate(rate float64) Option {
+	return func(l *Logger) {
+		l.sampleRate = rate
+	}
+}
+
+// WithClientErrors treats client errors (HTTP status 4xx) as failed requests,
+// so they are logged regardless of the sample rate.
+func WithClientErrors() Option {
+	return func(l *Logger) {
+		l.clientErrors = true
+	}
+}
+
+// New creates a [Logger] writing to the logger, [slog.Default] if nil.
+func New(logger *slog.Logger, opts ...Option) *Logger {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	l := &Logger{
+		logger:     logger,
+		level:      slog.LevelInfo,
+		sampleRate: 1,
+		sample:     rand.Float64,
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// Start attaches a new [Record] to the context, so it can be annotated during the request.
+func (l *Logger) Start(ctx context.Context, protocol, method, path string) (context.Context, *Record) {
+	record := &Record{Protocol: protocol, Method: method, Path: path}
+	return context.WithValue(ctx, recordKey, record), record
+}
+
+// End completes the [Record] with the status and latency and writes it, if it's sampled.
+func (l *Logger) End(ctx context.Context, record *Record, status int, start time.Time) {
+	record.Status = status
+	record.Latency = time.Since(start)
+	switch record.Protocol {
+	case ProtocolGRPC:
+		record.Failed = status != 0
+	default:
+		record.Failed = status >= 500 || (l.clientErrors && status >= 400)
+	}
+	if !record.Failed && l.sampleRate < 1 && l.sample() >= l.sampleRate {
+		return
+	}
+	level := l.level
+	if record.Failed {
+		level = slog.LevelError
+	}
+	l.logger.LogAttrs(ctx, level, "access", record.attrs()...)
+}
+
+func (r *Record) attrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("protocol", r.Protocol),
+		slog.String("method", r.Method),
+	}
+	if r.Path != "" {
+		attrs = append(attrs, slog.String("path", r.Path))
+	}
+	attrs = append(attrs,
+		slog.Int("status", r.Status),
+		slog.Duration("latency", r.Latency),
+	)
+	if r.UserID != "" {
+		attrs = append(attrs, slog.String("user_id", r.UserID))
+	}
+	if r.OrganizationID != "" {
+		attrs = append(attrs, slog.String("org_id", r.OrganizationID))
+	}
+	if r.TokenType != "" {
+		attrs = append(attrs, slog.String("token_type", r.TokenType))
+	}
+	return attrs
+}
+
+// Annotate sets the identity of the caller on the [Record] of the request, if there's one.
+// The token type is taken from the scheme of the authorization header (e.g. Bearer or DPoP).
+// It's called by the authorization middlewares, the authorization context might be nil or not authorized.
+func Annotate(ctx context.Context, authorizationHeader string, authCtx authorization.Ctx) {
+	record, ok := ctx.Value(recordKey).(*Record)
+	if !ok {
+		return
+	}
+	if scheme, _, ok := strings.Cut(authorizationHeader, " "); ok {
+		record.TokenType = scheme
+	}
+	if authCtx == nil || !authCtx.IsAuthorized() {
+		return
+	}
+	record.UserID = authCtx.UserID()
+	record.OrganizationID = authCtx.OrganizationID()
+}
@@ -0,0 +1,95 @@
+package accesslog
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zitadel/oidc/v3/pkg/oidc"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/authorization/oauth"
+)
+
+func TestLogger(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []Option
+		protocol string
+		status   int
+		sample   float64
+		want     string
+	}{
+		{
+			name:     "http",
+			protocol: ProtocolHTTP,
+			status:   200,
+			want:     "level=INFO msg=access protocol=http method=GET path=/api status=200 latency=0s user_id=user org_id=org token_type=Bearer\n",
+		},
+		{
+			name:     "not sampled",
+			opts:     []Option{WithSampleRate(0.5)},
+			protocol: ProtocolHTTP,
+			status:   200,
+			sample:   0.7,
+		},
+		{
+			name:     "failed always logged",
+			opts:     []Option{WithSampleRate(0.5)},
+			protocol: ProtocolHTTP,
+			status:   502,
+			sample:   0.7,
+			want:     "level=ERROR msg=access protocol=http method=GET path=/api status=502 latency=0s user_id=user org_id=org token_type=Bearer\n",
+		},
+		{
+			name:     "client error",
+			opts:     []Option{WithSampleRate(0), WithClientErrors()},
+			protocol: ProtocolHTTP,
+			status:   403,
+			want:     "level=ERROR msg=access protocol=http method=GET path=/api status=403 latency=0s user_id=user org_id=org token_type=Bearer\n",
+		},
+		{
+			name:     "grpc error",
+			protocol: ProtocolGRPC,
+			status:   7,
+			want:     "level=ERROR msg=access protocol=grpc method=GET path=/api status=7 latency=0s user_id=user org_id=org token_type=Bearer\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			handler := slog.NewTextHandler(&out, &slog.HandlerOptions{
+				ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+					switch a.Key {
+					case slog.TimeKey:
+						return slog.Attr{}
+					case "latency":
+						return slog.Duration("latency", 0)
+					}
+					return a
+				},
+			})
+			l := New(slog.New(handler), tt.opts...)
+			l.sample = func() float64 { return tt.sample }
+
+			ctx, record := l.Start(context.Background(), tt.protocol, "GET", "/api")
+			authCtx := &oauth.IntrospectionContext{IntrospectionResponse: oidc.IntrospectionResponse{
+				Active:  true,
+				Subject: "user",
+				Claims:  map[string]any{"urn:zitadel:iam:user:resourceowner:id": "org"},
+			}}
+			Annotate(ctx, "Bearer token", authCtx)
+			l.End(ctx, record, tt.status, time.Now())
+			assert.Equal(t, tt.want, out.String())
+		})
+	}
+}
+
+func TestAnnotate_unauthorized(t *testing.T) {
+	ctx, record := New(nil).Start(context.Background(), ProtocolHTTP, "GET", "/")
+	var authCtx *oauth.IntrospectionContext
+	Annotate(ctx, "DPoP token", authCtx)
+	assert.Equal(t, &Record{Protocol: ProtocolHTTP, Method: "GET", Path: "/", TokenType: "DPoP"}, record)
+}
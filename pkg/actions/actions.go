@@ -0,0 +1,135 @@
+// Package actions implements a client for the actions (v2) of ZITADEL, consisting of targets and executions.
+//
+// A [Target] is an endpoint called by ZITADEL, an execution binds targets to a [Condition],
+// e.g. a call of an API method (request or response), a function or an event:
+//
+//	c := actions.New(zitadelClient)
+//	id, err := c.CreateTarget(ctx, &actions.Target{
+//		Name:     "audit",
+//		Endpoint: "https://hooks.example.com/audit",
+//		Type:     actions.TargetTypeWebhook,
+//		Timeout:  10 * time.Second,
+//	})
+//	err = c.SetExecution(ctx, actions.Request("/zitadel.user.v2.UserService/AddHumanUser"), actions.Targets(id)...)
+//	err = c.SetExecution(ctx, actions.EventGroup("user.human"), actions.Targets(id)...)
+//
+// The actions are part of the resource API of ZITADEL (v3alpha), which is served as HTTP/JSON (see [resources]).
+package actions
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client"
+	"github.com/zitadel/zitadel-go/v3/pkg/resources"
+)
+
+const (
+	// TargetsPath and ExecutionsPath are the paths of the resources, relative to [resources.BasePath].
+	TargetsPath    = "/actions/targets"
+	ExecutionsPath = "/actions/executions"
+)
+
+// Client calls the action services of ZITADEL.
+type Client struct {
+	resources *resources.Client
+}
+
+// New creates a [Client] for the actions of the instance, using the credentials of the [client.Client].
+func New(c *client.Client, opts ...resources.Option) *Client {
+	return NewFromResources(resources.NewFromClient(c, opts...))
+}
+
+// NewFromResources creates a [Client] using an existing client of the resource API.
+func NewFromResources(c *resources.Client) *Client {
+	return &Client{resources: c}
+}
+
+type details struct {
+	ID string `json:"id"`
+}
+
+// CreateTarget creates the target and returns its ID.
+func (c *Client) CreateTarget(ctx context.Context, target *Target) (string, error) {
+	var resp struct {
+		Details details `json:"details"`
+	}
+	err := c.resources.Do(ctx, http.MethodPost, TargetsPath, target, &resp)
+	return resp.Details.ID, err
+}
+
+// UpdateTarget updates the target identified by its ID.
+func (c *Client) UpdateTarget(ctx context.Context, target *Target) error {
+	return c.resources.Do(ctx, http.MethodPatch, TargetsPath+"/"+url.PathEscape(target.ID), target, nil)
+}
+
+// GetTarget returns the target.
+func (c *Client) GetTarget(ctx context.Context, id string) (*Target, error) {
+	var resp struct {
+		Target struct {
+			Details details `json:"details"`
+			Config  *Target `json:"config"`
+		} `json:"target"`
+	}
+	if err := c.resources.Do(ctx, http.MethodGet, TargetsPath+"/"+url.PathEscape(id), nil, &resp); err != nil {
+		return nil, err
+	}
+	target := resp.Target.Config
+	if target == nil {
+		target = new(Target)
+	}
+	target.ID = resp.Target.Details.ID
+	return target, nil
+}
+
+// ListTargets returns the targets of the instance.
+func (c *Client) ListTargets(ctx context.Context) ([]*Target, error) {
+	var resp struct {
+		Result []struct {
+			Details details `json:"details"`
+			Config  *Target `json:"config"`
+		} `json:"result"`
+	}
+	if err := c.resources.Do(ctx, http.MethodPost, TargetsPath+"/_search", struct{}{}, &resp); err != nil {
+		return nil, err
+	}
+	targets := make([]*Target, 0, len(resp.Result))
+	for _, result := range resp.Result {
+		target := result.Config
+		if target == nil {
+			target = new(Target)
+		}
+		target.ID = result.Details.ID
+		targets = append(targets, target)
+	}
+	return targets, nil
+}
+
+// DeleteTarget deletes the target, it's removed from all executions.
+func (c *Client) DeleteTarget(ctx context.Context, id string) error {
+	return c.resources.Do(ctx, http.MethodDelete, TargetsPath+"/"+url.PathEscape(id), nil, nil)
+}
+
+// SetExecution binds the targets to the condition, replacing the previous targets of the condition.
+// The targets are called in the order provided.
+func (c *Client) SetExecution(ctx context.Context, condition Condition, targets ...ExecutionTarget) error {
+	return c.resources.Do(ctx, http.MethodPut, ExecutionsPath, &setExecutionRequest{
+		Condition: condition,
+		Execution: execution{Targets: targets},
+	}, nil)
+}
+
+// RemoveExecution removes all targets of the condition.
+func (c *Client) RemoveExecution(ctx context.Context, condition Condition) error {
+	return c.SetExecution(ctx, condition)
+}
+
+type setExecutionRequest struct {
+	Condition Condition `json:"condition"`
+	Execution execution `json:"execution"`
+}
+
+type execution struct {
+	Targets []ExecutionTarget `json:"targets"`
+}
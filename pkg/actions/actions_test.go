@@ -0,0 +1,103 @@
+package actions
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/resources"
+	"github.com/zitadel/zitadel-go/v3/pkg/resources/resourcestest"
+)
+
+func TestTarget_JSON(t *testing.T) {
+	tests := []struct {
+		name   string
+		target *Target
+		want   string
+	}{
+		{
+			name:   "webhook",
+			target: &Target{Name: "hook", Endpoint: "https://example.com", InterruptOnError: true, Timeout: 10 * time.Second},
+			want:   `{"name":"hook","endpoint":"https://example.com","timeout":"10s","restWebhook":{"interruptOnError":true}}`,
+		},
+		{
+			name:   "call",
+			target: &Target{Name: "call", Endpoint: "https://example.com", Type: TargetTypeCall, Timeout: 1500 * time.Millisecond},
+			want:   `{"name":"call","endpoint":"https://example.com","timeout":"1.5s","restCall":{}}`,
+		},
+		{
+			name:   "async",
+			target: &Target{Name: "async", Endpoint: "https://example.com", Type: TargetTypeAsync},
+			want:   `{"name":"async","endpoint":"https://example.com","restAsync":{}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.target)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.want, string(data))
+
+			got := new(Target)
+			require.NoError(t, json.Unmarshal(data, got))
+			assert.Equal(t, tt.target, got)
+		})
+	}
+}
+
+func testClient(t *testing.T, handler http.HandlerFunc) *Client {
+	return New(resourcestest.NewClient(t, handler))
+}
+
+func TestClient_SetExecution(t *testing.T) {
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPut, r.Method)
+		assert.Equal(t, resources.BasePath+ExecutionsPath, r.URL.Path)
+		assert.Equal(t, "Bearer "+resourcestest.Token, r.Header.Get("Authorization"))
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{
+			"condition": {"request": {"method": "/zitadel.user.v2.UserService/AddHumanUser"}},
+			"execution": {"targets": [{"target": "t1"}, {"include": {"request": {"service": "zitadel.user.v2.UserService"}}}]}
+		}`, string(body))
+		w.Write([]byte(`{}`))
+	})
+	err := c.SetExecution(context.Background(),
+		Request("/zitadel.user.v2.UserService/AddHumanUser"),
+		append(Targets("t1"), Include(RequestService("zitadel.user.v2.UserService")))...,
+	)
+	require.NoError(t, err)
+}
+
+func TestClient_GetTarget(t *testing.T) {
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, resources.BasePath+TargetsPath+"/t1", r.URL.Path)
+		w.Write([]byte(`{"target":{"details":{"id":"t1"},"config":{"name":"hook","endpoint":"https://example.com","timeout":"5s","restCall":{"interruptOnError":true}}}}`))
+	})
+	target, err := c.GetTarget(context.Background(), "t1")
+	require.NoError(t, err)
+	assert.Equal(t, &Target{
+		ID:               "t1",
+		Name:             "hook",
+		Endpoint:         "https://example.com",
+		Type:             TargetTypeCall,
+		InterruptOnError: true,
+		Timeout:          5 * time.Second,
+	}, target)
+}
+
+func TestClient_ListTargets(t *testing.T) {
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, resources.BasePath+TargetsPath+"/_search", r.URL.Path)
+		w.Write([]byte(`{"result":[{"details":{"id":"t1"},"config":{"name":"hook","endpoint":"https://example.com","restAsync":{}}}]}`))
+	})
+	targets, err := c.ListTargets(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []*Target{{ID: "t1", Name: "hook", Endpoint: "https://example.com", Type: TargetTypeAsync}}, targets)
+}
@@ -0,0 +1,102 @@
+package actions
+
+// Condition defines when an execution is triggered, create one using [Request], [Response], [Function] or [Event]
+// and their variants.
+type Condition struct {
+	Request  *MethodCondition   `json:"request,omitempty"`
+	Response *MethodCondition   `json:"response,omitempty"`
+	Function *FunctionCondition `json:"function,omitempty"`
+	Event    *EventCondition    `json:"event,omitempty"`
+}
+
+// MethodCondition matches calls of the API by method, service or all of them.
+type MethodCondition struct {
+	// Method is the full gRPC method, e.g. `/zitadel.user.v2.UserService/AddHumanUser`.
+	Method  string `json:"method,omitempty"`
+	Service string `json:"service,omitempty"`
+	All     bool   `json:"all,omitempty"`
+}
+
+// FunctionCondition matches a function of ZITADEL, e.g. `preuserinfo` or `preaccesstoken`.
+type FunctionCondition struct {
+	Name string `json:"name"`
+}
+
+// EventCondition matches events by type, group (type prefix) or all of them.
+type EventCondition struct {
+	Event string `json:"event,omitempty"`
+	Group string `json:"group,omitempty"`
+	All   bool   `json:"all,omitempty"`
+}
+
+// Request is triggered before ZITADEL handles a call of the method,
+// e.g. `/zitadel.user.v2.UserService/AddHumanUser`.
+func Request(method string) Condition {
+	return Condition{Request: &MethodCondition{Method: method}}
+}
+
+// RequestService is triggered before ZITADEL handles a call of the service, e.g. `zitadel.user.v2.UserService`.
+func RequestService(service string) Condition {
+	return Condition{Request: &MethodCondition{Service: service}}
+}
+
+// AllRequests is triggered before ZITADEL handles any call.
+func AllRequests() Condition {
+	return Condition{Request: &MethodCondition{All: true}}
+}
+
+// Response is triggered before ZITADEL returns the response of a call of the method.
+func Response(method string) Condition {
+	return Condition{Response: &MethodCondition{Method: method}}
+}
+
+// ResponseService is triggered before ZITADEL returns the response of a call of the service.
+func ResponseService(service string) Condition {
+	return Condition{Response: &MethodCondition{Service: service}}
+}
+
+// AllResponses is triggered before ZITADEL returns the response of any call.
+func AllResponses() Condition {
+	return Condition{Response: &MethodCondition{All: true}}
+}
+
+// Function is triggered when ZITADEL executes the function, e.g. `preuserinfo`.
+func Function(name string) Condition {
+	return Condition{Function: &FunctionCondition{Name: name}}
+}
+
+// Event is triggered when an event of the type is created, e.g. `user.human.added`.
+func Event(eventType string) Condition {
+	return Condition{Event: &EventCondition{Event: eventType}}
+}
+
+// EventGroup is triggered when an event of the group is created, e.g. `user.human`.
+func EventGroup(group string) Condition {
+	return Condition{Event: &EventCondition{Group: group}}
+}
+
+// AllEvents is triggered when any event is created.
+func AllEvents() Condition {
+	return Condition{Event: &EventCondition{All: true}}
+}
+
+// ExecutionTarget is a target of an execution, either a [Target] or the targets of another condition.
+type ExecutionTarget struct {
+	Target  string     `json:"target,omitempty"`
+	Include *Condition `json:"include,omitempty"`
+}
+
+// Targets returns the execution targets of the target IDs.
+func Targets(ids ...string) []ExecutionTarget {
+	targets := make([]ExecutionTarget, len(ids))
+	for i, id := range ids {
+		targets[i] = ExecutionTarget{Target: id}
+	}
+	return targets
+}
+
+// Include returns an execution target calling the targets of the execution of the condition,
+// e.g. to reuse the targets of a service for some of its methods.
+func Include(condition Condition) ExecutionTarget {
+	return ExecutionTarget{Include: &condition}
+}
@@ -0,0 +1,88 @@
+package actions
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+// TargetType defines how ZITADEL calls a [Target].
+type TargetType int
+
+const (
+	// TargetTypeWebhook calls the target and ignores its response body, the status code is checked.
+	TargetTypeWebhook TargetType = iota
+	// TargetTypeCall calls the target and uses its response body, e.g. to modify the request or response.
+	TargetTypeCall
+	// TargetTypeAsync calls the target without waiting for its response.
+	TargetTypeAsync
+)
+
+// Target is an endpoint called by ZITADEL when an execution is triggered.
+type Target struct {
+	// ID is set by ZITADEL and used to update the target.
+	ID       string
+	Name     string
+	Endpoint string
+	Type     TargetType
+	// InterruptOnError interrupts the request (or response) if the target fails, not used for [TargetTypeAsync].
+	InterruptOnError bool
+	Timeout          time.Duration
+}
+
+type restTarget struct {
+	InterruptOnError bool `json:"interruptOnError,omitempty"`
+}
+
+type targetJSON struct {
+	Name        string      `json:"name,omitempty"`
+	Endpoint    string      `json:"endpoint,omitempty"`
+	Timeout     string      `json:"timeout,omitempty"`
+	RestWebhook *restTarget `json:"restWebhook,omitempty"`
+	RestCall    *restTarget `json:"restCall,omitempty"`
+	RestAsync   *restTarget `json:"restAsync,omitempty"`
+}
+
+// MarshalJSON implements [json.Marshaler] using the representation of the API.
+func (t *Target) MarshalJSON() ([]byte, error) {
+	target := targetJSON{Name: t.Name, Endpoint: t.Endpoint}
+	if t.Timeout > 0 {
+		// durations are encoded in seconds, as by protojson
+		target.Timeout = strconv.FormatFloat(t.Timeout.Seconds(), 'f', -1, 64) + "s"
+	}
+	switch t.Type {
+	case TargetTypeCall:
+		target.RestCall = &restTarget{InterruptOnError: t.InterruptOnError}
+	case TargetTypeAsync:
+		target.RestAsync = &restTarget{}
+	default:
+		target.RestWebhook = &restTarget{InterruptOnError: t.InterruptOnError}
+	}
+	return json.Marshal(target)
+}
+
+// UnmarshalJSON implements [json.Unmarshaler] using the representation of the API.
+func (t *Target) UnmarshalJSON(data []byte) error {
+	var target targetJSON
+	if err := json.Unmarshal(data, &target); err != nil {
+		return err
+	}
+	t.Name, t.Endpoint = target.Name, target.Endpoint
+	t.Timeout = 0
+	if target.Timeout != "" {
+		timeout, err := time.ParseDuration(target.Timeout)
+		if err != nil {
+			return err
+		}
+		t.Timeout = timeout
+	}
+	switch {
+	case target.RestCall != nil:
+		t.Type, t.InterruptOnError = TargetTypeCall, target.RestCall.InterruptOnError
+	case target.RestAsync != nil:
+		t.Type, t.InterruptOnError = TargetTypeAsync, false
+	case target.RestWebhook != nil:
+		t.Type, t.InterruptOnError = TargetTypeWebhook, target.RestWebhook.InterruptOnError
+	}
+	return nil
+}
@@ -0,0 +1,284 @@
+// Package approval requires a second person to approve designated privileged calls of the SDK
+// (e.g. adding IAM members or changing instance policies), also known as four-eyes principle.
+//
+// The [Gate] is installed on the [client.Client] (see [Gate.ClientOption]). Instead of executing a designated call,
+// it records a pending [Operation] in the [Store] and returns a [PendingError] containing its ID.
+// The call is only executed once another person approves it with [Gate.Approve]:
+//
+//	gate := approval.New(store)
+//	c, err := client.New(ctx, zitadel, client.WithAuth(auth), gate.ClientOption())
+//	_, err = c.AdminService().AddIAMMember(approval.WithActor(ctx, "alice"), req)
+//	var pending *approval.PendingError
+//	if errors.As(err, &pending) {
+//		// notify the approvers about pending.ID
+//	}
+//	...
+//	resp, err := gate.Approve(approval.WithActor(ctx, "bob"), c.Conn(), id)
+package approval
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/admin"
+)
+
+var (
+	ErrApprovalRequired = errors.New("approval required")
+	ErrNotPending       = errors.New("operation is not pending")
+	ErrMissingActor     = errors.New("approver is unknown, use WithActor")
+	ErrSelfApproval     = errors.New("operation cannot be approved by its requester")
+)
+
+// DefaultMethods are the calls requiring an approval if no other methods are configured (see [WithMethods]):
+// changes of the IAM members, the default organization, the security and login settings of the instance
+// and the removal of organizations.
+var DefaultMethods = []string{
+	admin.AdminService_AddIAMMember_FullMethodName,
+	admin.AdminService_UpdateIAMMember_FullMethodName,
+	admin.AdminService_RemoveIAMMember_FullMethodName,
+	admin.AdminService_SetDefaultOrg_FullMethodName,
+	admin.AdminService_RemoveOrg_FullMethodName,
+	admin.AdminService_SetSecurityPolicy_FullMethodName,
+	admin.AdminService_UpdateLoginPolicy_FullMethodName,
+	admin.AdminService_UpdatePasswordComplexityPolicy_FullMethodName,
+	admin.AdminService_UpdateLockoutPolicy_FullMethodName,
+}
+
+// PendingError is returned for a designated call, which was recorded as pending [Operation].
+type PendingError struct {
+	ID     string
+	Method string
+}
+
+func (e *PendingError) Error() string {
+	return fmt.Sprintf("%s: %s is pending as operation %s", ErrApprovalRequired, e.Method, e.ID)
+}
+
+// Is allows to check for [ErrApprovalRequired] using errors.Is.
+func (e *PendingError) Is(target error) bool {
+	return target == ErrApprovalRequired
+}
+
+type actorKey struct{}
+
+type approvedKey struct{}
+
+// WithActor sets the person requesting, resp. approving a call.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// Actor returns the person set by [WithActor].
+func Actor(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
+
+// Gate records the designated calls as pending operations until they are approved.
+type Gate struct {
+	store   Store
+	methods map[string]struct{}
+	notify  func(ctx context.Context, operation *Operation)
+	now     func() time.Time
+}
+
+// Option allows customization of the [Gate].
+type Option func(*Gate)
+
+// WithMethods sets the full gRPC methods requiring an approval instead of the [DefaultMethods].
+func WithMethods(methods ...string) Option {
+	return func(g *Gate) {
+		g.methods = make(map[string]struct{}, len(methods))
+		for _, method := range methods {
+			g.methods[method] = struct{}{}
+		}
+	}
+}
+
+// WithNotification calls the function for every recorded operation, e.g. to notify the approvers.
+func WithNotification(notify func(ctx context.Context, operation *Operation)) Option {
+	return func(g *Gate) {
+		g.notify = notify
+	}
+}
+
+// New creates the [Gate] storing the operations in the store.
+func New(store Store, opts ...Option) *Gate {
+	g := &Gate{store: store, now: time.Now}
+	WithMethods(DefaultMethods...)(g)
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g
+}
+
+// ClientOption installs the [Gate.UnaryInterceptor] on the client.
+func (g *Gate) ClientOption() client.Option {
+	return client.WithUnaryInterceptors(g.UnaryInterceptor())
+}
+
+// UnaryInterceptor records the designated calls as pending [Operation] and returns a [PendingError]
+// instead of executing them.
+func (g *Gate) UnaryInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if _, ok := g.methods[method]; !ok || ctx.Value(approvedKey{}) != nil {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+		message, ok := req.(proto.Message)
+		if !ok {
+			return fmt.Errorf("approval: request of %s is no proto message: %T", method, req)
+		}
+		request, err := anypb.New(message)
+		if err != nil {
+			return err
+		}
+		id, err := newID()
+		if err != nil {
+			return err
+		}
+		operation := &Operation{
+			ID:             id,
+			Method:         method,
+			Request:        request,
+			OrganizationID: organizationID(ctx),
+			RequestedBy:    Actor(ctx),
+			RequestedAt:    g.now(),
+			Status:         StatusPending,
+		}
+		if err = g.store.Create(ctx, operation); err != nil {
+			return err
+		}
+		if g.notify != nil {
+			g.notify(ctx, operation)
+		}
+		return &PendingError{ID: id, Method: method}
+	}
+}
+
+// Approve executes the pending operation on the connection (e.g. [client.Client.Conn]) and returns its response.
+// The approver is taken from the context (see [WithActor]) and must differ from the requester.
+// The operation is marked as [StatusExecuted], resp. [StatusFailed] with the error as reason.
+func (g *Gate) Approve(ctx context.Context, conn grpc.ClientConnInterface, id string) (proto.Message, error) {
+	operation, err := g.decide(ctx, id, StatusExecuted, "")
+	if err != nil {
+		return nil, err
+	}
+	reply, err := g.execute(ctx, conn, operation)
+	if err != nil {
+		operation.Status = StatusFailed
+		operation.Reason = err.Error()
+	}
+	if updateErr := g.store.Update(ctx, operation, statusApproved); updateErr != nil {
+		return reply, errors.Join(err, updateErr)
+	}
+	return reply, err
+}
+
+// Reject rejects the pending operation with the reason, it will not be executed.
+// The approver is taken from the context (see [WithActor]) and must differ from the requester.
+func (g *Gate) Reject(ctx context.Context, id, reason string) error {
+	_, err := g.decide(ctx, id, StatusRejected, reason)
+	return err
+}
+
+// statusApproved marks an operation while it's executed, so it cannot be approved concurrently.
+const statusApproved Status = "approved"
+
+func (g *Gate) decide(ctx context.Context, id string, decision Status, reason string) (*Operation, error) {
+	approver := Actor(ctx)
+	if approver == "" {
+		return nil, ErrMissingActor
+	}
+	operation, err := g.store.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if operation.Status != StatusPending {
+		return nil, fmt.Errorf("%w: %s", ErrNotPending, operation.Status)
+	}
+	if operation.RequestedBy == approver {
+		return nil, ErrSelfApproval
+	}
+	operation.DecidedBy = approver
+	operation.DecidedAt = g.now()
+	operation.Reason = reason
+	operation.Status = decision
+	if decision == StatusExecuted {
+		operation.Status = statusApproved
+	}
+	if err = g.store.Update(ctx, operation, StatusPending); err != nil {
+		return nil, err
+	}
+	operation.Status = decision
+	return operation, nil
+}
+
+func (g *Gate) execute(ctx context.Context, conn grpc.ClientConnInterface, operation *Operation) (proto.Message, error) {
+	req, err := operation.Request.UnmarshalNew()
+	if err != nil {
+		return nil, err
+	}
+	reply, err := newReply(operation.Method)
+	if err != nil {
+		return nil, err
+	}
+	ctx = context.WithValue(ctx, approvedKey{}, true)
+	if operation.OrganizationID != "" {
+		md, _ := metadata.FromOutgoingContext(ctx)
+		md = md.Copy()
+		md.Set(client.OrgHeader, operation.OrganizationID)
+		ctx = metadata.NewOutgoingContext(ctx, md)
+	}
+	if err = conn.Invoke(ctx, operation.Method, req, reply); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+// newReply creates the response message of the full gRPC method using the registered descriptors.
+func newReply(method string) (proto.Message, error) {
+	name := protoreflect.FullName(strings.Replace(strings.TrimPrefix(method, "/"), "/", ".", 1))
+	descriptor, err := protoregistry.GlobalFiles.FindDescriptorByName(name)
+	if err != nil {
+		return nil, err
+	}
+	methodDescriptor, ok := descriptor.(protoreflect.MethodDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("approval: %s is no method", method)
+	}
+	messageType, err := protoregistry.GlobalTypes.FindMessageByName(methodDescriptor.Output().FullName())
+	if err != nil {
+		return nil, err
+	}
+	return messageType.New().Interface(), nil
+}
+
+func organizationID(ctx context.Context) string {
+	md, _ := metadata.FromOutgoingContext(ctx)
+	if values := md.Get(client.OrgHeader); len(values) > 0 {
+		return values[len(values)-1]
+	}
+	return ""
+}
+
+func newID() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(id), nil
+}
@@ -0,0 +1,93 @@
+package approval
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/admin"
+)
+
+type testConn struct {
+	grpc.ClientConnInterface
+	method string
+	req    any
+	orgID  string
+}
+
+func (c *testConn) Invoke(ctx context.Context, method string, req, _ any, _ ...grpc.CallOption) error {
+	c.method, c.req = method, req
+	md, _ := metadata.FromOutgoingContext(ctx)
+	if values := md.Get(client.OrgHeader); len(values) > 0 {
+		c.orgID = values[0]
+	}
+	return nil
+}
+
+func TestGate(t *testing.T) {
+	gate := New(NewMemoryStore())
+	interceptor := gate.UnaryInterceptor()
+	invoked := false
+	invoker := func(context.Context, string, any, any, *grpc.ClientConn, ...grpc.CallOption) error {
+		invoked = true
+		return nil
+	}
+
+	err := interceptor(context.Background(), admin.AdminService_ListIAMMembers_FullMethodName, &admin.ListIAMMembersRequest{}, nil, nil, invoker)
+	require.NoError(t, err)
+	assert.True(t, invoked, "other methods are executed directly")
+
+	invoked = false
+	req := &admin.AddIAMMemberRequest{UserId: "user", Roles: []string{"IAM_OWNER"}}
+	ctx := metadata.AppendToOutgoingContext(WithActor(context.Background(), "alice"), client.OrgHeader, "org")
+	err = interceptor(ctx, admin.AdminService_AddIAMMember_FullMethodName, req, &admin.AddIAMMemberResponse{}, nil, invoker)
+	var pending *PendingError
+	require.ErrorAs(t, err, &pending)
+	assert.ErrorIs(t, err, ErrApprovalRequired)
+	assert.False(t, invoked)
+
+	conn := new(testConn)
+	_, err = gate.Approve(WithActor(context.Background(), "alice"), conn, pending.ID)
+	assert.ErrorIs(t, err, ErrSelfApproval)
+	_, err = gate.Approve(context.Background(), conn, pending.ID)
+	assert.ErrorIs(t, err, ErrMissingActor)
+
+	reply, err := gate.Approve(WithActor(context.Background(), "bob"), conn, pending.ID)
+	require.NoError(t, err)
+	assert.IsType(t, &admin.AddIAMMemberResponse{}, reply)
+	assert.Equal(t, admin.AdminService_AddIAMMember_FullMethodName, conn.method)
+	assert.True(t, proto.Equal(req, conn.req.(proto.Message)))
+	assert.Equal(t, "org", conn.orgID)
+
+	operation, err := gate.store.Get(context.Background(), pending.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusExecuted, operation.Status)
+	assert.Equal(t, "bob", operation.DecidedBy)
+
+	_, err = gate.Approve(WithActor(context.Background(), "carol"), conn, pending.ID)
+	assert.ErrorIs(t, err, ErrNotPending)
+}
+
+func TestGate_Reject(t *testing.T) {
+	gate := New(NewMemoryStore(), WithMethods(admin.AdminService_RemoveOrg_FullMethodName))
+	err := gate.UnaryInterceptor()(WithActor(context.Background(), "alice"), admin.AdminService_RemoveOrg_FullMethodName,
+		&admin.RemoveOrgRequest{OrgId: "org"}, &admin.RemoveOrgResponse{}, nil, nil)
+	var pending *PendingError
+	require.True(t, errors.As(err, &pending))
+
+	require.NoError(t, gate.Reject(WithActor(context.Background(), "bob"), pending.ID, "not planned"))
+	rejected, err := gate.store.List(context.Background(), StatusRejected)
+	require.NoError(t, err)
+	if assert.Len(t, rejected, 1) {
+		assert.Equal(t, "not planned", rejected[0].Reason)
+	}
+	_, err = gate.Approve(WithActor(context.Background(), "carol"), new(testConn), pending.ID)
+	assert.ErrorIs(t, err, ErrNotPending)
+}
@@ -0,0 +1,122 @@
+package approval
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+var (
+	ErrNotFound = errors.New("operation not found")
+	// ErrConflict is returned by [Store.Update] if the operation was changed concurrently, e.g. approved twice.
+	ErrConflict = errors.New("operation was changed concurrently")
+)
+
+// Status of an [Operation].
+type Status string
+
+const (
+	StatusPending  Status = "pending"
+	StatusExecuted Status = "executed"
+	StatusFailed   Status = "failed"
+	StatusRejected Status = "rejected"
+)
+
+// Operation is a call waiting for, resp. decided by an approver.
+type Operation struct {
+	ID string
+	// Method is the full gRPC method, e.g. `/zitadel.admin.v1.AdminService/AddIAMMember`.
+	Method string
+	// Request is the serialized request of the call.
+	Request *anypb.Any
+	// OrganizationID is the organization context of the call, if set.
+	OrganizationID string
+	RequestedBy    string
+	RequestedAt    time.Time
+	Status         Status
+	DecidedBy      string
+	DecidedAt      time.Time
+	// Reason is the reason of the rejection, resp. the error of the failed execution.
+	Reason string
+}
+
+// Store persists the operations, e.g. in a database shared by the requesting and approving processes.
+type Store interface {
+	Create(ctx context.Context, operation *Operation) error
+	// Get returns the operation or [ErrNotFound].
+	Get(ctx context.Context, id string) (*Operation, error)
+	// Update stores the operation if its current status is the expected one, [ErrConflict] otherwise.
+	Update(ctx context.Context, operation *Operation, expected Status) error
+	// List returns the operations with the status.
+	List(ctx context.Context, status Status) ([]*Operation, error)
+}
+
+// MemoryStore is an in-memory [Store], e.g. for tests or single process setups.
+type MemoryStore struct {
+	mu         sync.Mutex
+	operations map[string]*Operation
+}
+
+// NewMemoryStore creates an empty [MemoryStore].
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{operations: make(map[string]*Operation)}
+}
+
+// Create implements [Store].
+func (s *MemoryStore) Create(_ context.Context, operation *Operation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.operations[operation.ID] = clone(operation)
+	return nil
+}
+
+// Get implements [Store].
+func (s *MemoryStore) Get(_ context.Context, id string) (*Operation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	operation, ok := s.operations[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return clone(operation), nil
+}
+
+// Update implements [Store].
+func (s *MemoryStore) Update(_ context.Context, operation *Operation, expected Status) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	current, ok := s.operations[operation.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	if current.Status != expected {
+		return ErrConflict
+	}
+	s.operations[operation.ID] = clone(operation)
+	return nil
+}
+
+// List implements [Store].
+func (s *MemoryStore) List(_ context.Context, status Status) ([]*Operation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var operations []*Operation
+	for _, operation := range s.operations {
+		if operation.Status == status {
+			operations = append(operations, clone(operation))
+		}
+	}
+	return operations, nil
+}
+
+func clone(operation *Operation) *Operation {
+	c := *operation
+	if operation.Request != nil {
+		c.Request = proto.Clone(operation.Request).(*anypb.Any)
+	}
+	return &c
+}
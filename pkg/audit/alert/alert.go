@@ -0,0 +1,245 @@
+// Package alert provides an [audit.Sink] posting alerts for high-severity events
+// (e.g. IAM members added, policies changed, keys created) to Slack or Microsoft Teams webhooks.
+package alert
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/audit"
+)
+
+// Format is the payload format of the webhook.
+type Format int
+
+const (
+	// Slack posts messages to a Slack incoming webhook.
+	Slack Format = iota
+	// Teams posts Adaptive Cards to a Microsoft Teams (Workflows) webhook.
+	Teams
+)
+
+const (
+	defaultDedupWindow = 10 * time.Minute
+	defaultRateLimit   = 10
+	defaultRatePeriod  = time.Minute
+)
+
+// DefaultEventTypes are the patterns (see [path.Match]) of the event types alerted by default.
+var DefaultEventTypes = []string{
+	"instance.member.added",
+	"instance.member.changed",
+	"org.member.added",
+	"instance.policy.*",
+	"org.policy.*",
+	"user.machine.key.added",
+	"user.personal.access.token.added",
+	"project.application.key.added",
+}
+
+var ErrWebhookFailed = errors.New("alert webhook failed")
+
+// Sink implements [audit.Sink] by posting alerts for the matching records.
+// Alerts for the same event type and aggregate are sent only once within the dedup window
+// and the number of alerts is limited per period, suppressed alerts are counted in the next one.
+type Sink struct {
+	url         string
+	format      Format
+	eventTypes  []string
+	dedupWindow time.Duration
+	rateLimit   int
+	ratePeriod  time.Duration
+	httpClient  *http.Client
+	now         func() time.Time
+
+	mu          sync.Mutex
+	sent        map[string]time.Time
+	periodStart time.Time
+	periodCount int
+	suppressed  int
+}
+
+// Option allows customization of the [Sink].
+type Option func(*Sink)
+
+// WithEventTypes replaces the [DefaultEventTypes] with the provided patterns (see [path.Match]).
+func WithEventTypes(patterns ...string) Option {
+	return func(s *Sink) {
+		s.eventTypes = patterns
+	}
+}
+
+// WithDedupWindow sets the duration alerts for the same event type and aggregate are deduplicated (default 10 minutes).
+func WithDedupWindow(window time.Duration) Option {
+	return func(s *Sink) {
+		s.dedupWindow = window
+	}
+}
+
+// WithRateLimit sets the max number of alerts per period (default 10 per minute).
+func WithRateLimit(limit int, period time.Duration) Option {
+	return func(s *Sink) {
+		s.rateLimit = limit
+		s.ratePeriod = period
+	}
+}
+
+// WithHTTPClient sets the client used to call the webhook (default [http.DefaultClient]).
+func WithHTTPClient(client *http.Client) Option {
+	return func(s *Sink) {
+		s.httpClient = client
+	}
+}
+
+// New creates a [Sink] posting to the webhook url in the provided format.
+func New(url string, format Format, options ...Option) *Sink {
+	s := &Sink{
+		url:         url,
+		format:      format,
+		eventTypes:  DefaultEventTypes,
+		dedupWindow: defaultDedupWindow,
+		rateLimit:   defaultRateLimit,
+		ratePeriod:  defaultRatePeriod,
+		httpClient:  http.DefaultClient,
+		now:         time.Now,
+		sent:        make(map[string]time.Time),
+	}
+	for _, option := range options {
+		option(s)
+	}
+	return s
+}
+
+// Write implements [audit.Sink]
+func (s *Sink) Write(ctx context.Context, records []*audit.Record) error {
+	var errs []error
+	for _, record := range records {
+		if !s.matches(record.Type) {
+			continue
+		}
+		suppressed, ok := s.allow(record)
+		if !ok {
+			continue
+		}
+		if err := s.post(ctx, record, suppressed); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *Sink) matches(eventType string) bool {
+	for _, pattern := range s.eventTypes {
+		if ok, _ := path.Match(pattern, eventType); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// allow checks the dedup window and the rate limit and returns the number of previously suppressed alerts.
+func (s *Sink) allow(record *audit.Record) (int, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	for key, sent := range s.sent {
+		if now.Sub(sent) >= s.dedupWindow {
+			delete(s.sent, key)
+		}
+	}
+	key := record.Type + ":" + record.AggregateID
+	if _, ok := s.sent[key]; ok {
+		return 0, false
+	}
+	if now.Sub(s.periodStart) >= s.ratePeriod {
+		s.periodStart = now
+		s.periodCount = 0
+	}
+	if s.periodCount >= s.rateLimit {
+		s.suppressed++
+		return 0, false
+	}
+	s.periodCount++
+	s.sent[key] = now
+	suppressed := s.suppressed
+	s.suppressed = 0
+	return suppressed, true
+}
+
+func (s *Sink) post(ctx context.Context, record *audit.Record, suppressed int) error {
+	body, err := json.Marshal(s.message(record, suppressed))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrWebhookFailed, err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%w: status %d", ErrWebhookFailed, resp.StatusCode)
+	}
+	return nil
+}
+
+type fact struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+}
+
+func facts(record *audit.Record, suppressed int) []fact {
+	actor := record.Actor.DisplayName
+	if actor == "" {
+		actor = record.Actor.UserID
+	}
+	f := []fact{
+		{Title: "Aggregate", Value: record.AggregateType + " " + record.AggregateID},
+		{Title: "Organization", Value: record.ResourceOwner},
+		{Title: "Actor", Value: actor},
+		{Title: "Time", Value: record.Time.UTC().Format(time.RFC3339)},
+	}
+	if suppressed > 0 {
+		f = append(f, fact{Title: "Suppressed", Value: fmt.Sprintf("%d alerts suppressed by rate limit", suppressed)})
+	}
+	return f
+}
+
+func (s *Sink) message(record *audit.Record, suppressed int) any {
+	title := "ZITADEL: " + record.Type
+	if s.format == Teams {
+		return map[string]any{
+			"type": "message",
+			"attachments": []map[string]any{{
+				"contentType": "application/vnd.microsoft.card.adaptive",
+				"content": map[string]any{
+					"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+					"type":    "AdaptiveCard",
+					"version": "1.4",
+					"body": []map[string]any{
+						{"type": "TextBlock", "text": title, "weight": "Bolder", "size": "Medium", "color": "Attention"},
+						{"type": "FactSet", "facts": facts(record, suppressed)},
+					},
+				},
+			}},
+		}
+	}
+	text := ":rotating_light: *" + title + "*"
+	for _, f := range facts(record, suppressed) {
+		text += "\n*" + f.Title + ":* " + f.Value
+	}
+	return map[string]string{"text": text}
+}
@@ -0,0 +1,78 @@
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/audit"
+)
+
+func TestSink_Write(t *testing.T) {
+	var messages []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var message map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&message))
+		messages = append(messages, message)
+	}))
+	defer server.Close()
+
+	sink := New(server.URL, Slack, WithRateLimit(2, time.Minute))
+	now := time.Now()
+	sink.now = func() time.Time { return now }
+	record := func(eventType, aggregateID string) *audit.Record {
+		return &audit.Record{Type: eventType, AggregateType: "org", AggregateID: aggregateID, ResourceOwner: aggregateID}
+	}
+
+	err := sink.Write(context.Background(), []*audit.Record{
+		record("org.member.added", "org1"),
+		record("org.member.added", "org1"),  // deduplicated
+		record("user.human.added", "user1"), // not matching
+		record("org.policy.lockout.changed", "org1"),
+		record("org.policy.login.changed", "org1"), // rate limited
+	})
+	require.NoError(t, err)
+	require.Len(t, messages, 2)
+	assert.Contains(t, messages[0]["text"], "org.member.added")
+	assert.Contains(t, messages[1]["text"], "org.policy.lockout.changed")
+
+	now = now.Add(2 * time.Minute)
+	require.NoError(t, sink.Write(context.Background(), []*audit.Record{record("org.member.added", "org1")}))
+	require.Len(t, messages, 2, "dedup window must not be over")
+
+	now = now.Add(10 * time.Minute)
+	require.NoError(t, sink.Write(context.Background(), []*audit.Record{record("org.member.added", "org1")}))
+	require.Len(t, messages, 3)
+	assert.Contains(t, messages[2]["text"], "1 alerts suppressed")
+}
+
+func TestSink_Teams(t *testing.T) {
+	var message map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&message))
+	}))
+	defer server.Close()
+
+	err := New(server.URL, Teams).Write(context.Background(), []*audit.Record{{Type: "instance.member.added", AggregateID: "instance1"}})
+	require.NoError(t, err)
+	assert.Equal(t, "message", message["type"])
+	attachments, _ := message["attachments"].([]any)
+	require.Len(t, attachments, 1)
+	assert.Equal(t, "application/vnd.microsoft.card.adaptive", attachments[0].(map[string]any)["contentType"])
+}
+
+func TestSink_Write_error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	err := New(server.URL, Slack).Write(context.Background(), []*audit.Record{{Type: "instance.member.added"}})
+	assert.ErrorIs(t, err, ErrWebhookFailed)
+}
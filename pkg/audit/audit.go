@@ -0,0 +1,367 @@
+// Package audit exports the events of ZITADEL as normalized audit records to sinks,
+// e.g. for alerting (see package alert) or long-term retention: records are written as JSON lines
+// to an [io.Writer] ([WriterSink]), objects in an S3 compatible storage ([S3Sink]) or a webhook ([WebhookSink]),
+// or sent to a SIEM as syslog messages ([SyslogSink], e.g. CEF) or to the Splunk HTTP Event Collector ([SplunkHECSink]).
+// The export is resumable by persisting its cursor (see [WithJob]).
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/event"
+	"github.com/zitadel/zitadel-go/v3/pkg/events"
+	"github.com/zitadel/zitadel-go/v3/pkg/job"
+)
+
+const defaultInterval = 10 * time.Second
+
+var ErrNoSinks = errors.New("no sinks configured")
+
+// Record is the normalized representation of an event of ZITADEL.
+type Record struct {
+	// ID identifies the record, it's derived from the aggregate, the sequence and the creation date of the event.
+	ID            string         `json:"id"`
+	Time          time.Time      `json:"time"`
+	Type          string         `json:"type"`
+	AggregateType string         `json:"aggregateType"`
+	AggregateID   string         `json:"aggregateId"`
+	ResourceOwner string         `json:"resourceOwner,omitempty"`
+	Sequence      uint64         `json:"sequence"`
+	Actor         Actor          `json:"actor"`
+	Payload       map[string]any `json:"payload,omitempty"`
+}
+
+// Actor is the user (or service of ZITADEL) which caused the event.
+type Actor struct {
+	UserID      string `json:"userId,omitempty"`
+	DisplayName string `json:"displayName,omitempty"`
+	Service     string `json:"service,omitempty"`
+}
+
+// NewRecord converts the event into a [Record].
+func NewRecord(e *event.Event) *Record {
+	created := e.GetCreationDate().AsTime()
+	return &Record{
+		ID:            recordID(e),
+		Time:          created,
+		Type:          e.GetType().GetType(),
+		AggregateType: e.GetAggregate().GetType().GetType(),
+		AggregateID:   e.GetAggregate().GetId(),
+		ResourceOwner: e.GetAggregate().GetResourceOwner(),
+		Sequence:      e.GetSequence(),
+		Actor: Actor{
+			UserID:      e.GetEditor().GetUserId(),
+			DisplayName: e.GetEditor().GetDisplayName(),
+			Service:     e.GetEditor().GetService(),
+		},
+		Payload: e.GetPayload().AsMap(),
+	}
+}
+
+func recordID(e *event.Event) string {
+	return e.GetAggregate().GetType().GetType() + ":" + e.GetAggregate().GetId() + ":" +
+		strconv.FormatUint(e.GetSequence(), 10) + ":" + e.GetCreationDate().AsTime().Format(time.RFC3339Nano)
+}
+
+// newRecord converts the event of the [events.Subscriber] into a [Record], like [NewRecord].
+func newRecord(e *events.Event) (*Record, error) {
+	var payload map[string]any
+	if err := e.Decode(&payload); err != nil {
+		return nil, err
+	}
+	return &Record{
+		ID:            e.Key() + ":" + e.CreationDate.Format(time.RFC3339Nano),
+		Time:          e.CreationDate,
+		Type:          e.Type,
+		AggregateType: e.AggregateType,
+		AggregateID:   e.AggregateID,
+		ResourceOwner: e.ResourceOwner,
+		Sequence:      e.Sequence,
+		Actor: Actor{
+			UserID:      e.EditorUserID,
+			DisplayName: e.EditorName,
+			Service:     e.EditorService,
+		},
+		Payload: payload,
+	}, nil
+}
+
+// Sink receives the exported records.
+type Sink interface {
+	Write(ctx context.Context, records []*Record) error
+}
+
+// SinkFunc allows the use of ordinary functions as [Sink].
+type SinkFunc func(ctx context.Context, records []*Record) error
+
+// Write implements [Sink]
+func (f SinkFunc) Write(ctx context.Context, records []*Record) error {
+	return f(ctx, records)
+}
+
+// Exporter polls the events of ZITADEL and writes them as records to the sinks.
+type Exporter struct {
+	client    *client.Client
+	filter    events.Filter
+	sinks     []Sink
+	interval  time.Duration
+	from      time.Time
+	onError   func(sink Sink, err error)
+	job       *job.Controller
+	batchSize int
+	attempts  int
+	backoff   time.Duration
+}
+
+// Option allows customization of the [Exporter].
+type Option func(*Exporter)
+
+// WithInterval sets the polling interval (default 10 seconds).
+func WithInterval(interval time.Duration) Option {
+	return func(e *Exporter) {
+		e.interval = interval
+	}
+}
+
+// WithFrom sets the creation date from which on events are exported (default start of [Exporter.Run]).
+func WithFrom(from time.Time) Option {
+	return func(e *Exporter) {
+		e.from = from
+	}
+}
+
+// WithEventTypes restricts the export to the event types, e.g. `user.human.added`.
+func WithEventTypes(types ...string) Option {
+	return func(e *Exporter) {
+		e.filter.EventTypes = types
+	}
+}
+
+// WithAggregateTypes restricts the export to the aggregate types, e.g. `user` or `org`.
+func WithAggregateTypes(types ...string) Option {
+	return func(e *Exporter) {
+		e.filter.AggregateTypes = types
+	}
+}
+
+// WithErrorHandler sets a function called if a sink fails to write the records (default: ignored).
+func WithErrorHandler(onError func(sink Sink, err error)) Option {
+	return func(e *Exporter) {
+		e.onError = onError
+	}
+}
+
+// WithBatchSize limits the number of records passed to a single [Sink.Write] (default: all new records at once),
+// e.g. to respect the limits of a SIEM.
+func WithBatchSize(size int) Option {
+	return func(e *Exporter) {
+		e.batchSize = size
+	}
+}
+
+// WithRetry retries failed writes of a sink up to the number of attempts (default 1: no retries)
+// with an exponential backoff starting with the duration. The error handler is only called after the last attempt.
+func WithRetry(attempts int, backoff time.Duration) Option {
+	return func(e *Exporter) {
+		e.attempts = attempts
+		e.backoff = backoff
+	}
+}
+
+// WithJob allows to pause and resume the export and persists its progress (see [job.Controller]).
+// An export started again with the same job continues after the last persisted events instead of [WithFrom],
+// records exported after the progress was last persisted (see [job.WithSaveEvery]) are written again (with the same [Record.ID]).
+func WithJob(ctrl *job.Controller) Option {
+	return func(e *Exporter) {
+		e.job = ctrl
+	}
+}
+
+// NewExporter creates an [Exporter] writing to the sinks.
+// The client must be authorized to read the events of the instance (e.g. IAM_OWNER_VIEWER).
+func NewExporter(client *client.Client, sinks []Sink, options ...Option) *Exporter {
+	e := &Exporter{
+		client:   client,
+		sinks:    sinks,
+		interval: defaultInterval,
+		onError:  func(Sink, error) {},
+		attempts: 1,
+	}
+	for _, option := range options {
+		option(e)
+	}
+	return e
+}
+
+// Run exports the events until the context is done.
+func (e *Exporter) Run(ctx context.Context) error {
+	return e.export(ctx, time.Now, e.poll)
+}
+
+// Backfill exports the events once until all existing events are exported, e.g. for a periodic retention job
+// or to export the history before starting [Exporter.Run]. Without [WithFrom] (and progress of the [WithJob]),
+// all events of the instance are exported.
+func (e *Exporter) Backfill(ctx context.Context) error {
+	return e.export(ctx, func() time.Time { return time.Unix(0, 0) }, e.catchUp)
+}
+
+func (e *Exporter) export(ctx context.Context, defaultFrom func() time.Time, run func(ctx context.Context, subscriber *events.Subscriber, cursor *events.Cursor) error) error {
+	if len(e.sinks) == 0 {
+		return ErrNoSinks
+	}
+	progress, err := e.job.Start(ctx)
+	if err != nil {
+		return err
+	}
+	cursor, err := parseCursor(progress.Cursor)
+	if err != nil {
+		return errors.Join(err, e.job.Finish(ctx, err))
+	}
+	if cursor == nil {
+		from := e.from
+		if from.IsZero() {
+			from = defaultFrom()
+		}
+		cursor = &events.Cursor{CreationDate: from}
+	}
+	err = run(ctx, events.New(e.client), cursor)
+	return errors.Join(err, e.job.Finish(ctx, err))
+}
+
+// parseCursor parses the cursor of the progress of the [WithJob], nil if there's none.
+// Exports of previous versions persisted the creation date of the last exported events only.
+func parseCursor(progress string) (*events.Cursor, error) {
+	if progress == "" {
+		return nil, nil
+	}
+	if from, err := time.Parse(time.RFC3339Nano, progress); err == nil {
+		return &events.Cursor{CreationDate: from}, nil
+	}
+	cursor := new(events.Cursor)
+	if err := json.Unmarshal([]byte(progress), cursor); err != nil {
+		return nil, err
+	}
+	return cursor, nil
+}
+
+func (e *Exporter) poll(ctx context.Context, subscriber *events.Subscriber, cursor *events.Cursor) error {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+		if err := e.job.Wait(ctx); err != nil {
+			return err
+		}
+		var err error
+		if cursor, err = e.exportEvents(ctx, subscriber, cursor); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			// temporary errors of listing the events are retried with the next tick
+			var listErr *listError
+			if !errors.As(err, &listErr) {
+				return err
+			}
+		}
+	}
+}
+
+// listError is returned by [Exporter.exportEvents] if the events could not be listed.
+type listError struct {
+	err error
+}
+
+func (l *listError) Error() string {
+	return l.err.Error()
+}
+
+func (l *listError) Unwrap() error {
+	return l.err
+}
+
+func (e *Exporter) catchUp(ctx context.Context, subscriber *events.Subscriber, cursor *events.Cursor) error {
+	_, err := e.exportEvents(ctx, subscriber, cursor)
+	return err
+}
+
+// exportEvents exports the events after the cursor until all existing events are exported
+// and returns the cursor of the last exported events.
+func (e *Exporter) exportEvents(ctx context.Context, subscriber *events.Subscriber, cursor *events.Cursor) (*events.Cursor, error) {
+	var exportErr error
+	cursor, err := subscriber.CatchUp(ctx, e.filter, cursor, func(ctx context.Context, page []*events.Event) error {
+		exportErr = e.exportPage(ctx, page)
+		return exportErr
+	})
+	if err != nil && exportErr == nil {
+		return cursor, &listError{err: err}
+	}
+	return cursor, err
+}
+
+func (e *Exporter) exportPage(ctx context.Context, page []*events.Event) error {
+	records := make([]*Record, len(page))
+	for i, ev := range page {
+		record, err := newRecord(ev)
+		if err != nil {
+			return err
+		}
+		records[i] = record
+	}
+	e.write(ctx, records)
+	cursor, err := json.Marshal(page[len(page)-1].Cursor)
+	if err != nil {
+		return err
+	}
+	return e.job.Update(ctx, func(progress *job.Progress) {
+		progress.Done += len(records)
+		progress.Cursor = string(cursor)
+	})
+}
+
+func (e *Exporter) write(ctx context.Context, records []*Record) {
+	for _, sink := range e.sinks {
+		for _, batch := range batches(records, e.batchSize) {
+			if err := e.writeSink(ctx, sink, batch); err != nil {
+				e.onError(sink, err)
+			}
+		}
+	}
+}
+
+// writeSink writes the records to the sink, retrying according to [WithRetry].
+func (e *Exporter) writeSink(ctx context.Context, sink Sink, records []*Record) error {
+	backoff := e.backoff
+	for attempt := 1; ; attempt++ {
+		err := sink.Write(ctx, records)
+		if err == nil || attempt >= e.attempts {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+			backoff *= 2
+		}
+	}
+}
+
+// batches splits the records into batches of the size, a single batch if the size is not positive.
+func batches(records []*Record, size int) [][]*Record {
+	if size <= 0 || len(records) <= size {
+		return [][]*Record{records}
+	}
+	batches := make([][]*Record, 0, (len(records)+size-1)/size)
+	for size < len(records) {
+		records, batches = records[size:], append(batches, records[:size])
+	}
+	return append(batches, records)
+}
@@ -0,0 +1,54 @@
+package audit
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/events"
+)
+
+func Test_newRecord(t *testing.T) {
+	record, err := newRecord(&events.Event{
+		AggregateType: "user",
+		AggregateID:   "user1",
+		ResourceOwner: "org1",
+		Type:          "user.human.email.changed",
+		Sequence:      3,
+		CreationDate:  testRecord.Time,
+		EditorUserID:  "admin1",
+		EditorName:    "Admin | Ops",
+		Payload:       json.RawMessage(`{"email":"a=b@zitadel.com"}`),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, testRecord, record)
+}
+
+func Test_parseCursor(t *testing.T) {
+	from := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		progress string
+		want     *events.Cursor
+		wantErr  bool
+	}{
+		{"none", "", nil, false},
+		{"creation date", "2024-06-01T12:00:00Z", &events.Cursor{CreationDate: from}, false},
+		{"cursor", `{"creationDate":"2024-06-01T12:00:00Z","delivered":["user:user1:3"]}`, &events.Cursor{CreationDate: from, Delivered: []string{"user:user1:3"}}, false},
+		{"invalid", "invalid", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseCursor(tt.progress)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
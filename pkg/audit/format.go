@@ -0,0 +1,185 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Formatter serializes a [Record] for a sink, e.g. [FormatJSON], [FormatECS] or [NewCEFFormatter].
+type Formatter func(record *Record) ([]byte, error)
+
+// FormatJSON serializes the [Record] as JSON.
+func FormatJSON(record *Record) ([]byte, error) {
+	return json.Marshal(record)
+}
+
+const ecsVersion = "8.11.0"
+
+// FormatECS serializes the [Record] as JSON document according to the Elastic Common Schema (ECS),
+// the original event is kept in the `zitadel` field.
+func FormatECS(record *Record) ([]byte, error) {
+	doc := map[string]any{
+		"@timestamp": record.Time.UTC(),
+		"ecs":        map[string]any{"version": ecsVersion},
+		"message":    record.Type,
+		"event": map[string]any{
+			"id":       record.ID,
+			"kind":     "event",
+			"module":   "zitadel",
+			"dataset":  "zitadel.audit",
+			"action":   record.Type,
+			"category": []string{ecsCategory(record)},
+			"type":     []string{ecsType(record.Type)},
+			"outcome":  "success",
+			"sequence": record.Sequence,
+			"created":  record.Time.UTC(),
+		},
+		"organization": map[string]any{"id": record.ResourceOwner},
+		"zitadel": map[string]any{
+			"aggregate": map[string]any{"type": record.AggregateType, "id": record.AggregateID},
+			"payload":   record.Payload,
+		},
+	}
+	user := make(map[string]any)
+	related := make([]string, 0, 2)
+	if record.Actor.UserID != "" {
+		user["id"] = record.Actor.UserID
+		user["name"] = record.Actor.DisplayName
+		related = append(related, record.Actor.UserID)
+	}
+	if record.AggregateType == "user" {
+		user["target"] = map[string]any{"id": record.AggregateID}
+		related = append(related, record.AggregateID)
+	}
+	if len(user) > 0 {
+		doc["user"] = user
+		doc["related"] = map[string]any{"user": related}
+	}
+	if record.Actor.Service != "" {
+		doc["service"] = map[string]any{"name": record.Actor.Service}
+	}
+	return json.Marshal(doc)
+}
+
+func ecsCategory(record *Record) string {
+	switch {
+	case record.AggregateType == "session", strings.Contains(record.Type, ".password.check"),
+		strings.Contains(record.Type, ".mfa."), strings.Contains(record.Type, ".idp.check"):
+		return "authentication"
+	case strings.Contains(record.Type, ".policy."), strings.Contains(record.Type, ".settings."):
+		return "configuration"
+	default:
+		return "iam"
+	}
+}
+
+func ecsType(eventType string) string {
+	switch {
+	case strings.HasSuffix(eventType, ".added"), strings.HasSuffix(eventType, ".created"):
+		return "creation"
+	case strings.HasSuffix(eventType, ".removed"), strings.HasSuffix(eventType, ".deleted"):
+		return "deletion"
+	case strings.HasSuffix(eventType, ".changed"), strings.HasSuffix(eventType, ".set"):
+		return "change"
+	default:
+		return "info"
+	}
+}
+
+// CEFOption allows customization of the CEF [Formatter].
+type CEFOption func(*cefFormatter)
+
+type cefFormatter struct {
+	vendor, product, version string
+	severity                 func(record *Record) int
+}
+
+// WithCEFProduct sets the device vendor, product and version of the CEF header (default `ZITADEL|ZITADEL|`).
+func WithCEFProduct(vendor, product, version string) CEFOption {
+	return func(f *cefFormatter) {
+		f.vendor, f.product, f.version = vendor, product, version
+	}
+}
+
+// WithCEFSeverity sets the function determining the severity (0-10) of a record.
+// By default, removals are of severity 5 and all other events 3.
+func WithCEFSeverity(severity func(record *Record) int) CEFOption {
+	return func(f *cefFormatter) {
+		f.severity = severity
+	}
+}
+
+// NewCEFFormatter creates a [Formatter] serializing the [Record] in the ArcSight Common Event Format (CEF),
+// e.g. to be sent as syslog message.
+func NewCEFFormatter(options ...CEFOption) Formatter {
+	f := &cefFormatter{
+		vendor:  "ZITADEL",
+		product: "ZITADEL",
+		severity: func(record *Record) int {
+			if ecsType(record.Type) == "deletion" {
+				return 5
+			}
+			return 3
+		},
+	}
+	for _, option := range options {
+		option(f)
+	}
+	return f.format
+}
+
+func (f *cefFormatter) format(record *Record) ([]byte, error) {
+	extensions := map[string]string{
+		"rt":         strconv.FormatInt(record.Time.UnixMilli(), 10),
+		"externalId": record.ID,
+		"act":        record.Type,
+		"suid":       record.Actor.UserID,
+		"suser":      record.Actor.DisplayName,
+		"cs1Label":   "aggregateType",
+		"cs1":        record.AggregateType,
+		"cs2Label":   "aggregateId",
+		"cs2":        record.AggregateID,
+		"cs3Label":   "resourceOwner",
+		"cs3":        record.ResourceOwner,
+		"cn1Label":   "sequence",
+		"cn1":        strconv.FormatUint(record.Sequence, 10),
+	}
+	if record.AggregateType == "user" {
+		extensions["duid"] = record.AggregateID
+	}
+	if len(record.Payload) > 0 {
+		payload, err := json.Marshal(record.Payload)
+		if err != nil {
+			return nil, err
+		}
+		extensions["msg"] = string(payload)
+	}
+	keys := make([]string, 0, len(extensions))
+	for key, value := range extensions {
+		if value != "" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	ext := make([]string, len(keys))
+	for i, key := range keys {
+		ext[i] = key + "=" + cefExtensionEscaper.Replace(extensions[key])
+	}
+	return []byte(fmt.Sprintf("CEF:0|%s|%s|%s|%s|%s|%d|%s",
+		cefHeaderEscaper.Replace(f.vendor),
+		cefHeaderEscaper.Replace(f.product),
+		cefHeaderEscaper.Replace(f.version),
+		cefHeaderEscaper.Replace(record.Type),
+		cefHeaderEscaper.Replace(record.Type),
+		f.severity(record),
+		strings.Join(ext, " "),
+	)), nil
+}
+
+var (
+	cefHeaderEscaper    = strings.NewReplacer(`\`, `\\`, `|`, `\|`, "\n", " ", "\r", " ")
+	cefExtensionEscaper = strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\n", `\n`, "\r", `\r`)
+)
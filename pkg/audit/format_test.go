@@ -0,0 +1,61 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testRecord = &Record{
+	ID:            "user:user1:3:2024-06-01T12:00:00Z",
+	Time:          time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC),
+	Type:          "user.human.email.changed",
+	AggregateType: "user",
+	AggregateID:   "user1",
+	ResourceOwner: "org1",
+	Sequence:      3,
+	Actor:         Actor{UserID: "admin1", DisplayName: "Admin | Ops"},
+	Payload:       map[string]any{"email": "a=b@zitadel.com"},
+}
+
+func TestFormatECS(t *testing.T) {
+	data, err := FormatECS(testRecord)
+	require.NoError(t, err)
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal(data, &doc))
+	assert.Equal(t, "2024-06-01T12:00:00Z", doc["@timestamp"])
+	event := doc["event"].(map[string]any)
+	assert.Equal(t, "user.human.email.changed", event["action"])
+	assert.Equal(t, []any{"iam"}, event["category"])
+	assert.Equal(t, []any{"change"}, event["type"])
+	user := doc["user"].(map[string]any)
+	assert.Equal(t, "admin1", user["id"])
+	assert.Equal(t, map[string]any{"id": "user1"}, user["target"])
+	assert.Equal(t, map[string]any{"id": "org1"}, doc["organization"])
+}
+
+func TestNewCEFFormatter(t *testing.T) {
+	data, err := NewCEFFormatter()(testRecord)
+	require.NoError(t, err)
+	assert.Equal(t,
+		`CEF:0|ZITADEL|ZITADEL||user.human.email.changed|user.human.email.changed|3|`+
+			`act=user.human.email.changed cn1=3 cn1Label=sequence cs1=user cs1Label=aggregateType cs2=user1 cs2Label=aggregateId `+
+			`cs3=org1 cs3Label=resourceOwner duid=user1 externalId=user:user1:3:2024-06-01T12:00:00Z `+
+			`msg={"email":"a\=b@zitadel.com"} rt=1717243200000 suid=admin1 suser=Admin | Ops`,
+		string(data),
+	)
+}
+
+func TestWriterSink(t *testing.T) {
+	buf := new(bytes.Buffer)
+	sink := NewWriterSink(buf, WithFormatter(NewCEFFormatter(WithCEFProduct("Acme", "IAM|Prod", "2"))))
+	require.NoError(t, sink.Write(context.Background(), []*Record{testRecord, testRecord}))
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	require.Len(t, lines, 2)
+	assert.True(t, bytes.HasPrefix(lines[0], []byte(`CEF:0|Acme|IAM\|Prod|2|`)))
+}
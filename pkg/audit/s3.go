@@ -0,0 +1,250 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	s3Algorithm  = "AWS4-HMAC-SHA256"
+	s3Service    = "s3"
+	s3DateFormat = "20060102T150405Z"
+)
+
+var ErrMissingS3Config = errors.New("endpoint, bucket and credentials are required")
+
+// S3Config configures the [S3Sink].
+type S3Config struct {
+	// Endpoint is the URL of the S3 compatible storage, e.g. `https://s3.eu-central-1.amazonaws.com`
+	// or `http://localhost:9000` for MinIO.
+	Endpoint string
+	// Region is used for signing the requests (default `us-east-1`).
+	Region string
+	Bucket string
+	// Prefix is prepended to the keys of the objects, e.g. `zitadel/audit`.
+	Prefix          string
+	AccessKeyID     string
+	SecretAccessKey string
+	// SessionToken is required for temporary credentials.
+	SessionToken string
+	// VirtualHostedStyle addresses the bucket as subdomain of the endpoint (`https://<bucket>.<host>`)
+	// instead of the path (`https://<host>/<bucket>`), which is supported by most S3 compatible storages.
+	VirtualHostedStyle bool
+}
+
+// S3Sink implements [Sink] by storing the records of each write as an object of JSON lines
+// in an S3 compatible storage, e.g. for long-term retention in a bucket with object lock.
+//
+// The key of an object consists of the date of the first record and a hash of the record IDs
+// (`<prefix>/2024/06/01/<time of first record>-<hash>.jsonl`), so records written again
+// after resuming an export overwrite the previous object instead of duplicating it.
+type S3Sink struct {
+	config     S3Config
+	endpoint   *url.URL
+	formatter  Formatter
+	httpClient *http.Client
+	now        func() time.Time
+}
+
+// S3Option allows customization of the [S3Sink].
+type S3Option func(*S3Sink)
+
+// WithS3Formatter sets the format of the records (default [FormatJSON]).
+func WithS3Formatter(formatter Formatter) S3Option {
+	return func(s *S3Sink) {
+		s.formatter = formatter
+	}
+}
+
+// WithS3HTTPClient sets the client used to call the storage (default [http.DefaultClient]).
+func WithS3HTTPClient(client *http.Client) S3Option {
+	return func(s *S3Sink) {
+		s.httpClient = client
+	}
+}
+
+// NewS3Sink creates an [S3Sink] for the config.
+func NewS3Sink(config S3Config, options ...S3Option) (*S3Sink, error) {
+	if config.Endpoint == "" || config.Bucket == "" || config.AccessKeyID == "" || config.SecretAccessKey == "" {
+		return nil, ErrMissingS3Config
+	}
+	endpoint, err := url.Parse(config.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if config.Region == "" {
+		config.Region = "us-east-1"
+	}
+	s := &S3Sink{
+		config:     config,
+		endpoint:   endpoint,
+		formatter:  FormatJSON,
+		httpClient: http.DefaultClient,
+		now:        time.Now,
+	}
+	for _, option := range options {
+		option(s)
+	}
+	return s, nil
+}
+
+// Write implements [Sink]
+func (s *S3Sink) Write(ctx context.Context, records []*Record) error {
+	if len(records) == 0 {
+		return nil
+	}
+	body, err := formatLines(records, s.formatter)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.objectURL(objectKey(s.config.Prefix, records)), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	signS3Request(req, body, s.config, s.now())
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+		return fmt.Errorf("storage responded with %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
+
+func (s *S3Sink) objectURL(key string) string {
+	u := *s.endpoint
+	if s.config.VirtualHostedStyle {
+		u.Host = s.config.Bucket + "." + u.Host
+		u.Path = "/" + key
+	} else {
+		u.Path = "/" + s.config.Bucket + "/" + key
+	}
+	return u.String()
+}
+
+// objectKey returns the key of the object storing the records.
+func objectKey(prefix string, records []*Record) string {
+	hash := sha256.New()
+	for _, record := range records {
+		hash.Write([]byte(record.ID))
+		hash.Write([]byte{'\n'})
+	}
+	first := records[0].Time.UTC()
+	name := first.Format("20060102T150405.000000000Z") + "-" + hex.EncodeToString(hash.Sum(nil))[:16] + ".jsonl"
+	return strings.TrimPrefix(path.Join(prefix, first.Format("2006/01/02"), name), "/")
+}
+
+// signS3Request signs the request using the AWS Signature Version 4, all headers of the request are signed.
+func signS3Request(req *http.Request, body []byte, config S3Config, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format(s3DateFormat)
+	payloadHash := sha256Hex(body)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if config.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", config.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for key, values := range req.Header {
+		headers[strings.ToLower(key)] = strings.Join(values, ",")
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(headers[name]) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		s3EscapePath(req.URL.Path),
+		s3CanonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	scope := now.Format("20060102") + "/" + config.Region + "/" + s3Service + "/aws4_request"
+	stringToSign := s3Algorithm + "\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+config.SecretAccessKey), now.Format("20060102"))
+	key = hmacSHA256(key, config.Region)
+	key = hmacSHA256(key, s3Service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3Algorithm, config.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func s3CanonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	params := make([]string, 0, len(keys))
+	for _, key := range keys {
+		values := slices.Clone(query[key])
+		slices.Sort(values)
+		for _, value := range values {
+			params = append(params, s3Escape(key, true)+"="+s3Escape(value, true))
+		}
+	}
+	return strings.Join(params, "&")
+}
+
+func s3EscapePath(p string) string {
+	if p == "" {
+		return "/"
+	}
+	return s3Escape(p, false)
+}
+
+// s3Escape encodes all characters except the unreserved ones (and the slash for paths) as required by S3.
+func s3Escape(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9', c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
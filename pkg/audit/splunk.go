@@ -0,0 +1,163 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+)
+
+// splunkEventPath is the path of the event endpoint of the HTTP Event Collector.
+const splunkEventPath = "/services/collector/event"
+
+// SplunkHECSink implements [Sink] by sending the records of each write as batch of events
+// to the HTTP Event Collector (HEC) of Splunk.
+type SplunkHECSink struct {
+	url        string
+	token      string
+	index      string
+	source     string
+	sourceType string
+	host       string
+	formatter  Formatter
+	httpClient *http.Client
+}
+
+// SplunkOption allows customization of the [SplunkHECSink].
+type SplunkOption func(*SplunkHECSink)
+
+// WithSplunkIndex sets the index of the events (default: index of the token).
+func WithSplunkIndex(index string) SplunkOption {
+	return func(s *SplunkHECSink) {
+		s.index = index
+	}
+}
+
+// WithSplunkSource sets the source of the events (default `zitadel`).
+func WithSplunkSource(source string) SplunkOption {
+	return func(s *SplunkHECSink) {
+		s.source = source
+	}
+}
+
+// WithSplunkSourceType sets the sourcetype of the events (default `zitadel:audit`).
+func WithSplunkSourceType(sourceType string) SplunkOption {
+	return func(s *SplunkHECSink) {
+		s.sourceType = sourceType
+	}
+}
+
+// WithSplunkHost sets the host of the events (default hostname of the machine).
+func WithSplunkHost(host string) SplunkOption {
+	return func(s *SplunkHECSink) {
+		s.host = host
+	}
+}
+
+// WithSplunkFormatter sets the format of the records (default [FormatJSON]), e.g. [FormatECS].
+// JSON is sent as event object, other formats (e.g. [NewCEFFormatter]) as string.
+func WithSplunkFormatter(formatter Formatter) SplunkOption {
+	return func(s *SplunkHECSink) {
+		s.formatter = formatter
+	}
+}
+
+// WithSplunkHTTPClient sets the client used to call the HEC (default [http.DefaultClient]).
+func WithSplunkHTTPClient(client *http.Client) SplunkOption {
+	return func(s *SplunkHECSink) {
+		s.httpClient = client
+	}
+}
+
+// NewSplunkHECSink creates a [SplunkHECSink] sending to the HEC at the url (e.g. `https://splunk:8088`)
+// authenticated by the token. The event endpoint is used if the url has no path.
+func NewSplunkHECSink(hecURL, token string, options ...SplunkOption) (*SplunkHECSink, error) {
+	u, err := url.Parse(hecURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Path == "" || u.Path == "/" {
+		u.Path = splunkEventPath
+	}
+	host, _ := os.Hostname()
+	s := &SplunkHECSink{
+		url:        u.String(),
+		token:      token,
+		source:     "zitadel",
+		sourceType: "zitadel:audit",
+		host:       host,
+		formatter:  FormatJSON,
+		httpClient: http.DefaultClient,
+	}
+	for _, option := range options {
+		option(s)
+	}
+	return s, nil
+}
+
+type splunkEvent struct {
+	Time       float64 `json:"time"`
+	Host       string  `json:"host,omitempty"`
+	Source     string  `json:"source,omitempty"`
+	SourceType string  `json:"sourcetype,omitempty"`
+	Index      string  `json:"index,omitempty"`
+	Event      any     `json:"event"`
+}
+
+// Write implements [Sink]
+func (s *SplunkHECSink) Write(ctx context.Context, records []*Record) error {
+	if len(records) == 0 {
+		return nil
+	}
+	body, err := s.batch(records)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Splunk "+s.token)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+		return fmt.Errorf("HEC responded with %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
+
+// batch serializes the records as concatenated HEC events.
+func (s *SplunkHECSink) batch(records []*Record) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, record := range records {
+		formatted, err := s.formatter(record)
+		if err != nil {
+			return nil, err
+		}
+		var event any = string(formatted)
+		if json.Valid(formatted) {
+			event = json.RawMessage(formatted)
+		}
+		if err = encoder.Encode(splunkEvent{
+			Time:       float64(record.Time.UnixMilli()) / 1000,
+			Host:       s.host,
+			Source:     s.source,
+			SourceType: s.sourceType,
+			Index:      s.index,
+			Event:      event,
+		}); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
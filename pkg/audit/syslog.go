@@ -0,0 +1,179 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// FacilityAuthPriv is the syslog facility for security and authorization messages.
+	FacilityAuthPriv = 10
+	// FacilityLocal0 is the first syslog facility for local use.
+	FacilityLocal0 = 16
+
+	syslogSeverityNotice = 5
+	syslogDialTimeout    = 10 * time.Second
+	// syslogTimeFormat is RFC 3339 with at most 6 fractional digits as required by RFC 5424
+	syslogTimeFormat = "2006-01-02T15:04:05.000000Z07:00"
+)
+
+// SyslogSink implements [Sink] by sending every record as RFC 5424 syslog message to a SIEM,
+// by default formatted as CEF (see [NewCEFFormatter]).
+// Messages are sent as datagrams over `udp` or newline delimited over `tcp` (optionally with TLS),
+// the connection is established on the first write and again after a failed write.
+type SyslogSink struct {
+	network   string
+	address   string
+	tlsConfig *tls.Config
+	formatter Formatter
+	facility  int
+	hostname  string
+	appName   string
+	dial      func(ctx context.Context) (net.Conn, error)
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// SyslogOption allows customization of the [SyslogSink].
+type SyslogOption func(*SyslogSink)
+
+// WithSyslogFormatter sets the format of the records (default CEF, see [NewCEFFormatter]).
+func WithSyslogFormatter(formatter Formatter) SyslogOption {
+	return func(s *SyslogSink) {
+		s.formatter = formatter
+	}
+}
+
+// WithSyslogTLS sends the messages over TLS, the network must be `tcp`.
+func WithSyslogTLS(config *tls.Config) SyslogOption {
+	return func(s *SyslogSink) {
+		s.tlsConfig = config
+	}
+}
+
+// WithSyslogFacility sets the facility of the messages (default [FacilityAuthPriv]).
+func WithSyslogFacility(facility int) SyslogOption {
+	return func(s *SyslogSink) {
+		s.facility = facility
+	}
+}
+
+// WithSyslogHostname sets the hostname of the messages (default hostname of the machine).
+func WithSyslogHostname(hostname string) SyslogOption {
+	return func(s *SyslogSink) {
+		s.hostname = hostname
+	}
+}
+
+// WithSyslogAppName sets the app name of the messages (default `zitadel`).
+func WithSyslogAppName(appName string) SyslogOption {
+	return func(s *SyslogSink) {
+		s.appName = appName
+	}
+}
+
+// NewSyslogSink creates a [SyslogSink] sending to the address over the network (`udp` or `tcp`).
+func NewSyslogSink(network, address string, options ...SyslogOption) *SyslogSink {
+	hostname, _ := os.Hostname()
+	s := &SyslogSink{
+		network:   network,
+		address:   address,
+		formatter: NewCEFFormatter(),
+		facility:  FacilityAuthPriv,
+		hostname:  hostname,
+		appName:   "zitadel",
+	}
+	for _, option := range options {
+		option(s)
+	}
+	s.dial = func(ctx context.Context) (net.Conn, error) {
+		dialer := &net.Dialer{Timeout: syslogDialTimeout}
+		if s.tlsConfig != nil {
+			return (&tls.Dialer{NetDialer: dialer, Config: s.tlsConfig}).DialContext(ctx, s.network, s.address)
+		}
+		return dialer.DialContext(ctx, s.network, s.address)
+	}
+	return s
+}
+
+// Write implements [Sink]
+func (s *SyslogSink) Write(ctx context.Context, records []*Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		conn, err := s.dial(ctx)
+		if err != nil {
+			return err
+		}
+		s.conn = conn
+	}
+	for _, record := range records {
+		msg, err := s.message(record)
+		if err != nil {
+			return err
+		}
+		if deadline, ok := ctx.Deadline(); ok {
+			_ = s.conn.SetWriteDeadline(deadline)
+		}
+		if _, err = s.conn.Write(msg); err != nil {
+			s.conn.Close()
+			s.conn = nil
+			return err
+		}
+	}
+	return nil
+}
+
+// Close closes the connection to the SIEM.
+func (s *SyslogSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+// message formats the record as RFC 5424 message: `<PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID SD MSG`.
+func (s *SyslogSink) message(record *Record) ([]byte, error) {
+	body, err := s.formatter(record)
+	if err != nil {
+		return nil, err
+	}
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "<%d>1 %s %s %s - %s - ",
+		s.facility*8+syslogSeverityNotice,
+		record.Time.UTC().Format(syslogTimeFormat),
+		syslogField(s.hostname, 255),
+		syslogField(s.appName, 48),
+		syslogField(record.Type, 32),
+	)
+	// newlines would split the message on stream connections
+	msg.Write(bytes.ReplaceAll(body, []byte("\n"), []byte(" ")))
+	if s.network != "udp" {
+		msg.WriteByte('\n')
+	}
+	return msg.Bytes(), nil
+}
+
+// syslogField returns the value as header field, which must not be empty, contain spaces or exceed the length.
+func syslogField(value string, maxLen int) string {
+	if value == "" {
+		return "-"
+	}
+	value = strings.ReplaceAll(value, " ", "_")
+	if len(value) > maxLen {
+		return value[:maxLen]
+	}
+	return value
+}
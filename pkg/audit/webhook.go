@@ -0,0 +1,115 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/webhook"
+)
+
+const maxResponseBody = 1 << 10
+
+// WebhookSink implements [Sink] by posting the records of each write as JSON lines to a webhook,
+// e.g. the HTTP collector of a log platform.
+type WebhookSink struct {
+	url        string
+	headers    http.Header
+	formatter  Formatter
+	signingKey string
+	httpClient *http.Client
+	now        func() time.Time
+}
+
+// WebhookOption allows customization of the [WebhookSink].
+type WebhookOption func(*WebhookSink)
+
+// WithWebhookHeader adds a header to every request, e.g. for authorization of the receiver.
+func WithWebhookHeader(key, value string) WebhookOption {
+	return func(s *WebhookSink) {
+		s.headers.Add(key, value)
+	}
+}
+
+// WithWebhookFormatter sets the format of the records (default [FormatJSON]).
+func WithWebhookFormatter(formatter Formatter) WebhookOption {
+	return func(s *WebhookSink) {
+		s.formatter = formatter
+	}
+}
+
+// WithWebhookSigningKey signs the body of every request with the key in the [webhook.SignatureHeader],
+// so the receiver can verify it using [webhook.VerifySignature].
+func WithWebhookSigningKey(key string) WebhookOption {
+	return func(s *WebhookSink) {
+		s.signingKey = key
+	}
+}
+
+// WithWebhookHTTPClient sets the client used to call the webhook (default [http.DefaultClient]).
+func WithWebhookHTTPClient(client *http.Client) WebhookOption {
+	return func(s *WebhookSink) {
+		s.httpClient = client
+	}
+}
+
+// NewWebhookSink creates a [WebhookSink] posting to the url.
+func NewWebhookSink(url string, options ...WebhookOption) *WebhookSink {
+	s := &WebhookSink{
+		url:        url,
+		headers:    make(http.Header),
+		formatter:  FormatJSON,
+		httpClient: http.DefaultClient,
+		now:        time.Now,
+	}
+	for _, option := range options {
+		option(s)
+	}
+	return s
+}
+
+// Write implements [Sink]
+func (s *WebhookSink) Write(ctx context.Context, records []*Record) error {
+	body, err := formatLines(records, s.formatter)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for key, values := range s.headers {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	if s.signingKey != "" {
+		req.Header.Set(webhook.SignatureHeader, webhook.Sign(body, s.signingKey, s.now()))
+	}
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+		return fmt.Errorf("webhook responded with %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
+
+// formatLines serializes the records as lines.
+func formatLines(records []*Record, formatter Formatter) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, record := range records {
+		line, err := formatter(record)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,52 @@
+package audit
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+// WriterSink implements [Sink] by writing every record as a line to an [io.Writer] (e.g. a file or stdout).
+type WriterSink struct {
+	mu        sync.Mutex
+	writer    io.Writer
+	formatter Formatter
+}
+
+// WriterOption allows customization of the [WriterSink].
+type WriterOption func(*WriterSink)
+
+// WithFormatter sets the format of the records (default [FormatJSON]), e.g. [FormatECS] or [NewCEFFormatter].
+func WithFormatter(formatter Formatter) WriterOption {
+	return func(s *WriterSink) {
+		s.formatter = formatter
+	}
+}
+
+// NewWriterSink creates a [WriterSink] writing JSON lines to the writer.
+func NewWriterSink(writer io.Writer, options ...WriterOption) *WriterSink {
+	s := &WriterSink{
+		writer:    writer,
+		formatter: FormatJSON,
+	}
+	for _, option := range options {
+		option(s)
+	}
+	return s
+}
+
+// Write implements [Sink]
+func (s *WriterSink) Write(_ context.Context, records []*Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, record := range records {
+		line, err := s.formatter(record)
+		if err != nil {
+			return err
+		}
+		if _, err = s.writer.Write(append(line, '\n')); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -28,39 +28,54 @@ type Ctx[C oidc.IDClaims, S rp.SubjectGetter] interface {
 // Use [WithCodeFlow] for implementation.
 type codeFlowAuthentication[T Ctx[C, S], C oidc.IDClaims, S rp.SubjectGetter] struct {
 	relyingParty rp.RelyingParty
+	config       codeFlowConfig
 }
 
 // WithCodeFlow creates the OIDC/OAuth2 Authorization Code Flow implementation of the [authentication.Handler] interface.
 // The token endpoint itself requires some [ClientAuthentication] of the client.
 // Possible implementation are [PKCEAuthentication] and [ClientIDSecretAuthentication].
-func WithCodeFlow[T Ctx[C, S], C oidc.IDClaims, S rp.SubjectGetter](auth ClientAuthentication) authentication.HandlerInitializer[T] {
+// The flow can be customized with [CodeFlowOption], e.g. [WithHooks] and [WithConformance]
+// to run the OpenID Connect RP certification suite.
+func WithCodeFlow[T Ctx[C, S], C oidc.IDClaims, S rp.SubjectGetter](auth ClientAuthentication, opts ...CodeFlowOption) authentication.HandlerInitializer[T] {
 	return func(ctx context.Context, zitadel *zitadel.Zitadel) (authentication.Handler[T], error) {
 		relyingParty, err := auth(ctx, zitadel.Origin())
 		if err != nil {
 			return nil, err
 		}
-		return &codeFlowAuthentication[T, C, S]{
+		c := &codeFlowAuthentication[T, C, S]{
 			relyingParty: relyingParty,
-		}, nil
+		}
+		for _, opt := range opts {
+			opt(&c.config)
+		}
+		return c, nil
 	}
 }
 
 type ClientAuthentication func(ctx context.Context, domain string) (rp.RelyingParty, error)
 
 // PKCEAuthentication allows to authenticate the code exchange request with Proof Key of Code Exchange (PKCE).
-func PKCEAuthentication(clientID, redirectURI string, scopes []string, cookieHandler *httphelper.CookieHandler) ClientAuthentication {
+// Additional options of the relying party can be passed, e.g. [WithHTTPClient].
+func PKCEAuthentication(clientID, redirectURI string, scopes []string, cookieHandler *httphelper.CookieHandler, options ...rp.Option) ClientAuthentication {
 	return func(ctx context.Context, domain string) (rp.RelyingParty, error) {
-		return newRP(ctx, domain, clientID, "", redirectURI, scopes, rp.WithPKCE(cookieHandler))
+		return newRP(ctx, domain, clientID, "", redirectURI, scopes, append([]rp.Option{rp.WithPKCE(cookieHandler)}, options...)...)
 	}
 }
 
 // ClientIDSecretAuthentication allows to authenticate the code exchange request with client_id and client_secret provide by ZITADEL.
-func ClientIDSecretAuthentication(clientID, clientSecret, redirectURI string, scopes []string, cookieHandler *httphelper.CookieHandler) ClientAuthentication {
+// Additional options of the relying party can be passed, e.g. [WithHTTPClient].
+func ClientIDSecretAuthentication(clientID, clientSecret, redirectURI string, scopes []string, cookieHandler *httphelper.CookieHandler, options ...rp.Option) ClientAuthentication {
 	return func(ctx context.Context, domain string) (rp.RelyingParty, error) {
-		return newRP(ctx, domain, clientID, clientSecret, redirectURI, scopes, rp.WithCookieHandler(cookieHandler))
+		return newRP(ctx, domain, clientID, clientSecret, redirectURI, scopes, append([]rp.Option{rp.WithCookieHandler(cookieHandler)}, options...)...)
 	}
 }
 
+// WithHTTPClient allows to use a custom [http.Client] for the discovery, token and key requests,
+// e.g. one serving the discovery and keys from disk during an outage (see diskcache.NewClient).
+func WithHTTPClient(httpClient *http.Client) rp.Option {
+	return rp.WithHTTPClient(httpClient)
+}
+
 // DefaultAuthentication is a short version of [WithCodeFlow[*UserInfoContext[*oidc.IDTokenClaims, *oidc.UserInfo], *oidc.IDTokenClaims, *oidc.UserInfo]]
 // with the client_id, redirectURI and encryptionKey and optional scopes.
 // If no scopes are provided, `"openid", "profile", "email"` will be used.
@@ -82,18 +97,55 @@ func newRP(ctx context.Context, domain, clientID, clientSecret, redirectURI stri
 
 // Authenticate starts the OIDC/OAuth2 Authorization Code Flow and redirects the user to the Login UI.
 func (c *codeFlowAuthentication[T, C, S]) Authenticate(w http.ResponseWriter, r *http.Request, state string) {
-	rp.AuthURLHandler(func() string { return state }, c.relyingParty)(w, r)
+	params, err := c.config.authURLParams(w, c.relyingParty)
+	if err != nil {
+		http.Error(w, "failed to prepare auth request: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	rp.AuthURLHandler(func() string { return state }, c.relyingParty, params...)(w, r)
+	if c.config.hooks.OnAuthRequest != nil {
+		if location := w.Header().Get("Location"); location != "" {
+			c.config.hooks.OnAuthRequest(r, location)
+		}
+	}
 }
 
 // Callback handles the redirect back from the Login UI and will exchange the code for the tokens.
 // Additionally, it will retrieve the information from the userinfo_endpoint and store everything in the [Ctx].
 func (c *codeFlowAuthentication[T, C, S]) Callback(w http.ResponseWriter, r *http.Request) (authCtx T, state string) {
+	if err := c.config.verifyAuthResponse(r, c.relyingParty); err != nil {
+		c.config.onError(r, err)
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return authCtx, state
+	}
+	var called bool
+	recorder := &errorRecorder{ResponseWriter: w}
 	rp.CodeExchangeHandler[C](rp.UserinfoCallback[C, S](func(w http.ResponseWriter, r *http.Request, tokens *oidc.Tokens[C], callbackState string, provider rp.RelyingParty, info S) {
+		called = true
+		if err := c.config.verifyTokens(w, r, provider, tokens.IDTokenClaims); err != nil {
+			c.config.onError(r, err)
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
 		state = callbackState
 		authCtx = authCtx.New().(T)
 		authCtx.SetTokens(tokens)
 		authCtx.SetUserInfo(info)
-	}), c.relyingParty)(w, r)
+		if c.config.hooks.OnCallback != nil {
+			c.config.hooks.OnCallback(r, &CallbackArtifacts{
+				Query:         r.URL.Query(),
+				State:         callbackState,
+				AccessToken:   tokens.AccessToken,
+				RefreshToken:  tokens.RefreshToken,
+				IDToken:       tokens.IDToken,
+				IDTokenClaims: tokens.IDTokenClaims,
+				UserInfo:      info,
+			})
+		}
+	}), c.relyingParty)(recorder, r)
+	if !called {
+		c.config.onError(r, recorder.err())
+	}
 	return authCtx, state
 }
 
@@ -123,5 +175,8 @@ func (c *codeFlowAuthentication[T, C, S]) Logout(w http.ResponseWriter, r *http.
 		return
 	}
 	endSession.RawQuery = params.Encode()
+	if c.config.hooks.OnLogout != nil {
+		c.config.hooks.OnLogout(r, endSession.String())
+	}
 	http.Redirect(w, r, endSession.String(), http.StatusFound)
 }
@@ -0,0 +1,182 @@
+package oidc
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/zitadel/oidc/v3/pkg/client/rp"
+	"github.com/zitadel/oidc/v3/pkg/oidc"
+)
+
+const nonceCookieName = "nonce"
+
+var (
+	ErrNonceMismatch    = errors.New("nonce of the id_token does not match the one of the authorization request")
+	ErrIssuerMismatch   = errors.New("iss parameter of the authorization response does not match the issuer")
+	ErrMissingIssuer    = errors.New("iss parameter of the authorization response is missing")
+	ErrCodeFlowRejected = errors.New("authorization code flow rejected")
+)
+
+// Hooks expose the artifacts of the Authorization Code Flow, e.g. to record them
+// when running the OpenID Connect RP certification suite against an application.
+// All hooks are optional.
+type Hooks struct {
+	// OnAuthRequest is called with the URL the user is redirected to for authentication.
+	OnAuthRequest func(r *http.Request, authURL string)
+	// OnCallback is called after a successful code exchange and userinfo request.
+	OnCallback func(r *http.Request, artifacts *CallbackArtifacts)
+	// OnError is called if the callback was rejected, either by the RP library or by a [Conformance] check.
+	OnError func(r *http.Request, err error)
+	// OnLogout is called with the end_session_endpoint URL the user is redirected to.
+	OnLogout func(r *http.Request, endSessionURL string)
+}
+
+// CallbackArtifacts are the request and response artifacts of a successful callback.
+type CallbackArtifacts struct {
+	// Query is the query of the authorization response (redirect back from the OP).
+	Query url.Values
+	State string
+	// AccessToken, RefreshToken and IDToken are the raw tokens of the token response.
+	AccessToken   string
+	RefreshToken  string
+	IDToken       string
+	IDTokenClaims oidc.IDClaims
+	UserInfo      rp.SubjectGetter
+}
+
+// Conformance toggles strict spec behavior, which the default flow does not enforce.
+type Conformance struct {
+	// RequireNonce sends a random nonce with the authorization request and requires it in the id_token.
+	RequireNonce bool
+	// VerifyIssuerParameter verifies the `iss` parameter of the authorization response (RFC 9207) if present.
+	VerifyIssuerParameter bool
+	// RequireIssuerParameter additionally rejects authorization responses without the `iss` parameter.
+	RequireIssuerParameter bool
+}
+
+// StrictConformance returns the [Conformance] enabling all checks recommended for certification.
+// Note that RequireIssuerParameter is left disabled, since not every OP returns the `iss` parameter.
+func StrictConformance() Conformance {
+	return Conformance{
+		RequireNonce:          true,
+		VerifyIssuerParameter: true,
+	}
+}
+
+// CodeFlowOption allows customization of the Authorization Code Flow of [WithCodeFlow].
+type CodeFlowOption func(*codeFlowConfig)
+
+type codeFlowConfig struct {
+	hooks       Hooks
+	conformance Conformance
+}
+
+// WithHooks sets the [Hooks] called during the flow.
+func WithHooks(hooks Hooks) CodeFlowOption {
+	return func(c *codeFlowConfig) {
+		c.hooks = hooks
+	}
+}
+
+// WithConformance enables the strict spec checks of the [Conformance].
+func WithConformance(conformance Conformance) CodeFlowOption {
+	return func(c *codeFlowConfig) {
+		c.conformance = conformance
+	}
+}
+
+func (c *codeFlowConfig) authURLParams(w http.ResponseWriter, relyingParty rp.RelyingParty) ([]rp.URLParamOpt, error) {
+	if !c.conformance.RequireNonce {
+		return nil, nil
+	}
+	nonce, err := randomNonce()
+	if err != nil {
+		return nil, err
+	}
+	if err = relyingParty.CookieHandler().SetCookie(w, nonceCookieName, nonce); err != nil {
+		return nil, err
+	}
+	return []rp.URLParamOpt{rp.WithURLParam("nonce", nonce)}, nil
+}
+
+// verifyAuthResponse checks the authorization response before the code is exchanged.
+func (c *codeFlowConfig) verifyAuthResponse(r *http.Request, relyingParty rp.RelyingParty) error {
+	if !c.conformance.VerifyIssuerParameter && !c.conformance.RequireIssuerParameter {
+		return nil
+	}
+	iss := r.FormValue("iss")
+	if iss == "" {
+		if c.conformance.RequireIssuerParameter {
+			return ErrMissingIssuer
+		}
+		return nil
+	}
+	if iss != relyingParty.Issuer() {
+		return ErrIssuerMismatch
+	}
+	return nil
+}
+
+// verifyTokens checks the tokens after the code exchange.
+func (c *codeFlowConfig) verifyTokens(w http.ResponseWriter, r *http.Request, relyingParty rp.RelyingParty, claims oidc.IDClaims) error {
+	if !c.conformance.RequireNonce {
+		return nil
+	}
+	nonce, err := relyingParty.CookieHandler().CheckCookie(r, nonceCookieName)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrNonceMismatch, err)
+	}
+	relyingParty.CookieHandler().DeleteCookie(w, nonceCookieName)
+	if claims == nil || claims.GetNonce() != nonce {
+		return ErrNonceMismatch
+	}
+	return nil
+}
+
+func (c *codeFlowConfig) onError(r *http.Request, err error) {
+	if c.hooks.OnError != nil {
+		c.hooks.OnError(r, err)
+	}
+}
+
+func randomNonce() (string, error) {
+	nonce := make([]byte, 32)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(nonce), nil
+}
+
+// errorRecorder captures the response written by the RP library if the callback was rejected,
+// so it can be passed to [Hooks.OnError].
+type errorRecorder struct {
+	http.ResponseWriter
+	status int
+	body   strings.Builder
+}
+
+func (e *errorRecorder) WriteHeader(status int) {
+	if e.status == 0 {
+		e.status = status
+	}
+	e.ResponseWriter.WriteHeader(status)
+}
+
+func (e *errorRecorder) Write(b []byte) (int, error) {
+	if e.status == 0 {
+		e.status = http.StatusOK
+	}
+	if e.status >= http.StatusBadRequest && e.body.Len() < 1024 {
+		e.body.Write(b)
+	}
+	return e.ResponseWriter.Write(b)
+}
+
+func (e *errorRecorder) err() error {
+	return fmt.Errorf("%w: %d %s", ErrCodeFlowRejected, e.status, strings.TrimSpace(e.body.String()))
+}
@@ -0,0 +1,86 @@
+package oidc
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zitadel/oidc/v3/pkg/client/rp"
+	httphelper "github.com/zitadel/oidc/v3/pkg/http"
+	"github.com/zitadel/oidc/v3/pkg/oidc"
+	"golang.org/x/oauth2"
+)
+
+func testRelyingParty(t *testing.T) rp.RelyingParty {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	relyingParty, err := rp.NewRelyingPartyOAuth(&oauth2.Config{ClientID: "client"},
+		rp.WithCookieHandler(httphelper.NewCookieHandler(key, key, httphelper.WithUnsecure())),
+	)
+	require.NoError(t, err)
+	return relyingParty
+}
+
+func Test_codeFlowConfig_nonce(t *testing.T) {
+	relyingParty := testRelyingParty(t)
+	config := &codeFlowConfig{conformance: StrictConformance()}
+
+	w := httptest.NewRecorder()
+	params, err := config.authURLParams(w, relyingParty)
+	require.NoError(t, err)
+	require.Len(t, params, 1)
+
+	callback := func() *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "/callback", nil)
+		for _, cookie := range w.Result().Cookies() {
+			r.AddCookie(cookie)
+		}
+		return r
+	}
+	nonce, err := relyingParty.CookieHandler().CheckCookie(callback(), nonceCookieName)
+	require.NoError(t, err)
+	require.NotEmpty(t, nonce)
+	err = config.verifyTokens(httptest.NewRecorder(), callback(), relyingParty, &oidc.IDTokenClaims{TokenClaims: oidc.TokenClaims{Nonce: nonce}})
+	assert.NoError(t, err)
+	err = config.verifyTokens(httptest.NewRecorder(), callback(), relyingParty, &oidc.IDTokenClaims{TokenClaims: oidc.TokenClaims{Nonce: "other"}})
+	assert.ErrorIs(t, err, ErrNonceMismatch)
+	err = config.verifyTokens(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/callback", nil), relyingParty, &oidc.IDTokenClaims{})
+	assert.ErrorIs(t, err, ErrNonceMismatch)
+}
+
+func Test_codeFlowConfig_verifyAuthResponse(t *testing.T) {
+	tests := []struct {
+		name        string
+		conformance Conformance
+		query       string
+		wantErr     error
+	}{
+		{
+			name:  "disabled",
+			query: "iss=https://other.example.com",
+		},
+		{
+			name:        "missing iss allowed",
+			conformance: Conformance{VerifyIssuerParameter: true},
+		},
+		{
+			name:        "missing iss required",
+			conformance: Conformance{RequireIssuerParameter: true},
+			wantErr:     ErrMissingIssuer,
+		},
+		{
+			name:        "iss mismatch",
+			conformance: Conformance{VerifyIssuerParameter: true},
+			query:       "iss=https://other.example.com",
+			wantErr:     ErrIssuerMismatch,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &codeFlowConfig{conformance: tt.conformance}
+			r := httptest.NewRequest(http.MethodGet, "/callback?"+tt.query, nil)
+			assert.ErrorIs(t, config.verifyAuthResponse(r, testRelyingParty(t)), tt.wantErr)
+		})
+	}
+}
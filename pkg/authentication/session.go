@@ -14,6 +14,10 @@ type InMemorySessions[T Ctx] struct {
 	sessions map[string]T
 }
 
+func NewInMemorySessions[T Ctx]() *InMemorySessions[T] {
+	return &InMemorySessions[T]{sessions: make(map[string]T)}
+}
+
 func (s *InMemorySessions[T]) Get(id string) (T, error) {
 	t, ok := s.sessions[id]
 	if !ok {
@@ -23,6 +23,7 @@ var (
 type Authorizer[T Ctx] struct {
 	verifier Verifier[T]
 	logger   *slog.Logger
+	fallback *fallbackCache[T]
 }
 
 // Option allows customization of the [Authorizer] such as caching, logging and more.
@@ -54,29 +55,72 @@ func New[T Ctx](ctx context.Context, zitadel *zitadel.Zitadel, initVerifier Veri
 
 // CheckAuthorization will verify the token using the configured [Verifier] and provided [Check]
 func (a *Authorizer[T]) CheckAuthorization(ctx context.Context, token string, options ...CheckOption) (authCtx T, err error) {
+	authCtx, _, err = a.check(ctx, token, options...)
+	return authCtx, err
+}
+
+// Authorize verifies the token like [Authorizer.CheckAuthorization] and returns a context containing the
+// authorization context (see [Context]) and the degraded flag (see [IsDegraded]).
+func (a *Authorizer[T]) Authorize(ctx context.Context, token string, options ...CheckOption) (context.Context, T, error) {
+	authCtx, degraded, err := a.check(ctx, token, options...)
+	if err != nil {
+		return ctx, authCtx, err
+	}
+	if degraded {
+		ctx = withDegraded(ctx)
+	}
+	return WithAuthContext(ctx, authCtx), authCtx, nil
+}
+
+func (a *Authorizer[T]) check(ctx context.Context, token string, options ...CheckOption) (authCtx T, degraded bool, err error) {
 	a.logger.Log(ctx, slog.LevelDebug, "checking authorization")
 	var t T
 	if token == "" {
 		a.logger.Log(ctx, slog.LevelWarn, "no authorization header")
-		return t, NewErrorUnauthorized(ErrEmptyAuthorizationHeader)
+		return t, false, NewErrorUnauthorized(ErrEmptyAuthorizationHeader)
 	}
 	checks := new(Check[Ctx])
 	for _, option := range options {
 		option(checks)
 	}
-	authCtx, err = a.verifier.CheckAuthorization(ctx, token)
+	authCtx, degraded, err = a.verify(ctx, token)
 	if err != nil || !authCtx.IsAuthorized() {
 		a.logger.With("error", err).Log(ctx, slog.LevelWarn, "unauthorized")
-		return t, NewErrorUnauthorized(err)
+		return t, false, NewErrorUnauthorized(err)
 	}
 	for _, c := range checks.Checks {
 		if err = c(authCtx); err != nil {
 			a.logger.With("error", err, "user", authCtx.UserID()).Log(ctx, slog.LevelWarn, "permission denied")
-			return t, NewErrorPermissionDenied(err)
+			return t, false, NewErrorPermissionDenied(err)
 		}
 	}
 	authCtx.SetToken(token)
-	return authCtx, nil
+	return authCtx, degraded, nil
+}
+
+// verify calls the [Verifier] and falls back to the cached decision if configured (see [WithFallbackCache]).
+func (a *Authorizer[T]) verify(ctx context.Context, token string) (authCtx T, degraded bool, err error) {
+	authCtx, err = a.verifier.CheckAuthorization(ctx, token)
+	if a.fallback == nil {
+		return authCtx, false, err
+	}
+	if err == nil {
+		if authCtx.IsAuthorized() {
+			a.fallback.set(token, authCtx)
+		} else {
+			a.fallback.delete(token)
+		}
+		return authCtx, false, nil
+	}
+	if !isUnavailable(err) {
+		return authCtx, false, err
+	}
+	cached, ok := a.fallback.get(ctx, token)
+	if !ok {
+		return authCtx, false, err
+	}
+	a.logger.With("error", err, "user", cached.UserID()).Log(ctx, slog.LevelWarn, "verification failed, serving cached authorization")
+	return cached, true, nil
 }
 
 // Verifier defines the possible verification checks such as validation of the authorizationToken.
@@ -0,0 +1,24 @@
+// Package chi provides the authorization middleware for the chi router (github.com/go-chi/chi).
+// Since chi is built on net/http, the middleware can be used with `Use`, `With` and `Group`
+// as well as with any other net/http router.
+package chi
+
+import (
+	"net/http"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/authorization"
+	"github.com/zitadel/zitadel-go/v3/pkg/http/middleware"
+)
+
+// Middleware requires a valid authorization passing the provided checks (e.g. [authorization.WithRole]),
+// e.g. `r.With(chi.Middleware(authZ, authorization.WithRole("admin"))).Get("/admin", handler)`.
+// It responds with 401 Unauthorized for missing or invalid tokens and 403 Forbidden for failed checks.
+// The authorization context can be retrieved in the handler using [Context].
+func Middleware[T authorization.Ctx](authorizer *authorization.Authorizer[T], options ...authorization.CheckOption) func(next http.Handler) http.Handler {
+	return middleware.New(authorizer).RequireAuthorization(options...)
+}
+
+// Context returns the authorization context of the request.
+func Context[T authorization.Ctx](r *http.Request) T {
+	return authorization.Context[T](r.Context())
+}
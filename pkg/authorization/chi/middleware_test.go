@@ -0,0 +1,70 @@
+package chi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zitadel/oidc/v3/pkg/oidc"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/authorization"
+	"github.com/zitadel/zitadel-go/v3/pkg/authorization/oauth"
+	"github.com/zitadel/zitadel-go/v3/pkg/zitadel"
+)
+
+func TestMiddleware(t *testing.T) {
+	tests := []struct {
+		name       string
+		token      string
+		wantStatus int
+		wantBody   string
+	}{
+		{"missing token", "", http.StatusUnauthorized, ""},
+		{"missing role", "Bearer viewer", http.StatusForbidden, ""},
+		{"authorized", "Bearer admin", http.StatusOK, "user1"},
+	}
+	handler := Middleware(testAuthorizer(t), authorization.WithRole("admin"))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(Context[*oauth.IntrospectionContext](r).UserID()))
+	}))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+			if tt.token != "" {
+				req.Header.Set(authorization.HeaderName, tt.token)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			if tt.wantBody != "" {
+				assert.Equal(t, tt.wantBody, rec.Body.String())
+			}
+		})
+	}
+}
+
+// testAuthorizer grants the role of the token (e.g. `Bearer admin`) to user1.
+func testAuthorizer(t *testing.T) *authorization.Authorizer[*oauth.IntrospectionContext] {
+	authorizer, err := authorization.New[*oauth.IntrospectionContext](context.Background(), zitadel.New("zitadel.cloud"),
+		func(context.Context, *zitadel.Zitadel) (authorization.Verifier[*oauth.IntrospectionContext], error) {
+			return testVerifier{}, nil
+		},
+	)
+	require.NoError(t, err)
+	return authorizer
+}
+
+type testVerifier struct{}
+
+func (testVerifier) CheckAuthorization(_ context.Context, token string) (*oauth.IntrospectionContext, error) {
+	return &oauth.IntrospectionContext{IntrospectionResponse: oidc.IntrospectionResponse{
+		Active:  true,
+		Subject: "user1",
+		Claims: map[string]any{
+			"urn:zitadel:iam:org:project:roles": map[string]any{strings.TrimPrefix(token, "Bearer "): map[string]any{"org1": "zitadel.cloud"}},
+		},
+	}}, nil
+}
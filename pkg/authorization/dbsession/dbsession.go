@@ -0,0 +1,296 @@
+// Package dbsession stamps the authorized ZITADEL user and organization of the [authorization.Ctx]
+// into database session variables at the start of a transaction.
+// This allows row-level security policies keyed on the identity, e.g. for PostgreSQL:
+//
+//	CREATE POLICY tenant_isolation ON documents
+//	    USING (org_id = current_setting('zitadel.org_id'));
+//
+// The variables must be stamped on a transaction (e.g. [*sql.Tx], and therefore sqlc or ORMs on top of it),
+// never on the pool ([*sql.DB]), whose statements run on arbitrary connections.
+// With MySQL, the variables outlive the transaction on its connection, use [Stamper.InTx] to reset them.
+package dbsession
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/authorization"
+)
+
+var (
+	ErrNotAuthorized    = errors.New("no authorized user in context")
+	ErrNotTransaction   = errors.New("variables must be stamped on a transaction, not on the connection pool")
+	ErrConnectionScoped = errors.New("variables of the dialect are bound to the connection, use InTx to reset them")
+	ErrInvalidVariable  = errors.New("invalid variable name")
+)
+
+// Default names of the variables set by the [Stamper].
+const (
+	VariableUserID         = "zitadel.user_id"
+	VariableOrganizationID = "zitadel.org_id"
+)
+
+// Execer executes a statement in a transaction, it's implemented by [*sql.Tx].
+// For other drivers (e.g. pgx), a small adapter of their transaction is enough.
+type Execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// Variable is a session variable and its value.
+type Variable struct {
+	Name  string
+	Value string
+}
+
+// Dialect creates the statements setting the variables in the database.
+// An error is returned for variables the dialect can't set, e.g. names which can't be passed as argument.
+type Dialect interface {
+	Statement(variables []Variable) (query string, args []any, err error)
+	// Reset returns the statement resetting the variables after the transaction,
+	// empty if they are scoped to the transaction.
+	Reset(variables []Variable) (query string, args []any, err error)
+}
+
+// Postgres sets the variables by `set_config` scoped to the current transaction,
+// so they are reset on commit or rollback and can't leak to other requests using the same pooled connection.
+// They can be read using `current_setting('zitadel.user_id')`.
+var Postgres Dialect = postgres{}
+
+type postgres struct{}
+
+func (postgres) Reset([]Variable) (string, []any, error) {
+	return "", nil, nil
+}
+
+func (postgres) Statement(variables []Variable) (string, []any, error) {
+	calls := make([]string, len(variables))
+	args := make([]any, 0, len(variables)*2)
+	for i, variable := range variables {
+		calls[i] = fmt.Sprintf("set_config($%d, $%d, true)", 2*i+1, 2*i+2)
+		args = append(args, variable.Name, variable.Value)
+	}
+	return "SELECT " + strings.Join(calls, ", "), args, nil
+}
+
+// MySQL sets the variables as user-defined variables (dots are replaced by underscores,
+// e.g. `@zitadel_user_id`). They are bound to the connection and outlive the transaction,
+// so they must be reset before the connection is returned to the pool (see [Stamper.InTx]).
+// The names are part of the statement, so only letters, digits, underscores and dots are allowed
+// and [ErrInvalidVariable] is returned for any other name.
+var MySQL Dialect = mysql{}
+
+type mysql struct{}
+
+// mysqlVariableName matches the names which can safely be used as user-defined variable.
+var mysqlVariableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.]*$`)
+
+func (mysql) Reset(variables []Variable) (string, []any, error) {
+	assignments := make([]string, len(variables))
+	for i, variable := range variables {
+		name, err := mysqlVariable(variable.Name)
+		if err != nil {
+			return "", nil, err
+		}
+		assignments[i] = name + " = NULL"
+	}
+	return "SET " + strings.Join(assignments, ", "), nil, nil
+}
+
+func (mysql) Statement(variables []Variable) (string, []any, error) {
+	assignments := make([]string, len(variables))
+	args := make([]any, len(variables))
+	for i, variable := range variables {
+		name, err := mysqlVariable(variable.Name)
+		if err != nil {
+			return "", nil, err
+		}
+		assignments[i] = name + " = ?"
+		args[i] = variable.Value
+	}
+	return "SET " + strings.Join(assignments, ", "), args, nil
+}
+
+// mysqlVariable validates the name and returns the user-defined variable, e.g. `@zitadel_user_id`.
+func mysqlVariable(name string) (string, error) {
+	if !mysqlVariableName.MatchString(name) {
+		return "", fmt.Errorf("%w %q", ErrInvalidVariable, name)
+	}
+	return "@" + strings.ReplaceAll(name, ".", "_"), nil
+}
+
+// Stamper sets the identity of the authorization context as session variables.
+type Stamper struct {
+	dialect   Dialect
+	userID    string
+	orgID     string
+	custom    []customVariable
+	anonymous bool
+}
+
+type customVariable struct {
+	name  string
+	value func(ctx context.Context) string
+}
+
+// Option allows customization of the [Stamper].
+type Option func(*Stamper)
+
+// WithVariableNames overrides the default names ([VariableUserID] and [VariableOrganizationID]).
+func WithVariableNames(userID, organizationID string) Option {
+	return func(s *Stamper) {
+		s.userID = userID
+		s.orgID = organizationID
+	}
+}
+
+// WithVariable sets an additional variable with the value derived from the context,
+// e.g. a tenant of the application or a flag whether the user is granted a role.
+func WithVariable(name string, value func(ctx context.Context) string) Option {
+	return func(s *Stamper) {
+		s.custom = append(s.custom, customVariable{name: name, value: value})
+	}
+}
+
+// WithAnonymous allows contexts without authorized user, the variables are set to empty values.
+// By default, [ErrNotAuthorized] is returned, so no statement can run without identity by accident.
+func WithAnonymous() Option {
+	return func(s *Stamper) {
+		s.anonymous = true
+	}
+}
+
+// New creates a [Stamper] for the database dialect, e.g. [Postgres].
+func New(dialect Dialect, opts ...Option) *Stamper {
+	s := &Stamper{
+		dialect: dialect,
+		userID:  VariableUserID,
+		orgID:   VariableOrganizationID,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Stamp sets the variables on the transaction from the authorization context of ctx,
+// which is set by the middleware of the authorization package.
+// [ErrNotTransaction] is returned for the connection pool ([*sql.DB]).
+// For dialects whose variables outlive the transaction (e.g. [MySQL]), [ErrConnectionScoped] is returned, use [Stamper.InTx].
+func (s *Stamper) Stamp(ctx context.Context, exec Execer) error {
+	if _, ok := exec.(*sql.DB); ok {
+		return ErrNotTransaction
+	}
+	if s.connectionScoped() {
+		return ErrConnectionScoped
+	}
+	return s.stamp(ctx, exec)
+}
+
+// stamp sets the variables on the transaction, the caller is responsible for resetting connection scoped ones.
+func (s *Stamper) stamp(ctx context.Context, exec Execer) error {
+	variables, err := s.Variables(ctx)
+	if err != nil {
+		return err
+	}
+	query, args, err := s.dialect.Statement(variables)
+	if err != nil {
+		return err
+	}
+	_, err = exec.ExecContext(ctx, query, args...)
+	return err
+}
+
+// Variables returns the variables which are set by [Stamper.Stamp].
+func (s *Stamper) Variables(ctx context.Context) ([]Variable, error) {
+	variables := make([]Variable, 0, 2+len(s.custom))
+	authCtx := authorization.Context[authorization.Ctx](ctx)
+	switch {
+	case authCtx != nil && authCtx.IsAuthorized():
+		variables = append(variables,
+			Variable{Name: s.userID, Value: authCtx.UserID()},
+			Variable{Name: s.orgID, Value: authCtx.OrganizationID()},
+		)
+	case s.anonymous:
+		variables = append(variables, Variable{Name: s.userID}, Variable{Name: s.orgID})
+	default:
+		return nil, ErrNotAuthorized
+	}
+	for _, c := range s.custom {
+		variables = append(variables, Variable{Name: c.name, Value: c.value(ctx)})
+	}
+	return variables, nil
+}
+
+// BeginTx starts a transaction on the db and stamps it.
+// The transaction is rolled back if the stamping fails.
+// For dialects whose variables outlive the transaction (e.g. [MySQL]), [ErrConnectionScoped] is returned, use [Stamper.InTx].
+func (s *Stamper) BeginTx(ctx context.Context, db *sql.DB, opts *sql.TxOptions) (*sql.Tx, error) {
+	if s.connectionScoped() {
+		return nil, ErrConnectionScoped
+	}
+	tx, err := db.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err = s.stamp(ctx, tx); err != nil {
+		return nil, errors.Join(err, tx.Rollback())
+	}
+	return tx, nil
+}
+
+// InTx runs fn in a stamped transaction, which is committed if fn succeeds and rolled back otherwise.
+// For dialects whose variables outlive the transaction (e.g. [MySQL]), they are reset on the connection afterward.
+// If the reset fails, the connection is discarded instead of being returned to the pool.
+func (s *Stamper) InTx(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) (err error) {
+	variables, err := s.Variables(ctx)
+	if err != nil {
+		return err
+	}
+	// the reset statement is created upfront, so invalid variables are rejected before the connection is used
+	reset, resetArgs, err := s.dialect.Reset(variables)
+	if err != nil {
+		return err
+	}
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errors.Join(err, resetConn(ctx, conn, reset, resetArgs))
+	}()
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err = s.stamp(ctx, tx); err != nil {
+		return errors.Join(err, tx.Rollback())
+	}
+	if err = fn(tx); err != nil {
+		return errors.Join(err, tx.Rollback())
+	}
+	return tx.Commit()
+}
+
+// connectionScoped returns whether the variables of the dialect outlive the transaction and must be reset.
+func (s *Stamper) connectionScoped() bool {
+	reset, _, _ := s.dialect.Reset(nil)
+	return reset != ""
+}
+
+// resetConn runs the reset statement on the connection and returns it to the pool.
+func resetConn(ctx context.Context, conn *sql.Conn, query string, args []any) error {
+	if query == "" {
+		return conn.Close()
+	}
+	if _, err := conn.ExecContext(context.WithoutCancel(ctx), query, args...); err != nil {
+		// the connection must not be reused with the identity set, returning ErrBadConn discards it
+		_ = conn.Raw(func(any) error { return driver.ErrBadConn })
+		return err
+	}
+	return conn.Close()
+}
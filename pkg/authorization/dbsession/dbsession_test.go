@@ -0,0 +1,230 @@
+package dbsession
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zitadel/oidc/v3/pkg/oidc"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/authorization"
+	"github.com/zitadel/zitadel-go/v3/pkg/authorization/oauth"
+)
+
+type recordingExecer struct {
+	query string
+	args  []any
+}
+
+func (r *recordingExecer) ExecContext(_ context.Context, query string, args ...any) (sql.Result, error) {
+	r.query = query
+	r.args = args
+	return nil, nil
+}
+
+func authorizedCtx() context.Context {
+	return authorization.WithAuthContext(context.Background(), &oauth.IntrospectionContext{
+		IntrospectionResponse: oidc.IntrospectionResponse{
+			Active:  true,
+			Subject: "user",
+			Claims:  map[string]any{"urn:zitadel:iam:user:resourceowner:id": "org"},
+		},
+	})
+}
+
+func TestStamper_Stamp(t *testing.T) {
+	tests := []struct {
+		name      string
+		dialect   Dialect
+		opts      []Option
+		wantQuery string
+		wantArgs  []any
+	}{
+		{
+			name:      "postgres",
+			dialect:   Postgres,
+			wantQuery: "SELECT set_config($1, $2, true), set_config($3, $4, true)",
+			wantArgs:  []any{"zitadel.user_id", "user", "zitadel.org_id", "org"},
+		},
+		{
+			name:    "mysql with custom variable",
+			dialect: MySQL,
+			opts: []Option{WithVariable("app.tenant", func(context.Context) string {
+				return "tenant"
+			})},
+			wantQuery: "SET @zitadel_user_id = ?, @zitadel_org_id = ?, @app_tenant = ?",
+			wantArgs:  []any{"user", "org", "tenant"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exec := new(recordingExecer)
+			require.NoError(t, New(tt.dialect, tt.opts...).stamp(authorizedCtx(), exec))
+			assert.Equal(t, tt.wantQuery, exec.query)
+			assert.Equal(t, tt.wantArgs, exec.args)
+		})
+	}
+}
+
+func TestStamper_Stamp_invalidMySQLVariable(t *testing.T) {
+	tests := []struct {
+		name     string
+		variable string
+	}{
+		{name: "injection", variable: "tenant = 1, @zitadel_user_id"},
+		{name: "quoted", variable: "`tenant`"},
+		{name: "leading digit", variable: "1tenant"},
+		{name: "leading dot", variable: ".tenant"},
+		{name: "space", variable: "app tenant"},
+		{name: "empty", variable: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stamper := New(MySQL, WithVariable(tt.variable, func(context.Context) string { return "tenant" }))
+			exec := new(recordingExecer)
+			assert.ErrorIs(t, stamper.stamp(authorizedCtx(), exec), ErrInvalidVariable)
+			assert.Empty(t, exec.query, "no statement is executed")
+
+			d := new(recordingDriver)
+			db := sql.OpenDB(connector{d})
+			defer db.Close()
+			err := stamper.InTx(authorizedCtx(), db, func(*sql.Tx) error {
+				t.Fatal("fn must not be called")
+				return nil
+			})
+			assert.ErrorIs(t, err, ErrInvalidVariable)
+			assert.Empty(t, d.statements, "no statement is executed")
+		})
+	}
+
+	_, _, err := MySQL.Statement([]Variable{{Name: "zitadel.user_id"}, {Name: "app_tenant.id_2"}})
+	assert.NoError(t, err)
+}
+
+func TestStamper_Variables_unauthorized(t *testing.T) {
+	_, err := New(Postgres).Variables(context.Background())
+	assert.ErrorIs(t, err, ErrNotAuthorized)
+
+	variables, err := New(Postgres, WithAnonymous(), WithVariableNames("app.user", "app.org")).Variables(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []Variable{{Name: "app.user"}, {Name: "app.org"}}, variables)
+}
+
+// recordingDriver records the statements of all its connections.
+type recordingDriver struct {
+	mu         sync.Mutex
+	statements []string
+}
+
+func (d *recordingDriver) record(statement string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.statements = append(d.statements, statement)
+}
+
+func (d *recordingDriver) Open(string) (driver.Conn, error) {
+	return &recordingConn{driver: d}, nil
+}
+
+type recordingConn struct {
+	driver *recordingDriver
+}
+
+func (c *recordingConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("not supported")
+}
+
+func (c *recordingConn) Close() error {
+	return nil
+}
+
+func (c *recordingConn) Begin() (driver.Tx, error) {
+	c.driver.record("BEGIN")
+	return c, nil
+}
+
+func (c *recordingConn) Commit() error {
+	c.driver.record("COMMIT")
+	return nil
+}
+
+func (c *recordingConn) Rollback() error {
+	c.driver.record("ROLLBACK")
+	return nil
+}
+
+func (c *recordingConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	c.driver.record(query)
+	return driver.RowsAffected(0), nil
+}
+
+func TestStamper_InTx(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect Dialect
+		fnErr   error
+		want    []string
+	}{
+		{
+			name:    "postgres",
+			dialect: Postgres,
+			want:    []string{"BEGIN", "SELECT set_config($1, $2, true), set_config($3, $4, true)", "UPDATE documents", "COMMIT"},
+		},
+		{
+			name:    "mysql is reset",
+			dialect: MySQL,
+			want:    []string{"BEGIN", "SET @zitadel_user_id = ?, @zitadel_org_id = ?", "UPDATE documents", "COMMIT", "SET @zitadel_user_id = NULL, @zitadel_org_id = NULL"},
+		},
+		{
+			name:    "mysql is reset on rollback",
+			dialect: MySQL,
+			fnErr:   errors.New("failed"),
+			want:    []string{"BEGIN", "SET @zitadel_user_id = ?, @zitadel_org_id = ?", "UPDATE documents", "ROLLBACK", "SET @zitadel_user_id = NULL, @zitadel_org_id = NULL"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := new(recordingDriver)
+			db := sql.OpenDB(connector{d})
+			defer db.Close()
+			err := New(tt.dialect).InTx(authorizedCtx(), db, func(tx *sql.Tx) error {
+				_, err := tx.ExecContext(context.Background(), "UPDATE documents")
+				require.NoError(t, err)
+				return tt.fnErr
+			})
+			assert.ErrorIs(t, err, tt.fnErr)
+			assert.Equal(t, tt.want, d.statements)
+		})
+	}
+}
+
+func TestStamper_connectionPool(t *testing.T) {
+	db := sql.OpenDB(connector{new(recordingDriver)})
+	defer db.Close()
+	assert.ErrorIs(t, New(Postgres).Stamp(authorizedCtx(), db), ErrNotTransaction)
+	_, err := New(MySQL).BeginTx(authorizedCtx(), db, nil)
+	assert.ErrorIs(t, err, ErrConnectionScoped)
+}
+
+func TestStamper_Stamp_connectionScoped(t *testing.T) {
+	exec := new(recordingExecer)
+	assert.ErrorIs(t, New(MySQL).Stamp(authorizedCtx(), exec), ErrConnectionScoped)
+	assert.Empty(t, exec.query, "no statement is executed")
+}
+
+type connector struct {
+	driver *recordingDriver
+}
+
+func (c connector) Connect(context.Context) (driver.Conn, error) {
+	return c.driver.Open("")
+}
+
+func (c connector) Driver() driver.Driver {
+	return c.driver
+}
@@ -0,0 +1,43 @@
+// Package echo provides the authorization middleware for the Echo framework (github.com/labstack/echo).
+// It's a separate module, so only applications using Echo depend on it.
+package echo
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/authorization"
+	"github.com/zitadel/zitadel-go/v3/pkg/dpop"
+)
+
+// Middleware returns an [echo.MiddlewareFunc] requiring a valid authorization passing the provided checks (e.g. [authorization.WithRole]).
+// It responds with 401 Unauthorized for missing or invalid tokens and 403 Forbidden for failed checks.
+// DPoP-bound tokens (see [dpop.ConfirmationThumbprint]) are rejected with 401 Unauthorized, as their proof is not verified.
+// The authorization context can be retrieved in the handler using [Context].
+func Middleware[T authorization.Ctx](authorizer *authorization.Authorizer[T], options ...authorization.CheckOption) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			ctx, authCtx, err := authorizer.Authorize(req.Context(), req.Header.Get(authorization.HeaderName), options...)
+			if err != nil {
+				if errors.Is(err, &authorization.UnauthorizedErr{}) {
+					return echo.NewHTTPError(http.StatusUnauthorized, err.Error())
+				}
+				return echo.NewHTTPError(http.StatusForbidden, err.Error())
+			}
+			if dpop.ConfirmationThumbprint(authCtx) != "" {
+				c.Response().Header().Set("WWW-Authenticate", `DPoP error="invalid_token"`)
+				return echo.NewHTTPError(http.StatusUnauthorized, dpop.ErrMissingProof.Error())
+			}
+			c.SetRequest(req.WithContext(ctx))
+			return next(c)
+		}
+	}
+}
+
+// Context returns the authorization context of the request.
+func Context[T authorization.Ctx](c echo.Context) T {
+	return authorization.Context[T](c.Request().Context())
+}
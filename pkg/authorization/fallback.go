@@ -0,0 +1,159 @@
+package authorization
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"reflect"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	degradedKey key = 2
+
+	meterName = "github.com/zitadel/zitadel-go/v3/pkg/authorization"
+
+	attributeFallbackResult = attribute.Key("zitadel.fallback.result")
+)
+
+// ErrUnavailable is wrapped by the errors of verifiers which could not reach ZITADEL
+// (e.g. oauth.ErrIntrospectionFailed caused by a transport error or a 5xx response, or verifier.ErrKeysUnavailable),
+// as opposed to errors of invalid tokens or rejected requests.
+var ErrUnavailable = errors.New("zitadel unavailable")
+
+// ExpiringCtx is implemented by authorization contexts providing the expiration of the token,
+// e.g. the oauth.IntrospectionContext. The fallback cache doesn't serve decisions of expired tokens.
+type ExpiringCtx interface {
+	ExpiresAt() time.Time
+}
+
+// WithFallbackCache remembers the positive authorization decisions of the [Verifier] and serves them for at most
+// maxStaleness after their verification (but not after the expiration of the token, see [ExpiringCtx]),
+// while ZITADEL is unreachable: the verifier fails with an error wrapping [ErrUnavailable] or with the gRPC code Unavailable.
+// Other errors (e.g. expired tokens or invalid audiences) are never served from the cache.
+// Tokens reported as not authorized (e.g. revoked) are removed from the cache immediately.
+//
+// Calls authorized from the cache are marked as degraded (see [IsDegraded]), so applications can restrict sensitive actions.
+// Note that role checks are evaluated on the cached decision and therefore reflect the grants at the time of the verification.
+//
+// The served and rejected fallbacks are counted in the `zitadel.authorization.fallback` metric of the meterProvider.
+// If the meterProvider is nil, the global provider will be used.
+func WithFallbackCache[T Ctx](maxStaleness time.Duration, meterProvider metric.MeterProvider) Option[T] {
+	return func(a *Authorizer[T]) {
+		if meterProvider == nil {
+			meterProvider = otel.GetMeterProvider()
+		}
+		counter, err := meterProvider.Meter(meterName).Int64Counter("zitadel.authorization.fallback",
+			metric.WithDescription("Authorization decisions requested from the fallback cache while the verifier failed"),
+		)
+		if err != nil {
+			a.logger.Warn("unable to create fallback metric", "error", err)
+		}
+		a.fallback = &fallbackCache[T]{
+			maxStaleness: maxStaleness,
+			entries:      make(map[[sha256.Size]byte]fallbackEntry[T]),
+			counter:      counter,
+			now:          time.Now,
+		}
+	}
+}
+
+// IsDegraded returns if the call was authorized by a cached decision (see [WithFallbackCache]),
+// because the token could not be verified.
+func IsDegraded(ctx context.Context) bool {
+	degraded, _ := ctx.Value(degradedKey).(bool)
+	return degraded
+}
+
+func withDegraded(ctx context.Context) context.Context {
+	return context.WithValue(ctx, degradedKey, true)
+}
+
+// isUnavailable checks if the error of the verifier is caused by ZITADEL being unreachable.
+func isUnavailable(err error) bool {
+	return errors.Is(err, ErrUnavailable) || status.Code(err) == codes.Unavailable
+}
+
+type fallbackEntry[T Ctx] struct {
+	authCtx   T
+	expiresAt time.Time
+}
+
+type fallbackCache[T Ctx] struct {
+	maxStaleness time.Duration
+	counter      metric.Int64Counter
+	now          func() time.Time
+
+	mu       sync.Mutex
+	entries  map[[sha256.Size]byte]fallbackEntry[T]
+	prunedAt time.Time
+}
+
+func (c *fallbackCache[T]) set(token string, authCtx T) {
+	now := c.now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if now.Sub(c.prunedAt) > c.maxStaleness {
+		for hash, entry := range c.entries {
+			if now.After(entry.expiresAt) {
+				delete(c.entries, hash)
+			}
+		}
+		c.prunedAt = now
+	}
+	expiresAt := now.Add(c.maxStaleness)
+	if expiring, ok := any(authCtx).(ExpiringCtx); ok {
+		if exp := expiring.ExpiresAt(); !exp.IsZero() && exp.Before(expiresAt) {
+			expiresAt = exp
+		}
+	}
+	c.entries[sha256.Sum256([]byte(token))] = fallbackEntry[T]{authCtx: clone(authCtx), expiresAt: expiresAt}
+}
+
+func (c *fallbackCache[T]) delete(token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, sha256.Sum256([]byte(token)))
+}
+
+func (c *fallbackCache[T]) get(ctx context.Context, token string) (authCtx T, ok bool) {
+	c.mu.Lock()
+	entry, found := c.entries[sha256.Sum256([]byte(token))]
+	c.mu.Unlock()
+	switch {
+	case !found:
+		c.record(ctx, "miss")
+		return authCtx, false
+	case c.now().After(entry.expiresAt):
+		c.record(ctx, "stale")
+		return authCtx, false
+	}
+	c.record(ctx, "served")
+	return clone(entry.authCtx), true
+}
+
+// clone returns a shallow copy of an authorization context implemented by a pointer (e.g. *oauth.IntrospectionContext),
+// so the cached context isn't shared with (and modified by, see [Ctx.SetToken]) the requests it is served to.
+func clone[T Ctx](authCtx T) T {
+	v := reflect.ValueOf(authCtx)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return authCtx
+	}
+	c := reflect.New(v.Elem().Type())
+	c.Elem().Set(v.Elem())
+	return c.Interface().(T)
+}
+
+func (c *fallbackCache[T]) record(ctx context.Context, result string) {
+	if c.counter == nil {
+		return
+	}
+	c.counter.Add(ctx, 1, metric.WithAttributes(attributeFallbackResult.String(result)))
+}
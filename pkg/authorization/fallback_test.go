@@ -0,0 +1,104 @@
+package authorization
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/metric/noop"
+	"golang.org/x/exp/slog"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestAuthorizer_Authorize_fallback(t *testing.T) {
+	verifier := &testVerifier[*testCtx]{ctx: &testCtx{isAuthorized: true, userID: "user"}}
+	a := &Authorizer[*testCtx]{verifier: verifier, logger: slog.Default()}
+	WithFallbackCache[*testCtx](time.Minute, noop.NewMeterProvider())(a)
+	now := time.Now()
+	a.fallback.now = func() time.Time { return now }
+
+	ctx, authCtx, err := a.Authorize(context.Background(), "token")
+	require.NoError(t, err)
+	assert.Equal(t, "user", authCtx.UserID())
+	assert.False(t, IsDegraded(ctx))
+	assert.Equal(t, "user", UserID(ctx))
+
+	verifier.ctx, verifier.err = nil, fmt.Errorf("introspection failed: %w", ErrUnavailable)
+	ctx, authCtx, err = a.Authorize(context.Background(), "token")
+	require.NoError(t, err)
+	assert.Equal(t, "user", authCtx.UserID())
+	assert.True(t, IsDegraded(ctx))
+
+	verifier.err = status.Error(codes.Unavailable, "connection refused")
+	_, _, err = a.Authorize(context.Background(), "token")
+	require.NoError(t, err)
+
+	// invalid tokens are never served from the cache
+	verifier.err = errors.New("token expired")
+	_, _, err = a.Authorize(context.Background(), "token")
+	assert.ErrorIs(t, err, &UnauthorizedErr{})
+	verifier.err = ErrUnavailable
+
+	_, _, err = a.Authorize(context.Background(), "unknown")
+	assert.ErrorIs(t, err, &UnauthorizedErr{})
+
+	now = now.Add(2 * time.Minute)
+	_, _, err = a.Authorize(context.Background(), "token")
+	assert.ErrorIs(t, err, &UnauthorizedErr{})
+}
+
+func TestAuthorizer_Authorize_fallbackRevoked(t *testing.T) {
+	verifier := &testVerifier[*testCtx]{ctx: &testCtx{isAuthorized: true}}
+	a := &Authorizer[*testCtx]{verifier: verifier, logger: slog.Default()}
+	WithFallbackCache[*testCtx](time.Minute, noop.NewMeterProvider())(a)
+
+	_, _, err := a.Authorize(context.Background(), "token")
+	require.NoError(t, err)
+
+	verifier.ctx = &testCtx{isAuthorized: false}
+	_, _, err = a.Authorize(context.Background(), "token")
+	assert.ErrorIs(t, err, &UnauthorizedErr{})
+
+	verifier.ctx, verifier.err = nil, ErrUnavailable
+	_, _, err = a.Authorize(context.Background(), "token")
+	assert.ErrorIs(t, err, &UnauthorizedErr{})
+}
+
+type expiringTestCtx struct {
+	testCtx
+	expiresAt time.Time
+}
+
+func (t *expiringTestCtx) ExpiresAt() time.Time {
+	return t.expiresAt
+}
+
+func TestAuthorizer_Authorize_fallbackExpiration(t *testing.T) {
+	now := time.Now()
+	verifier := &testVerifier[*expiringTestCtx]{ctx: &expiringTestCtx{testCtx: testCtx{isAuthorized: true}, expiresAt: now.Add(time.Second)}}
+	a := &Authorizer[*expiringTestCtx]{verifier: verifier, logger: slog.Default()}
+	WithFallbackCache[*expiringTestCtx](time.Minute, noop.NewMeterProvider())(a)
+	a.fallback.now = func() time.Time { return now }
+
+	_, verified, err := a.Authorize(context.Background(), "token")
+	require.NoError(t, err)
+
+	verifier.ctx, verifier.err = nil, ErrUnavailable
+	_, cached, err := a.Authorize(context.Background(), "token")
+	require.NoError(t, err)
+	assert.NotSame(t, verified, cached, "the cached context must not be shared")
+	cached.SetToken("modified")
+	_, cached, err = a.Authorize(context.Background(), "token")
+	require.NoError(t, err)
+	assert.Equal(t, "token", cached.GetToken())
+
+	// the token expires before the max staleness
+	now = now.Add(2 * time.Second)
+	_, _, err = a.Authorize(context.Background(), "token")
+	assert.ErrorIs(t, err, &UnauthorizedErr{})
+}
@@ -0,0 +1,39 @@
+// Package fiber provides the authorization middleware for the Fiber framework (github.com/gofiber/fiber).
+// It's a separate module, so only applications using Fiber depend on it.
+package fiber
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/authorization"
+	"github.com/zitadel/zitadel-go/v3/pkg/dpop"
+)
+
+// Middleware returns a [fiber.Handler] requiring a valid authorization passing the provided checks (e.g. [authorization.WithRole]).
+// It responds with 401 Unauthorized for missing or invalid tokens and 403 Forbidden for failed checks.
+// DPoP-bound tokens (see [dpop.ConfirmationThumbprint]) are rejected with 401 Unauthorized, as their proof is not verified.
+// The authorization context is set on the user context of the request and can be retrieved using [Context].
+func Middleware[T authorization.Ctx](authorizer *authorization.Authorizer[T], options ...authorization.CheckOption) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx, authCtx, err := authorizer.Authorize(c.UserContext(), c.Get(authorization.HeaderName), options...)
+		if err != nil {
+			if errors.Is(err, &authorization.UnauthorizedErr{}) {
+				return fiber.NewError(fiber.StatusUnauthorized, err.Error())
+			}
+			return fiber.NewError(fiber.StatusForbidden, err.Error())
+		}
+		if dpop.ConfirmationThumbprint(authCtx) != "" {
+			c.Set(fiber.HeaderWWWAuthenticate, `DPoP error="invalid_token"`)
+			return fiber.NewError(fiber.StatusUnauthorized, dpop.ErrMissingProof.Error())
+		}
+		c.SetUserContext(ctx)
+		return c.Next()
+	}
+}
+
+// Context returns the authorization context of the request.
+func Context[T authorization.Ctx](c *fiber.Ctx) T {
+	return authorization.Context[T](c.UserContext())
+}
@@ -0,0 +1,43 @@
+// Package gin provides the authorization middleware for the Gin framework (github.com/gin-gonic/gin).
+// It's a separate module, so only applications using Gin depend on it.
+package gin
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/authorization"
+	"github.com/zitadel/zitadel-go/v3/pkg/dpop"
+)
+
+// Middleware returns a [gin.HandlerFunc] requiring a valid authorization passing the provided checks (e.g. [authorization.WithRole]).
+// It aborts with 401 Unauthorized for missing or invalid tokens and 403 Forbidden for failed checks.
+// DPoP-bound tokens (see [dpop.ConfirmationThumbprint]) are rejected with 401 Unauthorized, as their proof is not verified.
+// The authorization context can be retrieved in the handler using [Context].
+func Middleware[T authorization.Ctx](authorizer *authorization.Authorizer[T], options ...authorization.CheckOption) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, authCtx, err := authorizer.Authorize(c.Request.Context(), c.GetHeader(authorization.HeaderName), options...)
+		if err != nil {
+			if errors.Is(err, &authorization.UnauthorizedErr{}) {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		if dpop.ConfirmationThumbprint(authCtx) != "" {
+			c.Header("WWW-Authenticate", `DPoP error="invalid_token"`)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": dpop.ErrMissingProof.Error()})
+			return
+		}
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// Context returns the authorization context of the request.
+func Context[T authorization.Ctx](c *gin.Context) T {
+	return authorization.Context[T](c.Request.Context())
+}
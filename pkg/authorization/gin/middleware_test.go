@@ -0,0 +1,82 @@
+package gin
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zitadel/oidc/v3/pkg/oidc"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/authorization"
+	"github.com/zitadel/zitadel-go/v3/pkg/authorization/oauth"
+	"github.com/zitadel/zitadel-go/v3/pkg/zitadel"
+)
+
+func TestMiddleware(t *testing.T) {
+	tests := []struct {
+		name          string
+		token         string
+		wantStatus    int
+		wantBody      string
+		wantChallenge string
+	}{
+		{"missing token", "", http.StatusUnauthorized, "", ""},
+		{"missing role", "Bearer viewer", http.StatusForbidden, "", ""},
+		{"authorized", "Bearer admin", http.StatusOK, "user1", ""},
+		{"DPoP-bound token without proof", "Bearer admin bound", http.StatusUnauthorized, "", `DPoP error="invalid_token"`},
+	}
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/admin", Middleware(testAuthorizer(t), authorization.WithRole("admin")), func(c *gin.Context) {
+		c.String(http.StatusOK, Context[*oauth.IntrospectionContext](c).UserID())
+	})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+			if tt.token != "" {
+				req.Header.Set(authorization.HeaderName, tt.token)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			assert.Equal(t, tt.wantChallenge, rec.Header().Get("WWW-Authenticate"))
+			if tt.wantBody != "" {
+				assert.Equal(t, tt.wantBody, rec.Body.String())
+			}
+		})
+	}
+}
+
+// testAuthorizer grants the role of the token (e.g. `Bearer admin`) to user1,
+// tokens with the suffix ` bound` are bound to a DPoP key.
+func testAuthorizer(t *testing.T) *authorization.Authorizer[*oauth.IntrospectionContext] {
+	authorizer, err := authorization.New[*oauth.IntrospectionContext](context.Background(), zitadel.New("zitadel.cloud"),
+		func(context.Context, *zitadel.Zitadel) (authorization.Verifier[*oauth.IntrospectionContext], error) {
+			return testVerifier{}, nil
+		},
+	)
+	require.NoError(t, err)
+	return authorizer
+}
+
+type testVerifier struct{}
+
+func (testVerifier) CheckAuthorization(_ context.Context, token string) (*oauth.IntrospectionContext, error) {
+	role, bound := strings.CutSuffix(strings.TrimPrefix(token, "Bearer "), " bound")
+	claims := map[string]any{
+		"urn:zitadel:iam:org:project:roles": map[string]any{role: map[string]any{"org1": "zitadel.cloud"}},
+	}
+	if bound {
+		claims["cnf"] = map[string]any{"jkt": "thumbprint"}
+	}
+	return &oauth.IntrospectionContext{IntrospectionResponse: oidc.IntrospectionResponse{
+		Active:  true,
+		Subject: "user1",
+		Claims:  claims,
+	}}, nil
+}
@@ -4,18 +4,19 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"strings"
+	"net/http"
+	"net/url"
 
 	"github.com/zitadel/oidc/v3/pkg/client"
 	"github.com/zitadel/oidc/v3/pkg/client/rs"
-	"github.com/zitadel/oidc/v3/pkg/oidc"
 
 	"github.com/zitadel/zitadel-go/v3/pkg/authorization"
+	"github.com/zitadel/zitadel-go/v3/pkg/dpop"
 	"github.com/zitadel/zitadel-go/v3/pkg/zitadel"
 )
 
 var (
-	ErrInvalidAuthorizationHeader = errors.New("invalid authorization header, must be prefixed with `Bearer`")
+	ErrInvalidAuthorizationHeader = errors.New("invalid authorization header, must be prefixed with `Bearer` or `DPoP`")
 	ErrIntrospectionFailed        = errors.New("token introspection failed")
 )
 
@@ -45,20 +46,26 @@ type IntrospectionAuthentication func(ctx context.Context, issuer string) (rs.Re
 
 // JWTProfileIntrospectionAuthentication allows to authenticate the introspection request with JWT Profile
 // using a key.json provided by ZITADEL.
-func JWTProfileIntrospectionAuthentication(file *client.KeyFile) IntrospectionAuthentication {
+func JWTProfileIntrospectionAuthentication(file *client.KeyFile, options ...rs.Option) IntrospectionAuthentication {
 	return func(ctx context.Context, issuer string) (rs.ResourceServer, error) {
-		return rs.NewResourceServerJWTProfile(ctx, issuer, file.ClientID, file.KeyID, []byte(file.Key))
+		return rs.NewResourceServerJWTProfile(ctx, issuer, file.ClientID, file.KeyID, []byte(file.Key), options...)
 	}
 }
 
 // ClientIDSecretIntrospectionAuthentication allows to authenticate the introspection request with
 // the client_id and client_secret provided by ZITADEL.
-func ClientIDSecretIntrospectionAuthentication(clientID, clientSecret string) IntrospectionAuthentication {
+func ClientIDSecretIntrospectionAuthentication(clientID, clientSecret string, options ...rs.Option) IntrospectionAuthentication {
 	return func(ctx context.Context, issuer string) (rs.ResourceServer, error) {
-		return rs.NewResourceServerClientCredentials(ctx, issuer, clientID, clientSecret)
+		return rs.NewResourceServerClientCredentials(ctx, issuer, clientID, clientSecret, options...)
 	}
 }
 
+// WithHTTPClient allows to use a custom [http.Client] for the discovery and introspection requests,
+// e.g. one serving the discovery from disk during an outage (see diskcache.NewClient).
+func WithHTTPClient(httpClient *http.Client) rs.Option {
+	return rs.WithClient(httpClient)
+}
+
 // DefaultAuthorization is a short version of [WithIntrospection[*IntrospectionContext](JWTProfileIntrospectionAuthentication)]
 // with a key.json read from a provided path.
 func DefaultAuthorization(path string) authorization.VerifierInitializer[*IntrospectionContext] {
@@ -73,15 +80,70 @@ func DefaultAuthorization(path string) authorization.VerifierInitializer[*Intros
 
 // CheckAuthorization implements the [authorization.Verifier] interface by checking the authorizationToken
 // on the OAuth2 introspection endpoint.
+// Sender-constrained tokens (DPoP scheme) are only accepted if the proof of the request has been verified
+// and is provided in the context (see middleware.RequireDPoP and [dpop.WithProof]).
 // On success, it will return a generic struct of type [T] of the [IntrospectionVerification].
 func (i *IntrospectionVerification[T]) CheckAuthorization(ctx context.Context, authorizationToken string) (resp T, err error) {
-	accessToken, ok := strings.CutPrefix(authorizationToken, oidc.BearerToken)
+	token, ok := accessToken(authorizationToken)
 	if !ok {
 		return resp, ErrInvalidAuthorizationHeader
 	}
-	resp, err = rs.Introspect[T](ctx, i.ResourceServer, strings.TrimSpace(accessToken))
+	if _, isDPoP := dpop.AccessToken(authorizationToken); isDPoP {
+		if _, verified := dpop.ProofFromContext(ctx); !verified {
+			return resp, fmt.Errorf("%w: %w", ErrInvalidAuthorizationHeader, dpop.ErrMissingProof)
+		}
+	}
+	server := &statusResourceServer{ResourceServer: i.ResourceServer}
+	resp, err = rs.Introspect[T](ctx, server, token)
 	if err != nil {
+		if server.unavailable(err) {
+			return resp, fmt.Errorf("%w: %w: %v", ErrIntrospectionFailed, authorization.ErrUnavailable, err)
+		}
 		return resp, fmt.Errorf("%w: %v", ErrIntrospectionFailed, err)
 	}
 	return resp, nil
 }
+
+// statusResourceServer records the status code of the introspection response,
+// which is not part of the error returned by [rs.Introspect].
+type statusResourceServer struct {
+	rs.ResourceServer
+	status int
+}
+
+func (s *statusResourceServer) HttpClient() *http.Client {
+	httpClient := http.DefaultClient
+	if c := s.ResourceServer.HttpClient(); c != nil {
+		httpClient = c
+	}
+	recording := *httpClient
+	recording.Transport = &statusTransport{next: httpClient.Transport, status: &s.status}
+	return &recording
+}
+
+// unavailable reports whether the introspection failed because ZITADEL could not be reached or is overloaded
+// (transport errors, timeouts, 5xx and 429 responses), in contrast to a rejected request (e.g. invalid client authentication).
+func (s *statusResourceServer) unavailable(err error) bool {
+	if s.status != 0 {
+		return s.status >= http.StatusInternalServerError || s.status == http.StatusTooManyRequests
+	}
+	var urlErr *url.Error
+	return errors.As(err, &urlErr) || errors.Is(err, context.DeadlineExceeded)
+}
+
+type statusTransport struct {
+	next   http.RoundTripper
+	status *int
+}
+
+func (t *statusTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	next := t.next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	resp, err := next.RoundTrip(req)
+	if resp != nil {
+		*t.status = resp.StatusCode
+	}
+	return resp, err
+}
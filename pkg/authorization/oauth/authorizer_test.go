@@ -3,11 +3,19 @@ package oauth
 import (
 	"bytes"
 	"context"
+	"errors"
 	"io"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/metric/noop"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/authorization"
+	"github.com/zitadel/zitadel-go/v3/pkg/dpop"
+	"github.com/zitadel/zitadel-go/v3/pkg/zitadel"
 )
 
 func TestIntrospectionVerification_CheckAuthorization(t *testing.T) {
@@ -58,6 +66,74 @@ func TestIntrospectionVerification_CheckAuthorization(t *testing.T) {
 			},
 			wantErr: ErrIntrospectionFailed,
 		},
+		{
+			name: "introspection unavailable",
+			i: IntrospectionVerification[*introspection]{
+				ResourceServer: &resourceServer{
+					client: mockClient([]byte(`service unavailable`), 503),
+				},
+			},
+			args: args{
+				ctx:                context.Background(),
+				authorizationToken: "Bearer valid",
+			},
+			wantErr: authorization.ErrUnavailable,
+		},
+		{
+			name: "introspection rate limited",
+			i: IntrospectionVerification[*introspection]{
+				ResourceServer: &resourceServer{
+					client: mockClient([]byte(`{"error": "slow_down"}`), 429),
+				},
+			},
+			args: args{
+				ctx:                context.Background(),
+				authorizationToken: "Bearer valid",
+			},
+			wantErr: authorization.ErrUnavailable,
+		},
+		{
+			name: "introspection unreachable",
+			i: IntrospectionVerification[*introspection]{
+				ResourceServer: &resourceServer{
+					client: &http.Client{Transport: &mockTransport{err: errors.New("connection refused")}},
+				},
+			},
+			args: args{
+				ctx:                context.Background(),
+				authorizationToken: "Bearer valid",
+			},
+			wantErr: authorization.ErrUnavailable,
+		},
+		{
+			name: "DPoP token without verified proof",
+			i: IntrospectionVerification[*introspection]{
+				ResourceServer: &resourceServer{
+					client: mockClient([]byte(`{"active": true, "sub": "sub"}`), 200),
+				},
+			},
+			args: args{
+				ctx:                context.Background(),
+				authorizationToken: "DPoP token",
+			},
+			wantErr: ErrInvalidAuthorizationHeader,
+		},
+		{
+			name: "DPoP token with verified proof",
+			i: IntrospectionVerification[*introspection]{
+				ResourceServer: &resourceServer{
+					client: mockClient([]byte(`{"active": true, "sub": "sub"}`), 200),
+				},
+			},
+			args: args{
+				ctx:                dpop.WithProof(context.Background(), &dpop.Proof{Thumbprint: "jkt"}),
+				authorizationToken: "DPoP token",
+			},
+			wantResp: &introspection{
+				Active:  true,
+				Subject: "sub",
+			},
+		},
 		{
 			name: "introspection succeeded",
 			i: IntrospectionVerification[*introspection]{
@@ -78,12 +154,41 @@ func TestIntrospectionVerification_CheckAuthorization(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			got, err := tt.i.CheckAuthorization(tt.args.ctx, tt.args.authorizationToken)
+			if !errors.Is(tt.wantErr, authorization.ErrUnavailable) {
+				assert.NotErrorIs(t, err, authorization.ErrUnavailable)
+			}
 			assert.ErrorIs(t, err, tt.wantErr)
 			assert.Equal(t, tt.wantResp, got)
 		})
 	}
 }
 
+func TestIntrospectionVerification_fallback(t *testing.T) {
+	transport := &mockTransport{resp: []byte(`{"active": true, "sub": "sub"}`), status: 200}
+	a, err := authorization.New(context.Background(), nil,
+		func(context.Context, *zitadel.Zitadel) (authorization.Verifier[*IntrospectionContext], error) {
+			return &IntrospectionVerification[*IntrospectionContext]{
+				ResourceServer: &resourceServer{client: &http.Client{Transport: transport}},
+			}, nil
+		},
+		authorization.WithFallbackCache[*IntrospectionContext](time.Minute, noop.NewMeterProvider()),
+	)
+	require.NoError(t, err)
+	_, _, err = a.Authorize(context.Background(), "Bearer token")
+	require.NoError(t, err)
+
+	// a rejected introspection request (e.g. the client credentials were revoked) must not fall back
+	transport.resp, transport.status = []byte(`{"error": "invalid_client"}`), 401
+	_, _, err = a.Authorize(context.Background(), "Bearer token")
+	assert.ErrorIs(t, err, &authorization.UnauthorizedErr{})
+
+	transport.resp, transport.status = []byte(`bad gateway`), 502
+	ctx, authCtx, err := a.Authorize(context.Background(), "Bearer token")
+	require.NoError(t, err)
+	assert.Equal(t, "sub", authCtx.UserID())
+	assert.True(t, authorization.IsDegraded(ctx))
+}
+
 type introspection struct {
 	Active  bool   `json:"active,omitempty"`
 	Subject string `json:"sub,omitempty"`
@@ -121,9 +226,13 @@ func mockClient(resp []byte, status int) *http.Client {
 type mockTransport struct {
 	resp   []byte
 	status int
+	err    error
 }
 
 func (m *mockTransport) RoundTrip(_ *http.Request) (*http.Response, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
 	responseBody := io.NopCloser(bytes.NewReader(m.resp))
 	return &http.Response{
 		StatusCode: m.status,
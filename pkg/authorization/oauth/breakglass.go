@@ -0,0 +1,234 @@
+package oauth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"maps"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zitadel/oidc/v3/pkg/oidc"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/authorization"
+	"github.com/zitadel/zitadel-go/v3/pkg/dpop"
+	"github.com/zitadel/zitadel-go/v3/pkg/zitadel"
+)
+
+const (
+	// ClaimBreakGlass is set on the [IntrospectionContext] of calls authorized by [WithBreakGlass]
+	// while the introspection was unavailable. It contains the name of the break-glass token, resp. `identity`.
+	ClaimBreakGlass = "urn:zitadel-go:break_glass"
+
+	claimResourceOwnerID = "urn:zitadel:iam:user:resourceowner:id"
+	claimRoles           = "urn:zitadel:iam:org:project:roles"
+)
+
+// BreakGlassToken is a static token, which remains authorized while the introspection is unavailable.
+// Only the SHA-256 hash of the token is configured (see [HashToken]), so the token itself is not part of the configuration.
+type BreakGlassToken struct {
+	// Name identifies the token in the logs.
+	Name string
+	// TokenHash is the hex encoded SHA-256 hash of the access token.
+	TokenHash string
+	// Subject, OrganizationID and Roles are used as identity of the caller.
+	Subject        string
+	OrganizationID string
+	Roles          []string
+	// ExpiresAt is the mandatory expiry of the token, expired tokens are rejected.
+	ExpiresAt time.Time
+}
+
+// HashToken returns the hex encoded SHA-256 hash of the token for a [BreakGlassToken].
+func HashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+// BreakGlassOption allows customization of [WithBreakGlass].
+type BreakGlassOption func(*breakGlassVerification)
+
+// WithBreakGlassTokens allows the tokens while the introspection is unavailable.
+func WithBreakGlassTokens(tokens ...BreakGlassToken) BreakGlassOption {
+	return func(v *breakGlassVerification) {
+		v.tokens = append(v.tokens, tokens...)
+	}
+}
+
+// WithAllowlistedIdentities remembers the successfully introspected tokens of the subjects (e.g. service users of
+// critical internal tooling) for the ttl, but at most until their expiry, and allows them while the introspection is unavailable.
+func WithAllowlistedIdentities(ttl time.Duration, subjects ...string) BreakGlassOption {
+	return func(v *breakGlassVerification) {
+		v.identityTTL = ttl
+		for _, subject := range subjects {
+			v.identities[subject] = struct{}{}
+		}
+	}
+}
+
+// WithBreakGlassLogger allows a logger other than slog.Default().
+func WithBreakGlassLogger(logger *slog.Logger) BreakGlassOption {
+	return func(v *breakGlassVerification) {
+		v.logger = logger
+	}
+}
+
+// WithBreakGlass wraps the [authorization.VerifierInitializer], so that configured break-glass tokens
+// and allowlisted identities remain authorized when the introspection is unavailable ([authorization.ErrUnavailable]),
+// e.g. to keep critical internal tooling alive during an outage of ZITADEL.
+// Every call authorized this way is logged with level Error and marked with the [ClaimBreakGlass].
+// While the introspection is available, the break-glass tokens are not accepted.
+func WithBreakGlass(initVerifier authorization.VerifierInitializer[*IntrospectionContext], opts ...BreakGlassOption) authorization.VerifierInitializer[*IntrospectionContext] {
+	return func(ctx context.Context, zitadel *zitadel.Zitadel) (authorization.Verifier[*IntrospectionContext], error) {
+		verifier, err := initVerifier(ctx, zitadel)
+		if err != nil {
+			return nil, err
+		}
+		v := &breakGlassVerification{
+			verifier:   verifier,
+			identities: make(map[string]struct{}),
+			seen:       make(map[string]*IntrospectionContext),
+			logger:     slog.Default(),
+			now:        time.Now,
+		}
+		for _, opt := range opts {
+			opt(v)
+		}
+		return v, nil
+	}
+}
+
+type breakGlassVerification struct {
+	verifier    authorization.Verifier[*IntrospectionContext]
+	tokens      []BreakGlassToken
+	identities  map[string]struct{}
+	identityTTL time.Duration
+	logger      *slog.Logger
+	now         func() time.Time
+
+	mu sync.Mutex
+	// seen contains the introspection results of the allowlisted identities by token hash
+	seen map[string]*IntrospectionContext
+}
+
+// CheckAuthorization implements the [authorization.Verifier] interface.
+func (v *breakGlassVerification) CheckAuthorization(ctx context.Context, authorizationToken string) (*IntrospectionContext, error) {
+	resp, err := v.verifier.CheckAuthorization(ctx, authorizationToken)
+	if err == nil {
+		v.remember(authorizationToken, resp)
+		return resp, nil
+	}
+	if !errors.Is(err, authorization.ErrUnavailable) {
+		return resp, err
+	}
+	token, ok := accessToken(authorizationToken)
+	if !ok {
+		return resp, err
+	}
+	hash := HashToken(token)
+	if breakGlass := v.token(ctx, hash); breakGlass != nil {
+		v.logger.ErrorContext(ctx, "BREAK-GLASS: token authorized while introspection is unavailable",
+			"name", breakGlass.Name, "subject", breakGlass.Subject, "expires_at", breakGlass.ExpiresAt, "error", err)
+		return breakGlassContext(breakGlass), nil
+	}
+	if identity := v.identity(hash); identity != nil {
+		v.logger.ErrorContext(ctx, "BREAK-GLASS: allowlisted identity authorized while introspection is unavailable",
+			"subject", identity.Subject, "expires_at", identity.Expiration.AsTime(), "error", err)
+		return identity, nil
+	}
+	return resp, err
+}
+
+func (v *breakGlassVerification) token(ctx context.Context, hash string) *BreakGlassToken {
+	for i, token := range v.tokens {
+		if subtle.ConstantTimeCompare([]byte(strings.ToLower(token.TokenHash)), []byte(hash)) != 1 {
+			continue
+		}
+		if !v.now().Before(token.ExpiresAt) {
+			v.logger.WarnContext(ctx, "BREAK-GLASS: expired token rejected", "name", token.Name, "expired_at", token.ExpiresAt)
+			return nil
+		}
+		return &v.tokens[i]
+	}
+	return nil
+}
+
+// remember stores the introspection result of an allowlisted identity.
+// A token which is no longer authorized (e.g. revoked) is forgotten, so it's not allowed during the next outage.
+func (v *breakGlassVerification) remember(authorizationToken string, resp *IntrospectionContext) {
+	token, ok := accessToken(authorizationToken)
+	if !ok {
+		return
+	}
+	if resp == nil || !resp.IsAuthorized() {
+		v.mu.Lock()
+		defer v.mu.Unlock()
+		delete(v.seen, HashToken(token))
+		return
+	}
+	if _, ok := v.identities[resp.Subject]; !ok {
+		return
+	}
+	expiration := v.now().Add(v.identityTTL)
+	if exp := resp.Expiration.AsTime(); !exp.IsZero() && exp.Before(expiration) {
+		expiration = exp
+	}
+	identity := &IntrospectionContext{IntrospectionResponse: resp.IntrospectionResponse}
+	identity.Claims = maps.Clone(resp.Claims)
+	identity.Expiration = oidc.FromTime(expiration)
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	for hash, seen := range v.seen {
+		if !v.now().Before(seen.Expiration.AsTime()) {
+			delete(v.seen, hash)
+		}
+	}
+	v.seen[HashToken(token)] = identity
+}
+
+func (v *breakGlassVerification) identity(hash string) *IntrospectionContext {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	identity, ok := v.seen[hash]
+	if !ok || !v.now().Before(identity.Expiration.AsTime()) {
+		return nil
+	}
+	resp := &IntrospectionContext{IntrospectionResponse: identity.IntrospectionResponse}
+	resp.Claims = maps.Clone(identity.Claims)
+	if resp.Claims == nil {
+		resp.Claims = make(map[string]any)
+	}
+	resp.Claims[ClaimBreakGlass] = "identity"
+	return resp
+}
+
+func breakGlassContext(token *BreakGlassToken) *IntrospectionContext {
+	roles := make(map[string]any, len(token.Roles))
+	for _, role := range token.Roles {
+		roles[role] = map[string]any{token.OrganizationID: ""}
+	}
+	resp := &IntrospectionContext{}
+	resp.Active = true
+	resp.Subject = token.Subject
+	resp.Expiration = oidc.FromTime(token.ExpiresAt)
+	resp.Claims = map[string]any{
+		ClaimBreakGlass:      token.Name,
+		claimResourceOwnerID: token.OrganizationID,
+		claimRoles:           roles,
+	}
+	return resp
+}
+
+// accessToken returns the token of the authorization header using the Bearer or DPoP scheme.
+func accessToken(authorizationToken string) (string, bool) {
+	token, ok := strings.CutPrefix(authorizationToken, oidc.BearerToken)
+	if !ok {
+		token, ok = dpop.AccessToken(authorizationToken)
+	}
+	return strings.TrimSpace(token), ok
+}
@@ -0,0 +1,109 @@
+package oauth
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zitadel/oidc/v3/pkg/oidc"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/authorization"
+	"github.com/zitadel/zitadel-go/v3/pkg/zitadel"
+)
+
+// verifierFunc implements [authorization.Verifier] for the tests.
+type verifierFunc func(ctx context.Context, authorizationToken string) (*IntrospectionContext, error)
+
+func (f verifierFunc) CheckAuthorization(ctx context.Context, authorizationToken string) (*IntrospectionContext, error) {
+	return f(ctx, authorizationToken)
+}
+
+func TestWithBreakGlass(t *testing.T) {
+	available := true
+	verifier := verifierFunc(func(_ context.Context, authorizationToken string) (*IntrospectionContext, error) {
+		if !available {
+			return nil, fmt.Errorf("%w: %w: connection refused", ErrIntrospectionFailed, authorization.ErrUnavailable)
+		}
+		if authorizationToken == "Bearer service" {
+			return &IntrospectionContext{IntrospectionResponse: oidc.IntrospectionResponse{Active: true, Subject: "tooling"}}, nil
+		}
+		return &IntrospectionContext{}, nil
+	})
+	initVerifier := WithBreakGlass(
+		func(context.Context, *zitadel.Zitadel) (authorization.Verifier[*IntrospectionContext], error) {
+			return verifier, nil
+		},
+		WithBreakGlassTokens(
+			BreakGlassToken{Name: "oncall", TokenHash: HashToken("glass"), Subject: "oncall", OrganizationID: "org", Roles: []string{"admin"}, ExpiresAt: time.Now().Add(time.Hour)},
+			BreakGlassToken{Name: "expired", TokenHash: HashToken("old"), ExpiresAt: time.Now().Add(-time.Hour)},
+		),
+		WithAllowlistedIdentities(time.Hour, "tooling"),
+	)
+	v, err := initVerifier(context.Background(), nil)
+	require.NoError(t, err)
+
+	// break-glass tokens are not accepted while introspection is available
+	resp, err := v.CheckAuthorization(context.Background(), "Bearer glass")
+	require.NoError(t, err)
+	assert.False(t, resp.IsAuthorized())
+	_, err = v.CheckAuthorization(context.Background(), "Bearer service")
+	require.NoError(t, err)
+
+	available = false
+	resp, err = v.CheckAuthorization(context.Background(), "Bearer glass")
+	require.NoError(t, err)
+	assert.True(t, resp.IsAuthorized())
+	assert.Equal(t, "oncall", resp.UserID())
+	assert.Equal(t, "org", resp.OrganizationID())
+	assert.True(t, resp.IsGrantedRoleInOrganization("admin", "org"))
+	assert.Equal(t, "oncall", resp.Claims[ClaimBreakGlass])
+
+	resp, err = v.CheckAuthorization(context.Background(), "Bearer service")
+	require.NoError(t, err)
+	assert.Equal(t, "tooling", resp.UserID())
+	assert.Equal(t, "identity", resp.Claims[ClaimBreakGlass])
+
+	_, err = v.CheckAuthorization(context.Background(), "Bearer old")
+	assert.ErrorIs(t, err, ErrIntrospectionFailed)
+	_, err = v.CheckAuthorization(context.Background(), "Bearer unknown")
+	assert.ErrorIs(t, err, ErrIntrospectionFailed)
+}
+
+func TestWithBreakGlass_revokedIdentity(t *testing.T) {
+	available, revoked := true, false
+	verifier := verifierFunc(func(context.Context, string) (*IntrospectionContext, error) {
+		switch {
+		case !available:
+			return nil, fmt.Errorf("%w: %w: connection refused", ErrIntrospectionFailed, authorization.ErrUnavailable)
+		case revoked:
+			return &IntrospectionContext{IntrospectionResponse: oidc.IntrospectionResponse{Active: false}}, nil
+		default:
+			return &IntrospectionContext{IntrospectionResponse: oidc.IntrospectionResponse{Active: true, Subject: "tooling"}}, nil
+		}
+	})
+	initVerifier := WithBreakGlass(
+		func(context.Context, *zitadel.Zitadel) (authorization.Verifier[*IntrospectionContext], error) {
+			return verifier, nil
+		},
+		WithAllowlistedIdentities(time.Hour, "tooling"),
+	)
+	v, err := initVerifier(context.Background(), nil)
+	require.NoError(t, err)
+
+	resp, err := v.CheckAuthorization(context.Background(), "Bearer service")
+	require.NoError(t, err)
+	assert.True(t, resp.IsAuthorized())
+
+	// the token is revoked, so it's rejected and must not be allowed during the next outage
+	revoked = true
+	resp, err = v.CheckAuthorization(context.Background(), "Bearer service")
+	require.NoError(t, err)
+	assert.False(t, resp.IsAuthorized())
+
+	available = false
+	_, err = v.CheckAuthorization(context.Background(), "Bearer service")
+	assert.ErrorIs(t, err, authorization.ErrUnavailable)
+}
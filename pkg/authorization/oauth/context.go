@@ -1,6 +1,10 @@
 package oauth
 
-import "github.com/zitadel/oidc/v3/pkg/oidc"
+import (
+	"time"
+
+	"github.com/zitadel/oidc/v3/pkg/oidc"
+)
 
 // IntrospectionContext implements the [authorization.Ctx] interface with the [oidc.IntrospectionResponse] as underlying data.
 type IntrospectionContext struct {
@@ -53,6 +57,41 @@ func (c *IntrospectionContext) IsGrantedRoleInOrganization(role, organizationID
 	return ok
 }
 
+// GetIssuer returns the `iss` claim of the [oidc.IntrospectionResponse].
+func (c *IntrospectionContext) GetIssuer() string {
+	if c == nil {
+		return ""
+	}
+	return c.IntrospectionResponse.Issuer
+}
+
+// GetAudience returns the `aud` claim of the [oidc.IntrospectionResponse].
+func (c *IntrospectionContext) GetAudience() []string {
+	if c == nil {
+		return nil
+	}
+	return c.IntrospectionResponse.Audience
+}
+
+// ExpiresAt implements [authorization.ExpiringCtx] by returning the `exp` claim of the [oidc.IntrospectionResponse].
+func (c *IntrospectionContext) ExpiresAt() time.Time {
+	if c == nil {
+		return time.Time{}
+	}
+	return c.IntrospectionResponse.Expiration.AsTime()
+}
+
+// ConfirmationThumbprint returns the `jkt` of the `cnf` claim, which is the JWK thumbprint of the key
+// a DPoP bound token is bound to.
+func (c *IntrospectionContext) ConfirmationThumbprint() string {
+	if c == nil {
+		return ""
+	}
+	cnf, _ := c.IntrospectionResponse.Claims["cnf"].(map[string]interface{})
+	jkt, _ := cnf["jkt"].(string)
+	return jkt
+}
+
 func (c *IntrospectionContext) SetToken(token string) {
 	c.token = token
 }
@@ -3,7 +3,7 @@ package client
 import (
 	"context"
 	"fmt"
-	"net/http"
+	"slices"
 	"strings"
 
 	"github.com/zitadel/oidc/v3/pkg/client"
@@ -15,12 +15,14 @@ import (
 	"github.com/zitadel/zitadel-go/v3/pkg/authorization"
 )
 
+// TokenSourceInitializer initializes the [oauth2.TokenSource] used for authorization of the client.
+// Requests to ZITADEL (e.g. discovery) should use the [http.Client] set as [oauth2.HTTPClient] in the context (if any).
 type TokenSourceInitializer func(ctx context.Context, issuer string) (oauth2.TokenSource, error)
 
 // JWTAuthentication allows using the OAuth2 JWT Profile Grant to get a token using a key.json of a service user provided by ZITADEL.
 func JWTAuthentication(file *client.KeyFile, scopes ...string) TokenSourceInitializer {
 	return func(ctx context.Context, issuer string) (oauth2.TokenSource, error) {
-		return profile.NewJWTProfileTokenSource(ctx, issuer, file.UserID, file.KeyID, []byte(file.Key), scopes)
+		return profile.NewJWTProfileTokenSource(ctx, issuer, file.UserID, file.KeyID, []byte(file.Key), scopes, profile.WithHTTPClient(httpClient(ctx)))
 	}
 }
 
@@ -28,18 +30,47 @@ func JWTAuthentication(file *client.KeyFile, scopes ...string) TokenSourceInitia
 // of a service user provided by ZITADEL.
 func PasswordAuthentication(username, password string, scopes ...string) TokenSourceInitializer {
 	return func(ctx context.Context, issuer string) (oauth2.TokenSource, error) {
-		discovery, err := client.Discover(ctx, issuer, http.DefaultClient)
-		if err != nil {
-			return nil, err
-		}
-		config := &clientcredentials.Config{
-			ClientID:     username,
-			ClientSecret: password,
-			TokenURL:     discovery.TokenEndpoint,
-			Scopes:       scopes,
+		return clientCredentialsTokenSource(ctx, issuer, username, password, scopes)
+	}
+}
+
+// ClientCredentialsAuthentication allows using the OAuth2 Client Credentials Grant to get a token
+// using the client_id and client_secret of an API application or service user.
+// The scopes are composed automatically: `openid` and the audience of the ZITADEL API ([ScopeZitadelAPI])
+// are always requested, so the token can be used by the client. Additional API projects can be added
+// to the audience using [ScopeProjectID].
+func ClientCredentialsAuthentication(clientID, clientSecret string, scopes ...string) TokenSourceInitializer {
+	return func(ctx context.Context, issuer string) (oauth2.TokenSource, error) {
+		return clientCredentialsTokenSource(ctx, issuer, clientID, clientSecret, composeScopes(scopes))
+	}
+}
+
+// clientCredentialsTokenSource discovers the token endpoint of the issuer and returns a token source
+// of the OAuth2 Client Credentials Grant.
+// The token source refreshes the token for the whole lifetime of the client,
+// so it only keeps the values (e.g. the [oauth2.HTTPClient]) of the context, but not its cancellation.
+func clientCredentialsTokenSource(ctx context.Context, issuer, clientID, clientSecret string, scopes []string) (oauth2.TokenSource, error) {
+	discovery, err := client.Discover(ctx, issuer, httpClient(ctx))
+	if err != nil {
+		return nil, err
+	}
+	config := &clientcredentials.Config{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		TokenURL:     discovery.TokenEndpoint,
+		Scopes:       scopes,
+	}
+	return config.TokenSource(context.WithoutCancel(ctx)), nil
+}
+
+func composeScopes(scopes []string) []string {
+	composed := make([]string, 0, len(scopes)+2)
+	for _, scope := range append([]string{oidc.ScopeOpenID, ScopeZitadelAPI()}, scopes...) {
+		if !slices.Contains(composed, scope) {
+			composed = append(composed, scope)
 		}
-		return config.TokenSource(ctx), nil
 	}
+	return composed
 }
 
 // PAT allows setting a service user personal access token to be used for authorization.
@@ -0,0 +1,126 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zitadel/oidc/v3/pkg/oidc"
+	"golang.org/x/oauth2"
+)
+
+func Test_composeScopes(t *testing.T) {
+	tests := []struct {
+		name   string
+		scopes []string
+		want   []string
+	}{
+		{
+			name: "none",
+			want: []string{oidc.ScopeOpenID, ScopeZitadelAPI()},
+		},
+		{
+			name:   "additional",
+			scopes: []string{oidc.ScopeProfile, ScopeProjectID("project1")},
+			want:   []string{oidc.ScopeOpenID, ScopeZitadelAPI(), oidc.ScopeProfile, ScopeProjectID("project1")},
+		},
+		{
+			name:   "duplicates",
+			scopes: []string{ScopeZitadelAPI(), oidc.ScopeOpenID, oidc.ScopeEmail, oidc.ScopeEmail},
+			want:   []string{oidc.ScopeOpenID, ScopeZitadelAPI(), oidc.ScopeEmail},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, composeScopes(tt.scopes))
+		})
+	}
+}
+
+// newClientCredentialsServer serves the discovery and a token endpoint of the client credentials grant
+// for the client `backend` and returns the scopes of the token requests.
+func newClientCredentialsServer(t *testing.T) (*httptest.Server, func() []string) {
+	var (
+		mu     sync.Mutex
+		scopes []string
+	)
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{"issuer": server.URL, "token_endpoint": server.URL + "/oauth/v2/token"})
+	})
+	mux.HandleFunc("/oauth/v2/token", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		clientID, secret, _ := r.BasicAuth()
+		if r.PostForm.Get("grant_type") != "client_credentials" || clientID != "backend" || secret != "secret" {
+			http.Error(w, `{"error":"invalid_client"}`, http.StatusUnauthorized)
+			return
+		}
+		mu.Lock()
+		scopes = append(scopes, r.PostForm.Get("scope"))
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		// expired tokens, so every call to the token source requests a new one
+		_ = json.NewEncoder(w).Encode(map[string]any{"access_token": "token", "token_type": "Bearer", "expires_in": 1})
+	})
+	return server, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return scopes
+	}
+}
+
+func TestClientCredentialsTokenSource(t *testing.T) {
+	tests := []struct {
+		name      string
+		init      TokenSourceInitializer
+		wantScope string
+		wantErr   bool
+	}{
+		{
+			name:      "client credentials",
+			init:      ClientCredentialsAuthentication("backend", "secret", ScopeProjectID("project1")),
+			wantScope: strings.Join([]string{oidc.ScopeOpenID, ScopeZitadelAPI(), ScopeProjectID("project1")}, " "),
+		},
+		{
+			name:      "password",
+			init:      PasswordAuthentication("backend", "secret", oidc.ScopeOpenID),
+			wantScope: oidc.ScopeOpenID,
+		},
+		{
+			name:    "invalid secret",
+			init:    ClientCredentialsAuthentication("backend", "wrong"),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, scopes := newClientCredentialsServer(t)
+			ctx, cancel := context.WithCancel(context.WithValue(context.Background(), oauth2.HTTPClient, server.Client()))
+			defer cancel()
+			tokenSource, err := tt.init(ctx, server.URL)
+			require.NoError(t, err)
+
+			token, err := tokenSource.Token()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "token", token.AccessToken)
+
+			// the context of the client creation is done, but the token must still be refreshed
+			cancel()
+			_, err = tokenSource.Token()
+			require.NoError(t, err)
+			assert.Equal(t, []string{tt.wantScope, tt.wantScope}, scopes())
+		})
+	}
+}
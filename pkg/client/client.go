@@ -2,10 +2,16 @@ package client
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
+	"net"
 	"sync"
+	"time"
 
+	"golang.org/x/exp/maps"
 	"golang.org/x/oauth2"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
 
 	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/admin"
 	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/auth"
@@ -21,12 +27,44 @@ import (
 	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/system"
 	userV2 "github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/user/v2"
 	userV2Beta "github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/user/v2beta"
+	"github.com/zitadel/zitadel-go/v3/pkg/dpop"
 	"github.com/zitadel/zitadel-go/v3/pkg/zitadel"
 )
 
+// ErrConnectionClosed is returned by [Client.WaitForReady] if the connection was closed.
+var ErrConnectionClosed = errors.New("connection closed")
+
 type clientOptions struct {
 	initTokenSource TokenSourceInitializer
+	tokenCache      TokenCache
+	tokenCacheKey   string
 	grpcDialOptions []grpc.DialOption
+	// unaryInterceptors and streamInterceptors are installed by options such as [WithRetry]
+	unaryInterceptors  []grpc.UnaryClientInterceptor
+	streamInterceptors []grpc.StreamClientInterceptor
+	// serviceEndpoints maps services to a host:port other than the default one
+	serviceEndpoints    map[Service]string
+	healthCheckInterval time.Duration
+	// tlsConfigHooks allow to customize the TLS configuration, e.g. to provide a client certificate
+	tlsConfigHooks []func(*tls.Config)
+	// dialer and pinnedAddresses are used for the gRPC connection and the HTTP requests of the authentication
+	dialer          DialFunc
+	pinnedAddresses map[string][]string
+	transport       Transport
+	dpopProver      *dpop.Prover
+	// keyFileWatcher is set by [WithKeyFileWatch] and stopped when the client is closed
+	keyFileWatcher *keyFileWatcher
+	// poolSize is the number of gRPC connections per endpoint, see [WithConnectionPool]
+	poolSize int
+	// defaultTimeout and serviceTimeouts are applied to calls without deadline, see [WithDefaultTimeout]
+	defaultTimeout  time.Duration
+	serviceTimeouts map[Service]time.Duration
+	// compressor is the name of the compressor of all calls, see [WithCompression]
+	compressor string
+	// spiffe provides the client certificate for mutual TLS, see [WithSPIFFE]
+	spiffe *spiffeConfig
+	// telemetry instruments the HTTP requests of the REST transport and [Client.HTTPClient], see [WithTelemetry]
+	telemetry *telemetry
 }
 
 type Option func(*clientOptions)
@@ -47,6 +85,28 @@ func WithGRPCDialOptions(opts ...grpc.DialOption) Option {
 	}
 }
 
+// WithUnaryInterceptors installs the interceptors for all unary calls.
+// Unlike interceptors passed by [WithGRPCDialOptions], they are also used with [TransportREST].
+func WithUnaryInterceptors(interceptors ...grpc.UnaryClientInterceptor) Option {
+	return func(c *clientOptions) {
+		c.unaryInterceptors = append(c.unaryInterceptors, interceptors...)
+	}
+}
+
+func (o *clientOptions) dialOptions() []grpc.DialOption {
+	dialOptions := make([]grpc.DialOption, 0, len(o.grpcDialOptions)+3)
+	if o.hasCustomDialer() {
+		dialOptions = append(dialOptions, o.grpcDialer())
+	}
+	if len(o.unaryInterceptors) > 0 {
+		dialOptions = append(dialOptions, grpc.WithChainUnaryInterceptor(o.unaryInterceptors...))
+	}
+	if len(o.streamInterceptors) > 0 {
+		dialOptions = append(dialOptions, grpc.WithChainStreamInterceptor(o.streamInterceptors...))
+	}
+	return append(dialOptions, o.grpcDialOptions...)
+}
+
 type clientOnce struct {
 	systemService         sync.Once
 	adminService          sync.Once
@@ -65,8 +125,20 @@ type clientOnce struct {
 }
 
 type Client struct {
+	// connection is the default gRPC connection, it's nil when using [TransportREST]
 	connection *grpc.ClientConn
-	once       clientOnce
+	// invoker is used to create the service clients,
+	// which is either the default gRPC connection or the REST transport
+	invoker grpc.ClientConnInterface
+	// serviceConnections contains the connections of services routed to a different endpoint
+	serviceConnections map[Service]grpc.ClientConnInterface
+	once               clientOnce
+	healthChanges      chan HealthStatus
+	stopHealthCheck    context.CancelFunc
+	keyFileWatcher     *keyFileWatcher
+	// origin and httpTransport are used for the HTTP/JSON APIs, see [Client.HTTPClient]
+	origin        string
+	httpTransport *authTransport
 
 	systemService         system.SystemServiceClient
 	adminService          admin.AdminServiceClient
@@ -84,145 +156,297 @@ type Client struct {
 	oidcServiceV2         oidcV2_pb.OIDCServiceClient
 }
 
-func New(ctx context.Context, zitadel *zitadel.Zitadel, opts ...Option) (*Client, error) {
+func New(ctx context.Context, zitadel *zitadel.Zitadel, opts ...Option) (_ *Client, err error) {
 	var options clientOptions
 	for _, o := range opts {
 		o(&options)
 	}
+	// the watch of the key file is started by the token source initialization and owned by the client once created
+	defer func() {
+		if err != nil && options.keyFileWatcher != nil {
+			options.keyFileWatcher.close()
+		}
+	}()
+	options.installTimeouts()
+	if err = options.installCompression(); err != nil {
+		return nil, err
+	}
+	if err = options.installSPIFFE(); err != nil {
+		return nil, err
+	}
 
 	var source oauth2.TokenSource
 	if options.initTokenSource != nil {
-		var err error
-		source, err = options.initTokenSource(ctx, zitadel.Origin())
+		authCtx := ctx
+		if options.hasCustomDialer() || options.dpopProver != nil {
+			authCtx = context.WithValue(ctx, oauth2.HTTPClient, options.httpClient())
+		}
+		source, err = options.initTokenSource(authCtx, zitadel.Origin())
 		if err != nil {
 			return nil, err
 		}
+		if options.tokenCache != nil {
+			cached := newCachedTokenSource(source, options.tokenCache, options.tokenCacheKey)
+			options.unaryInterceptors = append(options.unaryInterceptors, cached.unaryInterceptor())
+			options.streamInterceptors = append(options.streamInterceptors, cached.streamInterceptor())
+			source = cached
+		}
 	}
 
-	conn, err := newConnection(ctx, zitadel, source, options.grpcDialOptions...)
+	var connect connectFunc = newConnection
+	if options.transport == TransportREST {
+		connect = newRESTConnection
+	} else if options.poolSize > 1 {
+		connect = pooled(connect, options.poolSize)
+	}
+	conn, err := connect(ctx, zitadel, zitadel.Host(), source, &options)
 	if err != nil {
 		return nil, err
 	}
-
-	return &Client{
-		connection: conn,
-	}, nil
+	httpTransport, err := restTransport(zitadel, zitadel.Host(), &options)
+	if err != nil {
+		if closer, ok := conn.(interface{ Close() error }); ok {
+			closer.Close()
+		}
+		return nil, err
+	}
+	c := &Client{
+		invoker:            conn,
+		serviceConnections: make(map[Service]grpc.ClientConnInterface, len(options.serviceEndpoints)),
+		keyFileWatcher:     options.keyFileWatcher,
+		origin:             zitadel.Origin(),
+		httpTransport: &authTransport{
+			cred: &cred{tls: zitadel.IsTLS(), tokenSource: source},
+			base: httpTransport,
+		},
+	}
+	switch conn := conn.(type) {
+	case *grpc.ClientConn:
+		c.connection = conn
+	case *connectionPool:
+		c.connection = conn.primary()
+	}
+	// services sharing the same endpoint will also share the connection
+	endpoints := make(map[string]grpc.ClientConnInterface, len(options.serviceEndpoints))
+	for service, hostPort := range options.serviceEndpoints {
+		serviceConn, ok := endpoints[hostPort]
+		if !ok {
+			serviceConn, err = connect(ctx, zitadel, hostPort, source, &options)
+			if err != nil {
+				c.Close()
+				return nil, err
+			}
+			endpoints[hostPort] = serviceConn
+		}
+		c.serviceConnections[service] = serviceConn
+	}
+	if options.healthCheckInterval > 0 {
+		c.startHealthCheck(options.healthCheckInterval)
+	}
+	return c, nil
 }
 
 func newConnection(
 	ctx context.Context,
 	zitadel *zitadel.Zitadel,
+	target string,
 	tokenSource oauth2.TokenSource,
-	opts ...grpc.DialOption,
-) (*grpc.ClientConn, error) {
-	transportCreds, err := transportCredentials(zitadel.Domain(), zitadel.IsTLS(), zitadel.IsInsecureSkipVerifyTLS())
+	options *clientOptions,
+) (grpc.ClientConnInterface, error) {
+	serverName := zitadel.Domain()
+	if target != zitadel.Host() {
+		serverName = hostname(target)
+	}
+	transportCreds, err := transportCredentials(serverName, zitadel.IsTLS(), zitadel.IsInsecureSkipVerifyTLS(), options.tlsConfigHooks...)
 	if err != nil {
 		return nil, err
 	}
 
 	dialOptions := []grpc.DialOption{
 		grpc.WithTransportCredentials(transportCreds),
-		grpc.WithPerRPCCredentials(&cred{tls: zitadel.IsTLS(), tokenSource: tokenSource}),
+		grpc.WithPerRPCCredentials(&cred{
+			tls:         zitadel.IsTLS(),
+			tokenSource: tokenSource,
+			dpopProver:  options.dpopProver,
+			origin:      restOrigin(zitadel, target),
+		}),
+	}
+	dialOptions = append(dialOptions, options.dialOptions()...)
+
+	return grpc.DialContext(ctx, target, dialOptions...)
+}
+
+func hostname(hostPort string) string {
+	host, _, err := net.SplitHostPort(hostPort)
+	if err != nil {
+		return hostPort
 	}
-	dialOptions = append(dialOptions, opts...)
+	return host
+}
+
+// conn returns the connection to be used for the service.
+func (c *Client) conn(service Service) grpc.ClientConnInterface {
+	if conn, ok := c.serviceConnections[service]; ok {
+		return conn
+	}
+	return c.invoker
+}
 
-	return grpc.DialContext(ctx, zitadel.Host(), dialOptions...)
+// Conn returns the underlying authenticated gRPC connection.
+// It can be used to create additional (custom) service stubs using the same channel.
+// When using [TransportREST] there is no gRPC connection and nil is returned.
+func (c *Client) Conn() *grpc.ClientConn {
+	return c.connection
+}
+
+// State returns the current [connectivity.State] of the underlying connection.
+// When using [TransportREST] the state is always [connectivity.Ready].
+func (c *Client) State() connectivity.State {
+	if c.connection == nil {
+		return connectivity.Ready
+	}
+	return c.connection.GetState()
+}
+
+// WaitForReady will trigger the connection to be established (if idle) and block until it's ready,
+// the connection is closed ([ErrConnectionClosed]) or the context is done.
+func (c *Client) WaitForReady(ctx context.Context) error {
+	if c.connection == nil {
+		return nil
+	}
+	c.connection.Connect()
+	for {
+		state := c.connection.GetState()
+		switch state {
+		case connectivity.Ready:
+			return nil
+		case connectivity.Shutdown:
+			return ErrConnectionClosed
+		}
+		if !c.connection.WaitForStateChange(ctx, state) {
+			return ctx.Err()
+		}
+	}
+}
+
+// Close closes the underlying connection(s).
+func (c *Client) Close() error {
+	if c.stopHealthCheck != nil {
+		c.stopHealthCheck()
+	}
+	if c.keyFileWatcher != nil {
+		c.keyFileWatcher.close()
+	}
+	if c.httpTransport != nil {
+		c.httpTransport.CloseIdleConnections()
+	}
+	closed := make(map[grpc.ClientConnInterface]bool, len(c.serviceConnections)+1)
+	var errs []error
+	for _, conn := range append(maps.Values(c.serviceConnections), c.invoker) {
+		closer, ok := conn.(interface{ Close() error })
+		if !ok || closed[conn] {
+			continue
+		}
+		closed[conn] = true
+		errs = append(errs, closer.Close())
+	}
+	return errors.Join(errs...)
 }
 
 func (c *Client) SystemService() system.SystemServiceClient {
 	c.once.systemService.Do(func() {
-		c.systemService = system.NewSystemServiceClient(c.connection)
+		c.systemService = system.NewSystemServiceClient(c.conn(ServiceSystem))
 	})
 	return c.systemService
 }
 
 func (c *Client) AdminService() admin.AdminServiceClient {
 	c.once.adminService.Do(func() {
-		c.adminService = admin.NewAdminServiceClient(c.connection)
+		c.adminService = admin.NewAdminServiceClient(c.conn(ServiceAdmin))
 	})
 	return c.adminService
 }
 
 func (c *Client) ManagementService() management.ManagementServiceClient {
 	c.once.managementService.Do(func() {
-		c.managementService = management.NewManagementServiceClient(c.connection)
+		c.managementService = management.NewManagementServiceClient(c.conn(ServiceManagement))
 	})
 	return c.managementService
 }
 
 func (c *Client) AuthService() auth.AuthServiceClient {
 	c.once.authService.Do(func() {
-		c.authService = auth.NewAuthServiceClient(c.connection)
+		c.authService = auth.NewAuthServiceClient(c.conn(ServiceAuth))
 	})
 	return c.authService
 }
 
 func (c *Client) UserService() userV2Beta.UserServiceClient {
 	c.once.userService.Do(func() {
-		c.userService = userV2Beta.NewUserServiceClient(c.connection)
+		c.userService = userV2Beta.NewUserServiceClient(c.conn(ServiceUserV2Beta))
 	})
 	return c.userService
 }
 
 func (c *Client) UserServiceV2() userV2.UserServiceClient {
 	c.once.userServiceV2.Do(func() {
-		c.userServiceV2 = userV2.NewUserServiceClient(c.connection)
+		c.userServiceV2 = userV2.NewUserServiceClient(c.conn(ServiceUserV2))
 	})
 	return c.userServiceV2
 }
 
 func (c *Client) SettingsService() settingsV2Beta.SettingsServiceClient {
 	c.once.settingsService.Do(func() {
-		c.settingsService = settingsV2Beta.NewSettingsServiceClient(c.connection)
+		c.settingsService = settingsV2Beta.NewSettingsServiceClient(c.conn(ServiceSettingsV2Beta))
 	})
 	return c.settingsService
 }
 
 func (c *Client) SettingsServiceV2() settingsV2.SettingsServiceClient {
 	c.once.settingsServiceV2.Do(func() {
-		c.settingsServiceV2 = settingsV2.NewSettingsServiceClient(c.connection)
+		c.settingsServiceV2 = settingsV2.NewSettingsServiceClient(c.conn(ServiceSettingsV2))
 	})
 	return c.settingsServiceV2
 }
 
 func (c *Client) SessionService() sessionV2Beta.SessionServiceClient {
 	c.once.sessionService.Do(func() {
-		c.sessionService = sessionV2Beta.NewSessionServiceClient(c.connection)
+		c.sessionService = sessionV2Beta.NewSessionServiceClient(c.conn(ServiceSessionV2Beta))
 	})
 	return c.sessionService
 }
 
 func (c *Client) SessionServiceV2() sessionV2.SessionServiceClient {
 	c.once.sessionServiceV2.Do(func() {
-		c.sessionServiceV2 = sessionV2.NewSessionServiceClient(c.connection)
+		c.sessionServiceV2 = sessionV2.NewSessionServiceClient(c.conn(ServiceSessionV2))
 	})
 	return c.sessionServiceV2
 }
 
 func (c *Client) OIDCService() oidcV2Beta_pb.OIDCServiceClient {
 	c.once.oidcService.Do(func() {
-		c.oidcService = oidcV2Beta_pb.NewOIDCServiceClient(c.connection)
+		c.oidcService = oidcV2Beta_pb.NewOIDCServiceClient(c.conn(ServiceOIDCV2Beta))
 	})
 	return c.oidcService
 }
 
 func (c *Client) OIDCServiceV2() oidcV2_pb.OIDCServiceClient {
 	c.once.oidcServiceV2.Do(func() {
-		c.oidcServiceV2 = oidcV2_pb.NewOIDCServiceClient(c.connection)
+		c.oidcServiceV2 = oidcV2_pb.NewOIDCServiceClient(c.conn(ServiceOIDCV2))
 	})
 	return c.oidcServiceV2
 }
 
 func (c *Client) OrganizationService() orgV2Beta.OrganizationServiceClient {
 	c.once.organizationService.Do(func() {
-		c.organizationService = orgV2Beta.NewOrganizationServiceClient(c.connection)
+		c.organizationService = orgV2Beta.NewOrganizationServiceClient(c.conn(ServiceOrganizationV2Beta))
 	})
 	return c.organizationService
 }
 
 func (c *Client) OrganizationServiceV2() orgV2.OrganizationServiceClient {
 	c.once.organizationServiceV2.Do(func() {
-		c.organizationServiceV2 = orgV2.NewOrganizationServiceClient(c.connection)
+		c.organizationServiceV2 = orgV2.NewOrganizationServiceClient(c.conn(ServiceOrganizationV2))
 	})
 	return c.organizationServiceV2
 }
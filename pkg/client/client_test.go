@@ -0,0 +1,63 @@
+package client
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/connectivity"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/zitadel"
+)
+
+func TestClient_WaitForReady(t *testing.T) {
+	server := newTestServer(t)
+	c, err := New(context.Background(), server.zitadel(t), WithAuth(PAT("token")))
+	require.NoError(t, err)
+	defer c.Close()
+	require.NotNil(t, c.Conn())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, c.WaitForReady(ctx))
+	assert.Equal(t, connectivity.Ready, c.State())
+
+	server.server.Stop()
+	require.True(t, c.Conn().WaitForStateChange(ctx, connectivity.Ready))
+	assert.NotEqual(t, connectivity.Ready, c.State(), "the connection is lost")
+
+	require.NoError(t, c.Close())
+	assert.Equal(t, connectivity.Shutdown, c.State())
+	assert.ErrorIs(t, c.WaitForReady(ctx), ErrConnectionClosed)
+}
+
+func TestClient_WaitForReady_timeout(t *testing.T) {
+	// reserve a port nobody is listening on
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	host, port, err := net.SplitHostPort(listener.Addr().String())
+	require.NoError(t, err)
+	require.NoError(t, listener.Close())
+
+	c, err := New(context.Background(), zitadel.New(host, zitadel.WithInsecure(port)), WithAuth(PAT("token")))
+	require.NoError(t, err)
+	defer c.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, c.WaitForReady(ctx), context.DeadlineExceeded)
+	assert.Contains(t, []connectivity.State{connectivity.Connecting, connectivity.TransientFailure}, c.State())
+}
+
+func TestClient_WaitForReady_rest(t *testing.T) {
+	c, err := New(context.Background(), zitadel.New("localhost", zitadel.WithInsecure("0")), WithAuth(PAT("token")), WithTransport(TransportREST))
+	require.NoError(t, err)
+	defer c.Close()
+
+	assert.Nil(t, c.Conn())
+	assert.Equal(t, connectivity.Ready, c.State())
+	assert.NoError(t, c.WaitForReady(context.Background()))
+}
@@ -0,0 +1,241 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/admin"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/auth"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/management"
+	objectV2 "github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/object/v2"
+	oidcV2_pb "github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/oidc/v2"
+	oidcV2Beta_pb "github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/oidc/v2beta"
+	orgV2 "github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/org/v2"
+	orgV2Beta "github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/org/v2beta"
+	sessionV2 "github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/session/v2"
+	sessionV2Beta "github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/session/v2beta"
+	settingsV2 "github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/settings/v2"
+	settingsV2Beta "github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/settings/v2beta"
+	userV2 "github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/user/v2"
+	userV2Beta "github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/user/v2beta"
+)
+
+const (
+	// MinServerVersion is the oldest ZITADEL version serving all APIs of the SDK.
+	MinServerVersion = "v2.62.0"
+	// VersionHeader is the response header the version of ZITADEL is read from, if the server reports it.
+	VersionHeader = "x-zitadel-version"
+)
+
+// ServiceStatus is the result of probing a [Service] by [Client.CompatibilityReport].
+type ServiceStatus struct {
+	Service Service
+	// Available is false if the server does not serve the service (UNIMPLEMENTED).
+	Available bool
+	// Err is the error of the probe, if it failed for another reason than the expected validation error,
+	// e.g. if the server is not reachable.
+	Err error
+}
+
+// CompatibilityReport describes the compatibility of the SDK and the connected ZITADEL.
+type CompatibilityReport struct {
+	// ServerVersion is the version reported by ZITADEL, empty if the server doesn't report it.
+	ServerVersion string
+	// MinServerVersion is the oldest version supported by the SDK, see [MinServerVersion].
+	MinServerVersion string
+	Services         []ServiceStatus
+	// Issues are the detected incompatibilities, e.g. services not served by the server.
+	Issues []string
+}
+
+// Compatible returns if no incompatibilities were detected.
+func (r *CompatibilityReport) Compatible() bool {
+	return len(r.Issues) == 0
+}
+
+// String returns a human readable representation of the report.
+func (r *CompatibilityReport) String() string {
+	var b strings.Builder
+	version := r.ServerVersion
+	if version == "" {
+		version = "unknown"
+	}
+	fmt.Fprintf(&b, "server version: %s (supported: >= %s)\n", version, r.MinServerVersion)
+	for _, s := range r.Services {
+		state := "available"
+		switch {
+		case s.Err != nil:
+			state = "error: " + s.Err.Error()
+		case !s.Available:
+			state = "not served"
+		}
+		fmt.Fprintf(&b, "%s: %s\n", s.Service, state)
+	}
+	for _, issue := range r.Issues {
+		fmt.Fprintf(&b, "issue: %s\n", issue)
+	}
+	return b.String()
+}
+
+// CompatibilityReport detects version skew between the SDK and the connected ZITADEL:
+// it reads the version of ZITADEL (if reported, see [VersionHeader]) from the healthz endpoint of the admin API,
+// compares it against [MinServerVersion] and probes the services used by the SDK with requests, which are
+// rejected by the validation of the server, to list the services not served by the server.
+// The system API is not probed, since it's usually not exposed.
+func (c *Client) CompatibilityReport(ctx context.Context) (*CompatibilityReport, error) {
+	var header metadata.MD
+	if _, err := c.AdminService().Healthz(ctx, &admin.HealthzRequest{}, grpc.Header(&header)); err != nil {
+		return nil, err
+	}
+	var version string
+	if values := header.Get(VersionHeader); len(values) > 0 {
+		version = values[0]
+	}
+	probes := c.compatibilityProbes()
+	results := make([]ServiceStatus, 0, len(probes))
+	for _, p := range probes {
+		results = append(results, probeResult(p.service, p.probe(ctx)))
+	}
+	return newCompatibilityReport(version, results), nil
+}
+
+type compatibilityProbe struct {
+	service Service
+	probe   func(ctx context.Context) error
+}
+
+func (c *Client) compatibilityProbes() []compatibilityProbe {
+	return []compatibilityProbe{
+		{ServiceAdmin, func(ctx context.Context) error {
+			_, err := c.AdminService().Healthz(ctx, &admin.HealthzRequest{})
+			return err
+		}},
+		{ServiceManagement, func(ctx context.Context) error {
+			_, err := c.ManagementService().Healthz(ctx, &management.HealthzRequest{})
+			return err
+		}},
+		{ServiceAuth, func(ctx context.Context) error {
+			_, err := c.AuthService().Healthz(ctx, &auth.HealthzRequest{})
+			return err
+		}},
+		{ServiceUserV2, func(ctx context.Context) error {
+			_, err := c.UserServiceV2().GetUserByID(ctx, &userV2.GetUserByIDRequest{})
+			return err
+		}},
+		{ServiceUserV2Beta, func(ctx context.Context) error {
+			_, err := c.UserService().GetUserByID(ctx, &userV2Beta.GetUserByIDRequest{})
+			return err
+		}},
+		{ServiceSessionV2, func(ctx context.Context) error {
+			_, err := c.SessionServiceV2().GetSession(ctx, &sessionV2.GetSessionRequest{})
+			return err
+		}},
+		{ServiceSessionV2Beta, func(ctx context.Context) error {
+			_, err := c.SessionService().GetSession(ctx, &sessionV2Beta.GetSessionRequest{})
+			return err
+		}},
+		{ServiceSettingsV2, func(ctx context.Context) error {
+			_, err := c.SettingsServiceV2().GetGeneralSettings(ctx, &settingsV2.GetGeneralSettingsRequest{})
+			return err
+		}},
+		{ServiceSettingsV2Beta, func(ctx context.Context) error {
+			_, err := c.SettingsService().GetGeneralSettings(ctx, &settingsV2Beta.GetGeneralSettingsRequest{})
+			return err
+		}},
+		{ServiceOrganizationV2, func(ctx context.Context) error {
+			_, err := c.OrganizationServiceV2().ListOrganizations(ctx, &orgV2.ListOrganizationsRequest{Query: &objectV2.ListQuery{Limit: 1}})
+			return err
+		}},
+		{ServiceOrganizationV2Beta, func(ctx context.Context) error {
+			// the only method of the service, the request is rejected, since the name is missing
+			_, err := c.OrganizationService().AddOrganization(ctx, &orgV2Beta.AddOrganizationRequest{})
+			return err
+		}},
+		{ServiceOIDCV2, func(ctx context.Context) error {
+			_, err := c.OIDCServiceV2().GetAuthRequest(ctx, &oidcV2_pb.GetAuthRequestRequest{})
+			return err
+		}},
+		{ServiceOIDCV2Beta, func(ctx context.Context) error {
+			_, err := c.OIDCService().GetAuthRequest(ctx, &oidcV2Beta_pb.GetAuthRequestRequest{})
+			return err
+		}},
+	}
+}
+
+// probeResult interprets the error of a probe: validation, permission and not found errors
+// prove that the service is served.
+func probeResult(service Service, err error) ServiceStatus {
+	switch status.Code(err) {
+	case codes.OK, codes.InvalidArgument, codes.NotFound, codes.PermissionDenied, codes.FailedPrecondition, codes.Unauthenticated:
+		return ServiceStatus{Service: service, Available: true}
+	case codes.Unimplemented:
+		return ServiceStatus{Service: service}
+	default:
+		return ServiceStatus{Service: service, Err: err}
+	}
+}
+
+func newCompatibilityReport(version string, services []ServiceStatus) *CompatibilityReport {
+	r := &CompatibilityReport{
+		ServerVersion:    version,
+		MinServerVersion: MinServerVersion,
+		Services:         services,
+	}
+	if version != "" {
+		if cmp, ok := compareVersions(version, MinServerVersion); ok && cmp < 0 {
+			r.Issues = append(r.Issues, fmt.Sprintf("server version %s is older than the supported %s", version, MinServerVersion))
+		}
+	}
+	for _, s := range services {
+		if !s.Available && s.Err == nil {
+			r.Issues = append(r.Issues, fmt.Sprintf("service %s is not served, the server is older than the SDK or the API is disabled", s.Service))
+		}
+	}
+	return r
+}
+
+// compareVersions compares two semantic versions (e.g. `v2.62.1`), pre-release and build suffixes are ignored.
+// It returns false if one of the versions can't be parsed.
+func compareVersions(a, b string) (int, bool) {
+	va, okA := parseVersion(a)
+	vb, okB := parseVersion(b)
+	if !okA || !okB {
+		return 0, false
+	}
+	for i := range va {
+		if va[i] != vb[i] {
+			if va[i] < vb[i] {
+				return -1, true
+			}
+			return 1, true
+		}
+	}
+	return 0, true
+}
+
+func parseVersion(version string) ([3]int, bool) {
+	var parsed [3]int
+	version = strings.TrimPrefix(version, "v")
+	if i := strings.IndexAny(version, "-+"); i >= 0 {
+		version = version[:i]
+	}
+	parts := strings.Split(version, ".")
+	if len(parts) == 0 || len(parts) > 3 {
+		return parsed, false
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return parsed, false
+		}
+		parsed[i] = n
+	}
+	return parsed, true
+}
@@ -0,0 +1,57 @@
+package client
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func Test_compareVersions(t *testing.T) {
+	tests := []struct {
+		a, b   string
+		want   int
+		wantOK bool
+	}{
+		{"v2.62.0", "v2.62.0", 0, true},
+		{"v2.61.9", "v2.62.0", -1, true},
+		{"v3.0.0-rc.1", "v2.62.0", 1, true},
+		{"2.70", "v2.62.0", 1, true},
+		{"latest", "v2.62.0", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a, func(t *testing.T) {
+			got, ok := compareVersions(tt.a, tt.b)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_newCompatibilityReport(t *testing.T) {
+	unavailable := errors.New("connection refused")
+	services := []ServiceStatus{
+		probeResult(ServiceUserV2, status.Error(codes.InvalidArgument, "id missing")),
+		probeResult(ServiceSessionV2, status.Error(codes.Unimplemented, "unknown service")),
+		probeResult(ServiceAdmin, nil),
+		probeResult(ServiceAuth, unavailable),
+	}
+	assert.Equal(t, []ServiceStatus{
+		{Service: ServiceUserV2, Available: true},
+		{Service: ServiceSessionV2},
+		{Service: ServiceAdmin, Available: true},
+		{Service: ServiceAuth, Err: unavailable},
+	}, services)
+
+	report := newCompatibilityReport("v2.50.3", services)
+	assert.False(t, report.Compatible())
+	assert.Equal(t, []string{
+		"server version v2.50.3 is older than the supported " + MinServerVersion,
+		"service zitadel.session.v2.SessionService is not served, the server is older than the SDK or the API is disabled",
+	}, report.Issues)
+	assert.Contains(t, report.String(), "zitadel.auth.v1.AuthService: error: connection refused")
+
+	assert.True(t, newCompatibilityReport("", services[:1]).Compatible())
+}
@@ -0,0 +1,48 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/encoding/gzip"
+)
+
+// CompressionGzip is the name of the gzip compressor, which is always registered.
+const CompressionGzip = gzip.Name
+
+// ErrUnknownCompressor is returned by [New] if the compressor of [WithCompression] is not registered.
+var ErrUnknownCompressor = errors.New("compressor not registered")
+
+// WithCompression compresses the messages of all calls using the compressor, e.g. [CompressionGzip].
+// ZITADEL compresses the responses using the same compressor, which reduces the bandwidth of large list responses
+// (e.g. ListUsers with thousands of results) at the cost of some CPU.
+// Other compressors must be registered using [encoding.RegisterCompressor] before creating the client.
+//
+// The option has no effect on [TransportREST], whose responses are already compressed with gzip
+// if the server supports it.
+func WithCompression(compressor string) Option {
+	return func(c *clientOptions) {
+		c.compressor = compressor
+	}
+}
+
+// installCompression installs the interceptors requesting the compressor on every call.
+func (o *clientOptions) installCompression() error {
+	if o.compressor == "" || o.transport == TransportREST {
+		return nil
+	}
+	if encoding.GetCompressor(o.compressor) == nil {
+		return ErrUnknownCompressor
+	}
+	useCompressor := grpc.UseCompressor(o.compressor)
+	o.unaryInterceptors = append(o.unaryInterceptors, func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(ctx, method, req, reply, cc, append(slices.Clip(opts), useCompressor)...)
+	})
+	o.streamInterceptors = append(o.streamInterceptors, func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(ctx, desc, cc, method, append(slices.Clip(opts), useCompressor)...)
+	})
+	return nil
+}
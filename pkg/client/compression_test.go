@@ -0,0 +1,52 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+func TestWithCompression(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      []Option
+		wantErr   error
+		wantCalls []grpc.CallOption
+	}{
+		{"gzip", []Option{WithCompression(CompressionGzip)}, nil, []grpc.CallOption{grpc.UseCompressor(CompressionGzip)}},
+		{"unknown compressor", []Option{WithCompression("zstd-unknown")}, ErrUnknownCompressor, nil},
+		{"rest transport", []Option{WithCompression(CompressionGzip), WithTransport(TransportREST)}, nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var options clientOptions
+			for _, opt := range tt.opts {
+				opt(&options)
+			}
+			err := options.installCompression()
+			require.ErrorIs(t, err, tt.wantErr)
+			if tt.wantCalls == nil {
+				assert.Empty(t, options.unaryInterceptors)
+				assert.Empty(t, options.streamInterceptors)
+				return
+			}
+			require.Len(t, options.unaryInterceptors, 1)
+			err = options.unaryInterceptors[0](context.Background(), "/method", nil, nil, nil,
+				func(_ context.Context, _ string, _, _ any, _ *grpc.ClientConn, opts ...grpc.CallOption) error {
+					assert.Equal(t, tt.wantCalls, opts)
+					return nil
+				})
+			require.NoError(t, err)
+			require.Len(t, options.streamInterceptors, 1)
+			_, err = options.streamInterceptors[0](context.Background(), nil, nil, "/method",
+				func(_ context.Context, _ *grpc.StreamDesc, _ *grpc.ClientConn, _ string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+					assert.Equal(t, tt.wantCalls, opts)
+					return nil, nil
+				})
+			require.NoError(t, err)
+		})
+	}
+}
@@ -4,10 +4,14 @@ import (
 	"context"
 	"crypto/tls"
 	"crypto/x509"
+	"net/http"
+	"strings"
 
 	"golang.org/x/oauth2"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/dpop"
 )
 
 type key int
@@ -19,6 +23,9 @@ const (
 type cred struct {
 	tokenSource oauth2.TokenSource
 	tls         bool
+	// dpopProver creates the DPoP proofs of the calls to the origin (if set)
+	dpopProver *dpop.Prover
+	origin     string
 }
 
 // GetRequestMetadata implements [credentials.PerRPCCredentials]
@@ -28,11 +35,11 @@ func (c *cred) GetRequestMetadata(ctx context.Context, uri ...string) (map[strin
 	// if there was an explicit token set, use this
 	token, ok := ctx.Value(ctxOverwrite).(*oauth2.Token)
 	if ok {
-		return requestMetadataFromToken(token), nil
+		return c.requestMetadata(ctx, token)
 	}
 	// check if there was a default token source provided
 	if c.tokenSource != nil {
-		return c.tokenFromTokenSource()
+		return c.tokenFromTokenSource(ctx)
 	}
 	return nil, nil
 }
@@ -42,12 +49,30 @@ func (c *cred) RequireTransportSecurity() bool {
 	return c.tls
 }
 
-func (c *cred) tokenFromTokenSource() (map[string]string, error) {
+func (c *cred) tokenFromTokenSource(ctx context.Context) (map[string]string, error) {
 	token, err := c.tokenSource.Token()
 	if err != nil {
 		return nil, err
 	}
-	return requestMetadataFromToken(token), nil
+	return c.requestMetadata(ctx, token)
+}
+
+// requestMetadata adds a DPoP proof for the called method if the token is bound to the key of the prover.
+func (c *cred) requestMetadata(ctx context.Context, token *oauth2.Token) (map[string]string, error) {
+	md := requestMetadataFromToken(token)
+	if c.dpopProver == nil || !strings.EqualFold(token.Type(), dpop.TokenType) {
+		return md, nil
+	}
+	info, ok := credentials.RequestInfoFromContext(ctx)
+	if !ok {
+		return md, nil
+	}
+	proof, err := c.dpopProver.Proof(http.MethodPost, c.origin+info.Method, token.AccessToken)
+	if err != nil {
+		return nil, err
+	}
+	md[strings.ToLower(dpop.HeaderName)] = proof
+	return md, nil
 }
 
 func requestMetadataFromToken(token *oauth2.Token) map[string]string {
@@ -56,24 +81,34 @@ func requestMetadataFromToken(token *oauth2.Token) map[string]string {
 	}
 }
 
-func transportCredentials(domain string, withTLS bool, insecureSkipVerifyTLS bool) (credentials.TransportCredentials, error) {
+func transportCredentials(domain string, withTLS bool, insecureSkipVerifyTLS bool, hooks ...func(*tls.Config)) (credentials.TransportCredentials, error) {
 	if !withTLS {
 		return insecure.NewCredentials(), nil
 	}
+	tlsConfig, err := clientTLSConfig(domain, insecureSkipVerifyTLS, hooks...)
+	if err != nil {
+		return nil, err
+	}
+	return credentials.NewTLS(tlsConfig), nil
+}
+
+func clientTLSConfig(domain string, insecureSkipVerifyTLS bool, hooks ...func(*tls.Config)) (*tls.Config, error) {
 	tlsConfig := &tls.Config{
 		ServerName:         domain,
 		InsecureSkipVerify: insecureSkipVerifyTLS,
 	}
-	if insecureSkipVerifyTLS {
-		return credentials.NewTLS(tlsConfig), nil
+	if !insecureSkipVerifyTLS {
+		ca, err := x509.SystemCertPool()
+		if err != nil {
+			return nil, err
+		}
+		if ca == nil {
+			ca = x509.NewCertPool()
+		}
+		tlsConfig.RootCAs = ca
 	}
-	ca, err := x509.SystemCertPool()
-	if err != nil {
-		return nil, err
+	for _, hook := range hooks {
+		hook(tlsConfig)
 	}
-	if ca == nil {
-		ca = x509.NewCertPool()
-	}
-	tlsConfig.RootCAs = ca
-	return credentials.NewTLS(tlsConfig), nil
+	return tlsConfig, nil
 }
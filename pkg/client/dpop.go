@@ -0,0 +1,15 @@
+package client
+
+import (
+	"github.com/zitadel/zitadel-go/v3/pkg/dpop"
+)
+
+// WithDPoP binds the tokens of the client to the key of the prover (DPoP, RFC 9449).
+// The proofs are added to the token requests of the authentication as well as to every call,
+// so ZITADEL can issue and verify sender-constrained access tokens.
+// Tokens explicitly set to the context (e.g. [BearerTokenCtx]) are only bound if they are of type DPoP.
+func WithDPoP(prover *dpop.Prover) Option {
+	return func(c *clientOptions) {
+		c.dpopProver = prover
+	}
+}
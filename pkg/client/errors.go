@@ -0,0 +1,25 @@
+package client
+
+import (
+	"github.com/zitadel/zitadel-go/v3/pkg/zerrors"
+)
+
+// WithStructuredErrors converts the gRPC status errors of all calls into a [zerrors.Error],
+// which allows checking the typed errors (e.g. errors.Is(err, zerrors.ErrNotFound)) and reading the error details of ZITADEL.
+func WithStructuredErrors() Option {
+	return func(c *clientOptions) {
+		c.unaryInterceptors = append(c.unaryInterceptors, zerrors.UnaryClientInterceptor())
+		c.streamInterceptors = append(c.streamInterceptors, zerrors.StreamClientInterceptor())
+	}
+}
+
+// WithRedactedErrors redacts personally identifiable information (PII) in the errors of all calls,
+// such as emails and the values of PII fields of the request (e.g. email, login_name), using the redaction,
+// e.g. [zerrors.Mask] or [zerrors.Hash]. The original error is available through errors.As, see [zerrors.RedactedError].
+// It can be combined with [WithStructuredErrors].
+func WithRedactedErrors(redaction zerrors.Redaction) Option {
+	return func(c *clientOptions) {
+		c.unaryInterceptors = append(c.unaryInterceptors, zerrors.RedactingUnaryClientInterceptor(redaction))
+		c.streamInterceptors = append(c.streamInterceptors, zerrors.RedactingStreamClientInterceptor(redaction))
+	}
+}
@@ -0,0 +1,201 @@
+package client
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// WithV2BetaFallback calls the v2beta API instead of the v2 API if the connected ZITADEL doesn't serve
+// the v2 service yet (UNIMPLEMENTED), so the facades using the v2 services (e.g. the users package) also work
+// with older servers. The downgrade of each service is logged once using the logger (default: [slog.Default]),
+// further calls of the service use the v2beta API directly.
+//
+// A call is only downgraded if the v2beta service has a method of the same name and its messages are
+// compatible (all fields of the request exist with the same number and type in the v2beta request and vice versa
+// for the response), otherwise the UNIMPLEMENTED error is returned.
+func WithV2BetaFallback(logger *slog.Logger) Option {
+	return func(c *clientOptions) {
+		if logger == nil {
+			logger = slog.Default()
+		}
+		f := &v2BetaFallback{logger: logger, downgraded: make(map[string]bool)}
+		c.unaryInterceptors = append(c.unaryInterceptors, f.unaryInterceptor())
+	}
+}
+
+type v2BetaFallback struct {
+	logger *slog.Logger
+	// compatible caches the compatibility of the methods
+	compatible sync.Map
+
+	mu sync.Mutex
+	// downgraded contains the services not served by ZITADEL
+	downgraded map[string]bool
+}
+
+func (f *v2BetaFallback) unaryInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		betaMethod, ok := v2BetaMethod(method)
+		if !ok {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+		service := methodService(method)
+		f.mu.Lock()
+		downgraded := f.downgraded[string(service)]
+		f.mu.Unlock()
+		if !downgraded {
+			err := invoker(ctx, method, req, reply, cc, opts...)
+			if status.Code(err) != codes.Unimplemented {
+				return err
+			}
+			if !f.isCompatible(method, betaMethod) {
+				return err
+			}
+			f.downgrade(ctx, service)
+		} else if !f.isCompatible(method, betaMethod) {
+			return status.Errorf(codes.Unimplemented, "%s is not served and not compatible with %s", method, betaMethod)
+		}
+		return invokeV2Beta(ctx, betaMethod, req, reply, cc, invoker, opts...)
+	}
+}
+
+func (f *v2BetaFallback) downgrade(ctx context.Context, service Service) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.downgraded[string(service)] {
+		return
+	}
+	f.downgraded[string(service)] = true
+	f.logger.WarnContext(ctx, "ZITADEL does not serve the v2 API, falling back to v2beta", "service", service)
+}
+
+func (f *v2BetaFallback) isCompatible(method, betaMethod string) bool {
+	if compatible, ok := f.compatible.Load(method); ok {
+		return compatible.(bool)
+	}
+	compatible := methodsCompatible(method, betaMethod)
+	f.compatible.Store(method, compatible)
+	return compatible
+}
+
+// invokeV2Beta converts the v2 request into the v2beta request, calls the v2beta method
+// and converts its response into the v2 reply.
+func invokeV2Beta(ctx context.Context, betaMethod string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	input, output, ok := methodMessages(betaMethod)
+	if !ok {
+		return status.Errorf(codes.Unimplemented, "unknown method %s", betaMethod)
+	}
+	betaReq := input.New().Interface()
+	if err := convertMessage(req.(proto.Message), betaReq); err != nil {
+		return err
+	}
+	betaReply := output.New().Interface()
+	if err := invoker(ctx, betaMethod, betaReq, betaReply, cc, opts...); err != nil {
+		return err
+	}
+	return convertMessage(betaReply, reply.(proto.Message))
+}
+
+// convertMessage converts compatible messages using their wire format.
+func convertMessage(from, to proto.Message) error {
+	data, err := proto.Marshal(from)
+	if err != nil {
+		return err
+	}
+	return proto.Unmarshal(data, to)
+}
+
+// v2BetaMethod returns the v2beta method of a v2 method,
+// e.g. `/zitadel.user.v2beta.UserService/GetUserByID` for `/zitadel.user.v2.UserService/GetUserByID`.
+func v2BetaMethod(method string) (string, bool) {
+	service, name, ok := strings.Cut(strings.TrimPrefix(method, "/"), "/")
+	if !ok {
+		return "", false
+	}
+	parts := strings.Split(service, ".")
+	if len(parts) < 3 || parts[0] != "zitadel" || parts[len(parts)-2] != "v2" {
+		return "", false
+	}
+	parts[len(parts)-2] = "v2beta"
+	return "/" + strings.Join(parts, ".") + "/" + name, true
+}
+
+func methodMessages(method string) (input, output protoreflect.MessageType, ok bool) {
+	service, name, _ := strings.Cut(strings.TrimPrefix(method, "/"), "/")
+	desc, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(service))
+	if err != nil {
+		return nil, nil, false
+	}
+	serviceDesc, ok := desc.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, nil, false
+	}
+	methodDesc := serviceDesc.Methods().ByName(protoreflect.Name(name))
+	if methodDesc == nil {
+		return nil, nil, false
+	}
+	input, err = protoregistry.GlobalTypes.FindMessageByName(methodDesc.Input().FullName())
+	if err != nil {
+		return nil, nil, false
+	}
+	output, err = protoregistry.GlobalTypes.FindMessageByName(methodDesc.Output().FullName())
+	if err != nil {
+		return nil, nil, false
+	}
+	return input, output, true
+}
+
+// methodsCompatible returns if the requests of the v2 method can be converted to the ones of the v2beta method
+// and the responses of the v2beta method to the ones of the v2 method.
+func methodsCompatible(method, betaMethod string) bool {
+	input, output, ok := methodMessages(method)
+	if !ok {
+		return false
+	}
+	betaInput, betaOutput, ok := methodMessages(betaMethod)
+	if !ok {
+		return false
+	}
+	return messageCompatible(input.Descriptor(), betaInput.Descriptor(), make(map[[2]protoreflect.FullName]bool)) &&
+		messageCompatible(betaOutput.Descriptor(), output.Descriptor(), make(map[[2]protoreflect.FullName]bool))
+}
+
+// messageCompatible returns if all fields of the message from exist with the same number, kind and cardinality
+// in the message to, recursively for nested messages.
+func messageCompatible(from, to protoreflect.MessageDescriptor, seen map[[2]protoreflect.FullName]bool) bool {
+	pair := [2]protoreflect.FullName{from.FullName(), to.FullName()}
+	if seen[pair] {
+		return true
+	}
+	seen[pair] = true
+	fields := from.Fields()
+	for i := range fields.Len() {
+		field := fields.Get(i)
+		target := to.Fields().ByNumber(field.Number())
+		if target == nil || target.Kind() != field.Kind() || target.Cardinality() != field.Cardinality() || target.IsMap() != field.IsMap() {
+			return false
+		}
+		if field.IsMap() {
+			if field.MapValue().Kind() != target.MapValue().Kind() {
+				return false
+			}
+			if field.MapValue().Message() != nil && !messageCompatible(field.MapValue().Message(), target.MapValue().Message(), seen) {
+				return false
+			}
+			continue
+		}
+		if field.Message() != nil && !messageCompatible(field.Message(), target.Message(), seen) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,96 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	userV2 "github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/user/v2"
+	userV2Beta "github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/user/v2beta"
+)
+
+func TestV2BetaMethod(t *testing.T) {
+	tests := []struct {
+		method string
+		want   string
+		wantOK bool
+	}{
+		{"/zitadel.user.v2.UserService/GetUserByID", "/zitadel.user.v2beta.UserService/GetUserByID", true},
+		{"/zitadel.user.v2beta.UserService/GetUserByID", "", false},
+		{"/zitadel.management.v1.ManagementService/ListProjects", "", false},
+		{"invalid", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			got, ok := v2BetaMethod(tt.method)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestV2BetaFallback(t *testing.T) {
+	const method = "/zitadel.user.v2.UserService/GetUserByID"
+	tests := []struct {
+		name         string
+		served       bool
+		wantMethods  []string
+		wantUsername string
+	}{
+		{"v2 served", true, []string{method, method}, "v2"},
+		{"v2 not served", false, []string{method, "/zitadel.user.v2beta.UserService/GetUserByID", "/zitadel.user.v2beta.UserService/GetUserByID"}, "v2beta"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logs bytes.Buffer
+			opts := new(clientOptions)
+			WithV2BetaFallback(slog.New(slog.NewTextHandler(&logs, nil)))(opts)
+			interceptor := opts.unaryInterceptors[0]
+			var methods []string
+			invoker := func(_ context.Context, method string, req, reply any, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+				methods = append(methods, method)
+				switch r := req.(type) {
+				case *userV2.GetUserByIDRequest:
+					if !tt.served {
+						return status.Error(codes.Unimplemented, "unknown service")
+					}
+					proto.Merge(reply.(proto.Message), &userV2.GetUserByIDResponse{User: &userV2.User{UserId: r.GetUserId(), Username: "v2"}})
+				case *userV2Beta.GetUserByIDRequest:
+					proto.Merge(reply.(proto.Message), &userV2Beta.GetUserByIDResponse{User: &userV2Beta.User{UserId: r.GetUserId(), Username: "v2beta"}})
+				}
+				return nil
+			}
+			for range 2 {
+				reply := new(userV2.GetUserByIDResponse)
+				err := interceptor(context.Background(), method, &userV2.GetUserByIDRequest{UserId: "123"}, reply, nil, invoker)
+				require.NoError(t, err)
+				assert.Equal(t, "123", reply.GetUser().GetUserId())
+				assert.Equal(t, tt.wantUsername, reply.GetUser().GetUsername())
+			}
+			assert.Equal(t, tt.wantMethods, methods)
+			if tt.served {
+				assert.Empty(t, logs.String())
+			} else {
+				assert.Equal(t, 1, bytes.Count(logs.Bytes(), []byte("falling back to v2beta")))
+			}
+		})
+	}
+}
+
+func TestMessageCompatible(t *testing.T) {
+	assert.True(t, methodsCompatible("/zitadel.user.v2.UserService/GetUserByID", "/zitadel.user.v2beta.UserService/GetUserByID"))
+	assert.False(t, messageCompatible(
+		(&userV2.GetUserByIDResponse{}).ProtoReflect().Descriptor(),
+		(&userV2.GetUserByIDRequest{}).ProtoReflect().Descriptor(),
+		make(map[[2]protoreflect.FullName]bool),
+	))
+}
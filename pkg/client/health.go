@@ -0,0 +1,111 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc/connectivity"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/auth"
+)
+
+// healthChangesBuffer is the number of [HealthStatus] changes buffered for slow consumers,
+// further changes are dropped until there's space again.
+const healthChangesBuffer = 8
+
+// HealthStatus represents the result of a health check of the connection to ZITADEL.
+type HealthStatus struct {
+	// State is the state of the underlying connection at the time of the check.
+	State connectivity.State
+	// Err is the error of the health check, nil if ZITADEL is healthy.
+	Err error
+	// Time is the time of the check.
+	Time time.Time
+}
+
+// Healthy returns if ZITADEL was reachable and healthy.
+func (s HealthStatus) Healthy() bool {
+	return s.Err == nil
+}
+
+// WithHealthCheck starts a background routine checking the health of ZITADEL in the provided interval.
+// Changes of the health or connection state are published on [Client.HealthChanges].
+// If ZITADEL becomes unhealthy, the connection is instructed to reconnect immediately
+// instead of waiting for the current backoff.
+func WithHealthCheck(interval time.Duration) Option {
+	return func(c *clientOptions) {
+		c.healthCheckInterval = interval
+	}
+}
+
+// Healthz checks the availability of ZITADEL by calling the (public) healthz endpoint of the auth API.
+func (c *Client) Healthz(ctx context.Context) error {
+	_, err := c.AuthService().Healthz(ctx, &auth.HealthzRequest{})
+	return err
+}
+
+// HealthChanges returns a channel publishing changes of the [HealthStatus].
+// It will only publish if the health check is enabled by [WithHealthCheck]
+// and will be closed when the client is closed.
+// Clients returned by [Client.ForOrganization] have no health check and return nil.
+func (c *Client) HealthChanges() <-chan HealthStatus {
+	return c.healthChanges
+}
+
+func (c *Client) startHealthCheck(interval time.Duration) {
+	c.healthChanges = make(chan HealthStatus, healthChangesBuffer)
+	ctx, cancel := context.WithCancel(context.Background())
+	c.stopHealthCheck = cancel
+	go c.watchHealth(ctx, interval)
+}
+
+func (c *Client) watchHealth(ctx context.Context, interval time.Duration) {
+	defer close(c.healthChanges)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var last *HealthStatus
+	for {
+		status := c.checkHealth(ctx, interval)
+		if ctx.Err() != nil {
+			return
+		}
+		if last == nil || last.State != status.State || last.Healthy() != status.Healthy() {
+			last = &status
+			select {
+			case c.healthChanges <- status:
+			default:
+			}
+		}
+		if !status.Healthy() {
+			c.resetConnectBackoff()
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// resetConnectBackoff instructs the connection (or all connections of the pool) to reconnect immediately.
+func (c *Client) resetConnectBackoff() {
+	if pool, ok := c.invoker.(*connectionPool); ok {
+		pool.resetConnectBackoff()
+		return
+	}
+	if c.connection != nil {
+		c.connection.ResetConnectBackoff()
+	}
+}
+
+func (c *Client) checkHealth(ctx context.Context, timeout time.Duration) HealthStatus {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	err := c.Healthz(ctx)
+	return HealthStatus{
+		State: c.State(),
+		Err:   err,
+		Time:  time.Now(),
+	}
+}
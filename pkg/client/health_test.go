@@ -0,0 +1,77 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/status"
+)
+
+func TestWithHealthCheck(t *testing.T) {
+	const interval = 10 * time.Millisecond
+	server := newTestServer(t)
+	c, err := New(context.Background(), server.zitadel(t), WithHealthCheck(interval))
+	require.NoError(t, err)
+	changes := c.HealthChanges()
+	require.NotNil(t, changes)
+
+	next := func() HealthStatus {
+		t.Helper()
+		select {
+		case change, ok := <-changes:
+			require.True(t, ok, "changes must not be closed")
+			return change
+		case <-time.After(time.Second):
+			require.FailNow(t, "no health change published")
+			return HealthStatus{}
+		}
+	}
+	noChange := func() {
+		t.Helper()
+		select {
+		case change := <-changes:
+			assert.Failf(t, "unexpected health change", "%+v", change)
+		case <-time.After(5 * interval):
+		}
+	}
+
+	initial := next()
+	assert.True(t, initial.Healthy())
+	assert.Equal(t, connectivity.Ready, initial.State)
+	assert.False(t, initial.Time.IsZero())
+	noChange()
+
+	server.setServing(false)
+	unhealthy := next()
+	assert.False(t, unhealthy.Healthy())
+	assert.Equal(t, codes.Unavailable, status.Code(unhealthy.Err))
+	noChange()
+
+	server.setServing(true)
+	assert.True(t, next().Healthy())
+
+	require.NoError(t, c.Close())
+	select {
+	case _, ok := <-changes:
+		assert.False(t, ok, "changes are closed with the client")
+	case <-time.After(time.Second):
+		assert.Fail(t, "changes not closed")
+	}
+}
+
+func TestClient_HealthChanges_disabled(t *testing.T) {
+	server := newTestServer(t)
+	c, err := New(context.Background(), server.zitadel(t))
+	require.NoError(t, err)
+	defer c.Close()
+
+	assert.Nil(t, c.HealthChanges())
+	assert.NoError(t, c.Healthz(context.Background()))
+	server.setServing(false)
+	assert.Equal(t, codes.Unavailable, status.Code(c.Healthz(context.Background())))
+}
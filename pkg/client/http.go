@@ -0,0 +1,57 @@
+package client
+
+import (
+	"net/http"
+)
+
+// HTTPClient returns an [http.Client] for the HTTP/JSON APIs of ZITADEL, e.g. the resource API (see resources.NewFromClient).
+// The requests are authorized the same way as the calls of the service clients (see [WithAuth])
+// and use the TLS configuration, dialer and DPoP prover of the client.
+// The [OrgHeader] of a client returned by [Client.ForOrganization] is set as well.
+func (c *Client) HTTPClient() *http.Client {
+	return &http.Client{Transport: c.httpTransport}
+}
+
+// Origin returns the origin of the ZITADEL instance (e.g. https://my-instance.zitadel.cloud),
+// the paths of the HTTP/JSON APIs are relative to it.
+func (c *Client) Origin() string {
+	return c.origin
+}
+
+// authTransport sets the authorization of the client (and the organization) on the requests.
+type authTransport struct {
+	cred  *cred
+	orgID string
+	base  http.RoundTripper
+	// shared is set for the transports of clients returned by [Client.ForOrganization],
+	// which must not close the connections of the base transport owned by the parent client
+	shared bool
+}
+
+// RoundTrip implements [http.RoundTripper]
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	auth, err := t.cred.GetRequestMetadata(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	// the request must not be modified by a RoundTripper
+	req = req.Clone(req.Context())
+	for key, value := range auth {
+		req.Header.Set(key, value)
+	}
+	if t.orgID != "" {
+		req.Header.Set(OrgHeader, t.orgID)
+	}
+	return t.base.RoundTrip(req)
+}
+
+// CloseIdleConnections closes the idle connections of the underlying transport, see [Client.Close].
+// It has no effect on shared transports.
+func (t *authTransport) CloseIdleConnections() {
+	if t.shared {
+		return
+	}
+	if closer, ok := t.base.(interface{ CloseIdleConnections() }); ok {
+		closer.CloseIdleConnections()
+	}
+}
@@ -0,0 +1,48 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/zitadel"
+)
+
+func TestClient_HTTPClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		w.Write([]byte(r.Header.Get(OrgHeader)))
+	}))
+	defer server.Close()
+	host, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	require.NoError(t, err)
+	c, err := New(context.Background(), zitadel.New(host, zitadel.WithInsecure(port)), WithAuth(PAT("token")), WithTransport(TransportREST))
+	require.NoError(t, err)
+	defer c.Close()
+	assert.Equal(t, server.URL, c.Origin())
+
+	tests := []struct {
+		name      string
+		client    *Client
+		wantOrgID string
+	}{
+		{"instance", c, ""},
+		{"organization", c.ForOrganization("org1"), "org1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := tt.client.HTTPClient().Get(tt.client.Origin() + "/resources/v3alpha/actions/targets")
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			orgID, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantOrgID, string(orgID))
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/zitadel/oidc/v3/pkg/client"
+	"github.com/zitadel/oidc/v3/pkg/crypto"
+	"github.com/zitadel/oidc/v3/pkg/oidc"
+	"golang.org/x/oauth2"
+)
+
+// defaultAssertionExpiration is the lifetime of assertions created by [SignedAssertion].
+const defaultAssertionExpiration = time.Hour
+
+// Assertion provides the (signed) JWT sent as assertion of the JWT bearer grant (RFC 7523).
+// The audience is the list configured by [WithAssertionAudience] or the issuer of ZITADEL.
+// It's called for every token request, so assertions can be short-lived.
+type Assertion func(ctx context.Context, audience []string) (string, error)
+
+// AssertionOption allows customization of the assertions created by [SignedAssertion] and [KeyAssertion].
+type AssertionOption func(*assertionConfig)
+
+type assertionConfig struct {
+	issuer     string
+	audience   []string
+	expiration time.Duration
+}
+
+// WithAssertionIssuer sets the `iss` claim of the assertion.
+// By default, the subject is used as issuer (self-signed assertion of a user or service user key).
+func WithAssertionIssuer(issuer string) AssertionOption {
+	return func(c *assertionConfig) {
+		c.issuer = issuer
+	}
+}
+
+// WithAssertionAudience sets the `aud` claim of the assertion instead of the issuer of ZITADEL,
+// e.g. if the assertion is validated against a custom domain.
+func WithAssertionAudience(audience ...string) AssertionOption {
+	return func(c *assertionConfig) {
+		c.audience = audience
+	}
+}
+
+// WithAssertionExpiration sets the lifetime of the assertion (default 1h).
+func WithAssertionExpiration(expiration time.Duration) AssertionOption {
+	return func(c *assertionConfig) {
+		c.expiration = expiration
+	}
+}
+
+// SignedAssertion creates assertions for the subject (user ID) signed by the provided signer,
+// whose public key needs to be trusted by ZITADEL for the subject.
+func SignedAssertion(subject string, signer jose.Signer, options ...AssertionOption) Assertion {
+	config := &assertionConfig{
+		issuer:     subject,
+		expiration: defaultAssertionExpiration,
+	}
+	for _, option := range options {
+		option(config)
+	}
+	return func(_ context.Context, audience []string) (string, error) {
+		if len(config.audience) > 0 {
+			audience = config.audience
+		}
+		now := time.Now()
+		return crypto.Sign(&oidc.JWTTokenRequest{
+			Issuer:    config.issuer,
+			Subject:   subject,
+			Audience:  audience,
+			IssuedAt:  oidc.FromTime(now),
+			ExpiresAt: oidc.FromTime(now.Add(config.expiration)),
+		}, signer)
+	}
+}
+
+// KeyAssertion is a short version of [SignedAssertion] using a PEM encoded private key and its ID.
+func KeyAssertion(subject, keyID string, key []byte, options ...AssertionOption) Assertion {
+	signer, err := client.NewSignerFromPrivateKeyByte(key, keyID)
+	if err != nil {
+		return func(context.Context, []string) (string, error) {
+			return "", err
+		}
+	}
+	return SignedAssertion(subject, signer, options...)
+}
+
+// KeyFileAssertion is a short version of [KeyAssertion] using a key.json of a user provided by ZITADEL.
+func KeyFileAssertion(file *client.KeyFile, options ...AssertionOption) Assertion {
+	return KeyAssertion(file.UserID, file.KeyID, []byte(file.Key), options...)
+}
+
+// StaticAssertion uses an assertion issued by a third party, e.g. a trusted identity provider.
+// Since it can't be renewed, the token source will fail once the assertion has expired.
+func StaticAssertion(assertion string) Assertion {
+	return func(context.Context, []string) (string, error) {
+		return assertion, nil
+	}
+}
+
+// JWTBearerAuthentication allows using the OAuth2 JWT Bearer Grant (urn:ietf:params:oauth:grant-type:jwt-bearer)
+// to get a token for the subject of the assertion.
+// This allows trusted backends to act on behalf of a user (delegation).
+func JWTBearerAuthentication(assertion Assertion, scopes ...string) TokenSourceInitializer {
+	return func(ctx context.Context, issuer string) (oauth2.TokenSource, error) {
+		return NewJWTBearerTokenSource(ctx, issuer, assertion, scopes...)
+	}
+}
+
+// NewJWTBearerTokenSource returns an [oauth2.TokenSource] using the JWT Bearer Grant.
+// It can be used directly to obtain user tokens for a subsequent call (see [BearerTokenCtx]).
+// The passed context is used for the discovery and the [http.Client] set as [oauth2.HTTPClient] (if any).
+func NewJWTBearerTokenSource(ctx context.Context, issuer string, assertion Assertion, scopes ...string) (oauth2.TokenSource, error) {
+	httpClient := httpClient(ctx)
+	discovery, err := client.Discover(ctx, issuer, httpClient)
+	if err != nil {
+		return nil, err
+	}
+	return oauth2.ReuseTokenSource(nil, &jwtBearerTokenSource{
+		issuer:        issuer,
+		assertion:     assertion,
+		scopes:        scopes,
+		httpClient:    httpClient,
+		tokenEndpoint: discovery.TokenEndpoint,
+	}), nil
+}
+
+type jwtBearerTokenSource struct {
+	issuer        string
+	assertion     Assertion
+	scopes        []string
+	httpClient    *http.Client
+	tokenEndpoint string
+}
+
+// Token implements [oauth2.TokenSource]
+func (j *jwtBearerTokenSource) Token() (*oauth2.Token, error) {
+	ctx := context.Background()
+	assertion, err := j.assertion(ctx, []string{j.issuer})
+	if err != nil {
+		return nil, err
+	}
+	return client.JWTProfileExchange(ctx, oidc.NewJWTProfileGrantRequest(assertion, j.scopes...), j)
+}
+
+// TokenEndpoint implements [client.TokenEndpointCaller]
+func (j *jwtBearerTokenSource) TokenEndpoint() string {
+	return j.tokenEndpoint
+}
+
+// HttpClient implements [client.TokenEndpointCaller]
+func (j *jwtBearerTokenSource) HttpClient() *http.Client {
+	return j.httpClient
+}
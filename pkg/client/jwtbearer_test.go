@@ -0,0 +1,144 @@
+package client
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zitadel/oidc/v3/pkg/oidc"
+	"golang.org/x/oauth2"
+)
+
+// newJWTBearerServer serves the discovery and a token endpoint of the JWT bearer grant,
+// rejecting the assertion `rejected`. It returns the forms of the token requests.
+func newJWTBearerServer(t *testing.T) (*httptest.Server, func() []url.Values) {
+	var (
+		mu    sync.Mutex
+		forms []url.Values
+	)
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{"issuer": server.URL, "token_endpoint": server.URL + "/oauth/v2/token"})
+	})
+	mux.HandleFunc("/oauth/v2/token", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		mu.Lock()
+		forms = append(forms, r.PostForm)
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		if r.PostForm.Get("assertion") == "rejected" {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant", "error_description": "assertion invalid"})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"access_token": "token", "token_type": "Bearer", "expires_in": 3600})
+	})
+	return server, func() []url.Values {
+		mu.Lock()
+		defer mu.Unlock()
+		return forms
+	}
+}
+
+func TestJWTBearerAuthentication(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	server, forms := newJWTBearerServer(t)
+
+	tests := []struct {
+		name      string
+		assertion Assertion
+		scopes    []string
+		// wantClaims are the claims of the signed assertion, nil if it's not signed by the key
+		wantClaims     *oidc.JWTTokenRequest
+		wantExpiration time.Duration
+		wantAssertion  string
+		wantErr        string
+	}{
+		{
+			name:           "key assertion",
+			assertion:      KeyAssertion("user1", "key1", keyPEM),
+			scopes:         []string{oidc.ScopeOpenID},
+			wantClaims:     &oidc.JWTTokenRequest{Issuer: "user1", Subject: "user1", Audience: oidc.Audience{server.URL}},
+			wantExpiration: defaultAssertionExpiration,
+		},
+		{
+			name: "key assertion with options",
+			assertion: KeyAssertion("user1", "key1", keyPEM,
+				WithAssertionIssuer("backend"),
+				WithAssertionAudience("https://custom.example.com"),
+				WithAssertionExpiration(5*time.Minute),
+			),
+			wantClaims:     &oidc.JWTTokenRequest{Issuer: "backend", Subject: "user1", Audience: oidc.Audience{"https://custom.example.com"}},
+			wantExpiration: 5 * time.Minute,
+		},
+		{
+			name:          "static assertion",
+			assertion:     StaticAssertion("third-party"),
+			wantAssertion: "third-party",
+		},
+		{
+			name:      "invalid key",
+			assertion: KeyAssertion("user1", "key1", []byte("invalid")),
+			wantErr:   "PEM decode failed",
+		},
+		{
+			name:      "rejected assertion",
+			assertion: StaticAssertion("rejected"),
+			wantErr:   "invalid_grant",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := len(forms())
+			ctx := context.WithValue(context.Background(), oauth2.HTTPClient, server.Client())
+			tokenSource, err := JWTBearerAuthentication(tt.assertion, tt.scopes...)(ctx, server.URL)
+			require.NoError(t, err)
+
+			token, err := tokenSource.Token()
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "token", token.AccessToken)
+
+			require.Len(t, forms(), requests+1)
+			form := forms()[requests]
+			assert.Equal(t, oidc.GrantTypeBearer, oidc.GrantType(form.Get("grant_type")))
+			if len(tt.scopes) > 0 {
+				assert.Equal(t, oidc.SpaceDelimitedArray(tt.scopes).String(), form.Get("scope"))
+			}
+			if tt.wantClaims == nil {
+				assert.Equal(t, tt.wantAssertion, form.Get("assertion"))
+				return
+			}
+			signed, err := jose.ParseSigned(form.Get("assertion"), []jose.SignatureAlgorithm{jose.RS256})
+			require.NoError(t, err)
+			assert.Equal(t, "key1", signed.Signatures[0].Header.KeyID)
+			payload, err := signed.Verify(&key.PublicKey)
+			require.NoError(t, err)
+			var claims oidc.JWTTokenRequest
+			require.NoError(t, json.Unmarshal(payload, &claims))
+			assert.Equal(t, tt.wantClaims.Issuer, claims.Issuer)
+			assert.Equal(t, tt.wantClaims.Subject, claims.Subject)
+			assert.Equal(t, tt.wantClaims.Audience, claims.Audience)
+			assert.WithinDuration(t, time.Now().Add(tt.wantExpiration), claims.ExpiresAt.AsTime(), 5*time.Second)
+		})
+	}
+}
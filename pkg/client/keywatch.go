@@ -0,0 +1,113 @@
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/zitadel/oidc/v3/pkg/client"
+	"golang.org/x/oauth2"
+)
+
+// keyFileWatchInterval is the interval in which a watched key file is checked for changes.
+const keyFileWatchInterval = 10 * time.Second
+
+// WithKeyFileWatch authenticates using the JWT Profile Grant (see [JWTAuthentication]) with the key.json
+// of a service user read from the path and reloads the key when the content of the file changes,
+// e.g. a rotated secret mounted by Kubernetes, without the need to restart the process.
+// The file is checked every 10 seconds until the client is closed.
+// If the changed file cannot be read or parsed, the previous key is kept and the file checked again.
+func WithKeyFileWatch(path string, scopes ...string) Option {
+	return func(c *clientOptions) {
+		w := &keyFileWatcher{
+			path:     path,
+			interval: keyFileWatchInterval,
+			newSource: func(ctx context.Context, issuer string, file *client.KeyFile) (oauth2.TokenSource, error) {
+				return JWTAuthentication(file, scopes...)(ctx, issuer)
+			},
+		}
+		c.initTokenSource = w.init
+		c.keyFileWatcher = w
+	}
+}
+
+type keyFileWatcher struct {
+	path      string
+	interval  time.Duration
+	newSource func(ctx context.Context, issuer string, file *client.KeyFile) (oauth2.TokenSource, error)
+	stop      context.CancelFunc
+
+	mu     sync.RWMutex
+	source oauth2.TokenSource
+	sum    [sha256.Size]byte
+}
+
+// init implements [TokenSourceInitializer] by loading the key file and starting the watch.
+func (w *keyFileWatcher) init(ctx context.Context, issuer string) (oauth2.TokenSource, error) {
+	// the context is kept for the reloads, which happen after the client was created
+	ctx = context.WithoutCancel(ctx)
+	if _, err := w.reload(ctx, issuer); err != nil {
+		return nil, err
+	}
+	ctx, w.stop = context.WithCancel(ctx)
+	go w.watch(ctx, issuer)
+	return w, nil
+}
+
+// Token implements [oauth2.TokenSource] using the source of the current key.
+func (w *keyFileWatcher) Token() (*oauth2.Token, error) {
+	w.mu.RLock()
+	source := w.source
+	w.mu.RUnlock()
+	return source.Token()
+}
+
+func (w *keyFileWatcher) watch(ctx context.Context, issuer string) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// errors are ignored, the previous key is kept until the file is valid again
+			_, _ = w.reload(ctx, issuer)
+		}
+	}
+}
+
+// reload replaces the token source if the content of the key file changed.
+func (w *keyFileWatcher) reload(ctx context.Context, issuer string) (bool, error) {
+	data, err := os.ReadFile(w.path)
+	if err != nil {
+		return false, err
+	}
+	sum := sha256.Sum256(data)
+	w.mu.RLock()
+	unchanged := w.source != nil && sum == w.sum
+	w.mu.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+	file, err := client.ConfigFromKeyFileData(data)
+	if err != nil {
+		return false, err
+	}
+	source, err := w.newSource(ctx, issuer, file)
+	if err != nil {
+		return false, err
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.source = source
+	w.sum = sum
+	return true, nil
+}
+
+func (w *keyFileWatcher) close() {
+	if w.stop != nil {
+		w.stop()
+	}
+}
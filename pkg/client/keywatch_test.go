@@ -0,0 +1,76 @@
+package client
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zitadel/oidc/v3/pkg/client"
+	"golang.org/x/oauth2"
+)
+
+func TestKeyFileWatcher_reload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "key.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"type":"serviceaccount","keyId":"key1","userId":"user"}`), 0o600))
+	w := &keyFileWatcher{
+		path: path,
+		newSource: func(_ context.Context, _ string, file *client.KeyFile) (oauth2.TokenSource, error) {
+			return oauth2.StaticTokenSource(&oauth2.Token{AccessToken: file.KeyID}), nil
+		},
+	}
+	ctx := context.Background()
+	assertToken := func(want string) {
+		t.Helper()
+		token, err := w.Token()
+		require.NoError(t, err)
+		assert.Equal(t, want, token.AccessToken)
+	}
+
+	reloaded, err := w.reload(ctx, "issuer")
+	require.NoError(t, err)
+	assert.True(t, reloaded)
+	assertToken("key1")
+
+	reloaded, err = w.reload(ctx, "issuer")
+	require.NoError(t, err)
+	assert.False(t, reloaded, "unchanged file")
+
+	require.NoError(t, os.WriteFile(path, []byte(`invalid`), 0o600))
+	_, err = w.reload(ctx, "issuer")
+	assert.Error(t, err)
+	assertToken("key1")
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"type":"serviceaccount","keyId":"key2","userId":"user"}`), 0o600))
+	reloaded, err = w.reload(ctx, "issuer")
+	require.NoError(t, err)
+	assert.True(t, reloaded)
+	assertToken("key2")
+}
+
+func TestNew_keyFileWatchReleased(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "key.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"type":"serviceaccount","keyId":"key1","userId":"user"}`), 0o600))
+	var w *keyFileWatcher
+	watch := func(c *clientOptions) {
+		WithKeyFileWatch(path)(c)
+		w = c.keyFileWatcher
+		w.interval = 10 * time.Millisecond
+		w.newSource = func(_ context.Context, _ string, file *client.KeyFile) (oauth2.TokenSource, error) {
+			return oauth2.StaticTokenSource(&oauth2.Token{AccessToken: file.KeyID}), nil
+		}
+	}
+
+	// the connection of the service endpoint fails after the watch was started
+	_, err := New(context.Background(), newTestServer(t).zitadel(t), watch, WithServiceEndpoint(ServiceSystem, "passthrough:///"))
+	require.Error(t, err)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"type":"serviceaccount","keyId":"key2","userId":"user"}`), 0o600))
+	time.Sleep(10 * w.interval)
+	token, err := w.Token()
+	require.NoError(t, err)
+	assert.Equal(t, "key1", token.AccessToken, "the file is no longer watched")
+}
@@ -0,0 +1,60 @@
+package client
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// ForOrganization returns a client executing all calls in the context of the provided organization
+// by setting the [OrgHeader] (overriding one already present in the context).
+// The returned client shares the connection(s) with c, so it's cheap to create one per request or tenant.
+// Closing it has no effect, the connection(s) are closed by closing c.
+// It has no health check of its own, use [Client.HealthChanges] of c.
+func (c *Client) ForOrganization(orgID string) *Client {
+	derived := &Client{
+		connection:         c.connection,
+		invoker:            &orgConnection{ClientConnInterface: c.invoker, orgID: orgID},
+		serviceConnections: make(map[Service]grpc.ClientConnInterface, len(c.serviceConnections)),
+		origin:             c.origin,
+	}
+	if c.httpTransport != nil {
+		derived.httpTransport = &authTransport{
+			cred:   c.httpTransport.cred,
+			orgID:  orgID,
+			base:   c.httpTransport.base,
+			shared: true,
+		}
+	}
+	for service, conn := range c.serviceConnections {
+		derived.serviceConnections[service] = &orgConnection{ClientConnInterface: conn, orgID: orgID}
+	}
+	return derived
+}
+
+// orgConnection sets the organization context for every call on the underlying connection.
+// It deliberately does not implement Close, so a derived client will not close the shared connection.
+type orgConnection struct {
+	grpc.ClientConnInterface
+	orgID string
+}
+
+// Invoke implements [grpc.ClientConnInterface]
+func (o *orgConnection) Invoke(ctx context.Context, method string, args, reply any, opts ...grpc.CallOption) error {
+	return o.ClientConnInterface.Invoke(withOrgID(ctx, o.orgID), method, args, reply, opts...)
+}
+
+// NewStream implements [grpc.ClientConnInterface]
+func (o *orgConnection) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return o.ClientConnInterface.NewStream(withOrgID(ctx, o.orgID), desc, method, opts...)
+}
+
+func withOrgID(ctx context.Context, orgID string) context.Context {
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if !ok {
+		return metadata.AppendToOutgoingContext(ctx, OrgHeader, orgID)
+	}
+	md.Set(OrgHeader, orgID)
+	return metadata.NewOutgoingContext(ctx, md)
+}
@@ -0,0 +1,84 @@
+package client
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/zitadel"
+)
+
+func TestClient_ForOrganization(t *testing.T) {
+	server := newTestServer(t)
+	c, err := New(context.Background(), server.zitadel(t), WithAuth(PAT("token")), WithHealthCheck(time.Hour))
+	require.NoError(t, err)
+	defer c.Close()
+	org1 := c.ForOrganization("org1")
+	org2 := c.ForOrganization("org2")
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), OrgHeader, "overwritten")
+	require.NoError(t, org1.Healthz(ctx))
+	require.NoError(t, org2.Healthz(context.Background()))
+	require.NoError(t, c.Healthz(context.Background()))
+
+	calls := server.metadata()
+	require.Len(t, calls, 4, "including the call of the health check")
+	var orgIDs [][]string
+	for _, md := range calls[1:] {
+		assert.Equal(t, []string{"Bearer token"}, md.Get("authorization"))
+		orgIDs = append(orgIDs, md.Get(OrgHeader))
+	}
+	assert.Equal(t, [][]string{{"org1"}, {"org2"}, nil}, orgIDs, "the parent client is not affected")
+
+	assert.NotNil(t, c.HealthChanges())
+	assert.Nil(t, org1.HealthChanges(), "no health changes are taken from the parent")
+}
+
+func TestClient_ForOrganization_Close(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		remotes []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the default gRPC connection of the client connects to the server as well
+		if r.URL.Path != "/resources/v3alpha/actions/targets" {
+			return
+		}
+		mu.Lock()
+		remotes = append(remotes, r.RemoteAddr)
+		mu.Unlock()
+	}))
+	defer server.Close()
+	host, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	require.NoError(t, err)
+	grpcServer := newTestServer(t)
+	c, err := New(context.Background(), zitadel.New(host, zitadel.WithInsecure(port)), WithAuth(PAT("token")),
+		WithServiceEndpoint(ServiceAuth, grpcServer.addr()),
+	)
+	require.NoError(t, err)
+	defer c.Close()
+	get := func() {
+		resp, err := c.HTTPClient().Get(c.Origin() + "/resources/v3alpha/actions/targets")
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	get()
+	org := c.ForOrganization("org1")
+	require.NoError(t, org.Close())
+	get()
+	require.NoError(t, c.Healthz(context.Background()), "the connection of the parent is still open")
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, remotes, 2)
+	assert.Equal(t, remotes[0], remotes[1], "the idle HTTP connection of the parent is reused")
+}
@@ -0,0 +1,92 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+
+	"golang.org/x/oauth2"
+	"google.golang.org/grpc"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/zitadel"
+)
+
+// WithConnectionPool opens size gRPC connections (per endpoint) and distributes the calls round-robin over them.
+// A single HTTP/2 connection limits the number of concurrent streams and is served by a single server instance,
+// so a pool improves the throughput of high-throughput workloads, such as bulk operations.
+// [Client.Conn], [Client.State] and [Client.WaitForReady] refer to the first connection of the pool.
+// The option has no effect with [TransportREST], which already uses a pool of HTTP connections.
+func WithConnectionPool(size int) Option {
+	return func(c *clientOptions) {
+		c.poolSize = size
+	}
+}
+
+type connectFunc func(
+	ctx context.Context,
+	zitadel *zitadel.Zitadel,
+	target string,
+	tokenSource oauth2.TokenSource,
+	options *clientOptions,
+) (grpc.ClientConnInterface, error)
+
+// pooled returns a connectFunc creating a [connectionPool] of size connections using connect.
+func pooled(connect connectFunc, size int) connectFunc {
+	return func(ctx context.Context, zitadel *zitadel.Zitadel, target string, tokenSource oauth2.TokenSource, options *clientOptions) (grpc.ClientConnInterface, error) {
+		pool := &connectionPool{conns: make([]grpc.ClientConnInterface, 0, size)}
+		for range size {
+			conn, err := connect(ctx, zitadel, target, tokenSource, options)
+			if err != nil {
+				return nil, errors.Join(err, pool.Close())
+			}
+			pool.conns = append(pool.conns, conn)
+		}
+		return pool, nil
+	}
+}
+
+// connectionPool implements [grpc.ClientConnInterface] by distributing the calls round-robin over its connections.
+type connectionPool struct {
+	conns []grpc.ClientConnInterface
+	next  atomic.Uint64
+}
+
+func (p *connectionPool) pick() grpc.ClientConnInterface {
+	return p.conns[(p.next.Add(1)-1)%uint64(len(p.conns))]
+}
+
+// primary returns the first connection of the pool, used for the connection state.
+func (p *connectionPool) primary() *grpc.ClientConn {
+	conn, _ := p.conns[0].(*grpc.ClientConn)
+	return conn
+}
+
+// Invoke implements [grpc.ClientConnInterface]
+func (p *connectionPool) Invoke(ctx context.Context, method string, args, reply any, opts ...grpc.CallOption) error {
+	return p.pick().Invoke(ctx, method, args, reply, opts...)
+}
+
+// NewStream implements [grpc.ClientConnInterface]
+func (p *connectionPool) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return p.pick().NewStream(ctx, desc, method, opts...)
+}
+
+// Close closes all connections of the pool.
+func (p *connectionPool) Close() error {
+	var errs []error
+	for _, conn := range p.conns {
+		if closer, ok := conn.(interface{ Close() error }); ok {
+			errs = append(errs, closer.Close())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// resetConnectBackoff resets the backoff of all connections of the pool.
+func (p *connectionPool) resetConnectBackoff() {
+	for _, conn := range p.conns {
+		if conn, ok := conn.(*grpc.ClientConn); ok {
+			conn.ResetConnectBackoff()
+		}
+	}
+}
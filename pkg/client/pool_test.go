@@ -0,0 +1,68 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+	"google.golang.org/grpc"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/zitadel"
+)
+
+type countingConn struct {
+	grpc.ClientConnInterface
+	calls  int
+	closed bool
+}
+
+func (c *countingConn) Invoke(context.Context, string, any, any, ...grpc.CallOption) error {
+	c.calls++
+	return nil
+}
+
+func (c *countingConn) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestConnectionPool(t *testing.T) {
+	var conns []*countingConn
+	connect := func(context.Context, *zitadel.Zitadel, string, oauth2.TokenSource, *clientOptions) (grpc.ClientConnInterface, error) {
+		if len(conns) == 3 {
+			return nil, errors.New("connection failed")
+		}
+		conn := &countingConn{}
+		conns = append(conns, conn)
+		return conn, nil
+	}
+
+	t.Run("round-robin", func(t *testing.T) {
+		conn, err := pooled(connect, 3)(context.Background(), nil, "", nil, nil)
+		require.NoError(t, err)
+		for range 7 {
+			require.NoError(t, conn.Invoke(context.Background(), "/method", nil, nil))
+		}
+		assert.Equal(t, 3, conns[0].calls)
+		assert.Equal(t, 2, conns[1].calls)
+		assert.Equal(t, 2, conns[2].calls)
+
+		require.NoError(t, conn.(*connectionPool).Close())
+		for _, c := range conns {
+			assert.True(t, c.closed)
+		}
+	})
+	t.Run("connection failed, opened connections closed", func(t *testing.T) {
+		conns = conns[:1]
+		conns[0].closed = false
+		_, err := pooled(connect, 3)(context.Background(), nil, "", nil, nil)
+		require.Error(t, err)
+		assert.Len(t, conns, 3)
+		assert.True(t, conns[1].closed)
+		assert.True(t, conns[2].closed)
+		assert.False(t, conns[0].closed)
+	})
+}
@@ -0,0 +1,109 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// WithRateLimit installs an interceptor limiting the calls of the client to rps calls per second on average,
+// allowing bursts of up to burst calls (token bucket), so batch jobs (e.g. imports or syncs)
+// throttle themselves below the rate limits of ZITADEL instead of being rate limited.
+// Calls exceeding the limit wait for their turn, or fail with the error of the context if it's done before.
+// Streams are limited when they are established.
+//
+// Installed before [WithRetry], the retries are limited as well.
+func WithRateLimit(rps float64, burst int) Option {
+	return func(c *clientOptions) {
+		limiter := newTokenBucket(rps, burst)
+		c.unaryInterceptors = append(c.unaryInterceptors, limiter.unaryInterceptor())
+		c.streamInterceptors = append(c.streamInterceptors, limiter.streamInterceptor())
+	}
+}
+
+// tokenBucket is refilled with rate tokens per second up to burst tokens, every call takes one token.
+// A nil bucket or a non positive rate does not limit.
+type tokenBucket struct {
+	rate  float64
+	burst float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+}
+
+func newTokenBucket(rps float64, burst int) *tokenBucket {
+	if rps <= 0 {
+		return nil
+	}
+	return &tokenBucket{
+		rate:   rps,
+		burst:  float64(max(burst, 1)),
+		tokens: float64(max(burst, 1)),
+		now:    time.Now,
+	}
+}
+
+// reserve takes a token and returns the time to wait until it's available.
+// The tokens may become negative, so waiting calls are served in order.
+func (b *tokenBucket) reserve() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := b.now()
+	if !b.last.IsZero() {
+		b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	}
+	b.last = now
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// cancel returns a reserved token, which was not used.
+func (b *tokenBucket) cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens = min(b.burst, b.tokens+1)
+}
+
+// wait blocks until a token is available or the context is done.
+func (b *tokenBucket) wait(ctx context.Context) error {
+	if b == nil {
+		return nil
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	delay := b.reserve()
+	if delay <= 0 {
+		return nil
+	}
+	if err := wait(ctx, delay); err != nil {
+		b.cancel()
+		return err
+	}
+	return nil
+}
+
+func (b *tokenBucket) unaryInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if err := b.wait(ctx); err != nil {
+			return err
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+func (b *tokenBucket) streamInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		if err := b.wait(ctx); err != nil {
+			return nil, err
+		}
+		return streamer(ctx, desc, cc, method, opts...)
+	}
+}
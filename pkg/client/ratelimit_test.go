@@ -0,0 +1,47 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenBucket_reserve(t *testing.T) {
+	now := time.Unix(0, 0)
+	bucket := newTokenBucket(2, 3)
+	bucket.now = func() time.Time { return now }
+
+	// the burst is available immediately
+	for range 3 {
+		assert.Equal(t, time.Duration(0), bucket.reserve())
+	}
+	// further calls wait for the refill in order
+	assert.Equal(t, 500*time.Millisecond, bucket.reserve())
+	assert.Equal(t, time.Second, bucket.reserve())
+
+	// the bucket is refilled, but not beyond the burst
+	now = now.Add(time.Hour)
+	for range 3 {
+		assert.Equal(t, time.Duration(0), bucket.reserve())
+	}
+	assert.Equal(t, 500*time.Millisecond, bucket.reserve())
+}
+
+func TestTokenBucket_wait(t *testing.T) {
+	t.Run("unlimited", func(t *testing.T) {
+		assert.Nil(t, newTokenBucket(0, 10))
+		require.NoError(t, newTokenBucket(0, 10).wait(context.Background()))
+	})
+	t.Run("context done, token returned", func(t *testing.T) {
+		bucket := newTokenBucket(0.001, 1)
+		require.NoError(t, bucket.wait(context.Background()))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		require.ErrorIs(t, bucket.wait(ctx), context.DeadlineExceeded)
+		assert.InDelta(t, 0, bucket.tokens, 0.01)
+	})
+}
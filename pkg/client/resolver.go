@@ -0,0 +1,99 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+
+	"golang.org/x/oauth2"
+	"google.golang.org/grpc"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/dpop"
+)
+
+// DialFunc establishes a network connection to the address (host:port).
+type DialFunc func(ctx context.Context, network, address string) (net.Conn, error)
+
+// WithDialer allows to use a custom dialer for the gRPC connection as well as for the HTTP requests
+// of the authentication (discovery and token endpoint).
+func WithDialer(dial DialFunc) Option {
+	return func(c *clientOptions) {
+		c.dialer = dial
+	}
+}
+
+// WithResolver allows to resolve the ZITADEL domain using a custom [net.Resolver], e.g. one querying
+// an internal DNS server for split-horizon setups.
+// It applies to the gRPC connection as well as to the HTTP requests of the authentication.
+func WithResolver(resolver *net.Resolver) Option {
+	return WithDialer((&net.Dialer{Resolver: resolver}).DialContext)
+}
+
+// WithPinnedAddresses pins the host to the provided IP addresses, which will be tried in order.
+// This is useful in locked-down networks where the public domain of ZITADEL is not resolvable.
+// The domain is still used for TLS verification.
+// It applies to the gRPC connection as well as to the HTTP requests of the authentication.
+func WithPinnedAddresses(host string, addresses ...string) Option {
+	return func(c *clientOptions) {
+		if c.pinnedAddresses == nil {
+			c.pinnedAddresses = make(map[string][]string)
+		}
+		c.pinnedAddresses[host] = append(c.pinnedAddresses[host], addresses...)
+	}
+}
+
+func (o *clientOptions) hasCustomDialer() bool {
+	return o.dialer != nil || len(o.pinnedAddresses) > 0
+}
+
+// dial uses the custom dialer (if any) and resolves pinned hosts.
+func (o *clientOptions) dial(ctx context.Context, network, address string) (net.Conn, error) {
+	dial := o.dialer
+	if dial == nil {
+		dial = new(net.Dialer).DialContext
+	}
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return dial(ctx, network, address)
+	}
+	addresses, ok := o.pinnedAddresses[host]
+	if !ok {
+		return dial(ctx, network, address)
+	}
+	var errs []error
+	for _, ip := range addresses {
+		conn, err := dial(ctx, network, net.JoinHostPort(ip, port))
+		if err == nil {
+			return conn, nil
+		}
+		errs = append(errs, err)
+	}
+	return nil, errors.Join(errs...)
+}
+
+func (o *clientOptions) grpcDialer() grpc.DialOption {
+	return grpc.WithContextDialer(func(ctx context.Context, address string) (net.Conn, error) {
+		return o.dial(ctx, "tcp", address)
+	})
+}
+
+func (o *clientOptions) httpClient() *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if o.hasCustomDialer() {
+		transport.DialContext = o.dial
+	}
+	if o.dpopProver != nil {
+		return &http.Client{Transport: &dpop.Transport{Prover: o.dpopProver, Base: transport}}
+	}
+	return &http.Client{Transport: transport}
+}
+
+// httpClient returns the [http.Client] set as [oauth2.HTTPClient] in the context or the [http.DefaultClient].
+// It's used by the [TokenSourceInitializer] for its requests.
+func httpClient(ctx context.Context) *http.Client {
+	if c, ok := ctx.Value(oauth2.HTTPClient).(*http.Client); ok && c != nil {
+		return c
+	}
+	return http.DefaultClient
+}
@@ -0,0 +1,94 @@
+package client
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"math/big"
+	"net"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/zitadel"
+)
+
+// newTestCertificate creates a self-signed server certificate for the domain.
+func newTestCertificate(t *testing.T, domain string) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		DNSNames:     []string{domain},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: cert}
+}
+
+func TestWithPinnedAddresses(t *testing.T) {
+	// the domain is not resolvable, so the call only succeeds if the pinned address is dialled
+	const domain = "zitadel.invalid"
+	cert := newTestCertificate(t, domain)
+	var (
+		mu          sync.Mutex
+		serverNames []string
+	)
+	server := newTestServer(t, grpc.Creds(credentials.NewTLS(&tls.Config{
+		GetCertificate: func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+			mu.Lock()
+			serverNames = append(serverNames, hello.ServerName)
+			mu.Unlock()
+			return &cert, nil
+		},
+	})))
+	_, port, err := net.SplitHostPort(server.addr())
+	require.NoError(t, err)
+	portNumber, err := strconv.ParseUint(port, 10, 16)
+	require.NoError(t, err)
+	roots := x509.NewCertPool()
+	roots.AddCert(cert.Leaf)
+	trustServer := func(c *clientOptions) {
+		c.tlsConfigHooks = append(c.tlsConfigHooks, func(config *tls.Config) {
+			config.RootCAs = roots
+		})
+	}
+
+	var dialed []string
+	c, err := New(context.Background(), zitadel.New(domain, zitadel.WithPort(uint16(portNumber))), trustServer,
+		// the first address is not reachable, so the next one is tried
+		WithPinnedAddresses(domain, "invalid", "127.0.0.1"),
+		WithDialer(func(ctx context.Context, network, address string) (net.Conn, error) {
+			mu.Lock()
+			dialed = append(dialed, address)
+			mu.Unlock()
+			return new(net.Dialer).DialContext(ctx, network, address)
+		}),
+	)
+	require.NoError(t, err)
+	defer c.Close()
+	require.NoError(t, c.Healthz(context.Background()))
+
+	mu.Lock()
+	assert.Equal(t, []string{net.JoinHostPort("invalid", port), net.JoinHostPort("127.0.0.1", port)}, dialed)
+	assert.Equal(t, []string{domain}, serverNames, "the domain is used for SNI")
+	mu.Unlock()
+	calls := server.metadata()
+	require.Len(t, calls, 1)
+	assert.Equal(t, []string{domain}, calls[0].Get(":authority"))
+}
@@ -0,0 +1,516 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"golang.org/x/oauth2"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/dpop"
+	"github.com/zitadel/zitadel-go/v3/pkg/zitadel"
+)
+
+// Transport defines the protocol used by the [Client] to communicate with ZITADEL.
+type Transport int
+
+const (
+	// TransportGRPC uses gRPC (HTTP/2) and is the default.
+	TransportGRPC Transport = iota
+	// TransportREST uses the HTTP/JSON endpoints (gRPC-gateway) of ZITADEL,
+	// e.g. if gRPC is blocked by a corporate proxy.
+	// The service clients can be used the same way, but only unary calls are supported.
+	// Options relying on gRPC internals, such as [WithGRPCDialOptions], have no effect.
+	TransportREST
+)
+
+// WithTransport allows to use a [Transport] other than [TransportGRPC].
+func WithTransport(transport Transport) Option {
+	return func(c *clientOptions) {
+		c.transport = transport
+	}
+}
+
+var pathParam = regexp.MustCompile(`\{([^}=]+)(=[^}]*)?\}`)
+
+// restConnection implements [grpc.ClientConnInterface] by mapping the calls to the HTTP/JSON endpoints
+// using the `google.api.http` annotations of the services.
+type restConnection struct {
+	origin      string
+	httpClient  *http.Client
+	cred        *cred
+	interceptor grpc.UnaryClientInterceptor
+}
+
+func newRESTConnection(
+	_ context.Context,
+	zitadel *zitadel.Zitadel,
+	target string,
+	tokenSource oauth2.TokenSource,
+	options *clientOptions,
+) (grpc.ClientConnInterface, error) {
+	roundTripper, err := restTransport(zitadel, target, options)
+	if err != nil {
+		return nil, err
+	}
+	return &restConnection{
+		origin:      restOrigin(zitadel, target),
+		httpClient:  &http.Client{Transport: roundTripper},
+		cred:        &cred{tls: zitadel.IsTLS(), tokenSource: tokenSource},
+		interceptor: chainUnaryInterceptors(options.unaryInterceptors),
+	}, nil
+}
+
+// restTransport returns the [http.RoundTripper] for the HTTP/JSON endpoints of the target,
+// using the TLS configuration, dialer and DPoP prover of the options.
+func restTransport(zitadel *zitadel.Zitadel, target string, options *clientOptions) (http.RoundTripper, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if zitadel.IsTLS() {
+		serverName := zitadel.Domain()
+		if target != zitadel.Host() {
+			serverName = hostname(target)
+		}
+		tlsConfig, err := clientTLSConfig(serverName, zitadel.IsInsecureSkipVerifyTLS(), options.tlsConfigHooks...)
+		if err != nil {
+			return nil, err
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+	if options.hasCustomDialer() {
+		transport.DialContext = options.dial
+	}
+	var roundTripper http.RoundTripper = transport
+	if options.dpopProver != nil {
+		roundTripper = &dpop.Transport{Prover: options.dpopProver, Base: transport}
+	}
+	if options.telemetry != nil {
+		roundTripper = options.telemetry.transport(roundTripper)
+	}
+	return roundTripper, nil
+}
+
+// restOrigin returns the origin of the target, omitting the default port
+// since ZITADEL identifies the instance by the host header.
+func restOrigin(zitadel *zitadel.Zitadel, target string) string {
+	if target == zitadel.Host() {
+		return zitadel.Origin()
+	}
+	scheme := "http"
+	defaultPort := "80"
+	if zitadel.IsTLS() {
+		scheme = "https"
+		defaultPort = "443"
+	}
+	host, port, err := net.SplitHostPort(target)
+	if err != nil || port != defaultPort {
+		return scheme + "://" + target
+	}
+	return scheme + "://" + host
+}
+
+// Invoke implements [grpc.ClientConnInterface]
+func (r *restConnection) Invoke(ctx context.Context, method string, args, reply any, opts ...grpc.CallOption) error {
+	if r.interceptor == nil {
+		return r.invoke(ctx, method, args, reply, nil, opts...)
+	}
+	return r.interceptor(ctx, method, args, reply, nil, r.invoke, opts...)
+}
+
+// NewStream implements [grpc.ClientConnInterface]
+func (r *restConnection) NewStream(context.Context, *grpc.StreamDesc, string, ...grpc.CallOption) (grpc.ClientStream, error) {
+	return nil, status.Error(codes.Unimplemented, "streaming calls are not supported by the REST transport")
+}
+
+func (r *restConnection) Close() error {
+	r.httpClient.CloseIdleConnections()
+	return nil
+}
+
+func (r *restConnection) invoke(ctx context.Context, method string, args, reply any, _ *grpc.ClientConn, opts ...grpc.CallOption) error {
+	req, ok := args.(proto.Message)
+	if !ok {
+		return status.Errorf(codes.Internal, "request of %s is not a proto message", method)
+	}
+	resp, ok := reply.(proto.Message)
+	if !ok {
+		return status.Errorf(codes.Internal, "response of %s is not a proto message", method)
+	}
+	rule, prefix, err := restRoute(method)
+	if err != nil {
+		return err
+	}
+	httpReq, err := r.newRequest(ctx, rule, prefix, req)
+	if err != nil {
+		return err
+	}
+	httpResp, err := r.httpClient.Do(httpReq)
+	if err != nil {
+		return status.Error(codes.Unavailable, err.Error())
+	}
+	defer httpResp.Body.Close()
+	setCallHeader(httpResp.Header, opts)
+	body, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return status.Error(codes.Unavailable, err.Error())
+	}
+	if httpResp.StatusCode >= http.StatusMultipleChoices {
+		return restError(httpResp.StatusCode, body)
+	}
+	return unmarshalResponse(body, rule.GetResponseBody(), resp)
+}
+
+// restRoute returns the HTTP mapping of the gRPC method and the path prefix of its service.
+func restRoute(method string) (*annotations.HttpRule, string, error) {
+	name := strings.ReplaceAll(strings.TrimPrefix(method, "/"), "/", ".")
+	desc, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(name))
+	if err != nil {
+		return nil, "", status.Errorf(codes.Unimplemented, "unknown method %s", method)
+	}
+	methodDesc, ok := desc.(protoreflect.MethodDescriptor)
+	if !ok {
+		return nil, "", status.Errorf(codes.Unimplemented, "unknown method %s", method)
+	}
+	rule, ok := proto.GetExtension(methodDesc.Options(), annotations.E_Http).(*annotations.HttpRule)
+	if !ok || rule == nil {
+		return nil, "", status.Errorf(codes.Unimplemented, "method %s has no REST mapping", method)
+	}
+	return rule, pathPrefix(methodDesc.Parent().ParentFile().Package()), nil
+}
+
+// pathPrefix returns the prefix the v1 APIs are served on (e.g. zitadel.management.v1 -> /management/v1).
+// The paths of newer APIs already contain their version (e.g. /v2/users).
+func pathPrefix(pkg protoreflect.FullName) string {
+	parts := strings.Split(string(pkg), ".")
+	if len(parts) == 3 && parts[2] == "v1" {
+		return "/" + parts[1] + "/v1"
+	}
+	return ""
+}
+
+func (r *restConnection) newRequest(ctx context.Context, rule *annotations.HttpRule, prefix string, req proto.Message) (*http.Request, error) {
+	verb, pattern := ruleVerbAndPath(rule)
+	msg := proto.Clone(req).ProtoReflect()
+	path, pathFields, err := expandPath(pattern, msg)
+	if err != nil {
+		return nil, err
+	}
+	for _, field := range pathFields {
+		clearField(msg, field)
+	}
+
+	var body []byte
+	query := make(url.Values)
+	switch rule.GetBody() {
+	case "*":
+		body, err = protojson.Marshal(msg.Interface())
+	case "":
+		queryParams(msg, "", query)
+	default:
+		body, err = marshalField(msg, rule.GetBody())
+		clearField(msg, rule.GetBody())
+		queryParams(msg, "", query)
+	}
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	u := r.origin + prefix + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, verb, u, bytes.NewReader(body))
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	httpReq.Header.Set("Accept", "application/json")
+	if body != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+	if md, ok := metadata.FromOutgoingContext(ctx); ok {
+		for key, values := range md {
+			if strings.HasSuffix(key, "-bin") {
+				continue
+			}
+			for _, value := range values {
+				httpReq.Header.Add(key, value)
+			}
+		}
+	}
+	auth, err := r.cred.GetRequestMetadata(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	for key, value := range auth {
+		httpReq.Header.Set(key, value)
+	}
+	return httpReq, nil
+}
+
+func ruleVerbAndPath(rule *annotations.HttpRule) (string, string) {
+	switch pattern := rule.GetPattern().(type) {
+	case *annotations.HttpRule_Get:
+		return http.MethodGet, pattern.Get
+	case *annotations.HttpRule_Put:
+		return http.MethodPut, pattern.Put
+	case *annotations.HttpRule_Post:
+		return http.MethodPost, pattern.Post
+	case *annotations.HttpRule_Delete:
+		return http.MethodDelete, pattern.Delete
+	case *annotations.HttpRule_Patch:
+		return http.MethodPatch, pattern.Patch
+	case *annotations.HttpRule_Custom:
+		return pattern.Custom.GetKind(), pattern.Custom.GetPath()
+	}
+	return http.MethodPost, ""
+}
+
+// expandPath replaces the path parameters (e.g. `/v2/users/{user_id}`) with the values of the request
+// and returns the names of the used fields.
+func expandPath(pattern string, msg protoreflect.Message) (string, []string, error) {
+	var fields []string
+	var err error
+	path := pathParam.ReplaceAllStringFunc(pattern, func(param string) string {
+		match := pathParam.FindStringSubmatch(param)
+		fields = append(fields, match[1])
+		value, ok := fieldValue(msg, match[1])
+		if !ok {
+			err = status.Errorf(codes.InvalidArgument, "missing path parameter %s", match[1])
+			return ""
+		}
+		if match[2] == "" {
+			return url.PathEscape(value)
+		}
+		// multi segment parameters (e.g. `{name=orgs/*}`) must keep their slashes
+		segments := strings.Split(value, "/")
+		for i, segment := range segments {
+			segments[i] = url.PathEscape(segment)
+		}
+		return strings.Join(segments, "/")
+	})
+	return path, fields, err
+}
+
+// fieldValue returns the formatted scalar value of the (nested) field path, e.g. `details.id`.
+func fieldValue(msg protoreflect.Message, fieldPath string) (string, bool) {
+	names := strings.Split(fieldPath, ".")
+	for i, name := range names {
+		fd := msg.Descriptor().Fields().ByName(protoreflect.Name(name))
+		if fd == nil {
+			return "", false
+		}
+		if i < len(names)-1 {
+			if fd.Kind() != protoreflect.MessageKind {
+				return "", false
+			}
+			msg = msg.Get(fd).Message()
+			continue
+		}
+		return formatValue(fd, msg.Get(fd)), true
+	}
+	return "", false
+}
+
+func clearField(msg protoreflect.Message, fieldPath string) {
+	names := strings.Split(fieldPath, ".")
+	for i, name := range names {
+		fd := msg.Descriptor().Fields().ByName(protoreflect.Name(name))
+		if fd == nil {
+			return
+		}
+		if i == len(names)-1 {
+			msg.Clear(fd)
+			return
+		}
+		if fd.Kind() != protoreflect.MessageKind || !msg.Has(fd) {
+			return
+		}
+		msg = msg.Mutable(fd).Message()
+	}
+}
+
+func marshalField(msg protoreflect.Message, name string) ([]byte, error) {
+	fd := msg.Descriptor().Fields().ByName(protoreflect.Name(name))
+	if fd == nil {
+		return nil, fmt.Errorf("unknown body field %s", name)
+	}
+	if fd.Kind() == protoreflect.MessageKind && !fd.IsList() && !fd.IsMap() {
+		return protojson.Marshal(msg.Get(fd).Message().Interface())
+	}
+	// marshal non message fields by marshalling a message only containing the field
+	// and extracting its value
+	wrapper := msg.New()
+	wrapper.Set(fd, msg.Get(fd))
+	data, err := protojson.Marshal(wrapper.Interface())
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err = json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields[fd.JSONName()], nil
+}
+
+// queryParams adds all set fields of the message as query parameters (nested fields separated by dots).
+func queryParams(msg protoreflect.Message, prefix string, query url.Values) {
+	msg.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		key := prefix + string(fd.Name())
+		switch {
+		case fd.IsMap():
+			// maps are not supported as query parameters
+		case fd.IsList():
+			list := v.List()
+			for i := 0; i < list.Len(); i++ {
+				query.Add(key, formatValue(fd, list.Get(i)))
+			}
+		case fd.Kind() == protoreflect.MessageKind && !isWellKnownType(fd.Message()):
+			queryParams(v.Message(), key+".", query)
+		default:
+			query.Add(key, formatValue(fd, v))
+		}
+		return true
+	})
+}
+
+func isWellKnownType(desc protoreflect.MessageDescriptor) bool {
+	return strings.HasPrefix(string(desc.FullName()), "google.protobuf.")
+}
+
+func formatValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) string {
+	switch fd.Kind() {
+	case protoreflect.EnumKind:
+		if value := fd.Enum().Values().ByNumber(v.Enum()); value != nil {
+			return string(value.Name())
+		}
+		return fmt.Sprint(int32(v.Enum()))
+	case protoreflect.BytesKind:
+		return base64.StdEncoding.EncodeToString(v.Bytes())
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		// well known types (timestamps, durations, wrappers) are formatted as their JSON representation
+		data, err := protojson.Marshal(v.Message().Interface())
+		if err != nil {
+			return ""
+		}
+		return strings.Trim(string(data), `"`)
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
+
+func unmarshalResponse(body []byte, responseBody string, resp proto.Message) error {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil
+	}
+	if responseBody != "" {
+		if fd := resp.ProtoReflect().Descriptor().Fields().ByName(protoreflect.Name(responseBody)); fd != nil {
+			body = []byte(fmt.Sprintf(`{%q:%s}`, fd.JSONName(), body))
+		}
+	}
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(body, resp); err != nil {
+		return status.Errorf(codes.Internal, "unable to parse response: %v", err)
+	}
+	return nil
+}
+
+// setCallHeader provides the HTTP response header to [grpc.Header] call options (e.g. used by [WithRetry]).
+func setCallHeader(header http.Header, opts []grpc.CallOption) {
+	md := make(metadata.MD, len(header))
+	for key, values := range header {
+		md[strings.ToLower(key)] = values
+	}
+	for _, opt := range opts {
+		switch o := opt.(type) {
+		case grpc.HeaderCallOption:
+			*o.HeaderAddr = md
+		case grpc.TrailerCallOption:
+			*o.TrailerAddr = metadata.MD{}
+		}
+	}
+}
+
+// restError converts the error response into a gRPC status error,
+// so the errors can be handled the same way as with [TransportGRPC].
+func restError(statusCode int, body []byte) error {
+	s := new(spb.Status)
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(body, s); err == nil && s.GetCode() != 0 {
+		return status.ErrorProto(s)
+	}
+	// details might contain unknown types, so fallback to code and message
+	var plain struct {
+		Code    int32  `json:"code"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(body, &plain); err == nil && plain.Code != 0 {
+		return status.Error(codes.Code(plain.Code), plain.Message)
+	}
+	message := strings.TrimSpace(string(body))
+	if message == "" {
+		message = http.StatusText(statusCode)
+	}
+	return status.Error(httpStatusCode(statusCode), message)
+}
+
+func httpStatusCode(statusCode int) codes.Code {
+	switch statusCode {
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.AlreadyExists
+	case http.StatusPreconditionFailed:
+		return codes.FailedPrecondition
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusNotImplemented:
+		return codes.Unimplemented
+	case http.StatusServiceUnavailable, http.StatusBadGateway:
+		return codes.Unavailable
+	case http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	case http.StatusInternalServerError:
+		return codes.Internal
+	}
+	return codes.Unknown
+}
+
+// chainUnaryInterceptors chains the interceptors in the same order as [grpc.WithChainUnaryInterceptor].
+func chainUnaryInterceptors(interceptors []grpc.UnaryClientInterceptor) grpc.UnaryClientInterceptor {
+	if len(interceptors) == 0 {
+		return nil
+	}
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return interceptors[0](ctx, method, req, reply, cc, chainedInvoker(interceptors, 0, invoker), opts...)
+	}
+}
+
+func chainedInvoker(interceptors []grpc.UnaryClientInterceptor, current int, final grpc.UnaryInvoker) grpc.UnaryInvoker {
+	if current == len(interceptors)-1 {
+		return final
+	}
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		return interceptors[current+1](ctx, method, req, reply, cc, chainedInvoker(interceptors, current+1, final), opts...)
+	}
+}
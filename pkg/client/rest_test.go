@@ -0,0 +1,263 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/admin"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/management"
+	object "github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/object/v2"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/settings"
+	user "github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/user/v2"
+)
+
+func TestRESTConnection_newRequest(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		rule     *annotations.HttpRule
+		req      proto.Message
+		wantVerb string
+		wantURL  string
+		wantBody string
+		wantCode codes.Code
+	}{
+		{
+			name:     "get with path parameter",
+			method:   user.UserService_GetUserByID_FullMethodName,
+			req:      &user.GetUserByIDRequest{UserId: "user/1"},
+			wantVerb: http.MethodGet,
+			wantURL:  "https://zitadel.cloud/v2/users/user%2F1",
+		},
+		{
+			name:     "post with body without path parameter",
+			method:   user.UserService_SetEmail_FullMethodName,
+			req:      &user.SetEmailRequest{UserId: "user1", Email: "gigi@zitadel.com"},
+			wantVerb: http.MethodPost,
+			wantURL:  "https://zitadel.cloud/v2/users/user1/email",
+			wantBody: `{"email":"gigi@zitadel.com"}`,
+		},
+		{
+			name:   "get with nested query parameters",
+			method: user.UserService_ListAuthenticationMethodTypes_FullMethodName,
+			req: &user.ListAuthenticationMethodTypesRequest{
+				UserId:      "user1",
+				DomainQuery: &user.DomainQuery{IncludeWithoutDomain: true, Domain: "zitadel.cloud"},
+			},
+			wantVerb: http.MethodGet,
+			wantURL:  "https://zitadel.cloud/v2/users/user1/authentication_methods?domain_query.domain=zitadel.cloud&domain_query.include_without_domain=true",
+		},
+		{
+			name:     "delete",
+			method:   user.UserService_DeleteUser_FullMethodName,
+			req:      &user.DeleteUserRequest{UserId: "user1"},
+			wantVerb: http.MethodDelete,
+			wantURL:  "https://zitadel.cloud/v2/users/user1",
+		},
+		{
+			name:     "v1 prefix",
+			method:   management.ManagementService_GetUserByID_FullMethodName,
+			req:      &management.GetUserByIDRequest{Id: "user1"},
+			wantVerb: http.MethodGet,
+			wantURL:  "https://zitadel.cloud/management/v1/users/user1",
+		},
+		{
+			name:     "enum path parameter",
+			method:   admin.AdminService_GetSecretGenerator_FullMethodName,
+			req:      &admin.GetSecretGeneratorRequest{GeneratorType: settings.SecretGeneratorType_SECRET_GENERATOR_TYPE_INIT_CODE},
+			wantVerb: http.MethodGet,
+			wantURL:  "https://zitadel.cloud/admin/v1/secretgenerators/SECRET_GENERATOR_TYPE_INIT_CODE",
+		},
+		{
+			name: "body field",
+			rule: &annotations.HttpRule{
+				Pattern: &annotations.HttpRule_Put{Put: "/v2/users/{user_id}/email"},
+				Body:    "email",
+			},
+			req:      &user.SetEmailRequest{UserId: "user1", Email: "gigi@zitadel.com"},
+			wantVerb: http.MethodPut,
+			wantURL:  "https://zitadel.cloud/v2/users/user1/email",
+			wantBody: `"gigi@zitadel.com"`,
+		},
+		{
+			name: "multi segment path parameter",
+			rule: &annotations.HttpRule{
+				Pattern: &annotations.HttpRule_Get{Get: "/v2/{user_id=users/*}"},
+			},
+			req:      &user.GetUserByIDRequest{UserId: "users/user 1"},
+			wantVerb: http.MethodGet,
+			wantURL:  "https://zitadel.cloud/v2/users/user%201",
+		},
+		{
+			name: "unknown path parameter",
+			rule: &annotations.HttpRule{
+				Pattern: &annotations.HttpRule_Get{Get: "/v2/users/{id}"},
+			},
+			req:      &user.GetUserByIDRequest{UserId: "user1"},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "unknown method",
+			method:   "/zitadel.user.v2.UserService/Unknown",
+			req:      &user.GetUserByIDRequest{UserId: "user1"},
+			wantCode: codes.Unimplemented,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &restConnection{
+				origin: "https://zitadel.cloud",
+				cred:   &cred{tls: true, tokenSource: oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "token", TokenType: "Bearer"})},
+			}
+			rule, prefix := tt.rule, ""
+			var err error
+			if rule == nil {
+				rule, prefix, err = restRoute(tt.method)
+			}
+			var req *http.Request
+			if err == nil {
+				req, err = r.newRequest(context.Background(), rule, prefix, tt.req)
+			}
+			if tt.wantCode != codes.OK {
+				assert.Equal(t, tt.wantCode, status.Code(err))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantVerb, req.Method)
+			assert.Equal(t, tt.wantURL, req.URL.String())
+			assert.Equal(t, "Bearer token", req.Header.Get("Authorization"))
+			body, err := io.ReadAll(req.Body)
+			require.NoError(t, err)
+			if tt.wantBody == "" {
+				assert.Empty(t, body)
+				assert.Empty(t, req.Header.Get("Content-Type"))
+				return
+			}
+			assert.JSONEq(t, tt.wantBody, string(body))
+			assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+		})
+	}
+}
+
+func Test_restError(t *testing.T) {
+	tests := []struct {
+		name        string
+		statusCode  int
+		body        string
+		wantCode    codes.Code
+		wantMessage string
+	}{
+		{"status body", http.StatusBadRequest, `{"code":5,"message":"User could not be found","details":[]}`, codes.NotFound, "User could not be found"},
+		{"unknown details", http.StatusConflict, `{"code":6,"message":"exists","details":[{"@type":"unknown.Type"}]}`, codes.AlreadyExists, "exists"},
+		{"plain body", http.StatusBadGateway, "upstream connect error", codes.Unavailable, "upstream connect error"},
+		{"bad request", http.StatusBadRequest, "", codes.InvalidArgument, "Bad Request"},
+		{"unauthorized", http.StatusUnauthorized, "", codes.Unauthenticated, "Unauthorized"},
+		{"forbidden", http.StatusForbidden, "", codes.PermissionDenied, "Forbidden"},
+		{"not found", http.StatusNotFound, "", codes.NotFound, "Not Found"},
+		{"conflict", http.StatusConflict, "", codes.AlreadyExists, "Conflict"},
+		{"precondition failed", http.StatusPreconditionFailed, "", codes.FailedPrecondition, "Precondition Failed"},
+		{"too many requests", http.StatusTooManyRequests, "", codes.ResourceExhausted, "Too Many Requests"},
+		{"internal server error", http.StatusInternalServerError, "", codes.Internal, "Internal Server Error"},
+		{"not implemented", http.StatusNotImplemented, "", codes.Unimplemented, "Not Implemented"},
+		{"service unavailable", http.StatusServiceUnavailable, "", codes.Unavailable, "Service Unavailable"},
+		{"gateway timeout", http.StatusGatewayTimeout, "", codes.DeadlineExceeded, "Gateway Timeout"},
+		{"other", http.StatusTeapot, "", codes.Unknown, "I'm a teapot"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, ok := status.FromError(restError(tt.statusCode, []byte(tt.body)))
+			require.True(t, ok)
+			assert.Equal(t, tt.wantCode, s.Code())
+			assert.Equal(t, tt.wantMessage, s.Message())
+		})
+	}
+}
+
+func Test_unmarshalResponse(t *testing.T) {
+	tests := []struct {
+		name         string
+		body         string
+		responseBody string
+		want         proto.Message
+		wantCode     codes.Code
+	}{
+		{"empty", "", "", &user.GetUserByIDResponse{}, codes.OK},
+		{"unknown fields", `{"details":{"resourceOwner":"org1"},"unknown":true}`, "", &user.GetUserByIDResponse{Details: &object.Details{ResourceOwner: "org1"}}, codes.OK},
+		{"response body", `{"resourceOwner":"org1"}`, "details", &user.GetUserByIDResponse{Details: &object.Details{ResourceOwner: "org1"}}, codes.OK},
+		{"invalid", `{"details":1}`, "", nil, codes.Internal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := new(user.GetUserByIDResponse)
+			err := unmarshalResponse([]byte(tt.body), tt.responseBody, got)
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			if tt.want != nil {
+				assert.True(t, proto.Equal(tt.want, got), "got %v", got)
+			}
+		})
+	}
+}
+
+func TestRESTConnection_Invoke(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v2/users/user1", r.URL.Path)
+		assert.Equal(t, "org1", r.Header.Get("x-zitadel-orgid"))
+		w.Header().Set("Retry-After", "1")
+		if r.Header.Get("Authorization") == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"details":{"resourceOwner":"org1"}}`))
+	}))
+	defer server.Close()
+
+	var calls []string
+	interceptor := func(name string) grpc.UnaryClientInterceptor {
+		return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			calls = append(calls, name)
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+	}
+	tests := []struct {
+		name        string
+		tokenSource oauth2.TokenSource
+		wantCode    codes.Code
+	}{
+		{"authorized", oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "token"}), codes.OK},
+		{"unauthorized", nil, codes.Unauthenticated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls = nil
+			r := &restConnection{
+				origin:      server.URL,
+				httpClient:  server.Client(),
+				cred:        &cred{tokenSource: tt.tokenSource},
+				interceptor: chainUnaryInterceptors([]grpc.UnaryClientInterceptor{interceptor("first"), interceptor("second")}),
+			}
+			ctx := metadata.AppendToOutgoingContext(context.Background(), "x-zitadel-orgid", "org1")
+			var header metadata.MD
+			resp := new(user.GetUserByIDResponse)
+			err := r.Invoke(ctx, user.UserService_GetUserByID_FullMethodName, &user.GetUserByIDRequest{UserId: "user1"}, resp, grpc.Header(&header))
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			assert.Equal(t, []string{"first", "second"}, calls)
+			assert.Equal(t, []string{"1"}, header.Get("retry-after"))
+			if tt.wantCode == codes.OK {
+				assert.Equal(t, "org1", resp.GetDetails().GetResourceOwner())
+			}
+		})
+	}
+}
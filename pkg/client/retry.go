@@ -0,0 +1,141 @@
+package client
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"slices"
+	"strconv"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const retryAfterHeader = "retry-after"
+
+// RetryPolicy defines if and how failed calls are retried by the interceptor installed with [WithRetry].
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts including the initial call.
+	MaxAttempts int
+	// InitialBackoff is the time to wait before the first retry.
+	InitialBackoff time.Duration
+	// MaxBackoff limits the time to wait between two attempts.
+	MaxBackoff time.Duration
+	// Multiplier is applied to the backoff after every attempt.
+	Multiplier float64
+	// Jitter randomizes the backoff by the provided fraction (0-1) to prevent synchronized retries.
+	Jitter float64
+	// Codes are the status codes of failed calls to be retried.
+	Codes []codes.Code
+}
+
+// DefaultRetryPolicy retries calls failing with UNAVAILABLE or RESOURCE_EXHAUSTED up to 4 times.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    5,
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     5 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+		Codes:          []codes.Code{codes.Unavailable, codes.ResourceExhausted},
+	}
+}
+
+// WithRetry installs an interceptor retrying failed calls using exponential backoff as defined by the [RetryPolicy].
+// If ZITADEL returns a `retry-after` header (e.g. when rate limited) or a [errdetails.RetryInfo],
+// the interceptor will wait at least the requested delay.
+// Streams are only retried if they could not be established.
+func WithRetry(policy RetryPolicy) Option {
+	return func(c *clientOptions) {
+		c.unaryInterceptors = append(c.unaryInterceptors, policy.unaryInterceptor())
+		c.streamInterceptors = append(c.streamInterceptors, policy.streamInterceptor())
+	}
+}
+
+func (p RetryPolicy) unaryInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		var err error
+		for attempt := 0; ; attempt++ {
+			var header, trailer metadata.MD
+			err = invoker(ctx, method, req, reply, cc, append(slices.Clip(opts), grpc.Header(&header), grpc.Trailer(&trailer))...)
+			if !p.retryable(err, attempt) {
+				return err
+			}
+			if waitErr := wait(ctx, p.delay(attempt, err, header, trailer)); waitErr != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (p RetryPolicy) streamInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		for attempt := 0; ; attempt++ {
+			stream, err := streamer(ctx, desc, cc, method, opts...)
+			if !p.retryable(err, attempt) {
+				return stream, err
+			}
+			if waitErr := wait(ctx, p.delay(attempt, err, nil, nil)); waitErr != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+func (p RetryPolicy) retryable(err error, attempt int) bool {
+	if err == nil || attempt+1 >= p.MaxAttempts {
+		return false
+	}
+	return slices.Contains(p.Codes, status.Code(err))
+}
+
+// delay computes the jittered exponential backoff for the attempt,
+// but returns the delay requested by the server if it's longer.
+func (p RetryPolicy) delay(attempt int, err error, header, trailer metadata.MD) time.Duration {
+	backoff := float64(p.InitialBackoff) * math.Pow(p.Multiplier, float64(attempt))
+	if p.MaxBackoff > 0 && backoff > float64(p.MaxBackoff) {
+		backoff = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		backoff += backoff * p.Jitter * (rand.Float64()*2 - 1)
+	}
+	return max(time.Duration(backoff), serverDelay(err, header, trailer))
+}
+
+// serverDelay returns the delay requested by the server, either as `retry-after` header (in seconds)
+// or as [errdetails.RetryInfo] in the status details.
+func serverDelay(err error, mds ...metadata.MD) time.Duration {
+	for _, detail := range status.Convert(err).Details() {
+		if info, ok := detail.(*errdetails.RetryInfo); ok && info.GetRetryDelay() != nil {
+			return info.GetRetryDelay().AsDuration()
+		}
+	}
+	for _, md := range mds {
+		values := md.Get(retryAfterHeader)
+		if len(values) == 0 {
+			continue
+		}
+		if seconds, err := strconv.Atoi(values[0]); err == nil {
+			return time.Duration(seconds) * time.Second
+		}
+		if date, err := time.Parse(time.RFC1123, values[0]); err == nil {
+			return time.Until(date)
+		}
+	}
+	return 0
+}
+
+func wait(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
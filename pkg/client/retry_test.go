@@ -0,0 +1,138 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func TestRetryPolicy_delay(t *testing.T) {
+	policy := RetryPolicy{
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+	}
+	tests := []struct {
+		name    string
+		attempt int
+		backoff time.Duration
+	}{
+		{"initial", 0, 100 * time.Millisecond},
+		{"multiplied", 2, 400 * time.Millisecond},
+		{"max backoff", 5, time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jitter := time.Duration(float64(tt.backoff) * policy.Jitter)
+			for range 100 {
+				delay := policy.delay(tt.attempt, status.Error(codes.Unavailable, "unavailable"), nil, nil)
+				assert.GreaterOrEqual(t, delay, tt.backoff-jitter)
+				assert.LessOrEqual(t, delay, tt.backoff+jitter)
+			}
+		})
+	}
+	t.Run("without jitter", func(t *testing.T) {
+		policy := policy
+		policy.Jitter = 0
+		assert.Equal(t, 200*time.Millisecond, policy.delay(1, nil, nil, nil))
+	})
+	t.Run("server delay", func(t *testing.T) {
+		header := metadata.Pairs(retryAfterHeader, "3")
+		assert.Equal(t, 3*time.Second, policy.delay(0, status.Error(codes.ResourceExhausted, "rate limited"), header, nil))
+	})
+}
+
+func Test_serverDelay(t *testing.T) {
+	retryInfo, err := status.New(codes.ResourceExhausted, "rate limited").
+		WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(2 * time.Second)})
+	require.NoError(t, err)
+	tests := []struct {
+		name string
+		err  error
+		mds  []metadata.MD
+		want time.Duration
+	}{
+		{"none", status.Error(codes.Unavailable, "unavailable"), nil, 0},
+		{"retry info", retryInfo.Err(), []metadata.MD{metadata.Pairs(retryAfterHeader, "5")}, 2 * time.Second},
+		{"header seconds", status.Error(codes.ResourceExhausted, "rate limited"), []metadata.MD{metadata.Pairs(retryAfterHeader, "5")}, 5 * time.Second},
+		{"trailer seconds", status.Error(codes.ResourceExhausted, "rate limited"), []metadata.MD{nil, metadata.Pairs(retryAfterHeader, "1")}, time.Second},
+		{"invalid header", status.Error(codes.ResourceExhausted, "rate limited"), []metadata.MD{metadata.Pairs(retryAfterHeader, "soon")}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, serverDelay(tt.err, tt.mds...))
+		})
+	}
+	t.Run("header date", func(t *testing.T) {
+		header := metadata.Pairs(retryAfterHeader, time.Now().Add(time.Minute).UTC().Format(http.TimeFormat))
+		assert.InDelta(t, time.Minute, serverDelay(status.Error(codes.ResourceExhausted, "rate limited"), header), float64(2*time.Second))
+	})
+}
+
+func TestRetryPolicy_unaryInterceptor(t *testing.T) {
+	policy := RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+		Multiplier:     2,
+		Codes:          []codes.Code{codes.Unavailable, codes.ResourceExhausted},
+	}
+	tests := []struct {
+		name         string
+		errs         []error
+		wantAttempts int
+		wantCode     codes.Code
+	}{
+		{"success", []error{nil}, 1, codes.OK},
+		{"retried", []error{status.Error(codes.Unavailable, "unavailable"), nil}, 2, codes.OK},
+		{"max attempts", []error{status.Error(codes.Unavailable, "unavailable"), status.Error(codes.ResourceExhausted, "rate limited"), status.Error(codes.Unavailable, "unavailable"), nil}, 3, codes.Unavailable},
+		{"not retryable", []error{status.Error(codes.InvalidArgument, "invalid"), nil}, 1, codes.InvalidArgument},
+		{"not retryable after retry", []error{status.Error(codes.Unavailable, "unavailable"), status.Error(codes.NotFound, "not found"), nil}, 2, codes.NotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts int
+			err := policy.unaryInterceptor()(context.Background(), "/zitadel.admin.v1.AdminService/ListOrgs", nil, nil, nil,
+				func(_ context.Context, _ string, _, _ any, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+					attempts++
+					return tt.errs[attempts-1]
+				})
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			assert.Equal(t, tt.wantAttempts, attempts)
+		})
+	}
+}
+
+func TestRetryPolicy_unaryInterceptor_retryAfter(t *testing.T) {
+	policy := RetryPolicy{
+		MaxAttempts:    2,
+		InitialBackoff: time.Millisecond,
+		Codes:          []codes.Code{codes.ResourceExhausted},
+	}
+	invoker := func(_ context.Context, _ string, _, _ any, _ *grpc.ClientConn, opts ...grpc.CallOption) error {
+		for _, opt := range opts {
+			if header, ok := opt.(grpc.HeaderCallOption); ok {
+				*header.HeaderAddr = metadata.Pairs(retryAfterHeader, "60")
+			}
+		}
+		return status.Error(codes.ResourceExhausted, "rate limited")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := policy.unaryInterceptor()(ctx, "/zitadel.admin.v1.AdminService/ListOrgs", nil, nil, nil, invoker)
+	// the requested delay exceeds the deadline, so the error of the call is returned once the context is done
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+	assert.Less(t, time.Since(start), time.Second)
+}
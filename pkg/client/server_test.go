@@ -0,0 +1,75 @@
+package client
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/auth"
+	"github.com/zitadel/zitadel-go/v3/pkg/zitadel"
+)
+
+// testServer is an (insecure) gRPC server of the auth API, recording the metadata of the calls.
+// Its health can be flipped by [testServer.setServing].
+type testServer struct {
+	auth.UnimplementedAuthServiceServer
+	listener net.Listener
+	server   *grpc.Server
+
+	mu        sync.Mutex
+	calls     []metadata.MD
+	unhealthy bool
+}
+
+func newTestServer(t *testing.T, options ...grpc.ServerOption) *testServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s := &testServer{listener: listener, server: grpc.NewServer(options...)}
+	auth.RegisterAuthServiceServer(s.server, s)
+	go s.server.Serve(listener)
+	t.Cleanup(s.server.Stop)
+	return s
+}
+
+// zitadel returns the instance served by the server.
+func (s *testServer) zitadel(t *testing.T) *zitadel.Zitadel {
+	host, port, err := net.SplitHostPort(s.addr())
+	require.NoError(t, err)
+	return zitadel.New(host, zitadel.WithInsecure(port))
+}
+
+func (s *testServer) addr() string {
+	return s.listener.Addr().String()
+}
+
+func (s *testServer) setServing(serving bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.unhealthy = !serving
+}
+
+// metadata returns the metadata of the calls received so far.
+func (s *testServer) metadata() []metadata.MD {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]metadata.MD(nil), s.calls...)
+}
+
+// Healthz implements [auth.AuthServiceServer]
+func (s *testServer) Healthz(ctx context.Context, _ *auth.HealthzRequest) (*auth.HealthzResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls = append(s.calls, md)
+	if s.unhealthy {
+		return nil, status.Error(codes.Unavailable, "not serving")
+	}
+	return &auth.HealthzResponse{}, nil
+}
@@ -0,0 +1,34 @@
+package client
+
+// Service is the fully qualified gRPC name of a ZITADEL API service,
+// e.g. used to route a service to a different endpoint using [WithServiceEndpoint].
+type Service string
+
+const (
+	ServiceSystem             Service = "zitadel.system.v1.SystemService"
+	ServiceAdmin              Service = "zitadel.admin.v1.AdminService"
+	ServiceManagement         Service = "zitadel.management.v1.ManagementService"
+	ServiceAuth               Service = "zitadel.auth.v1.AuthService"
+	ServiceUserV2Beta         Service = "zitadel.user.v2beta.UserService"
+	ServiceUserV2             Service = "zitadel.user.v2.UserService"
+	ServiceSettingsV2Beta     Service = "zitadel.settings.v2beta.SettingsService"
+	ServiceSettingsV2         Service = "zitadel.settings.v2.SettingsService"
+	ServiceSessionV2Beta      Service = "zitadel.session.v2beta.SessionService"
+	ServiceSessionV2          Service = "zitadel.session.v2.SessionService"
+	ServiceOrganizationV2Beta Service = "zitadel.org.v2beta.OrganizationService"
+	ServiceOrganizationV2     Service = "zitadel.org.v2.OrganizationService"
+	ServiceOIDCV2Beta         Service = "zitadel.oidc.v2beta.OIDCService"
+	ServiceOIDCV2             Service = "zitadel.oidc.v2.OIDCService"
+)
+
+// WithServiceEndpoint allows to route all calls of a specific [Service] to a different endpoint (host:port),
+// e.g. the system API running on an internal address.
+// The connection will use the same authentication and transport security settings as the default one.
+func WithServiceEndpoint(service Service, hostPort string) Option {
+	return func(c *clientOptions) {
+		if c.serviceEndpoints == nil {
+			c.serviceEndpoints = make(map[Service]string)
+		}
+		c.serviceEndpoints[service] = hostPort
+	}
+}
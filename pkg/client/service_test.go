@@ -0,0 +1,93 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/admin"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/management"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/system"
+)
+
+// methodRecorder records the methods of all calls to services not registered on the server.
+type methodRecorder struct {
+	mu      sync.Mutex
+	methods []string
+}
+
+func (r *methodRecorder) handler(_ any, stream grpc.ServerStream) error {
+	method, _ := grpc.MethodFromServerStream(stream)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.methods = append(r.methods, method)
+	return status.Error(codes.Unimplemented, "recorded")
+}
+
+func (r *methodRecorder) calls() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.methods
+}
+
+func TestWithServiceEndpoint(t *testing.T) {
+	const (
+		adminHealthz      = "/zitadel.admin.v1.AdminService/Healthz"
+		managementHealthz = "/zitadel.management.v1.ManagementService/Healthz"
+		systemHealthz     = "/zitadel.system.v1.SystemService/Healthz"
+	)
+	tests := []struct {
+		name        string
+		routes      []Service
+		wantDefault []string
+		wantRouted  []string
+	}{
+		{
+			name:        "no routes",
+			wantDefault: []string{systemHealthz, adminHealthz, managementHealthz},
+		},
+		{
+			name:        "single service",
+			routes:      []Service{ServiceSystem},
+			wantDefault: []string{adminHealthz, managementHealthz},
+			wantRouted:  []string{systemHealthz},
+		},
+		{
+			name:        "multiple services sharing the endpoint",
+			routes:      []Service{ServiceSystem, ServiceManagement},
+			wantDefault: []string{adminHealthz},
+			wantRouted:  []string{systemHealthz, managementHealthz},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var defaultCalls, routedCalls methodRecorder
+			defaultServer := newTestServer(t, grpc.UnknownServiceHandler(defaultCalls.handler))
+			routedServer := newTestServer(t, grpc.UnknownServiceHandler(routedCalls.handler))
+			options := []Option{WithAuth(PAT("token"))}
+			for _, service := range tt.routes {
+				options = append(options, WithServiceEndpoint(service, routedServer.addr()))
+			}
+			c, err := New(context.Background(), defaultServer.zitadel(t), options...)
+			require.NoError(t, err)
+			defer c.Close()
+
+			ctx := context.Background()
+			_, err = c.SystemService().Healthz(ctx, &system.HealthzRequest{})
+			require.Equal(t, codes.Unimplemented, status.Code(err))
+			_, err = c.AdminService().Healthz(ctx, &admin.HealthzRequest{})
+			require.Equal(t, codes.Unimplemented, status.Code(err))
+			_, err = c.ManagementService().Healthz(ctx, &management.HealthzRequest{})
+			require.Equal(t, codes.Unimplemented, status.Code(err))
+
+			assert.Equal(t, tt.wantDefault, defaultCalls.calls())
+			assert.Equal(t, tt.wantRouted, routedCalls.calls())
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+
+	"github.com/spiffe/go-spiffe/v2/bundle/x509bundle"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/go-spiffe/v2/spiffetls/tlsconfig"
+	"github.com/spiffe/go-spiffe/v2/svid/x509svid"
+	"golang.org/x/oauth2"
+)
+
+// ErrNoSVIDSource is returned by [New] if [WithSPIFFE] and by [SVIDAuthentication] if no X509-SVID source is provided.
+var ErrNoSVIDSource = errors.New("no X509-SVID source provided")
+
+// WithSPIFFE uses the X509-SVID of the provided source (e.g. a workloadapi.X509Source) as client certificate
+// for mutual TLS with ZITADEL or a proxy in front of it. The SVID is read on every handshake,
+// so rotated certificates are picked up automatically.
+//
+// If no bundle is provided, the server certificate is verified as usual (system cert pool),
+// which is the case when connecting to ZITADEL directly.
+// Otherwise, the server needs to present an X509-SVID of the bundle accepted by the authorizer
+// (e.g. [tlsconfig.AuthorizeID]), which is the case when connecting through a proxy of the service mesh.
+func WithSPIFFE(svid x509svid.Source, bundle x509bundle.Source, authorizer tlsconfig.Authorizer) Option {
+	return func(c *clientOptions) {
+		c.spiffe = &spiffeConfig{svid: svid, bundle: bundle, authorizer: authorizer}
+	}
+}
+
+type spiffeConfig struct {
+	svid       x509svid.Source
+	bundle     x509bundle.Source
+	authorizer tlsconfig.Authorizer
+}
+
+// installSPIFFE installs the TLS configuration hook presenting the X509-SVID of [WithSPIFFE].
+func (o *clientOptions) installSPIFFE() error {
+	if o.spiffe == nil {
+		return nil
+	}
+	if o.spiffe.svid == nil {
+		return ErrNoSVIDSource
+	}
+	spiffe := o.spiffe
+	o.tlsConfigHooks = append(o.tlsConfigHooks, func(config *tls.Config) {
+		if spiffe.bundle == nil {
+			config.GetClientCertificate = tlsconfig.GetClientCertificate(spiffe.svid)
+			return
+		}
+		tlsconfig.HookMTLSClientConfig(config, spiffe.svid, spiffe.bundle, spiffe.authorizer)
+	})
+	return nil
+}
+
+// SVIDAuthentication selects the authentication of the service user mapped to the SPIFFE ID
+// of the current X509-SVID of the workload. This allows to deploy the same configuration to all workloads
+// and let each of them authenticate as its own service user.
+func SVIDAuthentication(svid x509svid.Source, serviceUsers map[spiffeid.ID]TokenSourceInitializer) TokenSourceInitializer {
+	return func(ctx context.Context, issuer string) (oauth2.TokenSource, error) {
+		if svid == nil {
+			return nil, ErrNoSVIDSource
+		}
+		current, err := svid.GetX509SVID()
+		if err != nil {
+			return nil, err
+		}
+		initTokenSource, ok := serviceUsers[current.ID]
+		if !ok {
+			return nil, fmt.Errorf("no service user mapped to SPIFFE ID %s", current.ID)
+		}
+		return initTokenSource(ctx, issuer)
+	}
+}
@@ -0,0 +1,139 @@
+package client
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"math/big"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/spiffe/go-spiffe/v2/bundle/x509bundle"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/go-spiffe/v2/spiffetls/tlsconfig"
+	"github.com/spiffe/go-spiffe/v2/svid/x509svid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/zitadel"
+)
+
+// newTestSVID creates a self-signed X509-SVID of the SPIFFE ID.
+func newTestSVID(t *testing.T, id spiffeid.ID) *x509svid.SVID {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	uri, err := url.Parse(id.String())
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		URIs:         []*url.URL{uri},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &x509svid.SVID{ID: id, Certificates: []*x509.Certificate{cert}, PrivateKey: key}
+}
+
+func TestWithSPIFFE(t *testing.T) {
+	id := spiffeid.RequireFromString("spiffe://example.com/workload")
+	svid := newTestSVID(t, id)
+	bundle := x509bundle.FromX509Authorities(id.TrustDomain(), svid.Certificates)
+
+	tests := []struct {
+		name           string
+		bundle         x509bundle.Source
+		wantMeshVerify bool
+	}{
+		{
+			name: "without bundle",
+		},
+		{
+			name:           "with bundle",
+			bundle:         bundle,
+			wantMeshVerify: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var options clientOptions
+			WithSPIFFE(svid, tt.bundle, tlsconfig.AuthorizeID(id))(&options)
+			require.NoError(t, options.installSPIFFE())
+
+			config, err := clientTLSConfig("zitadel.example.com", false, options.tlsConfigHooks...)
+			require.NoError(t, err)
+			require.NotNil(t, config.GetClientCertificate)
+			cert, err := config.GetClientCertificate(&tls.CertificateRequestInfo{})
+			require.NoError(t, err)
+			assert.Equal(t, svid.Certificates[0].Raw, cert.Certificate[0])
+
+			assert.Equal(t, tt.wantMeshVerify, config.InsecureSkipVerify, "the server certificate is verified against the bundle instead")
+			assert.Equal(t, tt.wantMeshVerify, config.VerifyPeerCertificate != nil)
+		})
+	}
+}
+
+func TestWithSPIFFE_noSource(t *testing.T) {
+	_, err := New(context.Background(), zitadel.New("zitadel.example.com"), WithSPIFFE(nil, nil, nil))
+	assert.ErrorIs(t, err, ErrNoSVIDSource)
+}
+
+func TestSVIDAuthentication(t *testing.T) {
+	id := spiffeid.RequireFromString("spiffe://example.com/workload")
+	other := spiffeid.RequireFromString("spiffe://example.com/other")
+	svid := newTestSVID(t, id)
+
+	tests := []struct {
+		name      string
+		svid      x509svid.Source
+		users     map[spiffeid.ID]TokenSourceInitializer
+		wantToken string
+		wantErr   error
+		// wantErrMsg is the expected error message, if it's not a sentinel error
+		wantErrMsg string
+	}{
+		{
+			name:    "no source",
+			wantErr: ErrNoSVIDSource,
+		},
+		{
+			name:  "mapped",
+			svid:  svid,
+			users: map[spiffeid.ID]TokenSourceInitializer{id: PAT("workload"), other: PAT("other")},
+			// the PAT is returned as is
+			wantToken: "workload",
+		},
+		{
+			name:       "not mapped",
+			svid:       svid,
+			users:      map[spiffeid.ID]TokenSourceInitializer{other: PAT("other")},
+			wantErrMsg: "no service user mapped to SPIFFE ID " + id.String(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source, err := SVIDAuthentication(tt.svid, tt.users)(context.Background(), "https://zitadel.example.com")
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			if tt.wantErrMsg != "" {
+				assert.EqualError(t, err, tt.wantErrMsg)
+				return
+			}
+			require.NoError(t, err)
+			var token *oauth2.Token
+			token, err = source.Token()
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantToken, token.AccessToken)
+		})
+	}
+}
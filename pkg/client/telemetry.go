@@ -0,0 +1,220 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// RequestIDHeader is the response header which is recorded as span attribute if returned by ZITADEL.
+	RequestIDHeader = "x-request-id"
+
+	attributeOrgID     = attribute.Key("zitadel.org_id")
+	attributeService   = attribute.Key("zitadel.service")
+	attributeRequestID = attribute.Key("zitadel.request_id")
+
+	instrumentationName = "github.com/zitadel/zitadel-go/v3/pkg/client"
+	// durationMetric is the histogram of the call durations (in milliseconds).
+	durationMetric = "rpc.client.duration"
+)
+
+// WithTelemetry instruments all calls with OpenTelemetry tracing and metrics.
+// The spans contain the standard RPC attributes, the ZITADEL service,
+// the organization context (if set) and the request id returned by ZITADEL.
+// The calls are instrumented by interceptors (see [WithUnaryInterceptors]), so they are also recorded with [TransportREST],
+// whose HTTP requests (as well as the ones of [Client.HTTPClient]) are instrumented using otelhttp in addition.
+// If a provider is nil, the corresponding global provider will be used.
+func WithTelemetry(tracerProvider trace.TracerProvider, meterProvider metric.MeterProvider) Option {
+	return func(c *clientOptions) {
+		t := newTelemetry(tracerProvider, meterProvider)
+		c.telemetry = t
+		c.unaryInterceptors = append(c.unaryInterceptors, t.unaryInterceptor())
+		c.streamInterceptors = append(c.streamInterceptors, t.streamInterceptor())
+	}
+}
+
+type telemetry struct {
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+	tracer         trace.Tracer
+	duration       metric.Float64Histogram
+	propagator     propagation.TextMapPropagator
+}
+
+func newTelemetry(tracerProvider trace.TracerProvider, meterProvider metric.MeterProvider) *telemetry {
+	if tracerProvider == nil {
+		tracerProvider = otel.GetTracerProvider()
+	}
+	if meterProvider == nil {
+		meterProvider = otel.GetMeterProvider()
+	}
+	duration, err := meterProvider.Meter(instrumentationName).Float64Histogram(durationMetric,
+		metric.WithDescription("Measures the duration of outbound RPC."),
+		metric.WithUnit("ms"),
+	)
+	if err != nil {
+		otel.Handle(err)
+	}
+	return &telemetry{
+		tracerProvider: tracerProvider,
+		meterProvider:  meterProvider,
+		tracer:         tracerProvider.Tracer(instrumentationName),
+		duration:       duration,
+		propagator:     otel.GetTextMapPropagator(),
+	}
+}
+
+// transport instruments the HTTP requests of the [http.RoundTripper].
+func (t *telemetry) transport(base http.RoundTripper) http.RoundTripper {
+	return otelhttp.NewTransport(base,
+		otelhttp.WithTracerProvider(t.tracerProvider),
+		otelhttp.WithMeterProvider(t.meterProvider),
+	)
+}
+
+func (t *telemetry) unaryInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		ctx, call := t.start(ctx, method)
+		var header metadata.MD
+		err := invoker(ctx, method, req, reply, cc, append(opts, grpc.Header(&header))...)
+		call.end(header, err)
+		return err
+	}
+}
+
+func (t *telemetry) streamInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		ctx, call := t.start(ctx, method)
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			call.end(nil, err)
+			return nil, err
+		}
+		return &telemetryStream{ClientStream: stream, call: call}, nil
+	}
+}
+
+// call is a call recorded by the [telemetry].
+type call struct {
+	telemetry  *telemetry
+	span       trace.Span
+	attributes []attribute.KeyValue
+	start      time.Time
+	ctx        context.Context
+	once       sync.Once
+}
+
+// start starts the span of the call and propagates its context to ZITADEL.
+func (t *telemetry) start(ctx context.Context, method string) (context.Context, *call) {
+	fullMethod := strings.TrimPrefix(method, "/")
+	attributes := []attribute.KeyValue{semconv.RPCSystemGRPC}
+	spanAttributes := make([]attribute.KeyValue, 0, 2)
+	if service, name, ok := strings.Cut(fullMethod, "/"); ok {
+		attributes = append(attributes, semconv.RPCService(service), semconv.RPCMethod(name))
+		spanAttributes = append(spanAttributes, attributeService.String(service))
+	}
+	md, _ := metadata.FromOutgoingContext(ctx)
+	if orgID := md.Get(OrgHeader); len(orgID) > 0 {
+		spanAttributes = append(spanAttributes, attributeOrgID.String(orgID[0]))
+	}
+	ctx, span := t.tracer.Start(ctx, fullMethod,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attributes...),
+		trace.WithAttributes(spanAttributes...),
+	)
+	md = md.Copy()
+	t.propagator.Inject(ctx, metadataCarrier(md))
+	ctx = metadata.NewOutgoingContext(ctx, md)
+	return ctx, &call{telemetry: t, span: span, attributes: attributes, start: time.Now(), ctx: ctx}
+}
+
+// end records the result of the call, only the first call of end is recorded.
+func (c *call) end(header metadata.MD, err error) {
+	c.once.Do(func() {
+		if requestID := header.Get(RequestIDHeader); len(requestID) > 0 {
+			c.span.SetAttributes(attributeRequestID.String(requestID[0]))
+		}
+		code := status.Code(err)
+		c.span.SetAttributes(semconv.RPCGRPCStatusCodeKey.Int(int(code)))
+		if err != nil {
+			c.span.SetStatus(otelcodes.Error, status.Convert(err).Message())
+		}
+		c.span.End()
+		if c.telemetry.duration != nil {
+			attributes := append(c.attributes, semconv.RPCGRPCStatusCodeKey.Int(int(code)))
+			elapsed := float64(time.Since(c.start)) / float64(time.Millisecond)
+			c.telemetry.duration.Record(c.ctx, elapsed, metric.WithAttributes(attributes...))
+		}
+	})
+}
+
+// telemetryStream ends the call once the stream is finished.
+type telemetryStream struct {
+	grpc.ClientStream
+	call *call
+}
+
+// RecvMsg implements [grpc.ClientStream]
+func (s *telemetryStream) RecvMsg(m any) error {
+	err := s.ClientStream.RecvMsg(m)
+	if errors.Is(err, io.EOF) {
+		s.call.end(s.header(), nil)
+	} else if err != nil {
+		s.call.end(s.header(), err)
+	}
+	return err
+}
+
+// SendMsg implements [grpc.ClientStream]
+func (s *telemetryStream) SendMsg(m any) error {
+	err := s.ClientStream.SendMsg(m)
+	if err != nil && !errors.Is(err, io.EOF) {
+		s.call.end(s.header(), err)
+	}
+	return err
+}
+
+func (s *telemetryStream) header() metadata.MD {
+	header, _ := s.ClientStream.Header()
+	return header
+}
+
+// metadataCarrier implements [propagation.TextMapCarrier] for the outgoing metadata.
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	values := metadata.MD(c).Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
@@ -0,0 +1,100 @@
+package client
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/zitadel"
+)
+
+func TestWithTelemetry(t *testing.T) {
+	grpcServer := newTestServer(t, grpc.UnaryInterceptor(func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		_ = grpc.SetHeader(ctx, metadata.Pairs(RequestIDHeader, "request1"))
+		return handler(ctx, req)
+	}))
+	restServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/auth/v1/healthz", r.URL.Path)
+		assert.Equal(t, "org1", r.Header.Get(OrgHeader))
+		w.Header().Set(RequestIDHeader, "request1")
+		w.Write([]byte(`{}`))
+	}))
+	defer restServer.Close()
+	host, port, err := net.SplitHostPort(restServer.Listener.Addr().String())
+	require.NoError(t, err)
+
+	tests := []struct {
+		name          string
+		zitadel       *zitadel.Zitadel
+		transport     Transport
+		wantHTTPSpans int
+	}{
+		{"grpc", grpcServer.zitadel(t), TransportGRPC, 0},
+		{"rest", zitadel.New(host, zitadel.WithInsecure(port)), TransportREST, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spans := tracetest.NewSpanRecorder()
+			metrics := sdkmetric.NewManualReader()
+			c, err := New(context.Background(), tt.zitadel, WithAuth(PAT("token")), WithTransport(tt.transport),
+				WithTelemetry(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)), sdkmetric.NewMeterProvider(sdkmetric.WithReader(metrics))),
+			)
+			require.NoError(t, err)
+			defer c.Close()
+
+			require.NoError(t, c.ForOrganization("org1").Healthz(context.Background()))
+
+			var rpcSpan sdktrace.ReadOnlySpan
+			var httpSpans []sdktrace.ReadOnlySpan
+			for _, span := range spans.Ended() {
+				if span.Name() == string(ServiceAuth)+"/Healthz" {
+					rpcSpan = span
+					continue
+				}
+				httpSpans = append(httpSpans, span)
+			}
+			require.NotNil(t, rpcSpan)
+			require.Len(t, httpSpans, tt.wantHTTPSpans)
+			for _, span := range httpSpans {
+				assert.Equal(t, rpcSpan.SpanContext().SpanID(), span.Parent().SpanID(), "the HTTP request is part of the call")
+			}
+			assert.Equal(t, trace.SpanKindClient, rpcSpan.SpanKind())
+			assert.Subset(t, rpcSpan.Attributes(), []attribute.KeyValue{
+				attributeService.String(string(ServiceAuth)),
+				attributeOrgID.String("org1"),
+				attributeRequestID.String("request1"),
+				attribute.Int("rpc.grpc.status_code", 0),
+			})
+
+			var data metricdata.ResourceMetrics
+			require.NoError(t, metrics.Collect(context.Background(), &data))
+			var calls uint64
+			for _, scope := range data.ScopeMetrics {
+				for _, m := range scope.Metrics {
+					if m.Name != durationMetric {
+						continue
+					}
+					for _, point := range m.Data.(metricdata.Histogram[float64]).DataPoints {
+						service, _ := point.Attributes.Value("rpc.service")
+						assert.Equal(t, string(ServiceAuth), service.AsString())
+						calls += point.Count
+					}
+				}
+			}
+			assert.Equal(t, uint64(1), calls)
+		})
+	}
+}
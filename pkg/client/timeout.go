@@ -0,0 +1,65 @@
+package client
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// WithDefaultTimeout applies a deadline of d to all unary calls, whose context has no deadline yet,
+// preventing calls from hanging forever if the caller forgets to set a timeout.
+// The deadline covers the whole call including retries (see [WithRetry]).
+// Streams are not limited, since they are usually long-lived.
+func WithDefaultTimeout(d time.Duration) Option {
+	return func(c *clientOptions) {
+		c.defaultTimeout = d
+	}
+}
+
+// WithServiceTimeout overrides the default timeout (see [WithDefaultTimeout]) for the calls of the [Service],
+// e.g. a longer one for the calls of the admin API used by exports.
+// A timeout of zero disables the deadline for the service.
+func WithServiceTimeout(service Service, d time.Duration) Option {
+	return func(c *clientOptions) {
+		if c.serviceTimeouts == nil {
+			c.serviceTimeouts = make(map[Service]time.Duration)
+		}
+		c.serviceTimeouts[service] = d
+	}
+}
+
+// installTimeouts installs the interceptor applying the timeouts as first interceptor, so it covers the others.
+func (o *clientOptions) installTimeouts() {
+	if o.defaultTimeout <= 0 && len(o.serviceTimeouts) == 0 {
+		return
+	}
+	o.unaryInterceptors = append([]grpc.UnaryClientInterceptor{timeoutInterceptor(o.defaultTimeout, o.serviceTimeouts)}, o.unaryInterceptors...)
+}
+
+func timeoutInterceptor(defaultTimeout time.Duration, serviceTimeouts map[Service]time.Duration) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if _, ok := ctx.Deadline(); !ok {
+			timeout, ok := serviceTimeouts[methodService(method)]
+			if !ok {
+				timeout = defaultTimeout
+			}
+			if timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, timeout)
+				defer cancel()
+			}
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// methodService returns the [Service] of the full gRPC method, e.g. `/zitadel.admin.v1.AdminService/ListOrgs`.
+func methodService(method string) Service {
+	method = strings.TrimPrefix(method, "/")
+	if i := strings.LastIndex(method, "/"); i >= 0 {
+		method = method[:i]
+	}
+	return Service(method)
+}
@@ -0,0 +1,54 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+func TestTimeoutInterceptor(t *testing.T) {
+	interceptor := timeoutInterceptor(time.Second, map[Service]time.Duration{
+		ServiceAdmin:  time.Minute,
+		ServiceSystem: 0,
+	})
+	tests := []struct {
+		name         string
+		method       string
+		ctxTimeout   time.Duration
+		wantDeadline bool
+		wantTimeout  time.Duration
+	}{
+		{"default", "/zitadel.management.v1.ManagementService/GetMyOrg", 0, true, time.Second},
+		{"service override", "/zitadel.admin.v1.AdminService/ListOrgs", 0, true, time.Minute},
+		{"service disabled", "/zitadel.system.v1.SystemService/ListInstances", 0, false, 0},
+		{"context deadline kept", "/zitadel.management.v1.ManagementService/GetMyOrg", time.Hour, true, time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.ctxTimeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.ctxTimeout)
+				defer cancel()
+			}
+			err := interceptor(ctx, tt.method, nil, nil, nil, func(ctx context.Context, _ string, _, _ any, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+				deadline, ok := ctx.Deadline()
+				require.Equal(t, tt.wantDeadline, ok)
+				if ok {
+					assert.WithinDuration(t, time.Now().Add(tt.wantTimeout), deadline, time.Second)
+				}
+				return nil
+			})
+			require.NoError(t, err)
+		})
+	}
+}
+
+func Test_methodService(t *testing.T) {
+	assert.Equal(t, ServiceAdmin, methodService("/zitadel.admin.v1.AdminService/ListOrgs"))
+	assert.Equal(t, ServiceUserV2, methodService("/zitadel.user.v2.UserService/GetUserByID"))
+}
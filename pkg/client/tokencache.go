@@ -0,0 +1,280 @@
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// tokenExpiryDelta is subtracted from the expiry of a cached token to make sure it will
+// not expire during the request it is used for.
+const tokenExpiryDelta = 10 * time.Second
+
+// ErrTokenNotCached is returned by a [TokenCache] if there is no token stored for the requested key.
+var ErrTokenNotCached = errors.New("token not cached")
+
+// TokenCache abstracts the storage of tokens, so they can be reused across client instances
+// and process restarts. Implementations must be safe for concurrent use.
+type TokenCache interface {
+	// Load returns the token stored for the key or [ErrTokenNotCached] if there is none.
+	Load(key string) (*oauth2.Token, error)
+	// Store saves the token for the key, replacing an existing one.
+	Store(key string, token *oauth2.Token) error
+}
+
+// WithTokenCache allows to store the tokens of the configured authentication (see [WithAuth])
+// in the provided [TokenCache], e.g. [FileTokenCache], so a valid token can be reused after a restart.
+// The key needs to identify the credentials, e.g. the user id of the service user.
+// The credential (e.g. the content of the key.json or the client secret) is hashed into the key,
+// so a token is not reused once the credential changed.
+// Tokens without expiry (e.g. a [PAT]) are not cached and a token rejected by ZITADEL is discarded.
+func WithTokenCache(cache TokenCache, key string, credential []byte) Option {
+	return func(c *clientOptions) {
+		c.tokenCache = cache
+		c.tokenCacheKey = CredentialCacheKey(key, credential)
+	}
+}
+
+// CredentialCacheKey returns the key for a [TokenCache] bound to the credential,
+// which is only stored as SHA-256 hash.
+func CredentialCacheKey(key string, credential []byte) string {
+	sum := sha256.Sum256(credential)
+	return key + ":" + hex.EncodeToString(sum[:])
+}
+
+// NewCachedTokenSource wraps the provided token source and stores every newly issued token in the cache.
+// Tokens are only requested from the underlying source if the cached one is missing or expired.
+// Tokens without expiry are passed through and never stored.
+// The key needs to identify the credentials, see [CredentialCacheKey].
+func NewCachedTokenSource(source oauth2.TokenSource, cache TokenCache, key string) oauth2.TokenSource {
+	return newCachedTokenSource(source, cache, key)
+}
+
+func newCachedTokenSource(source oauth2.TokenSource, cache TokenCache, key string) *cachedTokenSource {
+	return &cachedTokenSource{
+		source: source,
+		cache:  cache,
+		key:    key,
+	}
+}
+
+type cachedTokenSource struct {
+	source oauth2.TokenSource
+	cache  TokenCache
+	key    string
+
+	mu    sync.Mutex
+	token *oauth2.Token
+	// rejected is the access token which was discarded by [cachedTokenSource.invalidate],
+	// it's not stored again if the underlying source still returns it
+	rejected string
+}
+
+// Token implements [oauth2.TokenSource]
+func (s *cachedTokenSource) Token() (*oauth2.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if tokenValid(s.token) {
+		return s.token, nil
+	}
+	if token, err := s.cache.Load(s.key); err == nil && tokenValid(token) {
+		s.token = token
+		return token, nil
+	}
+	token, err := s.source.Token()
+	if err != nil {
+		return nil, err
+	}
+	if !tokenValid(token) || token.AccessToken == s.rejected {
+		return token, nil
+	}
+	s.token = token
+	// failing to persist the token must not fail the call itself,
+	// the token will simply be requested again on the next start.
+	_ = s.cache.Store(s.key, token)
+	return token, nil
+}
+
+// invalidate discards the token, e.g. after it was rejected by ZITADEL, so it's neither used nor stored again.
+func (s *cachedTokenSource) invalidate(accessToken string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token == nil || s.token.AccessToken != accessToken {
+		return
+	}
+	s.rejected = accessToken
+	s.token = nil
+	// the cache has no delete, storing an empty token marks it as invalid
+	_ = s.cache.Store(s.key, new(oauth2.Token))
+}
+
+// unaryInterceptor invalidates the token used for a call which was rejected as unauthenticated.
+func (s *cachedTokenSource) unaryInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		ctx, accessToken := s.pin(ctx)
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		s.rejectedBy(accessToken, err)
+		return err
+	}
+}
+
+// streamInterceptor invalidates the token used for a stream which was rejected as unauthenticated,
+// either when it's created or on receiving a message.
+func (s *cachedTokenSource) streamInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		ctx, accessToken := s.pin(ctx)
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			s.rejectedBy(accessToken, err)
+			return nil, err
+		}
+		return &cachedTokenStream{ClientStream: stream, source: s, accessToken: accessToken}, nil
+	}
+}
+
+// pin sets the token explicitly for the call, so it's known which token was rejected.
+// If the call already uses an explicit token (see [BearerTokenCtx]) or no token could be issued,
+// the context is returned unchanged and the access token is empty.
+func (s *cachedTokenSource) pin(ctx context.Context) (context.Context, string) {
+	if _, ok := ctx.Value(ctxOverwrite).(*oauth2.Token); ok {
+		return ctx, ""
+	}
+	token, err := s.Token()
+	if err != nil {
+		// the credentials of the call will return the error
+		return ctx, ""
+	}
+	return context.WithValue(ctx, ctxOverwrite, token), token.AccessToken
+}
+
+// rejectedBy invalidates the access token if the call failed as unauthenticated.
+// A token issued in the meantime (e.g. by a concurrent call) is kept.
+func (s *cachedTokenSource) rejectedBy(accessToken string, err error) {
+	if accessToken == "" || status.Code(err) != codes.Unauthenticated {
+		return
+	}
+	s.invalidate(accessToken)
+}
+
+// cachedTokenStream invalidates the token of the stream if a message is rejected as unauthenticated.
+type cachedTokenStream struct {
+	grpc.ClientStream
+	source      *cachedTokenSource
+	accessToken string
+}
+
+// RecvMsg implements [grpc.ClientStream]
+func (s *cachedTokenStream) RecvMsg(m any) error {
+	err := s.ClientStream.RecvMsg(m)
+	s.source.rejectedBy(s.accessToken, err)
+	return err
+}
+
+// tokenValid reports whether the token can be cached and reused.
+// Tokens without expiry are not, since it's unknown if they are still valid.
+func tokenValid(token *oauth2.Token) bool {
+	if token == nil || token.AccessToken == "" || token.Expiry.IsZero() {
+		return false
+	}
+	return token.Expiry.Add(-tokenExpiryDelta).After(time.Now())
+}
+
+// MemoryTokenCache implements [TokenCache] by keeping the tokens in-memory.
+// It allows sharing tokens between multiple clients of the same process.
+type MemoryTokenCache struct {
+	mu     sync.RWMutex
+	tokens map[string]*oauth2.Token
+}
+
+func NewMemoryTokenCache() *MemoryTokenCache {
+	return &MemoryTokenCache{
+		tokens: make(map[string]*oauth2.Token),
+	}
+}
+
+func (m *MemoryTokenCache) Load(key string) (*oauth2.Token, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	token, ok := m.tokens[key]
+	if !ok {
+		return nil, ErrTokenNotCached
+	}
+	return token, nil
+}
+
+func (m *MemoryTokenCache) Store(key string, token *oauth2.Token) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tokens[key] = token
+	return nil
+}
+
+// FileTokenCache implements [TokenCache] by storing every token as JSON file in the provided directory.
+// The files are only readable by the current user.
+type FileTokenCache struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewFileTokenCache creates a [FileTokenCache] and ensures the directory exists.
+func NewFileTokenCache(dir string) (*FileTokenCache, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	return &FileTokenCache{dir: dir}, nil
+}
+
+func (f *FileTokenCache) Load(key string) (*oauth2.Token, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	data, err := os.ReadFile(f.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrTokenNotCached
+	}
+	if err != nil {
+		return nil, err
+	}
+	token := new(oauth2.Token)
+	if err = json.Unmarshal(data, token); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+func (f *FileTokenCache) Store(key string, token *oauth2.Token) error {
+	data, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	tmp, err := os.CreateTemp(f.dir, ".token-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), f.path(key))
+}
+
+func (f *FileTokenCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(f.dir, hex.EncodeToString(sum[:])+".json")
+}
@@ -0,0 +1,267 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCachedTokenSource_Token(t *testing.T) {
+	valid := &oauth2.Token{AccessToken: "cached", Expiry: time.Now().Add(time.Hour)}
+	expired := &oauth2.Token{AccessToken: "expired", Expiry: time.Now().Add(-time.Hour)}
+	fresh := &oauth2.Token{AccessToken: "fresh", Expiry: time.Now().Add(time.Hour)}
+	pat := &oauth2.Token{AccessToken: "pat"}
+
+	tests := []struct {
+		name      string
+		cached    *oauth2.Token
+		source    *countingTokenSource
+		want      string
+		wantCalls int
+		wantErr   bool
+	}{
+		{
+			name:      "nothing cached, source used",
+			source:    &countingTokenSource{token: fresh},
+			want:      "fresh",
+			wantCalls: 1,
+		},
+		{
+			name:      "valid token cached, source not used",
+			cached:    valid,
+			source:    &countingTokenSource{token: fresh},
+			want:      "cached",
+			wantCalls: 0,
+		},
+		{
+			name:      "expired token cached, source used",
+			cached:    expired,
+			source:    &countingTokenSource{token: fresh},
+			want:      "fresh",
+			wantCalls: 1,
+		},
+		{
+			name:      "token without expiry cached, source used",
+			cached:    pat,
+			source:    &countingTokenSource{token: fresh},
+			want:      "fresh",
+			wantCalls: 1,
+		},
+		{
+			name:      "token without expiry not cached",
+			source:    &countingTokenSource{token: pat},
+			want:      "pat",
+			wantCalls: 2,
+		},
+		{
+			name:      "source error",
+			source:    &countingTokenSource{err: errors.New("failed")},
+			wantCalls: 1,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := NewMemoryTokenCache()
+			if tt.cached != nil {
+				require.NoError(t, cache.Store("key", tt.cached))
+			}
+			source := NewCachedTokenSource(tt.source, cache, "key")
+			for i := 0; i < 2; i++ {
+				got, err := source.Token()
+				if tt.wantErr {
+					assert.Error(t, err)
+					continue
+				}
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, got.AccessToken)
+			}
+			if !tt.wantErr {
+				assert.Equal(t, tt.wantCalls, tt.source.calls)
+			}
+		})
+	}
+}
+
+func TestCachedTokenSource_invalidate(t *testing.T) {
+	token := &oauth2.Token{AccessToken: "rejected", Expiry: time.Now().Add(time.Hour)}
+	cache := NewMemoryTokenCache()
+	source := newCachedTokenSource(&countingTokenSource{token: token}, cache, "key")
+	_, err := source.Token()
+	require.NoError(t, err)
+
+	err = source.unaryInterceptor()(context.Background(), "/zitadel.auth.v1.AuthService/GetMyUser", nil, nil, nil,
+		func(context.Context, string, any, any, *grpc.ClientConn, ...grpc.CallOption) error {
+			return status.Error(codes.Unauthenticated, "token invalid")
+		})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	cached, err := cache.Load("key")
+	require.NoError(t, err)
+	assert.Empty(t, cached.AccessToken)
+
+	// the underlying source still returns the rejected token, which must not be stored again
+	got, err := source.Token()
+	require.NoError(t, err)
+	assert.Equal(t, "rejected", got.AccessToken)
+	cached, err = cache.Load("key")
+	require.NoError(t, err)
+	assert.Empty(t, cached.AccessToken)
+}
+
+func TestCachedTokenSource_unaryInterceptor(t *testing.T) {
+	tests := []struct {
+		name string
+		ctx  context.Context
+		// refreshed simulates a concurrent call replacing the token while the call is running
+		refreshed   bool
+		wantToken   string
+		wantInvalid bool
+	}{
+		{
+			name:        "used token rejected",
+			ctx:         context.Background(),
+			wantToken:   "token1",
+			wantInvalid: true,
+		},
+		{
+			name:      "token refreshed in the meantime",
+			ctx:       context.Background(),
+			refreshed: true,
+			wantToken: "token1",
+		},
+		{
+			name:      "explicit token rejected",
+			ctx:       BearerTokenCtx(context.Background(), "explicit"),
+			wantToken: "explicit",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := NewMemoryTokenCache()
+			source := newCachedTokenSource(&countingTokenSource{token: &oauth2.Token{AccessToken: "token1", Expiry: time.Now().Add(time.Hour)}}, cache, "key")
+			token2 := &oauth2.Token{AccessToken: "token2", Expiry: time.Now().Add(time.Hour)}
+			_, err := source.Token()
+			require.NoError(t, err)
+
+			err = source.unaryInterceptor()(tt.ctx, "/zitadel.auth.v1.AuthService/GetMyUser", nil, nil, nil,
+				func(ctx context.Context, _ string, _, _ any, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+					token, _ := ctx.Value(ctxOverwrite).(*oauth2.Token)
+					require.NotNil(t, token)
+					assert.Equal(t, tt.wantToken, token.AccessToken, "the call uses the pinned token")
+					if tt.refreshed {
+						source.mu.Lock()
+						source.token = token2
+						source.mu.Unlock()
+						require.NoError(t, cache.Store("key", token2))
+					}
+					return status.Error(codes.Unauthenticated, "token invalid")
+				})
+			assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+			cached, err := cache.Load("key")
+			require.NoError(t, err)
+			if tt.wantInvalid {
+				assert.Empty(t, cached.AccessToken)
+				return
+			}
+			assert.NotEmpty(t, cached.AccessToken, "the current token is kept")
+			got, err := source.Token()
+			require.NoError(t, err)
+			assert.Equal(t, cached.AccessToken, got.AccessToken)
+		})
+	}
+}
+
+func TestCachedTokenSource_streamInterceptor(t *testing.T) {
+	tests := []struct {
+		name      string
+		streamErr error
+		recvErr   error
+	}{
+		{
+			name:      "rejected on creation",
+			streamErr: status.Error(codes.Unauthenticated, "token invalid"),
+		},
+		{
+			name:    "rejected on receive",
+			recvErr: status.Error(codes.Unauthenticated, "token invalid"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := NewMemoryTokenCache()
+			source := newCachedTokenSource(&countingTokenSource{token: &oauth2.Token{AccessToken: "token", Expiry: time.Now().Add(time.Hour)}}, cache, "key")
+
+			stream, err := source.streamInterceptor()(context.Background(), &grpc.StreamDesc{ServerStreams: true}, nil, "/zitadel.auth.v1.AuthService/ListMyUserChanges",
+				func(ctx context.Context, _ *grpc.StreamDesc, _ *grpc.ClientConn, _ string, _ ...grpc.CallOption) (grpc.ClientStream, error) {
+					token, _ := ctx.Value(ctxOverwrite).(*oauth2.Token)
+					require.NotNil(t, token)
+					assert.Equal(t, "token", token.AccessToken, "the stream uses the pinned token")
+					if tt.streamErr != nil {
+						return nil, tt.streamErr
+					}
+					return &failingStream{err: tt.recvErr}, nil
+				})
+			if err == nil {
+				err = stream.RecvMsg(nil)
+			}
+			assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+			cached, err := cache.Load("key")
+			require.NoError(t, err)
+			assert.Empty(t, cached.AccessToken)
+		})
+	}
+}
+
+func TestCredentialCacheKey(t *testing.T) {
+	key := CredentialCacheKey("user", []byte("secret"))
+	assert.Equal(t, key, CredentialCacheKey("user", []byte("secret")))
+	assert.NotEqual(t, key, CredentialCacheKey("user", []byte("rotated")))
+	assert.NotContains(t, key, "secret")
+}
+
+func TestFileTokenCache(t *testing.T) {
+	cache, err := NewFileTokenCache(t.TempDir())
+	require.NoError(t, err)
+
+	_, err = cache.Load("key")
+	assert.ErrorIs(t, err, ErrTokenNotCached)
+
+	token := &oauth2.Token{AccessToken: "token", TokenType: "Bearer", Expiry: time.Now().Add(time.Hour).Round(0)}
+	require.NoError(t, cache.Store("key", token))
+
+	got, err := cache.Load("key")
+	require.NoError(t, err)
+	assert.Equal(t, token.AccessToken, got.AccessToken)
+	assert.True(t, token.Expiry.Equal(got.Expiry))
+}
+
+type countingTokenSource struct {
+	token *oauth2.Token
+	err   error
+	calls int
+}
+
+func (c *countingTokenSource) Token() (*oauth2.Token, error) {
+	c.calls++
+	return c.token, c.err
+}
+
+// failingStream is a [grpc.ClientStream] failing every receive with the error.
+type failingStream struct {
+	grpc.ClientStream
+	err error
+}
+
+func (f *failingStream) RecvMsg(any) error {
+	return f.err
+}
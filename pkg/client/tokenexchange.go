@@ -0,0 +1,215 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/zitadel/oidc/v3/pkg/client"
+	"github.com/zitadel/oidc/v3/pkg/client/tokenexchange"
+	"github.com/zitadel/oidc/v3/pkg/oidc"
+	"golang.org/x/oauth2"
+)
+
+// TokenTypeUserID is the ZITADEL specific token type of a subject passed by its user ID, used for impersonation.
+const TokenTypeUserID oidc.TokenType = "urn:zitadel:params:oauth:token-type:user_id"
+
+var ErrMissingActor = errors.New("impersonation requires an actor token")
+
+// ExchangeClientAuthentication authenticates the application performing the token exchange (RFC 8693) at the token endpoint.
+type ExchangeClientAuthentication func(ctx context.Context, issuer string, httpClient *http.Client) (tokenexchange.TokenExchanger, error)
+
+// ExchangeClientSecret authenticates the token exchange with the client_id and client_secret of the application.
+func ExchangeClientSecret(clientID, clientSecret string) ExchangeClientAuthentication {
+	return func(ctx context.Context, issuer string, httpClient *http.Client) (tokenexchange.TokenExchanger, error) {
+		return tokenexchange.NewTokenExchangerClientCredentials(ctx, issuer, clientID, clientSecret, tokenexchange.WithHTTPClient(httpClient))
+	}
+}
+
+// ExchangeKeyFile authenticates the token exchange with a JWT signed by the key of the application (key.json provided by ZITADEL).
+func ExchangeKeyFile(file *client.KeyFile) ExchangeClientAuthentication {
+	return func(ctx context.Context, issuer string, httpClient *http.Client) (tokenexchange.TokenExchanger, error) {
+		signer, err := client.NewSignerFromPrivateKeyByte([]byte(file.Key), file.KeyID)
+		if err != nil {
+			return nil, err
+		}
+		clientID := file.ClientID
+		if clientID == "" {
+			clientID = file.UserID
+		}
+		return tokenexchange.NewTokenExchangerJWTProfile(ctx, issuer, clientID, signer, tokenexchange.WithHTTPClient(httpClient))
+	}
+}
+
+// TokenExchangeOption allows customization of a token exchange.
+type TokenExchangeOption func(*tokenExchangeConfig)
+
+type tokenExchangeConfig struct {
+	subjectTokenType   oidc.TokenType
+	actor              oauth2.TokenSource
+	actorTokenType     oidc.TokenType
+	audience           []string
+	resource           []string
+	scopes             []string
+	requestedTokenType oidc.TokenType
+}
+
+// WithSubjectTokenType sets the type of the subject token (default access token), e.g. [oidc.IDTokenType] or [oidc.JWTTokenType].
+func WithSubjectTokenType(tokenType oidc.TokenType) TokenExchangeOption {
+	return func(c *tokenExchangeConfig) {
+		c.subjectTokenType = tokenType
+	}
+}
+
+// WithActor passes the (access) token of the actor, e.g. the token of the backend service itself.
+// The issued token represents the subject with the actor acting on its behalf (delegation, `act` claim).
+func WithActor(actor oauth2.TokenSource) TokenExchangeOption {
+	return func(c *tokenExchangeConfig) {
+		c.actor = actor
+	}
+}
+
+// WithActorTokenType sets the type of the actor token (default access token).
+func WithActorTokenType(tokenType oidc.TokenType) TokenExchangeOption {
+	return func(c *tokenExchangeConfig) {
+		c.actorTokenType = tokenType
+	}
+}
+
+// WithExchangeAudience requests the audience of the issued token, e.g. the project ID of the downstream API.
+func WithExchangeAudience(audience ...string) TokenExchangeOption {
+	return func(c *tokenExchangeConfig) {
+		c.audience = audience
+	}
+}
+
+// WithExchangeResource requests the issued token to be used at the resources (URIs).
+func WithExchangeResource(resource ...string) TokenExchangeOption {
+	return func(c *tokenExchangeConfig) {
+		c.resource = resource
+	}
+}
+
+// WithExchangeScopes requests the scopes of the issued token, e.g. [ScopeProjectID] to scope it to a downstream API.
+// Without scopes, the scopes of the subject token are used.
+func WithExchangeScopes(scopes ...string) TokenExchangeOption {
+	return func(c *tokenExchangeConfig) {
+		c.scopes = scopes
+	}
+}
+
+// WithRequestedTokenType sets the type of the issued token (default access token), e.g. [oidc.JWTTokenType].
+func WithRequestedTokenType(tokenType oidc.TokenType) TokenExchangeOption {
+	return func(c *tokenExchangeConfig) {
+		c.requestedTokenType = tokenType
+	}
+}
+
+// TokenExchanger exchanges tokens at ZITADEL using the OAuth 2.0 Token Exchange (RFC 8693),
+// e.g. so a backend service can exchange the token of a user for a token scoped to a downstream API.
+// The feature must be enabled in ZITADEL and the application must be allowed to exchange tokens.
+type TokenExchanger struct {
+	exchanger tokenexchange.TokenExchanger
+}
+
+// NewTokenExchanger discovers the token endpoint of the issuer and creates a [TokenExchanger] authenticated as the application.
+// The passed context is used for the discovery and the [http.Client] set as [oauth2.HTTPClient] (if any).
+func NewTokenExchanger(ctx context.Context, issuer string, auth ExchangeClientAuthentication) (*TokenExchanger, error) {
+	exchanger, err := auth(ctx, issuer, httpClient(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return &TokenExchanger{exchanger: exchanger}, nil
+}
+
+// Exchange exchanges the subject token (default an access token, see [WithSubjectTokenType]).
+func (e *TokenExchanger) Exchange(ctx context.Context, subjectToken string, options ...TokenExchangeOption) (*oauth2.Token, error) {
+	config := &tokenExchangeConfig{
+		subjectTokenType:   oidc.AccessTokenType,
+		actorTokenType:     oidc.AccessTokenType,
+		requestedTokenType: oidc.AccessTokenType,
+	}
+	for _, option := range options {
+		option(config)
+	}
+	var actorToken string
+	var actorTokenType oidc.TokenType
+	if config.actor != nil {
+		actor, err := config.actor.Token()
+		if err != nil {
+			return nil, err
+		}
+		actorToken, actorTokenType = actor.AccessToken, config.actorTokenType
+	}
+	resp, err := tokenexchange.ExchangeToken(ctx, e.exchanger,
+		subjectToken, config.subjectTokenType,
+		actorToken, actorTokenType,
+		config.resource, config.audience, config.scopes,
+		config.requestedTokenType,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return exchangedToken(resp), nil
+}
+
+// Impersonate issues a token of the user with the actor (see [WithActor]) impersonating the user.
+// The actor must be allowed to impersonate users in ZITADEL, e.g. by the IAM_END_USER_IMPERSONATOR role.
+func (e *TokenExchanger) Impersonate(ctx context.Context, userID string, options ...TokenExchangeOption) (*oauth2.Token, error) {
+	config := new(tokenExchangeConfig)
+	for _, option := range options {
+		option(config)
+	}
+	if config.actor == nil {
+		return nil, ErrMissingActor
+	}
+	return e.Exchange(ctx, userID, append(slices.Clip(options), WithSubjectTokenType(TokenTypeUserID))...)
+}
+
+// TokenSource returns an [oauth2.TokenSource] exchanging the subject token whenever the issued token expires,
+// e.g. to call a downstream API on behalf of the user for the duration of a request (see [BearerTokenCtx]).
+func (e *TokenExchanger) TokenSource(ctx context.Context, subjectToken string, options ...TokenExchangeOption) oauth2.TokenSource {
+	return oauth2.ReuseTokenSource(nil, &tokenExchangeTokenSource{ctx: ctx, exchanger: e, subjectToken: subjectToken, options: options})
+}
+
+type tokenExchangeTokenSource struct {
+	ctx          context.Context
+	exchanger    *TokenExchanger
+	subjectToken string
+	options      []TokenExchangeOption
+}
+
+// Token implements [oauth2.TokenSource]
+func (t *tokenExchangeTokenSource) Token() (*oauth2.Token, error) {
+	return t.exchanger.Exchange(t.ctx, t.subjectToken, t.options...)
+}
+
+// TokenExchangeAuthentication allows the client to call ZITADEL with the token issued by the exchange of the subject token,
+// e.g. a backend acting on behalf of a user (delegation, see [WithActor]).
+func TokenExchangeAuthentication(auth ExchangeClientAuthentication, subjectToken string, options ...TokenExchangeOption) TokenSourceInitializer {
+	return func(ctx context.Context, issuer string) (oauth2.TokenSource, error) {
+		exchanger, err := NewTokenExchanger(ctx, issuer, auth)
+		if err != nil {
+			return nil, err
+		}
+		return exchanger.TokenSource(context.WithoutCancel(ctx), subjectToken, options...), nil
+	}
+}
+
+func exchangedToken(resp *oidc.TokenExchangeResponse) *oauth2.Token {
+	token := &oauth2.Token{
+		AccessToken:  resp.AccessToken,
+		TokenType:    resp.TokenType,
+		RefreshToken: resp.RefreshToken,
+	}
+	if resp.ExpiresIn > 0 {
+		token.Expiry = time.Now().Add(time.Duration(resp.ExpiresIn) * time.Second)
+	}
+	return token.WithExtra(map[string]any{
+		"issued_token_type": string(resp.IssuedTokenType),
+		"id_token":          resp.IDToken,
+		"scope":             resp.Scopes.String(),
+	})
+}
@@ -0,0 +1,109 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zitadel/oidc/v3/pkg/oidc"
+	"golang.org/x/oauth2"
+)
+
+type tokenExchangeServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests []url.Values
+}
+
+func newTokenExchangeServer(t *testing.T) *tokenExchangeServer {
+	s := new(tokenExchangeServer)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{"issuer": s.URL, "token_endpoint": s.URL + "/oauth/v2/token"})
+	})
+	mux.HandleFunc("/oauth/v2/token", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		clientID, secret, _ := r.BasicAuth()
+		if clientID != "backend" || secret != "secret" {
+			http.Error(w, `{"error":"invalid_client"}`, http.StatusUnauthorized)
+			return
+		}
+		s.mu.Lock()
+		s.requests = append(s.requests, r.PostForm)
+		n := len(s.requests)
+		s.mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"access_token":      "exchanged-" + string(rune('0'+n)),
+			"issued_token_type": oidc.AccessTokenType,
+			"token_type":        "Bearer",
+			"expires_in":        3600,
+			"scope":             r.PostForm.Get("scope"),
+		})
+	})
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+	return s
+}
+
+func TestTokenExchanger(t *testing.T) {
+	server := newTokenExchangeServer(t)
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, server.Client())
+	exchanger, err := NewTokenExchanger(ctx, server.URL, ExchangeClientSecret("backend", "secret"))
+	require.NoError(t, err)
+	actor := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "backend-token"})
+
+	t.Run("delegation", func(t *testing.T) {
+		token, err := exchanger.Exchange(ctx, "user-token",
+			WithActor(actor),
+			WithExchangeAudience("downstream"),
+			WithExchangeScopes(oidc.ScopeOpenID, ScopeProjectID("downstream")),
+		)
+		require.NoError(t, err)
+		assert.Equal(t, "exchanged-1", token.AccessToken)
+		assert.True(t, token.Valid())
+		assert.Equal(t, string(oidc.AccessTokenType), token.Extra("issued_token_type"))
+		assert.Equal(t, url.Values{
+			"grant_type":           {string(oidc.GrantTypeTokenExchange)},
+			"subject_token":        {"user-token"},
+			"subject_token_type":   {string(oidc.AccessTokenType)},
+			"actor_token":          {"backend-token"},
+			"actor_token_type":     {string(oidc.AccessTokenType)},
+			"audience":             {"downstream"},
+			"scope":                {"openid urn:zitadel:iam:org:project:id:downstream:aud"},
+			"requested_token_type": {string(oidc.AccessTokenType)},
+		}, server.requests[0])
+	})
+	t.Run("impersonation", func(t *testing.T) {
+		_, err := exchanger.Impersonate(ctx, "user1")
+		assert.ErrorIs(t, err, ErrMissingActor)
+
+		_, err = exchanger.Impersonate(ctx, "user1", WithActor(actor))
+		require.NoError(t, err)
+		req := server.requests[len(server.requests)-1]
+		assert.Equal(t, "user1", req.Get("subject_token"))
+		assert.Equal(t, string(TokenTypeUserID), req.Get("subject_token_type"))
+		assert.Equal(t, "backend-token", req.Get("actor_token"))
+	})
+	t.Run("token source", func(t *testing.T) {
+		source := exchanger.TokenSource(ctx, "user-token")
+		first, err := source.Token()
+		require.NoError(t, err)
+		second, err := source.Token()
+		require.NoError(t, err)
+		assert.Equal(t, first.AccessToken, second.AccessToken, "valid token must be reused")
+	})
+
+	t.Run("unauthorized client", func(t *testing.T) {
+		unauthorized, err := NewTokenExchanger(ctx, server.URL, ExchangeClientSecret("backend", "wrong"))
+		require.NoError(t, err)
+		_, err = unauthorized.Exchange(ctx, "user-token")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,159 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// ErrUnknownFields is matched by the [UnknownFields] returned by [StrictUnknownFields].
+var ErrUnknownFields = errors.New("response contains unknown fields")
+
+// UnknownFields describes a response containing fields unknown to the SDK,
+// which indicates that the server is newer than the SDK.
+type UnknownFields struct {
+	Method string
+	// Message is the full name of the response message.
+	Message string
+	// Paths are the paths of the messages containing unknown fields, e.g. `user.human.profile`,
+	// resp. an empty path for the response message itself.
+	Paths []string
+}
+
+// Error implements the error interface, so the [UnknownFields] can be returned by an [UnknownFieldsHandler].
+func (u *UnknownFields) Error() string {
+	paths := make([]string, len(u.Paths))
+	for i, path := range u.Paths {
+		paths[i] = u.Message
+		if path != "" {
+			paths[i] += "." + path
+		}
+	}
+	return fmt.Sprintf("%s: %s: %s (the server is newer than the SDK)", u.Method, ErrUnknownFields, strings.Join(paths, ", "))
+}
+
+// Is matches [ErrUnknownFields].
+func (u *UnknownFields) Is(target error) bool {
+	return target == ErrUnknownFields
+}
+
+// UnknownFieldsHandler is called for every response containing unknown fields,
+// the call fails with the returned error (if any).
+type UnknownFieldsHandler func(ctx context.Context, fields *UnknownFields) error
+
+// WithUnknownFieldsHandler checks the responses for fields unknown to the SDK, which indicate version skew
+// between the SDK and the server, and calls the handler if there are any,
+// e.g. [LogUnknownFields] to warn operators or [StrictUnknownFields] to fail the calls in tests.
+// Messages of streams are checked as they are received.
+//
+// Responses of [TransportREST] are decoded from JSON discarding unknown fields, so they are not checked.
+func WithUnknownFieldsHandler(handler UnknownFieldsHandler) Option {
+	return func(c *clientOptions) {
+		c.unaryInterceptors = append(c.unaryInterceptors, unknownFieldsUnaryInterceptor(handler))
+		c.streamInterceptors = append(c.streamInterceptors, unknownFieldsStreamInterceptor(handler))
+	}
+}
+
+// StrictUnknownFields fails every call whose response contains unknown fields with the [UnknownFields].
+func StrictUnknownFields(_ context.Context, fields *UnknownFields) error {
+	return fields
+}
+
+// LogUnknownFields returns a handler logging a warning for the first response of each method containing
+// unknown fields, the calls succeed.
+func LogUnknownFields(logger *slog.Logger) UnknownFieldsHandler {
+	var logged sync.Map
+	return func(ctx context.Context, fields *UnknownFields) error {
+		if _, loaded := logged.LoadOrStore(fields.Method, true); !loaded {
+			logger.WarnContext(ctx, "response contains fields unknown to the SDK, the server is newer than the SDK",
+				"method", fields.Method, "message", fields.Message, "paths", fields.Paths)
+		}
+		return nil
+	}
+}
+
+func unknownFieldsUnaryInterceptor(handler UnknownFieldsHandler) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if err := invoker(ctx, method, req, reply, cc, opts...); err != nil {
+			return err
+		}
+		return checkUnknownFields(ctx, handler, method, reply)
+	}
+}
+
+func unknownFieldsStreamInterceptor(handler UnknownFieldsHandler) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			return nil, err
+		}
+		return &unknownFieldsStream{ClientStream: stream, handler: handler, method: method}, nil
+	}
+}
+
+type unknownFieldsStream struct {
+	grpc.ClientStream
+	handler UnknownFieldsHandler
+	method  string
+}
+
+func (s *unknownFieldsStream) RecvMsg(m any) error {
+	if err := s.ClientStream.RecvMsg(m); err != nil {
+		return err
+	}
+	return checkUnknownFields(s.Context(), s.handler, s.method, m)
+}
+
+func checkUnknownFields(ctx context.Context, handler UnknownFieldsHandler, method string, reply any) error {
+	msg, ok := reply.(proto.Message)
+	if !ok {
+		return nil
+	}
+	paths := unknownFieldPaths(msg.ProtoReflect(), "", nil)
+	if len(paths) == 0 {
+		return nil
+	}
+	slices.Sort(paths)
+	return handler(ctx, &UnknownFields{
+		Method:  method,
+		Message: string(msg.ProtoReflect().Descriptor().FullName()),
+		Paths:   paths,
+	})
+}
+
+// unknownFieldPaths returns the paths of the message and its nested messages containing unknown fields.
+func unknownFieldPaths(msg protoreflect.Message, path string, paths []string) []string {
+	if len(msg.GetUnknown()) > 0 {
+		paths = append(paths, path)
+	}
+	msg.Range(func(field protoreflect.FieldDescriptor, value protoreflect.Value) bool {
+		fieldPath := string(field.Name())
+		if path != "" {
+			fieldPath = path + "." + fieldPath
+		}
+		switch {
+		case field.IsList() && field.Message() != nil:
+			list := value.List()
+			for i := range list.Len() {
+				paths = unknownFieldPaths(list.Get(i).Message(), fmt.Sprintf("%s[%d]", fieldPath, i), paths)
+			}
+		case field.IsMap() && field.MapValue().Message() != nil:
+			value.Map().Range(func(key protoreflect.MapKey, value protoreflect.Value) bool {
+				paths = unknownFieldPaths(value.Message(), fmt.Sprintf("%s[%v]", fieldPath, key.Interface()), paths)
+				return true
+			})
+		case field.Message() != nil && !field.IsList() && !field.IsMap():
+			paths = unknownFieldPaths(value.Message(), fieldPath, paths)
+		}
+		return true
+	})
+	return paths
+}
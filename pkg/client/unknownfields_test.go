@@ -0,0 +1,63 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/management"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/object"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/project"
+)
+
+// unknownField is the encoding of a field with a number unknown to all messages.
+var unknownField = protowire.AppendVarint(protowire.AppendTag(nil, 9999, protowire.VarintType), 1)
+
+func TestUnknownFieldsInterceptor(t *testing.T) {
+	withUnknown := func() *management.ListProjectsResponse {
+		resp := &management.ListProjectsResponse{Result: []*project.Project{
+			{Id: "1", Details: &object.ObjectDetails{Sequence: 1}},
+			{Id: "2", Details: &object.ObjectDetails{Sequence: 2}},
+		}}
+		resp.ProtoReflect().SetUnknown(unknownField)
+		resp.GetResult()[1].GetDetails().ProtoReflect().SetUnknown(unknownField)
+		return resp
+	}
+	tests := []struct {
+		name      string
+		response  proto.Message
+		wantPaths []string
+	}{
+		{"known fields only", &management.ListProjectsResponse{Result: []*project.Project{{Id: "1"}}}, nil},
+		{"unknown fields", withUnknown(), []string{"", "result[1].details"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got *UnknownFields
+			interceptor := unknownFieldsUnaryInterceptor(func(ctx context.Context, fields *UnknownFields) error {
+				got = fields
+				return StrictUnknownFields(ctx, fields)
+			})
+			reply := new(management.ListProjectsResponse)
+			err := interceptor(context.Background(), "/zitadel.management.v1.ManagementService/ListProjects", nil, reply, nil,
+				func(_ context.Context, _ string, _, reply any, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+					proto.Merge(reply.(proto.Message), tt.response)
+					return nil
+				})
+			if tt.wantPaths == nil {
+				require.NoError(t, err)
+				assert.Nil(t, got)
+				return
+			}
+			require.ErrorIs(t, err, ErrUnknownFields)
+			assert.Equal(t, "zitadel.management.v1.ListProjectsResponse", got.Message)
+			assert.Equal(t, tt.wantPaths, got.Paths)
+			assert.Contains(t, err.Error(), "zitadel.management.v1.ListProjectsResponse.result[1].details")
+		})
+	}
+}
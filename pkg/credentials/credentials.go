@@ -0,0 +1,228 @@
+// Package credentials supplies the credentials of a service user (a PAT or a key.json) from a secret backend,
+// such as HashiCorp Vault ([Vault]) or Kubernetes secrets ([KubernetesSecret]), to the [client.Client]:
+//
+//	provider := credentials.NewVault("https://vault.example.com", "secret", "zitadel/service-user")
+//	c, err := client.New(ctx, zitadel.New("my-instance.zitadel.cloud"),
+//		client.WithAuth(credentials.Authentication(provider, 5*time.Minute, client.ScopeZitadelAPI())),
+//	)
+//
+// The credentials are fetched again periodically and whenever a token cannot be issued,
+// so a rotation of the secret in the backend is picked up without restarting the process.
+package credentials
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/zitadel/oidc/v3/pkg/client"
+	"github.com/zitadel/oidc/v3/pkg/oidc"
+	"golang.org/x/oauth2"
+
+	zitadelClient "github.com/zitadel/zitadel-go/v3/pkg/client"
+)
+
+const (
+	DefaultPATField     = "pat"
+	DefaultKeyFileField = "key.json"
+)
+
+var ErrNoCredentials = errors.New("secret contains neither a PAT nor a key file")
+
+// Credentials of a service user, either a PAT or a key file.
+type Credentials struct {
+	PAT     string
+	KeyFile *client.KeyFile
+	// Version identifies the version of the secret in the backend, it changes when the secret is rotated.
+	Version string
+}
+
+// Provider fetches the current credentials from a secret backend.
+type Provider interface {
+	Credentials(ctx context.Context) (*Credentials, error)
+}
+
+// ProviderFunc allows to use a function as [Provider].
+type ProviderFunc func(ctx context.Context) (*Credentials, error)
+
+// Credentials implements [Provider].
+func (f ProviderFunc) Credentials(ctx context.Context) (*Credentials, error) {
+	return f(ctx)
+}
+
+// Option allows customization of the providers.
+type Option func(*options)
+
+type options struct {
+	patField     string
+	keyFileField string
+	httpClient   *http.Client
+	token        string
+	apiServer    string
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{
+		patField:     DefaultPATField,
+		keyFileField: DefaultKeyFileField,
+		httpClient:   http.DefaultClient,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithPATField sets the field of the secret containing a PAT, [DefaultPATField] by default.
+func WithPATField(field string) Option {
+	return func(o *options) {
+		o.patField = field
+	}
+}
+
+// WithKeyFileField sets the field of the secret containing a key.json, [DefaultKeyFileField] by default.
+func WithKeyFileField(field string) Option {
+	return func(o *options) {
+		o.keyFileField = field
+	}
+}
+
+// WithHTTPClient sets the client for the requests to the backend, [http.DefaultClient] by default.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(o *options) {
+		o.httpClient = httpClient
+	}
+}
+
+// WithToken sets the token authenticating the requests to the backend.
+// By default, [Vault] uses the environment variable VAULT_TOKEN
+// and [KubernetesSecret] the token of the service account of the pod.
+func WithToken(token string) Option {
+	return func(o *options) {
+		o.token = token
+	}
+}
+
+// WithAPIServer sets the URL of the Kubernetes API for the [KubernetesSecret], e.g. if not running in a cluster.
+// By default, the in-cluster configuration of the pod is used.
+func WithAPIServer(apiServer string) Option {
+	return func(o *options) {
+		o.apiServer = apiServer
+	}
+}
+
+// credentialsFromData returns the credentials of the configured fields, a PAT is preferred over a key file.
+func (o *options) credentialsFromData(data map[string][]byte, version string) (*Credentials, error) {
+	if pat := data[o.patField]; len(pat) > 0 {
+		return &Credentials{PAT: string(pat), Version: version}, nil
+	}
+	if key := data[o.keyFileField]; len(key) > 0 {
+		file, err := client.ConfigFromKeyFileData(key)
+		if err != nil {
+			return nil, err
+		}
+		return &Credentials{KeyFile: file, Version: version}, nil
+	}
+	return nil, ErrNoCredentials
+}
+
+// Authentication issues the tokens using the credentials of the provider,
+// a PAT is used as is, a key file with the JWT Profile Grant (see [zitadelClient.JWTAuthentication]).
+// The credentials are fetched again after the refresh interval or if a token cannot be issued.
+// If they changed, the following tokens are issued with the new credentials.
+func Authentication(provider Provider, refresh time.Duration, scopes ...string) zitadelClient.TokenSourceInitializer {
+	return func(ctx context.Context, issuer string) (oauth2.TokenSource, error) {
+		s := &tokenSource{
+			ctx:      context.WithoutCancel(ctx),
+			issuer:   issuer,
+			provider: provider,
+			refresh:  refresh,
+			scopes:   scopes,
+			now:      time.Now,
+		}
+		if err := s.renew(); err != nil {
+			return nil, err
+		}
+		return s, nil
+	}
+}
+
+type tokenSource struct {
+	ctx      context.Context
+	issuer   string
+	provider Provider
+	refresh  time.Duration
+	scopes   []string
+	now      func() time.Time
+
+	mu          sync.Mutex
+	credentials *Credentials
+	source      oauth2.TokenSource
+	fetched     time.Time
+}
+
+// Token implements [oauth2.TokenSource]
+func (s *tokenSource) Token() (*oauth2.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.refresh > 0 && s.now().Sub(s.fetched) >= s.refresh {
+		// the current credentials are kept if the backend is unavailable
+		_ = s.renew()
+	}
+	token, err := s.source.Token()
+	if err == nil {
+		return token, nil
+	}
+	// the credentials might have been rotated and the current ones removed
+	if renewErr := s.renew(); renewErr != nil {
+		return nil, errors.Join(err, renewErr)
+	}
+	return s.source.Token()
+}
+
+// renew fetches the credentials and replaces the token source if they changed.
+func (s *tokenSource) renew() error {
+	credentials, err := s.provider.Credentials(s.ctx)
+	if err != nil {
+		return err
+	}
+	s.fetched = s.now()
+	if s.source != nil && equal(s.credentials, credentials) {
+		return nil
+	}
+	source, err := s.newSource(credentials)
+	if err != nil {
+		return err
+	}
+	s.credentials = credentials
+	s.source = source
+	return nil
+}
+
+func (s *tokenSource) newSource(credentials *Credentials) (oauth2.TokenSource, error) {
+	if credentials.PAT != "" {
+		return oauth2.StaticTokenSource(&oauth2.Token{AccessToken: credentials.PAT, TokenType: oidc.BearerToken}), nil
+	}
+	if credentials.KeyFile != nil {
+		return zitadelClient.JWTAuthentication(credentials.KeyFile, s.scopes...)(s.ctx, s.issuer)
+	}
+	return nil, ErrNoCredentials
+}
+
+func equal(a, b *Credentials) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if a.Version != "" || b.Version != "" {
+		return a.Version == b.Version
+	}
+	if a.PAT != b.PAT {
+		return false
+	}
+	keyA, _ := json.Marshal(a.KeyFile)
+	keyB, _ := json.Marshal(b.KeyFile)
+	return string(keyA) == string(keyB)
+}
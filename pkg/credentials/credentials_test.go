@@ -0,0 +1,75 @@
+package credentials
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVault_Credentials(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/secret/data/zitadel/service-user", r.URL.Path)
+		assert.Equal(t, "vault-token", r.Header.Get("X-Vault-Token"))
+		_, _ = w.Write([]byte(`{"data":{"data":{"key.json":{"type":"serviceaccount","keyId":"key","userId":"user"}},"metadata":{"version":3}}}`))
+	}))
+	defer server.Close()
+
+	credentials, err := NewVault(server.URL, "secret", "/zitadel/service-user", WithToken("vault-token")).Credentials(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "3", credentials.Version)
+	if assert.NotNil(t, credentials.KeyFile) {
+		assert.Equal(t, "key", credentials.KeyFile.KeyID)
+	}
+}
+
+func TestKubernetesSecret_Credentials(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/namespaces/apps/secrets/zitadel", r.URL.Path)
+		assert.Equal(t, "Bearer k8s-token", r.Header.Get("Authorization"))
+		// "bXktcGF0" is "my-pat"
+		_, _ = w.Write([]byte(`{"metadata":{"resourceVersion":"42"},"data":{"token":"bXktcGF0"}}`))
+	}))
+	defer server.Close()
+
+	provider, err := NewKubernetesSecret("apps", "zitadel", WithAPIServer(server.URL), WithToken("k8s-token"), WithPATField("token"))
+	require.NoError(t, err)
+	credentials, err := provider.Credentials(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, &Credentials{PAT: "my-pat", Version: "42"}, credentials)
+
+	provider, err = NewKubernetesSecret("apps", "zitadel", WithAPIServer(server.URL), WithToken("k8s-token"))
+	require.NoError(t, err)
+	_, err = provider.Credentials(context.Background())
+	assert.ErrorIs(t, err, ErrNoCredentials)
+}
+
+func TestAuthentication_renewal(t *testing.T) {
+	current := &Credentials{PAT: "pat1", Version: "1"}
+	provider := ProviderFunc(func(context.Context) (*Credentials, error) {
+		c := *current
+		return &c, nil
+	})
+	source, err := Authentication(provider, time.Minute)(context.Background(), "https://issuer")
+	require.NoError(t, err)
+	now := time.Now()
+	source.(*tokenSource).now = func() time.Time { return now }
+
+	token, err := source.Token()
+	require.NoError(t, err)
+	assert.Equal(t, "pat1", token.AccessToken)
+
+	current = &Credentials{PAT: "pat2", Version: "2"}
+	token, err = source.Token()
+	require.NoError(t, err)
+	assert.Equal(t, "pat1", token.AccessToken, "not refreshed before the interval")
+
+	now = now.Add(time.Minute)
+	token, err = source.Token()
+	require.NoError(t, err)
+	assert.Equal(t, "pat2", token.AccessToken)
+}
@@ -0,0 +1,127 @@
+package credentials
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// serviceAccountDir contains the token, CA and namespace of the service account mounted into a pod.
+const serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+
+var ErrNotInCluster = errors.New("kubernetes: not running in a cluster, KUBERNETES_SERVICE_HOST is not set")
+
+// KubernetesSecret reads the credentials from a secret using the Kubernetes API,
+// so a rotation is picked up without waiting for the kubelet to update a mounted secret.
+// The service account of the pod needs the permission to get the secret.
+type KubernetesSecret struct {
+	apiServer string
+	namespace string
+	name      string
+	options   *options
+	// tokenFile is read for every request, since the projected service account tokens are rotated
+	tokenFile string
+}
+
+// NewKubernetesSecret creates the [KubernetesSecret] provider reading the secret with the name of the namespace.
+// If the namespace is empty, the namespace of the pod is used.
+// The secret must contain a PAT or a key.json, see [WithPATField] and [WithKeyFileField].
+func NewKubernetesSecret(namespace, name string, opts ...Option) (*KubernetesSecret, error) {
+	o := newOptions(opts)
+	k := &KubernetesSecret{
+		apiServer: strings.TrimSuffix(o.apiServer, "/"),
+		namespace: namespace,
+		name:      name,
+		options:   o,
+	}
+	if k.apiServer != "" {
+		return k, nil
+	}
+	return k, k.inCluster()
+}
+
+// inCluster configures the provider using the service account of the pod.
+func (k *KubernetesSecret) inCluster() error {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" {
+		return ErrNotInCluster
+	}
+	k.apiServer = "https://" + net.JoinHostPort(host, port)
+	if k.namespace == "" {
+		namespace, err := os.ReadFile(serviceAccountDir + "/namespace")
+		if err != nil {
+			return err
+		}
+		k.namespace = strings.TrimSpace(string(namespace))
+	}
+	if k.options.token == "" {
+		k.tokenFile = serviceAccountDir + "/token"
+	}
+	ca, err := os.ReadFile(serviceAccountDir + "/ca.crt")
+	if err != nil {
+		return err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return errors.New("kubernetes: invalid CA of the service account")
+	}
+	if k.options.httpClient == http.DefaultClient {
+		k.options.httpClient = &http.Client{
+			Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}},
+		}
+	}
+	return nil
+}
+
+type kubernetesSecret struct {
+	Metadata struct {
+		ResourceVersion string `json:"resourceVersion"`
+	} `json:"metadata"`
+	// Data is base64 encoded by the API, which is decoded by encoding/json for []byte
+	Data map[string][]byte `json:"data"`
+}
+
+// Credentials implements [Provider].
+func (k *KubernetesSecret) Credentials(ctx context.Context) (*Credentials, error) {
+	endpoint, err := url.JoinPath(k.apiServer, "api/v1/namespaces", k.namespace, "secrets", k.name)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	token := k.options.token
+	if k.tokenFile != "" {
+		data, err := os.ReadFile(k.tokenFile)
+		if err != nil {
+			return nil, err
+		}
+		token = strings.TrimSpace(string(data))
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := k.options.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("kubernetes: reading secret %s/%s failed with status %d", k.namespace, k.name, resp.StatusCode)
+	}
+	secret := new(kubernetesSecret)
+	if err = json.NewDecoder(resp.Body).Decode(secret); err != nil {
+		return nil, err
+	}
+	return k.options.credentialsFromData(secret.Data, secret.Metadata.ResourceVersion)
+}
@@ -0,0 +1,80 @@
+package credentials
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Vault reads the credentials from a secret of the KV (version 2) secrets engine of HashiCorp Vault.
+type Vault struct {
+	address string
+	mount   string
+	path    string
+	options *options
+}
+
+// NewVault creates the [Vault] provider reading the secret at the path of the KV engine mounted at mount,
+// e.g. NewVault("https://vault.example.com", "secret", "zitadel/service-user").
+// The secret must contain a PAT or a key.json (as string or JSON object), see [WithPATField] and [WithKeyFileField].
+func NewVault(address, mount, path string, opts ...Option) *Vault {
+	o := newOptions(opts)
+	if o.token == "" {
+		o.token = os.Getenv("VAULT_TOKEN")
+	}
+	return &Vault{
+		address: strings.TrimSuffix(address, "/"),
+		mount:   strings.Trim(mount, "/"),
+		path:    strings.Trim(path, "/"),
+		options: o,
+	}
+}
+
+type vaultResponse struct {
+	Data struct {
+		Data     map[string]json.RawMessage `json:"data"`
+		Metadata struct {
+			Version int `json:"version"`
+		} `json:"metadata"`
+	} `json:"data"`
+}
+
+// Credentials implements [Provider].
+func (v *Vault) Credentials(ctx context.Context) (*Credentials, error) {
+	endpoint, err := url.JoinPath(v.address, "v1", v.mount, "data", v.path)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", v.options.token)
+	resp, err := v.options.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("vault: reading %s/%s failed with status %d", v.mount, v.path, resp.StatusCode)
+	}
+	secret := new(vaultResponse)
+	if err = json.NewDecoder(resp.Body).Decode(secret); err != nil {
+		return nil, err
+	}
+	data := make(map[string][]byte, len(secret.Data.Data))
+	for field, value := range secret.Data.Data {
+		var s string
+		if json.Unmarshal(value, &s) == nil {
+			data[field] = []byte(s)
+			continue
+		}
+		data[field] = value
+	}
+	return v.options.credentialsFromData(data, strconv.Itoa(secret.Data.Metadata.Version))
+}
@@ -0,0 +1,132 @@
+// Package credhelper implements a credential helper protocol (similar to the docker credential helpers),
+// which allows tools to obtain ZITADEL access tokens by executing a helper binary
+// instead of handling the credentials (e.g. key files) themselves.
+//
+// The tool executes the helper with the action as only argument and the [Request] as JSON on stdin:
+//
+//	echo '{"issuer":"https://my-instance.zitadel.cloud","scopes":["openid"]}' | zitadel-credential-helper get
+//
+// On success the helper writes the [Response] as JSON to stdout and exits with status 0.
+// On failure it writes the error message to stderr and exits with a non-zero status.
+// The helper caches the tokens (e.g. in the OS keyring), so they're shared across all tools of a toolchain.
+// Use [Authentication] to obtain the tokens of a helper with the [client.Client].
+package credhelper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client"
+)
+
+// Action is the first argument of the helper.
+type Action string
+
+const (
+	// ActionGet returns a valid token, issuing a new one if there is no cached one.
+	ActionGet Action = "get"
+	// ActionErase removes the cached token, so the next [ActionGet] issues a new one.
+	ActionErase Action = "erase"
+)
+
+var ErrHelperFailed = errors.New("credential helper failed")
+
+// Request is passed as JSON on stdin to the helper.
+type Request struct {
+	// Issuer is the origin of the ZITADEL instance, e.g. `https://my-instance.zitadel.cloud`.
+	Issuer string   `json:"issuer"`
+	Scopes []string `json:"scopes,omitempty"`
+}
+
+// Response is written as JSON to stdout by the helper on [ActionGet].
+type Response struct {
+	AccessToken string    `json:"access_token"`
+	TokenType   string    `json:"token_type"`
+	Expiry      time.Time `json:"expiry,omitempty"`
+}
+
+// Token returns the [Response] as [oauth2.Token].
+func (r *Response) Token() *oauth2.Token {
+	return &oauth2.Token{
+		AccessToken: r.AccessToken,
+		TokenType:   r.TokenType,
+		Expiry:      r.Expiry,
+	}
+}
+
+// Get executes the helper command with the [ActionGet] and returns the token.
+func Get(ctx context.Context, command string, req *Request) (*oauth2.Token, error) {
+	out, err := run(ctx, command, ActionGet, req)
+	if err != nil {
+		return nil, err
+	}
+	resp := new(Response)
+	if err = json.Unmarshal(out, resp); err != nil {
+		return nil, fmt.Errorf("%w: invalid response: %v", ErrHelperFailed, err)
+	}
+	if resp.AccessToken == "" {
+		return nil, fmt.Errorf("%w: empty access token", ErrHelperFailed)
+	}
+	return resp.Token(), nil
+}
+
+// Erase executes the helper command with the [ActionErase], so the cached token is discarded.
+func Erase(ctx context.Context, command string, req *Request) error {
+	_, err := run(ctx, command, ActionErase, req)
+	return err
+}
+
+func run(ctx context.Context, command string, action Action, req *Request) ([]byte, error) {
+	input, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, command, string(action))
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err = cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%w: %s", ErrHelperFailed, msg)
+		}
+		return nil, fmt.Errorf("%w: %v", ErrHelperFailed, err)
+	}
+	return stdout.Bytes(), nil
+}
+
+// Authentication obtains the tokens for the [client.Client] by executing the helper command, e.g.:
+//
+//	client.New(ctx, zitadel.New("my-instance.zitadel.cloud"),
+//		client.WithAuth(credhelper.Authentication("zitadel-credential-helper", client.ScopeZitadelAPI())),
+//	)
+//
+// The helper is only executed again once the token expired.
+func Authentication(command string, scopes ...string) client.TokenSourceInitializer {
+	return func(ctx context.Context, issuer string) (oauth2.TokenSource, error) {
+		return oauth2.ReuseTokenSource(nil, &tokenSource{
+			ctx:     ctx,
+			command: command,
+			req:     &Request{Issuer: issuer, Scopes: scopes},
+		}), nil
+	}
+}
+
+type tokenSource struct {
+	ctx     context.Context
+	command string
+	req     *Request
+}
+
+// Token implements [oauth2.TokenSource]
+func (s *tokenSource) Token() (*oauth2.Token, error) {
+	return Get(s.ctx, s.command, s.req)
+}
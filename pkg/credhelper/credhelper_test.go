@@ -0,0 +1,106 @@
+package credhelper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client"
+)
+
+// TestMain allows the test binary to act as credential helper for the tests of [Get].
+func TestMain(m *testing.M) {
+	if os.Getenv("CREDHELPER_TEST_HELPER") == "1" {
+		helper := NewHelper(testAuthenticator(new(int)), "user", "https://zitadel.cloud")
+		if err := helper.Run(context.Background(), os.Args[1:], os.Stdin, os.Stdout); err != nil {
+			os.Stderr.WriteString(err.Error())
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+func testAuthenticator(issued *int) Authenticator {
+	return func(scopes ...string) client.TokenSourceInitializer {
+		return func(_ context.Context, issuer string) (oauth2.TokenSource, error) {
+			if issuer != "https://zitadel.cloud" {
+				return nil, errors.New("credentials sent to other issuer")
+			}
+			*issued++
+			return oauth2.StaticTokenSource(&oauth2.Token{
+				AccessToken: strings.Join(scopes, ","),
+				TokenType:   "Bearer",
+				Expiry:      time.Now().Add(time.Hour),
+			}), nil
+		}
+	}
+}
+
+func TestHelper_Run(t *testing.T) {
+	var issued int
+	helper := NewHelper(testAuthenticator(&issued), "user", "https://zitadel.cloud/", WithCache(client.NewMemoryTokenCache()))
+	run := func(action, request string) (*Response, error) {
+		var stdout bytes.Buffer
+		if err := helper.Run(context.Background(), []string{action}, strings.NewReader(request), &stdout); err != nil {
+			return nil, err
+		}
+		resp := new(Response)
+		if stdout.Len() > 0 {
+			require.NoError(t, json.Unmarshal(stdout.Bytes(), resp))
+		}
+		return resp, nil
+	}
+
+	resp, err := run("get", `{"issuer":"https://zitadel.cloud","scopes":["b","a"]}`)
+	require.NoError(t, err)
+	assert.Equal(t, "b,a", resp.AccessToken)
+	assert.Equal(t, "Bearer", resp.TokenType)
+
+	// same scopes in different order are served from the cache
+	_, err = run("get", `{"issuer":"https://zitadel.cloud/","scopes":["a","b"]}`)
+	require.NoError(t, err)
+	assert.Equal(t, 1, issued)
+
+	_, err = run("erase", `{"issuer":"https://zitadel.cloud","scopes":["a","b"]}`)
+	require.NoError(t, err)
+	_, err = run("get", `{"issuer":"https://zitadel.cloud","scopes":["a","b"]}`)
+	require.NoError(t, err)
+	assert.Equal(t, 2, issued)
+
+	_, err = run("store", `{"issuer":"https://zitadel.cloud"}`)
+	assert.ErrorIs(t, err, ErrUnknownAction)
+	_, err = run("get", `{}`)
+	assert.ErrorIs(t, err, ErrMissingIssuer)
+
+	// the credentials are never sent to another issuer
+	for _, action := range []string{"get", "erase"} {
+		_, err = run(action, `{"issuer":"https://attacker.example.com","scopes":["a","b"]}`)
+		assert.ErrorIs(t, err, ErrOtherIssuer)
+	}
+	assert.Equal(t, 2, issued)
+}
+
+func TestGet(t *testing.T) {
+	t.Setenv("CREDHELPER_TEST_HELPER", "1")
+	command, err := os.Executable()
+	require.NoError(t, err)
+
+	token, err := Get(context.Background(), command, &Request{Issuer: "https://zitadel.cloud", Scopes: []string{"openid"}})
+	require.NoError(t, err)
+	assert.Equal(t, "openid", token.AccessToken)
+	assert.True(t, token.Valid())
+
+	_, err = Get(context.Background(), command, &Request{Issuer: "https://attacker.example.com"})
+	assert.ErrorIs(t, err, ErrHelperFailed)
+	assert.ErrorContains(t, err, ErrOtherIssuer.Error())
+}
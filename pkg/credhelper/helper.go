@@ -0,0 +1,153 @@
+package credhelper
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"golang.org/x/oauth2"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client"
+)
+
+var (
+	ErrUnknownAction = errors.New("unknown action, must be `get` or `erase`")
+	ErrMissingIssuer = errors.New("issuer is required")
+	ErrMissingAction = errors.New("action is required")
+	ErrOtherIssuer   = errors.New("issuer is not the one of the helper")
+)
+
+// Authenticator creates the authentication of the credentials of the helper for the requested scopes,
+// e.g. [client.JWTAuthentication] with a key file.
+type Authenticator func(scopes ...string) client.TokenSourceInitializer
+
+// Helper implements the helper side of the protocol, see [Helper.Run].
+type Helper struct {
+	authenticator Authenticator
+	identity      string
+	issuer        string
+	cache         client.TokenCache
+}
+
+// HelperOption allows customization of the [Helper].
+type HelperOption func(*Helper)
+
+// WithCache stores the tokens in the cache, e.g. the TokenCache of the keyring package or a [client.FileTokenCache].
+// Without cache, every call issues a new token.
+func WithCache(cache client.TokenCache) HelperOption {
+	return func(h *Helper) {
+		h.cache = cache
+	}
+}
+
+// NewHelper creates the [Helper] issuing tokens of the issuer using the authenticator.
+// The identity identifies the credentials in the cache, e.g. the user ID of the service user.
+// Requests for any other issuer are rejected with [ErrOtherIssuer], so callers can't make the helper
+// send the credentials (e.g. a client secret or a signed assertion) to an issuer of their choice.
+func NewHelper(authenticator Authenticator, identity, issuer string, opts ...HelperOption) *Helper {
+	h := &Helper{
+		authenticator: authenticator,
+		identity:      identity,
+		issuer:        strings.TrimSuffix(issuer, "/"),
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// Run handles the action of the args (usually os.Args[1:]) with the [Request] read from stdin
+// and writes the [Response] to stdout.
+func (h *Helper) Run(ctx context.Context, args []string, stdin io.Reader, stdout io.Writer) error {
+	if len(args) == 0 {
+		return ErrMissingAction
+	}
+	req := new(Request)
+	if err := json.NewDecoder(stdin).Decode(req); err != nil {
+		return fmt.Errorf("invalid request: %w", err)
+	}
+	if err := h.checkIssuer(req); err != nil {
+		return err
+	}
+	switch Action(args[0]) {
+	case ActionGet:
+		token, err := h.Token(ctx, req)
+		if err != nil {
+			return err
+		}
+		return json.NewEncoder(stdout).Encode(&Response{
+			AccessToken: token.AccessToken,
+			TokenType:   token.Type(),
+			Expiry:      token.Expiry,
+		})
+	case ActionErase:
+		return h.Erase(req)
+	default:
+		return fmt.Errorf("%w: %q", ErrUnknownAction, args[0])
+	}
+}
+
+// Token returns the cached token for the request if it's still valid, otherwise a new one is issued and cached.
+func (h *Helper) Token(ctx context.Context, req *Request) (*oauth2.Token, error) {
+	if err := h.checkIssuer(req); err != nil {
+		return nil, err
+	}
+	key := h.cacheKey(req)
+	if h.cache != nil {
+		if token, err := h.cache.Load(key); err == nil && token.Valid() {
+			return token, nil
+		}
+	}
+	source, err := h.authenticator(req.Scopes...)(ctx, req.Issuer)
+	if err != nil {
+		return nil, err
+	}
+	token, err := source.Token()
+	if err != nil {
+		return nil, err
+	}
+	if h.cache != nil {
+		if err = h.cache.Store(key, token); err != nil {
+			return nil, err
+		}
+	}
+	return token, nil
+}
+
+// Erase discards the cached token of the request.
+func (h *Helper) Erase(req *Request) error {
+	if err := h.checkIssuer(req); err != nil {
+		return err
+	}
+	if h.cache == nil {
+		return nil
+	}
+	key := h.cacheKey(req)
+	if deleter, ok := h.cache.(interface{ Delete(string) error }); ok {
+		return deleter.Delete(key)
+	}
+	// an empty token is never valid and will therefore be replaced on the next call
+	return h.cache.Store(key, new(oauth2.Token))
+}
+
+// checkIssuer requires the issuer of the request to be the one of the helper.
+func (h *Helper) checkIssuer(req *Request) error {
+	if req.Issuer == "" {
+		return ErrMissingIssuer
+	}
+	if strings.TrimSuffix(req.Issuer, "/") != h.issuer {
+		return fmt.Errorf("%w: %q", ErrOtherIssuer, req.Issuer)
+	}
+	return nil
+}
+
+// cacheKey identifies the token by the credentials, issuer and (sorted) scopes.
+func (h *Helper) cacheKey(req *Request) string {
+	scopes := slices.Clone(req.Scopes)
+	slices.Sort(scopes)
+	return strings.Join([]string{h.identity, strings.TrimSuffix(req.Issuer, "/"), strings.Join(scopes, " ")}, "|")
+}
@@ -0,0 +1,72 @@
+// Package keyring provides a [client.TokenCache] storing the tokens in the keyring of the OS,
+// e.g. for the credential helper of the credhelper package.
+// It's a separate module, so only applications using it depend on github.com/zalando/go-keyring.
+package keyring
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+
+	gokeyring "github.com/zalando/go-keyring"
+	"golang.org/x/oauth2"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client"
+)
+
+// DefaultService is the service name of the tokens stored in the OS keyring.
+const DefaultService = "zitadel-credential-helper"
+
+// TokenCache implements [client.TokenCache] by storing the tokens in the keyring of the OS
+// (macOS Keychain, Windows Credential Manager or the Secret Service (e.g. GNOME Keyring) on Linux).
+type TokenCache struct {
+	service string
+}
+
+// NewTokenCache creates a [TokenCache] storing the tokens under the service name
+// ([DefaultService] if empty).
+func NewTokenCache(service string) *TokenCache {
+	if service == "" {
+		service = DefaultService
+	}
+	return &TokenCache{service: service}
+}
+
+func (k *TokenCache) Load(key string) (*oauth2.Token, error) {
+	data, err := gokeyring.Get(k.service, k.user(key))
+	if errors.Is(err, gokeyring.ErrNotFound) {
+		return nil, client.ErrTokenNotCached
+	}
+	if err != nil {
+		return nil, err
+	}
+	token := new(oauth2.Token)
+	if err = json.Unmarshal([]byte(data), token); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+func (k *TokenCache) Store(key string, token *oauth2.Token) error {
+	data, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+	return gokeyring.Set(k.service, k.user(key), string(data))
+}
+
+// Delete removes the token of the key from the keyring.
+func (k *TokenCache) Delete(key string) error {
+	err := gokeyring.Delete(k.service, k.user(key))
+	if errors.Is(err, gokeyring.ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+// user hashes the key, since it might contain characters or exceed lengths not supported by all keyrings.
+func (k *TokenCache) user(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
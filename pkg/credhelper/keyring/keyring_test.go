@@ -0,0 +1,30 @@
+package keyring
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gokeyring "github.com/zalando/go-keyring"
+	"golang.org/x/oauth2"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client"
+)
+
+func TestTokenCache(t *testing.T) {
+	gokeyring.MockInit()
+	cache := NewTokenCache("")
+
+	_, err := cache.Load("https://zitadel.cloud|client")
+	assert.ErrorIs(t, err, client.ErrTokenNotCached)
+
+	require.NoError(t, cache.Store("https://zitadel.cloud|client", &oauth2.Token{AccessToken: "token", TokenType: "Bearer"}))
+	token, err := cache.Load("https://zitadel.cloud|client")
+	require.NoError(t, err)
+	assert.Equal(t, "token", token.AccessToken)
+
+	require.NoError(t, cache.Delete("https://zitadel.cloud|client"))
+	require.NoError(t, cache.Delete("https://zitadel.cloud|client"))
+	_, err = cache.Load("https://zitadel.cloud|client")
+	assert.ErrorIs(t, err, client.ErrTokenNotCached)
+}
@@ -0,0 +1,188 @@
+// Package dpop implements sender-constrained access tokens using
+// OAuth 2.0 Demonstrating Proof of Possession (DPoP, RFC 9449).
+//
+// A [Prover] creates the proofs for outgoing requests (see [Transport] and client.WithDPoP),
+// [Verify] and [VerifyRequest] validate the proofs of incoming requests.
+package dpop
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+)
+
+const (
+	// HeaderName is the header carrying the DPoP proof.
+	HeaderName = "DPoP"
+	// NonceHeaderName is the header used by servers to provide a nonce to be included in subsequent proofs.
+	NonceHeaderName = "DPoP-Nonce"
+	// TokenType is the authorization scheme and token type of DPoP bound access tokens.
+	TokenType = "DPoP"
+
+	proofType = "dpop+jwt"
+)
+
+var ErrUnsupportedKey = errors.New("unsupported key type for DPoP, must be ECDSA, RSA or Ed25519")
+
+// Prover creates DPoP proofs signed by its private key.
+// It keeps track of the nonces provided by the servers and is safe for concurrent use.
+type Prover struct {
+	signer     jose.Signer
+	thumbprint string
+
+	mu     sync.RWMutex
+	nonces map[string]string
+}
+
+// NewProver creates a [Prover] for the private key.
+// Supported are ECDSA (ES256, ES384, ES512), RSA (RS256) and Ed25519 (EdDSA) keys.
+func NewProver(key crypto.Signer) (*Prover, error) {
+	algorithm, err := signatureAlgorithm(key)
+	if err != nil {
+		return nil, err
+	}
+	signer, err := jose.NewSigner(
+		jose.SigningKey{Algorithm: algorithm, Key: key},
+		(&jose.SignerOptions{EmbedJWK: true}).WithType(proofType),
+	)
+	if err != nil {
+		return nil, err
+	}
+	publicKey := jose.JSONWebKey{Key: key.Public()}
+	thumbprint, err := publicKey.Thumbprint(crypto.SHA256)
+	if err != nil {
+		return nil, err
+	}
+	return &Prover{
+		signer:     signer,
+		thumbprint: base64.RawURLEncoding.EncodeToString(thumbprint),
+		nonces:     make(map[string]string),
+	}, nil
+}
+
+// GenerateProver creates a [Prover] with a new ECDSA P-256 key.
+func GenerateProver() (*Prover, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return NewProver(key)
+}
+
+func signatureAlgorithm(key crypto.Signer) (jose.SignatureAlgorithm, error) {
+	switch k := key.Public().(type) {
+	case *ecdsa.PublicKey:
+		switch k.Curve {
+		case elliptic.P256():
+			return jose.ES256, nil
+		case elliptic.P384():
+			return jose.ES384, nil
+		case elliptic.P521():
+			return jose.ES512, nil
+		}
+	case *rsa.PublicKey:
+		return jose.RS256, nil
+	case ed25519.PublicKey:
+		return jose.EdDSA, nil
+	}
+	return "", ErrUnsupportedKey
+}
+
+// Thumbprint returns the JWK SHA-256 thumbprint of the public key,
+// which is the `jkt` confirmation claim of access tokens bound to the [Prover].
+func (p *Prover) Thumbprint() string {
+	return p.thumbprint
+}
+
+// Proof creates a proof for a request with the HTTP method to the uri.
+// If the proof is sent along a DPoP bound access token, the token must be provided as well.
+// A nonce previously provided by the server (see [Prover.SetNonce]) is included automatically.
+func (p *Prover) Proof(method, uri, accessToken string) (string, error) {
+	htu, err := targetURI(uri)
+	if err != nil {
+		return "", err
+	}
+	jti := make([]byte, 16)
+	if _, err = rand.Read(jti); err != nil {
+		return "", err
+	}
+	claims := &proofClaims{
+		ID:       base64.RawURLEncoding.EncodeToString(jti),
+		Method:   method,
+		URI:      htu,
+		IssuedAt: time.Now().Unix(),
+		Nonce:    p.nonce(htu),
+	}
+	if accessToken != "" {
+		claims.AccessTokenHash = AccessTokenHash(accessToken)
+	}
+	return sign(p.signer, claims)
+}
+
+// SetNonce stores the nonce provided by the server (`DPoP-Nonce` header) for subsequent proofs to the origin of the uri.
+func (p *Prover) SetNonce(uri, nonce string) {
+	origin, err := originOf(uri)
+	if err != nil || nonce == "" {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.nonces[origin] = nonce
+}
+
+func (p *Prover) nonce(uri string) string {
+	origin, err := originOf(uri)
+	if err != nil {
+		return ""
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.nonces[origin]
+}
+
+// AccessTokenHash returns the `ath` claim of a proof for the access token.
+func AccessTokenHash(accessToken string) string {
+	hash := sha256.Sum256([]byte(accessToken))
+	return base64.RawURLEncoding.EncodeToString(hash[:])
+}
+
+type proofClaims struct {
+	ID              string `json:"jti"`
+	Method          string `json:"htm"`
+	URI             string `json:"htu"`
+	IssuedAt        int64  `json:"iat"`
+	AccessTokenHash string `json:"ath,omitempty"`
+	Nonce           string `json:"nonce,omitempty"`
+}
+
+// targetURI returns the uri without query and fragment as required for the `htu` claim.
+func targetURI(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", fmt.Errorf("invalid DPoP target uri: %w", err)
+	}
+	u.RawQuery = ""
+	u.Fragment = ""
+	u.RawFragment = ""
+	return u.String(), nil
+}
+
+func originOf(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", err
+	}
+	return u.Scheme + "://" + u.Host, nil
+}
@@ -0,0 +1,107 @@
+package dpop
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/go-jose/go-jose/v4"
+)
+
+// Transport is an [http.RoundTripper] adding a DPoP proof to every request.
+// If the `Authorization` header uses the DPoP scheme, the proof is bound to its access token.
+// If the server requires a nonce (`use_dpop_nonce` error), the request is retried once with the provided nonce.
+type Transport struct {
+	Prover *Prover
+	// Base is the underlying [http.RoundTripper], [http.DefaultTransport] if nil.
+	Base http.RoundTripper
+}
+
+// NewClient returns an [http.Client] using a [Transport] with the prover,
+// e.g. to request DPoP bound tokens from the token endpoint.
+func NewClient(prover *Prover) *http.Client {
+	return &http.Client{Transport: &Transport{Prover: prover}}
+}
+
+// RoundTrip implements [http.RoundTripper]
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.roundTrip(req)
+	if err != nil || !requiresNonce(resp) {
+		return resp, err
+	}
+	// the body has already been consumed by the first try
+	if req.Body != nil && req.GetBody == nil {
+		return resp, nil
+	}
+	resp.Body.Close()
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		req = req.Clone(req.Context())
+		req.Body = body
+	}
+	return t.roundTrip(req)
+}
+
+func (t *Transport) roundTrip(req *http.Request) (*http.Response, error) {
+	accessToken, _ := AccessToken(req.Header.Get("Authorization"))
+	proof, err := t.Prover.Proof(req.Method, req.URL.String(), accessToken)
+	if err != nil {
+		return nil, err
+	}
+	// the request must not be modified by a RoundTripper
+	req = req.Clone(req.Context())
+	req.Header.Set(HeaderName, proof)
+	resp, err := t.base().RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	t.Prover.SetNonce(req.URL.String(), resp.Header.Get(NonceHeaderName))
+	return resp, nil
+}
+
+func (t *Transport) base() http.RoundTripper {
+	if t.Base != nil {
+		return t.Base
+	}
+	return http.DefaultTransport
+}
+
+// requiresNonce checks if the server rejected the proof because of a missing or outdated nonce,
+// either as token endpoint error or as `WWW-Authenticate` challenge of a resource server.
+func requiresNonce(resp *http.Response) bool {
+	if resp.Header.Get(NonceHeaderName) == "" {
+		return false
+	}
+	switch resp.StatusCode {
+	case http.StatusUnauthorized:
+		return strings.Contains(resp.Header.Get("WWW-Authenticate"), "use_dpop_nonce")
+	case http.StatusBadRequest:
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		resp.Body = io.NopCloser(strings.NewReader(string(body)))
+		if err != nil {
+			return false
+		}
+		var tokenErr struct {
+			Error string `json:"error"`
+		}
+		return json.Unmarshal(body, &tokenErr) == nil && tokenErr.Error == "use_dpop_nonce"
+	}
+	return false
+}
+
+func sign(signer jose.Signer, claims *proofClaims) (string, error) {
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	signature, err := signer.Sign(payload)
+	if err != nil {
+		return "", err
+	}
+	return signature.CompactSerialize()
+}
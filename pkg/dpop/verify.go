@@ -0,0 +1,284 @@
+package dpop
+
+import (
+	"context"
+	"crypto"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/internal/expiring"
+)
+
+var (
+	ErrMissingProof   = errors.New("missing DPoP proof")
+	ErrInvalidProof   = errors.New("invalid DPoP proof")
+	ErrProofMismatch  = errors.New("DPoP proof does not match the request")
+	ErrProofExpired   = errors.New("DPoP proof expired")
+	ErrProofReplayed  = errors.New("DPoP proof has already been used")
+	ErrInvalidNonce   = errors.New("invalid DPoP nonce")
+	ErrTokenNotBound  = errors.New("access token is not bound to the DPoP proof")
+	ErrMultipleProofs = errors.New("multiple DPoP proofs provided")
+)
+
+const (
+	defaultMaxAge = 5 * time.Minute
+	clockSkew     = 30 * time.Second
+)
+
+var supportedAlgorithms = []jose.SignatureAlgorithm{
+	jose.ES256, jose.ES384, jose.ES512,
+	jose.RS256, jose.RS384, jose.RS512,
+	jose.PS256, jose.PS384, jose.PS512,
+	jose.EdDSA,
+}
+
+// Proof is a verified DPoP proof.
+type Proof struct {
+	ID              string
+	Method          string
+	URI             string
+	IssuedAt        time.Time
+	AccessTokenHash string
+	Nonce           string
+	// Thumbprint is the JWK SHA-256 thumbprint of the public key which signed the proof.
+	Thumbprint string
+}
+
+// ReplayCache tracks the IDs (`jti`) of used proofs.
+type ReplayCache interface {
+	// Use marks the ID as used until the expiration and returns false if it was already used.
+	Use(id string, expiration time.Time) bool
+}
+
+// VerifyOption allows additional checks of the proof.
+type VerifyOption func(*verifyConfig)
+
+type verifyConfig struct {
+	accessToken    string
+	thumbprint     string
+	maxAge         time.Duration
+	nonce          func(string) bool
+	replayCache    ReplayCache
+	forwardedProto bool
+}
+
+// WithAccessToken requires the proof to be bound to the access token (`ath` claim).
+func WithAccessToken(accessToken string) VerifyOption {
+	return func(c *verifyConfig) {
+		c.accessToken = accessToken
+	}
+}
+
+// WithThumbprint requires the proof to be signed by the key with the thumbprint,
+// usually the `jkt` confirmation claim of the access token.
+func WithThumbprint(thumbprint string) VerifyOption {
+	return func(c *verifyConfig) {
+		c.thumbprint = thumbprint
+	}
+}
+
+// WithMaxAge sets the max age of the proof (default 5 minutes).
+func WithMaxAge(maxAge time.Duration) VerifyOption {
+	return func(c *verifyConfig) {
+		c.maxAge = maxAge
+	}
+}
+
+// WithNonce requires the proof to contain a nonce which is valid by the provided check.
+func WithNonce(valid func(nonce string) bool) VerifyOption {
+	return func(c *verifyConfig) {
+		c.nonce = valid
+	}
+}
+
+// WithReplayCache rejects proofs which have already been used.
+func WithReplayCache(cache ReplayCache) VerifyOption {
+	return func(c *verifyConfig) {
+		c.replayCache = cache
+	}
+}
+
+// WithForwardedProto uses the scheme of the `X-Forwarded-Proto` header to reconstruct the uri in [VerifyRequest].
+// It must only be used behind a TLS terminating proxy which sets (and overwrites) the header.
+func WithForwardedProto() VerifyOption {
+	return func(c *verifyConfig) {
+		c.forwardedProto = true
+	}
+}
+
+// Verify validates the DPoP proof for the request with the HTTP method to the uri.
+func Verify(proof, method, uri string, options ...VerifyOption) (*Proof, error) {
+	config := &verifyConfig{maxAge: defaultMaxAge}
+	for _, option := range options {
+		option(config)
+	}
+	if proof == "" {
+		return nil, ErrMissingProof
+	}
+	jws, err := jose.ParseSignedCompact(proof, supportedAlgorithms)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidProof, err)
+	}
+	header := jws.Signatures[0].Protected
+	if typ, _ := header.ExtraHeaders[jose.HeaderType].(string); typ != proofType {
+		return nil, fmt.Errorf("%w: typ must be %s", ErrInvalidProof, proofType)
+	}
+	if header.JSONWebKey == nil || !header.JSONWebKey.IsPublic() {
+		return nil, fmt.Errorf("%w: jwk must be a public key", ErrInvalidProof)
+	}
+	payload, err := jws.Verify(header.JSONWebKey)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidProof, err)
+	}
+	claims := new(proofClaims)
+	if err = json.Unmarshal(payload, claims); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidProof, err)
+	}
+	if claims.ID == "" {
+		return nil, fmt.Errorf("%w: missing jti", ErrInvalidProof)
+	}
+	htu, err := targetURI(uri)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(claims.Method, method) || claims.URI != htu {
+		return nil, ErrProofMismatch
+	}
+	issuedAt := time.Unix(claims.IssuedAt, 0)
+	now := time.Now()
+	if issuedAt.Before(now.Add(-config.maxAge)) || issuedAt.After(now.Add(clockSkew)) {
+		return nil, ErrProofExpired
+	}
+	if config.nonce != nil && (claims.Nonce == "" || !config.nonce(claims.Nonce)) {
+		return nil, ErrInvalidNonce
+	}
+	thumbprint, err := header.JSONWebKey.Thumbprint(crypto.SHA256)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidProof, err)
+	}
+	verified := &Proof{
+		ID:              claims.ID,
+		Method:          claims.Method,
+		URI:             claims.URI,
+		IssuedAt:        issuedAt,
+		AccessTokenHash: claims.AccessTokenHash,
+		Nonce:           claims.Nonce,
+		Thumbprint:      base64.RawURLEncoding.EncodeToString(thumbprint),
+	}
+	if config.accessToken != "" && !equal(verified.AccessTokenHash, AccessTokenHash(config.accessToken)) {
+		return nil, ErrTokenNotBound
+	}
+	if config.thumbprint != "" && !equal(verified.Thumbprint, config.thumbprint) {
+		return nil, ErrTokenNotBound
+	}
+	if config.replayCache != nil && !config.replayCache.Use(claims.ID, issuedAt.Add(config.maxAge+clockSkew)) {
+		return nil, ErrProofReplayed
+	}
+	return verified, nil
+}
+
+// VerifyRequest validates the DPoP proof of the request.
+// If the request contains an access token with the DPoP scheme, the proof must be bound to it.
+// The uri is reconstructed from the request (see [RequestURI] and [WithForwardedProto]).
+func VerifyRequest(req *http.Request, options ...VerifyOption) (*Proof, error) {
+	proofs := req.Header.Values(HeaderName)
+	if len(proofs) > 1 {
+		return nil, ErrMultipleProofs
+	}
+	if len(proofs) == 0 {
+		return nil, ErrMissingProof
+	}
+	if accessToken, ok := AccessToken(req.Header.Get("Authorization")); ok {
+		options = append([]VerifyOption{WithAccessToken(accessToken)}, options...)
+	}
+	config := new(verifyConfig)
+	for _, option := range options {
+		option(config)
+	}
+	return Verify(proofs[0], req.Method, requestURI(req, config.forwardedProto), options...)
+}
+
+// AccessToken returns the token of an authorization header value using the DPoP scheme.
+func AccessToken(authorization string) (string, bool) {
+	scheme, token, ok := strings.Cut(authorization, " ")
+	if !ok || !strings.EqualFold(scheme, TokenType) {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// RequestURI returns the absolute uri of the request as it's used by the client for the `htu` claim.
+// The scheme is derived from the TLS state of the request, the `X-Forwarded-Proto` header is ignored.
+func RequestURI(req *http.Request) string {
+	return requestURI(req, false)
+}
+
+func requestURI(req *http.Request, forwardedProto bool) string {
+	scheme := "http"
+	if req.TLS != nil {
+		scheme = "https"
+	}
+	if proto := req.Header.Get("X-Forwarded-Proto"); forwardedProto && proto != "" {
+		scheme = proto
+	}
+	return scheme + "://" + req.Host + req.URL.EscapedPath()
+}
+
+type proofKey struct{}
+
+// WithProof returns a context containing the verified proof of the request, e.g. set by middleware.RequireDPoP,
+// so verifiers accept the access token with the DPoP scheme (see [ProofFromContext]).
+func WithProof(ctx context.Context, proof *Proof) context.Context {
+	return context.WithValue(ctx, proofKey{}, proof)
+}
+
+// ProofFromContext returns the verified proof of the request set by [WithProof].
+func ProofFromContext(ctx context.Context) (*Proof, bool) {
+	proof, ok := ctx.Value(proofKey{}).(*Proof)
+	return proof, ok && proof != nil
+}
+
+// ConfirmationThumbprint returns the JWK thumbprint of the key the access token of the authorization context
+// is bound to (the `jkt` of the `cnf` claim). It's empty if the token is not bound or the context doesn't provide it
+// by a `ConfirmationThumbprint() string` method (e.g. oauth.IntrospectionContext).
+// Bound tokens must only be accepted with a verified proof of that key.
+func ConfirmationThumbprint(authCtx any) string {
+	confirmation, ok := authCtx.(interface{ ConfirmationThumbprint() string })
+	if !ok {
+		return ""
+	}
+	return confirmation.ConfirmationThumbprint()
+}
+
+func equal(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+// MemoryReplayCache is an in-memory [ReplayCache], e.g. for tests or single process setups.
+// Expired IDs are evicted in the order of their expiration.
+type MemoryReplayCache struct {
+	mu  sync.Mutex
+	ids *expiring.Set
+}
+
+// NewMemoryReplayCache creates an empty [MemoryReplayCache].
+func NewMemoryReplayCache() *MemoryReplayCache {
+	return &MemoryReplayCache{ids: expiring.New()}
+}
+
+// Use implements [ReplayCache].
+func (c *MemoryReplayCache) Use(id string, expiration time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ids.Add(id, expiration)
+}
@@ -0,0 +1,143 @@
+package dpop
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerify(t *testing.T) {
+	prover, err := GenerateProver()
+	require.NoError(t, err)
+	other, err := GenerateProver()
+	require.NoError(t, err)
+
+	type args struct {
+		method  string
+		uri     string
+		options []VerifyOption
+	}
+	tests := []struct {
+		name    string
+		proof   func() string
+		args    args
+		wantErr error
+	}{
+		{
+			name:  "valid proof",
+			proof: proof(t, prover, "POST", "https://api.example.com/resource?query=1", "token"),
+			args: args{
+				method:  "POST",
+				uri:     "https://api.example.com/resource",
+				options: []VerifyOption{WithAccessToken("token"), WithThumbprint(prover.Thumbprint())},
+			},
+		},
+		{
+			name:    "missing proof",
+			proof:   func() string { return "" },
+			args:    args{method: "GET", uri: "https://api.example.com/resource"},
+			wantErr: ErrMissingProof,
+		},
+		{
+			name:    "different method",
+			proof:   proof(t, prover, "GET", "https://api.example.com/resource", ""),
+			args:    args{method: "POST", uri: "https://api.example.com/resource"},
+			wantErr: ErrProofMismatch,
+		},
+		{
+			name:    "different uri",
+			proof:   proof(t, prover, "GET", "https://api.example.com/resource", ""),
+			args:    args{method: "GET", uri: "https://api.example.com/other"},
+			wantErr: ErrProofMismatch,
+		},
+		{
+			name:  "different access token",
+			proof: proof(t, prover, "GET", "https://api.example.com/resource", "token"),
+			args: args{
+				method:  "GET",
+				uri:     "https://api.example.com/resource",
+				options: []VerifyOption{WithAccessToken("other")},
+			},
+			wantErr: ErrTokenNotBound,
+		},
+		{
+			name:  "different key",
+			proof: proof(t, other, "GET", "https://api.example.com/resource", "token"),
+			args: args{
+				method:  "GET",
+				uri:     "https://api.example.com/resource",
+				options: []VerifyOption{WithThumbprint(prover.Thumbprint())},
+			},
+			wantErr: ErrTokenNotBound,
+		},
+		{
+			name:  "missing nonce",
+			proof: proof(t, prover, "GET", "https://api.example.com/resource", ""),
+			args: args{
+				method:  "GET",
+				uri:     "https://api.example.com/resource",
+				options: []VerifyOption{WithNonce(func(string) bool { return true })},
+			},
+			wantErr: ErrInvalidNonce,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Verify(tt.proof(), tt.args.method, tt.args.uri, tt.args.options...)
+			assert.ErrorIs(t, err, tt.wantErr)
+			if tt.wantErr == nil {
+				assert.Equal(t, prover.Thumbprint(), got.Thumbprint)
+			}
+		})
+	}
+}
+
+func TestVerify_replay(t *testing.T) {
+	prover, err := GenerateProver()
+	require.NoError(t, err)
+	p, err := prover.Proof("GET", "https://api.example.com/resource", "")
+	require.NoError(t, err)
+	cache := NewMemoryReplayCache()
+
+	_, err = Verify(p, "GET", "https://api.example.com/resource", WithReplayCache(cache))
+	assert.NoError(t, err)
+	_, err = Verify(p, "GET", "https://api.example.com/resource", WithReplayCache(cache))
+	assert.ErrorIs(t, err, ErrProofReplayed)
+}
+
+func TestMemoryReplayCache(t *testing.T) {
+	cache := NewMemoryReplayCache()
+	now := time.Now()
+	assert.True(t, cache.Use("a", now.Add(time.Minute)))
+	assert.False(t, cache.Use("a", now.Add(time.Minute)), "already used")
+	assert.True(t, cache.Use("b", now.Add(-time.Second)))
+	assert.True(t, cache.Use("b", now.Add(time.Minute)), "expired IDs can be used again")
+}
+
+func TestVerifyRequest_forwardedProto(t *testing.T) {
+	prover, err := GenerateProver()
+	require.NoError(t, err)
+	p, err := prover.Proof("GET", "https://api.example.com/resource", "token")
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodGet, "http://api.example.com/resource", nil)
+	req.Header.Set(HeaderName, p)
+	req.Header.Set("Authorization", "DPoP token")
+	req.Header.Set("X-Forwarded-Proto", "https")
+
+	_, err = VerifyRequest(req)
+	assert.ErrorIs(t, err, ErrProofMismatch, "the header must not be trusted by default")
+	_, err = VerifyRequest(req, WithForwardedProto())
+	assert.NoError(t, err)
+}
+
+func proof(t *testing.T, prover *Prover, method, uri, accessToken string) func() string {
+	return func() string {
+		p, err := prover.Proof(method, uri, accessToken)
+		require.NoError(t, err)
+		return p
+	}
+}
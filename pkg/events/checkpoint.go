@@ -0,0 +1,100 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// CheckpointStore persists the cursors of subscriptions.
+type CheckpointStore interface {
+	// Load returns the cursor saved under the name or [ErrCheckpointNotFound].
+	Load(ctx context.Context, name string) (*Cursor, error)
+	Save(ctx context.Context, name string, cursor *Cursor) error
+}
+
+// MemoryCheckpointStore is an in-memory [CheckpointStore], e.g. for tests.
+type MemoryCheckpointStore struct {
+	mu      sync.Mutex
+	cursors map[string]*Cursor
+}
+
+func NewMemoryCheckpointStore() *MemoryCheckpointStore {
+	return &MemoryCheckpointStore{cursors: make(map[string]*Cursor)}
+}
+
+// Load implements [CheckpointStore]
+func (m *MemoryCheckpointStore) Load(_ context.Context, name string) (*Cursor, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	cursor, ok := m.cursors[name]
+	if !ok {
+		return nil, ErrCheckpointNotFound
+	}
+	return cursor, nil
+}
+
+// Save implements [CheckpointStore]
+func (m *MemoryCheckpointStore) Save(_ context.Context, name string, cursor *Cursor) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cursors[name] = cursor
+	return nil
+}
+
+// FileCheckpointStore is a [CheckpointStore] writing every cursor as JSON file into a directory.
+type FileCheckpointStore struct {
+	dir string
+}
+
+// NewFileCheckpointStore creates a [FileCheckpointStore] in the directory, which is created if necessary.
+func NewFileCheckpointStore(dir string) (*FileCheckpointStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	return &FileCheckpointStore{dir: dir}, nil
+}
+
+// Load implements [CheckpointStore]
+func (f *FileCheckpointStore) Load(_ context.Context, name string) (*Cursor, error) {
+	data, err := os.ReadFile(f.path(name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrCheckpointNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	cursor := new(Cursor)
+	if err = json.Unmarshal(data, cursor); err != nil {
+		return nil, err
+	}
+	return cursor, nil
+}
+
+// Save implements [CheckpointStore] by writing to a temporary file first, so a crash can't corrupt the checkpoint.
+func (f *FileCheckpointStore) Save(_ context.Context, name string, cursor *Cursor) error {
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(f.dir, ".checkpoint-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), f.path(name))
+}
+
+func (f *FileCheckpointStore) path(name string) string {
+	return filepath.Join(f.dir, filepath.Base(name)+".json")
+}
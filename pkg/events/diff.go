@@ -0,0 +1,138 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Operation is an operation of a JSON Patch (RFC 6902).
+type Operation struct {
+	// Op is one of `add`, `remove` and `replace`.
+	Op string `json:"op"`
+	// Path is the JSON Pointer (RFC 6901) of the changed field, e.g. `/roles` or `/profile/givenName`.
+	Path string `json:"path"`
+	// Value is the new value of `add` and `replace` operations.
+	Value any `json:"value,omitempty"`
+	// Old is the previous value of `remove` and `replace` operations, it's not part of the serialized patch.
+	Old any `json:"-"`
+}
+
+// MarshalJSON serializes the value even if it's null, except for `remove` operations.
+func (o Operation) MarshalJSON() ([]byte, error) {
+	if o.Op == "remove" {
+		return json.Marshal(struct {
+			Op   string `json:"op"`
+			Path string `json:"path"`
+		}{o.Op, o.Path})
+	}
+	return json.Marshal(struct {
+		Op    string `json:"op"`
+		Path  string `json:"path"`
+		Value any    `json:"value"`
+	}{o.Op, o.Path, o.Value})
+}
+
+// Patch is a JSON Patch (RFC 6902) transforming the state of an aggregate at one time into the state at another time.
+type Patch []Operation
+
+// Diff returns the field-level changes of the aggregate (e.g. a user, org or project) between the times t1 and t2
+// as [Patch] of the states reconstructed by [Replayer.StateAt].
+// The states are compared by their JSON representation: objects field by field, arrays as a whole.
+// If the aggregate did not exist at t1 (or was removed), the patch adds the whole state at t2 at the root path,
+// if it was removed until t2, the patch removes the root path.
+func (r *Replayer) Diff(ctx context.Context, aggregateID string, t1, t2 time.Time) (Patch, error) {
+	from, err := r.jsonStateAt(ctx, aggregateID, t1)
+	if err != nil {
+		return nil, err
+	}
+	to, err := r.jsonStateAt(ctx, aggregateID, t2)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case from == nil && to == nil:
+		return nil, ErrAggregateNotFound
+	case !from.exists() && !to.exists():
+		return Patch{}, nil
+	case !from.exists():
+		return Patch{{Op: "add", Path: "", Value: to.value}}, nil
+	case !to.exists():
+		return Patch{{Op: "remove", Path: "", Old: from.value}}, nil
+	}
+	return diff(Patch{}, "", from.value, to.value), nil
+}
+
+// jsonState is the state of an aggregate with its value as generic JSON value.
+type jsonState struct {
+	removed bool
+	value   any
+}
+
+// exists returns whether the aggregate existed, i.e. it was created and not removed.
+func (s *jsonState) exists() bool {
+	return s != nil && !s.removed
+}
+
+// jsonStateAt returns the state at the time t, nil if the aggregate did not exist.
+func (r *Replayer) jsonStateAt(ctx context.Context, aggregateID string, t time.Time) (*jsonState, error) {
+	state, err := r.StateAt(ctx, aggregateID, t)
+	if errors.Is(err, ErrAggregateNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(state.Value)
+	if err != nil {
+		return nil, err
+	}
+	s := &jsonState{removed: state.Removed}
+	if err = json.Unmarshal(data, &s.value); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// diff appends the operations transforming the value from into to at the path.
+func diff(patch Patch, path string, from, to any) Patch {
+	fromObject, fromOK := from.(map[string]any)
+	toObject, toOK := to.(map[string]any)
+	if !fromOK || !toOK {
+		if !reflect.DeepEqual(from, to) {
+			patch = append(patch, Operation{Op: "replace", Path: path, Value: to, Old: from})
+		}
+		return patch
+	}
+	keys := slices.Collect(maps.Keys(fromObject))
+	for key := range toObject {
+		if _, ok := fromObject[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		fieldPath := path + "/" + escapePointer(key)
+		fromValue, inFrom := fromObject[key]
+		toValue, inTo := toObject[key]
+		switch {
+		case !inTo:
+			patch = append(patch, Operation{Op: "remove", Path: fieldPath, Old: fromValue})
+		case !inFrom:
+			patch = append(patch, Operation{Op: "add", Path: fieldPath, Value: toValue})
+		default:
+			patch = diff(patch, fieldPath, fromValue, toValue)
+		}
+	}
+	return patch
+}
+
+// escapePointer escapes a reference token of a JSON Pointer (RFC 6901).
+func escapePointer(token string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+}
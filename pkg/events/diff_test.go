@@ -0,0 +1,108 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplayer_Diff(t *testing.T) {
+	june := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	lister := new(testLister)
+	lister.add("user1", 1, june, map[string]any{"userName": "gigi", "email": "gigi@example.com", "profile": map[string]any{"givenName": "Gigi", "nickName": "g"}})
+	lister.addEvent("user", "user1", "user.human.changed", 2, june.AddDate(0, 1, 0), map[string]any{"email": "gigi@zitadel.com", "profile": map[string]any{"givenName": "Gigi"}, "a/b": "c"})
+	lister.addEvent("usergrant", "grant1", "user.grant.added", 1, june, map[string]any{"userId": "user1", "roleKeys": []any{"reader"}})
+	lister.addEvent("usergrant", "grant1", "user.grant.changed", 2, june.AddDate(0, 1, 0), map[string]any{"roleKeys": []any{"reader", "writer"}})
+	lister.addEvent("usergrant", "grant1", "user.grant.removed", 3, june.AddDate(1, 0, 0), nil)
+
+	replayer := newReplayer(lister,
+		WithReducer("user", MergePayloads),
+		WithReducer("usergrant", ReducerFor(reduceGrant)),
+	)
+
+	tests := []struct {
+		name        string
+		aggregateID string
+		t1, t2      time.Time
+		want        string
+		wantErr     error
+	}{
+		{
+			name:        "changed fields",
+			aggregateID: "user1",
+			t1:          june,
+			t2:          june.AddDate(1, 0, 0),
+			want: `[
+				{"op":"add","path":"/a~1b","value":"c"},
+				{"op":"replace","path":"/email","value":"gigi@zitadel.com"},
+				{"op":"remove","path":"/profile/nickName"}
+			]`,
+		},
+		{
+			name:        "reducer state",
+			aggregateID: "grant1",
+			t1:          june,
+			t2:          june.AddDate(0, 6, 0),
+			want:        `[{"op":"replace","path":"/Roles","value":["reader","writer"]}]`,
+		},
+		{
+			name:        "created",
+			aggregateID: "grant1",
+			t1:          june.AddDate(-1, 0, 0),
+			t2:          june,
+			want:        `[{"op":"add","path":"","value":{"UserID":"user1","Roles":["reader"]}}]`,
+		},
+		{
+			name:        "removed",
+			aggregateID: "grant1",
+			t1:          june,
+			t2:          june.AddDate(2, 0, 0),
+			want:        `[{"op":"remove","path":""}]`,
+		},
+		{
+			name:        "removed at t1",
+			aggregateID: "grant1",
+			t1:          june.AddDate(2, 0, 0),
+			t2:          june,
+			want:        `[{"op":"add","path":"","value":{"UserID":"user1","Roles":["reader"]}}]`,
+		},
+		{
+			name:        "removed at both times",
+			aggregateID: "grant1",
+			t1:          june.AddDate(1, 0, 0),
+			t2:          june.AddDate(2, 0, 0),
+			want:        `[]`,
+		},
+		{
+			name:        "unchanged",
+			aggregateID: "grant1",
+			t1:          june,
+			t2:          june.Add(time.Hour),
+			want:        `[]`,
+		},
+		{
+			name:        "not found",
+			aggregateID: "unknown",
+			t1:          june,
+			t2:          june.Add(time.Hour),
+			wantErr:     ErrAggregateNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patch, err := replayer.Diff(context.Background(), tt.aggregateID, tt.t1, tt.t2)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			got, err := json.Marshal(patch)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.want, string(got))
+		})
+	}
+}
@@ -0,0 +1,366 @@
+// Package events streams the events of the ZITADEL event store by polling the admin ListEvents API,
+// so consumers can build their own projections.
+// The position in the stream is tracked by a [Cursor], which can be persisted using a [CheckpointStore]
+// to resume the stream after a restart.
+//
+// A [Replayer] reconstructs the historical state of an aggregate (e.g. a user) at a point in time
+// by replaying its events with registered reducers, e.g. for audits.
+// [Replayer.Diff] compares the states at two points in time as JSON Patch.
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/admin"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/event"
+)
+
+const (
+	defaultInterval = 5 * time.Second
+	defaultPageSize = 1000
+)
+
+var ErrCheckpointNotFound = errors.New("checkpoint not found")
+
+// Event is an event of the event store.
+type Event struct {
+	AggregateType string
+	AggregateID   string
+	ResourceOwner string
+	Type          string
+	// Sequence is the sequence of the event in its aggregate.
+	Sequence     uint64
+	CreationDate time.Time
+	EditorUserID string
+	EditorName   string
+	// EditorService is the service of ZITADEL the event was created by, e.g. `Admin-API`.
+	EditorService string
+	// Payload is the JSON encoded data of the event, use [Event.Decode] to parse it.
+	Payload json.RawMessage
+	// Cursor is the position in the stream after the event.
+	Cursor *Cursor
+}
+
+// Key identifies the event by its aggregate and sequence.
+func (e *Event) Key() string {
+	return e.AggregateType + ":" + e.AggregateID + ":" + strconv.FormatUint(e.Sequence, 10)
+}
+
+// Decode parses the payload into v.
+func (e *Event) Decode(v any) error {
+	if len(e.Payload) == 0 {
+		return nil
+	}
+	return json.Unmarshal(e.Payload, v)
+}
+
+func newEvent(e *event.Event) (*Event, error) {
+	var payload json.RawMessage
+	if e.GetPayload() != nil {
+		var err error
+		payload, err = e.GetPayload().MarshalJSON()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &Event{
+		AggregateType: e.GetAggregate().GetType().GetType(),
+		AggregateID:   e.GetAggregate().GetId(),
+		ResourceOwner: e.GetAggregate().GetResourceOwner(),
+		Type:          e.GetType().GetType(),
+		Sequence:      e.GetSequence(),
+		CreationDate:  e.GetCreationDate().AsTime(),
+		EditorUserID:  e.GetEditor().GetUserId(),
+		EditorName:    e.GetEditor().GetDisplayName(),
+		EditorService: e.GetEditor().GetService(),
+		Payload:       payload,
+	}, nil
+}
+
+// Filter restricts the events of a subscription, empty fields match all events.
+type Filter struct {
+	AggregateTypes []string
+	// AggregateID restricts the events to a single aggregate (e.g. a user), the stream then uses its sequence as cursor.
+	AggregateID   string
+	EventTypes    []string
+	ResourceOwner string
+	EditorUserID  string
+}
+
+// Cursor is the position in the stream of events.
+// Events are ordered by their creation date, events created at the same time are distinguished by their key
+// (aggregate and sequence).
+type Cursor struct {
+	CreationDate time.Time `json:"creationDate"`
+	// Sequence is the sequence of the last event, it's only used for filters of a single aggregate.
+	Sequence uint64 `json:"sequence,omitempty"`
+	// Delivered contains the keys of the events delivered with the creation date of the cursor.
+	Delivered []string `json:"delivered,omitempty"`
+}
+
+func (c *Cursor) next(e *Event) *Cursor {
+	next := &Cursor{CreationDate: e.CreationDate, Sequence: e.Sequence}
+	if e.CreationDate.Equal(c.CreationDate) {
+		next.Delivered = append(append(next.Delivered, c.Delivered...), e.Key())
+		return next
+	}
+	next.Delivered = []string{e.Key()}
+	return next
+}
+
+func (c *Cursor) delivered(e *Event) bool {
+	if e.CreationDate.Before(c.CreationDate) {
+		return true
+	}
+	if !e.CreationDate.Equal(c.CreationDate) {
+		return false
+	}
+	key := e.Key()
+	for _, delivered := range c.Delivered {
+		if delivered == key {
+			return true
+		}
+	}
+	return false
+}
+
+// eventLister is implemented by the [admin.AdminServiceClient].
+type eventLister interface {
+	ListEvents(ctx context.Context, in *admin.ListEventsRequest, opts ...grpc.CallOption) (*admin.ListEventsResponse, error)
+}
+
+// Subscriber polls the events of the event store.
+// The client must be authorized to read the events of the instance (e.g. IAM_OWNER_VIEWER).
+type Subscriber struct {
+	lister     eventLister
+	interval   time.Duration
+	pageSize   uint32
+	buffer     int
+	from       time.Time
+	checkpoint CheckpointStore
+	name       string
+	onError    func(err error)
+}
+
+// Option allows customization of the [Subscriber].
+type Option func(*Subscriber)
+
+// WithInterval sets the polling interval (default 5 seconds).
+func WithInterval(interval time.Duration) Option {
+	return func(s *Subscriber) {
+		s.interval = interval
+	}
+}
+
+// WithPageSize sets the number of events requested at once (default 1000).
+func WithPageSize(size uint32) Option {
+	return func(s *Subscriber) {
+		s.pageSize = size
+	}
+}
+
+// WithBuffer sets the buffer size of the channel (default unbuffered).
+// Note that events in the buffer are considered received for the checkpoint (see [WithCheckpoint]).
+func WithBuffer(size int) Option {
+	return func(s *Subscriber) {
+		s.buffer = size
+	}
+}
+
+// WithFrom sets the creation date the stream starts at if there's no checkpoint (default: the beginning of the event store).
+func WithFrom(from time.Time) Option {
+	return func(s *Subscriber) {
+		s.from = from
+	}
+}
+
+// WithCheckpoint persists the cursor of the subscription in the store under the name and resumes from it.
+// The cursor of an event is saved once the next event has been received, so a consumer processing the events
+// sequentially from an unbuffered channel gets every event at least once.
+func WithCheckpoint(store CheckpointStore, name string) Option {
+	return func(s *Subscriber) {
+		s.checkpoint = store
+		s.name = name
+	}
+}
+
+// WithErrorHandler sets a function called on errors of the polling or saving checkpoints (default: ignored).
+// Failed requests are retried with the next poll.
+func WithErrorHandler(onError func(err error)) Option {
+	return func(s *Subscriber) {
+		s.onError = onError
+	}
+}
+
+// New creates a [Subscriber] using the admin API of the client.
+func New(client *client.Client, options ...Option) *Subscriber {
+	return newSubscriber(client.AdminService(), options...)
+}
+
+func newSubscriber(lister eventLister, options ...Option) *Subscriber {
+	s := &Subscriber{
+		lister:   lister,
+		interval: defaultInterval,
+		pageSize: defaultPageSize,
+		onError:  func(error) {},
+	}
+	for _, option := range options {
+		option(s)
+	}
+	return s
+}
+
+// Cursor returns the position the stream starts at: the saved checkpoint (if any) or the creation date of [WithFrom].
+func (s *Subscriber) Cursor(ctx context.Context) (*Cursor, error) {
+	if s.checkpoint != nil {
+		saved, err := s.checkpoint.Load(ctx, s.name)
+		if err != nil && !errors.Is(err, ErrCheckpointNotFound) {
+			return nil, err
+		}
+		if saved != nil {
+			return saved, nil
+		}
+	}
+	return &Cursor{CreationDate: s.from}, nil
+}
+
+// Subscribe streams the events matching the filter, starting at the checkpoint (if any).
+// The channel is closed when the context is done.
+func (s *Subscriber) Subscribe(ctx context.Context, filter Filter) (<-chan *Event, error) {
+	cursor, err := s.Cursor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ch := make(chan *Event, s.buffer)
+	go s.run(ctx, filter, cursor, ch)
+	return ch, nil
+}
+
+// CatchUp calls fn with the pages of events matching the filter after the cursor until all existing events
+// are delivered, e.g. for batch jobs or consumers polling at their own pace (start with [Subscriber.Cursor]).
+// It returns the cursor after the last page fn succeeded for, which is saved as checkpoint (see [WithCheckpoint]).
+func (s *Subscriber) CatchUp(ctx context.Context, filter Filter, cursor *Cursor, fn func(ctx context.Context, events []*Event) error) (*Cursor, error) {
+	limit := s.pageSize
+	for {
+		events, full, err := s.poll(ctx, filter, cursor, limit)
+		if err != nil {
+			return cursor, err
+		}
+		if len(events) > 0 {
+			if err = fn(ctx, events); err != nil {
+				return cursor, err
+			}
+			cursor = events[len(events)-1].Cursor
+			if s.checkpoint != nil {
+				if err = s.checkpoint.Save(ctx, s.name, cursor); err != nil {
+					return cursor, err
+				}
+			}
+		}
+		if !full {
+			return cursor, nil
+		}
+		limit = s.nextLimit(limit, len(events))
+	}
+}
+
+func (s *Subscriber) run(ctx context.Context, filter Filter, cursor *Cursor, ch chan<- *Event) {
+	defer close(ch)
+	// the cursor of the last sent event is saved once the next one is received
+	var pending *Cursor
+	limit := s.pageSize
+	for {
+		events, full, err := s.poll(ctx, filter, cursor, limit)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			s.onError(err)
+		}
+		for _, e := range events {
+			select {
+			case <-ctx.Done():
+				return
+			case ch <- e:
+			}
+			if pending != nil {
+				s.save(ctx, pending)
+			}
+			pending = e.Cursor
+			cursor = e.Cursor
+		}
+		if full {
+			limit = s.nextLimit(limit, len(events))
+			continue
+		}
+		limit = s.pageSize
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(s.interval):
+		}
+	}
+}
+
+// nextLimit returns the limit of the request following a full page:
+// a full page of delivered events (created at the same time) requires a larger page to proceed.
+func (s *Subscriber) nextLimit(limit uint32, events int) uint32 {
+	if events == 0 {
+		return limit * 2
+	}
+	return s.pageSize
+}
+
+// poll returns the events after the cursor and if the page was full.
+func (s *Subscriber) poll(ctx context.Context, filter Filter, cursor *Cursor, limit uint32) (_ []*Event, full bool, err error) {
+	req := &admin.ListEventsRequest{
+		Asc:            true,
+		Limit:          limit,
+		AggregateTypes: filter.AggregateTypes,
+		AggregateId:    filter.AggregateID,
+		EventTypes:     filter.EventTypes,
+		ResourceOwner:  filter.ResourceOwner,
+		EditorUserId:   filter.EditorUserID,
+	}
+	if !cursor.CreationDate.IsZero() {
+		req.CreationDateFilter = &admin.ListEventsRequest_From{From: timestamppb.New(cursor.CreationDate)}
+	}
+	if filter.AggregateID != "" {
+		req.Sequence = cursor.Sequence
+	}
+	resp, err := s.lister.ListEvents(ctx, req)
+	if err != nil {
+		return nil, false, err
+	}
+	events := make([]*Event, 0, len(resp.GetEvents()))
+	for _, pbEvent := range resp.GetEvents() {
+		e, err := newEvent(pbEvent)
+		if err != nil {
+			return events, false, err
+		}
+		if cursor.delivered(e) {
+			continue
+		}
+		cursor = cursor.next(e)
+		e.Cursor = cursor
+		events = append(events, e)
+	}
+	return events, len(resp.GetEvents()) == int(limit), nil
+}
+
+func (s *Subscriber) save(ctx context.Context, cursor *Cursor) {
+	if s.checkpoint == nil {
+		return
+	}
+	if err := s.checkpoint.Save(ctx, s.name, cursor); err != nil {
+		s.onError(err)
+	}
+}
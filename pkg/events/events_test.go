@@ -0,0 +1,163 @@
+package events
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/admin"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/event"
+)
+
+// testLister serves the events created at or after the requested date, like the event store.
+type testLister struct {
+	mu     sync.Mutex
+	events []*event.Event
+}
+
+func (l *testLister) add(aggregateID string, sequence uint64, created time.Time, payload map[string]any) {
+	l.addEvent("user", aggregateID, "user.human.added", sequence, created, payload)
+}
+
+func (l *testLister) addEvent(aggregateType, aggregateID, eventType string, sequence uint64, created time.Time, payload map[string]any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	p, _ := structpb.NewStruct(payload)
+	l.events = append(l.events, &event.Event{
+		Aggregate:    &event.Aggregate{Id: aggregateID, Type: &event.AggregateType{Type: aggregateType}, ResourceOwner: "org1"},
+		Sequence:     sequence,
+		CreationDate: timestamppb.New(created),
+		Type:         &event.EventType{Type: eventType},
+		Payload:      p,
+	})
+}
+
+func (l *testLister) ListEvents(_ context.Context, req *admin.ListEventsRequest, _ ...grpc.CallOption) (*admin.ListEventsResponse, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	resp := new(admin.ListEventsResponse)
+	for _, e := range l.events {
+		if from := req.GetFrom(); from != nil && e.GetCreationDate().AsTime().Before(from.AsTime()) {
+			continue
+		}
+		if until := req.GetRange().GetUntil(); until != nil && e.GetCreationDate().AsTime().After(until.AsTime()) {
+			continue
+		}
+		if id := req.GetAggregateId(); id != "" && (e.GetAggregate().GetId() != id || e.GetSequence() <= req.GetSequence()) {
+			continue
+		}
+		if uint32(len(resp.Events)) == req.GetLimit() {
+			break
+		}
+		resp.Events = append(resp.Events, e)
+	}
+	return resp, nil
+}
+
+func receive(t *testing.T, ch <-chan *Event, n int) []*Event {
+	events := make([]*Event, 0, n)
+	for len(events) < n {
+		select {
+		case e := <-ch:
+			events = append(events, e)
+		case <-time.After(time.Second):
+			t.Fatalf("received %d of %d events", len(events), n)
+		}
+	}
+	return events
+}
+
+func TestSubscriber_Subscribe(t *testing.T) {
+	start := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	lister := new(testLister)
+	lister.add("user1", 1, start, map[string]any{"userName": "gigi"})
+	lister.add("user2", 1, start, map[string]any{"userName": "ben"})
+	lister.add("user3", 1, start.Add(time.Second), nil)
+	store := NewMemoryCheckpointStore()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s := newSubscriber(lister, WithInterval(time.Millisecond), WithPageSize(2), WithCheckpoint(store, "test"))
+	ch, err := s.Subscribe(ctx, Filter{})
+	require.NoError(t, err)
+
+	events := receive(t, ch, 3)
+	assert.Equal(t, []string{"user:user1:1", "user:user2:1", "user:user3:1"}, []string{events[0].Key(), events[1].Key(), events[2].Key()})
+	var payload struct{ UserName string }
+	require.NoError(t, events[0].Decode(&payload))
+	assert.Equal(t, "gigi", payload.UserName)
+
+	lister.add("user4", 1, start.Add(time.Second), nil)
+	events = receive(t, ch, 1)
+	assert.Equal(t, "user:user4:1", events[0].Key())
+	cancel()
+	for range ch {
+	}
+
+	// the checkpoint is the cursor of user3, as user4 was received but not acknowledged by a next receive
+	ch, err = newSubscriber(lister, WithInterval(time.Millisecond), WithCheckpoint(store, "test")).Subscribe(context.Background(), Filter{})
+	require.NoError(t, err)
+	events = receive(t, ch, 1)
+	assert.Equal(t, "user:user4:1", events[0].Key())
+}
+
+func TestSubscriber_CatchUp(t *testing.T) {
+	start := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	lister := new(testLister)
+	lister.add("user1", 1, start, nil)
+	lister.add("user2", 1, start, nil)
+	lister.add("user3", 1, start, nil)
+	lister.add("user4", 1, start.Add(time.Second), nil)
+	store := NewMemoryCheckpointStore()
+	s := newSubscriber(lister, WithPageSize(2), WithCheckpoint(store, "test"))
+
+	cursor, err := s.Cursor(context.Background())
+	require.NoError(t, err)
+	var pages [][]string
+	collect := func(_ context.Context, events []*Event) error {
+		page := make([]string, len(events))
+		for i, e := range events {
+			page[i] = e.AggregateID
+		}
+		pages = append(pages, page)
+		return nil
+	}
+	cursor, err = s.CatchUp(context.Background(), Filter{}, cursor, collect)
+	require.NoError(t, err)
+	// the page after user2 starts at their creation date, so the first request returns delivered events only
+	assert.Equal(t, [][]string{{"user1", "user2"}, {"user3", "user4"}}, pages)
+	saved, err := store.Load(context.Background(), "test")
+	require.NoError(t, err)
+	assert.Equal(t, cursor, saved)
+
+	pages = nil
+	lister.add("user5", 1, start.Add(2*time.Second), nil)
+	cursor, err = s.CatchUp(context.Background(), Filter{}, cursor, func(_ context.Context, _ []*Event) error {
+		return assert.AnError
+	})
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.Equal(t, saved, cursor)
+
+	_, err = s.CatchUp(context.Background(), Filter{}, cursor, collect)
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"user5"}}, pages)
+}
+
+func TestFileCheckpointStore(t *testing.T) {
+	store, err := NewFileCheckpointStore(t.TempDir())
+	require.NoError(t, err)
+	_, err = store.Load(context.Background(), "projection")
+	assert.ErrorIs(t, err, ErrCheckpointNotFound)
+
+	cursor := &Cursor{CreationDate: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), Delivered: []string{"user:user1:1"}}
+	require.NoError(t, store.Save(context.Background(), "projection", cursor))
+	loaded, err := store.Load(context.Background(), "projection")
+	require.NoError(t, err)
+	assert.Equal(t, cursor, loaded)
+}
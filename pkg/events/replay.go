@@ -0,0 +1,185 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/admin"
+)
+
+var (
+	ErrAggregateNotFound = errors.New("aggregate not found")
+	ErrNoReducer         = errors.New("no reducer registered for aggregate type")
+	ErrAmbiguousID       = errors.New("aggregate ID is used by multiple aggregate types")
+)
+
+// Reducer applies an event to the state of an aggregate and returns the new state.
+// The state is nil for the first event of the aggregate.
+type Reducer func(state any, event *Event) (any, error)
+
+// ReducerFor creates a [Reducer] for states of type T, e.g. a struct of the fields of a user relevant for an audit.
+// The reduce function is called with a new T for the first event.
+func ReducerFor[T any](reduce func(state *T, event *Event) error) Reducer {
+	return func(state any, event *Event) (any, error) {
+		s, ok := state.(*T)
+		if !ok {
+			s = new(T)
+		}
+		if err := reduce(s, event); err != nil {
+			return nil, err
+		}
+		return s, nil
+	}
+}
+
+// MergePayloads is a generic [Reducer] for any aggregate, merging the top-level fields of the payloads
+// of all events into a map[string]any. Fields of later events overwrite the ones of earlier events.
+// It's a good start to inspect an aggregate, a specific reducer is needed to interpret the events,
+// e.g. to track the removal of grants.
+func MergePayloads(state any, event *Event) (any, error) {
+	s, ok := state.(map[string]any)
+	if !ok {
+		s = make(map[string]any)
+	}
+	s = maps.Clone(s)
+	var payload map[string]any
+	if err := event.Decode(&payload); err != nil {
+		return nil, err
+	}
+	maps.Copy(s, payload)
+	return s, nil
+}
+
+// State is the state of an aggregate at a point in time.
+type State struct {
+	AggregateType string
+	AggregateID   string
+	ResourceOwner string
+	// Sequence is the sequence of the last event applied.
+	Sequence uint64
+	// ChangeDate is the creation date of the last event applied.
+	ChangeDate time.Time
+	// Removed is set if the last event applied removed the aggregate (event type ending with `.removed`).
+	Removed bool
+	// Value is the state returned by the [Reducer].
+	Value any
+}
+
+// Replayer reconstructs the historical states of aggregates by replaying their events with the registered reducers,
+// e.g. to answer "what did the grants of this user look like on June 1st?" in audits.
+// The client must be authorized to read the events of the instance (e.g. IAM_OWNER_VIEWER).
+type Replayer struct {
+	lister   eventLister
+	pageSize uint32
+	reducers map[string]Reducer
+}
+
+// ReplayOption allows customization of the [Replayer].
+type ReplayOption func(*Replayer)
+
+// WithReducer registers the reducer for the events of the aggregate type (e.g. `user`, `usergrant`, `project`).
+func WithReducer(aggregateType string, reducer Reducer) ReplayOption {
+	return func(r *Replayer) {
+		r.reducers[aggregateType] = reducer
+	}
+}
+
+// WithReplayPageSize sets the number of events requested at once (default 1000).
+func WithReplayPageSize(size uint32) ReplayOption {
+	return func(r *Replayer) {
+		r.pageSize = size
+	}
+}
+
+// NewReplayer creates a [Replayer] using the admin API of the client.
+func NewReplayer(client *client.Client, options ...ReplayOption) *Replayer {
+	return newReplayer(client.AdminService(), options...)
+}
+
+func newReplayer(lister eventLister, options ...ReplayOption) *Replayer {
+	r := &Replayer{
+		lister:   lister,
+		pageSize: defaultPageSize,
+		reducers: make(map[string]Reducer),
+	}
+	for _, option := range options {
+		option(r)
+	}
+	return r
+}
+
+// StateAt reconstructs the state of the aggregate at the time t by replaying its events created until t.
+// [ErrAggregateNotFound] is returned if the aggregate did not exist at that time.
+func (r *Replayer) StateAt(ctx context.Context, aggregateID string, t time.Time) (*State, error) {
+	var state *State
+	err := r.replay(ctx, aggregateID, t, func(e *Event) error {
+		if state == nil {
+			state = &State{AggregateType: e.AggregateType, AggregateID: e.AggregateID, ResourceOwner: e.ResourceOwner}
+		}
+		if e.AggregateType != state.AggregateType {
+			return fmt.Errorf("%w: %q is of types %q and %q", ErrAmbiguousID, aggregateID, state.AggregateType, e.AggregateType)
+		}
+		reducer, ok := r.reducers[e.AggregateType]
+		if !ok {
+			return fmt.Errorf("%w: %q", ErrNoReducer, e.AggregateType)
+		}
+		value, err := reducer(state.Value, e)
+		if err != nil {
+			return fmt.Errorf("reduce event %s (%s): %w", e.Key(), e.Type, err)
+		}
+		state.Value = value
+		state.Sequence = e.Sequence
+		state.ChangeDate = e.CreationDate
+		state.Removed = strings.HasSuffix(e.Type, ".removed")
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if state == nil {
+		return nil, fmt.Errorf("%w: %q at %s", ErrAggregateNotFound, aggregateID, t.Format(time.RFC3339))
+	}
+	return state, nil
+}
+
+// replay calls apply with the events of the aggregate created until t, ordered by their sequence.
+func (r *Replayer) replay(ctx context.Context, aggregateID string, t time.Time, apply func(e *Event) error) error {
+	var sequence uint64
+	for {
+		resp, err := r.lister.ListEvents(ctx, &admin.ListEventsRequest{
+			Asc:         true,
+			Limit:       r.pageSize,
+			AggregateId: aggregateID,
+			Sequence:    sequence,
+			CreationDateFilter: &admin.ListEventsRequest_Range{Range: &admin.ListEventsRequestCreationDateRange{
+				Until: timestamppb.New(t),
+			}},
+		})
+		if err != nil {
+			return err
+		}
+		for _, pbEvent := range resp.GetEvents() {
+			e, err := newEvent(pbEvent)
+			if err != nil {
+				return err
+			}
+			if e.CreationDate.After(t) {
+				return nil
+			}
+			if err = apply(e); err != nil {
+				return err
+			}
+			sequence = e.Sequence
+		}
+		if len(resp.GetEvents()) < int(r.pageSize) {
+			return nil
+		}
+	}
+}
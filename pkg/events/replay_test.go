@@ -0,0 +1,130 @@
+package events
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testGrant struct {
+	UserID string
+	Roles  []string
+}
+
+func reduceGrant(state *testGrant, event *Event) error {
+	var payload struct {
+		UserID   string   `json:"userId"`
+		RoleKeys []string `json:"roleKeys"`
+	}
+	if err := event.Decode(&payload); err != nil {
+		return err
+	}
+	switch event.Type {
+	case "user.grant.added":
+		state.UserID = payload.UserID
+		state.Roles = payload.RoleKeys
+	case "user.grant.changed":
+		state.Roles = payload.RoleKeys
+	case "user.grant.removed":
+		state.Roles = nil
+	}
+	return nil
+}
+
+func TestReplayer_StateAt(t *testing.T) {
+	june := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	lister := new(testLister)
+	lister.addEvent("usergrant", "grant1", "user.grant.added", 1, june.AddDate(0, -1, 0), map[string]any{"userId": "user1", "roleKeys": []any{"reader"}})
+	lister.addEvent("usergrant", "grant1", "user.grant.changed", 2, june.Add(-time.Hour), map[string]any{"roleKeys": []any{"reader", "writer"}})
+	lister.addEvent("usergrant", "grant1", "user.grant.removed", 3, june.AddDate(0, 1, 0), nil)
+	lister.add("user1", 1, june.AddDate(0, -1, 0), map[string]any{"userName": "gigi", "email": "gigi@example.com"})
+	lister.addEvent("user", "user1", "user.human.email.changed", 2, june.AddDate(0, 0, 1), map[string]any{"email": "gigi@zitadel.com"})
+	lister.addEvent("user", "user1", "user.human.added", 3, june.AddDate(0, 0, 1), nil)
+	lister.addEvent("org", "shared", "org.added", 1, june, nil)
+	lister.addEvent("user", "shared", "user.human.added", 2, june, nil)
+
+	replayer := newReplayer(lister,
+		WithReplayPageSize(1),
+		WithReducer("usergrant", ReducerFor(reduceGrant)),
+		WithReducer("user", MergePayloads),
+		WithReducer("org", MergePayloads),
+	)
+
+	tests := []struct {
+		name        string
+		aggregateID string
+		at          time.Time
+		want        *State
+		wantErr     error
+	}{
+		{
+			name:        "grant on June 1st",
+			aggregateID: "grant1",
+			at:          june,
+			want: &State{
+				AggregateType: "usergrant", AggregateID: "grant1", ResourceOwner: "org1",
+				Sequence: 2, ChangeDate: june.Add(-time.Hour),
+				Value: &testGrant{UserID: "user1", Roles: []string{"reader", "writer"}},
+			},
+		},
+		{
+			name:        "removed grant",
+			aggregateID: "grant1",
+			at:          june.AddDate(1, 0, 0),
+			want: &State{
+				AggregateType: "usergrant", AggregateID: "grant1", ResourceOwner: "org1",
+				Sequence: 3, ChangeDate: june.AddDate(0, 1, 0), Removed: true,
+				Value: &testGrant{UserID: "user1"},
+			},
+		},
+		{
+			name:        "merged payloads",
+			aggregateID: "user1",
+			at:          june,
+			want: &State{
+				AggregateType: "user", AggregateID: "user1", ResourceOwner: "org1",
+				Sequence: 1, ChangeDate: june.AddDate(0, -1, 0),
+				Value: map[string]any{"userName": "gigi", "email": "gigi@example.com"},
+			},
+		},
+		{
+			name:        "not yet created",
+			aggregateID: "grant1",
+			at:          june.AddDate(-1, 0, 0),
+			wantErr:     ErrAggregateNotFound,
+		},
+		{
+			name:        "ambiguous",
+			aggregateID: "shared",
+			at:          june,
+			wantErr:     ErrAmbiguousID,
+		},
+		{
+			name:        "later changes",
+			aggregateID: "user1",
+			at:          june.AddDate(1, 0, 0),
+			want: &State{
+				AggregateType: "user", AggregateID: "user1", ResourceOwner: "org1",
+				Sequence: 3, ChangeDate: june.AddDate(0, 0, 1),
+				Value: map[string]any{"userName": "gigi", "email": "gigi@zitadel.com"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := replayer.StateAt(context.Background(), tt.aggregateID, tt.at)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err := newReplayer(lister).StateAt(context.Background(), "grant1", june)
+	assert.ErrorIs(t, err, ErrNoReducer)
+}
@@ -0,0 +1,109 @@
+// Package envoy provides the Envoy external authorization (ext_authz) gRPC server for the [extauthz.Server].
+// It's a separate module, so only applications using Envoy depend on its go-control-plane API:
+//
+//	grpcServer := grpc.NewServer()
+//	envoy.Register(grpcServer, extauthz.New(routes))
+package envoy
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	authv3 "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
+	typev3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	rpcstatus "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/authorization"
+	"github.com/zitadel/zitadel-go/v3/pkg/extauthz"
+)
+
+// Register registers the [extauthz.Server] as Envoy ext_authz service on the gRPC server.
+// Paths not in their clean form are denied with 400 (see [extauthz.ErrUncleanPath]).
+func Register[T authorization.Ctx](server grpc.ServiceRegistrar, authz *extauthz.Server[T]) {
+	authv3.RegisterAuthorizationServer(server, &envoyServer[T]{server: authz})
+}
+
+type envoyServer[T authorization.Ctx] struct {
+	authv3.UnimplementedAuthorizationServer
+	server *extauthz.Server[T]
+}
+
+// Check implements [authv3.AuthorizationServer]
+func (e *envoyServer[T]) Check(ctx context.Context, req *authv3.CheckRequest) (*authv3.CheckResponse, error) {
+	return e.check(ctx, req), nil
+}
+
+func (e *envoyServer[T]) check(ctx context.Context, checkReq *authv3.CheckRequest) *authv3.CheckResponse {
+	req, err := envoyRequest(ctx, checkReq.GetAttributes().GetRequest().GetHttp())
+	if err != nil {
+		return deniedResponse(http.StatusBadRequest, codes.InvalidArgument, "", err.Error())
+	}
+	headers, denial := e.server.Authorize(req)
+	if denial != nil {
+		code := codes.PermissionDenied
+		if denial.Status == http.StatusUnauthorized {
+			code = codes.Unauthenticated
+		}
+		return deniedResponse(denial.Status, code, denial.Challenge, denial.Err.Error())
+	}
+	ok := new(authv3.OkHttpResponse)
+	for _, name := range e.server.HeaderNames() {
+		if headers == nil {
+			ok.HeadersToRemove = append(ok.HeadersToRemove, name)
+			continue
+		}
+		ok.Headers = append(ok.Headers, headerValue(name, headers.Get(name)))
+	}
+	return &authv3.CheckResponse{
+		Status:       &rpcstatus.Status{Code: int32(codes.OK)},
+		HttpResponse: &authv3.CheckResponse_OkResponse{OkResponse: ok},
+	}
+}
+
+// envoyRequest reconstructs the original request, the path contains the query as well.
+func envoyRequest(ctx context.Context, attributes *authv3.AttributeContext_HttpRequest) (*http.Request, error) {
+	u, err := extauthz.RequestURL(attributes.GetPath())
+	if err != nil {
+		return nil, err
+	}
+	u.Scheme = attributes.GetScheme()
+	u.Host = attributes.GetHost()
+	req, err := http.NewRequestWithContext(ctx, attributes.GetMethod(), u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range attributes.GetHeaders() {
+		// Envoy passes pseudo headers (e.g. `:authority`) as well
+		if strings.HasPrefix(key, ":") {
+			continue
+		}
+		req.Header.Set(key, value)
+	}
+	req.Host = attributes.GetHost()
+	return req, nil
+}
+
+func deniedResponse(status int, code codes.Code, challenge, message string) *authv3.CheckResponse {
+	denied := &authv3.DeniedHttpResponse{
+		Status: &typev3.HttpStatus{Code: typev3.StatusCode(status)},
+		Body:   message,
+	}
+	if challenge != "" {
+		denied.Headers = append(denied.Headers, headerValue("WWW-Authenticate", challenge))
+	}
+	return &authv3.CheckResponse{
+		Status:       &rpcstatus.Status{Code: int32(code), Message: message},
+		HttpResponse: &authv3.CheckResponse_DeniedResponse{DeniedResponse: denied},
+	}
+}
+
+func headerValue(key, value string) *corev3.HeaderValueOption {
+	return &corev3.HeaderValueOption{
+		Header:       &corev3.HeaderValue{Key: key, Value: value},
+		AppendAction: corev3.HeaderValueOption_OVERWRITE_IF_EXISTS_OR_ADD,
+	}
+}
@@ -0,0 +1,92 @@
+package envoy
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"testing"
+
+	authv3 "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/authorization"
+	"github.com/zitadel/zitadel-go/v3/pkg/extauthz"
+	"github.com/zitadel/zitadel-go/v3/pkg/http/middleware"
+	"github.com/zitadel/zitadel-go/v3/pkg/zitadel"
+)
+
+func newTestServer(t *testing.T, verifier *testVerifier) *envoyServer[*testCtx] {
+	authorizer, err := authorization.New[*testCtx](context.Background(), zitadel.New("zitadel.cloud"),
+		func(context.Context, *zitadel.Zitadel) (authorization.Verifier[*testCtx], error) {
+			return verifier, nil
+		},
+	)
+	require.NoError(t, err)
+	routes, err := middleware.New(authorizer).RouteTable([]middleware.Route{
+		{Pattern: "GET /public/", Public: true},
+		{Pattern: "/admin/", Roles: []string{"admin"}},
+	})
+	require.NoError(t, err)
+	return &envoyServer[*testCtx]{server: extauthz.New(routes)}
+}
+
+func TestServer_Check(t *testing.T) {
+	checkRequest := func(path, token string) *authv3.CheckRequest {
+		headers := map[string]string{":authority": "api.example.com"}
+		if token != "" {
+			headers[authorization.HeaderName] = token
+		}
+		return &authv3.CheckRequest{Attributes: &authv3.AttributeContext{Request: &authv3.AttributeContext_Request{
+			Http: &authv3.AttributeContext_HttpRequest{Method: http.MethodGet, Scheme: "https", Host: "api.example.com", Path: path, Headers: headers},
+		}}}
+	}
+	t.Run("public", func(t *testing.T) {
+		resp := newTestServer(t, &testVerifier{}).check(context.Background(), checkRequest("/public/info", ""))
+		assert.Equal(t, int32(codes.OK), resp.GetStatus().GetCode())
+		assert.ElementsMatch(t, []string{extauthz.HeaderUserID, extauthz.HeaderOrganizationID}, resp.GetOkResponse().GetHeadersToRemove())
+	})
+	t.Run("forbidden", func(t *testing.T) {
+		resp := newTestServer(t, &testVerifier{ctx: &testCtx{}}).check(context.Background(), checkRequest("/admin/users", "Bearer token"))
+		assert.Equal(t, int32(codes.PermissionDenied), resp.GetStatus().GetCode())
+		assert.EqualValues(t, http.StatusForbidden, resp.GetDeniedResponse().GetStatus().GetCode())
+		require.Len(t, resp.GetDeniedResponse().GetHeaders(), 1)
+		assert.Equal(t, "WWW-Authenticate", resp.GetDeniedResponse().GetHeaders()[0].GetHeader().GetKey())
+	})
+	t.Run("unclean path", func(t *testing.T) {
+		resp := newTestServer(t, &testVerifier{ctx: &testCtx{}}).check(context.Background(), checkRequest("//admin/users", "Bearer token"))
+		assert.Equal(t, int32(codes.InvalidArgument), resp.GetStatus().GetCode())
+		assert.EqualValues(t, http.StatusBadRequest, resp.GetDeniedResponse().GetStatus().GetCode())
+		assert.Empty(t, resp.GetDeniedResponse().GetHeaders(), "no WWW-Authenticate header")
+		assert.Empty(t, resp.GetOkResponse().GetHeaders())
+	})
+	t.Run("authorized", func(t *testing.T) {
+		resp := newTestServer(t, &testVerifier{ctx: &testCtx{roles: []string{"admin"}}}).check(context.Background(), checkRequest("/admin/users?page=2", "Bearer token"))
+		assert.Equal(t, int32(codes.OK), resp.GetStatus().GetCode())
+		require.Len(t, resp.GetOkResponse().GetHeaders(), 2)
+		assert.Equal(t, "user", resp.GetOkResponse().GetHeaders()[0].GetHeader().GetValue())
+	})
+}
+
+type testVerifier struct {
+	ctx *testCtx
+	err error
+}
+
+func (t *testVerifier) CheckAuthorization(context.Context, string) (*testCtx, error) {
+	return t.ctx, t.err
+}
+
+type testCtx struct {
+	roles []string
+	token string
+}
+
+func (t *testCtx) IsAuthorized() bool                           { return t != nil }
+func (t *testCtx) OrganizationID() string                       { return "org" }
+func (t *testCtx) UserID() string                               { return "user" }
+func (t *testCtx) IsGrantedRole(role string) bool               { return slices.Contains(t.roles, role) }
+func (t *testCtx) IsGrantedRoleInOrganization(_, _ string) bool { return false }
+func (t *testCtx) SetToken(token string)                        { t.token = token }
+func (t *testCtx) GetToken() string                             { return t.token }
@@ -0,0 +1,117 @@
+// Package extauthz provides authorization servers for API gateways, so they can offload the verification
+// of ZITADEL tokens and the role checks: a forward auth HTTP handler (e.g. Traefik ForwardAuth, Caddy forward_auth,
+// NGINX auth_request) and, in the separate envoy module, an Envoy external authorization (ext_authz) gRPC server.
+//
+// The requirements per route are defined using the [middleware.RouteTable] of the http/middleware package.
+// Authorized requests are forwarded to the upstream with the identity of the user in the [HeaderUserID]
+// and [HeaderOrganizationID] headers.
+package extauthz
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/authorization"
+	"github.com/zitadel/zitadel-go/v3/pkg/http/middleware"
+)
+
+const (
+	// HeaderUserID carries the ID of the authorized user to the upstream.
+	HeaderUserID = "X-Zitadel-User-Id"
+	// HeaderOrganizationID carries the ID of the organization of the authorized user to the upstream.
+	HeaderOrganizationID = "X-Zitadel-Org-Id"
+)
+
+// ErrUncleanPath is returned for paths not in their clean form (e.g. `//admin`, `/x/../admin` or `/admin/%2e%2e/x`),
+// which upstreams might normalize differently, so they are rejected instead of being authorized.
+var ErrUncleanPath = middleware.ErrUncleanPath
+
+// Server authorizes the requests of an API gateway.
+type Server[T authorization.Ctx] struct {
+	routes  *middleware.RouteTable[T]
+	headers []header[T]
+}
+
+type header[T authorization.Ctx] struct {
+	name  string
+	value func(authCtx T) string
+}
+
+// Option allows customization of the [Server].
+type Option[T authorization.Ctx] func(*Server[T])
+
+// WithHeader adds a header passed to the upstream for authorized requests,
+// e.g. the email of the user. On public routes, the header is removed.
+func WithHeader[T authorization.Ctx](name string, value func(authCtx T) string) Option[T] {
+	return func(s *Server[T]) {
+		s.headers = append(s.headers, header[T]{name: name, value: value})
+	}
+}
+
+// New creates a [Server] enforcing the authorization of the routes.
+func New[T authorization.Ctx](routes *middleware.RouteTable[T], options ...Option[T]) *Server[T] {
+	s := &Server[T]{
+		routes: routes,
+		headers: []header[T]{
+			{name: HeaderUserID, value: func(authCtx T) string { return authCtx.UserID() }},
+			{name: HeaderOrganizationID, value: func(authCtx T) string { return authCtx.OrganizationID() }},
+		},
+	}
+	for _, option := range options {
+		option(s)
+	}
+	return s
+}
+
+// Authorize authorizes the original request of the gateway, e.g. for the integrations of other gateways.
+// It returns the headers for the upstream, which are nil for public routes.
+// In that case, the headers of [Server.HeaderNames] must be removed from the request to the upstream,
+// so clients can't set them.
+func (s *Server[T]) Authorize(req *http.Request) (http.Header, *middleware.Denial) {
+	authCtx, denial := s.routes.Authorize(req)
+	if denial != nil {
+		return nil, denial
+	}
+	if !isAuthorized(authCtx) {
+		return nil, nil
+	}
+	headers := make(http.Header, len(s.headers))
+	for _, h := range s.headers {
+		headers.Set(h.name, h.value(authCtx))
+	}
+	return headers, nil
+}
+
+// HeaderNames returns the names of the headers passed to the upstream.
+func (s *Server[T]) HeaderNames() []string {
+	names := make([]string, len(s.headers))
+	for i, h := range s.headers {
+		names[i] = h.name
+	}
+	return names
+}
+
+// isAuthorized checks if the context is set, which is not the case for public routes.
+func isAuthorized[T authorization.Ctx](authCtx T) bool {
+	return any(authCtx) != nil && authCtx.IsAuthorized()
+}
+
+// RequestURL parses the request URI (path and query) of the original request,
+// rejecting paths not in their clean form with [ErrUncleanPath].
+func RequestURL(uri string) (*url.URL, error) {
+	u, err := url.ParseRequestURI(uri)
+	if err != nil {
+		return nil, err
+	}
+	cleaned := path.Clean(u.Path)
+	if strings.HasSuffix(u.Path, "/") && cleaned != "/" {
+		cleaned += "/"
+	}
+	if u.Path != cleaned {
+		return nil, fmt.Errorf("%w: %q", ErrUncleanPath, u.Path)
+	}
+	return u, nil
+}
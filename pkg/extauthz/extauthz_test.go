@@ -0,0 +1,150 @@
+package extauthz
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/authorization"
+	"github.com/zitadel/zitadel-go/v3/pkg/http/middleware"
+	"github.com/zitadel/zitadel-go/v3/pkg/zitadel"
+)
+
+func newTestServer(t *testing.T, verifier *testVerifier) *Server[*testCtx] {
+	authorizer, err := authorization.New[*testCtx](context.Background(), zitadel.New("zitadel.cloud"),
+		func(context.Context, *zitadel.Zitadel) (authorization.Verifier[*testCtx], error) {
+			return verifier, nil
+		},
+	)
+	require.NoError(t, err)
+	routes, err := middleware.New(authorizer).RouteTable([]middleware.Route{
+		{Pattern: "GET /public/", Public: true},
+		{Pattern: "/admin/", Roles: []string{"admin"}},
+	})
+	require.NoError(t, err)
+	return New(routes)
+}
+
+func TestServer_ForwardAuth(t *testing.T) {
+	tests := []struct {
+		name       string
+		verifier   *testVerifier
+		uri        string
+		token      string
+		wantStatus int
+		// wantChallenge is whether the `WWW-Authenticate` header is sent
+		wantChallenge bool
+		wantUserID    string
+	}{
+		{
+			name:       "public",
+			verifier:   &testVerifier{err: errors.New("not called")},
+			uri:        "/public/info?a=b",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:          "unauthorized",
+			verifier:      &testVerifier{err: errors.New("expired")},
+			uri:           "/api",
+			token:         "Bearer token",
+			wantStatus:    http.StatusUnauthorized,
+			wantChallenge: true,
+		},
+		{
+			name:          "forbidden",
+			verifier:      &testVerifier{ctx: &testCtx{}},
+			uri:           "/admin/users",
+			token:         "Bearer token",
+			wantStatus:    http.StatusForbidden,
+			wantChallenge: true,
+		},
+		{
+			name:       "unclean path",
+			verifier:   &testVerifier{ctx: &testCtx{}},
+			uri:        "//admin/users",
+			token:      "Bearer token",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "dot segments",
+			verifier:   &testVerifier{ctx: &testCtx{}},
+			uri:        "/public/../admin/users",
+			token:      "Bearer token",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "encoded dot segments",
+			verifier:   &testVerifier{ctx: &testCtx{}},
+			uri:        "/public/%2e%2e/admin/users",
+			token:      "Bearer token",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "authorized",
+			verifier:   &testVerifier{ctx: &testCtx{roles: []string{"admin"}}},
+			uri:        "/admin/users",
+			token:      "Bearer token",
+			wantStatus: http.StatusOK,
+			wantUserID: "user",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/auth", nil)
+			req.Header.Set("X-Forwarded-Method", http.MethodGet)
+			req.Header.Set("X-Forwarded-Proto", "https")
+			req.Header.Set("X-Forwarded-Host", "api.example.com")
+			req.Header.Set("X-Forwarded-Uri", tt.uri)
+			if tt.token != "" {
+				req.Header.Set(authorization.HeaderName, tt.token)
+			}
+			rec := httptest.NewRecorder()
+			newTestServer(t, tt.verifier).ForwardAuth().ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			_, challenge := rec.Header()["Www-Authenticate"]
+			assert.Equal(t, tt.wantChallenge, challenge)
+			assert.Equal(t, tt.wantUserID, rec.Header().Get(HeaderUserID))
+		})
+	}
+}
+
+func TestServer_ForwardAuth_nginx(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/auth", nil)
+	req.Header.Set("X-Original-Method", http.MethodDelete)
+	req.Header.Set("X-Original-URI", "/admin/users/1?force=true")
+	req.Header.Set(authorization.HeaderName, "Bearer token")
+	rec := httptest.NewRecorder()
+	newTestServer(t, &testVerifier{ctx: &testCtx{}}).ForwardAuth().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Contains(t, rec.Header().Get("WWW-Authenticate"), "insufficient_scope")
+}
+
+type testVerifier struct {
+	ctx *testCtx
+	err error
+}
+
+func (t *testVerifier) CheckAuthorization(context.Context, string) (*testCtx, error) {
+	return t.ctx, t.err
+}
+
+type testCtx struct {
+	roles []string
+	token string
+}
+
+func (t *testCtx) IsAuthorized() bool                           { return t != nil }
+func (t *testCtx) OrganizationID() string                       { return "org" }
+func (t *testCtx) UserID() string                               { return "user" }
+func (t *testCtx) IsGrantedRole(role string) bool               { return slices.Contains(t.roles, role) }
+func (t *testCtx) IsGrantedRoleInOrganization(_, _ string) bool { return false }
+func (t *testCtx) SetToken(token string)                        { t.token = token }
+func (t *testCtx) GetToken() string                             { return t.token }
@@ -0,0 +1,80 @@
+package extauthz
+
+import (
+	"net/http"
+)
+
+// ForwardAuth returns a handler for forward authentication of a gateway or reverse proxy,
+// e.g. Traefik ForwardAuth, Caddy forward_auth or the NGINX auth_request module.
+// The original request is reconstructed from the `X-Forwarded-Method`, `X-Forwarded-Proto`, `X-Forwarded-Host`
+// and `X-Forwarded-Uri` headers, NGINX has to set them or `X-Original-Method` and `X-Original-URI`, e.g.:
+//
+//	location = /auth {
+//	    internal;
+//	    proxy_pass http://authz:8080/auth;
+//	    proxy_pass_request_body off;
+//	    proxy_set_header X-Original-Method $request_method;
+//	    proxy_set_header X-Original-URI $request_uri;
+//	}
+//
+// Paths not in their clean form are rejected with 400 (see [ErrUncleanPath]).
+// Authorized requests are answered with 200 and the headers for the upstream, which must be configured to be copied
+// (Traefik `authResponseHeaders`, Caddy `copy_headers`, NGINX `auth_request_set`),
+// otherwise with 401 / 403 including the `WWW-Authenticate` header (see [middleware.Denial]).
+func (s *Server[T]) ForwardAuth() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req, err := forwardedRequest(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		headers, denial := s.Authorize(req)
+		if denial != nil {
+			if denial.Challenge != "" {
+				w.Header().Set("WWW-Authenticate", denial.Challenge)
+			}
+			http.Error(w, denial.Err.Error(), denial.Status)
+			return
+		}
+		for name, values := range headers {
+			w.Header()[name] = values
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+func forwardedRequest(r *http.Request) (*http.Request, error) {
+	method := firstHeader(r.Header, "X-Forwarded-Method", "X-Original-Method")
+	if method == "" {
+		method = r.Method
+	}
+	uri := firstHeader(r.Header, "X-Forwarded-Uri", "X-Original-URI")
+	if uri == "" {
+		uri = r.URL.RequestURI()
+	}
+	u, err := RequestURL(uri)
+	if err != nil {
+		return nil, err
+	}
+	u.Scheme = r.Header.Get("X-Forwarded-Proto")
+	u.Host = firstHeader(r.Header, "X-Forwarded-Host", "Host")
+	if u.Host == "" {
+		u.Host = r.Host
+	}
+	req, err := http.NewRequestWithContext(r.Context(), method, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header = r.Header.Clone()
+	req.Host = u.Host
+	return req, nil
+}
+
+func firstHeader(header http.Header, keys ...string) string {
+	for _, key := range keys {
+		if value := header.Get(key); value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,285 @@
+// Package features manages the feature flags of ZITADEL (feature service v2) on the instance and organization level:
+//
+//	c, err := features.New(ctx, zitadel.New(domain), client.DefaultServiceUserAuthentication(keyPath, scopes...))
+//	err = c.EnableLoginV2(ctx, "https://login.example.com/ui/v2/login")
+//	effective, err := c.ResolveEffective(ctx)
+//	if effective.UserSchema.IsEnabled() { ... }
+//
+// The client calls the HTTP/JSON endpoints of the feature service using the client of the resources package
+// and provides the features as plain Go structs including the level they're set on.
+// For the complete API, the generated gRPC client of the feature service (pkg/client/zitadel/feature) can be used.
+// Errors are returned as gRPC status errors, so status.Code(err) works as with the other services.
+package features
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client"
+	"github.com/zitadel/zitadel-go/v3/pkg/resources"
+	"github.com/zitadel/zitadel-go/v3/pkg/zitadel"
+)
+
+const (
+	// BasePath of the feature service.
+	BasePath = "/v2/features"
+
+	instancePath     = "/instance"
+	organizationPath = "/organization/"
+)
+
+var ErrMissingOrgID = errors.New("organization ID is required")
+
+// Source is the level a feature flag is set on.
+type Source string
+
+const (
+	SourceUnspecified  Source = "SOURCE_UNSPECIFIED"
+	SourceSystem       Source = "SOURCE_SYSTEM"
+	SourceInstance     Source = "SOURCE_INSTANCE"
+	SourceOrganization Source = "SOURCE_ORGANIZATION"
+	SourceProject      Source = "SOURCE_PROJECT"
+	SourceApp          Source = "SOURCE_APP"
+	SourceUser         Source = "SOURCE_USER"
+)
+
+// Flag is the state of a boolean feature and the level it's set on.
+type Flag struct {
+	Enabled bool   `json:"enabled"`
+	Source  Source `json:"source,omitempty"`
+}
+
+// IsEnabled returns if the flag is set and enabled.
+func (f *Flag) IsEnabled() bool {
+	return f != nil && f.Enabled
+}
+
+// LoginV2 is the state of the login v2 feature.
+type LoginV2 struct {
+	// Required redirects the users to the login v2 instead of the login v1.
+	Required bool `json:"required"`
+	// BaseURI of the login v2, the one served by ZITADEL if empty.
+	BaseURI string `json:"baseUri,omitempty"`
+	Source  Source `json:"source,omitempty"`
+}
+
+// IsEnabled returns if the feature is set and required.
+func (l *LoginV2) IsEnabled() bool {
+	return l != nil && l.Required
+}
+
+// Features are the feature flags of a level. Features not returned by the server (e.g. unknown to the server
+// or not set on the level without inheritance) are nil.
+type Features struct {
+	LoginDefaultOrg                     *Flag    `json:"loginDefaultOrg,omitempty"`
+	OIDCTriggerIntrospectionProjections *Flag    `json:"oidcTriggerIntrospectionProjections,omitempty"`
+	OIDCLegacyIntrospection             *Flag    `json:"oidcLegacyIntrospection,omitempty"`
+	UserSchema                          *Flag    `json:"userSchema,omitempty"`
+	OIDCTokenExchange                   *Flag    `json:"oidcTokenExchange,omitempty"`
+	Actions                             *Flag    `json:"actions,omitempty"`
+	WebKey                              *Flag    `json:"webKey,omitempty"`
+	DebugOIDCParentError                *Flag    `json:"debugOidcParentError,omitempty"`
+	OIDCSingleV1SessionTermination      *Flag    `json:"oidcSingleV1SessionTermination,omitempty"`
+	DisableUserTokenEvent               *Flag    `json:"disableUserTokenEvent,omitempty"`
+	EnableBackChannelLogout             *Flag    `json:"enableBackChannelLogout,omitempty"`
+	LoginV2                             *LoginV2 `json:"loginV2,omitempty"`
+	PermissionCheckV2                   *Flag    `json:"permissionCheckV2,omitempty"`
+	ConsoleUseV2UserAPI                 *Flag    `json:"consoleUseV2UserApi,omitempty"`
+}
+
+// merge overrides the features of f with the ones set in override.
+func (f *Features) merge(override *Features) {
+	flags, overrides := f.flags(), override.flags()
+	for i, flag := range overrides {
+		if *flag != nil {
+			*flags[i] = *flag
+		}
+	}
+	if override.LoginV2 != nil {
+		f.LoginV2 = override.LoginV2
+	}
+}
+
+func (f *Features) flags() []**Flag {
+	return []**Flag{
+		&f.LoginDefaultOrg,
+		&f.OIDCTriggerIntrospectionProjections,
+		&f.OIDCLegacyIntrospection,
+		&f.UserSchema,
+		&f.OIDCTokenExchange,
+		&f.Actions,
+		&f.WebKey,
+		&f.DebugOIDCParentError,
+		&f.OIDCSingleV1SessionTermination,
+		&f.DisableUserTokenEvent,
+		&f.EnableBackChannelLogout,
+		&f.PermissionCheckV2,
+		&f.ConsoleUseV2UserAPI,
+	}
+}
+
+// Name is the name of a boolean feature in the API, see [Client.SetInstanceFeature].
+type Name string
+
+const (
+	NameLoginDefaultOrg                     Name = "loginDefaultOrg"
+	NameOIDCTriggerIntrospectionProjections Name = "oidcTriggerIntrospectionProjections"
+	NameOIDCLegacyIntrospection             Name = "oidcLegacyIntrospection"
+	NameUserSchema                          Name = "userSchema"
+	NameOIDCTokenExchange                   Name = "oidcTokenExchange"
+	NameActions                             Name = "actions"
+	NameWebKey                              Name = "webKey"
+	NameDebugOIDCParentError                Name = "debugOidcParentError"
+	NameOIDCSingleV1SessionTermination      Name = "oidcSingleV1SessionTermination"
+	NameDisableUserTokenEvent               Name = "disableUserTokenEvent"
+	NameEnableBackChannelLogout             Name = "enableBackChannelLogout"
+	NamePermissionCheckV2                   Name = "permissionCheckV2"
+	NameConsoleUseV2UserAPI                 Name = "consoleUseV2UserApi"
+)
+
+// Client calls the feature service of ZITADEL.
+type Client struct {
+	resources *resources.Client
+	orgID     string
+}
+
+// Option allows customization of the [Client].
+type Option func(*options)
+
+type options struct {
+	httpClient *http.Client
+	orgID      string
+}
+
+// WithHTTPClient sets the [http.Client] used for the token and feature requests,
+// e.g. with a custom transport or timeout.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(o *options) {
+		o.httpClient = httpClient
+	}
+}
+
+// WithOrganization resolves the effective features (see [Client.ResolveEffective]) for the organization
+// instead of the instance.
+func WithOrganization(orgID string) Option {
+	return func(o *options) {
+		o.orgID = orgID
+	}
+}
+
+// New creates a [Client] for the feature service of the instance.
+func New(ctx context.Context, zitadel *zitadel.Zitadel, auth client.TokenSourceInitializer, opts ...Option) (*Client, error) {
+	o := &options{httpClient: http.DefaultClient}
+	for _, opt := range opts {
+		opt(o)
+	}
+	c, err := resources.New(ctx, zitadel, auth, resources.WithHTTPClient(o.httpClient), resources.WithBasePath(BasePath))
+	if err != nil {
+		return nil, err
+	}
+	return &Client{resources: c, orgID: o.orgID}, nil
+}
+
+// Instance returns the features set on the instance, including the ones inherited from the system if inheritance is set.
+func (c *Client) Instance(ctx context.Context, inheritance bool) (*Features, error) {
+	features := new(Features)
+	if err := c.resources.Do(ctx, http.MethodGet, instancePath+inheritanceQuery(inheritance), nil, features); err != nil {
+		return nil, err
+	}
+	return features, nil
+}
+
+// Organization returns the features set on the organization, including the inherited ones if inheritance is set.
+func (c *Client) Organization(ctx context.Context, orgID string, inheritance bool) (*Features, error) {
+	if orgID == "" {
+		return nil, ErrMissingOrgID
+	}
+	features := new(Features)
+	if err := c.resources.Do(ctx, http.MethodGet, organizationPath+url.PathEscape(orgID)+inheritanceQuery(inheritance), nil, features); err != nil {
+		return nil, err
+	}
+	return features, nil
+}
+
+// ResolveEffective returns the effective features: the ones of the instance (inheriting the ones of the system),
+// overridden by the ones set on the organization if the client was created [WithOrganization].
+// The Source of each flag tells the level it's set on.
+func (c *Client) ResolveEffective(ctx context.Context) (*Features, error) {
+	features, err := c.Instance(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+	if c.orgID == "" {
+		return features, nil
+	}
+	org, err := c.Organization(ctx, c.orgID, false)
+	if err != nil {
+		return nil, err
+	}
+	features.merge(org)
+	return features, nil
+}
+
+// SetInstanceFeature enables or disables the boolean feature on the instance.
+func (c *Client) SetInstanceFeature(ctx context.Context, name Name, enabled bool) error {
+	return c.resources.Do(ctx, http.MethodPut, instancePath, map[Name]bool{name: enabled}, nil)
+}
+
+// SetUserSchemaFeature enables or disables the user schemas (see the userschema package) on the instance.
+func (c *Client) SetUserSchemaFeature(ctx context.Context, enabled bool) error {
+	return c.SetInstanceFeature(ctx, NameUserSchema, enabled)
+}
+
+// SetActionsFeature enables or disables the actions v2 (see the actions package) on the instance.
+func (c *Client) SetActionsFeature(ctx context.Context, enabled bool) error {
+	return c.SetInstanceFeature(ctx, NameActions, enabled)
+}
+
+// SetOIDCTokenExchangeFeature enables or disables the OAuth 2.0 token exchange on the instance.
+func (c *Client) SetOIDCTokenExchangeFeature(ctx context.Context, enabled bool) error {
+	return c.SetInstanceFeature(ctx, NameOIDCTokenExchange, enabled)
+}
+
+// SetWebKeyFeature enables or disables the web keys on the instance.
+func (c *Client) SetWebKeyFeature(ctx context.Context, enabled bool) error {
+	return c.SetInstanceFeature(ctx, NameWebKey, enabled)
+}
+
+// SetPermissionCheckV2Feature enables or disables the permission checks v2 on the instance.
+func (c *Client) SetPermissionCheckV2Feature(ctx context.Context, enabled bool) error {
+	return c.SetInstanceFeature(ctx, NamePermissionCheckV2, enabled)
+}
+
+// EnableLoginV2 requires the login v2 on the instance, served at the baseURI (the one served by ZITADEL if empty).
+func (c *Client) EnableLoginV2(ctx context.Context, baseURI string) error {
+	return c.setLoginV2(ctx, &LoginV2{Required: true, BaseURI: baseURI})
+}
+
+// DisableLoginV2 uses the login v1 on the instance again.
+func (c *Client) DisableLoginV2(ctx context.Context) error {
+	return c.setLoginV2(ctx, &LoginV2{})
+}
+
+func (c *Client) setLoginV2(ctx context.Context, loginV2 *LoginV2) error {
+	return c.resources.Do(ctx, http.MethodPut, instancePath, map[string]*LoginV2{"loginV2": loginV2}, nil)
+}
+
+// ResetInstance removes the features set on the instance, so the ones of the system apply.
+func (c *Client) ResetInstance(ctx context.Context) error {
+	return c.resources.Do(ctx, http.MethodDelete, instancePath, nil, nil)
+}
+
+// ResetOrganization removes the features set on the organization, so the ones of the instance apply.
+func (c *Client) ResetOrganization(ctx context.Context, orgID string) error {
+	if orgID == "" {
+		return ErrMissingOrgID
+	}
+	return c.resources.Do(ctx, http.MethodDelete, organizationPath+url.PathEscape(orgID), nil, nil)
+}
+
+func inheritanceQuery(inheritance bool) string {
+	return "?inheritance=" + strconv.FormatBool(inheritance)
+}
@@ -0,0 +1,120 @@
+package features
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client"
+	"github.com/zitadel/zitadel-go/v3/pkg/zitadel"
+)
+
+type request struct {
+	method string
+	path   string
+	body   string
+}
+
+func newTestClient(t *testing.T, responses map[string]string, opts ...Option) (*Client, *[]request) {
+	t.Helper()
+	var requests []request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		requests = append(requests, request{r.Method, r.URL.RequestURI(), string(body)})
+		resp, ok := responses[r.Method+" "+r.URL.RequestURI()]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"code":5,"message":"not found"}`))
+			return
+		}
+		_, _ = w.Write([]byte(resp))
+	}))
+	t.Cleanup(server.Close)
+	host, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	require.NoError(t, err)
+	c, err := New(context.Background(), zitadel.New(host, zitadel.WithInsecure(port)), client.PAT("token"), opts...)
+	require.NoError(t, err)
+	return c, &requests
+}
+
+func TestClient_ResolveEffective(t *testing.T) {
+	responses := map[string]string{
+		"GET /v2/features/instance?inheritance=true": `{
+			"details": {"sequence": "1"},
+			"userSchema": {"enabled": true, "source": "SOURCE_INSTANCE"},
+			"actions": {"enabled": false, "source": "SOURCE_SYSTEM"},
+			"loginV2": {"required": true, "baseUri": "https://login.example.com", "source": "SOURCE_INSTANCE"},
+			"unknownFeature": {"enabled": true}
+		}`,
+		"GET /v2/features/organization/org1?inheritance=false": `{"actions": {"enabled": true, "source": "SOURCE_ORGANIZATION"}}`,
+	}
+	tests := []struct {
+		name string
+		opts []Option
+		want *Features
+	}{
+		{
+			name: "instance",
+			want: &Features{
+				UserSchema: &Flag{Enabled: true, Source: SourceInstance},
+				Actions:    &Flag{Enabled: false, Source: SourceSystem},
+				LoginV2:    &LoginV2{Required: true, BaseURI: "https://login.example.com", Source: SourceInstance},
+			},
+		},
+		{
+			name: "organization",
+			opts: []Option{WithOrganization("org1")},
+			want: &Features{
+				UserSchema: &Flag{Enabled: true, Source: SourceInstance},
+				Actions:    &Flag{Enabled: true, Source: SourceOrganization},
+				LoginV2:    &LoginV2{Required: true, BaseURI: "https://login.example.com", Source: SourceInstance},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := newTestClient(t, responses, tt.opts...)
+			got, err := c.ResolveEffective(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.True(t, got.UserSchema.IsEnabled())
+			assert.False(t, got.WebKey.IsEnabled())
+		})
+	}
+}
+
+func TestClient_Set(t *testing.T) {
+	c, requests := newTestClient(t, map[string]string{
+		"PUT /v2/features/instance":             `{}`,
+		"DELETE /v2/features/organization/org1": `{}`,
+	})
+	ctx := context.Background()
+	require.NoError(t, c.SetUserSchemaFeature(ctx, true))
+	require.NoError(t, c.EnableLoginV2(ctx, "https://login.example.com"))
+	require.NoError(t, c.DisableLoginV2(ctx))
+	require.NoError(t, c.ResetOrganization(ctx, "org1"))
+	assert.ErrorIs(t, c.ResetOrganization(ctx, ""), ErrMissingOrgID)
+
+	bodies := make([]map[string]any, 3)
+	for i := range bodies {
+		assert.Equal(t, "PUT", (*requests)[i].method)
+		require.NoError(t, json.Unmarshal([]byte((*requests)[i].body), &bodies[i]))
+	}
+	assert.Equal(t, map[string]any{"userSchema": true}, bodies[0])
+	assert.Equal(t, map[string]any{"loginV2": map[string]any{"required": true, "baseUri": "https://login.example.com"}}, bodies[1])
+	assert.Equal(t, map[string]any{"loginV2": map[string]any{"required": false}}, bodies[2])
+	assert.Equal(t, request{"DELETE", "/v2/features/organization/org1", ""}, (*requests)[3])
+
+	err := c.ResetInstance(ctx)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
@@ -0,0 +1,68 @@
+package gateway
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/introspection"
+)
+
+// Cache stores the introspection results, e.g. in memory ([MemoryCache]) or a shared backend such as Redis,
+// so the replicas of a gateway share the results.
+type Cache interface {
+	// Get returns the value of the key, false if there is none or it expired.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Set stores the value of the key for the ttl.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+}
+
+// MemoryCache is an in-memory [Cache] with a maximum number of entries,
+// the [introspection.MemoryCache] also used by the [introspection.Introspector].
+type MemoryCache = introspection.MemoryCache[[]byte]
+
+// NewMemoryCache creates a [MemoryCache] holding up to size entries.
+func NewMemoryCache(size int) *MemoryCache {
+	return introspection.NewMemoryCache[[]byte](size)
+}
+
+// flightGroup ensures only one introspection per token is in flight,
+// concurrent requests with the same token wait for its result (stampede protection).
+type flightGroup struct {
+	mu      sync.Mutex
+	flights map[string]*flight
+}
+
+type flight struct {
+	done   chan struct{}
+	result *result
+	err    error
+}
+
+// do calls fn for the key, unless a call for the key is in flight, whose result is returned instead.
+// Waiting is aborted if the context is done.
+func (g *flightGroup) do(ctx context.Context, key string, fn func() (*result, error)) (*result, error) {
+	g.mu.Lock()
+	if g.flights == nil {
+		g.flights = make(map[string]*flight)
+	}
+	if f, ok := g.flights[key]; ok {
+		g.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-f.done:
+			return f.result, f.err
+		}
+	}
+	f := &flight{done: make(chan struct{})}
+	g.flights[key] = f
+	g.mu.Unlock()
+
+	f.result, f.err = fn()
+	close(f.done)
+	g.mu.Lock()
+	delete(g.flights, key)
+	g.mu.Unlock()
+	return f.result, f.err
+}
@@ -0,0 +1,337 @@
+// Package gateway provides a ready-made interceptor bundle for high-QPS internal gRPC gateways:
+// it validates the access token of every call using the token introspection of ZITADEL,
+// caches the results in a pluggable [Cache] with stampede protection (one introspection per token in flight),
+// and enriches the metadata of the call with the user info (e.g. [HeaderUserID]) for the upstream services:
+//
+//	introspector, err := introspection.New(ctx, z, oauth.JWTProfileIntrospectionAuthentication(keyFile),
+//		introspection.WithMaxTTL(0), // the results are cached by the gateway
+//	)
+//	gw := gateway.New(introspector, gateway.WithCache(redisCache), gateway.WithPublicMethods("/grpc.health.v1.Health/Check"))
+//	server := grpc.NewServer(grpc.ChainUnaryInterceptor(gw.Unary()), grpc.ChainStreamInterceptor(gw.Stream()))
+//
+// The user info headers sent by the caller are always removed, so they can't be spoofed.
+// Only bearer tokens are supported: DPoP-bound tokens (with a `cnf.jkt` claim) are rejected, as their proof is not verified.
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/zitadel/oidc/v3/pkg/oidc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/authorization"
+	"github.com/zitadel/zitadel-go/v3/pkg/dpop"
+	"github.com/zitadel/zitadel-go/v3/pkg/introspection"
+)
+
+const (
+	// HeaderUserID, HeaderOrganizationID, HeaderUsername, HeaderClientID and HeaderRoles
+	// are set by the default [MetadataFunc].
+	HeaderUserID         = "x-zitadel-user-id"
+	HeaderOrganizationID = "x-zitadel-org-id"
+	HeaderUsername       = "x-zitadel-username"
+	HeaderClientID       = "x-zitadel-client-id"
+	HeaderRoles          = "x-zitadel-roles"
+
+	// DefaultMaxTTL is the max duration an active result is cached, even if the token is valid longer.
+	DefaultMaxTTL = time.Minute
+	// DefaultCacheSize is the number of results cached by the default [MemoryCache].
+	DefaultCacheSize = 100000
+	// DefaultIntrospectionTimeout limits the duration of an introspection.
+	DefaultIntrospectionTimeout = 10 * time.Second
+
+	claimResourceOwnerID = "urn:zitadel:iam:user:resourceowner:id"
+	claimRoles           = "urn:zitadel:iam:org:project:roles"
+)
+
+// Introspector introspects the tokens, e.g. the [introspection.Introspector].
+type Introspector interface {
+	Introspect(ctx context.Context, token string) (*introspection.Claims, error)
+}
+
+// MetadataFunc returns the metadata the call is enriched with for the claims of the token.
+type MetadataFunc func(claims *introspection.Claims) metadata.MD
+
+// Gateway validates the tokens of the calls, see the package documentation.
+type Gateway struct {
+	introspector         Introspector
+	cache                Cache
+	maxTTL               time.Duration
+	inactiveTTL          time.Duration
+	introspectionTimeout time.Duration
+	publicMethods        map[string]bool
+	metadata             MetadataFunc
+	strippedHeaders      []string
+	flights              flightGroup
+}
+
+// Option allows customization of the [Gateway].
+type Option func(*Gateway)
+
+// WithCache sets the cache of the results (default: [MemoryCache] of 100000 entries).
+func WithCache(cache Cache) Option {
+	return func(g *Gateway) {
+		g.cache = cache
+	}
+}
+
+// WithMaxTTL sets the max duration an active result is cached (default 1 minute),
+// so revocations are taken into account after that duration.
+func WithMaxTTL(ttl time.Duration) Option {
+	return func(g *Gateway) {
+		g.maxTTL = ttl
+	}
+}
+
+// WithInactiveTTL sets the duration inactive results are cached (default: not cached),
+// protecting ZITADEL against repeated calls with invalid tokens.
+func WithInactiveTTL(ttl time.Duration) Option {
+	return func(g *Gateway) {
+		g.inactiveTTL = ttl
+	}
+}
+
+// WithIntrospectionTimeout limits the duration of an introspection (default 10 seconds).
+// The introspection is shared by all calls with the same token, so it's not canceled with the call that started it.
+func WithIntrospectionTimeout(timeout time.Duration) Option {
+	return func(g *Gateway) {
+		g.introspectionTimeout = timeout
+	}
+}
+
+// WithPublicMethods allows calls of the (full) methods without token, e.g. `/grpc.health.v1.Health/Check`.
+// If a token is provided anyway, it's validated.
+func WithPublicMethods(methods ...string) Option {
+	return func(g *Gateway) {
+		for _, method := range methods {
+			g.publicMethods[method] = true
+		}
+	}
+}
+
+// WithMetadata replaces the default [MetadataFunc] setting the headers,
+// which are removed from the incoming calls, so they can't be spoofed.
+func WithMetadata(headers []string, fn MetadataFunc) Option {
+	return func(g *Gateway) {
+		g.metadata = fn
+		g.strippedHeaders = headers
+	}
+}
+
+// New creates a [Gateway] validating the tokens using the introspector.
+func New(introspector Introspector, opts ...Option) *Gateway {
+	g := &Gateway{
+		introspector:         introspector,
+		maxTTL:               DefaultMaxTTL,
+		introspectionTimeout: DefaultIntrospectionTimeout,
+		publicMethods:        make(map[string]bool),
+		metadata:             DefaultMetadata,
+		strippedHeaders:      []string{HeaderUserID, HeaderOrganizationID, HeaderUsername, HeaderClientID, HeaderRoles},
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	if g.cache == nil {
+		g.cache = NewMemoryCache(DefaultCacheSize)
+	}
+	return g
+}
+
+// DefaultMetadata sets [HeaderUserID], [HeaderOrganizationID], [HeaderUsername], [HeaderClientID]
+// and [HeaderRoles] (comma separated keys of the project roles) if present.
+func DefaultMetadata(claims *introspection.Claims) metadata.MD {
+	md := metadata.MD{}
+	set := func(key, value string) {
+		if value != "" {
+			md.Set(key, value)
+		}
+	}
+	set(HeaderUserID, claims.Subject)
+	set(HeaderUsername, claims.Username)
+	set(HeaderClientID, claims.ClientID)
+	if orgID, ok := claims.Claims[claimResourceOwnerID].(string); ok {
+		set(HeaderOrganizationID, orgID)
+	}
+	if roles, ok := claims.Claims[claimRoles].(map[string]any); ok {
+		keys := make([]string, 0, len(roles))
+		for role := range roles {
+			keys = append(keys, role)
+		}
+		slices.Sort(keys)
+		set(HeaderRoles, strings.Join(keys, ","))
+	}
+	return md
+}
+
+type claimsKey struct{}
+
+// ClaimsFromContext returns the claims of the token of the call, nil for public calls without token.
+func ClaimsFromContext(ctx context.Context) *introspection.Claims {
+	claims, _ := ctx.Value(claimsKey{}).(*introspection.Claims)
+	return claims
+}
+
+// Unary creates a [grpc.UnaryServerInterceptor] validating the token.
+func (g *Gateway) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := g.intercept(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// Stream creates a [grpc.StreamServerInterceptor] validating the token when the stream is established.
+func (g *Gateway) Stream() grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := g.intercept(stream.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &serverStream{ServerStream: stream, ctx: ctx})
+	}
+}
+
+// Authenticate returns the claims of the token using the cache, see the package documentation.
+// [introspection.ErrInactiveToken] is returned for inactive tokens.
+func (g *Gateway) Authenticate(ctx context.Context, token string) (*introspection.Claims, error) {
+	key := cacheKey(token)
+	if cached, ok := g.cached(ctx, key); ok {
+		return cached.claims()
+	}
+	r, err := g.flights.do(ctx, key, func() (*result, error) {
+		introspectCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), g.introspectionTimeout)
+		defer cancel()
+		return g.introspect(introspectCtx, key, token)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.claims()
+}
+
+func (g *Gateway) intercept(ctx context.Context, method string) (context.Context, error) {
+	incoming, _ := metadata.FromIncomingContext(ctx)
+	incoming = incoming.Copy()
+	for _, header := range g.strippedHeaders {
+		incoming.Delete(header)
+	}
+	token, _ := strings.CutPrefix(first(incoming.Get(authorization.HeaderName)), oidc.PrefixBearer)
+	if token == "" {
+		if g.publicMethods[method] {
+			return metadata.NewIncomingContext(ctx, incoming), nil
+		}
+		return nil, status.Error(codes.Unauthenticated, "missing access token")
+	}
+	claims, err := g.Authenticate(ctx, token)
+	if errors.Is(err, introspection.ErrInactiveToken) {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	if dpop.ConfirmationThumbprint(claims) != "" {
+		return nil, status.Error(codes.Unauthenticated, dpop.ErrMissingProof.Error())
+	}
+	md := g.metadata(claims)
+	incoming = metadata.Join(incoming, md)
+	ctx = metadata.NewIncomingContext(ctx, incoming)
+	// the headers are also set on outgoing calls, so a proxying gateway forwards them
+	for key, values := range md {
+		for _, value := range values {
+			ctx = metadata.AppendToOutgoingContext(ctx, key, value)
+		}
+	}
+	return context.WithValue(ctx, claimsKey{}, claims), nil
+}
+
+// result is a cached introspection result, Claims is nil for inactive tokens.
+type result struct {
+	Active bool                  `json:"active"`
+	Claims *introspection.Claims `json:"claims,omitempty"`
+}
+
+func (r *result) claims() (*introspection.Claims, error) {
+	if !r.Active || r.Claims == nil {
+		return nil, introspection.ErrInactiveToken
+	}
+	return r.Claims, nil
+}
+
+func (g *Gateway) cached(ctx context.Context, key string) (*result, bool) {
+	data, ok, err := g.cache.Get(ctx, key)
+	if err != nil || !ok {
+		return nil, false
+	}
+	r := new(result)
+	if err = json.Unmarshal(data, r); err != nil {
+		return nil, false
+	}
+	return r, true
+}
+
+func (g *Gateway) introspect(ctx context.Context, key, token string) (*result, error) {
+	// another replica or a call finished before this flight started might have cached the result in the meantime
+	if cached, ok := g.cached(ctx, key); ok {
+		return cached, nil
+	}
+	claims, err := g.introspector.Introspect(ctx, token)
+	if errors.Is(err, introspection.ErrInactiveToken) {
+		r := &result{Active: false}
+		g.store(ctx, key, r, g.inactiveTTL)
+		return r, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	r := &result{Active: true, Claims: claims}
+	ttl := g.maxTTL
+	if exp := claims.Expiration.AsTime(); !exp.IsZero() && time.Until(exp) < ttl {
+		ttl = time.Until(exp)
+	}
+	g.store(ctx, key, r, ttl)
+	return r, nil
+}
+
+// store caches the result, errors of the cache are ignored since the result is still valid.
+func (g *Gateway) store(ctx context.Context, key string, r *result, ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+	data, err := json.Marshal(r)
+	if err != nil {
+		return
+	}
+	_ = g.cache.Set(ctx, key, data, ttl)
+}
+
+// cacheKey hashes the token, so no valid tokens are kept in the cache.
+// It's prefixed, since shared caches might be used for other data as well.
+func cacheKey(token string) string {
+	return "zitadel:introspection:" + introspection.CacheKey(token)
+}
+
+func first(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,131 @@
+package gateway
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zitadel/oidc/v3/pkg/oidc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/introspection"
+)
+
+type fakeIntrospector struct {
+	calls   atomic.Int32
+	release chan struct{}
+}
+
+func (f *fakeIntrospector) Introspect(_ context.Context, token string) (*introspection.Claims, error) {
+	f.calls.Add(1)
+	if f.release != nil {
+		<-f.release
+	}
+	if token != "valid" && token != "bound" {
+		return nil, introspection.ErrInactiveToken
+	}
+	claims := &introspection.Claims{IntrospectionResponse: oidc.IntrospectionResponse{
+		Active:   true,
+		Subject:  "user1",
+		Username: "alice",
+		Claims: map[string]any{
+			claimResourceOwnerID: "org1",
+			claimRoles:           map[string]any{"viewer": map[string]any{}, "admin": map[string]any{}},
+		},
+	}}
+	if token == "bound" {
+		claims.Claims["cnf"] = map[string]any{"jkt": "thumbprint"}
+	}
+	return claims, nil
+}
+
+func call(t *testing.T, g *Gateway, method string, md metadata.MD) (context.Context, error) {
+	t.Helper()
+	var handled context.Context
+	_, err := g.Unary()(metadata.NewIncomingContext(context.Background(), md), nil, &grpc.UnaryServerInfo{FullMethod: method},
+		func(ctx context.Context, _ any) (any, error) {
+			handled = ctx
+			return nil, nil
+		})
+	return handled, err
+}
+
+func TestGateway_Unary(t *testing.T) {
+	introspector := &fakeIntrospector{}
+	g := New(introspector, WithPublicMethods("/public"), WithInactiveTTL(time.Minute))
+
+	t.Run("valid token, metadata enriched and spoofed headers replaced", func(t *testing.T) {
+		ctx, err := call(t, g, "/service/Method", metadata.Pairs("authorization", "Bearer valid", HeaderUserID, "spoofed"))
+		require.NoError(t, err)
+		incoming, _ := metadata.FromIncomingContext(ctx)
+		assert.Equal(t, []string{"user1"}, incoming.Get(HeaderUserID))
+		assert.Equal(t, []string{"org1"}, incoming.Get(HeaderOrganizationID))
+		assert.Equal(t, []string{"alice"}, incoming.Get(HeaderUsername))
+		assert.Equal(t, []string{"admin,viewer"}, incoming.Get(HeaderRoles))
+		outgoing, _ := metadata.FromOutgoingContext(ctx)
+		assert.Equal(t, []string{"user1"}, outgoing.Get(HeaderUserID))
+		assert.Equal(t, "user1", ClaimsFromContext(ctx).Subject)
+	})
+	t.Run("cached", func(t *testing.T) {
+		before := introspector.calls.Load()
+		_, err := call(t, g, "/service/Method", metadata.Pairs("authorization", "Bearer valid"))
+		require.NoError(t, err)
+		assert.Equal(t, before, introspector.calls.Load())
+	})
+	t.Run("inactive token, cached", func(t *testing.T) {
+		for range 2 {
+			_, err := call(t, g, "/service/Method", metadata.Pairs("authorization", "Bearer invalid"))
+			assert.Equal(t, codes.Unauthenticated, status.Code(err))
+		}
+		assert.Equal(t, int32(2), introspector.calls.Load())
+	})
+	t.Run("DPoP-bound token, also if cached", func(t *testing.T) {
+		before := introspector.calls.Load()
+		for range 2 {
+			ctx, err := call(t, g, "/service/Method", metadata.Pairs("authorization", "Bearer bound"))
+			assert.Equal(t, codes.Unauthenticated, status.Code(err))
+			assert.Nil(t, ctx, "the handler is not called")
+		}
+		assert.Equal(t, before+1, introspector.calls.Load())
+	})
+	t.Run("missing token", func(t *testing.T) {
+		_, err := call(t, g, "/service/Method", metadata.Pairs(HeaderUserID, "spoofed"))
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+	t.Run("public method, spoofed headers removed", func(t *testing.T) {
+		ctx, err := call(t, g, "/public", metadata.Pairs(HeaderUserID, "spoofed"))
+		require.NoError(t, err)
+		incoming, _ := metadata.FromIncomingContext(ctx)
+		assert.Empty(t, incoming.Get(HeaderUserID))
+		assert.Nil(t, ClaimsFromContext(ctx))
+	})
+}
+
+func TestGateway_Authenticate_stampede(t *testing.T) {
+	introspector := &fakeIntrospector{release: make(chan struct{})}
+	g := New(introspector)
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			claims, err := g.Authenticate(context.Background(), "valid")
+			assert.NoError(t, err)
+			assert.Equal(t, "user1", claims.Subject)
+		}()
+	}
+	// wait for the first introspection to start
+	require.Eventually(t, func() bool { return introspector.calls.Load() == 1 }, time.Second, time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+	close(introspector.release)
+	wg.Wait()
+	assert.Equal(t, int32(1), introspector.calls.Load())
+}
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/accesslog"
+)
+
+// AccessLogUnary creates a [grpc.UnaryServerInterceptor] writing an access log entry for every call using the [accesslog.Logger].
+// Chain it in front of [Interceptor.Unary], which annotates the entry with the identity of the caller.
+func AccessLogUnary(logger *accesslog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		start := time.Now()
+		ctx, record := logger.Start(ctx, accesslog.ProtocolGRPC, info.FullMethod, "")
+		defer func() {
+			logger.End(ctx, record, int(status.Code(err)), start)
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// AccessLogStream creates a [grpc.StreamServerInterceptor] writing an access log entry for every call using the [accesslog.Logger].
+// Chain it in front of [Interceptor.Stream], which annotates the entry with the identity of the caller.
+func AccessLogStream(logger *accesslog.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		start := time.Now()
+		ctx, record := logger.Start(stream.Context(), accesslog.ProtocolGRPC, info.FullMethod, "")
+		defer func() {
+			logger.End(ctx, record, int(status.Code(err)), start)
+		}()
+		return handler(srv, &serverStream{ServerStream: stream, ctx: ctx})
+	}
+}
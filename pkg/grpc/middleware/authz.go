@@ -9,7 +9,9 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
+	"github.com/zitadel/zitadel-go/v3/pkg/accesslog"
 	"github.com/zitadel/zitadel-go/v3/pkg/authorization"
+	"github.com/zitadel/zitadel-go/v3/pkg/dpop"
 )
 
 type Interceptor[T authorization.Ctx] struct {
@@ -31,7 +33,7 @@ func (i *Interceptor[T]) Unary() grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
 		ctx, err = i.intercept(ctx, info.FullMethod)
 		if err != nil {
-			return nil, status.Error(codes.PermissionDenied, err.Error())
+			return nil, err
 		}
 		return handler(ctx, req)
 	}
@@ -60,14 +62,20 @@ func (i *Interceptor[T]) intercept(ctx context.Context, method string) (context.
 		if endpoint != method {
 			continue
 		}
-		authCtx, err := i.authorizer.CheckAuthorization(ctx, metautils.ExtractIncoming(ctx).Get(authorization.HeaderName), checks...)
+		header := metautils.ExtractIncoming(ctx).Get(authorization.HeaderName)
+		authorizedCtx, authCtx, err := i.authorizer.Authorize(ctx, header, checks...)
+		accesslog.Annotate(ctx, header, authCtx)
 		if err != nil {
 			if errors.Is(err, &authorization.UnauthorizedErr{}) {
 				return nil, status.Error(codes.Unauthenticated, err.Error())
 			}
 			return nil, status.Error(codes.PermissionDenied, err.Error())
 		}
-		return authorization.WithAuthContext(ctx, authCtx), nil
+		// DPoP proofs are not verified for gRPC, so tokens bound to a key are rejected instead of accepted as bearer token
+		if dpop.ConfirmationThumbprint(authCtx) != "" {
+			return nil, status.Error(codes.Unauthenticated, dpop.ErrMissingProof.Error())
+		}
+		return authorizedCtx, nil
 	}
 	return ctx, nil
 }
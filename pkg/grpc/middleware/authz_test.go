@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/authorization"
+	"github.com/zitadel/zitadel-go/v3/pkg/zitadel"
+)
+
+const testMethod = "/zitadel.test.v1.TestService/Test"
+
+func TestInterceptor_Unary(t *testing.T) {
+	tests := []struct {
+		name     string
+		authCtx  authorization.Ctx
+		header   string
+		wantCode codes.Code
+	}{
+		{"bearer token", &unboundCtx{}, "Bearer token", codes.OK},
+		{"unbound token", &boundCtx{}, "Bearer token", codes.OK},
+		{"bound token without proof", &boundCtx{thumbprint: "thumbprint"}, "Bearer token", codes.Unauthenticated},
+		{"missing token", &unboundCtx{}, "", codes.Unauthenticated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authorizer, err := authorization.New[authorization.Ctx](context.Background(), zitadel.New("zitadel.cloud"),
+				func(context.Context, *zitadel.Zitadel) (authorization.Verifier[authorization.Ctx], error) {
+					return &testVerifier{authCtx: tt.authCtx}, nil
+				},
+			)
+			require.NoError(t, err)
+			interceptor := New(authorizer, map[string][]authorization.CheckOption{testMethod: nil}).Unary()
+
+			ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(authorization.HeaderName, tt.header))
+			_, err = interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: testMethod}, func(context.Context, any) (any, error) {
+				return nil, nil
+			})
+			assert.Equal(t, tt.wantCode, status.Code(err))
+		})
+	}
+}
+
+type testVerifier struct {
+	authCtx authorization.Ctx
+}
+
+func (v *testVerifier) CheckAuthorization(context.Context, string) (authorization.Ctx, error) {
+	return v.authCtx, nil
+}
+
+type unboundCtx struct {
+	token string
+}
+
+func (c *unboundCtx) IsAuthorized() bool                           { return true }
+func (c *unboundCtx) OrganizationID() string                       { return "" }
+func (c *unboundCtx) UserID() string                               { return "user" }
+func (c *unboundCtx) IsGrantedRole(string) bool                    { return false }
+func (c *unboundCtx) IsGrantedRoleInOrganization(_, _ string) bool { return false }
+func (c *unboundCtx) SetToken(token string)                        { c.token = token }
+func (c *unboundCtx) GetToken() string                             { return c.token }
+
+type boundCtx struct {
+	unboundCtx
+	thumbprint string
+}
+
+func (c *boundCtx) ConfirmationThumbprint() string { return c.thumbprint }
@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/accesslog"
+)
+
+// AccessLog creates a middleware writing an access log entry for every request using the [accesslog.Logger].
+// Use it in front of [Interceptor.RequireAuthorization], which annotates the entry with the identity of the caller.
+func AccessLog(logger *accesslog.Logger) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			start := time.Now()
+			ctx, record := logger.Start(req.Context(), accesslog.ProtocolHTTP, req.Method, req.URL.Path)
+			recorder := &statusRecorder{ResponseWriter: w}
+			defer func() {
+				logger.End(ctx, record, recorder.statusCode(), start)
+			}()
+			next.ServeHTTP(recorder, req.WithContext(ctx))
+		})
+	}
+}
+
+// statusRecorder remembers the status written to the [http.ResponseWriter].
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	if s.status == 0 {
+		s.status = status
+	}
+	s.ResponseWriter.WriteHeader(status)
+}
+
+func (s *statusRecorder) Write(b []byte) (int, error) {
+	if s.status == 0 {
+		s.status = http.StatusOK
+	}
+	return s.ResponseWriter.Write(b)
+}
+
+// Unwrap allows the [http.ResponseController] to access the underlying [http.ResponseWriter].
+func (s *statusRecorder) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
+
+func (s *statusRecorder) statusCode() int {
+	if s.status == 0 {
+		return http.StatusOK
+	}
+	return s.status
+}
@@ -2,10 +2,13 @@ package middleware
 
 import (
 	"context"
+	"crypto/subtle"
 	"errors"
 	"net/http"
 
+	"github.com/zitadel/zitadel-go/v3/pkg/accesslog"
 	"github.com/zitadel/zitadel-go/v3/pkg/authorization"
+	"github.com/zitadel/zitadel-go/v3/pkg/dpop"
 )
 
 type Interceptor[T authorization.Ctx] struct {
@@ -18,10 +21,15 @@ func New[T authorization.Ctx](authorizer *authorization.Authorizer[T]) *Intercep
 	}
 }
 
+// RequireAuthorization verifies the token of the request and runs the checks (e.g. roles).
+// Tokens with the DPoP scheme are only accepted if the route is wrapped by [Interceptor.RequireDPoP].
+// DPoP-bound tokens (see [dpop.ConfirmationThumbprint]) sent without a verified proof, e.g. as bearer token,
+// are rejected with 401 Unauthorized.
 func (i *Interceptor[T]) RequireAuthorization(options ...authorization.CheckOption) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
-			ctx, err := i.authorizer.CheckAuthorization(req.Context(), req.Header.Get(authorization.HeaderName), options...)
+			ctx, authCtx, err := i.authorizer.Authorize(req.Context(), req.Header.Get(authorization.HeaderName), options...)
+			accesslog.Annotate(req.Context(), req.Header.Get(authorization.HeaderName), authCtx)
 			if err != nil {
 				if errors.Is(err, &authorization.UnauthorizedErr{}) {
 					http.Error(w, err.Error(), http.StatusUnauthorized)
@@ -30,12 +38,65 @@ func (i *Interceptor[T]) RequireAuthorization(options ...authorization.CheckOpti
 				http.Error(w, err.Error(), http.StatusForbidden)
 				return
 			}
-			req = req.WithContext(authorization.WithAuthContext(req.Context(), ctx))
-			next.ServeHTTP(w, req)
+			proof, ok := dpop.ProofFromContext(req.Context())
+			if !ok && dpop.ConfirmationThumbprint(authCtx) != "" {
+				w.Header().Set("WWW-Authenticate", `DPoP error="invalid_token"`)
+				http.Error(w, dpop.ErrMissingProof.Error(), http.StatusUnauthorized)
+				return
+			}
+			if ok {
+				if err = checkBinding(authCtx, proof); err != nil {
+					unauthorizedDPoP(w, err)
+					return
+				}
+			}
+			next.ServeHTTP(w, req.WithContext(ctx))
 		})
 	}
 }
 
+// RequireDPoP verifies the DPoP proof of the request and requires the access token to be bound to it.
+// It must wrap [Interceptor.RequireAuthorization], e.g. `i.RequireDPoP()(i.RequireAuthorization()(handler))`,
+// which only accepts tokens with the DPoP scheme with the verified proof and checks the key the token is bound to.
+// The authorization context must therefore provide it by a `ConfirmationThumbprint() string` method
+// (e.g. oauth.IntrospectionContext).
+// Requests without a valid proof, with a bearer token or with a token not bound to the key of the proof
+// are rejected with 401 Unauthorized.
+// Used proofs are rejected by an in-memory replay cache shared by the handlers of the middleware,
+// pass [dpop.WithReplayCache] to share it between the instances of the backend.
+func (i *Interceptor[T]) RequireDPoP(options ...dpop.VerifyOption) func(next http.Handler) http.Handler {
+	options = append([]dpop.VerifyOption{dpop.WithReplayCache(dpop.NewMemoryReplayCache())}, options...)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if _, ok := dpop.AccessToken(req.Header.Get(authorization.HeaderName)); !ok {
+				unauthorizedDPoP(w, dpop.ErrTokenNotBound)
+				return
+			}
+			proof, err := dpop.VerifyRequest(req, options...)
+			if err != nil {
+				unauthorizedDPoP(w, err)
+				return
+			}
+			next.ServeHTTP(w, req.WithContext(dpop.WithProof(req.Context(), proof)))
+		})
+	}
+}
+
+// checkBinding requires the token of the authorization context to be bound to the key of the proof.
+// Contexts not providing the thumbprint of the key are rejected.
+func checkBinding(authCtx any, proof *dpop.Proof) error {
+	thumbprint := dpop.ConfirmationThumbprint(authCtx)
+	if thumbprint == "" || subtle.ConstantTimeCompare([]byte(thumbprint), []byte(proof.Thumbprint)) != 1 {
+		return dpop.ErrTokenNotBound
+	}
+	return nil
+}
+
+func unauthorizedDPoP(w http.ResponseWriter, err error) {
+	w.Header().Set("WWW-Authenticate", `DPoP error="invalid_dpop_proof"`)
+	http.Error(w, err.Error(), http.StatusUnauthorized)
+}
+
 func (i *Interceptor[T]) Context(ctx context.Context) T {
 	return authorization.Context[T](ctx)
 }
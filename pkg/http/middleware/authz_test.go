@@ -0,0 +1,181 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/authorization"
+	"github.com/zitadel/zitadel-go/v3/pkg/dpop"
+	"github.com/zitadel/zitadel-go/v3/pkg/zitadel"
+)
+
+func TestInterceptor_RequireDPoP(t *testing.T) {
+	prover, err := dpop.GenerateProver()
+	require.NoError(t, err)
+	attacker, err := dpop.GenerateProver()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		authCtx    authorization.Ctx
+		prover     *dpop.Prover
+		scheme     string
+		wantStatus int
+	}{
+		{
+			name:       "bound token",
+			authCtx:    &boundCtx{thumbprint: prover.Thumbprint()},
+			prover:     prover,
+			scheme:     dpop.TokenType,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "proof of other key",
+			authCtx:    &boundCtx{thumbprint: prover.Thumbprint()},
+			prover:     attacker,
+			scheme:     dpop.TokenType,
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "token not bound",
+			authCtx:    &boundCtx{},
+			prover:     prover,
+			scheme:     dpop.TokenType,
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "context without thumbprint",
+			authCtx:    &unboundCtx{},
+			prover:     prover,
+			scheme:     dpop.TokenType,
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "bearer token",
+			authCtx:    &boundCtx{thumbprint: prover.Thumbprint()},
+			prover:     prover,
+			scheme:     "Bearer",
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authorizer, err := authorization.New[authorization.Ctx](context.Background(), zitadel.New("zitadel.cloud"),
+				func(context.Context, *zitadel.Zitadel) (authorization.Verifier[authorization.Ctx], error) {
+					return &testVerifier{authCtx: tt.authCtx}, nil
+				},
+			)
+			require.NoError(t, err)
+			i := New(authorizer)
+			handler := i.RequireDPoP()(i.RequireAuthorization()(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})))
+
+			req := httptest.NewRequest(http.MethodGet, "http://api.example.com/resource", nil)
+			proof, err := tt.prover.Proof(http.MethodGet, "http://api.example.com/resource", "token")
+			require.NoError(t, err)
+			req.Header.Set(dpop.HeaderName, proof)
+			req.Header.Set(authorization.HeaderName, tt.scheme+" token")
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+		})
+	}
+}
+
+func TestInterceptor_RequireDPoP_replay(t *testing.T) {
+	prover, err := dpop.GenerateProver()
+	require.NoError(t, err)
+	authorizer, err := authorization.New[authorization.Ctx](context.Background(), zitadel.New("zitadel.cloud"),
+		func(context.Context, *zitadel.Zitadel) (authorization.Verifier[authorization.Ctx], error) {
+			return &testVerifier{authCtx: &boundCtx{thumbprint: prover.Thumbprint()}}, nil
+		},
+	)
+	require.NoError(t, err)
+	i := New(authorizer)
+	requireDPoP := i.RequireDPoP()
+	// the replay cache is shared by all handlers of the middleware
+	first := requireDPoP(i.RequireAuthorization()(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})))
+	second := requireDPoP(i.RequireAuthorization()(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})))
+
+	proof, err := prover.Proof(http.MethodGet, "http://api.example.com/resource", "token")
+	require.NoError(t, err)
+	serve := func(handler http.Handler, proof string) int {
+		req := httptest.NewRequest(http.MethodGet, "http://api.example.com/resource", nil)
+		req.Header.Set(dpop.HeaderName, proof)
+		req.Header.Set(authorization.HeaderName, dpop.TokenType+" token")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	assert.Equal(t, http.StatusOK, serve(first, proof))
+	assert.Equal(t, http.StatusUnauthorized, serve(first, proof), "replayed proof")
+	assert.Equal(t, http.StatusUnauthorized, serve(second, proof), "replayed proof on another handler")
+
+	fresh, err := prover.Proof(http.MethodGet, "http://api.example.com/resource", "token")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, serve(second, fresh))
+}
+
+func TestInterceptor_RequireAuthorization(t *testing.T) {
+	tests := []struct {
+		name          string
+		authCtx       authorization.Ctx
+		wantStatus    int
+		wantChallenge string
+	}{
+		{"bearer token", &unboundCtx{}, http.StatusOK, ""},
+		{"unbound token", &boundCtx{}, http.StatusOK, ""},
+		{"bound token without proof", &boundCtx{thumbprint: "thumbprint"}, http.StatusUnauthorized, `DPoP error="invalid_token"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authorizer, err := authorization.New[authorization.Ctx](context.Background(), zitadel.New("zitadel.cloud"),
+				func(context.Context, *zitadel.Zitadel) (authorization.Verifier[authorization.Ctx], error) {
+					return &testVerifier{authCtx: tt.authCtx}, nil
+				},
+			)
+			require.NoError(t, err)
+			handler := New(authorizer).RequireAuthorization()(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+
+			req := httptest.NewRequest(http.MethodGet, "http://api.example.com/resource", nil)
+			req.Header.Set(authorization.HeaderName, "Bearer token")
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			assert.Equal(t, tt.wantChallenge, rec.Header().Get("WWW-Authenticate"))
+		})
+	}
+}
+
+type testVerifier struct {
+	authCtx authorization.Ctx
+}
+
+func (v *testVerifier) CheckAuthorization(context.Context, string) (authorization.Ctx, error) {
+	return v.authCtx, nil
+}
+
+type unboundCtx struct {
+	token string
+}
+
+func (c *unboundCtx) IsAuthorized() bool                           { return true }
+func (c *unboundCtx) OrganizationID() string                       { return "" }
+func (c *unboundCtx) UserID() string                               { return "user" }
+func (c *unboundCtx) IsGrantedRole(string) bool                    { return false }
+func (c *unboundCtx) IsGrantedRoleInOrganization(_, _ string) bool { return false }
+func (c *unboundCtx) SetToken(token string)                        { c.token = token }
+func (c *unboundCtx) GetToken() string                             { return c.token }
+
+type boundCtx struct {
+	unboundCtx
+	thumbprint string
+}
+
+func (c *boundCtx) ConfirmationThumbprint() string { return c.thumbprint }
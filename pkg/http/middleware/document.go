@@ -0,0 +1,156 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+const (
+	// ExtensionRoles is the OpenAPI extension listing the roles required by an operation.
+	ExtensionRoles = "x-required-roles"
+	// ExtensionAdditionalChecks is the OpenAPI extension set for operations with additional checks (see [Route.Checks]),
+	// which can't be documented.
+	ExtensionAdditionalChecks = "x-additional-checks"
+)
+
+// openAPIMethods are the operations of an OpenAPI path item.
+var openAPIMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// pathParameter matches the parameters of OpenAPI paths, e.g. `{id}`.
+var pathParameter = regexp.MustCompile(`\{[^/}]+\}`)
+
+// RouteAuthorization documents the authorization enforced for a [Route].
+type RouteAuthorization struct {
+	Pattern string `json:"pattern"`
+	// Method is empty if the route matches all methods.
+	Method string   `json:"method,omitempty"`
+	Host   string   `json:"host,omitempty"`
+	Path   string   `json:"path"`
+	Public bool     `json:"public"`
+	Roles  []string `json:"roles"`
+	// AdditionalChecks is the number of additional checks (see [Route.Checks]), which can't be documented.
+	AdditionalChecks int `json:"additionalChecks,omitempty"`
+}
+
+// Document returns the authorization table of the routes (e.g. of [RouteTable.Routes]) sorted by path and method,
+// so security reviews and API docs reflect the enforced roles.
+func Document(routes []Route) []RouteAuthorization {
+	table := make([]RouteAuthorization, len(routes))
+	for i, route := range routes {
+		method, host, path := splitPattern(route.Pattern)
+		roles := route.Roles
+		if roles == nil {
+			roles = []string{}
+		}
+		table[i] = RouteAuthorization{
+			Pattern:          route.Pattern,
+			Method:           method,
+			Host:             host,
+			Path:             path,
+			Public:           route.Public,
+			Roles:            roles,
+			AdditionalChecks: len(route.Checks),
+		}
+	}
+	slices.SortFunc(table, func(a, b RouteAuthorization) int {
+		if c := strings.Compare(a.Path, b.Path); c != 0 {
+			return c
+		}
+		if c := strings.Compare(a.Method, b.Method); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Host, b.Host)
+	})
+	return table
+}
+
+// WriteJSON writes the authorization table of the routes (see [Document]) as JSON.
+func WriteJSON(w io.Writer, routes []Route) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(Document(routes))
+}
+
+// AnnotateOpenAPI adds the authorization of the routes to the operations of the JSON encoded OpenAPI (3.x) spec
+// and returns the annotated spec. The route of an operation is determined the same way the [RouteTable] does,
+// i.e. by the most specific pattern matching its method and path (the paths of the spec are matched as they are,
+// without the paths of the servers). For each operation
+//   - public routes get an empty security requirement (`security: []`),
+//   - all others a requirement of the security scheme (`security: [{<scheme>: []}]`),
+//     the required roles in the [ExtensionRoles] and the number of additional checks in [ExtensionAdditionalChecks].
+func AnnotateOpenAPI(spec []byte, routes []Route, securityScheme string) ([]byte, error) {
+	mux := http.NewServeMux()
+	for _, route := range routes {
+		if err := register(mux, route); err != nil {
+			return nil, err
+		}
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(spec, &doc); err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI spec: %w", err)
+	}
+	paths, _ := doc["paths"].(map[string]any)
+	for path, item := range paths {
+		operations, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		for _, method := range openAPIMethods {
+			operation, ok := operations[method].(map[string]any)
+			if !ok {
+				continue
+			}
+			annotate(operation, matchRoute(mux, strings.ToUpper(method), path), securityScheme)
+		}
+	}
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+func annotate(operation map[string]any, route *Route, securityScheme string) {
+	delete(operation, ExtensionRoles)
+	delete(operation, ExtensionAdditionalChecks)
+	if route != nil && route.Public {
+		operation["security"] = []any{}
+		return
+	}
+	operation["security"] = []any{map[string]any{securityScheme: []any{}}}
+	if route == nil {
+		return
+	}
+	if len(route.Roles) > 0 {
+		operation[ExtensionRoles] = route.Roles
+	}
+	if len(route.Checks) > 0 {
+		operation[ExtensionAdditionalChecks] = len(route.Checks)
+	}
+}
+
+// matchRoute returns the route of the mux matching the method and OpenAPI path, nil if no route matches.
+func matchRoute(mux *http.ServeMux, method, path string) *Route {
+	req, err := http.NewRequest(method, pathParameter.ReplaceAllString(path, "0"), nil)
+	if err != nil {
+		return nil
+	}
+	handler, _ := mux.Handler(req)
+	if h, ok := handler.(*routeHandler); ok {
+		return &h.route
+	}
+	return nil
+}
+
+// splitPattern splits the pattern of a [http.ServeMux] (`[METHOD ][HOST]/[PATH]`) into its parts.
+func splitPattern(pattern string) (method, host, path string) {
+	rest := strings.TrimSpace(pattern)
+	if i := strings.IndexAny(rest, " \t"); i >= 0 {
+		method, rest = rest[:i], strings.TrimLeft(rest[i:], " \t")
+	}
+	if i := strings.Index(rest, "/"); i > 0 {
+		host, rest = rest[:i], rest[i:]
+	}
+	return method, host, rest
+}
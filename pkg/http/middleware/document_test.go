@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/authorization"
+)
+
+var documentedRoutes = []Route{
+	{Pattern: "GET /public/", Public: true},
+	{Pattern: "/admin/", Roles: []string{"admin"}},
+	{Pattern: "DELETE api.example.com/admin/users/{id}", Roles: []string{"admin", "owner"}, Checks: []authorization.CheckOption{authorization.WithRole("x")}},
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteJSON(&buf, documentedRoutes))
+	assert.JSONEq(t, `[
+		{"pattern": "/admin/", "path": "/admin/", "public": false, "roles": ["admin"]},
+		{"pattern": "DELETE api.example.com/admin/users/{id}", "method": "DELETE", "host": "api.example.com", "path": "/admin/users/{id}", "public": false, "roles": ["admin", "owner"], "additionalChecks": 1},
+		{"pattern": "GET /public/", "method": "GET", "path": "/public/", "public": true, "roles": []}
+	]`, buf.String())
+}
+
+func TestAnnotateOpenAPI(t *testing.T) {
+	spec := []byte(`{
+		"openapi": "3.0.3",
+		"paths": {
+			"/public/info": {"get": {"operationId": "info"}},
+			"/admin/users/{id}": {"get": {"operationId": "getUser"}, "delete": {"operationId": "deleteUser"}},
+			"/other": {"post": {"operationId": "other", "x-required-roles": ["stale"]}}
+		}
+	}`)
+	annotated, err := AnnotateOpenAPI(spec, documentedRoutes, "zitadel")
+	require.NoError(t, err)
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal(annotated, &doc))
+
+	operation := func(path, method string) map[string]any {
+		return doc["paths"].(map[string]any)[path].(map[string]any)[method].(map[string]any)
+	}
+	authenticated := []any{map[string]any{"zitadel": []any{}}}
+
+	assert.Equal(t, map[string]any{"operationId": "info", "security": []any{}}, operation("/public/info", "get"))
+	assert.Equal(t, map[string]any{"operationId": "getUser", "security": authenticated, ExtensionRoles: []any{"admin"}}, operation("/admin/users/{id}", "get"))
+	// the host specific route does not match the paths of the spec
+	assert.Equal(t, map[string]any{"operationId": "deleteUser", "security": authenticated, ExtensionRoles: []any{"admin"}}, operation("/admin/users/{id}", "delete"))
+	assert.Equal(t, map[string]any{"operationId": "other", "security": authenticated}, operation("/other", "post"))
+
+	_, err = AnnotateOpenAPI(spec, []Route{{Pattern: "/a/"}, {Pattern: "/a/"}}, "zitadel")
+	assert.Error(t, err)
+}
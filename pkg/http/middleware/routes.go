@@ -0,0 +1,385 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/authorization"
+	"github.com/zitadel/zitadel-go/v3/pkg/dpop"
+)
+
+var (
+	ErrInvalidIssuer    = errors.New("invalid issuer")
+	ErrInvalidAudience  = errors.New("invalid audience")
+	ErrMethodNotAllowed = errors.New("method not allowed")
+	// ErrUncleanPath is returned for paths not in their clean form (e.g. `//admin`, `/x/../admin` or `/admin/%2e%2e/x`),
+	// which the next handler might route differently than the middleware, so they are rejected instead of being authorized.
+	ErrUncleanPath = errors.New("path is not clean")
+)
+
+// Route defines the authorization requirements for the requests matching its pattern.
+type Route struct {
+	// Pattern is a pattern of a [http.ServeMux], e.g. `GET /api/users/{id}` or `/admin/`.
+	// The most specific pattern matching a request is used.
+	Pattern string
+	// Public routes don't require any authorization.
+	Public bool
+	// Roles are the roles the user must be granted (all of them).
+	Roles []string
+	// Checks are additional requirements, e.g. custom checks on the authorization context.
+	Checks []authorization.CheckOption
+}
+
+// TokenClaims is implemented by authorization contexts providing the issuer and audience of the token,
+// e.g. the oauth.IntrospectionContext. It's required for [WithIssuer] and [WithAudience].
+type TokenClaims interface {
+	GetIssuer() string
+	GetAudience() []string
+}
+
+// RouteTable enforces the authorization of requests per route (see [Interceptor.RouteTable]).
+// Requests not matching any route require a valid authorization without further checks.
+// Failures are answered with 401 / 403, including the `WWW-Authenticate` header as defined in RFC 6750
+// on 401 and on 403 for missing roles (`insufficient_scope`).
+type RouteTable[T authorization.Ctx] struct {
+	authorizer *authorization.Authorizer[T]
+	routes     *http.ServeMux
+	config     []Route
+	realm      string
+	issuer     string
+	audience   []string
+}
+
+// RouteOption allows customization of the [RouteTable].
+type RouteOption func(*routeOptions)
+
+type routeOptions struct {
+	realm    string
+	issuer   string
+	audience []string
+}
+
+// WithRealm sets the realm of the `WWW-Authenticate` challenge.
+func WithRealm(realm string) RouteOption {
+	return func(o *routeOptions) {
+		o.realm = realm
+	}
+}
+
+// WithIssuer requires the token to be issued by the issuer (e.g. the origin of the ZITADEL instance).
+func WithIssuer(issuer string) RouteOption {
+	return func(o *routeOptions) {
+		o.issuer = issuer
+	}
+}
+
+// WithAudience requires the token to contain at least one of the audiences (e.g. the ID of the API project).
+func WithAudience(audience ...string) RouteOption {
+	return func(o *routeOptions) {
+		o.audience = append(o.audience, audience...)
+	}
+}
+
+// RouteTable creates the [RouteTable] enforcing the authorization of the routes.
+// An error is returned if a pattern is invalid or conflicts with another one.
+func (i *Interceptor[T]) RouteTable(routes []Route, opts ...RouteOption) (_ *RouteTable[T], err error) {
+	o := new(routeOptions)
+	for _, opt := range opts {
+		opt(o)
+	}
+	mux := http.NewServeMux()
+	for _, route := range routes {
+		if err = register(mux, route); err != nil {
+			return nil, err
+		}
+	}
+	return &RouteTable[T]{
+		authorizer: i.authorizer,
+		routes:     mux,
+		config:     slices.Clone(routes),
+		realm:      o.realm,
+		issuer:     o.issuer,
+		audience:   o.audience,
+	}, nil
+}
+
+// register adds the route to the mux, which panics on invalid or conflicting patterns.
+func register(mux *http.ServeMux, route Route) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("invalid route %q: %v", route.Pattern, r)
+		}
+	}()
+	mux.Handle(route.Pattern, &routeHandler{route: route})
+	return nil
+}
+
+// routeHandler is only used to find the matching [Route] of a request.
+type routeHandler struct {
+	route Route
+}
+
+func (*routeHandler) ServeHTTP(http.ResponseWriter, *http.Request) {}
+
+// Route returns the [Route] matching the request, nil if no route matches
+// or the path is not in its clean form (see [ErrUncleanPath]).
+func (m *RouteTable[T]) Route(req *http.Request) *Route {
+	route, _ := m.match(req)
+	return route
+}
+
+// match returns the [Route] matching the path of the request.
+// [ErrMethodNotAllowed] is returned if the path only matches routes of other methods,
+// so the request isn't handled like one not matching any route.
+// Paths not in their clean form are rejected with [ErrUncleanPath], since the request is passed on unchanged.
+func (m *RouteTable[T]) match(req *http.Request) (*Route, error) {
+	if cleaned := cleanPath(req.URL.Path); cleaned != req.URL.Path {
+		return nil, fmt.Errorf("%w: %q", ErrUncleanPath, req.URL.Path)
+	}
+	u := *req.URL
+	u.RawPath = ""
+	r := req.Clone(req.Context())
+	r.URL = &u
+	// the mux redirects paths to their subtree pattern, e.g. `/admin` to `/admin/`, which is followed once
+	for range 2 {
+		handler, _ := m.routes.Handler(r)
+		if h, ok := handler.(*routeHandler); ok {
+			return &h.route, nil
+		}
+		rec := new(routeRecorder)
+		handler.ServeHTTP(rec, r)
+		switch rec.status {
+		case http.StatusMethodNotAllowed:
+			return nil, ErrMethodNotAllowed
+		case http.StatusMovedPermanently, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+			location, err := url.Parse(rec.header.Get("Location"))
+			if err != nil {
+				return nil, err
+			}
+			u.Path = cleanPath(location.Path)
+		default:
+			return nil, nil
+		}
+	}
+	return nil, fmt.Errorf("no route for redirected path %q", u.Path)
+}
+
+// cleanPath returns the canonical path as the [http.ServeMux] does, keeping a trailing slash.
+func cleanPath(p string) string {
+	if p == "" {
+		return "/"
+	}
+	if p[0] != '/' {
+		p = "/" + p
+	}
+	cleaned := path.Clean(p)
+	if strings.HasSuffix(p, "/") && cleaned != "/" {
+		cleaned += "/"
+	}
+	return cleaned
+}
+
+// routeRecorder records the status of the handlers of the mux not matching any [Route].
+type routeRecorder struct {
+	header http.Header
+	status int
+}
+
+func (r *routeRecorder) Header() http.Header {
+	if r.header == nil {
+		r.header = make(http.Header)
+	}
+	return r.header
+}
+
+func (r *routeRecorder) Write(b []byte) (int, error) {
+	return len(b), nil
+}
+
+func (r *routeRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+}
+
+// Routes returns the configured routes, e.g. to document them using [Document] or [AnnotateOpenAPI].
+func (m *RouteTable[T]) Routes() []Route {
+	return slices.Clone(m.config)
+}
+
+// Denial describes why a request was not authorized.
+type Denial struct {
+	// Status is the HTTP status of the response, either 401 or 403
+	// (400 for paths not in their clean form, 405 if the path only matches routes of other methods).
+	Status int
+	// Challenge is the value of the `WWW-Authenticate` header as defined in RFC 6750,
+	// empty if the header must not be sent (any status but 401 and 403 with `insufficient_scope`).
+	Challenge string
+	Err       error
+}
+
+// Authorize checks the authorization of the request for its route without responding to it,
+// e.g. for authorization servers of API gateways. If the request is not authorized, the [Denial] is returned.
+// For public routes, the (empty) authorization context is returned without checking any token.
+func (m *RouteTable[T]) Authorize(req *http.Request) (authCtx T, denial *Denial) {
+	route, err := m.match(req)
+	if err != nil {
+		return authCtx, m.deny(statusOf(err), "", err)
+	}
+	if route != nil && route.Public {
+		return authCtx, nil
+	}
+	_, authCtx, denial = m.authorize(req, route)
+	return authCtx, denial
+}
+
+func (m *RouteTable[T]) authorize(req *http.Request, route *Route) (ctx context.Context, authCtx T, denial *Denial) {
+	token := req.Header.Get(authorization.HeaderName)
+	if token == "" {
+		return nil, authCtx, m.deny(http.StatusUnauthorized, "", authorization.ErrEmptyAuthorizationHeader)
+	}
+	ctx, authCtx, err := m.authorizer.Authorize(req.Context(), token)
+	if err != nil {
+		return nil, authCtx, m.deny(http.StatusUnauthorized, "invalid_token", err)
+	}
+	if err = m.checkClaims(authCtx); err != nil {
+		return nil, authCtx, m.deny(http.StatusUnauthorized, "invalid_token", err)
+	}
+	// DPoP-bound tokens require a verified proof (see [Interceptor.RequireDPoP]) of the key they are bound to
+	if proof, ok := dpop.ProofFromContext(req.Context()); ok {
+		err = checkBinding(authCtx, proof)
+	} else if dpop.ConfirmationThumbprint(authCtx) != "" {
+		err = dpop.ErrMissingProof
+	}
+	if err != nil {
+		return nil, authCtx, m.deny(http.StatusUnauthorized, "invalid_token", err)
+	}
+	if route != nil {
+		if err = checkRoute(route, authCtx); err != nil {
+			return nil, authCtx, m.deny(http.StatusForbidden, "insufficient_scope", err)
+		}
+	}
+	return ctx, authCtx, nil
+}
+
+// RequireRoutes enforces the authorization of the routes (see [Interceptor.RouteTable]) for the requests of the next handler,
+// e.g. a whole API: `requireRoutes, err := i.RequireRoutes(routes)` and `http.ListenAndServe(addr, requireRoutes(mux))`.
+func (i *Interceptor[T]) RequireRoutes(routes []Route, opts ...RouteOption) (func(next http.Handler) http.Handler, error) {
+	table, err := i.RouteTable(routes, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return table.Handler, nil
+}
+
+// Handler wraps the next handler and enforces the authorization of the requests.
+// The authorization context is available in the next handler (see [authorization.Context]).
+func (m *RouteTable[T]) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		route, err := m.match(req)
+		if err == nil && route != nil && route.Public {
+			next.ServeHTTP(w, req)
+			return
+		}
+		var ctx context.Context
+		var denial *Denial
+		if err != nil {
+			denial = m.deny(statusOf(err), "", err)
+		} else {
+			ctx, _, denial = m.authorize(req, route)
+		}
+		if denial != nil {
+			if denial.Challenge != "" {
+				w.Header().Set("WWW-Authenticate", denial.Challenge)
+			}
+			http.Error(w, denial.Err.Error(), denial.Status)
+			return
+		}
+		next.ServeHTTP(w, req.WithContext(ctx))
+	})
+}
+
+func (m *RouteTable[T]) checkClaims(authCtx T) error {
+	if m.issuer == "" && len(m.audience) == 0 {
+		return nil
+	}
+	claims, ok := any(authCtx).(TokenClaims)
+	if !ok {
+		return fmt.Errorf("authorization context %T does not provide token claims", authCtx)
+	}
+	if m.issuer != "" && strings.TrimSuffix(claims.GetIssuer(), "/") != strings.TrimSuffix(m.issuer, "/") {
+		return ErrInvalidIssuer
+	}
+	if len(m.audience) > 0 && !slices.ContainsFunc(claims.GetAudience(), func(aud string) bool {
+		return slices.Contains(m.audience, aud)
+	}) {
+		return ErrInvalidAudience
+	}
+	return nil
+}
+
+func checkRoute(route *Route, authCtx authorization.Ctx) error {
+	checks := new(authorization.Check[authorization.Ctx])
+	for _, role := range route.Roles {
+		authorization.WithRole(role)(checks)
+	}
+	for _, option := range route.Checks {
+		option(checks)
+	}
+	for _, check := range checks.Checks {
+		if err := check(authCtx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// statusOf returns the status of a request whose route could not be matched.
+func statusOf(err error) int {
+	switch {
+	case errors.Is(err, ErrMethodNotAllowed):
+		return http.StatusMethodNotAllowed
+	case errors.Is(err, ErrUncleanPath):
+		return http.StatusBadRequest
+	default:
+		return http.StatusForbidden
+	}
+}
+
+// deny creates the [Denial] with the `WWW-Authenticate` challenge as defined in RFC 6750.
+// If no token was provided, the error code is omitted.
+// The challenge is only set on 401 and on 403 with `insufficient_scope`, other failures (e.g. 400 and 405)
+// are not about the token, so they don't ask the client to authenticate.
+func (m *RouteTable[T]) deny(status int, code string, err error) *Denial {
+	if status != http.StatusUnauthorized && (status != http.StatusForbidden || code != "insufficient_scope") {
+		return &Denial{Status: status, Err: err}
+	}
+	params := make([]string, 0, 3)
+	if m.realm != "" {
+		params = append(params, fmt.Sprintf("realm=%q", m.realm))
+	}
+	if code != "" {
+		params = append(params, fmt.Sprintf("error=%q", code), fmt.Sprintf("error_description=%q", errorDescription(err)))
+	}
+	challenge := "Bearer"
+	if len(params) > 0 {
+		challenge += " " + strings.Join(params, ", ")
+	}
+	return &Denial{Status: status, Challenge: challenge, Err: err}
+}
+
+// errorDescription removes characters not allowed in the `error_description` attribute.
+func errorDescription(err error) string {
+	return strings.Map(func(r rune) rune {
+		if r == '"' || r == '\\' || r < 0x20 || r > 0x7e {
+			return -1
+		}
+		return r
+	}, err.Error())
+}
@@ -0,0 +1,177 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/authorization"
+	"github.com/zitadel/zitadel-go/v3/pkg/zitadel"
+)
+
+func TestRouteTable_Handler(t *testing.T) {
+	routes := []Route{
+		{Pattern: "GET /public/", Public: true},
+		{Pattern: "/admin/", Roles: []string{"admin"}},
+		{Pattern: "GET /reports/{id}", Roles: []string{"reporter"}},
+	}
+	type args struct {
+		method string
+		path   string
+		token  string
+	}
+	tests := []struct {
+		name          string
+		verifier      *routeVerifier
+		options       []RouteOption
+		args          args
+		wantStatus    int
+		wantChallenge string
+	}{
+		{
+			name:       "public route",
+			verifier:   &routeVerifier{err: errors.New("not called")},
+			args:       args{path: "/public/info"},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:          "missing token",
+			verifier:      &routeVerifier{},
+			options:       []RouteOption{WithRealm("api")},
+			args:          args{path: "/resource"},
+			wantStatus:    http.StatusUnauthorized,
+			wantChallenge: `Bearer realm="api"`,
+		},
+		{
+			name:          "invalid token",
+			verifier:      &routeVerifier{err: errors.New("expired")},
+			args:          args{path: "/resource", token: "Bearer token"},
+			wantStatus:    http.StatusUnauthorized,
+			wantChallenge: `Bearer error="invalid_token", error_description="expired"`,
+		},
+		{
+			name:          "invalid audience",
+			verifier:      &routeVerifier{ctx: &routeCtx{audience: []string{"other"}}},
+			options:       []RouteOption{WithAudience("api")},
+			args:          args{path: "/resource", token: "Bearer token"},
+			wantStatus:    http.StatusUnauthorized,
+			wantChallenge: `Bearer error="invalid_token", error_description="invalid audience"`,
+		},
+		{
+			name:          "missing role",
+			verifier:      &routeVerifier{ctx: &routeCtx{}},
+			args:          args{path: "/admin/users", token: "Bearer token"},
+			wantStatus:    http.StatusForbidden,
+			wantChallenge: `Bearer error="insufficient_scope", error_description="missing required role: ` + "`admin`" + `"`,
+		},
+		{
+			name:       "unclean path",
+			verifier:   &routeVerifier{ctx: &routeCtx{}},
+			args:       args{path: "//admin/users", token: "Bearer token"},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "dot segments",
+			verifier:   &routeVerifier{ctx: &routeCtx{}},
+			args:       args{path: "/public/../admin/./users", token: "Bearer token"},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "encoded dot segments into public route",
+			verifier:   &routeVerifier{err: errors.New("not called")},
+			args:       args{path: "/admin/%2e%2e/public/x"},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:          "missing role, subtree redirect",
+			verifier:      &routeVerifier{ctx: &routeCtx{}},
+			args:          args{path: "/admin", token: "Bearer token"},
+			wantStatus:    http.StatusForbidden,
+			wantChallenge: `Bearer error="insufficient_scope", error_description="missing required role: ` + "`admin`" + `"`,
+		},
+		{
+			name:       "method mismatch",
+			verifier:   &routeVerifier{ctx: &routeCtx{}},
+			args:       args{method: http.MethodPost, path: "/reports/1", token: "Bearer token"},
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+		{
+			name:          "bound token without proof",
+			verifier:      &routeVerifier{ctx: &routeCtx{roles: []string{"admin"}, thumbprint: "thumbprint"}},
+			args:          args{path: "/admin/users", token: "Bearer token"},
+			wantStatus:    http.StatusUnauthorized,
+			wantChallenge: `Bearer error="invalid_token", error_description="missing DPoP proof"`,
+		},
+		{
+			name:       "granted role",
+			verifier:   &routeVerifier{ctx: &routeCtx{roles: []string{"admin"}, audience: []string{"api"}}},
+			options:    []RouteOption{WithAudience("api")},
+			args:       args{path: "/admin/users", token: "Bearer token"},
+			wantStatus: http.StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authorizer, err := authorization.New[*routeCtx](context.Background(), zitadel.New("zitadel.cloud"),
+				func(context.Context, *zitadel.Zitadel) (authorization.Verifier[*routeCtx], error) {
+					return tt.verifier, nil
+				},
+			)
+			require.NoError(t, err)
+			requireRoutes, err := New(authorizer).RequireRoutes(routes, tt.options...)
+			require.NoError(t, err)
+
+			method := tt.args.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			req := httptest.NewRequest(method, tt.args.path, nil)
+			if tt.args.token != "" {
+				req.Header.Set(authorization.HeaderName, tt.args.token)
+			}
+			rec := httptest.NewRecorder()
+			requireRoutes(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})).ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			assert.Equal(t, tt.wantChallenge, rec.Header().Get("WWW-Authenticate"))
+		})
+	}
+}
+
+func TestInterceptor_RouteTable_conflictingRoutes(t *testing.T) {
+	_, err := New[*routeCtx](nil).RouteTable([]Route{{Pattern: "/admin/"}, {Pattern: "/admin/"}})
+	assert.Error(t, err)
+}
+
+type routeVerifier struct {
+	ctx *routeCtx
+	err error
+}
+
+func (t *routeVerifier) CheckAuthorization(context.Context, string) (*routeCtx, error) {
+	return t.ctx, t.err
+}
+
+type routeCtx struct {
+	roles      []string
+	audience   []string
+	thumbprint string
+	token      string
+}
+
+func (t *routeCtx) IsAuthorized() bool                           { return t != nil }
+func (t *routeCtx) OrganizationID() string                       { return "" }
+func (t *routeCtx) UserID() string                               { return "user" }
+func (t *routeCtx) IsGrantedRole(role string) bool               { return slices.Contains(t.roles, role) }
+func (t *routeCtx) IsGrantedRoleInOrganization(_, _ string) bool { return false }
+func (t *routeCtx) SetToken(token string)                        { t.token = token }
+func (t *routeCtx) GetToken() string                             { return t.token }
+func (t *routeCtx) GetIssuer() string                            { return "" }
+func (t *routeCtx) GetAudience() []string                        { return t.audience }
+func (t *routeCtx) ConfirmationThumbprint() string               { return t.thumbprint }
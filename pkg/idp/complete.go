@@ -0,0 +1,103 @@
+package idp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/management/users"
+	"github.com/zitadel/zitadel-go/v3/pkg/session"
+)
+
+const pendingCookieSuffix = ".intent"
+
+// ErrNotLinked is returned by [Flow.Finish] if the external user is not linked to a ZITADEL user.
+var ErrNotLinked = errors.New("external user is not linked to a ZITADEL user")
+
+// Profile returns the user described by the [Result.RawInformation], e.g. to prefill a registration form.
+// The standard claims of OIDC are used, as well as the common attributes of Entra ID and GitHub.
+// Fields not provided by the identity provider are empty.
+func (r *Result) Profile() users.HumanUser {
+	user := users.HumanUser{
+		Username:   r.claim("preferred_username", "userPrincipalName", "login"),
+		Email:      r.claim("email", "mail"),
+		GivenName:  r.claim("given_name", "givenName", "first_name"),
+		FamilyName: r.claim("family_name", "surname", "last_name"),
+	}
+	if user.Username == "" {
+		user.Username = r.ExternalUserName
+	}
+	if name := r.claim("name"); user.GivenName == "" && user.FamilyName == "" {
+		if i := strings.LastIndex(name, " "); i > 0 {
+			user.GivenName, user.FamilyName = name[:i], name[i+1:]
+		}
+	}
+	return user
+}
+
+// claim returns the first of the string claims set in the raw information.
+func (r *Result) claim(names ...string) string {
+	for _, name := range names {
+		if value, ok := r.RawInformation[name].(string); ok && value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// SavePending stores the intent of a result not linked yet in a cookie, so the login can be completed
+// after the user filled a registration form or confirmed the link (see [Flow.Pending]).
+func (f *Flow) SavePending(w http.ResponseWriter, result *Result) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     f.cookieName + pendingCookieSuffix,
+		Value:    result.IntentID + ":" + result.IntentToken,
+		Path:     "/",
+		MaxAge:   int(stateLifetime.Seconds()),
+		Secure:   true,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// Pending retrieves the [Result] of the intent stored by [Flow.SavePending] again.
+// [ErrMissingIntent] is returned if there is none, e.g. because the cookie expired.
+func (f *Flow) Pending(r *http.Request) (*Result, error) {
+	cookie, err := r.Cookie(f.cookieName + pendingCookieSuffix)
+	if err != nil {
+		return nil, ErrMissingIntent
+	}
+	id, token, ok := strings.Cut(cookie.Value, ":")
+	if !ok || id == "" || token == "" {
+		return nil, ErrMissingIntent
+	}
+	return f.retrieve(r.Context(), id, token)
+}
+
+// ClearPending removes the intent stored by [Flow.SavePending], e.g. once the login is finished.
+func (f *Flow) ClearPending(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{Name: f.cookieName + pendingCookieSuffix, Path: "/", MaxAge: -1, Secure: true, HttpOnly: true, SameSite: http.SameSiteLaxMode})
+}
+
+// Finish creates a session of the linked user verified by the intent of the external login.
+// The checks of the step (e.g. a lifetime or further checks) are extended by the user and the intent.
+// The session token can then be used to finalize the auth request of the application.
+func (f *Flow) Finish(ctx context.Context, result *Result, step session.Step) (*session.Session, error) {
+	if !result.IsLinked() {
+		return nil, ErrNotLinked
+	}
+	step.Checks = append([]session.Check{
+		session.User(result.UserID),
+		session.IDPIntent(result.IntentID, result.IntentToken),
+	}, step.Checks...)
+	return session.New(f.client).Create(ctx, step)
+}
+
+// Register creates a new ZITADEL user linked to the external user (see [Flow.CreateUser])
+// and finishes the login with a session of it (see [Flow.Finish]).
+func (f *Flow) Register(ctx context.Context, result *Result, user users.HumanUser, step session.Step, opts ...users.Option) (*session.Session, error) {
+	if _, err := f.CreateUser(ctx, result, user, opts...); err != nil {
+		return nil, err
+	}
+	return f.Finish(ctx, result, step)
+}
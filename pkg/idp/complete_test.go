@@ -0,0 +1,97 @@
+package idp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/management/users"
+	"github.com/zitadel/zitadel-go/v3/pkg/session"
+)
+
+func TestResult_Profile(t *testing.T) {
+	tests := []struct {
+		name   string
+		result Result
+		want   users.HumanUser
+	}{
+		{
+			name: "oidc",
+			result: Result{RawInformation: map[string]any{
+				"preferred_username": "alice",
+				"email":              "alice@example.com",
+				"given_name":         "Alice",
+				"family_name":        "Liddell",
+				"name":               "Alice Pleasance Liddell",
+			}},
+			want: users.HumanUser{Username: "alice", Email: "alice@example.com", GivenName: "Alice", FamilyName: "Liddell"},
+		},
+		{
+			name: "entra id",
+			result: Result{RawInformation: map[string]any{
+				"userPrincipalName": "bob@corp.example.com",
+				"mail":              "bob@example.com",
+				"givenName":         "Bob",
+				"surname":           "Builder",
+			}},
+			want: users.HumanUser{Username: "bob@corp.example.com", Email: "bob@example.com", GivenName: "Bob", FamilyName: "Builder"},
+		},
+		{
+			name: "github with full name only",
+			result: Result{
+				ExternalUserName: "ignored",
+				RawInformation:   map[string]any{"login": "octocat", "name": "The Octo Cat", "email": nil},
+			},
+			want: users.HumanUser{Username: "octocat", GivenName: "The Octo", FamilyName: "Cat"},
+		},
+		{
+			name:   "no information",
+			result: Result{ExternalUserName: "carol"},
+			want:   users.HumanUser{Username: "carol"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.result.Profile())
+		})
+	}
+}
+
+func TestFlow_Pending(t *testing.T) {
+	f, err := New(nil, "https://app.example.com/success", "https://app.example.com/failure")
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	f.SavePending(w, &Result{IntentID: "intent", IntentToken: "to:ken"})
+	cookies := w.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.Equal(t, defaultCookieName+pendingCookieSuffix, cookies[0].Name)
+	assert.Equal(t, "intent:to:ken", cookies[0].Value)
+	assert.True(t, cookies[0].HttpOnly)
+
+	for _, value := range []string{"", "intent", "intent:", ":token"} {
+		r := httptest.NewRequest(http.MethodPost, "/register", nil)
+		if value != "" {
+			r.AddCookie(&http.Cookie{Name: defaultCookieName + pendingCookieSuffix, Value: value})
+		}
+		_, err = f.Pending(r)
+		assert.ErrorIs(t, err, ErrMissingIntent, value)
+	}
+
+	w = httptest.NewRecorder()
+	f.ClearPending(w)
+	cookies = w.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.Equal(t, -1, cookies[0].MaxAge)
+}
+
+func TestFlow_Finish_notLinked(t *testing.T) {
+	f, err := New(nil, "https://app.example.com/success", "https://app.example.com/failure")
+	require.NoError(t, err)
+	_, err = f.Finish(context.Background(), &Result{IntentID: "intent", IntentToken: "token"}, session.Step{})
+	assert.ErrorIs(t, err, ErrNotLinked)
+}
@@ -0,0 +1,249 @@
+// Package idp provides a helper for logins with external identity providers (e.g. Google, Entra ID, LDAP)
+// using the intents of the user service (v2), e.g. for custom login UIs.
+//
+// [Flow.Start] redirects the browser to the identity provider. After the login, ZITADEL redirects back to
+// the success URL, where [Flow.Callback] validates the state and retrieves the [Result].
+// If the external user is not yet linked to a ZITADEL user, it can be linked to an existing one ([Flow.Link])
+// or a new user can be created ([Flow.CreateUser]). The intent ID and token of the [Result] can then be used
+// to verify a session (see session.IDPIntent), which [Flow.Finish] and [Flow.Register] do directly.
+// Registration UIs keep the intent across requests by [Flow.SavePending] and [Flow.Pending]
+// and prefill their form by the [Result.Profile].
+//
+// Users migrated from a legacy system are linked to their external identities in bulk by a [Migration].
+package idp
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client"
+	userV2 "github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/user/v2"
+	"github.com/zitadel/zitadel-go/v3/pkg/management/users"
+)
+
+const (
+	defaultCookieName = "zitadel.idp"
+	stateParam        = "state"
+	stateLifetime     = 10 * time.Minute
+)
+
+var (
+	ErrInvalidState   = errors.New("invalid or missing state of the external login")
+	ErrMissingIntent  = errors.New("missing intent of the external login")
+	ErrLoginFailed    = errors.New("external login failed")
+	ErrUnexpectedStep = errors.New("unexpected next step of the intent")
+)
+
+// Flow handles external logins using identity provider intents.
+type Flow struct {
+	client     *client.Client
+	successURL *url.URL
+	failureURL *url.URL
+	cookieName string
+}
+
+// Option allows customization of the [Flow].
+type Option func(*Flow)
+
+// WithCookieName sets the name of the cookie storing the state of the login (default `zitadel.idp`).
+func WithCookieName(name string) Option {
+	return func(f *Flow) {
+		f.cookieName = name
+	}
+}
+
+// New creates the [Flow] using the client, which needs the permissions to start and retrieve intents
+// (e.g. a service user with the IAM_LOGIN_CLIENT role).
+// ZITADEL redirects to the successURL after a successful login, to the failureURL otherwise.
+// Both are usually handled by [Flow.Callback].
+func New(client *client.Client, successURL, failureURL string, opts ...Option) (*Flow, error) {
+	success, err := url.Parse(successURL)
+	if err != nil {
+		return nil, err
+	}
+	failure, err := url.Parse(failureURL)
+	if err != nil {
+		return nil, err
+	}
+	f := &Flow{
+		client:     client,
+		successURL: success,
+		failureURL: failure,
+		cookieName: defaultCookieName,
+	}
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f, nil
+}
+
+// Result is the outcome of a successful external login.
+type Result struct {
+	// IntentID and IntentToken verify the external login in a session check.
+	IntentID    string
+	IntentToken string
+	// UserID is the ZITADEL user linked to the external user, empty if not linked yet.
+	UserID string
+	// IDPID is the ID of the identity provider in ZITADEL.
+	IDPID string
+	// ExternalUserID and ExternalUserName identify the user at the identity provider.
+	ExternalUserID   string
+	ExternalUserName string
+	// RawInformation contains the user information as returned by the identity provider (e.g. the claims of OIDC).
+	RawInformation map[string]any
+	// AccessToken and IDToken are set for OAuth / OIDC providers.
+	AccessToken string
+	IDToken     string
+}
+
+// IsLinked returns if the external user is linked to a ZITADEL user.
+func (r *Result) IsLinked() bool {
+	return r.UserID != ""
+}
+
+// Start starts the login with the identity provider and redirects the browser to it.
+// The state of the login is stored in a cookie and validated by [Flow.Callback].
+func (f *Flow) Start(w http.ResponseWriter, r *http.Request, idpID string) error {
+	state, err := newState()
+	if err != nil {
+		return err
+	}
+	resp, err := f.client.UserServiceV2().StartIdentityProviderIntent(r.Context(), &userV2.StartIdentityProviderIntentRequest{
+		IdpId: idpID,
+		Content: &userV2.StartIdentityProviderIntentRequest_Urls{Urls: &userV2.RedirectURLs{
+			SuccessUrl: withState(f.successURL, state),
+			FailureUrl: withState(f.failureURL, state),
+		}},
+	})
+	if err != nil {
+		return err
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     f.cookieName,
+		Value:    state,
+		Path:     "/",
+		MaxAge:   int(stateLifetime.Seconds()),
+		Secure:   true,
+		HttpOnly: true,
+		// the callback is a top level navigation from ZITADEL
+		SameSite: http.SameSiteLaxMode,
+	})
+	switch step := resp.GetNextStep().(type) {
+	case *userV2.StartIdentityProviderIntentResponse_AuthUrl:
+		http.Redirect(w, r, step.AuthUrl, http.StatusFound)
+	case *userV2.StartIdentityProviderIntentResponse_PostForm:
+		// e.g. SAML with POST binding, the form is submitted automatically
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(step.PostForm)
+	default:
+		return fmt.Errorf("%w: %T", ErrUnexpectedStep, step)
+	}
+	return nil
+}
+
+// StartLDAP verifies the credentials at the LDAP provider directly, no redirect is involved.
+func (f *Flow) StartLDAP(ctx context.Context, idpID, username, password string) (*Result, error) {
+	resp, err := f.client.UserServiceV2().StartIdentityProviderIntent(ctx, &userV2.StartIdentityProviderIntentRequest{
+		IdpId:   idpID,
+		Content: &userV2.StartIdentityProviderIntentRequest_Ldap{Ldap: &userV2.LDAPCredentials{Username: username, Password: password}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	intent := resp.GetIdpIntent()
+	if intent == nil {
+		return nil, fmt.Errorf("%w: %T", ErrUnexpectedStep, resp.GetNextStep())
+	}
+	return f.retrieve(ctx, intent.GetIdpIntentId(), intent.GetIdpIntentToken())
+}
+
+// Callback handles the redirect of ZITADEL to the success or failure URL.
+// It validates the state against the cookie set by [Flow.Start] and retrieves the [Result] of the intent.
+func (f *Flow) Callback(w http.ResponseWriter, r *http.Request) (*Result, error) {
+	query := r.URL.Query()
+	cookie, err := r.Cookie(f.cookieName)
+	http.SetCookie(w, &http.Cookie{Name: f.cookieName, Path: "/", MaxAge: -1, Secure: true, HttpOnly: true, SameSite: http.SameSiteLaxMode})
+	if err != nil || cookie.Value == "" || subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(query.Get(stateParam))) != 1 {
+		return nil, ErrInvalidState
+	}
+	if errorType := query.Get("error"); errorType != "" {
+		return nil, fmt.Errorf("%w: %s: %s", ErrLoginFailed, errorType, query.Get("error_description"))
+	}
+	id, token := query.Get("id"), query.Get("token")
+	if id == "" || token == "" {
+		return nil, ErrMissingIntent
+	}
+	return f.retrieve(r.Context(), id, token)
+}
+
+// Link links the external user of the result to the existing ZITADEL user,
+// e.g. after the user logged in with the password to confirm the link.
+func (f *Flow) Link(ctx context.Context, result *Result, userID string) error {
+	_, err := f.client.UserServiceV2().AddIDPLink(ctx, &userV2.AddIDPLinkRequest{
+		UserId:  userID,
+		IdpLink: &userV2.IDPLink{IdpId: result.IDPID, UserId: result.ExternalUserID, UserName: result.ExternalUserName},
+	})
+	if err != nil {
+		return err
+	}
+	result.UserID = userID
+	return nil
+}
+
+// CreateUser creates a new ZITADEL user linked to the external user of the result.
+// The profile is usually taken from the [Result.RawInformation] of the identity provider.
+func (f *Flow) CreateUser(ctx context.Context, result *Result, user users.HumanUser, opts ...users.Option) (*users.Created, error) {
+	opts = append(opts, users.WithIDPLink(result.IDPID, result.ExternalUserID, result.ExternalUserName))
+	created, err := users.New(f.client).CreateHumanUser(ctx, user, opts...)
+	if err != nil {
+		return nil, err
+	}
+	result.UserID = created.UserID
+	return created, nil
+}
+
+func (f *Flow) retrieve(ctx context.Context, id, token string) (*Result, error) {
+	resp, err := f.client.UserServiceV2().RetrieveIdentityProviderIntent(ctx, &userV2.RetrieveIdentityProviderIntentRequest{
+		IdpIntentId:    id,
+		IdpIntentToken: token,
+	})
+	if err != nil {
+		return nil, err
+	}
+	info := resp.GetIdpInformation()
+	return &Result{
+		IntentID:         id,
+		IntentToken:      token,
+		UserID:           resp.GetUserId(),
+		IDPID:            info.GetIdpId(),
+		ExternalUserID:   info.GetUserId(),
+		ExternalUserName: info.GetUserName(),
+		RawInformation:   info.GetRawInformation().AsMap(),
+		AccessToken:      info.GetOauth().GetAccessToken(),
+		IDToken:          info.GetOauth().GetIdToken(),
+	}, nil
+}
+
+func newState() (string, error) {
+	state := make([]byte, 32)
+	if _, err := rand.Read(state); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(state), nil
+}
+
+// withState adds the state as query parameter, ZITADEL keeps it when appending the intent to the URL.
+func withState(u *url.URL, state string) string {
+	withState := *u
+	query := withState.Query()
+	query.Set(stateParam, state)
+	withState.RawQuery = query.Encode()
+	return withState.String()
+}
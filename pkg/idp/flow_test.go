@@ -0,0 +1,50 @@
+package idp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_withState(t *testing.T) {
+	u, err := url.Parse("https://app.example.com/idp/callback?tenant=acme")
+	require.NoError(t, err)
+	assert.Equal(t, "https://app.example.com/idp/callback?state=abc&tenant=acme", withState(u, "abc"))
+	assert.Equal(t, "https://app.example.com/idp/callback?tenant=acme", u.String())
+}
+
+func TestFlow_Callback(t *testing.T) {
+	f, err := New(nil, "https://app.example.com/success", "https://app.example.com/failure")
+	require.NoError(t, err)
+	tests := []struct {
+		name    string
+		cookie  string
+		query   string
+		wantErr error
+	}{
+		{"missing cookie", "", "state=abc&id=1&token=t", ErrInvalidState},
+		{"state mismatch", "abc", "state=xyz&id=1&token=t", ErrInvalidState},
+		{"missing state", "abc", "id=1&token=t", ErrInvalidState},
+		{"failed login", "abc", "state=abc&id=1&error=access_denied", ErrLoginFailed},
+		{"missing intent", "abc", "state=abc&id=1", ErrMissingIntent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/callback?"+tt.query, nil)
+			if tt.cookie != "" {
+				r.AddCookie(&http.Cookie{Name: defaultCookieName, Value: tt.cookie})
+			}
+			w := httptest.NewRecorder()
+			_, err := f.Callback(w, r)
+			assert.ErrorIs(t, err, tt.wantErr)
+			cookies := w.Result().Cookies()
+			if assert.Len(t, cookies, 1) {
+				assert.Equal(t, -1, cookies[0].MaxAge)
+			}
+		})
+	}
+}
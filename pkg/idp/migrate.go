@@ -0,0 +1,248 @@
+package idp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client"
+	objectV2 "github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/object/v2"
+	userV2 "github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/user/v2"
+)
+
+const linksLimit = 100
+
+var (
+	ErrMissingExternalUser = errors.New("IdP and external user ID are required")
+	// ErrLinkMismatch is returned by [Migration.Verify] if the login resolved to another user than migrated.
+	ErrLinkMismatch = errors.New("external user is not linked as migrated")
+)
+
+// Account is an account of the legacy system with a social or enterprise login (e.g. Sign in with Apple,
+// SAML or a generic OAuth provider) to be linked to a ZITADEL user.
+type Account struct {
+	// Ref identifies the account in the [MigrationReport], e.g. the ID in the legacy system.
+	Ref string
+	// IDPID is the ID of the identity provider configured in ZITADEL.
+	IDPID string
+	// ExternalUserID is the subject at the identity provider, e.g. the `sub` claim of Apple or the SAML NameID.
+	// It must be the same ID the identity provider of ZITADEL returns, or the user won't be found on login.
+	ExternalUserID   string
+	ExternalUserName string
+	// UserID is the ZITADEL user to link, if empty it's searched by the Email or Username (case-insensitive).
+	UserID   string
+	Email    string
+	Username string
+}
+
+// LinkStatus is the outcome of linking an [Account].
+type LinkStatus string
+
+const (
+	LinkStatusLinked        LinkStatus = "linked"
+	LinkStatusAlreadyLinked LinkStatus = "already_linked"
+	// LinkStatusUnmatched means no ZITADEL user matched the account.
+	LinkStatusUnmatched LinkStatus = "unmatched"
+	// LinkStatusAmbiguous means multiple ZITADEL users matched the email or username of the account.
+	LinkStatusAmbiguous LinkStatus = "ambiguous"
+	// LinkStatusConflict means the external user is already linked to another ZITADEL user
+	// or the user is linked to another external user of the identity provider.
+	LinkStatusConflict LinkStatus = "conflict"
+	LinkStatusFailed   LinkStatus = "failed"
+)
+
+// LinkResult is the outcome of linking a single [Account].
+type LinkResult struct {
+	Account Account
+	// UserID is the matched ZITADEL user, empty if unmatched or ambiguous.
+	UserID string
+	Status LinkStatus
+	Err    error
+}
+
+// MigrationReport summarizes a [Migration.LinkAll].
+type MigrationReport struct {
+	// Results of all accounts in the order of the input.
+	Results []LinkResult
+	// Counts are the number of results per status.
+	Counts map[LinkStatus]int
+}
+
+// Unmatched returns the results of the accounts which couldn't be linked,
+// i.e. all except linked and already linked ones.
+func (r *MigrationReport) Unmatched() []LinkResult {
+	var unmatched []LinkResult
+	for _, result := range r.Results {
+		if result.Status != LinkStatusLinked && result.Status != LinkStatusAlreadyLinked {
+			unmatched = append(unmatched, result)
+		}
+	}
+	return unmatched
+}
+
+// Sample returns up to n randomly chosen linked results, e.g. to verify them by a login (see [Migration.Verify]).
+func (r *MigrationReport) Sample(n int) []LinkResult {
+	var linked []LinkResult
+	for _, result := range r.Results {
+		if result.Status == LinkStatusLinked || result.Status == LinkStatusAlreadyLinked {
+			linked = append(linked, result)
+		}
+	}
+	rand.Shuffle(len(linked), func(i, j int) { linked[i], linked[j] = linked[j], linked[i] })
+	return linked[:min(n, len(linked))]
+}
+
+// Migration links the accounts of users migrated from a legacy system to ZITADEL users,
+// so they can continue to log in with their external identity provider.
+type Migration struct {
+	service userV2.UserServiceClient
+	dryRun  bool
+}
+
+// MigrationOption allows customization of the [Migration].
+type MigrationOption func(*Migration)
+
+// WithDryRun only matches the accounts and reports the results without adding any links.
+func WithDryRun() MigrationOption {
+	return func(m *Migration) {
+		m.dryRun = true
+	}
+}
+
+// NewMigration creates a [Migration] using the client, which needs the permissions to read and link users.
+func NewMigration(client *client.Client, opts ...MigrationOption) *Migration {
+	m := &Migration{service: client.UserServiceV2()}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// LinkAll links the external users of the accounts to the matching ZITADEL users.
+// Existing links are kept, failures of single accounts are reported in the [MigrationReport]
+// and don't stop the migration. Only an error of the context does.
+func (m *Migration) LinkAll(ctx context.Context, accounts []Account) (*MigrationReport, error) {
+	report := &MigrationReport{
+		Results: make([]LinkResult, 0, len(accounts)),
+		Counts:  make(map[LinkStatus]int),
+	}
+	for _, account := range accounts {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		result := m.link(ctx, account)
+		report.Results = append(report.Results, result)
+		report.Counts[result.Status]++
+	}
+	return report, nil
+}
+
+func (m *Migration) link(ctx context.Context, account Account) LinkResult {
+	result := LinkResult{Account: account}
+	if account.IDPID == "" || account.ExternalUserID == "" {
+		result.Status, result.Err = LinkStatusFailed, ErrMissingExternalUser
+		return result
+	}
+	userID, matchStatus, err := m.match(ctx, account)
+	if matchStatus != "" {
+		result.Status, result.Err = matchStatus, err
+		return result
+	}
+	result.UserID = userID
+	links, err := m.service.ListIDPLinks(ctx, &userV2.ListIDPLinksRequest{UserId: userID, Query: &objectV2.ListQuery{Limit: linksLimit}})
+	if err != nil {
+		result.Status, result.Err = LinkStatusFailed, err
+		return result
+	}
+	for _, link := range links.GetResult() {
+		if link.GetIdpId() != account.IDPID {
+			continue
+		}
+		if link.GetUserId() == account.ExternalUserID {
+			result.Status = LinkStatusAlreadyLinked
+			return result
+		}
+		result.Status = LinkStatusConflict
+		result.Err = fmt.Errorf("user is linked to external user %s of the IdP", link.GetUserId())
+		return result
+	}
+	if m.dryRun {
+		result.Status = LinkStatusLinked
+		return result
+	}
+	_, err = m.service.AddIDPLink(ctx, &userV2.AddIDPLinkRequest{
+		UserId:  userID,
+		IdpLink: &userV2.IDPLink{IdpId: account.IDPID, UserId: account.ExternalUserID, UserName: account.ExternalUserName},
+	})
+	switch status.Code(err) {
+	case codes.OK:
+		result.Status = LinkStatusLinked
+	case codes.AlreadyExists:
+		// the external user is linked to another ZITADEL user
+		result.Status, result.Err = LinkStatusConflict, err
+	default:
+		result.Status, result.Err = LinkStatusFailed, err
+	}
+	return result
+}
+
+// match returns the ID of the ZITADEL user of the account or the status if none or multiple matched.
+func (m *Migration) match(ctx context.Context, account Account) (string, LinkStatus, error) {
+	if account.UserID != "" {
+		return account.UserID, "", nil
+	}
+	var query *userV2.SearchQuery
+	switch {
+	case account.Email != "":
+		query = &userV2.SearchQuery{Query: &userV2.SearchQuery_EmailQuery{
+			EmailQuery: &userV2.EmailQuery{EmailAddress: account.Email, Method: objectV2.TextQueryMethod_TEXT_QUERY_METHOD_EQUALS_IGNORE_CASE},
+		}}
+	case account.Username != "":
+		query = &userV2.SearchQuery{Query: &userV2.SearchQuery_UserNameQuery{
+			UserNameQuery: &userV2.UserNameQuery{UserName: account.Username, Method: objectV2.TextQueryMethod_TEXT_QUERY_METHOD_EQUALS_IGNORE_CASE},
+		}}
+	default:
+		return "", LinkStatusUnmatched, nil
+	}
+	resp, err := m.service.ListUsers(ctx, &userV2.ListUsersRequest{
+		Query:   &objectV2.ListQuery{Limit: 2},
+		Queries: []*userV2.SearchQuery{query},
+	})
+	if err != nil {
+		return "", LinkStatusFailed, err
+	}
+	switch len(resp.GetResult()) {
+	case 0:
+		return "", LinkStatusUnmatched, nil
+	case 1:
+		return resp.GetResult()[0].GetUserId(), "", nil
+	default:
+		return "", LinkStatusAmbiguous, nil
+	}
+}
+
+// Verify checks the link of a migrated account using the intent of a login with the identity provider
+// (e.g. of a test account of the [MigrationReport.Sample] logged in by [Flow.Start] and [Flow.Callback]):
+// ZITADEL must resolve the external user to the migrated ZITADEL user, otherwise [ErrLinkMismatch] is returned.
+func (m *Migration) Verify(ctx context.Context, expected LinkResult, intentID, intentToken string) error {
+	resp, err := m.service.RetrieveIdentityProviderIntent(ctx, &userV2.RetrieveIdentityProviderIntentRequest{
+		IdpIntentId:    intentID,
+		IdpIntentToken: intentToken,
+	})
+	if err != nil {
+		return err
+	}
+	info := resp.GetIdpInformation()
+	if info.GetIdpId() != expected.Account.IDPID || info.GetUserId() != expected.Account.ExternalUserID {
+		return fmt.Errorf("%w: login of external user %s of IdP %s instead of %s of %s", ErrLinkMismatch,
+			info.GetUserId(), info.GetIdpId(), expected.Account.ExternalUserID, expected.Account.IDPID)
+	}
+	if resp.GetUserId() != expected.UserID {
+		return fmt.Errorf("%w: resolved to user %q instead of %q", ErrLinkMismatch, resp.GetUserId(), expected.UserID)
+	}
+	return nil
+}
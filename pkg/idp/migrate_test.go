@@ -0,0 +1,131 @@
+package idp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	userV2 "github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/user/v2"
+)
+
+type fakeUserService struct {
+	userV2.UserServiceClient
+	// usersByEmail are the IDs of the users with an email
+	usersByEmail map[string][]string
+	// links of the users
+	links map[string][]*userV2.IDPLink
+	// linkedExternal are the external user IDs linked to any user
+	linkedExternal map[string]bool
+	added          []*userV2.AddIDPLinkRequest
+	intent         *userV2.RetrieveIdentityProviderIntentResponse
+}
+
+func (f *fakeUserService) ListUsers(_ context.Context, req *userV2.ListUsersRequest, _ ...grpc.CallOption) (*userV2.ListUsersResponse, error) {
+	resp := &userV2.ListUsersResponse{}
+	for _, id := range f.usersByEmail[req.GetQueries()[0].GetEmailQuery().GetEmailAddress()] {
+		resp.Result = append(resp.Result, &userV2.User{UserId: id})
+	}
+	return resp, nil
+}
+
+func (f *fakeUserService) ListIDPLinks(_ context.Context, req *userV2.ListIDPLinksRequest, _ ...grpc.CallOption) (*userV2.ListIDPLinksResponse, error) {
+	return &userV2.ListIDPLinksResponse{Result: f.links[req.GetUserId()]}, nil
+}
+
+func (f *fakeUserService) AddIDPLink(_ context.Context, req *userV2.AddIDPLinkRequest, _ ...grpc.CallOption) (*userV2.AddIDPLinkResponse, error) {
+	if f.linkedExternal[req.GetIdpLink().GetUserId()] {
+		return nil, status.Error(codes.AlreadyExists, "already linked")
+	}
+	f.added = append(f.added, req)
+	return &userV2.AddIDPLinkResponse{}, nil
+}
+
+func (f *fakeUserService) RetrieveIdentityProviderIntent(context.Context, *userV2.RetrieveIdentityProviderIntentRequest, ...grpc.CallOption) (*userV2.RetrieveIdentityProviderIntentResponse, error) {
+	return f.intent, nil
+}
+
+func TestMigration_LinkAll(t *testing.T) {
+	service := &fakeUserService{
+		usersByEmail: map[string][]string{
+			"alice@example.com": {"u1"},
+			"bob@example.com":   {"u2"},
+			"twins@example.com": {"u3", "u4"},
+			"carol@example.com": {"u5"},
+			"dave@example.com":  {"u6"},
+		},
+		links: map[string][]*userV2.IDPLink{
+			"u2": {{IdpId: "apple", UserId: "bob.apple"}},
+			"u5": {{IdpId: "apple", UserId: "carol.other"}},
+		},
+		linkedExternal: map[string]bool{"dave.apple": true},
+	}
+	accounts := []Account{
+		{Ref: "1", IDPID: "apple", ExternalUserID: "alice.apple", Email: "alice@example.com"},
+		{Ref: "2", IDPID: "apple", ExternalUserID: "bob.apple", Email: "bob@example.com"},
+		{Ref: "3", IDPID: "apple", ExternalUserID: "twin.apple", Email: "twins@example.com"},
+		{Ref: "4", IDPID: "apple", ExternalUserID: "carol.apple", Email: "carol@example.com"},
+		{Ref: "5", IDPID: "apple", ExternalUserID: "dave.apple", Email: "dave@example.com"},
+		{Ref: "6", IDPID: "apple", ExternalUserID: "erin.apple", Email: "erin@example.com"},
+		{Ref: "7", IDPID: "saml", ExternalUserID: "frank@corp", UserID: "u7"},
+		{Ref: "8", IDPID: "saml"},
+	}
+
+	report, err := (&Migration{service: service}).LinkAll(context.Background(), accounts)
+	require.NoError(t, err)
+	statuses := make([]LinkStatus, len(report.Results))
+	for i, result := range report.Results {
+		statuses[i] = result.Status
+	}
+	assert.Equal(t, []LinkStatus{
+		LinkStatusLinked,
+		LinkStatusAlreadyLinked,
+		LinkStatusAmbiguous,
+		LinkStatusConflict,
+		LinkStatusConflict,
+		LinkStatusUnmatched,
+		LinkStatusLinked,
+		LinkStatusFailed,
+	}, statuses)
+	assert.Equal(t, 2, report.Counts[LinkStatusLinked])
+	assert.Len(t, report.Unmatched(), 5)
+	assert.ErrorIs(t, report.Results[7].Err, ErrMissingExternalUser)
+	require.Len(t, service.added, 2)
+	assert.Equal(t, "u1", service.added[0].GetUserId())
+	assert.Equal(t, "frank@corp", service.added[1].GetIdpLink().GetUserId())
+
+	sample := report.Sample(10)
+	assert.Len(t, sample, 3)
+	assert.Len(t, report.Sample(1), 1)
+
+	t.Run("dry run", func(t *testing.T) {
+		service.added = nil
+		report, err := (&Migration{service: service, dryRun: true}).LinkAll(context.Background(), accounts[:1])
+		require.NoError(t, err)
+		assert.Equal(t, LinkStatusLinked, report.Results[0].Status)
+		assert.Empty(t, service.added)
+	})
+}
+
+func TestMigration_Verify(t *testing.T) {
+	expected := LinkResult{Account: Account{IDPID: "apple", ExternalUserID: "alice.apple"}, UserID: "u1", Status: LinkStatusLinked}
+	service := &fakeUserService{}
+	m := &Migration{service: service}
+	intent := func(idpID, externalUserID, userID string) *userV2.RetrieveIdentityProviderIntentResponse {
+		return &userV2.RetrieveIdentityProviderIntentResponse{
+			IdpInformation: &userV2.IDPInformation{IdpId: idpID, UserId: externalUserID},
+			UserId:         userID,
+		}
+	}
+
+	service.intent = intent("apple", "alice.apple", "u1")
+	assert.NoError(t, m.Verify(context.Background(), expected, "intent", "token"))
+	service.intent = intent("apple", "alice.apple", "")
+	assert.ErrorIs(t, m.Verify(context.Background(), expected, "intent", "token"), ErrLinkMismatch)
+	service.intent = intent("apple", "bob.apple", "u2")
+	assert.ErrorIs(t, m.Verify(context.Background(), expected, "intent", "token"), ErrLinkMismatch)
+}
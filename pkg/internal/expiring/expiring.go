@@ -0,0 +1,80 @@
+// Package expiring provides a set of keys which expire at a point in time,
+// e.g. to remember used or issued one-time values.
+package expiring
+
+import (
+	"container/heap"
+	"time"
+)
+
+// Set is a set of keys with an expiration each.
+// Expired keys are evicted in the order of their expiration, so each operation only touches the expired keys.
+// It's not safe for concurrent use.
+type Set struct {
+	keys  map[string]time.Time
+	queue queue
+	now   func() time.Time
+}
+
+// New creates an empty [Set].
+func New() *Set {
+	return &Set{keys: make(map[string]time.Time), now: time.Now}
+}
+
+// Add stores the key until the expiration.
+// It returns false without changing the expiration if the key is already stored.
+func (s *Set) Add(key string, expiration time.Time) bool {
+	s.evict()
+	if _, ok := s.keys[key]; ok {
+		return false
+	}
+	s.keys[key] = expiration
+	heap.Push(&s.queue, entry{key: key, expiration: expiration})
+	return true
+}
+
+// Remove deletes the key and returns whether it was stored and not expired.
+func (s *Set) Remove(key string) bool {
+	s.evict()
+	if _, ok := s.keys[key]; !ok {
+		return false
+	}
+	// the entry stays in the queue until it expires, evict ignores it if the key was stored again with another expiration
+	delete(s.keys, key)
+	return true
+}
+
+// Len returns the number of keys, which are not expired.
+func (s *Set) Len() int {
+	s.evict()
+	return len(s.keys)
+}
+
+func (s *Set) evict() {
+	now := s.now()
+	for len(s.queue) > 0 && !s.queue[0].expiration.After(now) {
+		e := heap.Pop(&s.queue).(entry)
+		if expiration, ok := s.keys[e.key]; ok && expiration.Equal(e.expiration) {
+			delete(s.keys, e.key)
+		}
+	}
+}
+
+type entry struct {
+	key        string
+	expiration time.Time
+}
+
+// queue is a min-heap of the entries by their expiration.
+type queue []entry
+
+func (q queue) Len() int           { return len(q) }
+func (q queue) Less(i, j int) bool { return q[i].expiration.Before(q[j].expiration) }
+func (q queue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q *queue) Push(x any)        { *q = append(*q, x.(entry)) }
+func (q *queue) Pop() any {
+	old := *q
+	e := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return e
+}
@@ -0,0 +1,44 @@
+package expiring
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSet(t *testing.T) {
+	now := time.Now()
+	s := New()
+	s.now = func() time.Time { return now }
+
+	assert.True(t, s.Add("a", now.Add(time.Minute)))
+	assert.True(t, s.Add("b", now.Add(2*time.Minute)))
+	assert.False(t, s.Add("a", now.Add(time.Hour)), "already stored")
+	assert.Equal(t, 2, s.Len())
+
+	assert.True(t, s.Remove("b"))
+	assert.False(t, s.Remove("b"), "already removed")
+	assert.False(t, s.Remove("unknown"))
+
+	now = now.Add(time.Minute)
+	assert.Equal(t, 0, s.Len(), "a expired")
+	assert.False(t, s.Remove("a"))
+	assert.True(t, s.Add("a", now.Add(time.Minute)), "expired keys can be added again")
+}
+
+func TestSet_evictsInOrder(t *testing.T) {
+	now := time.Now()
+	s := New()
+	s.now = func() time.Time { return now }
+	for i := 10; i > 0; i-- {
+		s.Add(string(rune('a'+i)), now.Add(time.Duration(i)*time.Second))
+	}
+	s.Remove("c")
+	assert.True(t, s.Add("c", now.Add(time.Hour)), "stored again with a later expiration")
+
+	now = now.Add(5 * time.Second)
+	assert.Equal(t, 6, s.Len())
+	assert.Len(t, s.queue, 6, "only the expired entries are evicted")
+	assert.True(t, s.Remove("c"), "the earlier expiration of the removed key is ignored")
+}
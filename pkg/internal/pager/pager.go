@@ -0,0 +1,24 @@
+// Package pager requests all pages of the list calls of the ZITADEL APIs.
+package pager
+
+import (
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/object"
+)
+
+// PageSize is the number of results requested per list call.
+const PageSize = 100
+
+// ListAll requests all pages of a list call, until the total result of the details is reached or a page is empty.
+func ListAll[T any](list func(query *object.ListQuery) ([]T, *object.ListDetails, error)) ([]T, error) {
+	var all []T
+	for {
+		result, details, err := list(&object.ListQuery{Offset: uint64(len(all)), Limit: PageSize, Asc: true})
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, result...)
+		if len(result) == 0 || uint64(len(all)) >= details.GetTotalResult() {
+			return all, nil
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package pager
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/object"
+)
+
+func TestListAll(t *testing.T) {
+	items := make([]int, 2*PageSize+1)
+	for i := range items {
+		items[i] = i
+	}
+	tests := []struct {
+		name      string
+		total     uint64
+		wantCalls int
+		want      []int
+	}{
+		{"all pages", uint64(len(items)), 3, items},
+		{"total reached", PageSize, 1, items[:PageSize]},
+		{"empty page", 10 * PageSize, 4, items},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int
+			got, err := ListAll(func(query *object.ListQuery) ([]int, *object.ListDetails, error) {
+				calls++
+				assert.True(t, query.GetAsc())
+				start := min(query.GetOffset(), uint64(len(items)))
+				end := min(start+uint64(query.GetLimit()), uint64(len(items)))
+				return items[start:end], &object.ListDetails{TotalResult: tt.total}, nil
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantCalls, calls)
+		})
+	}
+}
+
+func TestListAll_error(t *testing.T) {
+	errList := errors.New("unavailable")
+	_, err := ListAll(func(*object.ListQuery) ([]int, *object.ListDetails, error) {
+		return nil, nil, errList
+	})
+	assert.ErrorIs(t, err, errList)
+}
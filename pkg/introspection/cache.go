@@ -0,0 +1,94 @@
+package introspection
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// MemoryCache is an in-memory cache with a maximum number of entries, which expire after their ttl.
+// If it's full, the expired entries are evicted, if it's still full, any entry.
+// It caches the results of the [Introspector] and can be used for other results of introspections,
+// e.g. as cache of the gateway package.
+type MemoryCache[V any] struct {
+	size int
+	now  func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]memoryEntry[V]
+}
+
+type memoryEntry[V any] struct {
+	value      V
+	expiration time.Time
+}
+
+// NewMemoryCache creates a [MemoryCache] holding up to size entries.
+func NewMemoryCache[V any](size int) *MemoryCache[V] {
+	return newMemoryCache[V](size, time.Now)
+}
+
+func newMemoryCache[V any](size int, now func() time.Time) *MemoryCache[V] {
+	return &MemoryCache[V]{
+		size:    size,
+		now:     now,
+		entries: make(map[string]memoryEntry[V]),
+	}
+}
+
+// Get returns the value of the key, false if there is none or it expired.
+func (c *MemoryCache[V]) Get(_ context.Context, key string) (value V, ok bool, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		return value, false, nil
+	}
+	if !c.now().Before(e.expiration) {
+		delete(c.entries, key)
+		return value, false, nil
+	}
+	return e.value, true, nil
+}
+
+// Set stores the value of the key for the ttl.
+func (c *MemoryCache[V]) Set(_ context.Context, key string, value V, ttl time.Duration) error {
+	if ttl <= 0 || c.size <= 0 {
+		return nil
+	}
+	now := c.now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) >= c.size {
+		for k, e := range c.entries {
+			if !now.Before(e.expiration) {
+				delete(c.entries, k)
+			}
+		}
+	}
+	// still full: evict any entry
+	for k := range c.entries {
+		if len(c.entries) < c.size {
+			break
+		}
+		delete(c.entries, k)
+	}
+	c.entries[key] = memoryEntry[V]{value: value, expiration: now.Add(ttl)}
+	return nil
+}
+
+// Delete removes the value of the key.
+func (c *MemoryCache[V]) Delete(_ context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, key)
+	return nil
+}
+
+// CacheKey returns the key of the token in caches, a hash of the token, so no valid tokens are kept in them.
+func CacheKey(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
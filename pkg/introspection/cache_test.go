@@ -0,0 +1,41 @@
+package introspection
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryCache(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	c := newMemoryCache[string](2, func() time.Time { return now })
+
+	require.NoError(t, c.Set(ctx, "short", "a", time.Second))
+	require.NoError(t, c.Set(ctx, "long", "b", time.Hour))
+	require.NoError(t, c.Set(ctx, "ignored", "c", 0))
+	now = now.Add(2 * time.Second)
+
+	// the expired entry is evicted first
+	require.NoError(t, c.Set(ctx, "new", "d", time.Hour))
+	value, ok, err := c.Get(ctx, "long")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "b", value)
+	_, ok, _ = c.Get(ctx, "ignored")
+	assert.False(t, ok)
+
+	// still full: any entry is evicted
+	require.NoError(t, c.Set(ctx, "other", "e", time.Hour))
+	assert.Len(t, c.entries, 2)
+	value, ok, _ = c.Get(ctx, "other")
+	assert.True(t, ok)
+	assert.Equal(t, "e", value)
+
+	require.NoError(t, c.Delete(ctx, "other"))
+	_, ok, _ = c.Get(ctx, "other")
+	assert.False(t, ok)
+}
@@ -0,0 +1,136 @@
+// Package introspection provides a client for the OAuth2 token introspection endpoint of ZITADEL
+// caching the results locally, so resource servers don't need to call ZITADEL on every request.
+package introspection
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/zitadel/oidc/v3/pkg/client/rs"
+	"github.com/zitadel/oidc/v3/pkg/oidc"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/authorization/oauth"
+	"github.com/zitadel/zitadel-go/v3/pkg/zitadel"
+)
+
+const (
+	// DefaultMaxTTL is the max duration a result is cached, even if the token is valid longer.
+	DefaultMaxTTL = 5 * time.Minute
+	// DefaultCacheSize is the max number of cached results.
+	DefaultCacheSize = 10000
+)
+
+var (
+	ErrInactiveToken       = errors.New("token is not active")
+	ErrIntrospectionFailed = errors.New("token introspection failed")
+)
+
+// Claims are the claims of an active token returned by the introspection endpoint.
+type Claims struct {
+	oidc.IntrospectionResponse
+}
+
+// ConfirmationThumbprint returns the `jkt` of the `cnf` claim, which is the JWK thumbprint of the key
+// a DPoP bound token is bound to.
+func (c *Claims) ConfirmationThumbprint() string {
+	if c == nil {
+		return ""
+	}
+	cnf, _ := c.IntrospectionResponse.Claims["cnf"].(map[string]any)
+	jkt, _ := cnf["jkt"].(string)
+	return jkt
+}
+
+// Introspector calls the introspection endpoint of ZITADEL.
+// Results of active tokens are cached until the token expires, but at most for the max TTL (see [WithMaxTTL]),
+// so revocations are taken into account after that duration.
+type Introspector struct {
+	resourceServer rs.ResourceServer
+	maxTTL         time.Duration
+	inactiveTTL    time.Duration
+	cacheSize      int
+	now            func() time.Time
+
+	// cache contains nil claims for inactive tokens
+	cache *MemoryCache[*Claims]
+}
+
+// Option allows customization of the [Introspector].
+type Option func(*Introspector)
+
+// WithMaxTTL sets the max duration an active result is cached (default 5 minutes).
+// A zero duration disables the cache.
+func WithMaxTTL(ttl time.Duration) Option {
+	return func(i *Introspector) {
+		i.maxTTL = ttl
+	}
+}
+
+// WithInactiveTTL sets the duration inactive results are cached (default: not cached),
+// protecting ZITADEL against repeated requests with invalid tokens.
+func WithInactiveTTL(ttl time.Duration) Option {
+	return func(i *Introspector) {
+		i.inactiveTTL = ttl
+	}
+}
+
+// WithCacheSize sets the max number of cached results (default 10000).
+func WithCacheSize(size int) Option {
+	return func(i *Introspector) {
+		i.cacheSize = size
+	}
+}
+
+// New creates an [Introspector] authenticating on the introspection endpoint with the provided authentication,
+// e.g. [oauth.JWTProfileIntrospectionAuthentication] or [oauth.ClientIDSecretIntrospectionAuthentication].
+func New(ctx context.Context, zitadel *zitadel.Zitadel, auth oauth.IntrospectionAuthentication, options ...Option) (*Introspector, error) {
+	resourceServer, err := auth(ctx, zitadel.Origin())
+	if err != nil {
+		return nil, err
+	}
+	i := &Introspector{
+		resourceServer: resourceServer,
+		maxTTL:         DefaultMaxTTL,
+		cacheSize:      DefaultCacheSize,
+		now:            time.Now,
+	}
+	for _, option := range options {
+		option(i)
+	}
+	i.cache = newMemoryCache[*Claims](i.cacheSize, func() time.Time { return i.now() })
+	return i, nil
+}
+
+// Introspect returns the claims of the (access) token.
+// If the token is not active, [ErrInactiveToken] is returned.
+func (i *Introspector) Introspect(ctx context.Context, token string) (*Claims, error) {
+	key := CacheKey(token)
+	if claims, ok, _ := i.cache.Get(ctx, key); ok {
+		if claims == nil {
+			return nil, ErrInactiveToken
+		}
+		return claims, nil
+	}
+	resp, err := rs.Introspect[*oidc.IntrospectionResponse](ctx, i.resourceServer, token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrIntrospectionFailed, err)
+	}
+	if !resp.Active {
+		_ = i.cache.Set(ctx, key, nil, i.inactiveTTL)
+		return nil, ErrInactiveToken
+	}
+	claims := &Claims{IntrospectionResponse: *resp}
+	ttl := i.maxTTL
+	if exp := resp.Expiration.AsTime(); !exp.IsZero() && exp.Sub(i.now()) < ttl {
+		ttl = exp.Sub(i.now())
+	}
+	_ = i.cache.Set(ctx, key, claims, ttl)
+	return claims, nil
+}
+
+// Invalidate removes the cached result of the token, e.g. after it has been revoked.
+func (i *Introspector) Invalidate(token string) {
+	_ = i.cache.Delete(context.Background(), CacheKey(token))
+}
@@ -0,0 +1,69 @@
+package introspection
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/authorization/oauth"
+	"github.com/zitadel/zitadel-go/v3/pkg/zitadel"
+)
+
+func TestIntrospector_Introspect(t *testing.T) {
+	exp := time.Now().Add(time.Minute).Unix()
+	var calls int
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 server.URL,
+			"introspection_endpoint": server.URL + "/oauth/v2/introspect",
+			"token_endpoint":         server.URL + "/oauth/v2/token",
+		})
+	})
+	mux.HandleFunc("/oauth/v2/introspect", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		_ = r.ParseForm()
+		if r.PostForm.Get("token") != "active" {
+			_ = json.NewEncoder(w).Encode(map[string]any{"active": false})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"active": true, "sub": "user1", "exp": exp})
+	})
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	introspector, err := New(context.Background(),
+		zitadel.New(u.Hostname(), zitadel.WithInsecure(u.Port())),
+		oauth.ClientIDSecretIntrospectionAuthentication("client", "secret"),
+	)
+	require.NoError(t, err)
+	now := time.Now()
+	introspector.now = func() time.Time { return now }
+
+	claims, err := introspector.Introspect(context.Background(), "active")
+	require.NoError(t, err)
+	assert.Equal(t, "user1", claims.Subject)
+	_, err = introspector.Introspect(context.Background(), "active")
+	require.NoError(t, err)
+	assert.Equal(t, 1, calls, "active result must be cached")
+
+	_, err = introspector.Introspect(context.Background(), "inactive")
+	assert.ErrorIs(t, err, ErrInactiveToken)
+	_, err = introspector.Introspect(context.Background(), "inactive")
+	assert.ErrorIs(t, err, ErrInactiveToken)
+	assert.Equal(t, 3, calls, "inactive result must not be cached by default")
+
+	now = now.Add(2 * time.Minute)
+	_, err = introspector.Introspect(context.Background(), "active")
+	require.NoError(t, err)
+	assert.Equal(t, 4, calls, "result must expire with the token")
+}
@@ -0,0 +1,288 @@
+// Package job provides the cooperative pause / resume and the progress persistence of the bulk operations
+// of the SDK (e.g. [users.Users.BulkImport], [grants.Grants.BulkReassign] or the [audit.Exporter]).
+//
+// A [Controller] is passed to the bulk operation, which reports its progress and waits at safe points while paused.
+// If a [Store] is configured, the progress is persisted, so a canceled operation (or crashed process)
+// continues where it stopped when the operation is started again with a controller of the same ID:
+//
+//	store, err := job.NewFileStore("/var/lib/jobs")
+//	ctrl := job.New("import-2024-01", job.WithStore(store))
+//	go func() { report, err = u.BulkImport(ctx, users, users.BulkOptions{Job: ctrl}) }()
+//	ctrl.Pause()
+//	fmt.Println(ctrl.Progress().Done)
+//	ctrl.Resume()
+//
+// All methods can be called on a nil controller, so the operations don't need to check if one is set.
+package job
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// DefaultSaveEvery is the number of progress updates after which the progress is persisted.
+const DefaultSaveEvery = 100
+
+// State of a job.
+type State string
+
+const (
+	StatePending   State = "pending"
+	StateRunning   State = "running"
+	StatePaused    State = "paused"
+	StateCompleted State = "completed"
+	StateFailed    State = "failed"
+	StateCanceled  State = "canceled"
+)
+
+// Progress of a job.
+type Progress struct {
+	State State `json:"state"`
+	// Done and Failed are the number of handled items, including the ones of previous runs.
+	Done   int `json:"done"`
+	Failed int `json:"failed"`
+	// Total is the number of items to handle, zero if unknown.
+	Total int `json:"total,omitempty"`
+	// Cursor is the position to continue from, its format is specific to the operation.
+	Cursor string `json:"cursor,omitempty"`
+	// Err is the error the job failed with.
+	Err string `json:"error,omitempty"`
+	// LastError is the error of the last failed item.
+	LastError string    `json:"lastError,omitempty"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Job is the common interface of the running bulk operations.
+type Job interface {
+	// Pause pauses the job at its next safe point.
+	Pause()
+	// Resume continues a paused job.
+	Resume()
+	// Progress returns the current progress.
+	Progress() Progress
+}
+
+var _ Job = (*Controller)(nil)
+
+// Controller controls a bulk operation, see the package documentation.
+type Controller struct {
+	id        string
+	store     Store
+	saveEvery int
+
+	mu       sync.Mutex
+	progress Progress
+	resumed  chan struct{}
+	unsaved  int
+	// startedAt, finishedAt, pausedAt, pausedFor and handledBefore are used for the rate of the current run
+	// (see [Controller.Report])
+	startedAt     time.Time
+	finishedAt    time.Time
+	pausedAt      time.Time
+	pausedFor     time.Duration
+	handledBefore int
+	now           func() time.Time
+}
+
+// Option allows customization of the [Controller].
+type Option func(*Controller)
+
+// WithStore persists the progress in the store.
+func WithStore(store Store) Option {
+	return func(c *Controller) {
+		c.store = store
+	}
+}
+
+// WithSaveEvery sets the number of progress updates after which the progress is persisted (default 100).
+// The progress is always persisted when the job is paused or ends.
+func WithSaveEvery(n int) Option {
+	return func(c *Controller) {
+		c.saveEvery = n
+	}
+}
+
+// New creates the [Controller] of the job with the ID, which identifies the progress in the [Store].
+func New(id string, opts ...Option) *Controller {
+	c := &Controller{
+		id:        id,
+		saveEvery: DefaultSaveEvery,
+		progress:  Progress{State: StatePending},
+		now:       time.Now,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// ID returns the ID of the job.
+func (c *Controller) ID() string {
+	if c == nil {
+		return ""
+	}
+	return c.id
+}
+
+// Pause implements [Job].
+func (c *Controller) Pause() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.resumed == nil {
+		c.resumed = make(chan struct{})
+	}
+	if c.progress.State == StateRunning || c.progress.State == StatePending {
+		c.progress.State = StatePaused
+		c.pausedAt = c.now()
+	}
+}
+
+// Resume implements [Job].
+func (c *Controller) Resume() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.resumed != nil {
+		close(c.resumed)
+		c.resumed = nil
+	}
+	if c.progress.State == StatePaused {
+		c.progress.State = StateRunning
+	}
+	if !c.pausedAt.IsZero() {
+		// a pause before the start is not part of the run
+		if !c.startedAt.IsZero() && c.pausedAt.After(c.startedAt) {
+			c.pausedFor += c.now().Sub(c.pausedAt)
+		}
+		c.pausedAt = time.Time{}
+	}
+}
+
+// Progress implements [Job].
+func (c *Controller) Progress() Progress {
+	if c == nil {
+		return Progress{}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.progress
+}
+
+// Start is called by the operation when it starts. It restores and returns the persisted progress of a previous run,
+// the operation continues from its [Progress.Cursor].
+func (c *Controller) Start(ctx context.Context) (Progress, error) {
+	if c == nil {
+		return Progress{}, nil
+	}
+	var saved *Progress
+	if c.store != nil {
+		var err error
+		saved, err = c.store.Load(ctx, c.id)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return Progress{}, err
+		}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if saved != nil {
+		state := c.progress.State
+		c.progress = *saved
+		c.progress.Err = ""
+		c.progress.State = state
+	}
+	if c.progress.State != StatePaused {
+		c.progress.State = StateRunning
+	}
+	c.startedAt = c.now()
+	c.finishedAt = time.Time{}
+	c.pausedFor = 0
+	if !c.pausedAt.IsZero() {
+		c.pausedAt = c.startedAt
+	}
+	c.handledBefore = c.progress.Done + c.progress.Failed
+	return c.progress, nil
+}
+
+// Wait is called by the operation at safe points. It blocks while the job is paused
+// and returns the error of the context if it's done before the job is resumed.
+func (c *Controller) Wait(ctx context.Context) error {
+	if c == nil {
+		return ctx.Err()
+	}
+	c.mu.Lock()
+	resumed := c.resumed
+	paused := c.progress.State == StatePaused
+	c.mu.Unlock()
+	if !paused || resumed == nil {
+		return ctx.Err()
+	}
+	// the progress is persisted, since a paused job might never be resumed in this process
+	if err := c.save(ctx); err != nil {
+		return err
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-resumed:
+		return nil
+	}
+}
+
+// Update is called by the operation to report its progress, e.g. a handled item and the new cursor.
+// The progress is persisted every n updates (see [WithSaveEvery]).
+func (c *Controller) Update(ctx context.Context, update func(progress *Progress)) error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	update(&c.progress)
+	c.progress.UpdatedAt = c.now()
+	c.unsaved++
+	save := c.unsaved >= c.saveEvery
+	c.mu.Unlock()
+	if !save {
+		return nil
+	}
+	return c.save(ctx)
+}
+
+// Finish is called by the operation when it ends. The state is set by the error:
+// [StateCompleted] for nil, [StateCanceled] for a canceled context and [StateFailed] otherwise.
+func (c *Controller) Finish(ctx context.Context, err error) error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	switch {
+	case err == nil:
+		c.progress.State = StateCompleted
+	case errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded):
+		c.progress.State = StateCanceled
+	default:
+		c.progress.State = StateFailed
+		c.progress.Err = err.Error()
+	}
+	c.progress.UpdatedAt = c.now()
+	c.finishedAt = c.progress.UpdatedAt
+	c.mu.Unlock()
+	// the context of a canceled job is done, but its progress must still be persisted
+	return c.save(context.WithoutCancel(ctx))
+}
+
+func (c *Controller) save(ctx context.Context) error {
+	c.mu.Lock()
+	progress := c.progress
+	c.unsaved = 0
+	c.mu.Unlock()
+	if c.store == nil {
+		return nil
+	}
+	return c.store.Save(ctx, c.id, &progress)
+}
@@ -0,0 +1,83 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestController(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	ctrl := New("job", WithStore(store), WithSaveEvery(2))
+
+	progress, err := ctrl.Start(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, StateRunning, progress.State)
+	require.NoError(t, ctrl.Update(ctx, func(p *Progress) { p.Done++; p.Cursor = "1" }))
+	_, err = store.Load(ctx, "job")
+	assert.ErrorIs(t, err, ErrNotFound, "saved every 2 updates")
+
+	ctrl.Pause()
+	assert.Equal(t, StatePaused, ctrl.Progress().State)
+	waited := make(chan error)
+	go func() { waited <- ctrl.Wait(ctx) }()
+	select {
+	case <-waited:
+		t.Fatal("wait returned while paused")
+	case <-time.After(50 * time.Millisecond):
+	}
+	saved, err := store.Load(ctx, "job")
+	require.NoError(t, err)
+	assert.Equal(t, "1", saved.Cursor, "saved when paused")
+	ctrl.Resume()
+	require.NoError(t, <-waited)
+	assert.Equal(t, StateRunning, ctrl.Progress().State)
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	require.NoError(t, ctrl.Finish(canceled, canceled.Err()))
+	saved, err = store.Load(ctx, "job")
+	require.NoError(t, err)
+	assert.Equal(t, StateCanceled, saved.State)
+
+	restarted := New("job", WithStore(store))
+	progress, err = restarted.Start(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, StateRunning, progress.State)
+	assert.Equal(t, 1, progress.Done)
+	assert.Equal(t, "1", progress.Cursor)
+
+	require.NoError(t, restarted.Finish(ctx, errors.New("failed")))
+	assert.Equal(t, StateFailed, restarted.Progress().State)
+	assert.Equal(t, "failed", restarted.Progress().Err)
+}
+
+func TestController_nil(t *testing.T) {
+	var ctrl *Controller
+	ctrl.Pause()
+	ctrl.Resume()
+	_, err := ctrl.Start(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, ctrl.Wait(context.Background()))
+	require.NoError(t, ctrl.Update(context.Background(), func(p *Progress) { p.Done++ }))
+	assert.Equal(t, Progress{}, ctrl.Progress())
+}
+
+func TestFileStore(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewFileStore(t.TempDir())
+	require.NoError(t, err)
+	_, err = store.Load(ctx, "job")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	progress := &Progress{State: StatePaused, Done: 3, Cursor: "c", UpdatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	require.NoError(t, store.Save(ctx, "job", progress))
+	loaded, err := store.Load(ctx, "job")
+	require.NoError(t, err)
+	assert.Equal(t, progress, loaded)
+}
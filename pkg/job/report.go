@@ -0,0 +1,121 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Report is a snapshot of the progress of a job, including its rate and estimated remaining time.
+type Report struct {
+	State State
+	// Done, Failed and Total are the numbers of [Progress].
+	Done, Failed, Total int
+	// Rate is the number of handled items per second of the current run, excluding the paused time.
+	Rate float64
+	// ETA is the estimated remaining time, negative if unknown (e.g. without total).
+	ETA time.Duration
+	// Elapsed is the duration of the current run, excluding the paused time.
+	Elapsed time.Duration
+	// LastError is the error of the last failed item.
+	LastError string
+}
+
+// ProgressReporter is implemented by the long-running operations (e.g. the [Controller] of the bulk operations)
+// to report their progress, see [Watch] and [Bar].
+type ProgressReporter interface {
+	Report() Report
+}
+
+var _ ProgressReporter = (*Controller)(nil)
+
+// Report implements [ProgressReporter].
+func (c *Controller) Report() Report {
+	if c == nil {
+		return Report{ETA: -1}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	r := Report{
+		State:     c.progress.State,
+		Done:      c.progress.Done,
+		Failed:    c.progress.Failed,
+		Total:     c.progress.Total,
+		LastError: c.progress.LastError,
+		ETA:       -1,
+	}
+	if c.startedAt.IsZero() {
+		return r
+	}
+	end := c.now()
+	switch {
+	case !c.finishedAt.IsZero():
+		end = c.finishedAt
+	case !c.pausedAt.IsZero():
+		end = c.pausedAt
+	}
+	r.Elapsed = end.Sub(c.startedAt) - c.pausedFor
+	handled := r.Done + r.Failed - c.handledBefore
+	if r.Elapsed > 0 {
+		r.Rate = float64(handled) / r.Elapsed.Seconds()
+	}
+	if remaining := r.Total - r.Done - r.Failed; r.Total > 0 && remaining <= 0 {
+		r.ETA = 0
+	} else if r.Total > 0 && r.Rate > 0 {
+		r.ETA = time.Duration(float64(remaining) / r.Rate * float64(time.Second))
+	}
+	return r
+}
+
+// Watch calls the function with the [Report] of the reporter in the interval until the context is done,
+// e.g. to push the progress to a UI. It's called a last time when the context is done.
+func Watch(ctx context.Context, reporter ProgressReporter, interval time.Duration, report func(Report)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			report(reporter.Report())
+			return
+		case <-ticker.C:
+			report(reporter.Report())
+		}
+	}
+}
+
+// Bar returns a function rendering the [Report] as progress bar of the width (in characters) to the writer,
+// e.g. os.Stderr, to be used with [Watch]. The line is overwritten on every call.
+func Bar(w io.Writer, width int) func(Report) {
+	return func(r Report) {
+		fmt.Fprint(w, "\r\033[K"+FormatBar(r, width))
+	}
+}
+
+// FormatBar formats the [Report] as progress bar of the width (in characters) followed by the numbers, e.g.:
+//
+//	[##########----------] 50/100 (2 failed)  12.5/s  ETA 4s
+//
+// Without total, only the numbers are formatted.
+func FormatBar(r Report, width int) string {
+	var b strings.Builder
+	if r.Total > 0 {
+		filled := min(width, width*(r.Done+r.Failed)/r.Total)
+		b.WriteString("[" + strings.Repeat("#", filled) + strings.Repeat("-", width-filled) + "] ")
+		fmt.Fprintf(&b, "%d/%d", r.Done+r.Failed, r.Total)
+	} else {
+		fmt.Fprintf(&b, "%d", r.Done+r.Failed)
+	}
+	if r.Failed > 0 {
+		fmt.Fprintf(&b, " (%d failed)", r.Failed)
+	}
+	fmt.Fprintf(&b, "  %.1f/s", r.Rate)
+	if r.ETA >= 0 {
+		fmt.Fprintf(&b, "  ETA %s", r.ETA.Round(time.Second))
+	}
+	if r.State == StatePaused {
+		b.WriteString("  (paused)")
+	}
+	return b.String()
+}
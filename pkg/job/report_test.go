@@ -0,0 +1,66 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestController_Report(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	ctrl := New("job")
+	ctrl.now = func() time.Time { return now }
+	assert.Equal(t, Report{State: StatePending, ETA: -1}, ctrl.Report())
+
+	_, err := ctrl.Start(ctx)
+	require.NoError(t, err)
+	require.NoError(t, ctrl.Update(ctx, func(p *Progress) { p.Total = 100 }))
+	now = now.Add(10 * time.Second)
+	require.NoError(t, ctrl.Update(ctx, func(p *Progress) { p.Done += 18; p.Failed += 2; p.LastError = "failed" }))
+
+	report := ctrl.Report()
+	assert.Equal(t, 10*time.Second, report.Elapsed)
+	assert.InDelta(t, 2, report.Rate, 0.001)
+	assert.Equal(t, 40*time.Second, report.ETA)
+	assert.Equal(t, "failed", report.LastError)
+
+	ctrl.Pause()
+	now = now.Add(time.Hour)
+	assert.Equal(t, 10*time.Second, ctrl.Report().Elapsed, "paused time is excluded")
+	ctrl.Resume()
+	now = now.Add(10 * time.Second)
+	assert.Equal(t, 20*time.Second, ctrl.Report().Elapsed)
+
+	require.NoError(t, ctrl.Finish(ctx, errors.New("stopped")))
+	now = now.Add(time.Hour)
+	assert.Equal(t, 20*time.Second, ctrl.Report().Elapsed, "finished")
+}
+
+func TestFormatBar(t *testing.T) {
+	tests := []struct {
+		name   string
+		report Report
+		want   string
+	}{
+		{
+			name:   "with total",
+			report: Report{Done: 48, Failed: 2, Total: 100, Rate: 12.5, ETA: 4 * time.Second},
+			want:   "[#####-----] 50/100 (2 failed)  12.5/s  ETA 4s",
+		},
+		{
+			name:   "without total",
+			report: Report{State: StatePaused, Done: 7, Rate: 1, ETA: -1},
+			want:   "7  1.0/s  (paused)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, FormatBar(tt.report, 10))
+		})
+	}
+}
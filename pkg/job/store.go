@@ -0,0 +1,114 @@
+package job
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// ErrNotFound is returned by a [Store] if there is no progress of the job.
+var ErrNotFound = errors.New("no progress of the job")
+
+// Store persists the progress of the jobs, e.g. in a database or on disk ([FileStore]).
+type Store interface {
+	// Load returns the progress of the job or [ErrNotFound].
+	Load(ctx context.Context, id string) (*Progress, error)
+	// Save stores the progress of the job, replacing the previous one.
+	Save(ctx context.Context, id string, progress *Progress) error
+}
+
+// MemoryStore is an in-memory [Store], e.g. to resume a job within the same process.
+type MemoryStore struct {
+	mu       sync.Mutex
+	progress map[string]Progress
+}
+
+// NewMemoryStore creates an empty [MemoryStore].
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{progress: make(map[string]Progress)}
+}
+
+// Load implements [Store].
+func (s *MemoryStore) Load(_ context.Context, id string) (*Progress, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	progress, ok := s.progress[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &progress, nil
+}
+
+// Save implements [Store].
+func (s *MemoryStore) Save(_ context.Context, id string, progress *Progress) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.progress[id] = *progress
+	return nil
+}
+
+// FileStore implements [Store] by storing the progress of every job as JSON file in the directory.
+type FileStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewFileStore creates a [FileStore] and ensures the directory exists.
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+// Load implements [Store].
+func (f *FileStore) Load(_ context.Context, id string) (*Progress, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	data, err := os.ReadFile(f.path(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	progress := new(Progress)
+	if err = json.Unmarshal(data, progress); err != nil {
+		return nil, err
+	}
+	return progress, nil
+}
+
+// Save implements [Store].
+// The file is replaced atomically, so a crash does not leave a partially written progress.
+func (f *FileStore) Save(_ context.Context, id string, progress *Progress) error {
+	data, err := json.Marshal(progress)
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	tmp, err := os.CreateTemp(f.dir, ".job-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), f.path(id))
+}
+
+func (f *FileStore) path(id string) string {
+	sum := sha256.Sum256([]byte(id))
+	return filepath.Join(f.dir, hex.EncodeToString(sum[:])+".json")
+}
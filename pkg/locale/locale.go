@@ -0,0 +1,149 @@
+// Package locale validates and normalizes language tags against the languages allowed by the instance,
+// so unsupported languages of users and message texts are detected instead of being silently rejected
+// or ignored by ZITADEL:
+//
+//	languages, err := locale.Load(ctx, client)
+//	lang, err := languages.Normalize("de-CH")                  // "de"
+//	lang = languages.Fallback(r.Header.Get("Accept-Language")) // best allowed match or the default
+package locale
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"golang.org/x/text/language"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client"
+	settingsV2 "github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/settings/v2"
+)
+
+var (
+	ErrInvalidTag = errors.New("invalid language tag")
+	// ErrUnsupportedLanguage is returned if a language is not allowed by the instance.
+	ErrUnsupportedLanguage = errors.New("language is not allowed by the instance")
+	ErrNoLanguages         = errors.New("at least one language is required")
+)
+
+// Languages are the languages allowed by an instance.
+type Languages struct {
+	defaultTag language.Tag
+	allowed    []language.Tag
+	matcher    language.Matcher
+}
+
+// Load returns the [Languages] allowed by the instance of the client and its default language.
+func Load(ctx context.Context, client *client.Client) (*Languages, error) {
+	return load(ctx, client.SettingsServiceV2())
+}
+
+func load(ctx context.Context, service settingsV2.SettingsServiceClient) (*Languages, error) {
+	resp, err := service.GetGeneralSettings(ctx, &settingsV2.GetGeneralSettingsRequest{})
+	if err != nil {
+		return nil, err
+	}
+	return New(resp.GetDefaultLanguage(), resp.GetSupportedLanguages()...)
+}
+
+// New creates [Languages] from the default and allowed languages, e.g. for tests or configured languages.
+// The default language is allowed in any case.
+func New(defaultLanguage string, allowed ...string) (*Languages, error) {
+	if defaultLanguage == "" && len(allowed) == 0 {
+		return nil, ErrNoLanguages
+	}
+	l := &Languages{}
+	for _, lang := range allowed {
+		tag, err := Parse(lang)
+		if err != nil {
+			return nil, err
+		}
+		if !slices.Contains(l.allowed, tag) {
+			l.allowed = append(l.allowed, tag)
+		}
+	}
+	if defaultLanguage == "" {
+		l.defaultTag = l.allowed[0]
+	} else {
+		tag, err := Parse(defaultLanguage)
+		if err != nil {
+			return nil, err
+		}
+		l.defaultTag = tag
+	}
+	// the first tag of the matcher is used if nothing matches, so the default is moved to the front
+	l.allowed = slices.DeleteFunc(l.allowed, func(tag language.Tag) bool { return tag == l.defaultTag })
+	l.allowed = slices.Insert(l.allowed, 0, l.defaultTag)
+	l.matcher = language.NewMatcher(l.allowed)
+	return l, nil
+}
+
+// Parse parses the BCP 47 tag and returns it in its canonical form, e.g. `en-us` as `en-US`.
+func Parse(lang string) (language.Tag, error) {
+	tag, err := language.Parse(lang)
+	if err != nil {
+		return language.Und, fmt.Errorf("%w: %q", ErrInvalidTag, lang)
+	}
+	return tag, nil
+}
+
+// Default returns the default language of the instance.
+func (l *Languages) Default() string {
+	return l.defaultTag.String()
+}
+
+// Allowed returns the allowed languages, starting with the default.
+func (l *Languages) Allowed() []string {
+	langs := make([]string, len(l.allowed))
+	for i, tag := range l.allowed {
+		langs[i] = tag.String()
+	}
+	return langs
+}
+
+// IsAllowed returns if the language is allowed as it is.
+func (l *Languages) IsAllowed(lang string) bool {
+	tag, err := Parse(lang)
+	return err == nil && slices.Contains(l.allowed, tag)
+}
+
+// Normalize returns the allowed language matching the tag, e.g. `de` for `de-CH` if only `de` is allowed,
+// as it should be stored as preferred language of a user or used for message texts.
+// [ErrUnsupportedLanguage] is returned if no allowed language matches with high confidence.
+func (l *Languages) Normalize(lang string) (string, error) {
+	tag, err := Parse(lang)
+	if err != nil {
+		return "", err
+	}
+	match, ok := l.match(tag)
+	if !ok {
+		return "", fmt.Errorf("%w: %q", ErrUnsupportedLanguage, lang)
+	}
+	return match.String(), nil
+}
+
+// Fallback returns the allowed language best matching the preferences, the default language if none matches.
+// The preferences are language tags or `Accept-Language` headers in order of preference,
+// invalid ones are ignored.
+func (l *Languages) Fallback(preferences ...string) string {
+	for _, preference := range preferences {
+		tags, _, err := language.ParseAcceptLanguage(preference)
+		if err != nil {
+			continue
+		}
+		for _, tag := range tags {
+			if match, ok := l.match(tag); ok {
+				return match.String()
+			}
+		}
+	}
+	return l.Default()
+}
+
+func (l *Languages) match(tag language.Tag) (language.Tag, bool) {
+	_, index, confidence := l.matcher.Match(tag)
+	if confidence < language.High {
+		return language.Und, false
+	}
+	return l.allowed[index], true
+}
@@ -0,0 +1,75 @@
+package locale
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	settingsV2 "github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/settings/v2"
+)
+
+type fakeSettingsService struct {
+	settingsV2.SettingsServiceClient
+}
+
+func (fakeSettingsService) GetGeneralSettings(context.Context, *settingsV2.GetGeneralSettingsRequest, ...grpc.CallOption) (*settingsV2.GetGeneralSettingsResponse, error) {
+	return &settingsV2.GetGeneralSettingsResponse{DefaultLanguage: "en", SupportedLanguages: []string{"de", "en", "fr", "zh"}}, nil
+}
+
+func TestLanguages(t *testing.T) {
+	languages, err := load(context.Background(), fakeSettingsService{})
+	require.NoError(t, err)
+	assert.Equal(t, "en", languages.Default())
+	assert.Equal(t, []string{"en", "de", "fr", "zh"}, languages.Allowed())
+
+	t.Run("is allowed", func(t *testing.T) {
+		assert.True(t, languages.IsAllowed("de"))
+		assert.False(t, languages.IsAllowed("de-CH"))
+		assert.False(t, languages.IsAllowed("it"))
+		assert.False(t, languages.IsAllowed("not a tag"))
+	})
+
+	t.Run("normalize", func(t *testing.T) {
+		tests := []struct {
+			lang    string
+			want    string
+			wantErr error
+		}{
+			{lang: "de", want: "de"},
+			{lang: "de-CH", want: "de"},
+			{lang: "FR-ca", want: "fr"},
+			{lang: "zh-Hans-CN", want: "zh"},
+			{lang: "it", wantErr: ErrUnsupportedLanguage},
+			{lang: "", wantErr: ErrInvalidTag},
+			{lang: "not a tag", wantErr: ErrInvalidTag},
+		}
+		for _, tt := range tests {
+			t.Run(tt.lang, func(t *testing.T) {
+				got, err := languages.Normalize(tt.lang)
+				require.ErrorIs(t, err, tt.wantErr)
+				assert.Equal(t, tt.want, got)
+			})
+		}
+	})
+
+	t.Run("fallback", func(t *testing.T) {
+		assert.Equal(t, "fr", languages.Fallback("it-IT,it;q=0.9,fr;q=0.8,de;q=0.7"))
+		assert.Equal(t, "de", languages.Fallback("invalid;;", "it", "de-AT"))
+		assert.Equal(t, "en", languages.Fallback("it", "es"))
+		assert.Equal(t, "en", languages.Fallback())
+	})
+}
+
+func TestNew(t *testing.T) {
+	languages, err := New("", "de", "en", "de")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"de", "en"}, languages.Allowed())
+
+	_, err = New("")
+	assert.ErrorIs(t, err, ErrNoLanguages)
+	_, err = New("en", "not a tag")
+	assert.ErrorIs(t, err, ErrInvalidTag)
+}
@@ -0,0 +1,209 @@
+package login
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/zitadel/oidc/v3/pkg/crypto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultCookieName = "zitadel.login"
+	maxRequestBody    = 1 << 16
+)
+
+// Handler exposes the [Login] as JSON endpoints for a browser based login page.
+// The [State] is kept in an encrypted cookie. Every endpoint responds with the [Response] containing the next step:
+//
+//	GET  /start?authRequest={id}  starts the login of the auth request
+//	POST /loginname               {"loginName": "..."}
+//	POST /password                {"password": "..."}
+//	POST /passkey/begin           responds with the passkey options
+//	POST /passkey/finish          {"credential": {...}}
+//	POST /otp/begin               {"method": "otp_sms|otp_email"}
+//	POST /otp                     {"method": "totp|otp_sms|otp_email", "code": "..."}
+//	POST /finalize                responds with the callback URL the browser must be redirected to
+type Handler struct {
+	login         *Login
+	encryptionKey string
+	cookieName    string
+	mux           *http.ServeMux
+}
+
+// HandlerOption allows customization of the [Handler].
+type HandlerOption func(*Handler)
+
+// WithCookieName sets the name of the cookie storing the [State] (default `zitadel.login`).
+func WithCookieName(name string) HandlerOption {
+	return func(h *Handler) {
+		h.cookieName = name
+	}
+}
+
+// NewHandler creates the [Handler] for the [Login]. The encryptionKey (32 bytes) is used to encrypt the state cookie.
+func NewHandler(login *Login, encryptionKey string, opts ...HandlerOption) *Handler {
+	h := &Handler{
+		login:         login,
+		encryptionKey: encryptionKey,
+		cookieName:    defaultCookieName,
+		mux:           http.NewServeMux(),
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	h.mux.HandleFunc("GET /start", h.start)
+	h.mux.HandleFunc("POST /loginname", h.step(func(r *http.Request, state *State, req *request, resp *Response) error {
+		return h.login.SubmitLoginName(r.Context(), state, req.LoginName)
+	}))
+	h.mux.HandleFunc("POST /password", h.step(func(r *http.Request, state *State, req *request, resp *Response) error {
+		return h.login.SubmitPassword(r.Context(), state, req.Password)
+	}))
+	h.mux.HandleFunc("POST /passkey/begin", h.step(func(r *http.Request, state *State, req *request, resp *Response) (err error) {
+		resp.PasskeyOptions, err = h.login.BeginPasskey(r.Context(), state)
+		return err
+	}))
+	h.mux.HandleFunc("POST /passkey/finish", h.step(func(r *http.Request, state *State, req *request, resp *Response) error {
+		return h.login.FinishPasskey(r.Context(), state, req.Credential)
+	}))
+	h.mux.HandleFunc("POST /otp/begin", h.step(func(r *http.Request, state *State, req *request, resp *Response) error {
+		return h.login.BeginOTP(r.Context(), state, req.Method)
+	}))
+	h.mux.HandleFunc("POST /otp", h.step(func(r *http.Request, state *State, req *request, resp *Response) error {
+		return h.login.SubmitOTP(r.Context(), state, req.Method, req.Code)
+	}))
+	h.mux.HandleFunc("POST /finalize", h.step(func(r *http.Request, state *State, req *request, resp *Response) (err error) {
+		resp.CallbackURL, err = h.login.Finalize(r.Context(), state)
+		return err
+	}))
+	return h
+}
+
+// ServeHTTP implements [http.Handler].
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+// Response is the JSON response of the [Handler].
+type Response struct {
+	Step      Step     `json:"step"`
+	LoginName string   `json:"loginName,omitempty"`
+	Methods   []Method `json:"methods,omitempty"`
+	// PasskeyOptions are the options for `navigator.credentials.get()`.
+	PasskeyOptions json.RawMessage `json:"passkeyOptions,omitempty"`
+	// CallbackURL is the URL the browser must be redirected to after the finalization.
+	CallbackURL string `json:"callbackUrl,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+type request struct {
+	LoginName  string          `json:"loginName"`
+	Password   string          `json:"password"`
+	Credential json.RawMessage `json:"credential"`
+	Method     Method          `json:"method"`
+	Code       string          `json:"code"`
+}
+
+func (h *Handler) start(w http.ResponseWriter, r *http.Request) {
+	state, err := h.login.Start(r.Context(), r.URL.Query().Get("authRequest"))
+	if err != nil {
+		writeError(w, &Response{}, err)
+		return
+	}
+	resp := new(Response)
+	if err = h.setState(w, state); err != nil {
+		writeError(w, resp, err)
+		return
+	}
+	writeResponse(w, state, resp)
+}
+
+func (h *Handler) step(f func(r *http.Request, state *State, req *request, resp *Response) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		resp := new(Response)
+		state, err := h.state(r)
+		if err != nil {
+			writeError(w, resp, ErrMissingAuthRequest)
+			return
+		}
+		req := new(request)
+		if r.ContentLength != 0 {
+			if err = json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBody)).Decode(req); err != nil {
+				writeError(w, resp, err)
+				return
+			}
+		}
+		if err = f(r, state, req, resp); err != nil {
+			resp.Step = state.Step
+			writeError(w, resp, err)
+			return
+		}
+		if err = h.setState(w, state); err != nil {
+			writeError(w, resp, err)
+			return
+		}
+		writeResponse(w, state, resp)
+	}
+}
+
+func (h *Handler) state(r *http.Request) (*State, error) {
+	cookie, err := r.Cookie(h.cookieName)
+	if err != nil {
+		return nil, err
+	}
+	data, err := crypto.DecryptAES(cookie.Value, h.encryptionKey)
+	if err != nil {
+		return nil, err
+	}
+	state := new(State)
+	return state, json.Unmarshal([]byte(data), state)
+}
+
+func (h *Handler) setState(w http.ResponseWriter, state *State) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	value, err := crypto.EncryptAES(string(data), h.encryptionKey)
+	if err != nil {
+		return err
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     h.cookieName,
+		Value:    value,
+		Path:     "/",
+		Secure:   true,
+		HttpOnly: true,
+		SameSite: http.SameSiteStrictMode,
+	})
+	return nil
+}
+
+func writeResponse(w http.ResponseWriter, state *State, resp *Response) {
+	resp.Step = state.Step
+	resp.LoginName = state.LoginName
+	resp.Methods = state.Methods
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func writeError(w http.ResponseWriter, resp *Response, err error) {
+	resp.Error = err.Error()
+	code := http.StatusBadRequest
+	switch {
+	case errors.Is(err, ErrInvalidStep):
+		code = http.StatusConflict
+	case status.Code(err) == codes.InvalidArgument, status.Code(err) == codes.PermissionDenied, status.Code(err) == codes.NotFound:
+		// failed checks (e.g. a wrong password) are not distinguished to prevent user enumeration
+		code = http.StatusUnauthorized
+		resp.Error = "verification failed"
+	case status.Code(err) != codes.Unknown:
+		code = http.StatusBadGateway
+		resp.Error = status.Convert(err).Message()
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,396 @@
+// Package login is a toolkit for building a custom login UI on top of the session and OIDC (v2) services.
+//
+// ZITADEL redirects the user to the login UI with the ID of the auth request (if configured for the application).
+// The [Login] resolves the login hints of the auth request and guides the user through the required steps
+// (login name, password or passkey and an optional second factor) using a serializable [State],
+// and finally creates the callback to the application.
+// The [Handler] exposes the flow as JSON endpoints for a browser based login page.
+package login
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client"
+	oidcV2 "github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/oidc/v2"
+	userV2 "github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/user/v2"
+	"github.com/zitadel/zitadel-go/v3/pkg/session"
+)
+
+var (
+	ErrInvalidStep        = errors.New("action not allowed in the current step")
+	ErrUnsupportedMethod  = errors.New("authentication method not available for the user")
+	ErrMissingAuthRequest = errors.New("auth request ID is required")
+)
+
+// Step is the step of the login the user has to complete next.
+type Step string
+
+const (
+	// StepLoginName requires the user to enter the login name.
+	StepLoginName Step = "loginname"
+	// StepPassword requires the user to enter the password.
+	StepPassword Step = "password"
+	// StepPasskey requires the user to authenticate with a passkey.
+	StepPasskey Step = "passkey"
+	// StepSecondFactor requires the user to verify one of the second factors in [State.Methods].
+	StepSecondFactor Step = "mfa"
+	// StepDone indicates the user is authenticated and the auth request can be finalized.
+	StepDone Step = "done"
+)
+
+// Method is an authentication method of a user.
+type Method string
+
+const (
+	MethodPassword Method = "password"
+	MethodPasskey  Method = "passkey"
+	MethodIDP      Method = "idp"
+	MethodTOTP     Method = "totp"
+	MethodU2F      Method = "u2f"
+	MethodOTPSMS   Method = "otp_sms"
+	MethodOTPEmail Method = "otp_email"
+)
+
+var secondFactors = []Method{MethodTOTP, MethodOTPSMS, MethodOTPEmail}
+
+// State is the progress of a login. It's serializable, so it can be kept between the requests,
+// e.g. in an encrypted cookie as done by the [Handler].
+type State struct {
+	AuthRequestID string `json:"authRequestId"`
+	SessionID     string `json:"sessionId,omitempty"`
+	SessionToken  string `json:"sessionToken,omitempty"`
+	UserID        string `json:"userId,omitempty"`
+	LoginName     string `json:"loginName,omitempty"`
+	// Methods are the authentication methods of the user.
+	Methods []Method `json:"methods,omitempty"`
+	// Verified are the methods verified in the session.
+	Verified []Method `json:"verified,omitempty"`
+	Step     Step     `json:"step"`
+}
+
+// SecondFactors returns the second factors of the user, which can be used in [StepSecondFactor].
+func (s *State) SecondFactors() []Method {
+	var methods []Method
+	for _, method := range s.Methods {
+		if slices.Contains(secondFactors, method) {
+			methods = append(methods, method)
+		}
+	}
+	return methods
+}
+
+func (s *State) session() *session.Session {
+	return &session.Session{ID: s.SessionID, Token: s.SessionToken}
+}
+
+// next determines the next step depending on the methods of the user and the verified factors.
+func (s *State) next(preferPasskey, requireMFA bool) {
+	switch {
+	case s.SessionID == "":
+		s.Step = StepLoginName
+	case slices.Contains(s.Verified, MethodPasskey):
+		// passkeys are multi-factor by themselves
+		s.Step = StepDone
+	case !slices.Contains(s.Verified, MethodPassword):
+		hasPasskey := slices.Contains(s.Methods, MethodPasskey)
+		if hasPasskey && (preferPasskey || !slices.Contains(s.Methods, MethodPassword)) {
+			s.Step = StepPasskey
+			return
+		}
+		s.Step = StepPassword
+	case slices.ContainsFunc(s.Verified, func(m Method) bool { return slices.Contains(secondFactors, m) }):
+		s.Step = StepDone
+	case len(s.SecondFactors()) > 0 || requireMFA:
+		s.Step = StepSecondFactor
+	default:
+		s.Step = StepDone
+	}
+}
+
+// Login runs the steps of the login using the session and OIDC services.
+type Login struct {
+	client        *client.Client
+	sessions      *session.Sessions
+	passkeyDomain string
+	lifetime      time.Duration
+	preferPasskey bool
+	requireMFA    bool
+}
+
+// Option allows customization of the [Login].
+type Option func(*Login)
+
+// WithPasskeyDomain sets the relying party ID of the passkeys, usually the domain of the login UI.
+// It's required for [Login.BeginPasskey].
+func WithPasskeyDomain(domain string) Option {
+	return func(l *Login) {
+		l.passkeyDomain = domain
+	}
+}
+
+// WithSessionLifetime sets the lifetime of the created sessions, they don't expire if not set.
+func WithSessionLifetime(lifetime time.Duration) Option {
+	return func(l *Login) {
+		l.lifetime = lifetime
+	}
+}
+
+// WithPreferPasskey asks users having a passkey and a password for the passkey first.
+func WithPreferPasskey() Option {
+	return func(l *Login) {
+		l.preferPasskey = true
+	}
+}
+
+// WithRequireMFA requires a second factor after the password, even if the user has none
+// (the login UI then needs to guide the user to set one up).
+func WithRequireMFA() Option {
+	return func(l *Login) {
+		l.requireMFA = true
+	}
+}
+
+// New creates a [Login] using the client, which needs the permissions of a login client (IAM_LOGIN_CLIENT).
+func New(client *client.Client, opts ...Option) *Login {
+	l := &Login{
+		client:   client,
+		sessions: session.New(client),
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// AuthRequest returns the auth request, e.g. to show the application or requested scopes.
+func (l *Login) AuthRequest(ctx context.Context, authRequestID string) (*oidcV2.AuthRequest, error) {
+	resp, err := l.client.OIDCServiceV2().GetAuthRequest(ctx, &oidcV2.GetAuthRequestRequest{AuthRequestId: authRequestID})
+	if err != nil {
+		return nil, err
+	}
+	return resp.GetAuthRequest(), nil
+}
+
+// Start starts the login for the auth request. If the auth request contains a login hint (`login_hint` or `id_token_hint`),
+// the user is identified directly and the login name step is skipped.
+func (l *Login) Start(ctx context.Context, authRequestID string) (*State, error) {
+	if authRequestID == "" {
+		return nil, ErrMissingAuthRequest
+	}
+	authRequest, err := l.AuthRequest(ctx, authRequestID)
+	if err != nil {
+		return nil, err
+	}
+	state := &State{AuthRequestID: authRequestID}
+	var check session.Check
+	switch {
+	case authRequest.GetHintUserId() != "":
+		check = session.User(authRequest.GetHintUserId())
+	case authRequest.GetLoginHint() != "":
+		check = session.LoginName(authRequest.GetLoginHint())
+	default:
+		state.next(l.preferPasskey, l.requireMFA)
+		return state, nil
+	}
+	err = l.identify(ctx, state, check)
+	if code := status.Code(err); code == codes.NotFound || code == codes.InvalidArgument {
+		// an unknown login hint must not fail the login, the user is asked for the login name instead
+		*state = State{AuthRequestID: authRequestID}
+		state.next(l.preferPasskey, l.requireMFA)
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// SubmitLoginName identifies the user by the login name and creates the session.
+func (l *Login) SubmitLoginName(ctx context.Context, state *State, loginName string) error {
+	if state.Step != StepLoginName {
+		return ErrInvalidStep
+	}
+	return l.identify(ctx, state, session.LoginName(loginName))
+}
+
+// SubmitPassword verifies the password of the user.
+func (l *Login) SubmitPassword(ctx context.Context, state *State, password string) error {
+	if state.Step != StepPassword {
+		return ErrInvalidStep
+	}
+	return l.verify(ctx, state, MethodPassword, session.Step{Checks: []session.Check{session.Password(password)}})
+}
+
+// BeginPasskey requests the passkey challenge and returns the options for `navigator.credentials.get()`.
+func (l *Login) BeginPasskey(ctx context.Context, state *State) (json.RawMessage, error) {
+	if state.Step != StepPasskey {
+		return nil, ErrInvalidStep
+	}
+	challenge, err := l.challenge(ctx, state, session.WebAuthNChallenge(l.passkeyDomain, session.UserVerificationRequired))
+	if err != nil {
+		return nil, err
+	}
+	return challenge.WebAuthN, nil
+}
+
+// FinishPasskey verifies the credential returned by `navigator.credentials.get()`.
+func (l *Login) FinishPasskey(ctx context.Context, state *State, credential json.RawMessage) error {
+	if state.Step != StepPasskey {
+		return ErrInvalidStep
+	}
+	return l.verify(ctx, state, MethodPasskey, session.Step{Checks: []session.Check{session.WebAuthN(credential)}})
+}
+
+// BeginOTP sends the code of the second factor (SMS or email), it's not required for TOTP.
+func (l *Login) BeginOTP(ctx context.Context, state *State, method Method) error {
+	if state.Step != StepSecondFactor {
+		return ErrInvalidStep
+	}
+	if !slices.Contains(state.SecondFactors(), method) {
+		return ErrUnsupportedMethod
+	}
+	var challenge session.Challenge
+	switch method {
+	case MethodOTPSMS:
+		challenge = session.OTPSMSChallenge(false)
+	case MethodOTPEmail:
+		challenge = session.OTPEmailChallenge("")
+	default:
+		return nil
+	}
+	_, err := l.challenge(ctx, state, challenge)
+	return err
+}
+
+// SubmitOTP verifies the code of the second factor.
+func (l *Login) SubmitOTP(ctx context.Context, state *State, method Method, code string) error {
+	if state.Step != StepSecondFactor {
+		return ErrInvalidStep
+	}
+	if !slices.Contains(state.SecondFactors(), method) {
+		return ErrUnsupportedMethod
+	}
+	var check session.Check
+	switch method {
+	case MethodTOTP:
+		check = session.TOTP(code)
+	case MethodOTPSMS:
+		check = session.OTPSMS(code)
+	case MethodOTPEmail:
+		check = session.OTPEmail(code)
+	}
+	return l.verify(ctx, state, method, session.Step{Checks: []session.Check{check}})
+}
+
+// Finalize links the session to the auth request and returns the callback URL of the application,
+// the user must be redirected to.
+func (l *Login) Finalize(ctx context.Context, state *State) (string, error) {
+	if state.Step != StepDone {
+		return "", ErrInvalidStep
+	}
+	resp, err := l.client.OIDCServiceV2().CreateCallback(ctx, &oidcV2.CreateCallbackRequest{
+		AuthRequestId: state.AuthRequestID,
+		CallbackKind: &oidcV2.CreateCallbackRequest_Session{Session: &oidcV2.Session{
+			SessionId:    state.SessionID,
+			SessionToken: state.SessionToken,
+		}},
+	})
+	if err != nil {
+		return "", err
+	}
+	return resp.GetCallbackUrl(), nil
+}
+
+// Deny fails the auth request (e.g. if the user cancels the login) and returns the callback URL of the application,
+// which receives the error.
+func (l *Login) Deny(ctx context.Context, authRequestID string, reason oidcV2.ErrorReason, description string) (string, error) {
+	authErr := &oidcV2.AuthorizationError{Error: reason}
+	if description != "" {
+		authErr.ErrorDescription = &description
+	}
+	resp, err := l.client.OIDCServiceV2().CreateCallback(ctx, &oidcV2.CreateCallbackRequest{
+		AuthRequestId: authRequestID,
+		CallbackKind:  &oidcV2.CreateCallbackRequest_Error{Error: authErr},
+	})
+	if err != nil {
+		return "", err
+	}
+	return resp.GetCallbackUrl(), nil
+}
+
+func (l *Login) identify(ctx context.Context, state *State, check session.Check) error {
+	created, err := l.sessions.Create(ctx, session.Step{Checks: []session.Check{check}, Lifetime: l.lifetime})
+	if err != nil {
+		return err
+	}
+	current, err := l.sessions.Get(ctx, created)
+	if err != nil {
+		return err
+	}
+	user := current.GetFactors().GetUser()
+	methods, err := l.client.UserServiceV2().ListAuthenticationMethodTypes(ctx, &userV2.ListAuthenticationMethodTypesRequest{
+		UserId: user.GetId(),
+	})
+	if err != nil {
+		return err
+	}
+	state.SessionID, state.SessionToken = created.ID, created.Token
+	state.UserID, state.LoginName = user.GetId(), user.GetLoginName()
+	state.Methods = authMethods(methods.GetAuthMethodTypes())
+	state.Verified = nil
+	state.next(l.preferPasskey, l.requireMFA)
+	return nil
+}
+
+func (l *Login) verify(ctx context.Context, state *State, method Method, step session.Step) error {
+	s := state.session()
+	if err := l.sessions.Set(ctx, s, step); err != nil {
+		return err
+	}
+	state.SessionToken = s.Token
+	state.Verified = append(state.Verified, method)
+	state.next(l.preferPasskey, l.requireMFA)
+	return nil
+}
+
+func (l *Login) challenge(ctx context.Context, state *State, challenge session.Challenge) (*session.Challenges, error) {
+	s := state.session()
+	if err := l.sessions.Set(ctx, s, session.Step{Challenges: []session.Challenge{challenge}}); err != nil {
+		return nil, err
+	}
+	state.SessionToken = s.Token
+	if s.Challenges == nil {
+		return new(session.Challenges), nil
+	}
+	return s.Challenges, nil
+}
+
+func authMethods(types []userV2.AuthenticationMethodType) []Method {
+	methods := make([]Method, 0, len(types))
+	for _, t := range types {
+		switch t {
+		case userV2.AuthenticationMethodType_AUTHENTICATION_METHOD_TYPE_PASSWORD:
+			methods = append(methods, MethodPassword)
+		case userV2.AuthenticationMethodType_AUTHENTICATION_METHOD_TYPE_PASSKEY:
+			methods = append(methods, MethodPasskey)
+		case userV2.AuthenticationMethodType_AUTHENTICATION_METHOD_TYPE_IDP:
+			methods = append(methods, MethodIDP)
+		case userV2.AuthenticationMethodType_AUTHENTICATION_METHOD_TYPE_TOTP:
+			methods = append(methods, MethodTOTP)
+		case userV2.AuthenticationMethodType_AUTHENTICATION_METHOD_TYPE_U2F:
+			methods = append(methods, MethodU2F)
+		case userV2.AuthenticationMethodType_AUTHENTICATION_METHOD_TYPE_OTP_SMS:
+			methods = append(methods, MethodOTPSMS)
+		case userV2.AuthenticationMethodType_AUTHENTICATION_METHOD_TYPE_OTP_EMAIL:
+			methods = append(methods, MethodOTPEmail)
+		}
+	}
+	return methods
+}
@@ -0,0 +1,96 @@
+package login
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestState_next(t *testing.T) {
+	tests := []struct {
+		name          string
+		state         State
+		preferPasskey bool
+		requireMFA    bool
+		want          Step
+	}{
+		{
+			name:  "not identified",
+			state: State{},
+			want:  StepLoginName,
+		},
+		{
+			name:  "password",
+			state: State{SessionID: "s", Methods: []Method{MethodPassword, MethodPasskey}},
+			want:  StepPassword,
+		},
+		{
+			name:          "prefer passkey",
+			state:         State{SessionID: "s", Methods: []Method{MethodPassword, MethodPasskey}},
+			preferPasskey: true,
+			want:          StepPasskey,
+		},
+		{
+			name:  "passkey only",
+			state: State{SessionID: "s", Methods: []Method{MethodPasskey}},
+			want:  StepPasskey,
+		},
+		{
+			name:  "passkey verified",
+			state: State{SessionID: "s", Methods: []Method{MethodPasskey, MethodTOTP}, Verified: []Method{MethodPasskey}},
+			want:  StepDone,
+		},
+		{
+			name:  "second factor",
+			state: State{SessionID: "s", Methods: []Method{MethodPassword, MethodTOTP}, Verified: []Method{MethodPassword}},
+			want:  StepSecondFactor,
+		},
+		{
+			name:  "second factor verified",
+			state: State{SessionID: "s", Methods: []Method{MethodPassword, MethodTOTP}, Verified: []Method{MethodPassword, MethodTOTP}},
+			want:  StepDone,
+		},
+		{
+			name:  "password only",
+			state: State{SessionID: "s", Methods: []Method{MethodPassword}, Verified: []Method{MethodPassword}},
+			want:  StepDone,
+		},
+		{
+			name:       "mfa required",
+			state:      State{SessionID: "s", Methods: []Method{MethodPassword}, Verified: []Method{MethodPassword}},
+			requireMFA: true,
+			want:       StepSecondFactor,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.state.next(tt.preferPasskey, tt.requireMFA)
+			assert.Equal(t, tt.want, tt.state.Step)
+		})
+	}
+}
+
+func TestHandler_invalidStep(t *testing.T) {
+	key := "0123456789abcdef0123456789abcdef"
+	h := NewHandler(New(nil), key)
+
+	w := httptest.NewRecorder()
+	require.NoError(t, h.setState(w, &State{AuthRequestID: "id", Step: StepLoginName}))
+
+	r := httptest.NewRequest(http.MethodPost, "/password", strings.NewReader(`{"password":"secret"}`))
+	for _, cookie := range w.Result().Cookies() {
+		r.AddCookie(cookie)
+	}
+	resp := httptest.NewRecorder()
+	h.ServeHTTP(resp, r)
+	assert.Equal(t, http.StatusConflict, resp.Code)
+	assert.JSONEq(t, `{"step":"loginname","error":"action not allowed in the current step"}`, resp.Body.String())
+
+	resp = httptest.NewRecorder()
+	h.ServeHTTP(resp, httptest.NewRequest(http.MethodPost, "/password", nil))
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
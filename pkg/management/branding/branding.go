@@ -0,0 +1,345 @@
+// Package branding manages the branding (label policy) of the instance and its organizations:
+// the colors and settings using the label policy RPCs and the assets (logo, icon and font)
+// using the HTTP asset API of ZITADEL, which isn't part of the gRPC API:
+//
+//	b, err := branding.New(ctx, api, zitadel.New(domain), client.DefaultServiceUserAuthentication(keyPath, scopes...))
+//	err = b.UploadFile(ctx, orgID, branding.AssetLogo, "logo.svg")
+//	err = b.Activate(ctx, orgID)
+//
+// All methods use the branding of the organization with the orgID, the one of the instance for an empty orgID.
+// Changes only affect the preview of the branding until they are activated.
+package branding
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/oauth2"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/admin"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/management"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/policy"
+	"github.com/zitadel/zitadel-go/v3/pkg/resources"
+	"github.com/zitadel/zitadel-go/v3/pkg/zitadel"
+)
+
+const (
+	// AssetsPath is the base path of the asset API.
+	AssetsPath = "/assets/v1"
+	// MaxAssetSize is the maximal size of an uploaded asset.
+	MaxAssetSize = 1 << 20
+
+	previewSuffix = "/_preview"
+	formFile      = "file"
+)
+
+var (
+	ErrUnknownAsset           = errors.New("unknown asset")
+	ErrUnsupportedContentType = errors.New("unsupported content type")
+	ErrAssetTooLarge          = fmt.Errorf("asset is larger than %d bytes", MaxAssetSize)
+)
+
+// Asset is an asset of the branding.
+type Asset string
+
+const (
+	AssetLogo     Asset = "logo"
+	AssetLogoDark Asset = "logo/dark"
+	AssetIcon     Asset = "icon"
+	AssetIconDark Asset = "icon/dark"
+	AssetFont     Asset = "font"
+)
+
+// contentTypePrefix returns the prefix of the content types accepted for the asset.
+func (a Asset) contentTypePrefix() (string, error) {
+	switch a {
+	case AssetLogo, AssetLogoDark, AssetIcon, AssetIconDark:
+		return "image/", nil
+	case AssetFont:
+		return "font/", nil
+	}
+	return "", fmt.Errorf("%w: %q", ErrUnknownAsset, string(a))
+}
+
+// Content is a downloaded asset.
+type Content struct {
+	ContentType string
+	Data        []byte
+}
+
+// Branding manages the branding of the instance and its organizations.
+type Branding struct {
+	client     *client.Client
+	baseURL    string
+	httpClient *http.Client
+}
+
+// Option allows customization of the [Branding].
+type Option func(*options)
+
+type options struct {
+	httpClient *http.Client
+}
+
+// WithHTTPClient sets the [http.Client] used for the token and asset requests,
+// e.g. with a custom transport or timeout.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(o *options) {
+		o.httpClient = httpClient
+	}
+}
+
+// New creates a [Branding] using the client for the label policy RPCs and the auth for the asset API of the instance.
+func New(ctx context.Context, client *client.Client, zitadel *zitadel.Zitadel, auth client.TokenSourceInitializer, opts ...Option) (*Branding, error) {
+	o := &options{httpClient: http.DefaultClient}
+	for _, opt := range opts {
+		opt(o)
+	}
+	authCtx := context.WithValue(ctx, oauth2.HTTPClient, o.httpClient)
+	source, err := auth(authCtx, zitadel.Origin())
+	if err != nil {
+		return nil, err
+	}
+	return &Branding{
+		client:     client,
+		baseURL:    zitadel.Origin() + AssetsPath,
+		httpClient: oauth2.NewClient(authCtx, source),
+	}, nil
+}
+
+// Policy returns the active label policy, the preview (containing the changes not activated yet) if preview is set.
+func (b *Branding) Policy(ctx context.Context, orgID string, preview bool) (*policy.LabelPolicy, error) {
+	if orgID == "" {
+		if preview {
+			resp, err := b.client.AdminService().GetPreviewLabelPolicy(ctx, &admin.GetPreviewLabelPolicyRequest{})
+			return resp.GetPolicy(), err
+		}
+		resp, err := b.client.AdminService().GetLabelPolicy(ctx, &admin.GetLabelPolicyRequest{})
+		return resp.GetPolicy(), err
+	}
+	service := b.client.ForOrganization(orgID).ManagementService()
+	if preview {
+		resp, err := service.GetPreviewLabelPolicy(ctx, &management.GetPreviewLabelPolicyRequest{})
+		return resp.GetPolicy(), err
+	}
+	resp, err := service.GetLabelPolicy(ctx, &management.GetLabelPolicyRequest{})
+	return resp.GetPolicy(), err
+}
+
+// EnsureCustomPolicy adds a custom label policy to the organization copying the one of the instance,
+// if the organization still uses the default. Assets can only be uploaded for organizations with a custom policy.
+func (b *Branding) EnsureCustomPolicy(ctx context.Context, orgID string) error {
+	service := b.client.ForOrganization(orgID).ManagementService()
+	resp, err := service.GetPreviewLabelPolicy(ctx, &management.GetPreviewLabelPolicyRequest{})
+	if err != nil {
+		return err
+	}
+	if !resp.GetIsDefault() {
+		return nil
+	}
+	p := resp.GetPolicy()
+	_, err = service.AddCustomLabelPolicy(ctx, &management.AddCustomLabelPolicyRequest{
+		PrimaryColor:        p.GetPrimaryColor(),
+		HideLoginNameSuffix: p.GetHideLoginNameSuffix(),
+		WarnColor:           p.GetWarnColor(),
+		BackgroundColor:     p.GetBackgroundColor(),
+		FontColor:           p.GetFontColor(),
+		PrimaryColorDark:    p.GetPrimaryColorDark(),
+		BackgroundColorDark: p.GetBackgroundColorDark(),
+		WarnColorDark:       p.GetWarnColorDark(),
+		FontColorDark:       p.GetFontColorDark(),
+		DisableWatermark:    p.GetDisableWatermark(),
+		ThemeMode:           p.GetThemeMode(),
+	})
+	return err
+}
+
+// Activate activates the preview of the branding, so it's used by the login and the emails.
+func (b *Branding) Activate(ctx context.Context, orgID string) error {
+	if orgID == "" {
+		_, err := b.client.AdminService().ActivateLabelPolicy(ctx, &admin.ActivateLabelPolicyRequest{})
+		return err
+	}
+	_, err := b.client.ForOrganization(orgID).ManagementService().ActivateCustomLabelPolicy(ctx, &management.ActivateCustomLabelPolicyRequest{})
+	return err
+}
+
+// Remove removes the asset from the preview of the branding.
+func (b *Branding) Remove(ctx context.Context, orgID string, asset Asset) (err error) {
+	if _, err := asset.contentTypePrefix(); err != nil {
+		return err
+	}
+	if orgID == "" {
+		service := b.client.AdminService()
+		switch asset {
+		case AssetLogo:
+			_, err = service.RemoveLabelPolicyLogo(ctx, &admin.RemoveLabelPolicyLogoRequest{})
+		case AssetLogoDark:
+			_, err = service.RemoveLabelPolicyLogoDark(ctx, &admin.RemoveLabelPolicyLogoDarkRequest{})
+		case AssetIcon:
+			_, err = service.RemoveLabelPolicyIcon(ctx, &admin.RemoveLabelPolicyIconRequest{})
+		case AssetIconDark:
+			_, err = service.RemoveLabelPolicyIconDark(ctx, &admin.RemoveLabelPolicyIconDarkRequest{})
+		case AssetFont:
+			_, err = service.RemoveLabelPolicyFont(ctx, &admin.RemoveLabelPolicyFontRequest{})
+		}
+		return err
+	}
+	service := b.client.ForOrganization(orgID).ManagementService()
+	switch asset {
+	case AssetLogo:
+		_, err = service.RemoveCustomLabelPolicyLogo(ctx, &management.RemoveCustomLabelPolicyLogoRequest{})
+	case AssetLogoDark:
+		_, err = service.RemoveCustomLabelPolicyLogoDark(ctx, &management.RemoveCustomLabelPolicyLogoDarkRequest{})
+	case AssetIcon:
+		_, err = service.RemoveCustomLabelPolicyIcon(ctx, &management.RemoveCustomLabelPolicyIconRequest{})
+	case AssetIconDark:
+		_, err = service.RemoveCustomLabelPolicyIconDark(ctx, &management.RemoveCustomLabelPolicyIconDarkRequest{})
+	case AssetFont:
+		_, err = service.RemoveCustomLabelPolicyFont(ctx, &management.RemoveCustomLabelPolicyFontRequest{})
+	}
+	return err
+}
+
+// UploadFile uploads the file as the asset, see [Branding.Upload].
+func (b *Branding) UploadFile(ctx context.Context, orgID string, asset Asset, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return b.Upload(ctx, orgID, asset, filepath.Base(path), f)
+}
+
+// Upload uploads the data as the asset into the preview of the branding.
+// The content type is detected from the data, resp. the extension of the filename if the data is not conclusive
+// (e.g. for SVGs), and must be an image for logos and icons and a font for the font.
+func (b *Branding) Upload(ctx context.Context, orgID string, asset Asset, filename string, r io.Reader) error {
+	data, err := io.ReadAll(io.LimitReader(r, MaxAssetSize+1))
+	if err != nil {
+		return err
+	}
+	if len(data) > MaxAssetSize {
+		return ErrAssetTooLarge
+	}
+	contentType, err := detectContentType(asset, filename, data)
+	if err != nil {
+		return err
+	}
+	body, formContentType, err := multipartBody(filename, contentType, data)
+	if err != nil {
+		return err
+	}
+	req, err := b.newRequest(ctx, http.MethodPost, orgID, asset, "", body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", formContentType)
+	resp, err := b.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		return resources.ResponseError(resp)
+	}
+	return nil
+}
+
+// Download returns the asset of the active branding, the one of the preview if preview is set.
+// A missing asset is returned as NotFound status error.
+func (b *Branding) Download(ctx context.Context, orgID string, asset Asset, preview bool) (*Content, error) {
+	if _, err := asset.contentTypePrefix(); err != nil {
+		return nil, err
+	}
+	var suffix string
+	if preview {
+		suffix = previewSuffix
+	}
+	req, err := b.newRequest(ctx, http.MethodGet, orgID, asset, suffix, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := b.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, resources.ResponseError(resp)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return &Content{ContentType: resp.Header.Get("Content-Type"), Data: data}, nil
+}
+
+// newRequest creates the request of the asset API, scoped to the organization by the [client.OrgHeader].
+func (b *Branding) newRequest(ctx context.Context, method, orgID string, asset Asset, suffix string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, b.baseURL+assetPath(orgID, asset)+suffix, body)
+	if err != nil {
+		return nil, err
+	}
+	if orgID != "" {
+		req.Header.Set(client.OrgHeader, orgID)
+	}
+	return req, nil
+}
+
+// assetPath returns the path of the asset relative to the [AssetsPath], e.g. `/org/policy/label/logo/dark`.
+func assetPath(orgID string, asset Asset) string {
+	scope := "/instance"
+	if orgID != "" {
+		scope = "/org"
+	}
+	return scope + "/policy/label/" + string(asset)
+}
+
+func detectContentType(asset Asset, filename string, data []byte) (string, error) {
+	prefix, err := asset.contentTypePrefix()
+	if err != nil {
+		return "", err
+	}
+	contentType, _, _ := mime.ParseMediaType(http.DetectContentType(data))
+	if !strings.HasPrefix(contentType, prefix) {
+		if byExtension, _, err := mime.ParseMediaType(mime.TypeByExtension(filepath.Ext(filename))); err == nil {
+			contentType = byExtension
+		}
+	}
+	if !strings.HasPrefix(contentType, prefix) {
+		return "", fmt.Errorf("%w: %s for %s", ErrUnsupportedContentType, contentType, asset)
+	}
+	return contentType, nil
+}
+
+// multipartBody encodes the data as the file of a multipart form.
+func multipartBody(filename, contentType string, data []byte) (io.Reader, string, error) {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", mime.FormatMediaType("form-data", map[string]string{"name": formFile, "filename": filename}))
+	header.Set("Content-Type", contentType)
+	part, err := w.CreatePart(header)
+	if err != nil {
+		return nil, "", err
+	}
+	if _, err := part.Write(data); err != nil {
+		return nil, "", err
+	}
+	if err := w.Close(); err != nil {
+		return nil, "", err
+	}
+	return &body, w.FormDataContentType(), nil
+}
@@ -0,0 +1,103 @@
+package branding
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client"
+	"github.com/zitadel/zitadel-go/v3/pkg/zitadel"
+)
+
+var png = []byte("\x89PNG\x0D\x0A\x1A\x0A\x00\x00\x00\x0DIHDR")
+
+func newTestBranding(t *testing.T, handler http.HandlerFunc) *Branding {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	host, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	require.NoError(t, err)
+	b, err := New(context.Background(), nil, zitadel.New(host, zitadel.WithInsecure(port)), client.PAT("token"))
+	require.NoError(t, err)
+	return b
+}
+
+func TestBranding_Upload(t *testing.T) {
+	tests := []struct {
+		name            string
+		orgID           string
+		asset           Asset
+		filename        string
+		data            []byte
+		wantPath        string
+		wantContentType string
+		wantErr         error
+	}{
+		{"instance logo", "", AssetLogo, "logo.png", png, "/assets/v1/instance/policy/label/logo", "image/png", nil},
+		{"org dark icon", "org1", AssetIconDark, "icon.png", png, "/assets/v1/org/policy/label/icon/dark", "image/png", nil},
+		{"svg by extension", "org1", AssetLogo, "logo.svg", []byte(`<svg xmlns="http://www.w3.org/2000/svg"></svg>`), "/assets/v1/org/policy/label/logo", "image/svg+xml", nil},
+		{"font", "", AssetFont, "font.woff2", []byte("wOF2\x00\x01\x00\x00"), "/assets/v1/instance/policy/label/font", "font/woff2", nil},
+		{"image as font", "", AssetFont, "font.png", png, "", "", ErrUnsupportedContentType},
+		{"unknown asset", "", Asset("banner"), "banner.png", png, "", "", ErrUnknownAsset},
+		{"too large", "", AssetLogo, "logo.png", bytes.Repeat([]byte{0}, MaxAssetSize+1), "", "", ErrAssetTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var called bool
+			b := newTestBranding(t, func(w http.ResponseWriter, r *http.Request) {
+				called = true
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.Equal(t, tt.wantPath, r.URL.Path)
+				assert.Equal(t, tt.orgID, r.Header.Get(client.OrgHeader))
+				assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+				file, header, err := r.FormFile(formFile)
+				require.NoError(t, err)
+				assert.Equal(t, tt.filename, header.Filename)
+				assert.Equal(t, tt.wantContentType, header.Header.Get("Content-Type"))
+				data, err := io.ReadAll(file)
+				require.NoError(t, err)
+				assert.Equal(t, tt.data, data)
+			})
+			err := b.Upload(context.Background(), tt.orgID, tt.asset, tt.filename, bytes.NewReader(tt.data))
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.False(t, called)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, called)
+		})
+	}
+}
+
+func TestBranding_Download(t *testing.T) {
+	b := newTestBranding(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/assets/v1/org/policy/label/logo/_preview":
+			assert.Equal(t, "org1", r.Header.Get(client.OrgHeader))
+			w.Header().Set("Content-Type", "image/png")
+			_, _ = w.Write(png)
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = io.Copy(w, strings.NewReader(`{"code":5,"message":"asset not found"}`))
+		}
+	})
+
+	content, err := b.Download(context.Background(), "org1", AssetLogo, true)
+	require.NoError(t, err)
+	assert.Equal(t, &Content{ContentType: "image/png", Data: png}, content)
+
+	_, err = b.Download(context.Background(), "", AssetIcon, false)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
@@ -0,0 +1,82 @@
+// Package diagnostics evaluates the configuration of ZITADEL to explain its behavior,
+// e.g. why a user is unable to log in to an application ([Diagnostics.Login]) or why an API rejects a token ([Token]).
+package diagnostics
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client"
+)
+
+// Severity of a [Finding].
+type Severity string
+
+const (
+	// SeverityBlocker prevents the action (e.g. the login).
+	SeverityBlocker Severity = "blocker"
+	// SeverityWarning requires an additional step of the user (e.g. change the password) but does not prevent the action.
+	SeverityWarning Severity = "warning"
+	// SeverityInfo provides additional information about the evaluated configuration.
+	SeverityInfo Severity = "info"
+)
+
+// Finding is the result of a single check.
+type Finding struct {
+	Severity Severity `json:"severity"`
+	// Check identifies the check, e.g. `user_state`.
+	Check   string `json:"check"`
+	Message string `json:"message"`
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("[%s] %s: %s", f.Severity, f.Check, f.Message)
+}
+
+// Report contains the findings of a diagnosis.
+type Report struct {
+	Findings []Finding `json:"findings"`
+}
+
+// Blockers returns the findings preventing the action.
+func (r *Report) Blockers() []Finding {
+	var blockers []Finding
+	for _, finding := range r.Findings {
+		if finding.Severity == SeverityBlocker {
+			blockers = append(blockers, finding)
+		}
+	}
+	return blockers
+}
+
+// OK returns true if there are no blockers.
+func (r *Report) OK() bool {
+	return len(r.Blockers()) == 0
+}
+
+// String returns the findings as a human-readable list, blockers first.
+func (r *Report) String() string {
+	var b strings.Builder
+	for _, severity := range []Severity{SeverityBlocker, SeverityWarning, SeverityInfo} {
+		for _, finding := range r.Findings {
+			if finding.Severity == severity {
+				b.WriteString(finding.String())
+				b.WriteByte('\n')
+			}
+		}
+	}
+	return b.String()
+}
+
+func (r *Report) add(severity Severity, check, format string, args ...any) {
+	r.Findings = append(r.Findings, Finding{Severity: severity, Check: check, Message: fmt.Sprintf(format, args...)})
+}
+
+// Diagnostics runs diagnoses using the client, which needs read permissions on the involved organizations.
+type Diagnostics struct {
+	client *client.Client
+}
+
+func New(client *client.Client) *Diagnostics {
+	return &Diagnostics{client: client}
+}
@@ -0,0 +1,256 @@
+package diagnostics
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/app"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/management"
+	objectV2 "github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/object/v2"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/project"
+	settings "github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/settings/v2"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/user"
+	userV2 "github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/user/v2"
+)
+
+// Checks of the login diagnosis.
+const (
+	CheckUserState     = "user_state"
+	CheckUserType      = "user_type"
+	CheckPassword      = "password"
+	CheckEmail         = "email"
+	CheckApplication   = "application"
+	CheckLoginMethods  = "login_methods"
+	CheckMFA           = "mfa"
+	CheckIDPs          = "identity_providers"
+	CheckProjectAccess = "project_access"
+	CheckProjectRoles  = "project_roles"
+)
+
+// LoginRequest identifies the user and the application of a login.
+type LoginRequest struct {
+	UserID    string
+	ProjectID string
+	AppID     string
+	// ProjectOrganizationID is the organization owning the project,
+	// it defaults to the organization of the user.
+	ProjectOrganizationID string
+}
+
+// Login evaluates the state of the user, the login settings of its organization, the available
+// authentication factors and identity providers as well as the project checks of the application
+// and reports the reasons which prevent the user from logging in.
+// An error is only returned if the required information could not be retrieved.
+func (d *Diagnostics) Login(ctx context.Context, req LoginRequest) (*Report, error) {
+	report := new(Report)
+	userResp, err := d.client.UserServiceV2().GetUserByID(ctx, &userV2.GetUserByIDRequest{UserId: req.UserID})
+	if err != nil {
+		return nil, err
+	}
+	u := userResp.GetUser()
+	userOrgID := u.GetDetails().GetResourceOwner()
+	projectOrgID := req.ProjectOrganizationID
+	if projectOrgID == "" {
+		projectOrgID = userOrgID
+	}
+	checkUser(report, u)
+
+	methodsResp, err := d.client.UserServiceV2().ListAuthenticationMethodTypes(ctx, &userV2.ListAuthenticationMethodTypesRequest{UserId: req.UserID})
+	if err != nil {
+		return nil, err
+	}
+	orgCtx := &objectV2.RequestContext{ResourceOwner: &objectV2.RequestContext_OrgId{OrgId: userOrgID}}
+	loginResp, err := d.client.SettingsServiceV2().GetLoginSettings(ctx, &settings.GetLoginSettingsRequest{Ctx: orgCtx})
+	if err != nil {
+		return nil, err
+	}
+	idpResp, err := d.client.SettingsServiceV2().GetActiveIdentityProviders(ctx, &settings.GetActiveIdentityProvidersRequest{Ctx: orgCtx})
+	if err != nil {
+		return nil, err
+	}
+	if u.GetHuman() != nil {
+		checkLoginMethods(report, loginResp.GetSettings(), methodsResp.GetAuthMethodTypes(), idpResp.GetIdentityProviders())
+	}
+
+	projectMgmt := d.client.ForOrganization(projectOrgID).ManagementService()
+	appResp, err := projectMgmt.GetAppByID(ctx, &management.GetAppByIDRequest{ProjectId: req.ProjectID, AppId: req.AppID})
+	if err != nil {
+		return nil, err
+	}
+	if appResp.GetApp().GetState() != app.AppState_APP_STATE_ACTIVE {
+		report.add(SeverityBlocker, CheckApplication, "the application %s is deactivated", appResp.GetApp().GetName())
+	}
+	projectResp, err := projectMgmt.GetProjectByID(ctx, &management.GetProjectByIDRequest{Id: req.ProjectID})
+	if err != nil {
+		return nil, err
+	}
+	if err = d.checkProject(ctx, report, projectResp.GetProject(), req.UserID, userOrgID, projectOrgID); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func checkUser(report *Report, u *userV2.User) {
+	switch u.GetState() {
+	case userV2.UserState_USER_STATE_ACTIVE:
+	case userV2.UserState_USER_STATE_INITIAL:
+		report.add(SeverityWarning, CheckUserState, "the user has not been initialized yet and needs to complete the initialization (set password, verify email) on the first login")
+	case userV2.UserState_USER_STATE_LOCKED:
+		report.add(SeverityBlocker, CheckUserState, "the user is locked, e.g. because of too many failed login attempts (lockout policy), and must be unlocked by an administrator")
+	case userV2.UserState_USER_STATE_INACTIVE:
+		report.add(SeverityBlocker, CheckUserState, "the user is deactivated and must be reactivated by an administrator")
+	case userV2.UserState_USER_STATE_DELETED:
+		report.add(SeverityBlocker, CheckUserState, "the user is deleted")
+	default:
+		report.add(SeverityBlocker, CheckUserState, "the user is in an unknown state (%s)", u.GetState())
+	}
+	if u.GetMachine() != nil {
+		report.add(SeverityBlocker, CheckUserType, "the user is a service user (machine), which can't log in interactively but must use a key, personal access token or client credentials")
+		return
+	}
+	human := u.GetHuman()
+	if human.GetPasswordChangeRequired() {
+		report.add(SeverityWarning, CheckPassword, "the user needs to change the password on the next login")
+	}
+	if !human.GetEmail().GetIsVerified() {
+		report.add(SeverityWarning, CheckEmail, "the email address %s is not verified, the user will be asked to verify it on the next login", human.GetEmail().GetEmail())
+	}
+}
+
+func checkLoginMethods(report *Report, login *settings.LoginSettings, methods []userV2.AuthenticationMethodType, idps []*settings.IdentityProvider) {
+	hasMethod := func(method userV2.AuthenticationMethodType) bool {
+		return slices.Contains(methods, method)
+	}
+	var available []string
+	if login.GetAllowUsernamePassword() {
+		if hasMethod(userV2.AuthenticationMethodType_AUTHENTICATION_METHOD_TYPE_PASSWORD) {
+			available = append(available, "password")
+		}
+	} else if hasMethod(userV2.AuthenticationMethodType_AUTHENTICATION_METHOD_TYPE_PASSWORD) {
+		report.add(SeverityInfo, CheckLoginMethods, "the user has a password, but the login settings don't allow username and password")
+	}
+	if login.GetPasskeysType() == settings.PasskeysType_PASSKEYS_TYPE_ALLOWED {
+		if hasMethod(userV2.AuthenticationMethodType_AUTHENTICATION_METHOD_TYPE_PASSKEY) {
+			available = append(available, "passkey")
+		}
+	} else if hasMethod(userV2.AuthenticationMethodType_AUTHENTICATION_METHOD_TYPE_PASSKEY) {
+		report.add(SeverityInfo, CheckLoginMethods, "the user has a passkey, but the login settings don't allow passkeys")
+	}
+	if hasMethod(userV2.AuthenticationMethodType_AUTHENTICATION_METHOD_TYPE_IDP) {
+		switch {
+		case !login.GetAllowExternalIdp():
+			report.add(SeverityInfo, CheckIDPs, "the user is linked to an identity provider, but the login settings don't allow external identity providers")
+		case len(idps) == 0:
+			report.add(SeverityInfo, CheckIDPs, "the user is linked to an identity provider, but no identity provider is active for the organization")
+		default:
+			available = append(available, "identity provider")
+		}
+	}
+	if len(available) == 0 {
+		report.add(SeverityBlocker, CheckLoginMethods, "the user has no authentication method allowed by the login settings (registered: %s)", methodNames(methods))
+	} else {
+		report.add(SeverityInfo, CheckLoginMethods, "the user can log in with: %s", strings.Join(available, ", "))
+	}
+
+	if !login.GetForceMfa() && !login.GetForceMfaLocalOnly() {
+		return
+	}
+	if len(login.GetSecondFactors()) == 0 && len(login.GetMultiFactors()) == 0 {
+		report.add(SeverityBlocker, CheckMFA, "the login settings enforce MFA, but don't allow any second factor")
+		return
+	}
+	if !hasAllowedSecondFactor(login.GetSecondFactors(), methods) {
+		report.add(SeverityWarning, CheckMFA, "the login settings enforce MFA and the user has no allowed second factor, the user will need to set one up on the next login")
+	}
+	if login.GetForceMfaLocalOnly() {
+		report.add(SeverityInfo, CheckMFA, "MFA is only enforced for local authentication, not when using an identity provider")
+	}
+}
+
+func hasAllowedSecondFactor(allowed []settings.SecondFactorType, methods []userV2.AuthenticationMethodType) bool {
+	factors := map[settings.SecondFactorType]userV2.AuthenticationMethodType{
+		settings.SecondFactorType_SECOND_FACTOR_TYPE_OTP:       userV2.AuthenticationMethodType_AUTHENTICATION_METHOD_TYPE_TOTP,
+		settings.SecondFactorType_SECOND_FACTOR_TYPE_U2F:       userV2.AuthenticationMethodType_AUTHENTICATION_METHOD_TYPE_U2F,
+		settings.SecondFactorType_SECOND_FACTOR_TYPE_OTP_EMAIL: userV2.AuthenticationMethodType_AUTHENTICATION_METHOD_TYPE_OTP_EMAIL,
+		settings.SecondFactorType_SECOND_FACTOR_TYPE_OTP_SMS:   userV2.AuthenticationMethodType_AUTHENTICATION_METHOD_TYPE_OTP_SMS,
+	}
+	for _, factor := range allowed {
+		if method, ok := factors[factor]; ok && slices.Contains(methods, method) {
+			return true
+		}
+	}
+	// passkeys are multi factors by themselves
+	return slices.Contains(methods, userV2.AuthenticationMethodType_AUTHENTICATION_METHOD_TYPE_PASSKEY)
+}
+
+func methodNames(methods []userV2.AuthenticationMethodType) string {
+	if len(methods) == 0 {
+		return "none"
+	}
+	names := make([]string, len(methods))
+	for i, method := range methods {
+		names[i] = strings.ToLower(strings.TrimPrefix(method.String(), "AUTHENTICATION_METHOD_TYPE_"))
+	}
+	return strings.Join(names, ", ")
+}
+
+func (d *Diagnostics) checkProject(ctx context.Context, report *Report, proj *project.Project, userID, userOrgID, projectOrgID string) error {
+	if proj.GetState() != project.ProjectState_PROJECT_STATE_ACTIVE {
+		report.add(SeverityBlocker, CheckApplication, "the project %s is deactivated", proj.GetName())
+	}
+	if proj.GetHasProjectCheck() && userOrgID != projectOrgID {
+		granted, err := d.projectGranted(ctx, proj.GetId(), userOrgID, projectOrgID)
+		if err != nil {
+			return err
+		}
+		if !granted {
+			report.add(SeverityBlocker, CheckProjectAccess, "the project requires the organization of the user to be granted (check for project on authentication), but there's no active project grant")
+		}
+	}
+	if !proj.GetProjectRoleCheck() {
+		return nil
+	}
+	hasGrant, err := d.userGranted(ctx, proj.GetId(), userID, userOrgID, projectOrgID)
+	if err != nil {
+		return err
+	}
+	if !hasGrant {
+		report.add(SeverityBlocker, CheckProjectRoles, "the project requires a role (check authorization on authentication), but the user has no active authorization for the project")
+	}
+	return nil
+}
+
+func (d *Diagnostics) projectGranted(ctx context.Context, projectID, userOrgID, projectOrgID string) (bool, error) {
+	resp, err := d.client.ForOrganization(projectOrgID).ManagementService().ListProjectGrants(ctx, &management.ListProjectGrantsRequest{
+		ProjectId: projectID,
+	})
+	if err != nil {
+		return false, err
+	}
+	return slices.ContainsFunc(resp.GetResult(), func(grant *project.GrantedProject) bool {
+		return grant.GetGrantedOrgId() == userOrgID && grant.GetState() == project.ProjectGrantState_PROJECT_GRANT_STATE_ACTIVE
+	}), nil
+}
+
+// userGranted searches the user grants in the organization of the project and (in case of a project grant)
+// the organization of the user.
+func (d *Diagnostics) userGranted(ctx context.Context, projectID, userID string, orgIDs ...string) (bool, error) {
+	for _, orgID := range slices.Compact(orgIDs) {
+		resp, err := d.client.ForOrganization(orgID).ManagementService().ListUserGrants(ctx, &management.ListUserGrantRequest{
+			Queries: []*user.UserGrantQuery{
+				{Query: &user.UserGrantQuery_UserIdQuery{UserIdQuery: &user.UserGrantUserIDQuery{UserId: userID}}},
+				{Query: &user.UserGrantQuery_ProjectIdQuery{ProjectIdQuery: &user.UserGrantProjectIDQuery{ProjectId: projectID}}},
+			},
+		})
+		if err != nil {
+			return false, err
+		}
+		if slices.ContainsFunc(resp.GetResult(), func(grant *user.UserGrant) bool {
+			return grant.GetState() == user.UserGrantState_USER_GRANT_STATE_ACTIVE
+		}) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
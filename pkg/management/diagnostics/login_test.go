@@ -0,0 +1,311 @@
+package diagnostics
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/app"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/management"
+	objectV2 "github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/object/v2"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/project"
+	settings "github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/settings/v2"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/user"
+	userV2 "github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/user/v2"
+	"github.com/zitadel/zitadel-go/v3/pkg/resources/resourcestest"
+)
+
+// loginInstance serves the APIs used by [Diagnostics.Login] for the user `user1` of the organization `org1`
+// and the application `app1` of the project `project1`.
+type loginInstance struct {
+	t             *testing.T
+	user          *userV2.User
+	methods       []userV2.AuthenticationMethodType
+	login         *settings.LoginSettings
+	idps          []*settings.IdentityProvider
+	app           *app.App
+	project       *project.Project
+	projectGrants []*project.GrantedProject
+	// userGrants are the grants of the user per organization
+	userGrants map[string][]*user.UserGrant
+}
+
+func newLoginInstance(t *testing.T) *loginInstance {
+	return &loginInstance{
+		t: t,
+		user: &userV2.User{
+			UserId:  "user1",
+			State:   userV2.UserState_USER_STATE_ACTIVE,
+			Details: &objectV2.Details{ResourceOwner: "org1"},
+			Type: &userV2.User_Human{Human: &userV2.HumanUser{
+				Email: &userV2.HumanEmail{Email: "user1@example.com", IsVerified: true},
+			}},
+		},
+		methods:    []userV2.AuthenticationMethodType{userV2.AuthenticationMethodType_AUTHENTICATION_METHOD_TYPE_PASSWORD},
+		login:      &settings.LoginSettings{AllowUsernamePassword: true},
+		app:        &app.App{Id: "app1", Name: "app", State: app.AppState_APP_STATE_ACTIVE},
+		project:    &project.Project{Id: "project1", Name: "project", State: project.ProjectState_PROJECT_STATE_ACTIVE},
+		userGrants: make(map[string][]*user.UserGrant),
+	}
+}
+
+func (i *loginInstance) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var resp proto.Message
+	switch r.URL.Path {
+	case "/v2/users/user1":
+		resp = &userV2.GetUserByIDResponse{User: i.user}
+	case "/v2/users/user1/authentication_methods":
+		resp = &userV2.ListAuthenticationMethodTypesResponse{AuthMethodTypes: i.methods}
+	case "/v2/settings/login":
+		resp = &settings.GetLoginSettingsResponse{Settings: i.login}
+	case "/v2/settings/login/idps":
+		resp = &settings.GetActiveIdentityProvidersResponse{IdentityProviders: i.idps}
+	case "/management/v1/projects/project1/apps/app1":
+		resp = &management.GetAppByIDResponse{App: i.app}
+	case "/management/v1/projects/project1":
+		resp = &management.GetProjectByIDResponse{Project: i.project}
+	case "/management/v1/projects/project1/grants/_search":
+		resp = &management.ListProjectGrantsResponse{Result: i.projectGrants}
+	case "/management/v1/users/grants/_search":
+		resp = &management.ListUserGrantResponse{Result: i.userGrants[r.Header.Get("x-zitadel-orgid")]}
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	data, err := protojson.Marshal(resp)
+	require.NoError(i.t, err)
+	w.Write(data)
+}
+
+func TestDiagnostics_Login(t *testing.T) {
+	activeGrant := []*user.UserGrant{{State: user.UserGrantState_USER_GRANT_STATE_ACTIVE}}
+	tests := []struct {
+		name                  string
+		prepare               func(i *loginInstance)
+		projectOrganizationID string
+		wantBlockers          []string
+		wantWarnings          []string
+	}{
+		{
+			name: "valid",
+		},
+		{
+			name:         "initial user",
+			prepare:      func(i *loginInstance) { i.user.State = userV2.UserState_USER_STATE_INITIAL },
+			wantWarnings: []string{CheckUserState},
+		},
+		{
+			name:         "locked user",
+			prepare:      func(i *loginInstance) { i.user.State = userV2.UserState_USER_STATE_LOCKED },
+			wantBlockers: []string{CheckUserState},
+		},
+		{
+			name:         "inactive user",
+			prepare:      func(i *loginInstance) { i.user.State = userV2.UserState_USER_STATE_INACTIVE },
+			wantBlockers: []string{CheckUserState},
+		},
+		{
+			name:         "deleted user",
+			prepare:      func(i *loginInstance) { i.user.State = userV2.UserState_USER_STATE_DELETED },
+			wantBlockers: []string{CheckUserState},
+		},
+		{
+			name:         "unknown state",
+			prepare:      func(i *loginInstance) { i.user.State = userV2.UserState_USER_STATE_UNSPECIFIED },
+			wantBlockers: []string{CheckUserState},
+		},
+		{
+			name:         "service user",
+			prepare:      func(i *loginInstance) { i.user.Type = &userV2.User_Machine{Machine: &userV2.MachineUser{}} },
+			wantBlockers: []string{CheckUserType},
+		},
+		{
+			name:         "password change required",
+			prepare:      func(i *loginInstance) { i.user.GetHuman().PasswordChangeRequired = true },
+			wantWarnings: []string{CheckPassword},
+		},
+		{
+			name:         "email not verified",
+			prepare:      func(i *loginInstance) { i.user.GetHuman().Email.IsVerified = false },
+			wantWarnings: []string{CheckEmail},
+		},
+		{
+			name:         "password not allowed",
+			prepare:      func(i *loginInstance) { i.login.AllowUsernamePassword = false },
+			wantBlockers: []string{CheckLoginMethods},
+		},
+		{
+			name:         "no authentication method",
+			prepare:      func(i *loginInstance) { i.methods = nil },
+			wantBlockers: []string{CheckLoginMethods},
+		},
+		{
+			name: "passkeys not allowed",
+			prepare: func(i *loginInstance) {
+				i.methods = []userV2.AuthenticationMethodType{userV2.AuthenticationMethodType_AUTHENTICATION_METHOD_TYPE_PASSKEY}
+			},
+			wantBlockers: []string{CheckLoginMethods},
+		},
+		{
+			name: "passkey allowed",
+			prepare: func(i *loginInstance) {
+				i.methods = []userV2.AuthenticationMethodType{userV2.AuthenticationMethodType_AUTHENTICATION_METHOD_TYPE_PASSKEY}
+				i.login.PasskeysType = settings.PasskeysType_PASSKEYS_TYPE_ALLOWED
+			},
+		},
+		{
+			name: "external identity providers not allowed",
+			prepare: func(i *loginInstance) {
+				i.methods = []userV2.AuthenticationMethodType{userV2.AuthenticationMethodType_AUTHENTICATION_METHOD_TYPE_IDP}
+				i.idps = []*settings.IdentityProvider{{Id: "idp1"}}
+			},
+			wantBlockers: []string{CheckLoginMethods},
+		},
+		{
+			name: "no active identity provider",
+			prepare: func(i *loginInstance) {
+				i.methods = []userV2.AuthenticationMethodType{userV2.AuthenticationMethodType_AUTHENTICATION_METHOD_TYPE_IDP}
+				i.login.AllowExternalIdp = true
+			},
+			wantBlockers: []string{CheckLoginMethods},
+		},
+		{
+			name: "identity provider",
+			prepare: func(i *loginInstance) {
+				i.methods = []userV2.AuthenticationMethodType{userV2.AuthenticationMethodType_AUTHENTICATION_METHOD_TYPE_IDP}
+				i.login.AllowExternalIdp = true
+				i.idps = []*settings.IdentityProvider{{Id: "idp1"}}
+			},
+		},
+		{
+			name:         "MFA enforced without factors",
+			prepare:      func(i *loginInstance) { i.login.ForceMfa = true },
+			wantBlockers: []string{CheckMFA},
+		},
+		{
+			name: "MFA enforced without second factor of the user",
+			prepare: func(i *loginInstance) {
+				i.login.ForceMfa = true
+				i.login.SecondFactors = []settings.SecondFactorType{settings.SecondFactorType_SECOND_FACTOR_TYPE_OTP}
+			},
+			wantWarnings: []string{CheckMFA},
+		},
+		{
+			name: "MFA enforced with second factor of the user",
+			prepare: func(i *loginInstance) {
+				i.login.ForceMfaLocalOnly = true
+				i.login.SecondFactors = []settings.SecondFactorType{settings.SecondFactorType_SECOND_FACTOR_TYPE_OTP}
+				i.methods = append(i.methods, userV2.AuthenticationMethodType_AUTHENTICATION_METHOD_TYPE_TOTP)
+			},
+		},
+		{
+			name:         "application deactivated",
+			prepare:      func(i *loginInstance) { i.app.State = app.AppState_APP_STATE_INACTIVE },
+			wantBlockers: []string{CheckApplication},
+		},
+		{
+			name:         "project deactivated",
+			prepare:      func(i *loginInstance) { i.project.State = project.ProjectState_PROJECT_STATE_INACTIVE },
+			wantBlockers: []string{CheckApplication},
+		},
+		{
+			name:                  "project not granted",
+			prepare:               func(i *loginInstance) { i.project.HasProjectCheck = true },
+			projectOrganizationID: "org2",
+			wantBlockers:          []string{CheckProjectAccess},
+		},
+		{
+			name: "project grant inactive",
+			prepare: func(i *loginInstance) {
+				i.project.HasProjectCheck = true
+				i.projectGrants = []*project.GrantedProject{{GrantedOrgId: "org1", State: project.ProjectGrantState_PROJECT_GRANT_STATE_INACTIVE}}
+			},
+			projectOrganizationID: "org2",
+			wantBlockers:          []string{CheckProjectAccess},
+		},
+		{
+			name: "project granted",
+			prepare: func(i *loginInstance) {
+				i.project.HasProjectCheck = true
+				i.projectGrants = []*project.GrantedProject{{GrantedOrgId: "org1", State: project.ProjectGrantState_PROJECT_GRANT_STATE_ACTIVE}}
+			},
+			projectOrganizationID: "org2",
+		},
+		{
+			name:         "no user grant",
+			prepare:      func(i *loginInstance) { i.project.ProjectRoleCheck = true },
+			wantBlockers: []string{CheckProjectRoles},
+		},
+		{
+			name: "user grant inactive",
+			prepare: func(i *loginInstance) {
+				i.project.ProjectRoleCheck = true
+				i.userGrants["org1"] = []*user.UserGrant{{State: user.UserGrantState_USER_GRANT_STATE_INACTIVE}}
+			},
+			wantBlockers: []string{CheckProjectRoles},
+		},
+		{
+			name: "user granted in the organization of the project",
+			prepare: func(i *loginInstance) {
+				i.project.ProjectRoleCheck = true
+				i.userGrants["org2"] = activeGrant
+			},
+			projectOrganizationID: "org2",
+		},
+		{
+			name: "user granted in the organization of the user",
+			prepare: func(i *loginInstance) {
+				i.project.ProjectRoleCheck = true
+				i.userGrants["org1"] = activeGrant
+			},
+			projectOrganizationID: "org2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			instance := newLoginInstance(t)
+			if tt.prepare != nil {
+				tt.prepare(instance)
+			}
+			d := New(resourcestest.NewClient(t, instance.ServeHTTP))
+
+			report, err := d.Login(context.Background(), LoginRequest{
+				UserID:                "user1",
+				ProjectID:             "project1",
+				AppID:                 "app1",
+				ProjectOrganizationID: tt.projectOrganizationID,
+			})
+			require.NoError(t, err)
+			var blockers, warnings []string
+			for _, finding := range report.Findings {
+				switch finding.Severity {
+				case SeverityBlocker:
+					blockers = append(blockers, finding.Check)
+				case SeverityWarning:
+					warnings = append(warnings, finding.Check)
+				}
+			}
+			assert.Equal(t, tt.wantBlockers, blockers, report.String())
+			assert.Equal(t, tt.wantWarnings, warnings, report.String())
+			assert.Equal(t, len(tt.wantBlockers) == 0, report.OK())
+		})
+	}
+}
+
+func TestDiagnostics_Login_error(t *testing.T) {
+	d := New(resourcestest.NewClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/management/") {
+			http.Error(w, `{"code":7,"message":"missing permission"}`, http.StatusForbidden)
+			return
+		}
+		newLoginInstance(t).ServeHTTP(w, r)
+	}))
+	_, err := d.Login(context.Background(), LoginRequest{UserID: "user1", ProjectID: "project1", AppID: "app1"})
+	assert.ErrorContains(t, err, "missing permission")
+}
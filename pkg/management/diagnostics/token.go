@@ -0,0 +1,318 @@
+package diagnostics
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	oidcclient "github.com/zitadel/oidc/v3/pkg/client"
+	"github.com/zitadel/oidc/v3/pkg/client/rs"
+	"github.com/zitadel/oidc/v3/pkg/oidc"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/authorization/oauth"
+	"github.com/zitadel/zitadel-go/v3/pkg/client"
+	"github.com/zitadel/zitadel-go/v3/pkg/zitadel"
+)
+
+// Checks of the token diagnosis.
+const (
+	CheckTokenFormat        = "token_format"
+	CheckTokenSignature     = "token_signature"
+	CheckTokenIntrospection = "token_introspection"
+	CheckTokenIssuer        = "token_issuer"
+	CheckTokenExpiry        = "token_expiry"
+	CheckTokenAudience      = "token_audience"
+	CheckTokenRoles         = "token_roles"
+)
+
+const (
+	claimRoles        = "urn:zitadel:iam:org:project:roles"
+	claimProjectRoles = "urn:zitadel:iam:org:project:%s:roles"
+	scopeProjectRoles = "urn:zitadel:iam:org:projects:roles"
+	// tokenClockSkew is the tolerated difference of the clocks of ZITADEL and the resource server.
+	tokenClockSkew = 10 * time.Second
+)
+
+var tokenAlgorithms = []jose.SignatureAlgorithm{
+	jose.RS256, jose.RS384, jose.RS512,
+	jose.ES256, jose.ES384, jose.ES512,
+	jose.PS256, jose.PS384, jose.PS512,
+	jose.EdDSA,
+}
+
+// TokenRequest describes a token and the expectations of the resource server rejecting it.
+type TokenRequest struct {
+	// Token is the access or ID token.
+	Token string
+	// Audience is checked if set: the token must contain one of them, e.g. the project or client ID of the API.
+	Audience []string
+	// ProjectID of the API, used for the project specific roles claim and in the hints.
+	ProjectID string
+	// Roles the user must have been granted in the project.
+	Roles []string
+	// Introspection validates the token at the introspection endpoint if set,
+	// which is the only way to validate opaque tokens and to detect revoked tokens.
+	Introspection oauth.IntrospectionAuthentication
+}
+
+// TokenReport is the result of [Token], containing the decoded token additionally to the findings.
+type TokenReport struct {
+	Report
+	// Header of a JWT, empty for opaque tokens.
+	Header map[string]any `json:"header,omitempty"`
+	// Claims of a JWT or returned by the introspection.
+	Claims map[string]any `json:"claims,omitempty"`
+}
+
+// Token decodes the token, verifies its signature by the keys of the instance, introspects it (if configured)
+// and checks its issuer, expiry, audience and roles, reporting the reasons a resource server would reject it.
+// An error is only returned if the discovery or the keys of the instance could not be retrieved.
+func Token(ctx context.Context, zitadel *zitadel.Zitadel, req TokenRequest) (*TokenReport, error) {
+	return diagnoseToken(ctx, http.DefaultClient, zitadel.Origin(), req)
+}
+
+func diagnoseToken(ctx context.Context, httpClient *http.Client, issuer string, req TokenRequest) (*TokenReport, error) {
+	report := new(TokenReport)
+	discovery, err := oidcclient.Discover(ctx, issuer, httpClient)
+	if err != nil {
+		return nil, err
+	}
+	token := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(req.Token), oidc.BearerToken))
+	if header, claims, ok := decodeJWT(token); ok {
+		report.Header, report.Claims = header, claims
+		report.add(SeverityInfo, CheckTokenFormat, "JWT signed with %v by key %v", header["alg"], header["kid"])
+		if err = checkSignature(ctx, &report.Report, httpClient, discovery.JwksURI, token); err != nil {
+			return nil, err
+		}
+	} else if req.Introspection == nil {
+		report.add(SeverityWarning, CheckTokenFormat, "opaque token, it can only be validated by introspection")
+	} else {
+		report.add(SeverityInfo, CheckTokenFormat, "opaque token")
+	}
+	if req.Introspection != nil {
+		claims := introspect(ctx, &report.Report, req.Introspection, discovery.Issuer, token)
+		if report.Claims == nil {
+			report.Claims = claims
+		}
+	}
+	if report.Claims == nil {
+		return report, nil
+	}
+	checkTokenIssuer(&report.Report, report.Claims, discovery.Issuer)
+	checkTokenExpiry(&report.Report, report.Claims, time.Now())
+	checkTokenAudience(&report.Report, report.Claims, req)
+	checkTokenRoles(&report.Report, report.Claims, req)
+	return report, nil
+}
+
+// decodeJWT returns the header and claims of a JWT without verifying it.
+func decodeJWT(token string) (header, claims map[string]any, ok bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, nil, false
+	}
+	decode := func(part string) map[string]any {
+		data, err := base64.RawURLEncoding.DecodeString(part)
+		if err != nil {
+			return nil
+		}
+		var decoded map[string]any
+		if err = json.Unmarshal(data, &decoded); err != nil {
+			return nil
+		}
+		return decoded
+	}
+	header, claims = decode(parts[0]), decode(parts[1])
+	return header, claims, header != nil && claims != nil
+}
+
+func checkSignature(ctx context.Context, report *Report, httpClient *http.Client, jwksURI, token string) error {
+	jws, err := jose.ParseSigned(token, tokenAlgorithms)
+	if err != nil {
+		report.add(SeverityBlocker, CheckTokenSignature, "invalid JWS: %v", err)
+		return nil
+	}
+	if len(jws.Signatures) != 1 {
+		report.add(SeverityBlocker, CheckTokenSignature, "the token must contain exactly one signature")
+		return nil
+	}
+	keys, err := fetchKeys(ctx, httpClient, jwksURI)
+	if err != nil {
+		return err
+	}
+	keyID := jws.Signatures[0].Protected.KeyID
+	candidates := keys.Key(keyID)
+	if len(candidates) == 0 {
+		report.add(SeverityBlocker, CheckTokenSignature,
+			"key %q is not published by the instance: the token was issued by another instance or the key was removed", keyID)
+		return nil
+	}
+	for _, key := range candidates {
+		if _, err = jws.Verify(key); err == nil {
+			report.add(SeverityInfo, CheckTokenSignature, "valid signature of key %q", keyID)
+			return nil
+		}
+	}
+	report.add(SeverityBlocker, CheckTokenSignature, "invalid signature of key %q: the token was modified", keyID)
+	return nil
+}
+
+func fetchKeys(ctx context.Context, httpClient *http.Client, jwksURI string) (*jose.JSONWebKeySet, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, jwksURI, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("keys of the instance responded with %s", resp.Status)
+	}
+	keys := new(jose.JSONWebKeySet)
+	if err = json.NewDecoder(resp.Body).Decode(keys); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// introspect reports the result of the introspection and returns the claims of an active token.
+func introspect(ctx context.Context, report *Report, auth oauth.IntrospectionAuthentication, issuer, token string) map[string]any {
+	resourceServer, err := auth(ctx, issuer)
+	if err != nil {
+		report.add(SeverityBlocker, CheckTokenIntrospection, "introspection is not possible, check the credentials of the API: %v", err)
+		return nil
+	}
+	resp, err := rs.Introspect[*oidc.IntrospectionResponse](ctx, resourceServer, token)
+	if err != nil {
+		report.add(SeverityBlocker, CheckTokenIntrospection, "introspection failed, check the credentials of the API: %v", err)
+		return nil
+	}
+	if !resp.Active {
+		report.add(SeverityBlocker, CheckTokenIntrospection,
+			"the token is not active: it expired, was revoked (e.g. by a logout) or was issued by another instance")
+		return nil
+	}
+	report.add(SeverityInfo, CheckTokenIntrospection, "the token is active")
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return nil
+	}
+	var claims map[string]any
+	if err = json.Unmarshal(data, &claims); err != nil {
+		return nil
+	}
+	return claims
+}
+
+func checkTokenIssuer(report *Report, claims map[string]any, issuer string) {
+	iss, _ := claims["iss"].(string)
+	if strings.TrimSuffix(iss, "/") != strings.TrimSuffix(issuer, "/") {
+		report.add(SeverityBlocker, CheckTokenIssuer, "issued by %q instead of %q", iss, issuer)
+	}
+}
+
+func checkTokenExpiry(report *Report, claims map[string]any, now time.Time) {
+	exp, ok := numericDate(claims, "exp")
+	if !ok {
+		report.add(SeverityBlocker, CheckTokenExpiry, "the token has no expiration")
+		return
+	}
+	if now.Add(-tokenClockSkew).After(exp) {
+		report.add(SeverityBlocker, CheckTokenExpiry, "expired %s ago at %s, request a new token (e.g. using the refresh token)",
+			now.Sub(exp).Round(time.Second), exp.Format(time.RFC3339))
+		return
+	}
+	for _, claim := range []string{"nbf", "iat"} {
+		if notBefore, ok := numericDate(claims, claim); ok && now.Add(tokenClockSkew).Before(notBefore) {
+			report.add(SeverityBlocker, CheckTokenExpiry, "not valid before %s (%s), check the clock of the resource server",
+				notBefore.Format(time.RFC3339), claim)
+			return
+		}
+	}
+	report.add(SeverityInfo, CheckTokenExpiry, "valid for %s until %s", exp.Sub(now).Round(time.Second), exp.Format(time.RFC3339))
+}
+
+func checkTokenAudience(report *Report, claims map[string]any, req TokenRequest) {
+	audience := stringsClaim(claims, "aud")
+	if len(req.Audience) == 0 {
+		report.add(SeverityInfo, CheckTokenAudience, "audience: %s", strings.Join(audience, ", "))
+		return
+	}
+	if slices.ContainsFunc(req.Audience, func(aud string) bool { return slices.Contains(audience, aud) }) {
+		report.add(SeverityInfo, CheckTokenAudience, "contains the expected audience")
+		return
+	}
+	hint := "the client must request the API project in the audience by the scope urn:zitadel:iam:org:project:id:<project ID>:aud"
+	if req.ProjectID != "" {
+		hint = "the client must request the scope " + client.ScopeProjectID(req.ProjectID)
+	}
+	report.add(SeverityBlocker, CheckTokenAudience, "audience [%s] does not contain any of [%s], %s",
+		strings.Join(audience, ", "), strings.Join(req.Audience, ", "), hint)
+}
+
+func checkTokenRoles(report *Report, claims map[string]any, req TokenRequest) {
+	roles, ok := claims[claimRoles].(map[string]any)
+	if projectRoles, projectOK := claims[fmt.Sprintf(claimProjectRoles, req.ProjectID)].(map[string]any); req.ProjectID != "" && projectOK {
+		roles, ok = projectRoles, true
+	}
+	if !ok {
+		if len(req.Roles) == 0 {
+			report.add(SeverityInfo, CheckTokenRoles, "the token contains no roles")
+			return
+		}
+		report.add(SeverityBlocker, CheckTokenRoles,
+			"the token contains no roles, enable the role assertion of the project or request the scope %s", scopeProjectRoles)
+		return
+	}
+	granted := make([]string, 0, len(roles))
+	for role := range roles {
+		granted = append(granted, role)
+	}
+	slices.Sort(granted)
+	var missing []string
+	for _, role := range req.Roles {
+		if !slices.Contains(granted, role) {
+			missing = append(missing, role)
+		}
+	}
+	if len(missing) > 0 {
+		report.add(SeverityBlocker, CheckTokenRoles, "missing roles [%s], granted are [%s]: the user needs a grant of the project with the roles",
+			strings.Join(missing, ", "), strings.Join(granted, ", "))
+		return
+	}
+	report.add(SeverityInfo, CheckTokenRoles, "roles: %s", strings.Join(granted, ", "))
+}
+
+func numericDate(claims map[string]any, name string) (time.Time, bool) {
+	value, ok := claims[name].(float64)
+	if !ok || value == 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(value), 0), true
+}
+
+// stringsClaim returns a claim, which is either a string or an array of strings.
+func stringsClaim(claims map[string]any, name string) []string {
+	switch value := claims[name].(type) {
+	case string:
+		return []string{value}
+	case []any:
+		values := make([]string, 0, len(value))
+		for _, v := range value {
+			if s, ok := v.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	default:
+		return nil
+	}
+}
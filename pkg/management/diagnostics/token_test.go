@@ -0,0 +1,119 @@
+package diagnostics
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToken(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	var server *httptest.Server
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{"issuer": server.URL, "jwks_uri": server.URL + "/oauth/v2/keys"})
+	})
+	mux.HandleFunc("/oauth/v2/keys", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+			{Key: key.Public(), KeyID: "k1", Algorithm: string(jose.ES256), Use: "sig"},
+		}})
+	})
+	server = httptest.NewServer(mux)
+	defer server.Close()
+
+	now := time.Now()
+	sign := func(key *ecdsa.PrivateKey, modify func(claims map[string]any)) string {
+		claims := map[string]any{
+			"iss":                               server.URL,
+			"sub":                               "user1",
+			"aud":                               []string{"api", "client"},
+			"exp":                               now.Add(time.Hour).Unix(),
+			"iat":                               now.Unix(),
+			"urn:zitadel:iam:org:project:roles": map[string]any{"reader": map[string]any{"org1": "acme.example.com"}},
+		}
+		if modify != nil {
+			modify(claims)
+		}
+		signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.ES256, Key: jose.JSONWebKey{Key: key, KeyID: "k1"}}, nil)
+		require.NoError(t, err)
+		payload, err := json.Marshal(claims)
+		require.NoError(t, err)
+		jws, err := signer.Sign(payload)
+		require.NoError(t, err)
+		token, err := jws.CompactSerialize()
+		require.NoError(t, err)
+		return token
+	}
+
+	tests := []struct {
+		name         string
+		req          TokenRequest
+		wantBlockers []string
+	}{
+		{
+			name: "valid",
+			req:  TokenRequest{Token: "Bearer " + sign(key, nil), Audience: []string{"api"}, Roles: []string{"reader"}},
+		},
+		{
+			name: "opaque",
+			req:  TokenRequest{Token: "opaque-token"},
+		},
+		{
+			name:         "other key",
+			req:          TokenRequest{Token: sign(otherKey, nil)},
+			wantBlockers: []string{CheckTokenSignature},
+		},
+		{
+			name: "expired and other issuer",
+			req: TokenRequest{Token: sign(key, func(claims map[string]any) {
+				claims["exp"] = now.Add(-time.Hour).Unix()
+				claims["iss"] = "https://other.example.com"
+			})},
+			wantBlockers: []string{CheckTokenIssuer, CheckTokenExpiry},
+		},
+		{
+			name:         "audience and roles",
+			req:          TokenRequest{Token: sign(key, nil), Audience: []string{"other"}, ProjectID: "p1", Roles: []string{"reader", "writer"}},
+			wantBlockers: []string{CheckTokenAudience, CheckTokenRoles},
+		},
+		{
+			name: "project roles",
+			req: TokenRequest{Token: sign(key, func(claims map[string]any) {
+				delete(claims, "urn:zitadel:iam:org:project:roles")
+				claims["urn:zitadel:iam:org:project:p1:roles"] = map[string]any{"writer": map[string]any{}}
+			}), ProjectID: "p1", Roles: []string{"writer"}},
+		},
+		{
+			name: "no roles",
+			req: TokenRequest{Token: sign(key, func(claims map[string]any) {
+				delete(claims, "urn:zitadel:iam:org:project:roles")
+			}), Roles: []string{"reader"}},
+			wantBlockers: []string{CheckTokenRoles},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := diagnoseToken(context.Background(), server.Client(), server.URL, tt.req)
+			require.NoError(t, err)
+			var blockers []string
+			for _, finding := range report.Blockers() {
+				blockers = append(blockers, finding.Check)
+			}
+			assert.Equal(t, tt.wantBlockers, blockers, report.String())
+		})
+	}
+}
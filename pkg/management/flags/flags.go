@@ -0,0 +1,214 @@
+// Package flags provides per-organization feature flags stored as JSON in the metadata of the organizations,
+// so features can be gated per customer organization without an additional service.
+package flags
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/management"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/metadata"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/object"
+	"github.com/zitadel/zitadel-go/v3/pkg/internal/pager"
+)
+
+const (
+	// DefaultKeyPrefix is the prefix of the metadata keys of the flags.
+	DefaultKeyPrefix = "flag."
+	// DefaultCacheTTL is the duration the flags of an organization are cached.
+	DefaultCacheTTL = time.Minute
+)
+
+var ErrInvalidName = errors.New("flag name must not be empty")
+
+// Flags reads and writes the feature flags of organizations.
+// The flags of an organization are loaded at once and cached locally,
+// the cache is invalidated on changes (see [Flags.Watch]) or after the TTL.
+type Flags struct {
+	client    *client.Client
+	keyPrefix string
+	cacheTTL  time.Duration
+	now       func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]*cachedFlags
+
+	subscribersMu sync.Mutex
+	subscribers   []chan<- Change
+}
+
+type cachedFlags struct {
+	flags    map[string]json.RawMessage
+	loadedAt time.Time
+}
+
+// Option allows customization of the [Flags].
+type Option func(*Flags)
+
+// WithKeyPrefix sets the prefix of the metadata keys (default `flag.`).
+func WithKeyPrefix(prefix string) Option {
+	return func(f *Flags) {
+		f.keyPrefix = prefix
+	}
+}
+
+// WithCacheTTL sets the duration the flags of an organization are cached (default 1 minute).
+// A zero duration disables the cache.
+func WithCacheTTL(ttl time.Duration) Option {
+	return func(f *Flags) {
+		f.cacheTTL = ttl
+	}
+}
+
+func New(client *client.Client, options ...Option) *Flags {
+	f := &Flags{
+		client:    client,
+		keyPrefix: DefaultKeyPrefix,
+		cacheTTL:  DefaultCacheTTL,
+		now:       time.Now,
+		cache:     make(map[string]*cachedFlags),
+	}
+	for _, option := range options {
+		option(f)
+	}
+	return f
+}
+
+// Set stores the JSON encoded value as flag of the organization.
+func (f *Flags) Set(ctx context.Context, orgID, name string, value any) error {
+	if name == "" {
+		return ErrInvalidName
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	_, err = f.client.ForOrganization(orgID).ManagementService().SetOrgMetadata(ctx, &management.SetOrgMetadataRequest{
+		Key:   f.keyPrefix + name,
+		Value: data,
+	})
+	if err != nil {
+		return err
+	}
+	f.Invalidate(orgID)
+	return nil
+}
+
+// Remove deletes the flag of the organization, subsequent reads will return the default value.
+func (f *Flags) Remove(ctx context.Context, orgID, name string) error {
+	_, err := f.client.ForOrganization(orgID).ManagementService().RemoveOrgMetadata(ctx, &management.RemoveOrgMetadataRequest{
+		Key: f.keyPrefix + name,
+	})
+	if err != nil {
+		return err
+	}
+	f.Invalidate(orgID)
+	return nil
+}
+
+// All returns the raw JSON values of all flags of the organization.
+func (f *Flags) All(ctx context.Context, orgID string) (map[string]json.RawMessage, error) {
+	f.mu.Lock()
+	cached, ok := f.cache[orgID]
+	f.mu.Unlock()
+	if ok && f.now().Sub(cached.loadedAt) < f.cacheTTL {
+		return cached.flags, nil
+	}
+	flags, err := f.load(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if f.cacheTTL > 0 {
+		f.mu.Lock()
+		f.cache[orgID] = &cachedFlags{flags: flags, loadedAt: f.now()}
+		f.mu.Unlock()
+	}
+	return flags, nil
+}
+
+// Raw returns the JSON value of the flag and if it's set.
+func (f *Flags) Raw(ctx context.Context, orgID, name string) (json.RawMessage, bool, error) {
+	flags, err := f.All(ctx, orgID)
+	if err != nil {
+		return nil, false, err
+	}
+	value, ok := flags[name]
+	return value, ok, nil
+}
+
+// Invalidate removes the cached flags of the organization.
+func (f *Flags) Invalidate(orgID string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.cache, orgID)
+}
+
+func (f *Flags) load(ctx context.Context, orgID string) (map[string]json.RawMessage, error) {
+	mgmt := f.client.ForOrganization(orgID).ManagementService()
+	queries := []*metadata.MetadataQuery{{
+		Query: &metadata.MetadataQuery_KeyQuery{
+			KeyQuery: &metadata.MetadataKeyQuery{Key: f.keyPrefix, Method: object.TextQueryMethod_TEXT_QUERY_METHOD_STARTS_WITH},
+		},
+	}}
+	result, err := pager.ListAll(func(query *object.ListQuery) ([]*metadata.Metadata, *object.ListDetails, error) {
+		resp, err := mgmt.ListOrgMetadata(ctx, &management.ListOrgMetadataRequest{Query: query, Queries: queries})
+		return resp.GetResult(), resp.GetDetails(), err
+	})
+	if err != nil {
+		return nil, err
+	}
+	flags := make(map[string]json.RawMessage)
+	for _, md := range result {
+		name, ok := strings.CutPrefix(md.GetKey(), f.keyPrefix)
+		if !ok || !json.Valid(md.GetValue()) {
+			continue
+		}
+		flags[name] = md.GetValue()
+	}
+	return flags, nil
+}
+
+// Get returns the value of the flag decoded into T or the default value if the flag is not set.
+func Get[T any](ctx context.Context, f *Flags, orgID, name string, defaultValue T) (T, error) {
+	raw, ok, err := f.Raw(ctx, orgID, name)
+	if err != nil || !ok {
+		return defaultValue, err
+	}
+	var value T
+	if err = json.Unmarshal(raw, &value); err != nil {
+		return defaultValue, fmt.Errorf("flag %s: %w", name, err)
+	}
+	return value, nil
+}
+
+// Bool returns the boolean value of the flag or the default value if the flag is not set.
+func (f *Flags) Bool(ctx context.Context, orgID, name string, defaultValue bool) (bool, error) {
+	return Get(ctx, f, orgID, name, defaultValue)
+}
+
+// String returns the string value of the flag or the default value if the flag is not set.
+func (f *Flags) String(ctx context.Context, orgID, name string, defaultValue string) (string, error) {
+	return Get(ctx, f, orgID, name, defaultValue)
+}
+
+// Int returns the integer value of the flag or the default value if the flag is not set.
+func (f *Flags) Int(ctx context.Context, orgID, name string, defaultValue int) (int, error) {
+	return Get(ctx, f, orgID, name, defaultValue)
+}
+
+// Float returns the numeric value of the flag or the default value if the flag is not set.
+func (f *Flags) Float(ctx context.Context, orgID, name string, defaultValue float64) (float64, error) {
+	return Get(ctx, f, orgID, name, defaultValue)
+}
+
+// Enabled is a short version of [Flags.Bool] returning false if the flag can't be read.
+func (f *Flags) Enabled(ctx context.Context, orgID, name string) bool {
+	enabled, _ := f.Bool(ctx, orgID, name, false)
+	return enabled
+}
@@ -0,0 +1,95 @@
+package flags
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/events"
+)
+
+const (
+	eventMetadataSet        = "org.metadata.set"
+	eventMetadataRemoved    = "org.metadata.removed"
+	eventMetadataRemovedAll = "org.metadata.removed.all"
+	aggregateTypeOrg        = "org"
+	pageSize                = 100
+)
+
+// Change notifies about a changed flag.
+type Change struct {
+	OrganizationID string
+	// Name of the changed flag, empty if all metadata of the organization was removed.
+	Name    string
+	Removed bool
+	Time    time.Time
+}
+
+// Subscribe returns a channel receiving the changes detected by [Flags.Watch].
+// Changes are dropped if the channel is full.
+func (f *Flags) Subscribe(buffer int) <-chan Change {
+	ch := make(chan Change, buffer)
+	f.subscribersMu.Lock()
+	defer f.subscribersMu.Unlock()
+	f.subscribers = append(f.subscribers, ch)
+	return ch
+}
+
+// Watch polls the events of ZITADEL for changes of the flags in the provided interval,
+// invalidates the cache of the changed organizations and notifies the subscribers (see [Flags.Subscribe]).
+// It blocks until the context is done and requires the client to be authorized to read the events (e.g. IAM_OWNER_VIEWER).
+func (f *Flags) Watch(ctx context.Context, interval time.Duration) error {
+	defer f.closeSubscribers()
+	subscriber := events.New(f.client, events.WithInterval(interval), events.WithFrom(f.now()), events.WithPageSize(pageSize))
+	changes, err := subscriber.Subscribe(ctx, events.Filter{
+		AggregateTypes: []string{aggregateTypeOrg},
+		EventTypes:     []string{eventMetadataSet, eventMetadataRemoved, eventMetadataRemovedAll},
+	})
+	if err != nil {
+		return err
+	}
+	// temporary errors are retried with the next poll
+	for e := range changes {
+		f.handleEvent(e)
+	}
+	return ctx.Err()
+}
+
+func (f *Flags) handleEvent(e *events.Event) {
+	change := Change{
+		OrganizationID: e.AggregateID,
+		Time:           e.CreationDate,
+		Removed:        e.Type != eventMetadataSet,
+	}
+	if e.Type != eventMetadataRemovedAll {
+		var payload struct {
+			Key string `json:"key"`
+		}
+		if err := e.Decode(&payload); err != nil {
+			return
+		}
+		name, ok := strings.CutPrefix(payload.Key, f.keyPrefix)
+		if !ok {
+			return
+		}
+		change.Name = name
+	}
+	f.Invalidate(change.OrganizationID)
+	f.subscribersMu.Lock()
+	defer f.subscribersMu.Unlock()
+	for _, subscriber := range f.subscribers {
+		select {
+		case subscriber <- change:
+		default:
+		}
+	}
+}
+
+func (f *Flags) closeSubscribers() {
+	f.subscribersMu.Lock()
+	defer f.subscribersMu.Unlock()
+	for _, subscriber := range f.subscribers {
+		close(subscriber)
+	}
+	f.subscribers = nil
+}
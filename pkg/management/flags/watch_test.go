@@ -0,0 +1,189 @@
+package flags
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/admin"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/event"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/management"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/metadata"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/object"
+	"github.com/zitadel/zitadel-go/v3/pkg/resources/resourcestest"
+)
+
+// testInstance serves the metadata and events of the organizations on the HTTP/JSON endpoints.
+type testInstance struct {
+	mu       sync.Mutex
+	metadata map[string]map[string]string
+	events   []*event.Event
+	loads    int
+}
+
+func newTestInstance() *testInstance {
+	return &testInstance{metadata: make(map[string]map[string]string)}
+}
+
+func (i *testInstance) setMetadata(orgID, key, value string, created time.Time) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if i.metadata[orgID] == nil {
+		i.metadata[orgID] = make(map[string]string)
+	}
+	i.metadata[orgID][key] = value
+	i.addEvent(orgID, eventMetadataSet, map[string]any{"key": key, "value": base64.StdEncoding.EncodeToString([]byte(value))}, created)
+}
+
+func (i *testInstance) removeAllMetadata(orgID string, created time.Time) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	delete(i.metadata, orgID)
+	i.addEvent(orgID, eventMetadataRemovedAll, nil, created)
+}
+
+func (i *testInstance) addEvent(orgID, eventType string, payload map[string]any, created time.Time) {
+	p, _ := structpb.NewStruct(payload)
+	i.events = append(i.events, &event.Event{
+		Aggregate:    &event.Aggregate{Id: orgID, Type: &event.AggregateType{Type: aggregateTypeOrg}, ResourceOwner: orgID},
+		Sequence:     uint64(len(i.events) + 1),
+		CreationDate: timestamppb.New(created),
+		Type:         &event.EventType{Type: eventType},
+		Payload:      p,
+	})
+}
+
+func (i *testInstance) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	var resp proto.Message
+	switch r.URL.Path {
+	case "/admin/v1/events/_search":
+		req := new(admin.ListEventsRequest)
+		if err := protojson.Unmarshal(body, req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		list := new(admin.ListEventsResponse)
+		for _, e := range i.events {
+			if from := req.GetFrom(); from != nil && e.GetCreationDate().AsTime().Before(from.AsTime()) {
+				continue
+			}
+			if uint32(len(list.Events)) == req.GetLimit() {
+				break
+			}
+			list.Events = append(list.Events, e)
+		}
+		resp = list
+	case "/management/v1/metadata/_search":
+		i.loads++
+		list := &management.ListOrgMetadataResponse{Details: new(object.ListDetails)}
+		for key, value := range i.metadata[r.Header.Get("x-zitadel-orgid")] {
+			list.Result = append(list.Result, &metadata.Metadata{Key: key, Value: []byte(value)})
+		}
+		list.Details.TotalResult = uint64(len(list.Result))
+		resp = list
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	data, _ := protojson.Marshal(resp)
+	_, _ = w.Write(data)
+}
+
+// watch starts [Flags.Watch] of the instance for changes since start and returns the subscription and the result of the watch.
+func watch(t *testing.T, ctx context.Context, instance *testInstance, start time.Time) (*Flags, <-chan Change, <-chan error) {
+	f := New(resourcestest.NewClient(t, instance.ServeHTTP))
+	f.now = func() time.Time { return start }
+	changes := f.Subscribe(1000)
+	done := make(chan error, 1)
+	go func() { done <- f.Watch(ctx, 10*time.Millisecond) }()
+	return f, changes, done
+}
+
+func receive(t *testing.T, changes <-chan Change) Change {
+	select {
+	case change := <-changes:
+		return change
+	case <-time.After(5 * time.Second):
+		t.Fatal("no change received")
+		return Change{}
+	}
+}
+
+func TestFlags_Watch(t *testing.T) {
+	start := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	instance := newTestInstance()
+	instance.setMetadata("org1", "flag.beta", "true", start)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	f, changes, _ := watch(t, ctx, instance, start)
+
+	assert.Equal(t, Change{OrganizationID: "org1", Name: "beta", Time: start}, receive(t, changes))
+	assert.True(t, f.Enabled(ctx, "org1", "beta"))
+	assert.True(t, f.Enabled(ctx, "org1", "beta"), "cached")
+	assert.Equal(t, 1, instance.loads)
+
+	instance.setMetadata("org1", "other", "ignored", start.Add(time.Second))
+	instance.setMetadata("org1", "flag.beta", "false", start.Add(2*time.Second))
+	assert.Equal(t, Change{OrganizationID: "org1", Name: "beta", Time: start.Add(2 * time.Second)}, receive(t, changes),
+		"metadata without the prefix is ignored")
+	assert.False(t, f.Enabled(ctx, "org1", "beta"), "the cache is invalidated")
+	assert.Equal(t, 2, instance.loads)
+
+	instance.removeAllMetadata("org1", start.Add(3*time.Second))
+	assert.Equal(t, Change{OrganizationID: "org1", Removed: true, Time: start.Add(3 * time.Second)}, receive(t, changes))
+	value, err := f.String(ctx, "org1", "beta", "default")
+	require.NoError(t, err)
+	assert.Equal(t, "default", value)
+}
+
+func TestFlags_Watch_paging(t *testing.T) {
+	start := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	instance := newTestInstance()
+	// more than a page of changes at the same time, e.g. by a bulk update
+	const sameTime = 2*pageSize + 50
+	for i := 0; i < sameTime; i++ {
+		instance.setMetadata(fmt.Sprintf("org%d", i), "flag.beta", "true", start)
+	}
+	instance.setMetadata("org0", "flag.beta", "false", start.Add(time.Second))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_, changes, _ := watch(t, ctx, instance, start)
+
+	orgs := make(map[string]bool)
+	for i := 0; i < sameTime; i++ {
+		change := receive(t, changes)
+		assert.False(t, orgs[change.OrganizationID], "notified twice: %s", change.OrganizationID)
+		orgs[change.OrganizationID] = true
+	}
+	assert.Equal(t, Change{OrganizationID: "org0", Name: "beta", Time: start.Add(time.Second)}, receive(t, changes),
+		"the change after the changes at the same time is received")
+}
+
+func TestFlags_Watch_cancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	_, changes, done := watch(t, ctx, newTestInstance(), time.Now())
+	cancel()
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-time.After(5 * time.Second):
+		t.Fatal("watch did not return")
+	}
+	_, ok := <-changes
+	assert.False(t, ok, "the subscriptions are closed")
+}
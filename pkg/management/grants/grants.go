@@ -0,0 +1,50 @@
+// Package grants provides higher level operations on user grants spanning multiple organizations.
+package grants
+
+import (
+	"github.com/zitadel/zitadel-go/v3/pkg/audit"
+	"github.com/zitadel/zitadel-go/v3/pkg/client"
+)
+
+// Grants provides operations on the user grants of the instance.
+type Grants struct {
+	client  *client.Client
+	queue   ExpiryQueue
+	sinks   []audit.Sink
+	onError func(expiry *Expiry, err error)
+}
+
+// Option allows customization of [Grants].
+type Option func(*Grants)
+
+// WithExpiryQueue sets the queue of the temporary grants (see [Grants.Temporary] and [Grants.RunExpiries]).
+func WithExpiryQueue(queue ExpiryQueue) Option {
+	return func(g *Grants) {
+		g.queue = queue
+	}
+}
+
+// WithAuditSinks writes an [audit.Record] for every granted and revoked temporary grant to the sinks.
+func WithAuditSinks(sinks ...audit.Sink) Option {
+	return func(g *Grants) {
+		g.sinks = append(g.sinks, sinks...)
+	}
+}
+
+// WithExpiryErrorHandler is called if a temporary grant could not be revoked by [Grants.RunExpiries],
+// which is retried on the next run, or if an audit record could not be written ([ErrAuditFailed]).
+func WithExpiryErrorHandler(onError func(expiry *Expiry, err error)) Option {
+	return func(g *Grants) {
+		g.onError = onError
+	}
+}
+
+// New creates [Grants] using the client.
+// Operations across all organizations require the client to be authorized on instance level (e.g. IAM_OWNER).
+func New(client *client.Client, opts ...Option) *Grants {
+	g := &Grants{client: client}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g
+}
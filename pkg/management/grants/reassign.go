@@ -0,0 +1,270 @@
+package grants
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/admin"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/management"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/object"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/org"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/user"
+	"github.com/zitadel/zitadel-go/v3/pkg/internal/pager"
+	"github.com/zitadel/zitadel-go/v3/pkg/job"
+)
+
+var ErrSameRole = errors.New("source and target role must differ")
+
+// Filter restricts the user grants handled by [Grants.BulkReassign].
+type Filter struct {
+	// ProjectID restricts the grants to the project (recommended, since role keys are only unique per project).
+	ProjectID string
+	// OrganizationIDs restricts the grants to the organizations, all organizations of the instance if empty.
+	OrganizationIDs []string
+	// UserIDs restricts the grants to the users, all users if empty.
+	UserIDs []string
+}
+
+// Progress is reported for every handled user grant.
+type Progress struct {
+	OrganizationID string
+	Grant          *user.UserGrant
+	// RoleKeys are the new roles of the grant.
+	RoleKeys []string
+	// Err is the error of the update, if any.
+	Err error
+	// Done is the number of handled grants (including this one) of Total found so far.
+	Done, Total int
+}
+
+// Result summarizes a [Grants.BulkReassign].
+type Result struct {
+	Updated  int
+	Failures []Failure
+}
+
+// Failure is a user grant which could not be updated.
+type Failure struct {
+	OrganizationID string
+	UserID         string
+	GrantID        string
+	Err            error
+}
+
+// ReassignOption allows customization of [Grants.BulkReassign].
+type ReassignOption func(*reassignConfig)
+
+type reassignConfig struct {
+	keepSource bool
+	dryRun     bool
+	progress   func(Progress)
+	job        *job.Controller
+}
+
+// WithKeepSourceRole adds the target role to the grants instead of replacing the source role.
+func WithKeepSourceRole() ReassignOption {
+	return func(c *reassignConfig) {
+		c.keepSource = true
+	}
+}
+
+// WithDryRun reports the changes without updating any grants.
+func WithDryRun() ReassignOption {
+	return func(c *reassignConfig) {
+		c.dryRun = true
+	}
+}
+
+// WithProgress calls the function for every handled user grant.
+func WithProgress(progress func(Progress)) ReassignOption {
+	return func(c *reassignConfig) {
+		c.progress = progress
+	}
+}
+
+// WithJob allows to pause and resume the reassignment and persists its progress (see [job.Controller]).
+// A reassignment started again with the same job continues after the organizations completed before.
+func WithJob(ctrl *job.Controller) ReassignOption {
+	return func(c *reassignConfig) {
+		c.job = ctrl
+	}
+}
+
+// BulkReassign finds all user grants with the fromRole (matching the filter) and replaces it with the toRole,
+// e.g. for role renames or refactorings of the permission model.
+// The target role must already exist on the project (and be granted in case of project grants).
+//
+// Failing updates don't stop the reassignment and are returned as [Result.Failures],
+// an error is only returned if the grants could not be listed or the context is canceled.
+func (g *Grants) BulkReassign(ctx context.Context, fromRole, toRole string, filter Filter, options ...ReassignOption) (*Result, error) {
+	if fromRole == toRole {
+		return nil, ErrSameRole
+	}
+	config := new(reassignConfig)
+	for _, option := range options {
+		option(config)
+	}
+	progress, err := config.job.Start(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result, err := bulkReassign(ctx, g, fromRole, toRole, filter, config, progress.Cursor)
+	return result, errors.Join(err, config.job.Finish(ctx, err))
+}
+
+// instance provides the organizations of the instance and their management services,
+// it's implemented by [Grants] and replaced in tests.
+type instance interface {
+	organizationIDs(ctx context.Context) ([]string, error)
+	managementService(orgID string) management.ManagementServiceClient
+}
+
+// bulkReassign reassigns the grants of the organizations after the one of the cursor.
+func bulkReassign(ctx context.Context, instance instance, fromRole, toRole string, filter Filter, config *reassignConfig, cursor string) (*Result, error) {
+	orgIDs := filter.OrganizationIDs
+	if len(orgIDs) == 0 {
+		var err error
+		orgIDs, err = instance.organizationIDs(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if i := slices.Index(orgIDs, cursor); i >= 0 {
+		orgIDs = orgIDs[i+1:]
+	}
+
+	result := new(Result)
+	var done, total int
+	for _, orgID := range orgIDs {
+		mgmt := instance.managementService(orgID)
+		// all grants are listed before updating, since updated grants would no longer match the query
+		grants, err := listGrants(ctx, mgmt, fromRole, filter)
+		if err != nil {
+			return result, err
+		}
+		total += len(grants)
+		if err = config.job.Update(ctx, func(progress *job.Progress) {
+			// the grants of the following organizations are not known yet
+			progress.Total = progress.Done + progress.Failed + len(grants)
+		}); err != nil {
+			return result, err
+		}
+		for _, grant := range grants {
+			if err = config.job.Wait(ctx); err != nil {
+				return result, err
+			}
+			done++
+			roleKeys := reassignRoles(grant.GetRoleKeys(), fromRole, toRole, config.keepSource)
+			if !config.dryRun {
+				_, err = mgmt.UpdateUserGrant(ctx, &management.UpdateUserGrantRequest{
+					UserId:   grant.GetUserId(),
+					GrantId:  grant.GetId(),
+					RoleKeys: roleKeys,
+				})
+			}
+			if err != nil {
+				result.Failures = append(result.Failures, Failure{
+					OrganizationID: orgID,
+					UserID:         grant.GetUserId(),
+					GrantID:        grant.GetId(),
+					Err:            err,
+				})
+			} else {
+				result.Updated++
+			}
+			if config.progress != nil {
+				config.progress(Progress{
+					OrganizationID: orgID,
+					Grant:          grant,
+					RoleKeys:       roleKeys,
+					Err:            err,
+					Done:           done,
+					Total:          total,
+				})
+			}
+			updateErr := err
+			if err = config.job.Update(ctx, func(progress *job.Progress) {
+				if updateErr != nil {
+					progress.Failed++
+					progress.LastError = updateErr.Error()
+				} else {
+					progress.Done++
+				}
+			}); err != nil {
+				return result, err
+			}
+		}
+		if err = config.job.Update(ctx, func(progress *job.Progress) { progress.Cursor = orgID }); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+func (g *Grants) managementService(orgID string) management.ManagementServiceClient {
+	return g.client.ForOrganization(orgID).ManagementService()
+}
+
+func (g *Grants) organizationIDs(ctx context.Context) ([]string, error) {
+	orgs, err := pager.ListAll(func(query *object.ListQuery) ([]*org.Org, *object.ListDetails, error) {
+		resp, err := g.client.AdminService().ListOrgs(ctx, &admin.ListOrgsRequest{Query: query})
+		return resp.GetResult(), resp.GetDetails(), err
+	})
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(orgs))
+	for i, org := range orgs {
+		ids[i] = org.GetId()
+	}
+	return ids, nil
+}
+
+func listGrants(ctx context.Context, mgmt management.ManagementServiceClient, role string, filter Filter) ([]*user.UserGrant, error) {
+	queries := []*user.UserGrantQuery{{
+		Query: &user.UserGrantQuery_RoleKeyQuery{
+			RoleKeyQuery: &user.UserGrantRoleKeyQuery{RoleKey: role, Method: object.TextQueryMethod_TEXT_QUERY_METHOD_EQUALS},
+		},
+	}}
+	if filter.ProjectID != "" {
+		queries = append(queries, &user.UserGrantQuery{
+			Query: &user.UserGrantQuery_ProjectIdQuery{
+				ProjectIdQuery: &user.UserGrantProjectIDQuery{ProjectId: filter.ProjectID},
+			},
+		})
+	}
+	all, err := pager.ListAll(func(query *object.ListQuery) ([]*user.UserGrant, *object.ListDetails, error) {
+		resp, err := mgmt.ListUserGrants(ctx, &management.ListUserGrantRequest{Query: query, Queries: queries})
+		return resp.GetResult(), resp.GetDetails(), err
+	})
+	if err != nil {
+		return nil, err
+	}
+	var grants []*user.UserGrant
+	for _, grant := range all {
+		if !slices.Contains(grant.GetRoleKeys(), role) {
+			continue
+		}
+		if len(filter.UserIDs) > 0 && !slices.Contains(filter.UserIDs, grant.GetUserId()) {
+			continue
+		}
+		grants = append(grants, grant)
+	}
+	return grants, nil
+}
+
+func reassignRoles(roleKeys []string, fromRole, toRole string, keepSource bool) []string {
+	reassigned := make([]string, 0, len(roleKeys)+1)
+	for _, key := range roleKeys {
+		if key == fromRole && !keepSource {
+			continue
+		}
+		reassigned = append(reassigned, key)
+	}
+	if !slices.Contains(reassigned, toRole) {
+		reassigned = append(reassigned, toRole)
+	}
+	return reassigned
+}
@@ -0,0 +1,181 @@
+package grants
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/management"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/object"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/user"
+)
+
+// testInstance serves the user grants of its organizations and records their updates.
+type testInstance struct {
+	grants  map[string][]*user.UserGrant
+	fail    map[string]bool
+	updates map[string][]string
+}
+
+func (i *testInstance) organizationIDs(context.Context) ([]string, error) {
+	return []string{"org1", "org2"}, nil
+}
+
+func (i *testInstance) managementService(orgID string) management.ManagementServiceClient {
+	return &testManagement{instance: i, orgID: orgID}
+}
+
+type testManagement struct {
+	management.ManagementServiceClient
+	instance *testInstance
+	orgID    string
+}
+
+func (m *testManagement) ListUserGrants(_ context.Context, req *management.ListUserGrantRequest, _ ...grpc.CallOption) (*management.ListUserGrantResponse, error) {
+	grants := m.instance.grants[m.orgID]
+	start := min(int(req.GetQuery().GetOffset()), len(grants))
+	end := min(start+int(req.GetQuery().GetLimit()), len(grants))
+	return &management.ListUserGrantResponse{
+		Result:  grants[start:end],
+		Details: &object.ListDetails{TotalResult: uint64(len(grants))},
+	}, nil
+}
+
+func (m *testManagement) UpdateUserGrant(_ context.Context, req *management.UpdateUserGrantRequest, _ ...grpc.CallOption) (*management.UpdateUserGrantResponse, error) {
+	if m.instance.fail[req.GetGrantId()] {
+		return nil, errors.New("update failed")
+	}
+	m.instance.updates[req.GetGrantId()] = req.GetRoleKeys()
+	return new(management.UpdateUserGrantResponse), nil
+}
+
+func TestBulkReassign(t *testing.T) {
+	grants := map[string][]*user.UserGrant{
+		"org1": {
+			{Id: "grant1", UserId: "user1", RoleKeys: []string{"admin", "viewer"}},
+			{Id: "grant2", UserId: "user2", RoleKeys: []string{"viewer"}},
+			{Id: "grant3", UserId: "user3", RoleKeys: []string{"admin"}},
+		},
+		"org2": {
+			{Id: "grant4", UserId: "user1", RoleKeys: []string{"admin", "owner"}},
+		},
+	}
+	tests := []struct {
+		name        string
+		filter      Filter
+		options     []ReassignOption
+		cursor      string
+		fail        map[string]bool
+		wantUpdates map[string][]string
+		want        *Result
+		wantReports int
+	}{
+		{
+			name: "replace source role",
+			wantUpdates: map[string][]string{
+				"grant1": {"viewer", "owner"},
+				"grant3": {"owner"},
+				"grant4": {"owner"},
+			},
+			want:        &Result{Updated: 3},
+			wantReports: 3,
+		},
+		{
+			name:    "keep source role",
+			options: []ReassignOption{WithKeepSourceRole()},
+			wantUpdates: map[string][]string{
+				"grant1": {"admin", "viewer", "owner"},
+				"grant3": {"admin", "owner"},
+				"grant4": {"admin", "owner"},
+			},
+			want:        &Result{Updated: 3},
+			wantReports: 3,
+		},
+		{
+			name:        "dry run",
+			options:     []ReassignOption{WithDryRun()},
+			wantUpdates: map[string][]string{},
+			want:        &Result{Updated: 3},
+			wantReports: 3,
+		},
+		{
+			name:   "filtered",
+			filter: Filter{OrganizationIDs: []string{"org1"}, UserIDs: []string{"user1"}},
+			wantUpdates: map[string][]string{
+				"grant1": {"viewer", "owner"},
+			},
+			want:        &Result{Updated: 1},
+			wantReports: 1,
+		},
+		{
+			name:   "after cursor",
+			cursor: "org1",
+			wantUpdates: map[string][]string{
+				"grant4": {"owner"},
+			},
+			want:        &Result{Updated: 1},
+			wantReports: 1,
+		},
+		{
+			name: "failure",
+			fail: map[string]bool{"grant3": true},
+			wantUpdates: map[string][]string{
+				"grant1": {"viewer", "owner"},
+				"grant4": {"owner"},
+			},
+			want: &Result{
+				Updated:  2,
+				Failures: []Failure{{OrganizationID: "org1", UserID: "user3", GrantID: "grant3", Err: errors.New("update failed")}},
+			},
+			wantReports: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			instance := &testInstance{grants: grants, fail: tt.fail, updates: make(map[string][]string)}
+			var reports []Progress
+			config := new(reassignConfig)
+			for _, option := range append(tt.options, WithProgress(func(p Progress) { reports = append(reports, p) })) {
+				option(config)
+			}
+			got, err := bulkReassign(context.Background(), instance, "admin", "owner", tt.filter, config, tt.cursor)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantUpdates, instance.updates)
+			require.Len(t, reports, tt.wantReports)
+			last := reports[len(reports)-1]
+			assert.Equal(t, tt.wantReports, last.Done)
+			assert.Equal(t, tt.wantReports, last.Total)
+		})
+	}
+}
+
+func TestGrants_BulkReassign_sameRole(t *testing.T) {
+	_, err := New(nil).BulkReassign(context.Background(), "admin", "admin", Filter{})
+	assert.ErrorIs(t, err, ErrSameRole)
+}
+
+func Test_reassignRoles(t *testing.T) {
+	tests := []struct {
+		name       string
+		roleKeys   []string
+		keepSource bool
+		want       []string
+	}{
+		{"replaced", []string{"admin", "viewer"}, false, []string{"viewer", "owner"}},
+		{"kept", []string{"admin", "viewer"}, true, []string{"admin", "viewer", "owner"}},
+		{"target already granted", []string{"admin", "owner"}, false, []string{"owner"}},
+		{"target already granted kept", []string{"owner", "admin"}, true, []string{"owner", "admin"}},
+		{"source not granted", []string{"viewer"}, false, []string{"viewer", "owner"}},
+		{"empty", nil, false, []string{"owner"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, reassignRoles(tt.roleKeys, "admin", "owner", tt.keepSource))
+		})
+	}
+}
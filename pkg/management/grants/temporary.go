@@ -0,0 +1,224 @@
+package grants
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/audit"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/management"
+	userV2 "github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/user/v2"
+)
+
+const (
+	// RecordTypeTemporaryGranted and RecordTypeTemporaryRevoked are the types of the [audit.Record]s of temporary grants.
+	RecordTypeTemporaryGranted = "user.grant.temporary.added"
+	RecordTypeTemporaryRevoked = "user.grant.temporary.revoked"
+
+	auditService = "zitadel-go"
+)
+
+var (
+	ErrNoExpiryQueue = errors.New("no expiry queue configured")
+	ErrExpiryInPast  = errors.New("expiry must be in the future")
+	ErrAuditFailed   = errors.New("audit record of temporary grant not written")
+)
+
+// Expiry is the scheduled revocation of a temporary user grant.
+type Expiry struct {
+	OrganizationID string    `json:"organizationId"`
+	UserID         string    `json:"userId"`
+	GrantID        string    `json:"grantId"`
+	ProjectID      string    `json:"projectId"`
+	Roles          []string  `json:"roles"`
+	Until          time.Time `json:"until"`
+}
+
+// ExpiryQueue stores the expiries of the temporary grants until they are revoked.
+// Use a persistent implementation (e.g. a database table), so the revocations survive restarts.
+type ExpiryQueue interface {
+	// Enqueue adds the expiry.
+	Enqueue(ctx context.Context, expiry *Expiry) error
+	// Due returns the expiries until the time.
+	Due(ctx context.Context, until time.Time) ([]*Expiry, error)
+	// Done removes the expiry after the revocation.
+	Done(ctx context.Context, expiry *Expiry) error
+}
+
+// MemoryQueue is an in-memory [ExpiryQueue], e.g. for tests.
+// The expiries are lost on restart, so the grants would not be revoked.
+type MemoryQueue struct {
+	mu       sync.Mutex
+	expiries []*Expiry
+}
+
+// NewMemoryQueue creates an empty [MemoryQueue].
+func NewMemoryQueue() *MemoryQueue {
+	return &MemoryQueue{}
+}
+
+// Enqueue implements [ExpiryQueue].
+func (q *MemoryQueue) Enqueue(_ context.Context, expiry *Expiry) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.expiries = append(q.expiries, expiry)
+	return nil
+}
+
+// Due implements [ExpiryQueue].
+func (q *MemoryQueue) Due(_ context.Context, until time.Time) ([]*Expiry, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var due []*Expiry
+	for _, expiry := range q.expiries {
+		if !expiry.Until.After(until) {
+			due = append(due, expiry)
+		}
+	}
+	return due, nil
+}
+
+// Done implements [ExpiryQueue].
+func (q *MemoryQueue) Done(_ context.Context, expiry *Expiry) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.expiries = slices.DeleteFunc(q.expiries, func(e *Expiry) bool {
+		return e.GrantID == expiry.GrantID && e.UserID == expiry.UserID
+	})
+	return nil
+}
+
+// Temporary grants the roles of the project to the user until the time.
+// The expiry is added to the [ExpiryQueue] (see [WithExpiryQueue]) and the grant is revoked by [Grants.RunExpiries].
+//
+// If the expiry cannot be enqueued, the grant is removed again, so no grant remains without expiry.
+func (g *Grants) Temporary(ctx context.Context, userID, projectID string, roles []string, until time.Time) (*Expiry, error) {
+	if g.queue == nil {
+		return nil, ErrNoExpiryQueue
+	}
+	if !until.After(time.Now()) {
+		return nil, ErrExpiryInPast
+	}
+	user, err := g.client.UserServiceV2().GetUserByID(ctx, &userV2.GetUserByIDRequest{UserId: userID})
+	if err != nil {
+		return nil, err
+	}
+	orgID := user.GetUser().GetDetails().GetResourceOwner()
+	mgmt := g.client.ForOrganization(orgID).ManagementService()
+	resp, err := mgmt.AddUserGrant(ctx, &management.AddUserGrantRequest{
+		UserId:    userID,
+		ProjectId: projectID,
+		RoleKeys:  roles,
+	})
+	if err != nil {
+		return nil, err
+	}
+	expiry := &Expiry{
+		OrganizationID: orgID,
+		UserID:         userID,
+		GrantID:        resp.GetUserGrantId(),
+		ProjectID:      projectID,
+		Roles:          roles,
+		Until:          until,
+	}
+	if err = g.queue.Enqueue(ctx, expiry); err != nil {
+		_, removeErr := mgmt.RemoveUserGrant(ctx, &management.RemoveUserGrantRequest{UserId: userID, GrantId: expiry.GrantID})
+		return nil, errors.Join(err, removeErr)
+	}
+	g.audit(ctx, RecordTypeTemporaryGranted, expiry)
+	return expiry, nil
+}
+
+// RunExpiries revokes the due temporary grants of the [ExpiryQueue] every interval until the context is done.
+// Grants which were already removed are considered as revoked.
+func (g *Grants) RunExpiries(ctx context.Context, interval time.Duration) error {
+	if g.queue == nil {
+		return ErrNoExpiryQueue
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := g.RevokeExpired(ctx); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// RevokeExpired revokes the due temporary grants of the [ExpiryQueue] once.
+// An error is only returned if the queue fails, failed revocations are passed to the error handler
+// (see [WithExpiryErrorHandler]) and retried on the next call.
+func (g *Grants) RevokeExpired(ctx context.Context) error {
+	if g.queue == nil {
+		return ErrNoExpiryQueue
+	}
+	due, err := g.queue.Due(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+	for _, expiry := range due {
+		if err = g.revoke(ctx, expiry); err != nil {
+			if g.onError != nil {
+				g.onError(expiry, err)
+			}
+			continue
+		}
+		if err = g.queue.Done(ctx, expiry); err != nil {
+			return err
+		}
+		g.audit(ctx, RecordTypeTemporaryRevoked, expiry)
+	}
+	return nil
+}
+
+func (g *Grants) revoke(ctx context.Context, expiry *Expiry) error {
+	_, err := g.client.ForOrganization(expiry.OrganizationID).ManagementService().RemoveUserGrant(ctx, &management.RemoveUserGrantRequest{
+		UserId:  expiry.UserID,
+		GrantId: expiry.GrantID,
+	})
+	if status.Code(err) == codes.NotFound {
+		return nil
+	}
+	return err
+}
+
+func (g *Grants) audit(ctx context.Context, recordType string, expiry *Expiry) {
+	if len(g.sinks) == 0 {
+		return
+	}
+	record := temporaryRecord(recordType, expiry, time.Now())
+	for _, sink := range g.sinks {
+		// a failing sink does not roll back the grant, resp. the revocation
+		if err := sink.Write(ctx, []*audit.Record{record}); err != nil && g.onError != nil {
+			g.onError(expiry, fmt.Errorf("%w: %w", ErrAuditFailed, err))
+		}
+	}
+}
+
+func temporaryRecord(recordType string, expiry *Expiry, now time.Time) *audit.Record {
+	return &audit.Record{
+		ID:            recordType + ":" + expiry.GrantID,
+		Time:          now,
+		Type:          recordType,
+		AggregateType: "user",
+		AggregateID:   expiry.UserID,
+		ResourceOwner: expiry.OrganizationID,
+		Actor:         audit.Actor{Service: auditService},
+		Payload: map[string]any{
+			"grantId":   expiry.GrantID,
+			"projectId": expiry.ProjectID,
+			"roleKeys":  expiry.Roles,
+			"until":     expiry.Until.Format(time.RFC3339),
+		},
+	}
+}
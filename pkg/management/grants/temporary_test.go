@@ -0,0 +1,45 @@
+package grants
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryQueue(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	q := NewMemoryQueue()
+	expired := &Expiry{UserID: "user1", GrantID: "grant1", Until: now.Add(-time.Minute)}
+	pending := &Expiry{UserID: "user2", GrantID: "grant2", Until: now.Add(time.Hour)}
+	require.NoError(t, q.Enqueue(ctx, expired))
+	require.NoError(t, q.Enqueue(ctx, pending))
+
+	due, err := q.Due(ctx, now)
+	require.NoError(t, err)
+	assert.Equal(t, []*Expiry{expired}, due)
+
+	require.NoError(t, q.Done(ctx, expired))
+	due, err = q.Due(ctx, now.Add(2*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, []*Expiry{pending}, due)
+}
+
+func Test_temporaryRecord(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	record := temporaryRecord(RecordTypeTemporaryRevoked, &Expiry{
+		OrganizationID: "org",
+		UserID:         "user",
+		GrantID:        "grant",
+		ProjectID:      "project",
+		Roles:          []string{"admin"},
+		Until:          now,
+	}, now)
+	assert.Equal(t, "user.grant.temporary.revoked:grant", record.ID)
+	assert.Equal(t, "user", record.AggregateID)
+	assert.Equal(t, "org", record.ResourceOwner)
+	assert.Equal(t, "2024-01-01T12:00:00Z", record.Payload["until"])
+}
@@ -0,0 +1,184 @@
+// Package machinekeys manages the keys of machine users (service accounts) used for the JWT profile authentication
+// ([client.JWTAuthentication]), including a rotation with a grace period, during which the old and new keys are valid.
+package machinekeys
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	oidcclient "github.com/zitadel/oidc/v3/pkg/client"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/authn"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/management"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/object"
+	"github.com/zitadel/zitadel-go/v3/pkg/internal/pager"
+)
+
+var ErrMissingKeyDetails = errors.New("no key details returned")
+
+// Key is a key of a machine user.
+type Key struct {
+	ID        string
+	CreatedAt time.Time
+	// ExpiresAt is the expiry of the key, zero if the key does not expire.
+	ExpiresAt time.Time
+}
+
+// MachineKeys provides the key management of machine users.
+type MachineKeys struct {
+	client *client.Client
+}
+
+// New creates [MachineKeys] using the client.
+// The client needs the permission to manage the machine users, the organization context is set using [client.Client.ForOrganization].
+func New(client *client.Client) *MachineKeys {
+	return &MachineKeys{client: client}
+}
+
+// KeyOption allows customization of the created key.
+type KeyOption func(*management.AddMachineKeyRequest)
+
+// WithExpiration sets the expiry of the created key.
+func WithExpiration(expiresAt time.Time) KeyOption {
+	return func(req *management.AddMachineKeyRequest) {
+		req.ExpirationDate = timestamppb.New(expiresAt)
+	}
+}
+
+// ListKeys returns all keys of the machine user.
+func (m *MachineKeys) ListKeys(ctx context.Context, userID string) ([]*Key, error) {
+	result, err := pager.ListAll(func(query *object.ListQuery) ([]*authn.Key, *object.ListDetails, error) {
+		resp, err := m.client.ManagementService().ListMachineKeys(ctx, &management.ListMachineKeysRequest{UserId: userID, Query: query})
+		return resp.GetResult(), resp.GetDetails(), err
+	})
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]*Key, len(result))
+	for i, key := range result {
+		keys[i] = keyFromProto(key)
+	}
+	return keys, nil
+}
+
+// AddKey creates a new key for the machine user and returns it in the key.json format of ZITADEL,
+// which can be written to a file or parsed using [ParseKeyFile].
+func (m *MachineKeys) AddKey(ctx context.Context, userID string, opts ...KeyOption) (keyID string, keyJSON []byte, err error) {
+	req := &management.AddMachineKeyRequest{UserId: userID, Type: authn.KeyType_KEY_TYPE_JSON}
+	for _, opt := range opts {
+		opt(req)
+	}
+	resp, err := m.client.ManagementService().AddMachineKey(ctx, req)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(resp.GetKeyDetails()) == 0 {
+		return "", nil, ErrMissingKeyDetails
+	}
+	return resp.GetKeyId(), resp.GetKeyDetails(), nil
+}
+
+// RemoveKey removes the key of the machine user. Nothing is done if the key does not exist (anymore).
+func (m *MachineKeys) RemoveKey(ctx context.Context, userID, keyID string) error {
+	_, err := m.client.ManagementService().RemoveMachineKey(ctx, &management.RemoveMachineKeyRequest{UserId: userID, KeyId: keyID})
+	if status.Code(err) == codes.NotFound {
+		return nil
+	}
+	return err
+}
+
+// Rotation is the result of [MachineKeys.RotateKey].
+type Rotation struct {
+	UserID string
+	// KeyID and KeyJSON are the new key in the key.json format accepted by [client.JWTAuthentication] (see [ParseKeyFile]).
+	KeyID   string
+	KeyJSON []byte
+	// OldKeyIDs are the keys to be removed after the grace period.
+	OldKeyIDs []string
+	// RemoveAfter is the end of the grace period.
+	RemoveAfter time.Time
+
+	keys *MachineKeys
+}
+
+// RotateKey creates a new key for the machine user. The existing keys remain valid for the grace period,
+// so the new key can be rolled out to all consumers first.
+// They are removed by [Rotation.Wait] after the grace period or immediately by [Rotation.RemoveOldKeys].
+// If the grace period is zero, they are removed before RotateKey returns.
+func (m *MachineKeys) RotateKey(ctx context.Context, userID string, gracePeriod time.Duration, opts ...KeyOption) (*Rotation, error) {
+	old, err := m.ListKeys(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	keyID, keyJSON, err := m.AddKey(ctx, userID, opts...)
+	if err != nil {
+		return nil, err
+	}
+	rotation := &Rotation{
+		UserID:      userID,
+		KeyID:       keyID,
+		KeyJSON:     keyJSON,
+		RemoveAfter: time.Now().Add(gracePeriod),
+		keys:        m,
+	}
+	for _, key := range old {
+		rotation.OldKeyIDs = append(rotation.OldKeyIDs, key.ID)
+	}
+	if gracePeriod <= 0 {
+		return rotation, rotation.RemoveOldKeys(ctx)
+	}
+	return rotation, nil
+}
+
+// KeyFile parses the new key for the use with [client.JWTAuthentication].
+func (r *Rotation) KeyFile() (*oidcclient.KeyFile, error) {
+	return ParseKeyFile(r.KeyJSON)
+}
+
+// Wait blocks until the end of the grace period and removes the old keys then.
+// If the context is done before, the old keys are kept and the error of the context is returned.
+func (r *Rotation) Wait(ctx context.Context) error {
+	timer := time.NewTimer(time.Until(r.RemoveAfter))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return r.RemoveOldKeys(ctx)
+	}
+}
+
+// RemoveOldKeys removes the old keys immediately.
+func (r *Rotation) RemoveOldKeys(ctx context.Context) error {
+	var errs []error
+	for _, keyID := range r.OldKeyIDs {
+		errs = append(errs, r.keys.RemoveKey(ctx, r.UserID, keyID))
+	}
+	return errors.Join(errs...)
+}
+
+// ParseKeyFile parses the key.json of a machine user.
+func ParseKeyFile(keyJSON []byte) (*oidcclient.KeyFile, error) {
+	file := new(oidcclient.KeyFile)
+	if err := json.Unmarshal(keyJSON, file); err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+func keyFromProto(key *authn.Key) *Key {
+	k := &Key{
+		ID:        key.GetId(),
+		CreatedAt: key.GetDetails().GetCreationDate().AsTime(),
+	}
+	if key.GetExpirationDate() != nil {
+		k.ExpiresAt = key.GetExpirationDate().AsTime()
+	}
+	return k
+}
@@ -0,0 +1,224 @@
+// Package metadata provides a compare-and-swap for the metadata of users,
+// so concurrent writers (e.g. multiple replicas of an application) detect each other's updates.
+//
+// ZITADEL has no conditional writes, so [Metadata.CAS] is not atomic: it reads, compares and writes the value
+// and detects afterwards using the sequences of the events of the user, whether another writer changed the key
+// between the read and the write. If another writer did, its value is restored (compensated) and [ErrConflict] returned.
+// Until then, other readers might see the overwritten value. The restore is skipped if the key was changed again
+// after the write, but a writer changing the key between the detection and the restore is overwritten by it.
+// Use it to reduce lost updates of rarely contended keys, not as a lock.
+//
+// The typed helpers (e.g. [GetTyped] and [SetTyped]) encode Go values as JSON for the metadata of users
+// and organizations, strings and byte slices are stored as they are, so they are compatible with values
+// set by the console or other applications:
+//
+//	err := metadata.SetTyped(ctx, m, userID, "preferences", Preferences{Theme: "dark"})
+//	prefs, err := metadata.GetTyped[Preferences](ctx, m, userID, "preferences")
+package metadata
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/admin"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/event"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/management"
+)
+
+const (
+	// DefaultMaxAttempts is the number of attempts of a compare-and-swap.
+	DefaultMaxAttempts = 3
+
+	eventMetadataSet        = "user.metadata.set"
+	eventMetadataRemoved    = "user.metadata.removed"
+	eventMetadataRemovedAll = "user.metadata.removed.all"
+	eventsLimit             = 1000
+)
+
+var (
+	// ErrMismatch is returned if the current value differs from the expected one.
+	ErrMismatch = errors.New("metadata value does not match the expected value")
+	// ErrConflict is returned if another writer changed the key concurrently, its value is restored.
+	ErrConflict = errors.New("metadata was changed concurrently")
+)
+
+// Metadata provides the compare-and-swap of the metadata of users.
+type Metadata struct {
+	client      *client.Client
+	maxAttempts int
+}
+
+// Option allows customization of the [Metadata].
+type Option func(*Metadata)
+
+// WithMaxAttempts sets the number of attempts (default 3).
+// An attempt is repeated if another writer changed the key concurrently, but its value still matches the expected one.
+func WithMaxAttempts(attempts int) Option {
+	return func(m *Metadata) {
+		m.maxAttempts = attempts
+	}
+}
+
+// New creates [Metadata] using the client.
+// Besides the permissions for the metadata of the users, the client needs to be allowed to read the events
+// of the instance (e.g. IAM_OWNER_VIEWER) for the verification.
+func New(client *client.Client, opts ...Option) *Metadata {
+	m := &Metadata{client: client, maxAttempts: DefaultMaxAttempts}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// CAS sets the metadata key of the user to the new value, if its current value equals the expected one.
+// A nil expected value requires the key to be absent, a nil new value removes the key.
+// [ErrMismatch] is returned if the value differs and [ErrConflict] if another writer changed the key concurrently,
+// in which case the change was compensated as described in the package doc.
+func (m *Metadata) CAS(ctx context.Context, userID, key string, expected, new []byte) error {
+	var err error
+	for range max(m.maxAttempts, 1) {
+		var retry bool
+		retry, err = cas(ctx, m, userID, key, expected, new)
+		if !retry {
+			return err
+		}
+	}
+	return err
+}
+
+// casStore reads and writes the metadata of users and lists their changes.
+type casStore interface {
+	sequence(ctx context.Context, userID string) (uint64, error)
+	get(ctx context.Context, userID, key string) ([]byte, error)
+	set(ctx context.Context, userID, key string, value []byte) (uint64, error)
+	events(ctx context.Context, userID, key string, after uint64) ([]*event.Event, error)
+}
+
+// cas returns if the compare-and-swap can be retried.
+func cas(ctx context.Context, s casStore, userID, key string, expected, new []byte) (bool, error) {
+	// the sequence of the user is read before the value, so any later change of the value has a higher sequence
+	before, err := s.sequence(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	current, err := s.get(ctx, userID, key)
+	if err != nil {
+		return false, err
+	}
+	if !equal(current, expected) {
+		return false, ErrMismatch
+	}
+	written, err := s.set(ctx, userID, key, new)
+	if err != nil {
+		return false, err
+	}
+	events, err := s.events(ctx, userID, key, before)
+	if err != nil {
+		return false, fmt.Errorf("unable to verify the metadata change: %w", err)
+	}
+	var concurrent, later []*event.Event
+	for _, e := range events {
+		switch {
+		case e.GetSequence() < written:
+			concurrent = append(concurrent, e)
+		case e.GetSequence() > written:
+			later = append(later, e)
+		}
+	}
+	if len(concurrent) == 0 {
+		return false, nil
+	}
+	// the value of the other writer was overwritten, it's restored unless the key was changed again in the meantime
+	value, err := eventValue(concurrent[len(concurrent)-1])
+	if err != nil {
+		return false, err
+	}
+	if len(later) == 0 {
+		if _, err = s.set(ctx, userID, key, value); err != nil {
+			return false, errors.Join(ErrConflict, fmt.Errorf("unable to restore the concurrent change: %w", err))
+		}
+	}
+	return equal(value, expected), ErrConflict
+}
+
+// sequence returns the sequence of the last change of the user.
+func (m *Metadata) sequence(ctx context.Context, userID string) (uint64, error) {
+	resp, err := m.client.ManagementService().GetUserByID(ctx, &management.GetUserByIDRequest{Id: userID})
+	return resp.GetUser().GetDetails().GetSequence(), err
+}
+
+// get returns the value of the key, nil if it does not exist.
+func (m *Metadata) get(ctx context.Context, userID, key string) ([]byte, error) {
+	resp, err := m.client.ManagementService().GetUserMetadata(ctx, &management.GetUserMetadataRequest{Id: userID, Key: key})
+	if status.Code(err) == codes.NotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return resp.GetMetadata().GetValue(), nil
+}
+
+// set sets the value of the key (removes it for nil) and returns the sequence of the change.
+func (m *Metadata) set(ctx context.Context, userID, key string, value []byte) (uint64, error) {
+	mgmt := m.client.ManagementService()
+	if value == nil {
+		resp, err := mgmt.RemoveUserMetadata(ctx, &management.RemoveUserMetadataRequest{Id: userID, Key: key})
+		return resp.GetDetails().GetSequence(), err
+	}
+	resp, err := mgmt.SetUserMetadata(ctx, &management.SetUserMetadataRequest{Id: userID, Key: key, Value: value})
+	return resp.GetDetails().GetSequence(), err
+}
+
+// events returns the changes of the key of the user after the sequence.
+func (m *Metadata) events(ctx context.Context, userID, key string, after uint64) ([]*event.Event, error) {
+	var events []*event.Event
+	for {
+		resp, err := m.client.AdminService().ListEvents(ctx, &admin.ListEventsRequest{
+			Sequence:       after,
+			Limit:          eventsLimit,
+			Asc:            true,
+			AggregateId:    userID,
+			AggregateTypes: []string{"user"},
+			EventTypes:     []string{eventMetadataSet, eventMetadataRemoved, eventMetadataRemovedAll},
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range resp.GetEvents() {
+			if e.GetSequence() <= after {
+				continue
+			}
+			after = e.GetSequence()
+			if e.GetType().GetType() == eventMetadataRemovedAll || e.GetPayload().GetFields()["key"].GetStringValue() == key {
+				events = append(events, e)
+			}
+		}
+		if len(resp.GetEvents()) < eventsLimit {
+			return events, nil
+		}
+	}
+}
+
+// eventValue returns the value set by the event, nil for removals.
+func eventValue(e *event.Event) ([]byte, error) {
+	if e.GetType().GetType() != eventMetadataSet {
+		return nil, nil
+	}
+	// the value is stored as bytes in the event, which is encoded as base64 in the JSON payload
+	return base64.StdEncoding.DecodeString(e.GetPayload().GetFields()["value"].GetStringValue())
+}
+
+func equal(a, b []byte) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return bytes.Equal(a, b)
+}
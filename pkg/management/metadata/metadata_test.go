@@ -0,0 +1,227 @@
+package metadata
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/event"
+)
+
+func Test_eventValue(t *testing.T) {
+	tests := []struct {
+		name    string
+		event   *event.Event
+		want    []byte
+		wantErr bool
+	}{
+		{
+			name:  "set",
+			event: metadataEvent(t, eventMetadataSet, "key", base64.StdEncoding.EncodeToString([]byte("value"))),
+			want:  []byte("value"),
+		},
+		{
+			name:  "removed",
+			event: metadataEvent(t, eventMetadataRemoved, "key", ""),
+			want:  nil,
+		},
+		{
+			name:  "removed all",
+			event: metadataEvent(t, eventMetadataRemovedAll, "", ""),
+			want:  nil,
+		},
+		{
+			name:    "invalid value",
+			event:   metadataEvent(t, eventMetadataSet, "key", "%%%"),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := eventValue(tt.event)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_equal(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []byte
+		want bool
+	}{
+		{"both absent", nil, nil, true},
+		{"absent and empty", nil, []byte{}, false},
+		{"equal", []byte("a"), []byte("a"), true},
+		{"different", []byte("a"), []byte("b"), false},
+		{"absent and value", nil, []byte("a"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, equal(tt.a, tt.b))
+		})
+	}
+}
+
+func metadataEvent(t *testing.T, typ, key, value string) *event.Event {
+	fields := map[string]any{}
+	if key != "" {
+		fields["key"] = key
+	}
+	if value != "" {
+		fields["value"] = value
+	}
+	payload, err := structpb.NewStruct(fields)
+	require.NoError(t, err)
+	return &event.Event{Type: &event.EventType{Type: typ}, Payload: payload}
+}
+
+// memoryUser is a [casStore] of a single user, which allows other writers to interleave
+// before the n-th write or before the changes are listed.
+type memoryUser struct {
+	t        *testing.T
+	seq      uint64
+	values   map[string][]byte
+	log      []*event.Event
+	sets     int
+	onSet    map[int]func()
+	onEvents func()
+}
+
+func (u *memoryUser) sequence(context.Context, string) (uint64, error) {
+	return u.seq, nil
+}
+
+func (u *memoryUser) get(_ context.Context, _, key string) ([]byte, error) {
+	return u.values[key], nil
+}
+
+func (u *memoryUser) set(_ context.Context, _, key string, value []byte) (uint64, error) {
+	u.sets++
+	if hook := u.onSet[u.sets]; hook != nil {
+		hook()
+	}
+	return u.write(key, value), nil
+}
+
+// write changes the key (e.g. by another writer) and returns the sequence of the change.
+func (u *memoryUser) write(key string, value []byte) uint64 {
+	u.seq++
+	e := metadataEvent(u.t, eventMetadataRemoved, key, "")
+	delete(u.values, key)
+	if value != nil {
+		e = metadataEvent(u.t, eventMetadataSet, key, base64.StdEncoding.EncodeToString(value))
+		u.values[key] = value
+	}
+	e.Sequence = u.seq
+	u.log = append(u.log, e)
+	return u.seq
+}
+
+func (u *memoryUser) events(_ context.Context, _, key string, after uint64) ([]*event.Event, error) {
+	if u.onEvents != nil {
+		u.onEvents()
+		u.onEvents = nil
+	}
+	var events []*event.Event
+	for _, e := range u.log {
+		if e.GetSequence() > after && e.GetPayload().GetFields()["key"].GetStringValue() == key {
+			events = append(events, e)
+		}
+	}
+	return events, nil
+}
+
+func Test_cas(t *testing.T) {
+	tests := []struct {
+		name      string
+		expected  []byte
+		setup     func(u *memoryUser)
+		wantRetry bool
+		wantErr   error
+		want      []byte
+	}{
+		{
+			name:     "no concurrent writer",
+			expected: []byte("initial"),
+			want:     []byte("a"),
+		},
+		{
+			name:     "mismatch",
+			expected: []byte("other"),
+			wantErr:  ErrMismatch,
+			want:     []byte("initial"),
+		},
+		{
+			name:     "concurrent writer is restored",
+			expected: []byte("initial"),
+			setup: func(u *memoryUser) {
+				u.onSet = map[int]func(){1: func() { u.write("key", []byte("b")) }}
+			},
+			wantErr: ErrConflict,
+			want:    []byte("b"),
+		},
+		{
+			name:     "concurrent writer of expected value is retried",
+			expected: []byte("initial"),
+			setup: func(u *memoryUser) {
+				u.onSet = map[int]func(){1: func() { u.write("key", []byte("initial")) }}
+			},
+			wantRetry: true,
+			wantErr:   ErrConflict,
+			want:      []byte("initial"),
+		},
+		{
+			// writer b changes the key between the read and the write, c after the write:
+			// b is detected, but not restored since it would overwrite c
+			name:     "third writer after the write is kept",
+			expected: []byte("initial"),
+			setup: func(u *memoryUser) {
+				u.onSet = map[int]func(){1: func() { u.write("key", []byte("b")) }}
+				u.onEvents = func() { u.write("key", []byte("c")) }
+			},
+			wantErr: ErrConflict,
+			want:    []byte("c"),
+		},
+		{
+			// writer c changes the key after the changes were listed, but before b is restored:
+			// the compensation overwrites c, which is the documented limitation
+			name:     "third writer before the restore is overwritten",
+			expected: []byte("initial"),
+			setup: func(u *memoryUser) {
+				u.onSet = map[int]func(){
+					1: func() { u.write("key", []byte("b")) },
+					2: func() { u.write("key", []byte("c")) },
+				}
+			},
+			wantErr: ErrConflict,
+			want:    []byte("b"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := &memoryUser{t: t, values: map[string][]byte{}}
+			u.write("key", []byte("initial"))
+			if tt.setup != nil {
+				tt.setup(u)
+			}
+			retry, err := cas(context.Background(), u, "user", "key", tt.expected, []byte("a"))
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantRetry, retry)
+			assert.Equal(t, tt.want, u.values["key"])
+		})
+	}
+}
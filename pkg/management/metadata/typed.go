@@ -0,0 +1,220 @@
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/management"
+	metadataPb "github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/metadata"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/object"
+)
+
+// listLimit is the number of metadata entries requested per list call.
+const listLimit = 100
+
+// ErrNotFound is returned by [GetTyped] and [GetOrgTyped] if the key does not exist.
+var ErrNotFound = errors.New("metadata key not found")
+
+// GetTyped returns the value of the metadata key of the user decoded into T, [ErrNotFound] if the key does not exist:
+//
+//	prefs, err := metadata.GetTyped[Preferences](ctx, m, userID, "preferences")
+func GetTyped[T any](ctx context.Context, m *Metadata, userID, key string) (T, error) {
+	return getTyped[T](ctx, m.userStore(userID), key)
+}
+
+// SetTyped sets the metadata key of the user to the encoded value.
+func SetTyped[T any](ctx context.Context, m *Metadata, userID, key string, value T) error {
+	return setTyped(ctx, m.userStore(userID), map[string]T{key: value})
+}
+
+// GetTypedBulk returns the values of the metadata keys of the user decoded into T, all keys if none are passed.
+// Keys which don't exist are not part of the result.
+func GetTypedBulk[T any](ctx context.Context, m *Metadata, userID string, keys ...string) (map[string]T, error) {
+	return getTypedBulk[T](ctx, m.userStore(userID), keys)
+}
+
+// SetTypedBulk sets the metadata keys of the user to the encoded values in one call.
+func SetTypedBulk[T any](ctx context.Context, m *Metadata, userID string, values map[string]T) error {
+	return setTyped(ctx, m.userStore(userID), values)
+}
+
+// GetOrgTyped returns the value of the metadata key of the organization decoded into T,
+// [ErrNotFound] if the key does not exist.
+func GetOrgTyped[T any](ctx context.Context, m *Metadata, orgID, key string) (T, error) {
+	return getTyped[T](ctx, m.orgStore(orgID), key)
+}
+
+// SetOrgTyped sets the metadata key of the organization to the encoded value.
+func SetOrgTyped[T any](ctx context.Context, m *Metadata, orgID, key string, value T) error {
+	return setTyped(ctx, m.orgStore(orgID), map[string]T{key: value})
+}
+
+// GetOrgTypedBulk returns the values of the metadata keys of the organization decoded into T,
+// all keys if none are passed. Keys which don't exist are not part of the result.
+func GetOrgTypedBulk[T any](ctx context.Context, m *Metadata, orgID string, keys ...string) (map[string]T, error) {
+	return getTypedBulk[T](ctx, m.orgStore(orgID), keys)
+}
+
+// SetOrgTypedBulk sets the metadata keys of the organization to the encoded values in one call.
+func SetOrgTypedBulk[T any](ctx context.Context, m *Metadata, orgID string, values map[string]T) error {
+	return setTyped(ctx, m.orgStore(orgID), values)
+}
+
+// store reads and writes the metadata of a user or an organization.
+type store interface {
+	get(ctx context.Context, key string) ([]byte, error)
+	list(ctx context.Context, query *object.ListQuery) ([]*metadataPb.Metadata, *object.ListDetails, error)
+	bulkSet(ctx context.Context, entries []entry) error
+}
+
+type entry struct {
+	key   string
+	value []byte
+}
+
+func getTyped[T any](ctx context.Context, s store, key string) (T, error) {
+	var value T
+	data, err := s.get(ctx, key)
+	if status.Code(err) == codes.NotFound {
+		return value, ErrNotFound
+	}
+	if err != nil {
+		return value, err
+	}
+	err = decode(data, &value)
+	return value, err
+}
+
+func getTypedBulk[T any](ctx context.Context, s store, keys []string) (map[string]T, error) {
+	wanted := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		wanted[key] = true
+	}
+	values := make(map[string]T)
+	var offset uint64
+	for {
+		entries, details, err := s.list(ctx, &object.ListQuery{Offset: offset, Limit: listLimit, Asc: true})
+		if err != nil {
+			return nil, err
+		}
+		offset += uint64(len(entries))
+		for _, md := range entries {
+			if len(wanted) > 0 && !wanted[md.GetKey()] {
+				continue
+			}
+			var value T
+			if err = decode(md.GetValue(), &value); err != nil {
+				return nil, fmt.Errorf("metadata %s: %w", md.GetKey(), err)
+			}
+			values[md.GetKey()] = value
+		}
+		if len(entries) == 0 || offset >= details.GetTotalResult() {
+			return values, nil
+		}
+	}
+}
+
+func setTyped[T any](ctx context.Context, s store, values map[string]T) error {
+	if len(values) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	encoded := make([]entry, 0, len(values))
+	for _, key := range keys {
+		data, err := encode(values[key])
+		if err != nil {
+			return fmt.Errorf("metadata %s: %w", key, err)
+		}
+		encoded = append(encoded, entry{key: key, value: data})
+	}
+	return s.bulkSet(ctx, encoded)
+}
+
+func encode(value any) ([]byte, error) {
+	switch v := value.(type) {
+	case string:
+		return []byte(v), nil
+	case []byte:
+		return v, nil
+	default:
+		return json.Marshal(value)
+	}
+}
+
+func decode(data []byte, value any) error {
+	switch v := value.(type) {
+	case *string:
+		*v = string(data)
+		return nil
+	case *[]byte:
+		*v = data
+		return nil
+	default:
+		return json.Unmarshal(data, value)
+	}
+}
+
+func (m *Metadata) userStore(userID string) store {
+	return &userStore{mgmt: m.client.ManagementService(), userID: userID}
+}
+
+func (m *Metadata) orgStore(orgID string) store {
+	return &orgStore{mgmt: m.client.ForOrganization(orgID).ManagementService()}
+}
+
+type userStore struct {
+	mgmt   management.ManagementServiceClient
+	userID string
+}
+
+func (s *userStore) get(ctx context.Context, key string) ([]byte, error) {
+	resp, err := s.mgmt.GetUserMetadata(ctx, &management.GetUserMetadataRequest{Id: s.userID, Key: key})
+	return resp.GetMetadata().GetValue(), err
+}
+
+func (s *userStore) list(ctx context.Context, query *object.ListQuery) ([]*metadataPb.Metadata, *object.ListDetails, error) {
+	resp, err := s.mgmt.ListUserMetadata(ctx, &management.ListUserMetadataRequest{Id: s.userID, Query: query})
+	return resp.GetResult(), resp.GetDetails(), err
+}
+
+func (s *userStore) bulkSet(ctx context.Context, entries []entry) error {
+	req := &management.BulkSetUserMetadataRequest{Id: s.userID}
+	for _, e := range entries {
+		req.Metadata = append(req.Metadata, &management.BulkSetUserMetadataRequest_Metadata{Key: e.key, Value: e.value})
+	}
+	_, err := s.mgmt.BulkSetUserMetadata(ctx, req)
+	return err
+}
+
+type orgStore struct {
+	mgmt management.ManagementServiceClient
+}
+
+func (s *orgStore) get(ctx context.Context, key string) ([]byte, error) {
+	resp, err := s.mgmt.GetOrgMetadata(ctx, &management.GetOrgMetadataRequest{Key: key})
+	return resp.GetMetadata().GetValue(), err
+}
+
+func (s *orgStore) list(ctx context.Context, query *object.ListQuery) ([]*metadataPb.Metadata, *object.ListDetails, error) {
+	resp, err := s.mgmt.ListOrgMetadata(ctx, &management.ListOrgMetadataRequest{Query: query})
+	return resp.GetResult(), resp.GetDetails(), err
+}
+
+func (s *orgStore) bulkSet(ctx context.Context, entries []entry) error {
+	req := new(management.BulkSetOrgMetadataRequest)
+	for _, e := range entries {
+		req.Metadata = append(req.Metadata, &management.BulkSetOrgMetadataRequest_Metadata{Key: e.key, Value: e.value})
+	}
+	_, err := s.mgmt.BulkSetOrgMetadata(ctx, req)
+	return err
+}
@@ -0,0 +1,90 @@
+package metadata
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	metadataPb "github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/metadata"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/object"
+)
+
+type memoryStore struct {
+	values map[string][]byte
+	order  []string
+}
+
+func (s *memoryStore) get(_ context.Context, key string) ([]byte, error) {
+	value, ok := s.values[key]
+	if !ok {
+		return nil, status.Error(codes.NotFound, "not found")
+	}
+	return value, nil
+}
+
+func (s *memoryStore) list(_ context.Context, query *object.ListQuery) ([]*metadataPb.Metadata, *object.ListDetails, error) {
+	var result []*metadataPb.Metadata
+	for i, key := range s.order {
+		if uint64(i) >= query.GetOffset() && uint64(len(result)) < uint64(query.GetLimit()) {
+			result = append(result, &metadataPb.Metadata{Key: key, Value: s.values[key]})
+		}
+	}
+	return result, &object.ListDetails{TotalResult: uint64(len(s.order))}, nil
+}
+
+func (s *memoryStore) bulkSet(_ context.Context, entries []entry) error {
+	for _, e := range entries {
+		if _, ok := s.values[e.key]; !ok {
+			s.order = append(s.order, e.key)
+		}
+		s.values[e.key] = e.value
+	}
+	return nil
+}
+
+type preferences struct {
+	Theme    string `json:"theme"`
+	Language string `json:"language,omitempty"`
+}
+
+func TestTyped(t *testing.T) {
+	ctx := context.Background()
+	s := &memoryStore{values: map[string][]byte{"plan": []byte("enterprise")}, order: []string{"plan"}}
+
+	require.NoError(t, setTyped(ctx, s, map[string]preferences{
+		"preferences": {Theme: "dark"},
+		"defaults":    {Theme: "light", Language: "de"},
+	}))
+	assert.Equal(t, `{"theme":"dark"}`, string(s.values["preferences"]))
+	assert.Equal(t, []string{"plan", "defaults", "preferences"}, s.order)
+
+	prefs, err := getTyped[preferences](ctx, s, "preferences")
+	require.NoError(t, err)
+	assert.Equal(t, preferences{Theme: "dark"}, prefs)
+
+	plan, err := getTyped[string](ctx, s, "plan")
+	require.NoError(t, err)
+	assert.Equal(t, "enterprise", plan)
+
+	_, err = getTyped[preferences](ctx, s, "missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, err = getTyped[preferences](ctx, s, "plan")
+	assert.Error(t, err)
+
+	bulk, err := getTypedBulk[preferences](ctx, s, []string{"preferences", "defaults", "missing"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]preferences{
+		"preferences": {Theme: "dark"},
+		"defaults":    {Theme: "light", Language: "de"},
+	}, bulk)
+
+	all, err := getTypedBulk[[]byte](ctx, s, nil)
+	require.NoError(t, err)
+	assert.Len(t, all, 3)
+	assert.Equal(t, []byte("enterprise"), all["plan"])
+}
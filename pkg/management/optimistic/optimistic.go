@@ -0,0 +1,113 @@
+// Package optimistic provides optimistic concurrency control for the resources of ZITADEL
+// using the sequence of their object details.
+//
+// ZITADEL has no conditional writes, so a concurrent modification is detected after the write:
+// the sequence of an aggregate is increased by every change, so if the sequence returned by the write
+// is more than one higher than the one read, another change happened in between.
+// The [Updater] then reads the resource again and resolves the conflict using the merge function:
+//
+//	u := optimistic.Updater[*management.GetProjectByIDResponse]{
+//		Read: func(ctx context.Context) (*management.GetProjectByIDResponse, optimistic.Details, error) {
+//			resp, err := c.ManagementService().GetProjectByID(ctx, &management.GetProjectByIDRequest{Id: projectID})
+//			return resp, resp.GetProject().GetDetails(), err
+//		},
+//		Write: ...,
+//		Merge: ...,
+//	}
+//	project, err := u.Update(ctx, func(project *management.GetProjectByIDResponse) (*management.GetProjectByIDResponse, error) {
+//		...
+//	})
+package optimistic
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// DefaultMaxAttempts is the number of writes of an update, including the ones of merges.
+const DefaultMaxAttempts = 3
+
+// ErrConflict is returned if the resource was modified concurrently and the conflict could not be resolved.
+var ErrConflict = errors.New("resource was modified concurrently")
+
+// Details are the object details returned by the reads and writes of ZITADEL,
+// e.g. the ObjectDetails of the v1 or the Details of the v2 API. They might be nil.
+type Details interface {
+	GetSequence() uint64
+	GetChangeDate() *timestamppb.Timestamp
+}
+
+// Updater updates a resource of type T using optimistic concurrency control, see the package documentation.
+type Updater[T any] struct {
+	// Read returns the current state of the resource and its details.
+	Read func(ctx context.Context) (T, Details, error)
+	// Write writes the resource and returns the details of the change.
+	Write func(ctx context.Context, value T) (Details, error)
+	// Merge resolves a concurrent modification: base is the state the update was based on,
+	// current the state after the concurrent changes (including the write of the update)
+	// and desired the state written by the update. It returns the state to write.
+	// Without merge, [ErrConflict] is returned on concurrent modifications.
+	Merge func(base, current, desired T) (T, error)
+	// MaxAttempts limits the number of writes (default 3), [ErrConflict] is returned if it's exceeded.
+	MaxAttempts int
+}
+
+// Update reads the resource, modifies it using the function and writes it.
+// A concurrent modification is resolved using [Updater.Merge]. It returns the written state.
+func (u Updater[T]) Update(ctx context.Context, modify func(current T) (T, error)) (T, error) {
+	var zero T
+	base, details, err := u.Read(ctx)
+	if err != nil {
+		return zero, err
+	}
+	desired, err := modify(base)
+	if err != nil {
+		return zero, err
+	}
+	attempts := u.MaxAttempts
+	if attempts <= 0 {
+		attempts = DefaultMaxAttempts
+	}
+	for range attempts {
+		written, err := u.Write(ctx, desired)
+		if err != nil {
+			return zero, err
+		}
+		if !Modified(details, written) {
+			return desired, nil
+		}
+		if u.Merge == nil {
+			return zero, ErrConflict
+		}
+		current, currentDetails, err := u.Read(ctx)
+		if err != nil {
+			return zero, err
+		}
+		merged, err := u.Merge(base, current, desired)
+		if err != nil {
+			return zero, err
+		}
+		base, desired, details = current, merged, currentDetails
+	}
+	return zero, ErrConflict
+}
+
+// Modified reports if the resource was modified by someone else between the read and the write,
+// i.e. the sequence of the write is more than one higher than the one of the read,
+// or the read is newer than the write if the sequences are missing.
+// A write resulting in multiple events is reported as modification as well, which the merge has to resolve.
+func Modified(read, written Details) bool {
+	if read == nil || written == nil {
+		return false
+	}
+	if read.GetSequence() > 0 && written.GetSequence() > 0 {
+		return written.GetSequence() > read.GetSequence()+1
+	}
+	readDate, writtenDate := read.GetChangeDate(), written.GetChangeDate()
+	if readDate == nil || writtenDate == nil {
+		return false
+	}
+	return readDate.AsTime().After(writtenDate.AsTime())
+}
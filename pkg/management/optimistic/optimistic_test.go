@@ -0,0 +1,123 @@
+package optimistic
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/object"
+)
+
+func TestModified(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name          string
+		read, written Details
+		want          bool
+	}{
+		{"next sequence", &object.ObjectDetails{Sequence: 4}, &object.ObjectDetails{Sequence: 5}, false},
+		{"no change", &object.ObjectDetails{Sequence: 4}, &object.ObjectDetails{Sequence: 4}, false},
+		{"sequence skipped", &object.ObjectDetails{Sequence: 4}, &object.ObjectDetails{Sequence: 6}, true},
+		{"missing details", nil, &object.ObjectDetails{Sequence: 6}, false},
+		{
+			"read newer without sequence",
+			&object.ObjectDetails{ChangeDate: timestamppb.New(now)},
+			&object.ObjectDetails{ChangeDate: timestamppb.New(now.Add(-time.Second))},
+			true,
+		},
+		{
+			"written newer without sequence",
+			&object.ObjectDetails{ChangeDate: timestamppb.New(now)},
+			&object.ObjectDetails{ChangeDate: timestamppb.New(now.Add(time.Second))},
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Modified(tt.read, tt.written))
+		})
+	}
+}
+
+// resource simulates a resource with a set of labels, a concurrent writer adds a label before the first write.
+type resource struct {
+	labels     []string
+	sequence   uint64
+	concurrent string
+	writes     int
+}
+
+func (r *resource) updater(merge bool) Updater[[]string] {
+	u := Updater[[]string]{
+		Read: func(context.Context) ([]string, Details, error) {
+			return append([]string(nil), r.labels...), &object.ObjectDetails{Sequence: r.sequence}, nil
+		},
+		Write: func(_ context.Context, labels []string) (Details, error) {
+			if r.concurrent != "" {
+				r.labels = append(r.labels, r.concurrent)
+				r.sequence++
+				r.concurrent = ""
+			}
+			r.writes++
+			r.labels = labels
+			r.sequence++
+			return &object.ObjectDetails{Sequence: r.sequence}, nil
+		},
+	}
+	if merge {
+		u.Merge = func(base, current, desired []string) ([]string, error) {
+			// the write has overwritten the labels added concurrently, so they are only known by the merge of the test
+			if !contains(desired, "concurrent") && !contains(base, "concurrent") {
+				desired = append(desired, "concurrent")
+			}
+			return desired, nil
+		}
+	}
+	return u
+}
+
+func contains(labels []string, label string) bool {
+	for _, l := range labels {
+		if l == label {
+			return true
+		}
+	}
+	return false
+}
+
+func TestUpdater_Update(t *testing.T) {
+	add := func(labels []string) ([]string, error) { return append(labels, "b"), nil }
+
+	t.Run("no conflict", func(t *testing.T) {
+		r := &resource{labels: []string{"a"}, sequence: 1}
+		got, err := r.updater(true).Update(context.Background(), add)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a", "b"}, got)
+		assert.Equal(t, 1, r.writes)
+	})
+	t.Run("conflict merged", func(t *testing.T) {
+		r := &resource{labels: []string{"a"}, sequence: 1, concurrent: "concurrent"}
+		got, err := r.updater(true).Update(context.Background(), add)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a", "b", "concurrent"}, got)
+		assert.Equal(t, got, r.labels)
+		assert.Equal(t, 2, r.writes)
+	})
+	t.Run("conflict without merge", func(t *testing.T) {
+		r := &resource{labels: []string{"a"}, sequence: 1, concurrent: "concurrent"}
+		_, err := r.updater(false).Update(context.Background(), add)
+		require.ErrorIs(t, err, ErrConflict)
+	})
+	t.Run("modify error", func(t *testing.T) {
+		r := &resource{labels: []string{"a"}, sequence: 1}
+		modifyErr := errors.New("invalid")
+		_, err := r.updater(true).Update(context.Background(), func([]string) ([]string, error) { return nil, modifyErr })
+		require.ErrorIs(t, err, modifyErr)
+		assert.Equal(t, 0, r.writes)
+	})
+}
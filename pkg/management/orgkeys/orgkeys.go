@@ -0,0 +1,173 @@
+// Package orgkeys derives stable per-organization data encryption keys from a master key and the organization ID
+// using HKDF (RFC 5869), for applications encrypting tenant data keyed to ZITADEL organizations.
+// Only the current key version of an organization is stored (as metadata of the organization),
+// the keys themselves are never persisted and can be derived again from the master key at any time.
+package orgkeys
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"golang.org/x/crypto/hkdf"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/management"
+)
+
+const (
+	// DefaultMetadataKey is the metadata key of the organization storing the [Bookkeeping].
+	DefaultMetadataKey = "encryption.key"
+	// KeySize is the size of the derived keys (e.g. for AES-256-GCM).
+	KeySize = 32
+	// MinMasterKeySize is the minimal size of the master key.
+	MinMasterKeySize = 32
+)
+
+var (
+	ErrMasterKeyTooShort = fmt.Errorf("master key must be at least %d bytes", MinMasterKeySize)
+	ErrInvalidVersion    = errors.New("key version must be greater than 0")
+	ErrMissingOrgID      = errors.New("organization ID is required")
+)
+
+// Key is a derived data encryption key of an organization.
+type Key struct {
+	OrganizationID string
+	Version        uint32
+	Key            []byte
+}
+
+// Bookkeeping is the rotation state of an organization, stored as JSON in its metadata.
+type Bookkeeping struct {
+	// Version is the current key version, which is used for encryption.
+	Version uint32 `json:"version"`
+	// RotatedAt is the time of the last rotation, zero if never rotated.
+	RotatedAt time.Time `json:"rotatedAt,omitempty"`
+}
+
+// Deriver derives the keys and manages their rotation.
+type Deriver struct {
+	client      *client.Client
+	masterKey   []byte
+	metadataKey string
+	info        string
+}
+
+// Option allows customization of the [Deriver].
+type Option func(*Deriver)
+
+// WithMetadataKey sets the metadata key storing the [Bookkeeping] (default `encryption.key`).
+// Use different keys for independent purposes together with [WithInfo].
+func WithMetadataKey(key string) Option {
+	return func(d *Deriver) {
+		d.metadataKey = key
+	}
+}
+
+// WithInfo sets the application specific info of the derivation (HKDF info),
+// so the same master key derives independent keys for different purposes.
+func WithInfo(info string) Option {
+	return func(d *Deriver) {
+		d.info = info
+	}
+}
+
+// New creates a [Deriver] using the client for the bookkeeping.
+// The master key must be a secret random value of at least [MinMasterKeySize] bytes.
+func New(client *client.Client, masterKey []byte, opts ...Option) (*Deriver, error) {
+	if len(masterKey) < MinMasterKeySize {
+		return nil, ErrMasterKeyTooShort
+	}
+	d := &Deriver{
+		client:      client,
+		masterKey:   masterKey,
+		metadataKey: DefaultMetadataKey,
+		info:        "zitadel-org-data-key",
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d, nil
+}
+
+// Derive returns the key of the organization in the version.
+// It's deterministic and doesn't call ZITADEL, so it's used to decrypt data encrypted with an older version.
+func (d *Deriver) Derive(orgID string, version uint32) (*Key, error) {
+	if orgID == "" {
+		return nil, ErrMissingOrgID
+	}
+	if version == 0 {
+		return nil, ErrInvalidVersion
+	}
+	info := d.info + "|" + orgID + "|" + strconv.FormatUint(uint64(version), 10)
+	key := make([]byte, KeySize)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, d.masterKey, nil, []byte(info)), key); err != nil {
+		return nil, err
+	}
+	return &Key{OrganizationID: orgID, Version: version, Key: key}, nil
+}
+
+// Current returns the key of the current version of the organization, which is used for encryption.
+// Organizations without bookkeeping use version 1.
+func (d *Deriver) Current(ctx context.Context, orgID string) (*Key, error) {
+	bookkeeping, err := d.Bookkeeping(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	return d.Derive(orgID, bookkeeping.Version)
+}
+
+// Rotate increments the key version of the organization and returns the new key.
+// Data encrypted with previous versions must be decrypted using [Deriver.Derive] with the version stored
+// alongside the data. Concurrent rotations of the same organization might result in a single increment.
+func (d *Deriver) Rotate(ctx context.Context, orgID string) (*Key, error) {
+	bookkeeping, err := d.Bookkeeping(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	bookkeeping.Version++
+	bookkeeping.RotatedAt = time.Now().UTC()
+	value, err := json.Marshal(bookkeeping)
+	if err != nil {
+		return nil, err
+	}
+	_, err = d.client.ForOrganization(orgID).ManagementService().SetOrgMetadata(ctx, &management.SetOrgMetadataRequest{
+		Key:   d.metadataKey,
+		Value: value,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return d.Derive(orgID, bookkeeping.Version)
+}
+
+// Bookkeeping returns the rotation state of the organization.
+func (d *Deriver) Bookkeeping(ctx context.Context, orgID string) (*Bookkeeping, error) {
+	if orgID == "" {
+		return nil, ErrMissingOrgID
+	}
+	resp, err := d.client.ForOrganization(orgID).ManagementService().GetOrgMetadata(ctx, &management.GetOrgMetadataRequest{
+		Key: d.metadataKey,
+	})
+	if status.Code(err) == codes.NotFound {
+		return &Bookkeeping{Version: 1}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	bookkeeping := new(Bookkeeping)
+	if err = json.Unmarshal(resp.GetMetadata().GetValue(), bookkeeping); err != nil {
+		return nil, fmt.Errorf("invalid key bookkeeping of organization %s: %w", orgID, err)
+	}
+	if bookkeeping.Version == 0 {
+		bookkeeping.Version = 1
+	}
+	return bookkeeping, nil
+}
@@ -0,0 +1,48 @@
+package orgkeys
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var masterKey = bytes.Repeat([]byte{0x42}, MinMasterKeySize)
+
+func TestNew(t *testing.T) {
+	_, err := New(nil, []byte("short"))
+	assert.ErrorIs(t, err, ErrMasterKeyTooShort)
+}
+
+func TestDeriver_Derive(t *testing.T) {
+	d, err := New(nil, masterKey)
+	require.NoError(t, err)
+
+	key, err := d.Derive("org1", 1)
+	require.NoError(t, err)
+	assert.Len(t, key.Key, KeySize)
+
+	again, err := d.Derive("org1", 1)
+	require.NoError(t, err)
+	assert.Equal(t, key, again, "derivation must be stable")
+
+	otherOrg, err := d.Derive("org2", 1)
+	require.NoError(t, err)
+	assert.NotEqual(t, key.Key, otherOrg.Key)
+
+	otherVersion, err := d.Derive("org1", 2)
+	require.NoError(t, err)
+	assert.NotEqual(t, key.Key, otherVersion.Key)
+
+	otherInfo, err := New(nil, masterKey, WithInfo("documents"))
+	require.NoError(t, err)
+	otherPurpose, err := otherInfo.Derive("org1", 1)
+	require.NoError(t, err)
+	assert.NotEqual(t, key.Key, otherPurpose.Key)
+
+	_, err = d.Derive("org1", 0)
+	assert.ErrorIs(t, err, ErrInvalidVersion)
+	_, err = d.Derive("", 1)
+	assert.ErrorIs(t, err, ErrMissingOrgID)
+}
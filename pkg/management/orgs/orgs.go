@@ -0,0 +1,187 @@
+// Package orgs provides a high level facade for provisioning organizations.
+// [Orgs.ProvisionOrganization] combines the creation of the organization, its first admin, the domains
+// and optional policies into a single call and removes the organization again if any of the steps fails.
+package orgs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/management"
+	orgV2 "github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/org/v2"
+	userV2 "github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/user/v2"
+)
+
+var (
+	ErrMissingName  = errors.New("organization name is required")
+	ErrMissingAdmin = errors.New("admin requires either a user ID or an email")
+)
+
+// OrgSpec describes the organization to be provisioned.
+type OrgSpec struct {
+	Name string
+	// Admin is the first administrator of the organization, none is added if nil.
+	Admin *Admin
+	// Domains are added to the organization additionally to the generated one.
+	Domains []string
+	// PrimaryDomain is set as primary domain of the organization, it's added if not part of Domains.
+	PrimaryDomain string
+	// Template is applied to the organization, the instance defaults are used if nil.
+	Template *Template
+}
+
+// Admin describes the first administrator of the organization.
+// It's either an existing user (UserID) or a human user created in the new organization.
+type Admin struct {
+	// UserID of an existing user, all other user fields are ignored if set.
+	UserID string
+	// Username is the login name of the created user, ZITADEL uses the email if empty.
+	Username   string
+	Email      string
+	GivenName  string
+	FamilyName string
+	// Password is the initial password of the created user, the user has to set it itself if empty.
+	Password string
+	// PasswordChangeRequired requires the user to change the initial password on the first login.
+	PasswordChangeRequired bool
+	// VerifiedEmail marks the email as verified, otherwise a verification code is sent.
+	VerifiedEmail bool
+	// EmailURLTemplate is the link in the verification email, ZITADEL uses its own verification page if empty.
+	EmailURLTemplate string
+	// Roles of the admin in the organization, ZITADEL uses ORG_OWNER if empty.
+	Roles []string
+}
+
+// Provisioned is the result of [Orgs.ProvisionOrganization].
+type Provisioned struct {
+	OrganizationID string
+	// AdminUserID is the ID of the admin, empty if no admin was requested.
+	AdminUserID string
+	// AdminEmailCode is the email verification code of a created admin, if returned by ZITADEL.
+	AdminEmailCode string
+}
+
+// Orgs provides the organization provisioning operations.
+type Orgs struct {
+	client *client.Client
+}
+
+// New creates [Orgs] using the client.
+// Provisioning requires the client to be allowed to create organizations (e.g. IAM_OWNER or IAM_ORG_MANAGER).
+func New(client *client.Client) *Orgs {
+	return &Orgs{client: client}
+}
+
+// ProvisionOrganization creates the organization with the admin of the spec, adds the domains
+// and applies the template.
+// If any step after the creation of the organization fails, the organization (including a created admin)
+// is removed again and the error is returned, joined with the error of the removal, if any.
+func (o *Orgs) ProvisionOrganization(ctx context.Context, spec OrgSpec) (*Provisioned, error) {
+	req, err := addOrganizationRequest(spec)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := o.client.OrganizationServiceV2().AddOrganization(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	provisioned := &Provisioned{OrganizationID: resp.GetOrganizationId()}
+	if spec.Admin != nil {
+		provisioned.AdminUserID = spec.Admin.UserID
+		if admins := resp.GetCreatedAdmins(); len(admins) > 0 {
+			provisioned.AdminUserID = admins[0].GetUserId()
+			provisioned.AdminEmailCode = admins[0].GetEmailCode()
+		}
+	}
+
+	mgmt := o.client.ForOrganization(provisioned.OrganizationID).ManagementService()
+	if err = setup(ctx, mgmt, spec); err != nil {
+		if _, rollbackErr := mgmt.RemoveOrg(context.WithoutCancel(ctx), &management.RemoveOrgRequest{}); rollbackErr != nil {
+			return nil, errors.Join(err, fmt.Errorf("rollback of organization %s failed: %w", provisioned.OrganizationID, rollbackErr))
+		}
+		return nil, err
+	}
+	return provisioned, nil
+}
+
+func setup(ctx context.Context, mgmt management.ManagementServiceClient, spec OrgSpec) error {
+	for _, domain := range domains(spec) {
+		if _, err := mgmt.AddOrgDomain(ctx, &management.AddOrgDomainRequest{Domain: domain}); err != nil {
+			return fmt.Errorf("add domain %s: %w", domain, err)
+		}
+	}
+	if spec.PrimaryDomain != "" {
+		if _, err := mgmt.SetPrimaryOrgDomain(ctx, &management.SetPrimaryOrgDomainRequest{Domain: spec.PrimaryDomain}); err != nil {
+			return fmt.Errorf("set primary domain %s: %w", spec.PrimaryDomain, err)
+		}
+	}
+	if spec.Template != nil {
+		return spec.Template.apply(ctx, mgmt)
+	}
+	return nil
+}
+
+// domains returns the domains of the spec including the primary domain without duplicates.
+func domains(spec OrgSpec) []string {
+	all := make([]string, 0, len(spec.Domains)+1)
+	seen := make(map[string]struct{}, len(spec.Domains)+1)
+	for _, domain := range slices.Concat(spec.Domains, []string{spec.PrimaryDomain}) {
+		if _, ok := seen[domain]; ok || domain == "" {
+			continue
+		}
+		seen[domain] = struct{}{}
+		all = append(all, domain)
+	}
+	return all
+}
+
+func addOrganizationRequest(spec OrgSpec) (*orgV2.AddOrganizationRequest, error) {
+	if spec.Name == "" {
+		return nil, ErrMissingName
+	}
+	req := &orgV2.AddOrganizationRequest{Name: spec.Name}
+	if spec.Admin == nil {
+		return req, nil
+	}
+	admin := &orgV2.AddOrganizationRequest_Admin{Roles: spec.Admin.Roles}
+	switch {
+	case spec.Admin.UserID != "":
+		admin.UserType = &orgV2.AddOrganizationRequest_Admin_UserId{UserId: spec.Admin.UserID}
+	case spec.Admin.Email != "":
+		admin.UserType = &orgV2.AddOrganizationRequest_Admin_Human{Human: addHumanUserRequest(spec.Admin)}
+	default:
+		return nil, ErrMissingAdmin
+	}
+	req.Admins = []*orgV2.AddOrganizationRequest_Admin{admin}
+	return req, nil
+}
+
+func addHumanUserRequest(admin *Admin) *userV2.AddHumanUserRequest {
+	req := &userV2.AddHumanUserRequest{
+		Profile: &userV2.SetHumanProfile{
+			GivenName:  admin.GivenName,
+			FamilyName: admin.FamilyName,
+		},
+		Email: &userV2.SetHumanEmail{Email: admin.Email},
+	}
+	if admin.Username != "" {
+		req.Username = &admin.Username
+	}
+	switch {
+	case admin.VerifiedEmail:
+		req.Email.Verification = &userV2.SetHumanEmail_IsVerified{IsVerified: true}
+	case admin.EmailURLTemplate != "":
+		req.Email.Verification = &userV2.SetHumanEmail_SendCode{
+			SendCode: &userV2.SendEmailVerificationCode{UrlTemplate: &admin.EmailURLTemplate},
+		}
+	}
+	if admin.Password != "" {
+		req.PasswordType = &userV2.AddHumanUserRequest_Password{
+			Password: &userV2.Password{Password: admin.Password, ChangeRequired: admin.PasswordChangeRequired},
+		}
+	}
+	return req
+}
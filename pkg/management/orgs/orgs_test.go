@@ -0,0 +1,88 @@
+package orgs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+
+	orgV2 "github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/org/v2"
+	userV2 "github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/user/v2"
+)
+
+func Test_addOrganizationRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    OrgSpec
+		want    *orgV2.AddOrganizationRequest
+		wantErr error
+	}{
+		{
+			name:    "missing name",
+			spec:    OrgSpec{},
+			wantErr: ErrMissingName,
+		},
+		{
+			name: "without admin",
+			spec: OrgSpec{Name: "ACME"},
+			want: &orgV2.AddOrganizationRequest{Name: "ACME"},
+		},
+		{
+			name: "existing user",
+			spec: OrgSpec{Name: "ACME", Admin: &Admin{UserID: "user", Email: "ignored@example.com"}},
+			want: &orgV2.AddOrganizationRequest{
+				Name: "ACME",
+				Admins: []*orgV2.AddOrganizationRequest_Admin{
+					{UserType: &orgV2.AddOrganizationRequest_Admin_UserId{UserId: "user"}},
+				},
+			},
+		},
+		{
+			name: "human user",
+			spec: OrgSpec{Name: "ACME", Admin: &Admin{
+				Email:         "alice@acme.com",
+				GivenName:     "Alice",
+				FamilyName:    "Doe",
+				Password:      "Password1!",
+				VerifiedEmail: true,
+				Roles:         []string{"ORG_OWNER"},
+			}},
+			want: &orgV2.AddOrganizationRequest{
+				Name: "ACME",
+				Admins: []*orgV2.AddOrganizationRequest_Admin{
+					{
+						UserType: &orgV2.AddOrganizationRequest_Admin_Human{Human: &userV2.AddHumanUserRequest{
+							Profile: &userV2.SetHumanProfile{GivenName: "Alice", FamilyName: "Doe"},
+							Email: &userV2.SetHumanEmail{
+								Email:        "alice@acme.com",
+								Verification: &userV2.SetHumanEmail_IsVerified{IsVerified: true},
+							},
+							PasswordType: &userV2.AddHumanUserRequest_Password{
+								Password: &userV2.Password{Password: "Password1!"},
+							},
+						}},
+						Roles: []string{"ORG_OWNER"},
+					},
+				},
+			},
+		},
+		{
+			name:    "admin without user",
+			spec:    OrgSpec{Name: "ACME", Admin: &Admin{GivenName: "Alice"}},
+			wantErr: ErrMissingAdmin,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := addOrganizationRequest(tt.spec)
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.True(t, proto.Equal(tt.want, got), "got %v", got)
+		})
+	}
+}
+
+func Test_domains(t *testing.T) {
+	spec := OrgSpec{Domains: []string{"acme.com", "", "acme.ch", "acme.com"}, PrimaryDomain: "acme.ch"}
+	assert.Equal(t, []string{"acme.com", "acme.ch"}, domains(spec))
+	assert.Equal(t, []string{"acme.io"}, domains(OrgSpec{PrimaryDomain: "acme.io"}))
+}
@@ -0,0 +1,102 @@
+package orgs
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/management"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/policy"
+)
+
+// Template contains the policies applied to a provisioned organization.
+// Policies which are nil are inherited from the instance.
+type Template struct {
+	Branding           *Branding
+	PasswordComplexity *PasswordComplexity
+	Lockout            *Lockout
+}
+
+// Branding is the custom label policy of the organization. It's activated right away.
+type Branding struct {
+	PrimaryColor        string
+	BackgroundColor     string
+	WarnColor           string
+	FontColor           string
+	PrimaryColorDark    string
+	BackgroundColorDark string
+	WarnColorDark       string
+	FontColorDark       string
+	HideLoginNameSuffix bool
+	DisableWatermark    bool
+	ThemeMode           policy.ThemeMode
+}
+
+// PasswordComplexity is the custom password complexity policy of the organization.
+type PasswordComplexity struct {
+	MinLength    uint64
+	HasUppercase bool
+	HasLowercase bool
+	HasNumber    bool
+	HasSymbol    bool
+}
+
+// Lockout is the custom lockout policy of the organization.
+type Lockout struct {
+	MaxPasswordAttempts uint32
+	MaxOTPAttempts      uint32
+}
+
+func (t *Template) apply(ctx context.Context, mgmt management.ManagementServiceClient) error {
+	if t.Branding != nil {
+		if _, err := mgmt.AddCustomLabelPolicy(ctx, t.Branding.request()); err != nil {
+			return fmt.Errorf("add branding: %w", err)
+		}
+		if _, err := mgmt.ActivateCustomLabelPolicy(ctx, &management.ActivateCustomLabelPolicyRequest{}); err != nil {
+			return fmt.Errorf("activate branding: %w", err)
+		}
+	}
+	if t.PasswordComplexity != nil {
+		if _, err := mgmt.AddCustomPasswordComplexityPolicy(ctx, t.PasswordComplexity.request()); err != nil {
+			return fmt.Errorf("add password complexity policy: %w", err)
+		}
+	}
+	if t.Lockout != nil {
+		if _, err := mgmt.AddCustomLockoutPolicy(ctx, t.Lockout.request()); err != nil {
+			return fmt.Errorf("add lockout policy: %w", err)
+		}
+	}
+	return nil
+}
+
+func (b *Branding) request() *management.AddCustomLabelPolicyRequest {
+	return &management.AddCustomLabelPolicyRequest{
+		PrimaryColor:        b.PrimaryColor,
+		BackgroundColor:     b.BackgroundColor,
+		WarnColor:           b.WarnColor,
+		FontColor:           b.FontColor,
+		PrimaryColorDark:    b.PrimaryColorDark,
+		BackgroundColorDark: b.BackgroundColorDark,
+		WarnColorDark:       b.WarnColorDark,
+		FontColorDark:       b.FontColorDark,
+		HideLoginNameSuffix: b.HideLoginNameSuffix,
+		DisableWatermark:    b.DisableWatermark,
+		ThemeMode:           b.ThemeMode,
+	}
+}
+
+func (p *PasswordComplexity) request() *management.AddCustomPasswordComplexityPolicyRequest {
+	return &management.AddCustomPasswordComplexityPolicyRequest{
+		MinLength:    p.MinLength,
+		HasUppercase: p.HasUppercase,
+		HasLowercase: p.HasLowercase,
+		HasNumber:    p.HasNumber,
+		HasSymbol:    p.HasSymbol,
+	}
+}
+
+func (l *Lockout) request() *management.AddCustomLockoutPolicyRequest {
+	return &management.AddCustomLockoutPolicyRequest{
+		MaxPasswordAttempts: l.MaxPasswordAttempts,
+		MaxOtpAttempts:      l.MaxOTPAttempts,
+	}
+}
@@ -0,0 +1,125 @@
+package permissions
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Change describes how a resource drifted from the snapshot.
+type Change string
+
+const (
+	ChangeAdded   Change = "added"
+	ChangeRemoved Change = "removed"
+	ChangeChanged Change = "changed"
+)
+
+// Resource types of a [Drift].
+const (
+	ResourceOrgMember     = "org_member"
+	ResourceProject       = "project"
+	ResourceProjectRole   = "project_role"
+	ResourceProjectMember = "project_member"
+	ResourceProjectGrant  = "project_grant"
+	ResourceUserGrant     = "user_grant"
+)
+
+// Drift is a difference between the snapshot and the live state.
+type Drift struct {
+	Change   Change `json:"change"`
+	Resource string `json:"resource"`
+	// ID identifies the resource, e.g. the user ID of a member or `<projectID>/<roleKey>` of a project role.
+	ID string `json:"id"`
+	// Expected and Actual describe the state of the snapshot resp. the live state, if any.
+	Expected string `json:"expected,omitempty"`
+	Actual   string `json:"actual,omitempty"`
+}
+
+func (d Drift) String() string {
+	switch d.Change {
+	case ChangeAdded:
+		return fmt.Sprintf("%s %s added: %s", d.Resource, d.ID, d.Actual)
+	case ChangeRemoved:
+		return fmt.Sprintf("%s %s removed: %s", d.Resource, d.ID, d.Expected)
+	default:
+		return fmt.Sprintf("%s %s changed: %s -> %s", d.Resource, d.ID, d.Expected, d.Actual)
+	}
+}
+
+// Compare returns the drift of the actual state against the expected (snapshot) state.
+// An empty result means there is no drift.
+func Compare(expected, actual *Snapshot) []Drift {
+	var drifts []Drift
+	drifts = append(drifts, compare(ResourceOrgMember, expected.OrgMembers, actual.OrgMembers, memberKey(""), describeMember)...)
+	drifts = append(drifts, compare(ResourceProject, expected.Projects, actual.Projects,
+		func(p Project) string { return p.ID },
+		func(p Project) string { return p.Name },
+	)...)
+	actualProjects := make(map[string]Project, len(actual.Projects))
+	for _, p := range actual.Projects {
+		actualProjects[p.ID] = p
+	}
+	for _, exp := range expected.Projects {
+		act, ok := actualProjects[exp.ID]
+		if !ok {
+			continue
+		}
+		drifts = append(drifts, compare(ResourceProjectRole, exp.Roles, act.Roles,
+			func(r Role) string { return exp.ID + "/" + r.Key },
+			func(r Role) string { return fmt.Sprintf("%s (%s)", r.DisplayName, r.Group) },
+		)...)
+		drifts = append(drifts, compare(ResourceProjectMember, exp.Members, act.Members, memberKey(exp.ID+"/"), describeMember)...)
+		drifts = append(drifts, compare(ResourceProjectGrant, exp.Grants, act.Grants,
+			func(g ProjectGrant) string { return exp.ID + "/" + g.ID },
+			func(g ProjectGrant) string {
+				return fmt.Sprintf("org %s: %s", g.GrantedOrgID, strings.Join(g.RoleKeys, ","))
+			},
+		)...)
+	}
+	drifts = append(drifts, compare(ResourceUserGrant, expected.UserGrants, actual.UserGrants,
+		func(g UserGrant) string { return g.ID },
+		func(g UserGrant) string {
+			return fmt.Sprintf("user %s on project %s: %s", g.UserID, g.ProjectID, strings.Join(g.RoleKeys, ","))
+		},
+	)...)
+	return drifts
+}
+
+func memberKey(prefix string) func(Member) string {
+	return func(m Member) string { return prefix + m.UserID }
+}
+
+func describeMember(m Member) string {
+	return strings.Join(m.Roles, ",")
+}
+
+// compare detects added, removed and changed resources identified by the key.
+// Resources are considered changed if their description differs.
+func compare[T any](resource string, expected, actual []T, key, describe func(T) string) []Drift {
+	var drifts []Drift
+	actualByKey := make(map[string]T, len(actual))
+	for _, a := range actual {
+		actualByKey[key(a)] = a
+	}
+	expectedKeys := make(map[string]bool, len(expected))
+	for _, e := range expected {
+		k := key(e)
+		expectedKeys[k] = true
+		a, ok := actualByKey[k]
+		if !ok {
+			drifts = append(drifts, Drift{Change: ChangeRemoved, Resource: resource, ID: k, Expected: describe(e)})
+			continue
+		}
+		if exp, act := describe(e), describe(a); exp != act {
+			drifts = append(drifts, Drift{Change: ChangeChanged, Resource: resource, ID: k, Expected: exp, Actual: act})
+		}
+	}
+	for _, a := range actual {
+		if k := key(a); !expectedKeys[k] {
+			drifts = append(drifts, Drift{Change: ChangeAdded, Resource: resource, ID: k, Actual: describe(a)})
+		}
+	}
+	slices.SortStableFunc(drifts, func(a, b Drift) int { return strings.Compare(a.ID, b.ID) })
+	return drifts
+}
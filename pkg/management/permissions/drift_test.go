@@ -0,0 +1,35 @@
+package permissions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompare(t *testing.T) {
+	expected := &Snapshot{
+		OrgMembers: []Member{{UserID: "1", Roles: []string{"ORG_OWNER"}}, {UserID: "2", Roles: []string{"ORG_USER_MANAGER"}}},
+		Projects: []Project{{
+			ID:    "p1",
+			Name:  "project",
+			Roles: []Role{{Key: "admin"}, {Key: "reader"}},
+		}},
+		UserGrants: []UserGrant{{ID: "g1", UserID: "1", ProjectID: "p1", RoleKeys: []string{"reader"}}},
+	}
+	actual := &Snapshot{
+		OrgMembers: []Member{{UserID: "1", Roles: []string{"ORG_OWNER"}}, {UserID: "3", Roles: []string{"ORG_OWNER"}}},
+		Projects: []Project{{
+			ID:    "p1",
+			Name:  "project",
+			Roles: []Role{{Key: "admin"}, {Key: "reader"}},
+		}},
+		UserGrants: []UserGrant{{ID: "g1", UserID: "1", ProjectID: "p1", RoleKeys: []string{"admin", "reader"}}},
+	}
+
+	assert.Empty(t, Compare(expected, expected))
+	assert.Equal(t, []Drift{
+		{Change: ChangeRemoved, Resource: ResourceOrgMember, ID: "2", Expected: "ORG_USER_MANAGER"},
+		{Change: ChangeAdded, Resource: ResourceOrgMember, ID: "3", Actual: "ORG_OWNER"},
+		{Change: ChangeChanged, Resource: ResourceUserGrant, ID: "g1", Expected: "user 1 on project p1: reader", Actual: "user 1 on project p1: admin,reader"},
+	}, Compare(expected, actual))
+}
@@ -0,0 +1,281 @@
+// Package permissions snapshots the members, roles and grants of an organization or project
+// and detects drift of the live state against a snapshot, e.g. for compliance attestations.
+package permissions
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/management"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/member"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/object"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/project"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/user"
+	"github.com/zitadel/zitadel-go/v3/pkg/internal/pager"
+)
+
+// Scope defines what is covered by a [Snapshot].
+type Scope struct {
+	OrganizationID string `json:"organizationId"`
+	// ProjectID restricts the snapshot to a single project, all projects of the organization are covered if empty.
+	ProjectID string `json:"projectId,omitempty"`
+}
+
+// Snapshot is the state of the permissions at a point in time.
+// All lists are sorted, so snapshots can be compared and stored under version control.
+type Snapshot struct {
+	Scope      Scope       `json:"scope"`
+	TakenAt    time.Time   `json:"takenAt"`
+	OrgMembers []Member    `json:"orgMembers"`
+	Projects   []Project   `json:"projects"`
+	UserGrants []UserGrant `json:"userGrants"`
+}
+
+type Member struct {
+	UserID    string   `json:"userId"`
+	LoginName string   `json:"loginName,omitempty"`
+	Roles     []string `json:"roles"`
+}
+
+type Project struct {
+	ID      string         `json:"id"`
+	Name    string         `json:"name"`
+	Roles   []Role         `json:"roles"`
+	Members []Member       `json:"members"`
+	Grants  []ProjectGrant `json:"grants"`
+}
+
+type Role struct {
+	Key         string `json:"key"`
+	DisplayName string `json:"displayName,omitempty"`
+	Group       string `json:"group,omitempty"`
+}
+
+// ProjectGrant is a grant of the project to another organization.
+type ProjectGrant struct {
+	ID             string   `json:"id"`
+	GrantedOrgID   string   `json:"grantedOrgId"`
+	GrantedOrgName string   `json:"grantedOrgName,omitempty"`
+	RoleKeys       []string `json:"roleKeys"`
+}
+
+type UserGrant struct {
+	ID             string   `json:"id"`
+	UserID         string   `json:"userId"`
+	LoginName      string   `json:"loginName,omitempty"`
+	ProjectID      string   `json:"projectId"`
+	ProjectGrantID string   `json:"projectGrantId,omitempty"`
+	RoleKeys       []string `json:"roleKeys"`
+}
+
+// Permissions takes snapshots and checks them against the live state.
+type Permissions struct {
+	client *client.Client
+}
+
+func New(client *client.Client) *Permissions {
+	return &Permissions{client: client}
+}
+
+// Snapshot takes a [Snapshot] of the current state of the scope.
+func (p *Permissions) Snapshot(ctx context.Context, scope Scope) (*Snapshot, error) {
+	mgmt := p.client.ForOrganization(scope.OrganizationID).ManagementService()
+	snapshot := &Snapshot{
+		Scope:   scope,
+		TakenAt: time.Now().UTC(),
+	}
+	var err error
+	snapshot.OrgMembers, err = orgMembers(ctx, mgmt)
+	if err != nil {
+		return nil, err
+	}
+	projects, err := projects(ctx, mgmt, scope.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	for _, proj := range projects {
+		project, err := projectPermissions(ctx, mgmt, proj)
+		if err != nil {
+			return nil, err
+		}
+		snapshot.Projects = append(snapshot.Projects, *project)
+	}
+	snapshot.UserGrants, err = userGrants(ctx, mgmt, scope.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// Check takes a new snapshot of the scope of the provided one and returns the drift between them.
+func (p *Permissions) Check(ctx context.Context, snapshot *Snapshot) ([]Drift, error) {
+	live, err := p.Snapshot(ctx, snapshot.Scope)
+	if err != nil {
+		return nil, err
+	}
+	return Compare(snapshot, live), nil
+}
+
+// Write writes the snapshot as (indented) JSON.
+func (s *Snapshot) Write(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(s)
+}
+
+// SaveFile writes the snapshot to the file at path.
+func (s *Snapshot) SaveFile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err = s.Write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Read reads a snapshot written by [Snapshot.Write].
+func Read(r io.Reader) (*Snapshot, error) {
+	snapshot := new(Snapshot)
+	if err := json.NewDecoder(r).Decode(snapshot); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// LoadFile reads a snapshot from the file at path.
+func LoadFile(path string) (*Snapshot, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Read(f)
+}
+
+func orgMembers(ctx context.Context, mgmt management.ManagementServiceClient) ([]Member, error) {
+	members, err := pager.ListAll(func(query *object.ListQuery) ([]*member.Member, *object.ListDetails, error) {
+		resp, err := mgmt.ListOrgMembers(ctx, &management.ListOrgMembersRequest{Query: query})
+		return resp.GetResult(), resp.GetDetails(), err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toMembers(members), nil
+}
+
+func projects(ctx context.Context, mgmt management.ManagementServiceClient, projectID string) ([]*project.Project, error) {
+	if projectID != "" {
+		resp, err := mgmt.GetProjectByID(ctx, &management.GetProjectByIDRequest{Id: projectID})
+		if err != nil {
+			return nil, err
+		}
+		return []*project.Project{resp.GetProject()}, nil
+	}
+	projects, err := pager.ListAll(func(query *object.ListQuery) ([]*project.Project, *object.ListDetails, error) {
+		resp, err := mgmt.ListProjects(ctx, &management.ListProjectsRequest{Query: query})
+		return resp.GetResult(), resp.GetDetails(), err
+	})
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(projects, func(a, b *project.Project) int { return strings.Compare(a.GetId(), b.GetId()) })
+	return projects, nil
+}
+
+func projectPermissions(ctx context.Context, mgmt management.ManagementServiceClient, proj *project.Project) (*Project, error) {
+	roles, err := pager.ListAll(func(query *object.ListQuery) ([]*project.Role, *object.ListDetails, error) {
+		resp, err := mgmt.ListProjectRoles(ctx, &management.ListProjectRolesRequest{ProjectId: proj.GetId(), Query: query})
+		return resp.GetResult(), resp.GetDetails(), err
+	})
+	if err != nil {
+		return nil, err
+	}
+	members, err := pager.ListAll(func(query *object.ListQuery) ([]*member.Member, *object.ListDetails, error) {
+		resp, err := mgmt.ListProjectMembers(ctx, &management.ListProjectMembersRequest{ProjectId: proj.GetId(), Query: query})
+		return resp.GetResult(), resp.GetDetails(), err
+	})
+	if err != nil {
+		return nil, err
+	}
+	grants, err := pager.ListAll(func(query *object.ListQuery) ([]*project.GrantedProject, *object.ListDetails, error) {
+		resp, err := mgmt.ListProjectGrants(ctx, &management.ListProjectGrantsRequest{ProjectId: proj.GetId(), Query: query})
+		return resp.GetResult(), resp.GetDetails(), err
+	})
+	if err != nil {
+		return nil, err
+	}
+	p := &Project{
+		ID:      proj.GetId(),
+		Name:    proj.GetName(),
+		Roles:   make([]Role, 0, len(roles)),
+		Members: toMembers(members),
+		Grants:  make([]ProjectGrant, 0, len(grants)),
+	}
+	for _, role := range roles {
+		p.Roles = append(p.Roles, Role{Key: role.GetKey(), DisplayName: role.GetDisplayName(), Group: role.GetGroup()})
+	}
+	slices.SortFunc(p.Roles, func(a, b Role) int { return strings.Compare(a.Key, b.Key) })
+	for _, grant := range grants {
+		p.Grants = append(p.Grants, ProjectGrant{
+			ID:             grant.GetGrantId(),
+			GrantedOrgID:   grant.GetGrantedOrgId(),
+			GrantedOrgName: grant.GetGrantedOrgName(),
+			RoleKeys:       sorted(grant.GetGrantedRoleKeys()),
+		})
+	}
+	slices.SortFunc(p.Grants, func(a, b ProjectGrant) int { return strings.Compare(a.ID, b.ID) })
+	return p, nil
+}
+
+func userGrants(ctx context.Context, mgmt management.ManagementServiceClient, projectID string) ([]UserGrant, error) {
+	var queries []*user.UserGrantQuery
+	if projectID != "" {
+		queries = append(queries, &user.UserGrantQuery{
+			Query: &user.UserGrantQuery_ProjectIdQuery{ProjectIdQuery: &user.UserGrantProjectIDQuery{ProjectId: projectID}},
+		})
+	}
+	grants, err := pager.ListAll(func(query *object.ListQuery) ([]*user.UserGrant, *object.ListDetails, error) {
+		resp, err := mgmt.ListUserGrants(ctx, &management.ListUserGrantRequest{Query: query, Queries: queries})
+		return resp.GetResult(), resp.GetDetails(), err
+	})
+	if err != nil {
+		return nil, err
+	}
+	userGrants := make([]UserGrant, 0, len(grants))
+	for _, grant := range grants {
+		userGrants = append(userGrants, UserGrant{
+			ID:             grant.GetId(),
+			UserID:         grant.GetUserId(),
+			LoginName:      grant.GetPreferredLoginName(),
+			ProjectID:      grant.GetProjectId(),
+			ProjectGrantID: grant.GetProjectGrantId(),
+			RoleKeys:       sorted(grant.GetRoleKeys()),
+		})
+	}
+	slices.SortFunc(userGrants, func(a, b UserGrant) int { return strings.Compare(a.ID, b.ID) })
+	return userGrants, nil
+}
+
+func sorted(s []string) []string {
+	s = slices.Clone(s)
+	slices.Sort(s)
+	return s
+}
+
+func toMembers(members []*member.Member) []Member {
+	result := make([]Member, 0, len(members))
+	for _, m := range members {
+		result = append(result, Member{UserID: m.GetUserId(), LoginName: m.GetPreferredLoginName(), Roles: sorted(m.GetRoles())})
+	}
+	slices.SortFunc(result, func(a, b Member) int { return strings.Compare(a.UserID, b.UserID) })
+	return result
+}
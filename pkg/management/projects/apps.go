@@ -0,0 +1,176 @@
+package projects
+
+import (
+	"context"
+	"errors"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/app"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/management"
+)
+
+var ErrMissingSAMLMetadata = errors.New("SAML application requires either metadata XML or URL")
+
+// App is an application added to a project, either [OIDCApp], [APIApp] or [SAMLApp].
+type App interface {
+	add(ctx context.Context, mgmt management.ManagementServiceClient, projectID string) (*Application, error)
+}
+
+// OIDCAppType defines the defaults of an [OIDCApp].
+type OIDCAppType int
+
+const (
+	// OIDCWeb is a server side web application using the authorization code flow
+	// authenticated by a client secret (basic auth).
+	OIDCWeb OIDCAppType = iota
+	// OIDCSinglePage is a browser application (SPA) using the authorization code flow with PKCE
+	// and no client secret.
+	OIDCSinglePage
+	// OIDCNative is a mobile or desktop application using the authorization code flow with PKCE,
+	// refresh tokens and no client secret.
+	OIDCNative
+)
+
+// OIDCApp is an OpenID Connect application.
+type OIDCApp struct {
+	Name                   string
+	Type                   OIDCAppType
+	RedirectURIs           []string
+	PostLogoutRedirectURIs []string
+	// AdditionalOrigins are allowed for CORS additionally to the origins of the redirect URIs.
+	AdditionalOrigins []string
+	// DevMode allows insecure redirect URIs (e.g. http://localhost), do not use in production.
+	DevMode bool
+	// JWTAccessToken issues JWT instead of opaque access tokens.
+	JWTAccessToken bool
+	// RoleAssertion adds the roles of the user to the access and id token.
+	RoleAssertion bool
+	// PrivateKeyJWT authenticates a [OIDCWeb] application by a key instead of a client secret.
+	PrivateKeyJWT bool
+}
+
+func (o OIDCApp) add(ctx context.Context, mgmt management.ManagementServiceClient, projectID string) (*Application, error) {
+	if o.Name == "" {
+		return nil, ErrMissingName
+	}
+	resp, err := mgmt.AddOIDCApp(ctx, o.request(projectID))
+	if err != nil {
+		return nil, err
+	}
+	created := &Application{
+		Name:         o.Name,
+		AppID:        resp.GetAppId(),
+		ClientID:     resp.GetClientId(),
+		ClientSecret: resp.GetClientSecret(),
+	}
+	for _, problem := range resp.GetComplianceProblems() {
+		created.ComplianceProblems = append(created.ComplianceProblems, problem.GetKey())
+	}
+	return created, nil
+}
+
+func (o OIDCApp) request(projectID string) *management.AddOIDCAppRequest {
+	req := &management.AddOIDCAppRequest{
+		ProjectId:                projectID,
+		Name:                     o.Name,
+		RedirectUris:             o.RedirectURIs,
+		PostLogoutRedirectUris:   o.PostLogoutRedirectURIs,
+		AdditionalOrigins:        o.AdditionalOrigins,
+		ResponseTypes:            []app.OIDCResponseType{app.OIDCResponseType_OIDC_RESPONSE_TYPE_CODE},
+		GrantTypes:               []app.OIDCGrantType{app.OIDCGrantType_OIDC_GRANT_TYPE_AUTHORIZATION_CODE},
+		Version:                  app.OIDCVersion_OIDC_VERSION_1_0,
+		DevMode:                  o.DevMode,
+		AccessTokenRoleAssertion: o.RoleAssertion,
+		IdTokenRoleAssertion:     o.RoleAssertion,
+	}
+	if o.JWTAccessToken {
+		req.AccessTokenType = app.OIDCTokenType_OIDC_TOKEN_TYPE_JWT
+	}
+	switch o.Type {
+	case OIDCWeb:
+		req.AppType = app.OIDCAppType_OIDC_APP_TYPE_WEB
+		req.AuthMethodType = app.OIDCAuthMethodType_OIDC_AUTH_METHOD_TYPE_BASIC
+		if o.PrivateKeyJWT {
+			req.AuthMethodType = app.OIDCAuthMethodType_OIDC_AUTH_METHOD_TYPE_PRIVATE_KEY_JWT
+		}
+	case OIDCSinglePage:
+		req.AppType = app.OIDCAppType_OIDC_APP_TYPE_USER_AGENT
+		req.AuthMethodType = app.OIDCAuthMethodType_OIDC_AUTH_METHOD_TYPE_NONE
+	case OIDCNative:
+		req.AppType = app.OIDCAppType_OIDC_APP_TYPE_NATIVE
+		req.AuthMethodType = app.OIDCAuthMethodType_OIDC_AUTH_METHOD_TYPE_NONE
+		req.GrantTypes = append(req.GrantTypes, app.OIDCGrantType_OIDC_GRANT_TYPE_REFRESH_TOKEN)
+	}
+	return req
+}
+
+// APIApp is an API (resource server), e.g. used for token introspection.
+type APIApp struct {
+	Name string
+	// PrivateKeyJWT authenticates the application by a key instead of a client secret.
+	PrivateKeyJWT bool
+}
+
+func (a APIApp) add(ctx context.Context, mgmt management.ManagementServiceClient, projectID string) (*Application, error) {
+	if a.Name == "" {
+		return nil, ErrMissingName
+	}
+	resp, err := mgmt.AddAPIApp(ctx, a.request(projectID))
+	if err != nil {
+		return nil, err
+	}
+	return &Application{
+		Name:         a.Name,
+		AppID:        resp.GetAppId(),
+		ClientID:     resp.GetClientId(),
+		ClientSecret: resp.GetClientSecret(),
+	}, nil
+}
+
+func (a APIApp) request(projectID string) *management.AddAPIAppRequest {
+	req := &management.AddAPIAppRequest{
+		ProjectId:      projectID,
+		Name:           a.Name,
+		AuthMethodType: app.APIAuthMethodType_API_AUTH_METHOD_TYPE_BASIC,
+	}
+	if a.PrivateKeyJWT {
+		req.AuthMethodType = app.APIAuthMethodType_API_AUTH_METHOD_TYPE_PRIVATE_KEY_JWT
+	}
+	return req
+}
+
+// SAMLApp is a SAML service provider, configured by its metadata.
+type SAMLApp struct {
+	Name string
+	// MetadataXML is the metadata of the service provider.
+	MetadataXML []byte
+	// MetadataURL is used to fetch the metadata if no MetadataXML is provided.
+	MetadataURL string
+}
+
+func (s SAMLApp) add(ctx context.Context, mgmt management.ManagementServiceClient, projectID string) (*Application, error) {
+	req, err := s.request(projectID)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := mgmt.AddSAMLApp(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return &Application{Name: s.Name, AppID: resp.GetAppId()}, nil
+}
+
+func (s SAMLApp) request(projectID string) (*management.AddSAMLAppRequest, error) {
+	if s.Name == "" {
+		return nil, ErrMissingName
+	}
+	req := &management.AddSAMLAppRequest{ProjectId: projectID, Name: s.Name}
+	switch {
+	case len(s.MetadataXML) > 0:
+		req.Metadata = &management.AddSAMLAppRequest_MetadataXml{MetadataXml: s.MetadataXML}
+	case s.MetadataURL != "":
+		req.Metadata = &management.AddSAMLAppRequest_MetadataUrl{MetadataUrl: s.MetadataURL}
+	default:
+		return nil, ErrMissingSAMLMetadata
+	}
+	return req, nil
+}
@@ -0,0 +1,82 @@
+package projects
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/app"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/management"
+)
+
+func TestOIDCApp_request(t *testing.T) {
+	tests := []struct {
+		name string
+		app  OIDCApp
+		want *management.AddOIDCAppRequest
+	}{
+		{
+			name: "web",
+			app:  OIDCApp{Name: "web", RedirectURIs: []string{"https://app.example.com/callback"}, JWTAccessToken: true},
+			want: &management.AddOIDCAppRequest{
+				ProjectId:       "project",
+				Name:            "web",
+				RedirectUris:    []string{"https://app.example.com/callback"},
+				ResponseTypes:   []app.OIDCResponseType{app.OIDCResponseType_OIDC_RESPONSE_TYPE_CODE},
+				GrantTypes:      []app.OIDCGrantType{app.OIDCGrantType_OIDC_GRANT_TYPE_AUTHORIZATION_CODE},
+				AppType:         app.OIDCAppType_OIDC_APP_TYPE_WEB,
+				AuthMethodType:  app.OIDCAuthMethodType_OIDC_AUTH_METHOD_TYPE_BASIC,
+				AccessTokenType: app.OIDCTokenType_OIDC_TOKEN_TYPE_JWT,
+			},
+		},
+		{
+			name: "native",
+			app:  OIDCApp{Name: "native", Type: OIDCNative, RedirectURIs: []string{"com.example.app:/callback"}, RoleAssertion: true},
+			want: &management.AddOIDCAppRequest{
+				ProjectId:     "project",
+				Name:          "native",
+				RedirectUris:  []string{"com.example.app:/callback"},
+				ResponseTypes: []app.OIDCResponseType{app.OIDCResponseType_OIDC_RESPONSE_TYPE_CODE},
+				GrantTypes: []app.OIDCGrantType{
+					app.OIDCGrantType_OIDC_GRANT_TYPE_AUTHORIZATION_CODE,
+					app.OIDCGrantType_OIDC_GRANT_TYPE_REFRESH_TOKEN,
+				},
+				AppType:                  app.OIDCAppType_OIDC_APP_TYPE_NATIVE,
+				AuthMethodType:           app.OIDCAuthMethodType_OIDC_AUTH_METHOD_TYPE_NONE,
+				AccessTokenRoleAssertion: true,
+				IdTokenRoleAssertion:     true,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.app.request("project")
+			assert.True(t, proto.Equal(tt.want, got), "got %v", got)
+		})
+	}
+}
+
+func TestSAMLApp_request(t *testing.T) {
+	_, err := SAMLApp{Name: "saml"}.request("project")
+	assert.ErrorIs(t, err, ErrMissingSAMLMetadata)
+
+	got, err := SAMLApp{Name: "saml", MetadataURL: "https://sp.example.com/metadata"}.request("project")
+	assert.NoError(t, err)
+	assert.True(t, proto.Equal(&management.AddSAMLAppRequest{
+		ProjectId: "project",
+		Name:      "saml",
+		Metadata:  &management.AddSAMLAppRequest_MetadataUrl{MetadataUrl: "https://sp.example.com/metadata"},
+	}, got), "got %v", got)
+}
+
+func Test_bulkAddProjectRolesRequest(t *testing.T) {
+	got := bulkAddProjectRolesRequest("project", []Role{{Key: "admin"}, {Key: "reader", DisplayName: "Reader", Group: "read"}})
+	assert.True(t, proto.Equal(&management.BulkAddProjectRolesRequest{
+		ProjectId: "project",
+		Roles: []*management.BulkAddProjectRolesRequest_Role{
+			{Key: "admin", DisplayName: "admin"},
+			{Key: "reader", DisplayName: "Reader", Group: "read"},
+		},
+	}, got), "got %v", got)
+}
@@ -0,0 +1,142 @@
+// Package projects provides a high level facade for the most common "register my app" workflow:
+// [Projects.Bootstrap] creates a project with its roles and applications (OIDC, API and SAML) using sane defaults
+// and returns the generated client IDs and secrets in a single call.
+package projects
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/management"
+)
+
+var ErrMissingName = errors.New("name is required")
+
+// ProjectSpec describes the project to be bootstrapped.
+type ProjectSpec struct {
+	// OrganizationID is the organization the project is created in, the organization of the client if empty.
+	OrganizationID string
+	Name           string
+	// Roles are added to the project.
+	Roles []Role
+	// RoleAssertion adds the roles of the user to the tokens and userinfo.
+	RoleAssertion bool
+	// RoleCheck only allows users with a grant of the project to log in.
+	RoleCheck bool
+	// ProjectCheck only allows users of organizations granted the project to log in.
+	ProjectCheck bool
+	// Apps are added to the project.
+	Apps []App
+}
+
+// Role is a role of the project.
+type Role struct {
+	Key         string
+	DisplayName string
+	Group       string
+}
+
+// Bootstrapped is the result of [Projects.Bootstrap].
+type Bootstrapped struct {
+	ProjectID string
+	// Applications are the created applications in the order of [ProjectSpec.Apps].
+	Applications []*Application
+}
+
+// Application is a created application.
+type Application struct {
+	Name  string
+	AppID string
+	// ClientID is empty for SAML applications.
+	ClientID string
+	// ClientSecret is only set for applications using a client secret (basic or post authentication)
+	// and only returned on creation, so it must be stored by the caller.
+	ClientSecret string
+	// ComplianceProblems are the keys of the OIDC compliance problems of the configuration, e.g. http redirects
+	// without dev mode.
+	ComplianceProblems []string
+}
+
+// Projects provides the project and application operations.
+type Projects struct {
+	client *client.Client
+}
+
+// New creates [Projects] using the client.
+func New(client *client.Client) *Projects {
+	return &Projects{client: client}
+}
+
+// Bootstrap creates the project of the spec with its roles and applications.
+// If adding a role or application fails, the project is removed again and the error is returned,
+// joined with the error of the removal, if any.
+func (p *Projects) Bootstrap(ctx context.Context, spec ProjectSpec) (*Bootstrapped, error) {
+	if spec.Name == "" {
+		return nil, ErrMissingName
+	}
+	mgmt := p.management(spec.OrganizationID)
+	resp, err := mgmt.AddProject(ctx, &management.AddProjectRequest{
+		Name:                 spec.Name,
+		ProjectRoleAssertion: spec.RoleAssertion,
+		ProjectRoleCheck:     spec.RoleCheck,
+		HasProjectCheck:      spec.ProjectCheck,
+	})
+	if err != nil {
+		return nil, err
+	}
+	bootstrapped := &Bootstrapped{ProjectID: resp.GetId()}
+	if err = bootstrapped.setup(ctx, mgmt, spec); err != nil {
+		if _, rollbackErr := mgmt.RemoveProject(context.WithoutCancel(ctx), &management.RemoveProjectRequest{Id: bootstrapped.ProjectID}); rollbackErr != nil {
+			return nil, errors.Join(err, fmt.Errorf("rollback of project %s failed: %w", bootstrapped.ProjectID, rollbackErr))
+		}
+		return nil, err
+	}
+	return bootstrapped, nil
+}
+
+// AddApplication adds the application to an existing project.
+// The organizationID is the owner of the project, the organization of the client if empty.
+func (p *Projects) AddApplication(ctx context.Context, organizationID, projectID string, app App) (*Application, error) {
+	return app.add(ctx, p.management(organizationID), projectID)
+}
+
+func (b *Bootstrapped) setup(ctx context.Context, mgmt management.ManagementServiceClient, spec ProjectSpec) error {
+	if len(spec.Roles) > 0 {
+		if _, err := mgmt.BulkAddProjectRoles(ctx, bulkAddProjectRolesRequest(b.ProjectID, spec.Roles)); err != nil {
+			return fmt.Errorf("add roles: %w", err)
+		}
+	}
+	b.Applications = make([]*Application, 0, len(spec.Apps))
+	for _, app := range spec.Apps {
+		created, err := app.add(ctx, mgmt, b.ProjectID)
+		if err != nil {
+			return fmt.Errorf("add application: %w", err)
+		}
+		b.Applications = append(b.Applications, created)
+	}
+	return nil
+}
+
+func (p *Projects) management(organizationID string) management.ManagementServiceClient {
+	if organizationID == "" {
+		return p.client.ManagementService()
+	}
+	return p.client.ForOrganization(organizationID).ManagementService()
+}
+
+func bulkAddProjectRolesRequest(projectID string, roles []Role) *management.BulkAddProjectRolesRequest {
+	req := &management.BulkAddProjectRolesRequest{
+		ProjectId: projectID,
+		Roles:     make([]*management.BulkAddProjectRolesRequest_Role, len(roles)),
+	}
+	for i, role := range roles {
+		displayName := role.DisplayName
+		if displayName == "" {
+			displayName = role.Key
+		}
+		req.Roles[i] = &management.BulkAddProjectRolesRequest_Role{Key: role.Key, DisplayName: displayName, Group: role.Group}
+	}
+	return req
+}
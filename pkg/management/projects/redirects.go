@@ -0,0 +1,274 @@
+package projects
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/app"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/management"
+)
+
+var (
+	ErrNotOIDCApp         = errors.New("application is not an OIDC application")
+	ErrInvalidRedirect    = errors.New("invalid redirect URI")
+	ErrInsecureRedirect   = errors.New("http redirect URIs require dev mode, except for loopback addresses of native apps")
+	ErrWildcardRedirect   = errors.New("wildcards in redirect URIs require dev mode")
+	ErrInvalidOrigin      = errors.New("invalid origin, must be scheme://host[:port]")
+	ErrUnknownEnvironment = errors.New("unknown environment")
+	ErrUndefinedVariable  = errors.New("undefined variable")
+)
+
+// Redirects are the URIs of an OIDC application.
+type Redirects struct {
+	RedirectURIs           []string
+	PostLogoutRedirectURIs []string
+	// AdditionalOrigins are allowed for CORS additionally to the origins of the redirect URIs.
+	AdditionalOrigins []string
+}
+
+// Validate checks the URIs as ZITADEL does for an application of the type:
+// redirect URIs must be absolute URLs without fragment, using https (http and wildcards only in dev mode)
+// or, for native applications, a custom scheme or http on a loopback address.
+// Origins must consist of scheme, host and optional port only. All problems are returned joined.
+func (r Redirects) Validate(appType OIDCAppType, devMode bool) error {
+	var errs []error
+	for _, uri := range slices.Concat(r.RedirectURIs, r.PostLogoutRedirectURIs) {
+		if err := validateRedirect(uri, appType, devMode); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, origin := range r.AdditionalOrigins {
+		if err := validateOrigin(origin); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (r Redirects) equal(other Redirects) bool {
+	return equalUnordered(r.RedirectURIs, other.RedirectURIs) &&
+		equalUnordered(r.PostLogoutRedirectURIs, other.PostLogoutRedirectURIs) &&
+		equalUnordered(r.AdditionalOrigins, other.AdditionalOrigins)
+}
+
+func validateRedirect(uri string, appType OIDCAppType, devMode bool) error {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme == "" || strings.Contains(u.Scheme, "*") || u.Fragment != "" {
+		return fmt.Errorf("%w: %q", ErrInvalidRedirect, uri)
+	}
+	if strings.Contains(uri, "*") && !devMode {
+		return fmt.Errorf("%w: %q", ErrWildcardRedirect, uri)
+	}
+	switch u.Scheme {
+	case "https":
+	case "http":
+		if !devMode && !(appType == OIDCNative && isLoopback(u.Hostname())) {
+			return fmt.Errorf("%w: %q", ErrInsecureRedirect, uri)
+		}
+	default:
+		if appType != OIDCNative {
+			return fmt.Errorf("%w: custom schemes are only allowed for native apps: %q", ErrInvalidRedirect, uri)
+		}
+		return nil
+	}
+	if u.Host == "" {
+		return fmt.Errorf("%w: host is required: %q", ErrInvalidRedirect, uri)
+	}
+	return nil
+}
+
+func validateOrigin(origin string) error {
+	u, err := url.Parse(origin)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || strings.Contains(origin, "*") ||
+		(u.Path != "" && u.Path != "/") || u.RawQuery != "" || u.Fragment != "" || u.User != nil {
+		return fmt.Errorf("%w: %q", ErrInvalidOrigin, origin)
+	}
+	return nil
+}
+
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// RedirectTemplate defines the [Redirects] of an application for multiple environments at once.
+// The URIs contain variables (`${NAME}` or `$NAME`), which are replaced by the values of an environment:
+//
+//	template := projects.RedirectTemplate{
+//		Redirects: projects.Redirects{
+//			RedirectURIs:           []string{"${BASE_URL}/auth/callback"},
+//			PostLogoutRedirectURIs: []string{"${BASE_URL}/"},
+//		},
+//		Environments: map[string]map[string]string{
+//			"dev":  {"BASE_URL": "http://localhost:3000"},
+//			"prod": {"BASE_URL": "https://app.example.com"},
+//		},
+//	}
+//	redirects, err := template.Render("prod")
+type RedirectTemplate struct {
+	Redirects
+	Environments map[string]map[string]string
+}
+
+// Render returns the [Redirects] of the environment. Duplicates are removed,
+// e.g. of URIs equal in all environments.
+func (t RedirectTemplate) Render(environment string) (Redirects, error) {
+	vars, ok := t.Environments[environment]
+	if !ok {
+		return Redirects{}, fmt.Errorf("%w: %q", ErrUnknownEnvironment, environment)
+	}
+	var undefined []string
+	expand := func(uris []string) []string {
+		if uris == nil {
+			return nil
+		}
+		expanded := make([]string, 0, len(uris))
+		for _, uri := range uris {
+			uri = os.Expand(uri, func(name string) string {
+				value, ok := vars[name]
+				if !ok && !slices.Contains(undefined, name) {
+					undefined = append(undefined, name)
+				}
+				return value
+			})
+			if !slices.Contains(expanded, uri) {
+				expanded = append(expanded, uri)
+			}
+		}
+		return expanded
+	}
+	redirects := Redirects{
+		RedirectURIs:           expand(t.RedirectURIs),
+		PostLogoutRedirectURIs: expand(t.PostLogoutRedirectURIs),
+		AdditionalOrigins:      expand(t.AdditionalOrigins),
+	}
+	if len(undefined) > 0 {
+		return Redirects{}, fmt.Errorf("%w in environment %q: %s", ErrUndefinedVariable, environment, strings.Join(undefined, ", "))
+	}
+	return redirects, nil
+}
+
+// SetRedirects replaces the URIs of the OIDC application, all other settings are kept.
+// New URIs are validated for the type and dev mode of the application, nothing is changed if the URIs are equal.
+// The organizationID is the owner of the project, the organization of the client if empty.
+func (p *Projects) SetRedirects(ctx context.Context, organizationID, projectID, appID string, redirects Redirects) error {
+	return updateRedirects(ctx, p.management(organizationID), projectID, appID, func(Redirects) Redirects {
+		return redirects
+	})
+}
+
+// AddRedirects adds the URIs to the OIDC application, existing ones are kept (see [Projects.SetRedirects]).
+func (p *Projects) AddRedirects(ctx context.Context, organizationID, projectID, appID string, redirects Redirects) error {
+	return updateRedirects(ctx, p.management(organizationID), projectID, appID, func(current Redirects) Redirects {
+		return Redirects{
+			RedirectURIs:           union(current.RedirectURIs, redirects.RedirectURIs),
+			PostLogoutRedirectURIs: union(current.PostLogoutRedirectURIs, redirects.PostLogoutRedirectURIs),
+			AdditionalOrigins:      union(current.AdditionalOrigins, redirects.AdditionalOrigins),
+		}
+	})
+}
+
+// RemoveRedirects removes the URIs from the OIDC application (see [Projects.SetRedirects]).
+func (p *Projects) RemoveRedirects(ctx context.Context, organizationID, projectID, appID string, redirects Redirects) error {
+	return updateRedirects(ctx, p.management(organizationID), projectID, appID, func(current Redirects) Redirects {
+		return Redirects{
+			RedirectURIs:           without(current.RedirectURIs, redirects.RedirectURIs),
+			PostLogoutRedirectURIs: without(current.PostLogoutRedirectURIs, redirects.PostLogoutRedirectURIs),
+			AdditionalOrigins:      without(current.AdditionalOrigins, redirects.AdditionalOrigins),
+		}
+	})
+}
+
+func updateRedirects(ctx context.Context, mgmt management.ManagementServiceClient, projectID, appID string, change func(current Redirects) Redirects) error {
+	resp, err := mgmt.GetAppByID(ctx, &management.GetAppByIDRequest{ProjectId: projectID, AppId: appID})
+	if err != nil {
+		return err
+	}
+	config := resp.GetApp().GetOidcConfig()
+	if config == nil {
+		return ErrNotOIDCApp
+	}
+	current := Redirects{
+		RedirectURIs:           config.GetRedirectUris(),
+		PostLogoutRedirectURIs: config.GetPostLogoutRedirectUris(),
+		AdditionalOrigins:      config.GetAdditionalOrigins(),
+	}
+	desired := change(current)
+	// existing URIs are not validated again, so non-compliant ones can still be removed
+	added := Redirects{
+		RedirectURIs:           without(desired.RedirectURIs, current.RedirectURIs),
+		PostLogoutRedirectURIs: without(desired.PostLogoutRedirectURIs, current.PostLogoutRedirectURIs),
+		AdditionalOrigins:      without(desired.AdditionalOrigins, current.AdditionalOrigins),
+	}
+	if err = added.Validate(oidcAppTypeFromProto(config.GetAppType()), config.GetDevMode()); err != nil {
+		return err
+	}
+	if desired.equal(current) {
+		return nil
+	}
+	_, err = mgmt.UpdateOIDCAppConfig(ctx, &management.UpdateOIDCAppConfigRequest{
+		ProjectId:                projectID,
+		AppId:                    appID,
+		RedirectUris:             desired.RedirectURIs,
+		ResponseTypes:            config.GetResponseTypes(),
+		GrantTypes:               config.GetGrantTypes(),
+		AppType:                  config.GetAppType(),
+		AuthMethodType:           config.GetAuthMethodType(),
+		PostLogoutRedirectUris:   desired.PostLogoutRedirectURIs,
+		DevMode:                  config.GetDevMode(),
+		AccessTokenType:          config.GetAccessTokenType(),
+		AccessTokenRoleAssertion: config.GetAccessTokenRoleAssertion(),
+		IdTokenRoleAssertion:     config.GetIdTokenRoleAssertion(),
+		IdTokenUserinfoAssertion: config.GetIdTokenUserinfoAssertion(),
+		ClockSkew:                config.GetClockSkew(),
+		AdditionalOrigins:        desired.AdditionalOrigins,
+		SkipNativeAppSuccessPage: config.GetSkipNativeAppSuccessPage(),
+		BackChannelLogoutUri:     config.GetBackChannelLogoutUri(),
+		LoginVersion:             config.GetLoginVersion(),
+	})
+	return err
+}
+
+func oidcAppTypeFromProto(appType app.OIDCAppType) OIDCAppType {
+	switch appType {
+	case app.OIDCAppType_OIDC_APP_TYPE_USER_AGENT:
+		return OIDCSinglePage
+	case app.OIDCAppType_OIDC_APP_TYPE_NATIVE:
+		return OIDCNative
+	default:
+		return OIDCWeb
+	}
+}
+
+func union(a, b []string) []string {
+	result := slices.Clone(a)
+	for _, s := range b {
+		if !slices.Contains(result, s) {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+func without(a, b []string) []string {
+	return slices.DeleteFunc(slices.Clone(a), func(s string) bool { return slices.Contains(b, s) })
+}
+
+func equalUnordered(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(a, b)
+}
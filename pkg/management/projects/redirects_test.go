@@ -0,0 +1,193 @@
+package projects
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/app"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/management"
+)
+
+func TestRedirects_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		redirects Redirects
+		appType   OIDCAppType
+		devMode   bool
+		wantErr   error
+	}{
+		{
+			name: "valid",
+			redirects: Redirects{
+				RedirectURIs:           []string{"https://app.example.com/callback"},
+				PostLogoutRedirectURIs: []string{"https://app.example.com/"},
+				AdditionalOrigins:      []string{"https://admin.example.com", "http://localhost:3000/"},
+			},
+		},
+		{
+			name:      "http without dev mode",
+			redirects: Redirects{RedirectURIs: []string{"http://app.example.com/callback"}},
+			wantErr:   ErrInsecureRedirect,
+		},
+		{
+			name:      "http with dev mode",
+			redirects: Redirects{RedirectURIs: []string{"http://app.example.com/callback"}},
+			devMode:   true,
+		},
+		{
+			name:      "native loopback",
+			redirects: Redirects{RedirectURIs: []string{"http://127.0.0.1:8080/callback", "http://localhost/callback", "com.example.app:/callback"}},
+			appType:   OIDCNative,
+		},
+		{
+			name:      "custom scheme for web",
+			redirects: Redirects{RedirectURIs: []string{"com.example.app:/callback"}},
+			wantErr:   ErrInvalidRedirect,
+		},
+		{
+			name:      "relative",
+			redirects: Redirects{PostLogoutRedirectURIs: []string{"/logout"}},
+			wantErr:   ErrInvalidRedirect,
+		},
+		{
+			name:      "fragment",
+			redirects: Redirects{RedirectURIs: []string{"https://app.example.com/#/callback"}},
+			wantErr:   ErrInvalidRedirect,
+		},
+		{
+			name:      "wildcard without dev mode",
+			redirects: Redirects{RedirectURIs: []string{"https://*.preview.example.com/callback"}},
+			wantErr:   ErrWildcardRedirect,
+		},
+		{
+			name:      "wildcard with dev mode",
+			redirects: Redirects{RedirectURIs: []string{"https://*.preview.example.com/callback"}},
+			devMode:   true,
+		},
+		{
+			name:      "origin with path",
+			redirects: Redirects{AdditionalOrigins: []string{"https://app.example.com/path"}},
+			wantErr:   ErrInvalidOrigin,
+		},
+		{
+			name:      "origin with wildcard",
+			redirects: Redirects{AdditionalOrigins: []string{"https://*.example.com"}},
+			devMode:   true,
+			wantErr:   ErrInvalidOrigin,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.redirects.Validate(tt.appType, tt.devMode)
+			if tt.wantErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestRedirectTemplate_Render(t *testing.T) {
+	template := RedirectTemplate{
+		Redirects: Redirects{
+			RedirectURIs:           []string{"${BASE_URL}/auth/callback", "$ADMIN_URL/auth/callback"},
+			PostLogoutRedirectURIs: []string{"${BASE_URL}/", "${ADMIN_URL}/"},
+			AdditionalOrigins:      []string{"${BASE_URL}"},
+		},
+		Environments: map[string]map[string]string{
+			"dev":   {"BASE_URL": "http://localhost:3000", "ADMIN_URL": "http://localhost:3000"},
+			"prod":  {"BASE_URL": "https://app.example.com", "ADMIN_URL": "https://admin.example.com"},
+			"stage": {"BASE_URL": "https://stage.example.com"},
+		},
+	}
+
+	prod, err := template.Render("prod")
+	require.NoError(t, err)
+	assert.Equal(t, Redirects{
+		RedirectURIs:           []string{"https://app.example.com/auth/callback", "https://admin.example.com/auth/callback"},
+		PostLogoutRedirectURIs: []string{"https://app.example.com/", "https://admin.example.com/"},
+		AdditionalOrigins:      []string{"https://app.example.com"},
+	}, prod)
+
+	dev, err := template.Render("dev")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"http://localhost:3000/auth/callback"}, dev.RedirectURIs)
+
+	_, err = template.Render("stage")
+	assert.ErrorIs(t, err, ErrUndefinedVariable)
+	assert.ErrorContains(t, err, "ADMIN_URL")
+	_, err = template.Render("test")
+	assert.ErrorIs(t, err, ErrUnknownEnvironment)
+}
+
+type fakeAppManagement struct {
+	management.ManagementServiceClient
+	app     *app.App
+	updates []*management.UpdateOIDCAppConfigRequest
+}
+
+func (f *fakeAppManagement) GetAppByID(context.Context, *management.GetAppByIDRequest, ...grpc.CallOption) (*management.GetAppByIDResponse, error) {
+	return &management.GetAppByIDResponse{App: f.app}, nil
+}
+
+func (f *fakeAppManagement) UpdateOIDCAppConfig(_ context.Context, req *management.UpdateOIDCAppConfigRequest, _ ...grpc.CallOption) (*management.UpdateOIDCAppConfigResponse, error) {
+	f.updates = append(f.updates, req)
+	return &management.UpdateOIDCAppConfigResponse{}, nil
+}
+
+func Test_updateRedirects(t *testing.T) {
+	newMgmt := func() *fakeAppManagement {
+		return &fakeAppManagement{app: &app.App{Config: &app.App_OidcConfig{OidcConfig: &app.OIDCConfig{
+			AppType:                  app.OIDCAppType_OIDC_APP_TYPE_WEB,
+			AuthMethodType:           app.OIDCAuthMethodType_OIDC_AUTH_METHOD_TYPE_PRIVATE_KEY_JWT,
+			RedirectUris:             []string{"https://app.example.com/callback", "http://legacy.example.com/callback"},
+			AccessTokenRoleAssertion: true,
+		}}}}
+	}
+	ctx := context.Background()
+
+	t.Run("remove non-compliant and add", func(t *testing.T) {
+		mgmt := newMgmt()
+		err := updateRedirects(ctx, mgmt, "project", "app", func(current Redirects) Redirects {
+			current.RedirectURIs = union(without(current.RedirectURIs, []string{"http://legacy.example.com/callback"}), []string{"https://new.example.com/callback"})
+			return current
+		})
+		require.NoError(t, err)
+		require.Len(t, mgmt.updates, 1)
+		update := mgmt.updates[0]
+		assert.Equal(t, []string{"https://app.example.com/callback", "https://new.example.com/callback"}, update.GetRedirectUris())
+		assert.Equal(t, app.OIDCAuthMethodType_OIDC_AUTH_METHOD_TYPE_PRIVATE_KEY_JWT, update.GetAuthMethodType())
+		assert.True(t, update.GetAccessTokenRoleAssertion())
+	})
+
+	t.Run("unchanged", func(t *testing.T) {
+		mgmt := newMgmt()
+		err := updateRedirects(ctx, mgmt, "project", "app", func(current Redirects) Redirects {
+			current.RedirectURIs = []string{"http://legacy.example.com/callback", "https://app.example.com/callback"}
+			return current
+		})
+		require.NoError(t, err)
+		assert.Empty(t, mgmt.updates)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		mgmt := newMgmt()
+		err := updateRedirects(ctx, mgmt, "project", "app", func(current Redirects) Redirects {
+			current.AdditionalOrigins = []string{"https://app.example.com/path"}
+			return current
+		})
+		assert.ErrorIs(t, err, ErrInvalidOrigin)
+		assert.Empty(t, mgmt.updates)
+	})
+
+	t.Run("not oidc", func(t *testing.T) {
+		mgmt := &fakeAppManagement{app: &app.App{Config: &app.App_ApiConfig{ApiConfig: &app.APIConfig{}}}}
+		err := updateRedirects(ctx, mgmt, "project", "app", func(current Redirects) Redirects { return current })
+		assert.ErrorIs(t, err, ErrNotOIDCApp)
+	})
+}
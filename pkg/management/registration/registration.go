@@ -0,0 +1,259 @@
+// Package registration is a backend kit for public sign-up flows, where a person registers a new organization
+// with themselves as its first user:
+//
+//	reg := registration.New(c,
+//		registration.WithAllowedDomains("example.com"),
+//		registration.WithCheck(verifyCaptcha),
+//		registration.WithDefaultRoles(projectID, "user"),
+//	)
+//	result, err := reg.Register(ctx, registration.Request{Email: email, OrganizationName: name, ...})
+//
+// [Registration.Register] validates the email and its domain, runs the checks (e.g. CAPTCHA or risk scoring),
+// creates the organization and the user, grants the default roles and lets ZITADEL send the email verification.
+// Registrations are idempotent by their email (see [Store]): a repeated request returns the first result.
+package registration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"slices"
+	"strings"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/management"
+	"github.com/zitadel/zitadel-go/v3/pkg/management/orgs"
+)
+
+var (
+	ErrInvalidEmail  = errors.New("invalid email")
+	ErrDomainBlocked = errors.New("email domain is not allowed")
+	ErrMissingName   = errors.New("organization name is required")
+)
+
+// Request is the data of a sign-up.
+type Request struct {
+	Email            string
+	OrganizationName string
+	GivenName        string
+	FamilyName       string
+	// Password is the initial password of the user, the user has to set it itself if empty.
+	Password string
+	// CaptchaToken, RemoteAddr and UserAgent are not used by ZITADEL, but passed to the checks (see [WithCheck]).
+	CaptchaToken string
+	RemoteAddr   string
+	UserAgent    string
+}
+
+// Result is the result of a registration.
+type Result struct {
+	OrganizationID string
+	UserID         string
+	// Existing is true if the result is the one of a previous registration with the email.
+	Existing bool
+}
+
+// Check is called for every request before anything is created, e.g. to verify a CAPTCHA or to score the risk.
+// Returning an error rejects the request.
+type Check func(ctx context.Context, req *Request) error
+
+// Registration registers organizations with their first user.
+type Registration struct {
+	client         *client.Client
+	store          Store
+	allowedDomains []string
+	blockedDomains []string
+	checks         []Check
+	adminRoles     []string
+	defaultRoles   []defaultRoles
+	urlTemplate    string
+}
+
+type defaultRoles struct {
+	projectID string
+	roles     []string
+}
+
+// Option allows customization of the [Registration].
+type Option func(*Registration)
+
+// WithStore sets the store for the idempotency, an in-memory store is used by default (see [NewMemoryStore]).
+func WithStore(store Store) Option {
+	return func(r *Registration) {
+		r.store = store
+	}
+}
+
+// WithAllowedDomains only allows emails of the domains (including their subdomains).
+func WithAllowedDomains(domains ...string) Option {
+	return func(r *Registration) {
+		r.allowedDomains = append(r.allowedDomains, lowercase(domains)...)
+	}
+}
+
+// WithBlockedDomains rejects emails of the domains (including their subdomains), e.g. disposable email providers.
+func WithBlockedDomains(domains ...string) Option {
+	return func(r *Registration) {
+		r.blockedDomains = append(r.blockedDomains, lowercase(domains)...)
+	}
+}
+
+// WithCheck adds a check called for every request, in the order of the options.
+func WithCheck(check Check) Option {
+	return func(r *Registration) {
+		r.checks = append(r.checks, check)
+	}
+}
+
+// WithAdminRoles sets the roles of the user in its organization, ZITADEL uses ORG_OWNER by default.
+func WithAdminRoles(roles ...string) Option {
+	return func(r *Registration) {
+		r.adminRoles = roles
+	}
+}
+
+// WithDefaultRoles grants the roles of the project to the user.
+// The project must belong to the organization of the client, it's granted to the new organization.
+func WithDefaultRoles(projectID string, roles ...string) Option {
+	return func(r *Registration) {
+		r.defaultRoles = append(r.defaultRoles, defaultRoles{projectID: projectID, roles: roles})
+	}
+}
+
+// WithEmailURLTemplate sets the link in the verification email, ZITADEL uses its own verification page by default.
+func WithEmailURLTemplate(template string) Option {
+	return func(r *Registration) {
+		r.urlTemplate = template
+	}
+}
+
+// New creates the [Registration] using the client.
+// The client must be allowed to create organizations (e.g. IAM_ORG_MANAGER)
+// and to grant the projects of the default roles.
+func New(client *client.Client, opts ...Option) *Registration {
+	r := &Registration{client: client}
+	for _, opt := range opts {
+		opt(r)
+	}
+	if r.store == nil {
+		r.store = NewMemoryStore()
+	}
+	return r
+}
+
+// Register validates the request, runs the checks and creates the organization with the user.
+// If a registration with the email was completed before, its result is returned without creating anything.
+// If any step fails, the created organization is removed again, so the registration can be retried.
+func (r *Registration) Register(ctx context.Context, req Request) (*Result, error) {
+	email, err := r.validate(&req)
+	if err != nil {
+		return nil, err
+	}
+	for _, check := range r.checks {
+		if err = check(ctx, &req); err != nil {
+			return nil, err
+		}
+	}
+	existing, err := r.store.Acquire(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		existing.Existing = true
+		return existing, nil
+	}
+	result, err := r.register(ctx, email, req)
+	if err != nil {
+		return nil, errors.Join(err, r.store.Release(context.WithoutCancel(ctx), email))
+	}
+	if err = r.store.Complete(ctx, email, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// validate normalizes the email of the request and checks it against the allowed and blocked domains.
+func (r *Registration) validate(req *Request) (string, error) {
+	if strings.TrimSpace(req.OrganizationName) == "" {
+		return "", ErrMissingName
+	}
+	address, err := mail.ParseAddress(strings.TrimSpace(req.Email))
+	if err != nil || address.Name != "" {
+		return "", ErrInvalidEmail
+	}
+	email := strings.ToLower(address.Address)
+	domain := email[strings.LastIndex(email, "@")+1:]
+	if len(r.allowedDomains) > 0 && !matchesDomain(domain, r.allowedDomains) {
+		return "", fmt.Errorf("%w: %s", ErrDomainBlocked, domain)
+	}
+	if matchesDomain(domain, r.blockedDomains) {
+		return "", fmt.Errorf("%w: %s", ErrDomainBlocked, domain)
+	}
+	req.Email = email
+	return email, nil
+}
+
+func (r *Registration) register(ctx context.Context, email string, req Request) (*Result, error) {
+	provisioned, err := orgs.New(r.client).ProvisionOrganization(ctx, orgs.OrgSpec{
+		Name: strings.TrimSpace(req.OrganizationName),
+		Admin: &orgs.Admin{
+			Email:            email,
+			GivenName:        req.GivenName,
+			FamilyName:       req.FamilyName,
+			Password:         req.Password,
+			EmailURLTemplate: r.urlTemplate,
+			Roles:            r.adminRoles,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := &Result{OrganizationID: provisioned.OrganizationID, UserID: provisioned.AdminUserID}
+	if err = r.grantDefaultRoles(ctx, result); err != nil {
+		mgmt := r.client.ForOrganization(result.OrganizationID).ManagementService()
+		if _, rollbackErr := mgmt.RemoveOrg(context.WithoutCancel(ctx), &management.RemoveOrgRequest{}); rollbackErr != nil {
+			return nil, errors.Join(err, fmt.Errorf("rollback of organization %s failed: %w", result.OrganizationID, rollbackErr))
+		}
+		return nil, err
+	}
+	return result, nil
+}
+
+func (r *Registration) grantDefaultRoles(ctx context.Context, result *Result) error {
+	for _, defaults := range r.defaultRoles {
+		grant, err := r.client.ManagementService().AddProjectGrant(ctx, &management.AddProjectGrantRequest{
+			ProjectId:    defaults.projectID,
+			GrantedOrgId: result.OrganizationID,
+			RoleKeys:     defaults.roles,
+		})
+		if err != nil {
+			return fmt.Errorf("grant project %s: %w", defaults.projectID, err)
+		}
+		_, err = r.client.ForOrganization(result.OrganizationID).ManagementService().AddUserGrant(ctx, &management.AddUserGrantRequest{
+			UserId:         result.UserID,
+			ProjectId:      defaults.projectID,
+			ProjectGrantId: grant.GetGrantId(),
+			RoleKeys:       defaults.roles,
+		})
+		if err != nil {
+			return fmt.Errorf("grant roles of project %s: %w", defaults.projectID, err)
+		}
+	}
+	return nil
+}
+
+// matchesDomain returns true if the domain is one of the domains or a subdomain of them.
+func matchesDomain(domain string, domains []string) bool {
+	return slices.ContainsFunc(domains, func(d string) bool {
+		return domain == d || strings.HasSuffix(domain, "."+d)
+	})
+}
+
+func lowercase(values []string) []string {
+	lower := make([]string, len(values))
+	for i, value := range values {
+		lower[i] = strings.ToLower(value)
+	}
+	return lower
+}
@@ -0,0 +1,82 @@
+package registration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistration_validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []Option
+		email   string
+		want    string
+		wantErr error
+	}{
+		{
+			name:  "normalized",
+			email: " Jane.Doe@Example.COM ",
+			want:  "jane.doe@example.com",
+		},
+		{
+			name:    "invalid",
+			email:   "jane.doe",
+			wantErr: ErrInvalidEmail,
+		},
+		{
+			name:    "display name",
+			email:   "Jane <jane@example.com>",
+			wantErr: ErrInvalidEmail,
+		},
+		{
+			name:  "allowed subdomain",
+			opts:  []Option{WithAllowedDomains("Example.com")},
+			email: "jane@eu.example.com",
+			want:  "jane@eu.example.com",
+		},
+		{
+			name:    "not allowed",
+			opts:    []Option{WithAllowedDomains("example.com")},
+			email:   "jane@notexample.com",
+			wantErr: ErrDomainBlocked,
+		},
+		{
+			name:    "blocked",
+			opts:    []Option{WithBlockedDomains("mailinator.com")},
+			email:   "jane@mailinator.com",
+			wantErr: ErrDomainBlocked,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &Request{Email: tt.email, OrganizationName: "ACME"}
+			got, err := New(nil, tt.opts...).validate(req)
+			require.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	existing, err := store.Acquire(ctx, "jane@example.com")
+	require.NoError(t, err)
+	assert.Nil(t, existing)
+	_, err = store.Acquire(ctx, "jane@example.com")
+	assert.ErrorIs(t, err, ErrInProgress)
+
+	require.NoError(t, store.Release(ctx, "jane@example.com"))
+	_, err = store.Acquire(ctx, "jane@example.com")
+	require.NoError(t, err)
+
+	require.NoError(t, store.Complete(ctx, "jane@example.com", &Result{OrganizationID: "org", UserID: "user"}))
+	require.NoError(t, store.Release(ctx, "jane@example.com"), "completed registrations are kept")
+	existing, err = store.Acquire(ctx, "jane@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, &Result{OrganizationID: "org", UserID: "user"}, existing)
+}
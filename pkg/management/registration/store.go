@@ -0,0 +1,69 @@
+package registration
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrInProgress is returned by [Store.Acquire] if another registration with the same email is running.
+var ErrInProgress = errors.New("registration is already in progress")
+
+// Store makes the registrations idempotent by remembering them by their (normalized) email,
+// e.g. in a database shared by all instances of the backend.
+type Store interface {
+	// Acquire reserves the email for a registration. It returns the result of a completed registration with the email,
+	// nil if the email was reserved and [ErrInProgress] if it's already reserved.
+	Acquire(ctx context.Context, email string) (*Result, error)
+	// Complete stores the result of the registration reserved by Acquire.
+	Complete(ctx context.Context, email string, result *Result) error
+	// Release removes the reservation of a failed registration, so it can be retried.
+	Release(ctx context.Context, email string) error
+}
+
+// MemoryStore is an in-memory [Store], e.g. for tests or single process setups.
+type MemoryStore struct {
+	mu      sync.Mutex
+	results map[string]*Result
+}
+
+// NewMemoryStore creates an empty [MemoryStore].
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{results: make(map[string]*Result)}
+}
+
+// Acquire implements [Store].
+// A reservation is stored as nil result.
+func (s *MemoryStore) Acquire(_ context.Context, email string) (*Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result, ok := s.results[email]
+	if !ok {
+		s.results[email] = nil
+		return nil, nil
+	}
+	if result == nil {
+		return nil, ErrInProgress
+	}
+	c := *result
+	return &c, nil
+}
+
+// Complete implements [Store].
+func (s *MemoryStore) Complete(_ context.Context, email string, result *Result) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := *result
+	s.results[email] = &c
+	return nil
+}
+
+// Release implements [Store].
+func (s *MemoryStore) Release(_ context.Context, email string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if result, ok := s.results[email]; ok && result == nil {
+		delete(s.results, email)
+	}
+	return nil
+}
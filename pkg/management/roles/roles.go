@@ -0,0 +1,271 @@
+// Package roles synchronizes the roles of a project with a set defined in code, so the role definitions
+// are kept in version control:
+//
+//	var projectRoles = []roles.RoleSpec{
+//		{Key: "admin", DisplayName: "Administrator"},
+//		{Key: "editor", DisplayName: "Editor", Group: "write"},
+//	}
+//
+//	result, err := roles.New(client, roles.WithDeleteExtra()).Sync(ctx, projectID, projectRoles)
+//
+// The calls are executed in the organization of the client, use [client.Client.ForOrganization] for projects
+// of other organizations.
+package roles
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/management"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/object"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/project"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/user"
+	"github.com/zitadel/zitadel-go/v3/pkg/internal/pager"
+)
+
+var (
+	ErrMissingKey   = errors.New("role key is required")
+	ErrDuplicateKey = errors.New("duplicate role key")
+	// ErrEmptySpec is returned if extra roles would be deleted by an empty spec, which would remove all roles.
+	ErrEmptySpec = errors.New("refusing to delete all roles of the project with an empty spec")
+	// ErrTooManyDeletions is returned if more roles would be deleted than allowed by [WithMaxDeletions].
+	ErrTooManyDeletions = errors.New("too many roles to delete")
+	// ErrRoleInUse is returned if an extra role is still granted to users, unless [WithForceDelete] is set.
+	ErrRoleInUse = errors.New("role is granted to users")
+)
+
+// RoleSpec is the desired definition of a role.
+type RoleSpec struct {
+	Key string
+	// DisplayName defaults to the Key.
+	DisplayName string
+	Group       string
+}
+
+func (s RoleSpec) displayName() string {
+	if s.DisplayName == "" {
+		return s.Key
+	}
+	return s.DisplayName
+}
+
+// Result lists the keys of the changed roles.
+type Result struct {
+	Added   []string
+	Updated []string
+	Deleted []string
+	// Extra are the roles not in the spec, which are kept since [WithDeleteExtra] is not set.
+	Extra []string
+}
+
+// Changed returns if any role was (resp. would be for a dry run) changed.
+func (r *Result) Changed() bool {
+	return len(r.Added) > 0 || len(r.Updated) > 0 || len(r.Deleted) > 0
+}
+
+// Roles synchronizes the roles of projects.
+type Roles struct {
+	client       *client.Client
+	deleteExtra  bool
+	forceDelete  bool
+	maxDeletions int
+	dryRun       bool
+}
+
+// Option allows customization of the [Roles].
+type Option func(*Roles)
+
+// WithDeleteExtra deletes the roles of the project not in the spec, otherwise they are only reported as extra.
+// Roles still granted to users are not deleted (see [ErrRoleInUse]) unless [WithForceDelete] is set.
+func WithDeleteExtra() Option {
+	return func(r *Roles) {
+		r.deleteExtra = true
+	}
+}
+
+// WithForceDelete deletes extra roles even if they are still granted to users, which removes them from the grants.
+func WithForceDelete() Option {
+	return func(r *Roles) {
+		r.forceDelete = true
+	}
+}
+
+// WithMaxDeletions fails the sync without any change if more than n roles would be deleted (default: no limit).
+func WithMaxDeletions(n int) Option {
+	return func(r *Roles) {
+		r.maxDeletions = n
+	}
+}
+
+// WithDryRun only returns the changes without applying them.
+func WithDryRun() Option {
+	return func(r *Roles) {
+		r.dryRun = true
+	}
+}
+
+// New creates [Roles] using the client.
+func New(client *client.Client, opts ...Option) *Roles {
+	r := &Roles{client: client, maxDeletions: -1}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Sync ensures the roles of the project match the specs: missing roles are added, the display names and groups
+// of existing roles are updated and extra roles are deleted if [WithDeleteExtra] is set.
+// The safety checks (see [ErrEmptySpec], [ErrTooManyDeletions] and [ErrRoleInUse]) are done before any change.
+func (r *Roles) Sync(ctx context.Context, projectID string, specs []RoleSpec) (*Result, error) {
+	return r.sync(ctx, r.client.ManagementService(), projectID, specs)
+}
+
+func (r *Roles) sync(ctx context.Context, mgmt management.ManagementServiceClient, projectID string, specs []RoleSpec) (*Result, error) {
+	if err := validate(specs); err != nil {
+		return nil, err
+	}
+	current, err := listRoles(ctx, mgmt, projectID)
+	if err != nil {
+		return nil, err
+	}
+	p := plan(current, specs)
+	result := &Result{Added: keys(p.add), Updated: keys(p.update), Extra: p.extra}
+	if r.deleteExtra {
+		if err := r.checkDeletions(ctx, mgmt, projectID, specs, p.extra); err != nil {
+			return nil, err
+		}
+		result.Deleted, result.Extra = p.extra, nil
+	}
+	if r.dryRun {
+		return result, nil
+	}
+	if len(p.add) > 0 {
+		if _, err := mgmt.BulkAddProjectRoles(ctx, bulkAddRequest(projectID, p.add)); err != nil {
+			return nil, err
+		}
+	}
+	for _, spec := range p.update {
+		if _, err := mgmt.UpdateProjectRole(ctx, &management.UpdateProjectRoleRequest{
+			ProjectId:   projectID,
+			RoleKey:     spec.Key,
+			DisplayName: spec.displayName(),
+			Group:       spec.Group,
+		}); err != nil {
+			return nil, fmt.Errorf("update role %s: %w", spec.Key, err)
+		}
+	}
+	for _, key := range result.Deleted {
+		if _, err := mgmt.RemoveProjectRole(ctx, &management.RemoveProjectRoleRequest{ProjectId: projectID, RoleKey: key}); err != nil {
+			return nil, fmt.Errorf("delete role %s: %w", key, err)
+		}
+	}
+	return result, nil
+}
+
+func (r *Roles) checkDeletions(ctx context.Context, mgmt management.ManagementServiceClient, projectID string, specs []RoleSpec, extra []string) error {
+	if len(extra) == 0 {
+		return nil
+	}
+	if len(specs) == 0 {
+		return ErrEmptySpec
+	}
+	if r.maxDeletions >= 0 && len(extra) > r.maxDeletions {
+		return fmt.Errorf("%w: %d (max %d)", ErrTooManyDeletions, len(extra), r.maxDeletions)
+	}
+	if r.forceDelete {
+		return nil
+	}
+	for _, key := range extra {
+		resp, err := mgmt.ListUserGrants(ctx, &management.ListUserGrantRequest{
+			Query: &object.ListQuery{Limit: 1},
+			Queries: []*user.UserGrantQuery{
+				{Query: &user.UserGrantQuery_ProjectIdQuery{ProjectIdQuery: &user.UserGrantProjectIDQuery{ProjectId: projectID}}},
+				{Query: &user.UserGrantQuery_RoleKeyQuery{RoleKeyQuery: &user.UserGrantRoleKeyQuery{RoleKey: key, Method: object.TextQueryMethod_TEXT_QUERY_METHOD_EQUALS}}},
+			},
+		})
+		if err != nil {
+			return err
+		}
+		if len(resp.GetResult()) > 0 {
+			return fmt.Errorf("%w: %s", ErrRoleInUse, key)
+		}
+	}
+	return nil
+}
+
+func validate(specs []RoleSpec) error {
+	seen := make(map[string]bool, len(specs))
+	for _, spec := range specs {
+		if spec.Key == "" {
+			return ErrMissingKey
+		}
+		if seen[spec.Key] {
+			return fmt.Errorf("%w: %s", ErrDuplicateKey, spec.Key)
+		}
+		seen[spec.Key] = true
+	}
+	return nil
+}
+
+func listRoles(ctx context.Context, mgmt management.ManagementServiceClient, projectID string) ([]*project.Role, error) {
+	return pager.ListAll(func(query *object.ListQuery) ([]*project.Role, *object.ListDetails, error) {
+		resp, err := mgmt.ListProjectRoles(ctx, &management.ListProjectRolesRequest{ProjectId: projectID, Query: query})
+		return resp.GetResult(), resp.GetDetails(), err
+	})
+}
+
+type syncPlan struct {
+	add    []RoleSpec
+	update []RoleSpec
+	extra  []string
+}
+
+// plan compares the current roles with the specs, the extra roles are sorted by key.
+func plan(current []*project.Role, specs []RoleSpec) syncPlan {
+	existing := make(map[string]*project.Role, len(current))
+	for _, role := range current {
+		existing[role.GetKey()] = role
+	}
+	var p syncPlan
+	for _, spec := range specs {
+		role, ok := existing[spec.Key]
+		if !ok {
+			p.add = append(p.add, spec)
+			continue
+		}
+		delete(existing, spec.Key)
+		if role.GetDisplayName() != spec.displayName() || role.GetGroup() != spec.Group {
+			p.update = append(p.update, spec)
+		}
+	}
+	for key := range existing {
+		p.extra = append(p.extra, key)
+	}
+	slices.Sort(p.extra)
+	return p
+}
+
+func bulkAddRequest(projectID string, specs []RoleSpec) *management.BulkAddProjectRolesRequest {
+	req := &management.BulkAddProjectRolesRequest{
+		ProjectId: projectID,
+		Roles:     make([]*management.BulkAddProjectRolesRequest_Role, len(specs)),
+	}
+	for i, spec := range specs {
+		req.Roles[i] = &management.BulkAddProjectRolesRequest_Role{Key: spec.Key, DisplayName: spec.displayName(), Group: spec.Group}
+	}
+	return req
+}
+
+func keys(specs []RoleSpec) []string {
+	if len(specs) == 0 {
+		return nil
+	}
+	keys := make([]string, len(specs))
+	for i, spec := range specs {
+		keys[i] = spec.Key
+	}
+	return keys
+}
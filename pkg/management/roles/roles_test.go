@@ -0,0 +1,159 @@
+package roles
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/management"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/project"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/user"
+)
+
+type fakeManagement struct {
+	management.ManagementServiceClient
+	roles   []*project.Role
+	granted map[string]bool
+
+	added   []string
+	updated []string
+	removed []string
+}
+
+func (f *fakeManagement) ListProjectRoles(context.Context, *management.ListProjectRolesRequest, ...grpc.CallOption) (*management.ListProjectRolesResponse, error) {
+	return &management.ListProjectRolesResponse{Result: f.roles}, nil
+}
+
+func (f *fakeManagement) ListUserGrants(_ context.Context, req *management.ListUserGrantRequest, _ ...grpc.CallOption) (*management.ListUserGrantResponse, error) {
+	key := req.GetQueries()[1].GetRoleKeyQuery().GetRoleKey()
+	if f.granted[key] {
+		return &management.ListUserGrantResponse{Result: []*user.UserGrant{{RoleKeys: []string{key}}}}, nil
+	}
+	return &management.ListUserGrantResponse{}, nil
+}
+
+func (f *fakeManagement) BulkAddProjectRoles(_ context.Context, req *management.BulkAddProjectRolesRequest, _ ...grpc.CallOption) (*management.BulkAddProjectRolesResponse, error) {
+	for _, role := range req.GetRoles() {
+		f.added = append(f.added, role.GetKey()+":"+role.GetDisplayName())
+	}
+	return &management.BulkAddProjectRolesResponse{}, nil
+}
+
+func (f *fakeManagement) UpdateProjectRole(_ context.Context, req *management.UpdateProjectRoleRequest, _ ...grpc.CallOption) (*management.UpdateProjectRoleResponse, error) {
+	f.updated = append(f.updated, req.GetRoleKey()+":"+req.GetDisplayName()+":"+req.GetGroup())
+	return &management.UpdateProjectRoleResponse{}, nil
+}
+
+func (f *fakeManagement) RemoveProjectRole(_ context.Context, req *management.RemoveProjectRoleRequest, _ ...grpc.CallOption) (*management.RemoveProjectRoleResponse, error) {
+	f.removed = append(f.removed, req.GetRoleKey())
+	return &management.RemoveProjectRoleResponse{}, nil
+}
+
+func TestRoles_Sync(t *testing.T) {
+	specs := []RoleSpec{
+		{Key: "admin", DisplayName: "Administrator"},
+		{Key: "editor", Group: "write"},
+		{Key: "viewer"},
+	}
+	current := func() []*project.Role {
+		return []*project.Role{
+			{Key: "admin", DisplayName: "Administrator"},
+			{Key: "editor", DisplayName: "editor"},
+			{Key: "legacy", DisplayName: "Legacy"},
+			{Key: "old", DisplayName: "Old"},
+		}
+	}
+	tests := []struct {
+		name        string
+		opts        []Option
+		specs       []RoleSpec
+		granted     map[string]bool
+		want        *Result
+		wantErr     error
+		wantAdded   []string
+		wantUpdated []string
+		wantRemoved []string
+	}{
+		{
+			name:        "keep extra",
+			specs:       specs,
+			want:        &Result{Added: []string{"viewer"}, Updated: []string{"editor"}, Extra: []string{"legacy", "old"}},
+			wantAdded:   []string{"viewer:viewer"},
+			wantUpdated: []string{"editor:editor:write"},
+		},
+		{
+			name:        "delete extra",
+			opts:        []Option{WithDeleteExtra()},
+			specs:       specs,
+			want:        &Result{Added: []string{"viewer"}, Updated: []string{"editor"}, Deleted: []string{"legacy", "old"}},
+			wantAdded:   []string{"viewer:viewer"},
+			wantUpdated: []string{"editor:editor:write"},
+			wantRemoved: []string{"legacy", "old"},
+		},
+		{
+			name:  "dry run",
+			opts:  []Option{WithDeleteExtra(), WithDryRun()},
+			specs: specs,
+			want:  &Result{Added: []string{"viewer"}, Updated: []string{"editor"}, Deleted: []string{"legacy", "old"}},
+		},
+		{
+			name:    "role in use",
+			opts:    []Option{WithDeleteExtra()},
+			specs:   specs,
+			granted: map[string]bool{"old": true},
+			wantErr: ErrRoleInUse,
+		},
+		{
+			name:        "force delete role in use",
+			opts:        []Option{WithDeleteExtra(), WithForceDelete()},
+			specs:       specs,
+			granted:     map[string]bool{"old": true},
+			want:        &Result{Added: []string{"viewer"}, Updated: []string{"editor"}, Deleted: []string{"legacy", "old"}},
+			wantAdded:   []string{"viewer:viewer"},
+			wantUpdated: []string{"editor:editor:write"},
+			wantRemoved: []string{"legacy", "old"},
+		},
+		{
+			name:    "too many deletions",
+			opts:    []Option{WithDeleteExtra(), WithMaxDeletions(1)},
+			specs:   specs,
+			wantErr: ErrTooManyDeletions,
+		},
+		{
+			name:    "empty spec",
+			opts:    []Option{WithDeleteExtra()},
+			wantErr: ErrEmptySpec,
+		},
+		{
+			name:    "duplicate key",
+			specs:   []RoleSpec{{Key: "admin"}, {Key: "admin"}},
+			wantErr: ErrDuplicateKey,
+		},
+		{
+			name:    "missing key",
+			specs:   []RoleSpec{{DisplayName: "Admin"}},
+			wantErr: ErrMissingKey,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mgmt := &fakeManagement{roles: current(), granted: tt.granted}
+			got, err := New(nil, tt.opts...).sync(context.Background(), mgmt, "project", tt.specs)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				assert.Empty(t, mgmt.added)
+				assert.Empty(t, mgmt.updated)
+				assert.Empty(t, mgmt.removed)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantAdded, mgmt.added)
+			assert.Equal(t, tt.wantUpdated, mgmt.updated)
+			assert.Equal(t, tt.wantRemoved, mgmt.removed)
+		})
+	}
+}
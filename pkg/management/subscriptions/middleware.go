@@ -0,0 +1,83 @@
+package subscriptions
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/authorization"
+)
+
+// organizationID returns the organization of the authorized caller.
+func organizationID(ctx context.Context) string {
+	authCtx := authorization.Context[authorization.Ctx](ctx)
+	if authCtx == nil {
+		return ""
+	}
+	return authCtx.OrganizationID()
+}
+
+// RequireActive is an HTTP middleware denying the requests of callers, whose organization's subscription expired,
+// with 402 Payment Required.
+// It must be used after the authorization middleware, since the organization is taken from the authorization context.
+// Unauthorized requests are passed on.
+func (s *Subscriptions) RequireActive() func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			orgID := organizationID(req.Context())
+			if orgID == "" {
+				next.ServeHTTP(w, req)
+				return
+			}
+			if err := s.Check(req.Context(), orgID); err != nil {
+				if errors.Is(err, ErrExpired) || errors.Is(err, ErrNoSubscription) {
+					http.Error(w, err.Error(), http.StatusPaymentRequired)
+					return
+				}
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			next.ServeHTTP(w, req)
+		})
+	}
+}
+
+// UnaryServerInterceptor is the gRPC equivalent of [Subscriptions.RequireActive],
+// denying the calls with codes.PermissionDenied.
+func (s *Subscriptions) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if err := s.check(ctx); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor is the gRPC equivalent of [Subscriptions.RequireActive] for streams.
+func (s *Subscriptions) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := s.check(stream.Context()); err != nil {
+			return err
+		}
+		return handler(srv, stream)
+	}
+}
+
+func (s *Subscriptions) check(ctx context.Context) error {
+	orgID := organizationID(ctx)
+	if orgID == "" {
+		return nil
+	}
+	err := s.Check(ctx, orgID)
+	if errors.Is(err, ErrExpired) || errors.Is(err, ErrNoSubscription) {
+		return status.Error(codes.PermissionDenied, err.Error())
+	}
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,379 @@
+// Package subscriptions tags organizations with their plan and trial, stored as JSON in the metadata of the organization.
+// It computes the expirations, enforces them (see [Subscriptions.Check] and the middlewares)
+// and emits [Event]s for billing systems, a common pattern for SaaS built on top of ZITADEL organizations.
+package subscriptions
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/admin"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/management"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/object"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/org"
+	"github.com/zitadel/zitadel-go/v3/pkg/internal/pager"
+)
+
+const (
+	// DefaultMetadataKey is the metadata key of the subscription of an organization.
+	DefaultMetadataKey = "subscription"
+	// DefaultCacheTTL is the duration the subscription of an organization is cached for the checks.
+	DefaultCacheTTL = time.Minute
+)
+
+var (
+	ErrMissingOrgID   = errors.New("organization ID is required")
+	ErrMissingPlan    = errors.New("plan is required")
+	ErrExpired        = errors.New("subscription of the organization expired")
+	ErrNoSubscription = errors.New("organization has no subscription")
+)
+
+// Status of a [Subscription].
+type Status string
+
+const (
+	StatusTrial    Status = "trial"
+	StatusActive   Status = "active"
+	StatusCanceled Status = "canceled"
+	StatusExpired  Status = "expired"
+)
+
+// Subscription is the plan of an organization.
+type Subscription struct {
+	Plan   string `json:"plan"`
+	Status Status `json:"status"`
+	// TrialEndsAt is the end of the trial, zero if the subscription is no trial.
+	TrialEndsAt time.Time `json:"trialEndsAt"`
+	// ExpiresAt is the end of the subscription, zero if it does not expire.
+	ExpiresAt  time.Time `json:"expiresAt"`
+	CanceledAt time.Time `json:"canceledAt"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+// Expired returns if the subscription is expired at the time.
+func (s *Subscription) Expired(now time.Time) bool {
+	return s.Status == StatusExpired || !s.ExpiresAt.IsZero() && !now.Before(s.ExpiresAt)
+}
+
+// IsTrial returns if the subscription is a running trial at the time.
+func (s *Subscription) IsTrial(now time.Time) bool {
+	return s.Status == StatusTrial && !s.Expired(now)
+}
+
+// Remaining returns the duration until the subscription expires, zero if it's expired and negative if it does not expire.
+func (s *Subscription) Remaining(now time.Time) time.Duration {
+	if s.ExpiresAt.IsZero() && s.Status != StatusExpired {
+		return -1
+	}
+	if s.Expired(now) {
+		return 0
+	}
+	return s.ExpiresAt.Sub(now)
+}
+
+// EventType describes the change of a subscription.
+type EventType string
+
+const (
+	EventTrialStarted EventType = "trial.started"
+	EventPlanChanged  EventType = "plan.changed"
+	EventCanceled     EventType = "canceled"
+	EventExpired      EventType = "expired"
+)
+
+// Event is emitted for every change of a subscription (see [WithCallback]).
+type Event struct {
+	Type           EventType
+	OrganizationID string
+	Subscription   *Subscription
+	// Previous is the subscription before the change, nil if there was none.
+	Previous *Subscription
+}
+
+// Subscriptions manages the subscriptions of the organizations.
+type Subscriptions struct {
+	client      *client.Client
+	metadataKey string
+	cacheTTL    time.Duration
+	gracePeriod time.Duration
+	required    bool
+	callbacks   []func(ctx context.Context, event *Event)
+	now         func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]*cachedSubscription
+}
+
+type cachedSubscription struct {
+	subscription *Subscription
+	loadedAt     time.Time
+}
+
+// Option allows customization of the [Subscriptions].
+type Option func(*Subscriptions)
+
+// WithMetadataKey sets the metadata key of the subscription (default `subscription`).
+func WithMetadataKey(key string) Option {
+	return func(s *Subscriptions) {
+		s.metadataKey = key
+	}
+}
+
+// WithCacheTTL sets the duration the subscription of an organization is cached for the checks (default 1 minute).
+// A zero duration disables the cache.
+func WithCacheTTL(ttl time.Duration) Option {
+	return func(s *Subscriptions) {
+		s.cacheTTL = ttl
+	}
+}
+
+// WithGracePeriod allows the access for the duration after the subscription expired, e.g. to wait for a payment.
+func WithGracePeriod(gracePeriod time.Duration) Option {
+	return func(s *Subscriptions) {
+		s.gracePeriod = gracePeriod
+	}
+}
+
+// WithRequiredSubscription denies the access of organizations without subscription ([ErrNoSubscription]),
+// by default they are not restricted.
+func WithRequiredSubscription() Option {
+	return func(s *Subscriptions) {
+		s.required = true
+	}
+}
+
+// WithCallback calls the function for every change of a subscription, e.g. to inform the billing system.
+// The callbacks are called synchronously after the change was stored.
+func WithCallback(callback func(ctx context.Context, event *Event)) Option {
+	return func(s *Subscriptions) {
+		s.callbacks = append(s.callbacks, callback)
+	}
+}
+
+// New creates [Subscriptions] using the client.
+// The client needs the permission to manage the metadata of the organizations
+// and to list all organizations for [Subscriptions.ExpireDue].
+func New(client *client.Client, opts ...Option) *Subscriptions {
+	s := &Subscriptions{
+		client:      client,
+		metadataKey: DefaultMetadataKey,
+		cacheTTL:    DefaultCacheTTL,
+		now:         time.Now,
+		cache:       make(map[string]*cachedSubscription),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Get returns the subscription of the organization or [ErrNoSubscription].
+func (s *Subscriptions) Get(ctx context.Context, orgID string) (*Subscription, error) {
+	if orgID == "" {
+		return nil, ErrMissingOrgID
+	}
+	resp, err := s.client.ForOrganization(orgID).ManagementService().GetOrgMetadata(ctx, &management.GetOrgMetadataRequest{
+		Key: s.metadataKey,
+	})
+	if status.Code(err) == codes.NotFound {
+		return nil, ErrNoSubscription
+	}
+	if err != nil {
+		return nil, err
+	}
+	subscription := new(Subscription)
+	if err = json.Unmarshal(resp.GetMetadata().GetValue(), subscription); err != nil {
+		return nil, fmt.Errorf("invalid subscription of organization %s: %w", orgID, err)
+	}
+	return subscription, nil
+}
+
+// StartTrial starts a trial of the plan for the duration.
+func (s *Subscriptions) StartTrial(ctx context.Context, orgID, plan string, duration time.Duration) (*Subscription, error) {
+	now := s.now()
+	return s.update(ctx, orgID, EventTrialStarted, func(*Subscription) (*Subscription, error) {
+		if plan == "" {
+			return nil, ErrMissingPlan
+		}
+		return &Subscription{
+			Plan:        plan,
+			Status:      StatusTrial,
+			TrialEndsAt: now.Add(duration),
+			ExpiresAt:   now.Add(duration),
+		}, nil
+	})
+}
+
+// SetPlan activates the plan until the expiry, a zero expiry never expires.
+// A running trial is ended.
+func (s *Subscriptions) SetPlan(ctx context.Context, orgID, plan string, expiresAt time.Time) (*Subscription, error) {
+	return s.update(ctx, orgID, EventPlanChanged, func(*Subscription) (*Subscription, error) {
+		if plan == "" {
+			return nil, ErrMissingPlan
+		}
+		return &Subscription{Plan: plan, Status: StatusActive, ExpiresAt: expiresAt}, nil
+	})
+}
+
+// Cancel cancels the subscription at the time, e.g. the end of the paid period.
+// A zero time cancels the subscription immediately.
+func (s *Subscriptions) Cancel(ctx context.Context, orgID string, at time.Time) (*Subscription, error) {
+	now := s.now()
+	return s.update(ctx, orgID, EventCanceled, func(previous *Subscription) (*Subscription, error) {
+		if previous == nil {
+			return nil, ErrNoSubscription
+		}
+		canceled := *previous
+		canceled.Status = StatusCanceled
+		canceled.CanceledAt = now
+		if at.IsZero() {
+			at = now
+		}
+		if canceled.ExpiresAt.IsZero() || at.Before(canceled.ExpiresAt) {
+			canceled.ExpiresAt = at
+		}
+		return &canceled, nil
+	})
+}
+
+// Check returns [ErrExpired] if the subscription of the organization expired (including the grace period).
+// It can be used to block logins of expired organizations, e.g. in the login UI or an action,
+// the middlewares use it to block the API access.
+// The subscriptions are cached (see [WithCacheTTL]).
+func (s *Subscriptions) Check(ctx context.Context, orgID string) error {
+	subscription, err := s.cached(ctx, orgID)
+	if errors.Is(err, ErrNoSubscription) && !s.required {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if subscription.Expired(s.now().Add(-s.gracePeriod)) {
+		return ErrExpired
+	}
+	return nil
+}
+
+// ExpireDue marks the expired subscriptions of all organizations as [StatusExpired]
+// and emits an [EventExpired] for each of them once.
+func (s *Subscriptions) ExpireDue(ctx context.Context) error {
+	orgIDs, err := s.organizationIDs(ctx)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, orgID := range orgIDs {
+		_, err = s.update(ctx, orgID, EventExpired, func(previous *Subscription) (*Subscription, error) {
+			if previous == nil || previous.Status == StatusExpired || !previous.Expired(s.now()) {
+				return nil, nil
+			}
+			expired := *previous
+			expired.Status = StatusExpired
+			return &expired, nil
+		})
+		if err != nil && !errors.Is(err, ErrNoSubscription) {
+			errs = append(errs, fmt.Errorf("organization %s: %w", orgID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// RunExpirations calls [Subscriptions.ExpireDue] in the interval until the context is done.
+// Errors are passed to the onError function (if set), the expirations are retried in the next interval.
+func (s *Subscriptions) RunExpirations(ctx context.Context, interval time.Duration, onError func(error)) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := s.ExpireDue(ctx); err != nil && onError != nil {
+			onError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Invalidate removes the cached subscription of the organization.
+func (s *Subscriptions) Invalidate(orgID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.cache, orgID)
+}
+
+// update stores the subscription returned by change and emits the event.
+// Nothing is stored if change returns nil.
+func (s *Subscriptions) update(ctx context.Context, orgID string, eventType EventType, change func(previous *Subscription) (*Subscription, error)) (*Subscription, error) {
+	previous, err := s.Get(ctx, orgID)
+	if err != nil && !errors.Is(err, ErrNoSubscription) {
+		return nil, err
+	}
+	subscription, err := change(previous)
+	if err != nil || subscription == nil {
+		return subscription, err
+	}
+	subscription.UpdatedAt = s.now()
+	value, err := json.Marshal(subscription)
+	if err != nil {
+		return nil, err
+	}
+	_, err = s.client.ForOrganization(orgID).ManagementService().SetOrgMetadata(ctx, &management.SetOrgMetadataRequest{
+		Key:   s.metadataKey,
+		Value: value,
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.Invalidate(orgID)
+	event := &Event{Type: eventType, OrganizationID: orgID, Subscription: subscription, Previous: previous}
+	for _, callback := range s.callbacks {
+		callback(ctx, event)
+	}
+	return subscription, nil
+}
+
+func (s *Subscriptions) cached(ctx context.Context, orgID string) (*Subscription, error) {
+	s.mu.Lock()
+	cached, ok := s.cache[orgID]
+	s.mu.Unlock()
+	if ok && s.now().Sub(cached.loadedAt) < s.cacheTTL {
+		if cached.subscription == nil {
+			return nil, ErrNoSubscription
+		}
+		return cached.subscription, nil
+	}
+	subscription, err := s.Get(ctx, orgID)
+	if err != nil && !errors.Is(err, ErrNoSubscription) {
+		return nil, err
+	}
+	if s.cacheTTL > 0 {
+		s.mu.Lock()
+		s.cache[orgID] = &cachedSubscription{subscription: subscription, loadedAt: s.now()}
+		s.mu.Unlock()
+	}
+	return subscription, err
+}
+
+func (s *Subscriptions) organizationIDs(ctx context.Context) ([]string, error) {
+	orgs, err := pager.ListAll(func(query *object.ListQuery) ([]*org.Org, *object.ListDetails, error) {
+		resp, err := s.client.AdminService().ListOrgs(ctx, &admin.ListOrgsRequest{Query: query})
+		return resp.GetResult(), resp.GetDetails(), err
+	})
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(orgs))
+	for i, org := range orgs {
+		ids[i] = org.GetId()
+	}
+	return ids, nil
+}
@@ -0,0 +1,90 @@
+package subscriptions
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubscription(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name          string
+		subscription  *Subscription
+		wantExpired   bool
+		wantTrial     bool
+		wantRemaining time.Duration
+	}{
+		{
+			name:          "running trial",
+			subscription:  &Subscription{Status: StatusTrial, TrialEndsAt: now.Add(time.Hour), ExpiresAt: now.Add(time.Hour)},
+			wantTrial:     true,
+			wantRemaining: time.Hour,
+		},
+		{
+			name:         "ended trial",
+			subscription: &Subscription{Status: StatusTrial, TrialEndsAt: now, ExpiresAt: now},
+			wantExpired:  true,
+		},
+		{
+			name:          "active without expiry",
+			subscription:  &Subscription{Status: StatusActive},
+			wantRemaining: -1,
+		},
+		{
+			name:         "marked expired",
+			subscription: &Subscription{Status: StatusExpired},
+			wantExpired:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wantExpired, tt.subscription.Expired(now))
+			assert.Equal(t, tt.wantTrial, tt.subscription.IsTrial(now))
+			assert.Equal(t, tt.wantRemaining, tt.subscription.Remaining(now))
+		})
+	}
+}
+
+func TestSubscriptions_Check(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		opts    []Option
+		cached  *Subscription
+		wantErr error
+	}{
+		{
+			name:   "active",
+			cached: &Subscription{Status: StatusActive, ExpiresAt: now.Add(time.Hour)},
+		},
+		{
+			name:    "expired",
+			cached:  &Subscription{Status: StatusCanceled, ExpiresAt: now.Add(-time.Hour)},
+			wantErr: ErrExpired,
+		},
+		{
+			name:   "expired within grace period",
+			opts:   []Option{WithGracePeriod(2 * time.Hour)},
+			cached: &Subscription{Status: StatusCanceled, ExpiresAt: now.Add(-time.Hour)},
+		},
+		{
+			name: "no subscription",
+		},
+		{
+			name:    "no subscription, but required",
+			opts:    []Option{WithRequiredSubscription()},
+			wantErr: ErrNoSubscription,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := New(nil, tt.opts...)
+			s.now = func() time.Time { return now }
+			s.cache["org"] = &cachedSubscription{subscription: tt.cached, loadedAt: now}
+			assert.ErrorIs(t, s.Check(context.Background(), "org"), tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package texts
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/admin"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/management"
+)
+
+// Kinds of the custom texts, used as the file names (without extension).
+const (
+	KindLogin                    = "login"
+	KindInit                     = "init"
+	KindPasswordReset            = "password_reset"
+	KindVerifyEmail              = "verify_email"
+	KindVerifyPhone              = "verify_phone"
+	KindVerifySMSOTP             = "verify_sms_otp"
+	KindVerifyEmailOTP           = "verify_email_otp"
+	KindDomainClaimed            = "domain_claimed"
+	KindPasswordlessRegistration = "passwordless_registration"
+	KindPasswordChange           = "password_change"
+	KindInviteUser               = "invite_user"
+)
+
+type getFunc[S any] func(ctx context.Context, service S, language string) (proto.Message, error)
+
+type setFunc[S any] func(ctx context.Context, service S, language string, values map[string]any) error
+
+// kind describes how to read and write the custom texts of a kind for organizations (management API)
+// and the instance (admin API).
+type kind struct {
+	name     string
+	orgGet   getFunc[management.ManagementServiceClient]
+	orgSet   setFunc[management.ManagementServiceClient]
+	adminGet getFunc[admin.AdminServiceClient]
+	adminSet setFunc[admin.AdminServiceClient]
+}
+
+// Kinds returns the kinds of the custom texts.
+func Kinds() []string {
+	names := make([]string, len(kinds))
+	for i, k := range kinds {
+		names[i] = k.name
+	}
+	return names
+}
+
+var kinds = []kind{
+	{
+		KindLogin,
+		get(management.ManagementServiceClient.GetCustomLoginTexts),
+		set(management.ManagementServiceClient.SetCustomLoginText),
+		get(admin.AdminServiceClient.GetCustomLoginTexts),
+		set(admin.AdminServiceClient.SetCustomLoginText),
+	},
+	{
+		KindInit,
+		get(management.ManagementServiceClient.GetCustomInitMessageText),
+		set(management.ManagementServiceClient.SetCustomInitMessageText),
+		get(admin.AdminServiceClient.GetCustomInitMessageText),
+		set(admin.AdminServiceClient.SetDefaultInitMessageText),
+	},
+	{
+		KindPasswordReset,
+		get(management.ManagementServiceClient.GetCustomPasswordResetMessageText),
+		set(management.ManagementServiceClient.SetCustomPasswordResetMessageText),
+		get(admin.AdminServiceClient.GetCustomPasswordResetMessageText),
+		set(admin.AdminServiceClient.SetDefaultPasswordResetMessageText),
+	},
+	{
+		KindVerifyEmail,
+		get(management.ManagementServiceClient.GetCustomVerifyEmailMessageText),
+		set(management.ManagementServiceClient.SetCustomVerifyEmailMessageText),
+		get(admin.AdminServiceClient.GetCustomVerifyEmailMessageText),
+		set(admin.AdminServiceClient.SetDefaultVerifyEmailMessageText),
+	},
+	{
+		KindVerifyPhone,
+		get(management.ManagementServiceClient.GetCustomVerifyPhoneMessageText),
+		set(management.ManagementServiceClient.SetCustomVerifyPhoneMessageText),
+		get(admin.AdminServiceClient.GetCustomVerifyPhoneMessageText),
+		set(admin.AdminServiceClient.SetDefaultVerifyPhoneMessageText),
+	},
+	{
+		KindVerifySMSOTP,
+		get(management.ManagementServiceClient.GetCustomVerifySMSOTPMessageText),
+		set(management.ManagementServiceClient.SetCustomVerifySMSOTPMessageText),
+		get(admin.AdminServiceClient.GetCustomVerifySMSOTPMessageText),
+		set(admin.AdminServiceClient.SetDefaultVerifySMSOTPMessageText),
+	},
+	{
+		KindVerifyEmailOTP,
+		get(management.ManagementServiceClient.GetCustomVerifyEmailOTPMessageText),
+		set(management.ManagementServiceClient.SetCustomVerifyEmailOTPMessageText),
+		get(admin.AdminServiceClient.GetCustomVerifyEmailOTPMessageText),
+		set(admin.AdminServiceClient.SetDefaultVerifyEmailOTPMessageText),
+	},
+	{
+		KindDomainClaimed,
+		get(management.ManagementServiceClient.GetCustomDomainClaimedMessageText),
+		set(management.ManagementServiceClient.SetCustomDomainClaimedMessageCustomText),
+		get(admin.AdminServiceClient.GetCustomDomainClaimedMessageText),
+		set(admin.AdminServiceClient.SetDefaultDomainClaimedMessageText),
+	},
+	{
+		KindPasswordlessRegistration,
+		get(management.ManagementServiceClient.GetCustomPasswordlessRegistrationMessageText),
+		set(management.ManagementServiceClient.SetCustomPasswordlessRegistrationMessageCustomText),
+		get(admin.AdminServiceClient.GetCustomPasswordlessRegistrationMessageText),
+		set(admin.AdminServiceClient.SetDefaultPasswordlessRegistrationMessageText),
+	},
+	{
+		KindPasswordChange,
+		get(management.ManagementServiceClient.GetCustomPasswordChangeMessageText),
+		set(management.ManagementServiceClient.SetCustomPasswordChangeMessageCustomText),
+		get(admin.AdminServiceClient.GetCustomPasswordChangeMessageText),
+		set(admin.AdminServiceClient.SetDefaultPasswordChangeMessageText),
+	},
+	{
+		KindInviteUser,
+		get(management.ManagementServiceClient.GetCustomInviteUserMessageText),
+		set(management.ManagementServiceClient.SetCustomInviteUserMessageCustomText),
+		get(admin.AdminServiceClient.GetCustomInviteUserMessageText),
+		set(admin.AdminServiceClient.SetDefaultInviteUserMessageText),
+	},
+}
+
+// get wraps the RPC returning the custom texts of a language (in the field `custom_text`).
+func get[S any, Req, Resp proto.Message](call func(S, context.Context, Req, ...grpc.CallOption) (Resp, error)) getFunc[S] {
+	return func(ctx context.Context, service S, language string) (proto.Message, error) {
+		req, err := newRequest[Req](language, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := call(service, ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		msg := resp.ProtoReflect()
+		field := msg.Descriptor().Fields().ByName(customTextField)
+		if field == nil || !msg.Has(field) {
+			return nil, nil
+		}
+		text := msg.Get(field).Message()
+		// default texts are not custom texts of the organization resp. instance
+		if isDefault := text.Descriptor().Fields().ByName(isDefaultField); isDefault != nil && text.Get(isDefault).Bool() {
+			return nil, nil
+		}
+		return text.Interface(), nil
+	}
+}
+
+// set wraps the RPC setting the custom texts of a language.
+func set[S any, Req, Resp proto.Message](call func(S, context.Context, Req, ...grpc.CallOption) (Resp, error)) setFunc[S] {
+	return func(ctx context.Context, service S, language string, values map[string]any) error {
+		req, err := newRequest[Req](language, values)
+		if err != nil {
+			return err
+		}
+		_, err = call(service, ctx, req)
+		return err
+	}
+}
@@ -0,0 +1,282 @@
+// Package texts synchronizes the custom login and notification texts of an organization or the instance
+// with a directory of YAML files, e.g. to manage the translations in git (GitOps):
+//
+//	t := texts.New(client)
+//	written, err := t.Export(ctx, orgID, "texts")
+//	changes, err := t.Import(ctx, orgID, "texts")
+//
+// The directory contains a subdirectory per language with a file per kind of text (see [KindLogin] etc.),
+// e.g. `texts/de/login.yaml` or `texts/en/password_reset.yaml`. The keys of the files are the field names
+// of the texts in the API (e.g. `select_account_text.title` of the login texts or `subject` of the messages).
+//
+// All methods use the texts of the organization with the orgID, the ones of the instance for an empty orgID.
+package texts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"gopkg.in/yaml.v3"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/admin"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/management"
+)
+
+const (
+	fileExtension   = ".yaml"
+	customTextField = "custom_text"
+	languageField   = "language"
+	isDefaultField  = "is_default"
+)
+
+var ErrInvalidText = errors.New("invalid text")
+
+// ignoredFields are the fields of the custom texts, which are not texts.
+var ignoredFields = []string{"details", isDefaultField, languageField}
+
+// Change describes the changed texts of a kind and language.
+type Change struct {
+	Kind     string
+	Language string
+	// Keys are the added, changed and removed keys, e.g. `select_account_text.title`.
+	Keys []string
+}
+
+// Texts synchronizes the custom texts with YAML files, see the package documentation.
+type Texts struct {
+	client *client.Client
+}
+
+// New creates a [Texts] using the client.
+func New(client *client.Client) *Texts {
+	return &Texts{client: client}
+}
+
+// Export writes the custom texts of all supported languages into the directory
+// and returns the paths of the written files. Files with unchanged content are not written
+// and kinds without custom texts are skipped.
+func (t *Texts) Export(ctx context.Context, orgID, dir string) ([]string, error) {
+	languages, err := t.supportedLanguages(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	var written []string
+	for _, language := range languages {
+		for _, k := range kinds {
+			values, err := t.get(ctx, orgID, k, language)
+			if err != nil {
+				return written, fmt.Errorf("%s (%s): %w", k.name, language, err)
+			}
+			if len(values) == 0 {
+				continue
+			}
+			path := filepath.Join(dir, language, k.name+fileExtension)
+			changed, err := writeFile(path, values)
+			if err != nil {
+				return written, err
+			}
+			if changed {
+				written = append(written, path)
+			}
+		}
+	}
+	return written, nil
+}
+
+// Diff returns the changes [Texts.Import] would apply, without applying them.
+func (t *Texts) Diff(ctx context.Context, orgID, dir string) ([]Change, error) {
+	return t.sync(ctx, orgID, dir, false)
+}
+
+// Import sets the texts of the files in the directory and returns the applied changes.
+// Only the kinds and languages of texts differing from the current ones are set.
+// Texts of kinds and languages without a file are left unchanged, keys missing in a file are removed.
+func (t *Texts) Import(ctx context.Context, orgID, dir string) ([]Change, error) {
+	return t.sync(ctx, orgID, dir, true)
+}
+
+func (t *Texts) sync(ctx context.Context, orgID, dir string, apply bool) ([]Change, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var changes []Change
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		language := entry.Name()
+		for _, k := range kinds {
+			desired, err := readFile(filepath.Join(dir, language, k.name+fileExtension))
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return changes, err
+			}
+			current, err := t.get(ctx, orgID, k, language)
+			if err != nil {
+				return changes, fmt.Errorf("%s (%s): %w", k.name, language, err)
+			}
+			keys := diff(current, desired)
+			if len(keys) == 0 {
+				continue
+			}
+			if apply {
+				if err := t.set(ctx, orgID, k, language, desired); err != nil {
+					return changes, fmt.Errorf("%s (%s): %w", k.name, language, err)
+				}
+			}
+			changes = append(changes, Change{Kind: k.name, Language: language, Keys: keys})
+		}
+	}
+	return changes, nil
+}
+
+func (t *Texts) supportedLanguages(ctx context.Context, orgID string) ([]string, error) {
+	if orgID == "" {
+		resp, err := t.client.AdminService().GetSupportedLanguages(ctx, &admin.GetSupportedLanguagesRequest{})
+		return resp.GetLanguages(), err
+	}
+	resp, err := t.client.ForOrganization(orgID).ManagementService().GetSupportedLanguages(ctx, &management.GetSupportedLanguagesRequest{})
+	return resp.GetLanguages(), err
+}
+
+func (t *Texts) get(ctx context.Context, orgID string, k kind, language string) (map[string]any, error) {
+	var msg proto.Message
+	var err error
+	if orgID == "" {
+		msg, err = k.adminGet(ctx, t.client.AdminService(), language)
+	} else {
+		msg, err = k.orgGet(ctx, t.client.ForOrganization(orgID).ManagementService(), language)
+	}
+	if err != nil || msg == nil {
+		return nil, err
+	}
+	return textValues(msg)
+}
+
+func (t *Texts) set(ctx context.Context, orgID string, k kind, language string, values map[string]any) error {
+	if orgID == "" {
+		return k.adminSet(ctx, t.client.AdminService(), language, values)
+	}
+	return k.orgSet(ctx, t.client.ForOrganization(orgID).ManagementService(), language, values)
+}
+
+// textValues returns the texts of the message as (nested) map using the field names of the proto.
+func textValues(msg proto.Message) (map[string]any, error) {
+	data, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]any)
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, err
+	}
+	for _, field := range ignoredFields {
+		delete(values, field)
+	}
+	return values, nil
+}
+
+// newRequest creates the request of the language with the texts.
+func newRequest[Req proto.Message](language string, values map[string]any) (Req, error) {
+	var zero Req
+	req := zero.ProtoReflect().Type().New().Interface().(Req)
+	if len(values) > 0 {
+		data, err := json.Marshal(values)
+		if err != nil {
+			return zero, err
+		}
+		if err := protojson.Unmarshal(data, req); err != nil {
+			return zero, fmt.Errorf("%w: %v", ErrInvalidText, err)
+		}
+	}
+	msg := req.ProtoReflect()
+	if field := msg.Descriptor().Fields().ByName(languageField); field != nil {
+		msg.Set(field, protoreflect.ValueOfString(language))
+	}
+	return req, nil
+}
+
+// diff returns the sorted keys of the texts differing between current and desired.
+func diff(current, desired map[string]any) []string {
+	currentKeys, desiredKeys := flatten("", current, nil), flatten("", desired, nil)
+	var keys []string
+	for key, value := range desiredKeys {
+		if currentKeys[key] != value {
+			keys = append(keys, key)
+		}
+	}
+	for key := range currentKeys {
+		if _, ok := desiredKeys[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// flatten returns the non-empty values of the nested map by their dot separated keys.
+func flatten(prefix string, values map[string]any, flat map[string]string) map[string]string {
+	if flat == nil {
+		flat = make(map[string]string)
+	}
+	for key, value := range values {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		switch v := value.(type) {
+		case map[string]any:
+			flatten(key, v, flat)
+		case nil:
+		default:
+			if s := fmt.Sprint(v); s != "" {
+				flat[key] = s
+			}
+		}
+	}
+	return flat
+}
+
+func readFile(path string) (map[string]any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]any)
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return values, nil
+}
+
+// writeFile writes the texts into the file, unless it already contains them, and returns if it was written.
+func writeFile(path string, values map[string]any) (bool, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(values); err != nil {
+		return false, err
+	}
+	if err := encoder.Close(); err != nil {
+		return false, err
+	}
+	if existing, err := os.ReadFile(path); err == nil && bytes.Equal(existing, buf.Bytes()) {
+		return false, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return false, err
+	}
+	return true, os.WriteFile(path, buf.Bytes(), 0o644)
+}
@@ -0,0 +1,105 @@
+package texts
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/management"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/object"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/text"
+)
+
+type fakeManagement struct {
+	management.ManagementServiceClient
+	login *text.LoginCustomText
+	set   *management.SetCustomLoginTextsRequest
+}
+
+func (f *fakeManagement) GetCustomLoginTexts(_ context.Context, req *management.GetCustomLoginTextsRequest, _ ...grpc.CallOption) (*management.GetCustomLoginTextsResponse, error) {
+	if req.GetLanguage() != "de" {
+		return &management.GetCustomLoginTextsResponse{CustomText: &text.LoginCustomText{IsDefault: true}}, nil
+	}
+	return &management.GetCustomLoginTextsResponse{CustomText: f.login}, nil
+}
+
+func (f *fakeManagement) SetCustomLoginText(_ context.Context, req *management.SetCustomLoginTextsRequest, _ ...grpc.CallOption) (*management.SetCustomLoginTextsResponse, error) {
+	f.set = req
+	return &management.SetCustomLoginTextsResponse{}, nil
+}
+
+func TestKind_GetSet(t *testing.T) {
+	service := &fakeManagement{login: &text.LoginCustomText{
+		Details:           &object.ObjectDetails{Sequence: 1},
+		SelectAccountText: &text.SelectAccountScreenText{Title: "Konto wählen"},
+	}}
+	k := kinds[0]
+	require.Equal(t, KindLogin, k.name)
+
+	msg, err := k.orgGet(context.Background(), service, "de")
+	require.NoError(t, err)
+	values, err := textValues(msg)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"select_account_text": map[string]any{"title": "Konto wählen"}}, values)
+
+	msg, err = k.orgGet(context.Background(), service, "en")
+	require.NoError(t, err)
+	assert.Nil(t, msg, "default texts must be skipped")
+
+	err = k.orgSet(context.Background(), service, "de", map[string]any{"select_account_text": map[string]any{"description": "Beschreibung"}})
+	require.NoError(t, err)
+	assert.Equal(t, "de", service.set.GetLanguage())
+	assert.Equal(t, "Beschreibung", service.set.GetSelectAccountText().GetDescription())
+
+	err = k.orgSet(context.Background(), service, "de", map[string]any{"unknown_text": "x"})
+	assert.ErrorIs(t, err, ErrInvalidText)
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name    string
+		current map[string]any
+		desired map[string]any
+		want    []string
+	}{
+		{"equal", map[string]any{"title": "a"}, map[string]any{"title": "a"}, nil},
+		{"empty equals missing", map[string]any{}, map[string]any{"title": ""}, nil},
+		{"changed", map[string]any{"title": "a"}, map[string]any{"title": "b"}, []string{"title"}},
+		{
+			"nested added and removed",
+			map[string]any{"login": map[string]any{"title": "a"}},
+			map[string]any{"login": map[string]any{"description": "b"}},
+			[]string{"login.description", "login.title"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, diff(tt.current, tt.desired))
+		})
+	}
+}
+
+func TestWriteReadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "de", KindLogin+fileExtension)
+	values := map[string]any{"select_account_text": map[string]any{"title": "Konto wählen"}}
+
+	written, err := writeFile(path, values)
+	require.NoError(t, err)
+	assert.True(t, written)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "select_account_text:\n  title: Konto wählen\n", string(data))
+
+	written, err = writeFile(path, values)
+	require.NoError(t, err)
+	assert.False(t, written, "unchanged file must not be written")
+
+	read, err := readFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, values, read)
+}
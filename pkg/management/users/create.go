@@ -0,0 +1,350 @@
+package users
+
+import (
+	"context"
+	"errors"
+
+	objectV2 "github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/object/v2"
+	userV2 "github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/user/v2"
+)
+
+var ErrMissingEmail = errors.New("email is required")
+
+// HumanUser describes a human user to be created.
+type HumanUser struct {
+	// OrganizationID is the organization the user is created in, the organization of the client if empty.
+	OrganizationID string
+	// Username is the login name of the user, ZITADEL uses the email if empty.
+	Username   string
+	Email      string
+	GivenName  string
+	FamilyName string
+}
+
+// Created is the result of [Users.CreateHumanUser] and [Users.InviteUser].
+type Created struct {
+	UserID string
+	// EmailCode is the email verification code, only set if requested with [WithReturnEmailCode].
+	EmailCode string
+	// PhoneCode is the phone verification code, only set if requested with [WithReturnPhoneCode].
+	PhoneCode string
+	// InviteCode is the invite code, only set if requested with [WithReturnInviteCode].
+	InviteCode string
+}
+
+// Option allows customization of the created user.
+type Option func(*createConfig)
+
+type createConfig struct {
+	userID            string
+	nickName          string
+	displayName       string
+	preferredLanguage string
+	gender            Gender
+	email             emailVerification
+	emailURLTemplate  string
+	phone             string
+	phoneVerification phoneVerification
+	password          string
+	hashedPassword    string
+	changeRequired    bool
+	metadata          []*userV2.SetMetadataEntry
+	idpLinks          []*userV2.IDPLink
+	invite            inviteConfig
+	screener          *EmailScreener
+}
+
+type emailVerification int
+
+const (
+	emailSendCode emailVerification = iota
+	emailReturnCode
+	emailVerified
+)
+
+type phoneVerification int
+
+const (
+	phoneSendCode phoneVerification = iota
+	phoneReturnCode
+	phoneVerified
+)
+
+type inviteConfig struct {
+	returnCode      bool
+	urlTemplate     string
+	applicationName string
+}
+
+// WithUserID sets the ID of the user instead of a generated one.
+func WithUserID(id string) Option {
+	return func(c *createConfig) {
+		c.userID = id
+	}
+}
+
+// WithNickName sets the nick name of the user.
+func WithNickName(nickName string) Option {
+	return func(c *createConfig) {
+		c.nickName = nickName
+	}
+}
+
+// WithDisplayName sets the display name of the user, ZITADEL uses the given and family name if empty.
+func WithDisplayName(displayName string) Option {
+	return func(c *createConfig) {
+		c.displayName = displayName
+	}
+}
+
+// WithPreferredLanguage sets the preferred language of the user as language tag (e.g. `de`).
+func WithPreferredLanguage(language string) Option {
+	return func(c *createConfig) {
+		c.preferredLanguage = language
+	}
+}
+
+// WithGender sets the gender of the user.
+func WithGender(gender Gender) Option {
+	return func(c *createConfig) {
+		c.gender = gender
+	}
+}
+
+// WithVerifiedEmail marks the email as verified, no verification code is sent.
+func WithVerifiedEmail() Option {
+	return func(c *createConfig) {
+		c.email = emailVerified
+	}
+}
+
+// WithReturnEmailCode returns the email verification code in [Created] instead of sending it.
+func WithReturnEmailCode() Option {
+	return func(c *createConfig) {
+		c.email = emailReturnCode
+	}
+}
+
+// WithEmailURLTemplate sets the URL template of the link in the email verification message,
+// e.g. `https://example.com/verify?userID={{.UserID}}&code={{.Code}}`.
+func WithEmailURLTemplate(template string) Option {
+	return func(c *createConfig) {
+		c.emailURLTemplate = template
+	}
+}
+
+// WithPhone sets the phone number of the user, a verification code is sent by SMS.
+func WithPhone(phone string) Option {
+	return func(c *createConfig) {
+		c.phone = phone
+	}
+}
+
+// WithVerifiedPhone marks the phone number as verified, no verification code is sent.
+func WithVerifiedPhone() Option {
+	return func(c *createConfig) {
+		c.phoneVerification = phoneVerified
+	}
+}
+
+// WithReturnPhoneCode returns the phone verification code in [Created] instead of sending it.
+func WithReturnPhoneCode() Option {
+	return func(c *createConfig) {
+		c.phoneVerification = phoneReturnCode
+	}
+}
+
+// WithPassword sets the initial password of the user.
+// If changeRequired is set, the user has to change it on the next login.
+// It's ignored by [Users.InviteUser], where the user sets the password on accepting the invite.
+func WithPassword(password string, changeRequired bool) Option {
+	return func(c *createConfig) {
+		c.password = password
+		c.hashedPassword = ""
+		c.changeRequired = changeRequired
+	}
+}
+
+// WithHashedPassword sets the initial password of the user as hash (e.g. bcrypt), e.g. for migrations.
+// If changeRequired is set, the user has to change it on the next login.
+func WithHashedPassword(hash string, changeRequired bool) Option {
+	return func(c *createConfig) {
+		c.hashedPassword = hash
+		c.password = ""
+		c.changeRequired = changeRequired
+	}
+}
+
+// WithMetadata adds a metadata entry to the user.
+func WithMetadata(key string, value []byte) Option {
+	return func(c *createConfig) {
+		c.metadata = append(c.metadata, &userV2.SetMetadataEntry{Key: key, Value: value})
+	}
+}
+
+// WithIDPLink links the identity of an external identity provider to the user,
+// so the user can log in with it (e.g. after an external login, see idp.Flow).
+func WithIDPLink(idpID, externalUserID, externalUserName string) Option {
+	return func(c *createConfig) {
+		c.idpLinks = append(c.idpLinks, &userV2.IDPLink{IdpId: idpID, UserId: externalUserID, UserName: externalUserName})
+	}
+}
+
+// WithReturnInviteCode returns the invite code in [Created] instead of sending it.
+// It's only used by [Users.InviteUser].
+func WithReturnInviteCode() Option {
+	return func(c *createConfig) {
+		c.invite.returnCode = true
+	}
+}
+
+// WithInviteURLTemplate sets the URL template of the link in the invite message,
+// e.g. `https://example.com/invite?userID={{.UserID}}&code={{.Code}}`.
+// It's only used by [Users.InviteUser].
+func WithInviteURLTemplate(template string) Option {
+	return func(c *createConfig) {
+		c.invite.urlTemplate = template
+	}
+}
+
+// WithInviteApplicationName sets the application name shown in the invite message.
+// It's only used by [Users.InviteUser].
+func WithInviteApplicationName(name string) Option {
+	return func(c *createConfig) {
+		c.invite.applicationName = name
+	}
+}
+
+// WithEmailScreening screens the email by the [EmailScreener] before the user is created,
+// which may reject or normalize it.
+func WithEmailScreening(screener *EmailScreener) Option {
+	return func(c *createConfig) {
+		c.screener = screener
+	}
+}
+
+// CreateHumanUser creates the human user.
+// By default, an email verification code is sent to the user (see [WithVerifiedEmail] and [WithReturnEmailCode]).
+func (u *Users) CreateHumanUser(ctx context.Context, user HumanUser, opts ...Option) (*Created, error) {
+	c := new(createConfig)
+	for _, opt := range opts {
+		opt(c)
+	}
+	return u.createHumanUser(ctx, user, c)
+}
+
+// InviteUser creates the human user without password and invites it to set up its authentication
+// (e.g. password or passkey). The email is verified by accepting the invite.
+// By default, the invite code is sent to the user by email (see [WithReturnInviteCode]).
+func (u *Users) InviteUser(ctx context.Context, user HumanUser, opts ...Option) (*Created, error) {
+	c := new(createConfig)
+	for _, opt := range opts {
+		opt(c)
+	}
+	// the email is verified by the invite code, so no separate verification is sent
+	c.email = emailVerified
+	c.password, c.hashedPassword = "", ""
+	created, err := u.createHumanUser(ctx, user, c)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := u.client.UserServiceV2().CreateInviteCode(ctx, inviteCodeRequest(created.UserID, c.invite))
+	if err != nil {
+		return created, err
+	}
+	created.InviteCode = resp.GetInviteCode()
+	return created, nil
+}
+
+func (u *Users) createHumanUser(ctx context.Context, user HumanUser, c *createConfig) (*Created, error) {
+	if user.Email == "" {
+		return nil, ErrMissingEmail
+	}
+	if c.screener != nil {
+		email, err := c.screener.Screen(ctx, user.Email)
+		if err != nil {
+			return nil, err
+		}
+		user.Email = email
+	}
+	resp, err := u.client.UserServiceV2().AddHumanUser(ctx, addHumanUserRequest(user, c))
+	if err != nil {
+		return nil, err
+	}
+	return &Created{
+		UserID:    resp.GetUserId(),
+		EmailCode: resp.GetEmailCode(),
+		PhoneCode: resp.GetPhoneCode(),
+	}, nil
+}
+
+func addHumanUserRequest(user HumanUser, c *createConfig) *userV2.AddHumanUserRequest {
+	req := &userV2.AddHumanUserRequest{
+		UserId:   optional(c.userID),
+		Username: optional(user.Username),
+		Profile: &userV2.SetHumanProfile{
+			GivenName:         user.GivenName,
+			FamilyName:        user.FamilyName,
+			NickName:          optional(c.nickName),
+			DisplayName:       optional(c.displayName),
+			PreferredLanguage: optional(c.preferredLanguage),
+			Gender:            genderToProto(c.gender),
+		},
+		Email:    &userV2.SetHumanEmail{Email: user.Email},
+		Metadata: c.metadata,
+		IdpLinks: c.idpLinks,
+	}
+	if user.OrganizationID != "" {
+		req.Organization = &objectV2.Organization{Org: &objectV2.Organization_OrgId{OrgId: user.OrganizationID}}
+	}
+	switch c.email {
+	case emailVerified:
+		req.Email.Verification = &userV2.SetHumanEmail_IsVerified{IsVerified: true}
+	case emailReturnCode:
+		req.Email.Verification = &userV2.SetHumanEmail_ReturnCode{ReturnCode: &userV2.ReturnEmailVerificationCode{}}
+	case emailSendCode:
+		req.Email.Verification = &userV2.SetHumanEmail_SendCode{SendCode: &userV2.SendEmailVerificationCode{UrlTemplate: optional(c.emailURLTemplate)}}
+	}
+	if c.phone != "" {
+		req.Phone = &userV2.SetHumanPhone{Phone: c.phone}
+		switch c.phoneVerification {
+		case phoneVerified:
+			req.Phone.Verification = &userV2.SetHumanPhone_IsVerified{IsVerified: true}
+		case phoneReturnCode:
+			req.Phone.Verification = &userV2.SetHumanPhone_ReturnCode{ReturnCode: &userV2.ReturnPhoneVerificationCode{}}
+		case phoneSendCode:
+			req.Phone.Verification = &userV2.SetHumanPhone_SendCode{SendCode: &userV2.SendPhoneVerificationCode{}}
+		}
+	}
+	switch {
+	case c.password != "":
+		req.PasswordType = &userV2.AddHumanUserRequest_Password{
+			Password: &userV2.Password{Password: c.password, ChangeRequired: c.changeRequired},
+		}
+	case c.hashedPassword != "":
+		req.PasswordType = &userV2.AddHumanUserRequest_HashedPassword{
+			HashedPassword: &userV2.HashedPassword{Hash: c.hashedPassword, ChangeRequired: c.changeRequired},
+		}
+	}
+	return req
+}
+
+func inviteCodeRequest(userID string, c inviteConfig) *userV2.CreateInviteCodeRequest {
+	req := &userV2.CreateInviteCodeRequest{UserId: userID}
+	if c.returnCode {
+		req.Verification = &userV2.CreateInviteCodeRequest_ReturnCode{ReturnCode: &userV2.ReturnInviteCode{}}
+		return req
+	}
+	req.Verification = &userV2.CreateInviteCodeRequest_SendCode{SendCode: &userV2.SendInviteCode{
+		UrlTemplate:     optional(c.urlTemplate),
+		ApplicationName: optional(c.applicationName),
+	}}
+	return req
+}
+
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,157 @@
+package users
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	userV2 "github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/user/v2"
+	"github.com/zitadel/zitadel-go/v3/pkg/qrcode"
+)
+
+const defaultQRCodeScale = 8
+
+var ErrUnknownOTPMethod = errors.New("unknown OTP method")
+
+// PasswordOption allows customization of [Users.SetPassword].
+type PasswordOption func(*userV2.SetPasswordRequest)
+
+// WithChangeRequired requires the user to change the password on the next login.
+func WithChangeRequired() PasswordOption {
+	return func(req *userV2.SetPasswordRequest) {
+		req.NewPassword.ChangeRequired = true
+	}
+}
+
+// WithCurrentPassword verifies the current password of the user before setting the new one,
+// e.g. if a user changes its own password.
+func WithCurrentPassword(password string) PasswordOption {
+	return func(req *userV2.SetPasswordRequest) {
+		req.Verification = &userV2.SetPasswordRequest_CurrentPassword{CurrentPassword: password}
+	}
+}
+
+// WithVerificationCode verifies the password reset code sent to the user before setting the new password.
+func WithVerificationCode(code string) PasswordOption {
+	return func(req *userV2.SetPasswordRequest) {
+		req.Verification = &userV2.SetPasswordRequest_VerificationCode{VerificationCode: code}
+	}
+}
+
+// SetPassword sets the password of the user.
+// Without verification option, the client requires the permission to manage the user.
+func (u *Users) SetPassword(ctx context.Context, userID, password string, opts ...PasswordOption) error {
+	req := &userV2.SetPasswordRequest{
+		UserId:      userID,
+		NewPassword: &userV2.Password{Password: password},
+	}
+	for _, opt := range opts {
+		opt(req)
+	}
+	_, err := u.client.UserServiceV2().SetPassword(ctx, req)
+	return err
+}
+
+// OTPMethod is the second factor registered by [Users.AddOTP].
+type OTPMethod string
+
+const (
+	// OTPTOTP is a time-based one-time password of an authenticator app.
+	OTPTOTP OTPMethod = "totp"
+	// OTPSMS is a one-time password sent by SMS to the verified phone number.
+	OTPSMS OTPMethod = "sms"
+	// OTPEmail is a one-time password sent to the verified email.
+	OTPEmail OTPMethod = "email"
+)
+
+// TOTPRegistration contains the secret of a TOTP registration to be added to the authenticator app.
+type TOTPRegistration struct {
+	// URI is the `otpauth://` URI, usually shown as QR code.
+	URI    string
+	Secret string
+}
+
+// AddOTP adds the OTP method as second factor to the user.
+// For [OTPTOTP], the registration is returned and must be verified with [Users.VerifyTOTP]
+// using a code of the authenticator app. [OTPSMS] and [OTPEmail] require a verified phone number or email
+// and are active immediately, no registration is returned.
+func (u *Users) AddOTP(ctx context.Context, userID string, method OTPMethod) (*TOTPRegistration, error) {
+	switch method {
+	case OTPTOTP:
+		resp, err := u.client.UserServiceV2().RegisterTOTP(ctx, &userV2.RegisterTOTPRequest{UserId: userID})
+		if err != nil {
+			return nil, err
+		}
+		return &TOTPRegistration{URI: resp.GetUri(), Secret: resp.GetSecret()}, nil
+	case OTPSMS:
+		_, err := u.client.UserServiceV2().AddOTPSMS(ctx, &userV2.AddOTPSMSRequest{UserId: userID})
+		return nil, err
+	case OTPEmail:
+		_, err := u.client.UserServiceV2().AddOTPEmail(ctx, &userV2.AddOTPEmailRequest{UserId: userID})
+		return nil, err
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownOTPMethod, method)
+	}
+}
+
+// VerifyTOTP completes the TOTP registration of [Users.AddOTP] with a code of the authenticator app.
+func (u *Users) VerifyTOTP(ctx context.Context, userID, code string) error {
+	_, err := u.client.UserServiceV2().VerifyTOTPRegistration(ctx, &userV2.VerifyTOTPRegistrationRequest{UserId: userID, Code: code})
+	return err
+}
+
+// TOTPEnrollment is a pending TOTP registration of [Users.EnrollTOTP].
+type TOTPEnrollment struct {
+	TOTPRegistration
+	// QRCode is the PNG image of the URI to be scanned with the authenticator app.
+	QRCode []byte
+
+	users  *Users
+	userID string
+}
+
+// TOTPOption allows customization of [Users.EnrollTOTP].
+type TOTPOption func(*totpConfig)
+
+type totpConfig struct {
+	scale int
+}
+
+// WithQRCodeScale sets the size of a QR code module in pixels (default 8).
+func WithQRCodeScale(scale int) TOTPOption {
+	return func(c *totpConfig) {
+		c.scale = scale
+	}
+}
+
+// EnrollTOTP registers TOTP as second factor of the user and renders the `otpauth://` URI as QR code.
+// The registration is active once the first code of the authenticator app is verified with [TOTPEnrollment.Verify].
+func (u *Users) EnrollTOTP(ctx context.Context, userID string, opts ...TOTPOption) (*TOTPEnrollment, error) {
+	c := &totpConfig{scale: defaultQRCodeScale}
+	for _, opt := range opts {
+		opt(c)
+	}
+	registration, err := u.AddOTP(ctx, userID, OTPTOTP)
+	if err != nil {
+		return nil, err
+	}
+	code, err := qrcode.Encode(registration.URI)
+	if err != nil {
+		return nil, err
+	}
+	image, err := code.PNG(c.scale)
+	if err != nil {
+		return nil, err
+	}
+	return &TOTPEnrollment{
+		TOTPRegistration: *registration,
+		QRCode:           image,
+		users:            u,
+		userID:           userID,
+	}, nil
+}
+
+// Verify completes the enrollment with the first code of the authenticator app.
+func (e *TOTPEnrollment) Verify(ctx context.Context, code string) error {
+	return e.users.VerifyTOTP(ctx, e.userID, code)
+}
@@ -0,0 +1,410 @@
+package users
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/management"
+	"github.com/zitadel/zitadel-go/v3/pkg/job"
+)
+
+// ImportUser is a human user to be imported, e.g. from another identity system.
+type ImportUser struct {
+	// Ref identifies the record in the [ImportReport], e.g. the ID in the source system.
+	Ref string
+	// OrganizationID is the organization the user is imported to, the organization of the client if empty.
+	OrganizationID    string
+	Username          string
+	Email             string
+	EmailVerified     bool
+	Phone             string
+	PhoneVerified     bool
+	GivenName         string
+	FamilyName        string
+	NickName          string
+	DisplayName       string
+	PreferredLanguage string
+	// Password is the plain password, only one of Password and HashedPassword is used.
+	Password string
+	// HashedPassword is the password hash of the source system (e.g. bcrypt), supported by ZITADEL.
+	HashedPassword         string
+	PasswordChangeRequired bool
+	// IDPLinks link the user to its identities of external identity providers.
+	IDPLinks []IDPLink
+}
+
+// IDPLink is the identity of an [ImportUser] at an external identity provider.
+type IDPLink struct {
+	IDPID          string
+	ExternalUserID string
+	DisplayName    string
+}
+
+// Iterator streams the users to import. Next returns [io.EOF] once all users were returned.
+// Any other error stops the import.
+type Iterator interface {
+	Next(ctx context.Context) (*ImportUser, error)
+}
+
+// IteratorFunc implements [Iterator] using a function.
+type IteratorFunc func(ctx context.Context) (*ImportUser, error)
+
+// Next implements [Iterator].
+func (f IteratorFunc) Next(ctx context.Context) (*ImportUser, error) {
+	return f(ctx)
+}
+
+// SliceIterator returns an [Iterator] over the users.
+func SliceIterator(users []ImportUser) Iterator {
+	var i int
+	return IteratorFunc(func(context.Context) (*ImportUser, error) {
+		if i >= len(users) {
+			return nil, io.EOF
+		}
+		i++
+		return &users[i-1], nil
+	})
+}
+
+// BulkOptions customize [Users.BulkImport]. The zero value uses the defaults.
+type BulkOptions struct {
+	// Workers is the number of concurrent import calls (default 4).
+	Workers int
+	// RequestsPerSecond limits the rate of import calls (including retries), unlimited if zero.
+	RequestsPerSecond float64
+	// MaxAttempts is the maximum number of attempts per user including the initial one (default 3).
+	MaxAttempts int
+	// InitialBackoff is the time to wait before the first retry (default 500ms), it's doubled with every attempt.
+	InitialBackoff time.Duration
+	// RetryCodes are the status codes considered transient (default UNAVAILABLE, RESOURCE_EXHAUSTED,
+	// DEADLINE_EXCEEDED and ABORTED).
+	RetryCodes []codes.Code
+	// OnResult is called (sequentially) for every handled user, e.g. to report progress.
+	OnResult func(ImportResult)
+	// Job allows to pause and resume the import and persists its progress (see [job.Controller]).
+	// An import started again with the same job continues after the users handled before (including failed ones),
+	// which requires the iterator to return the users in the same order.
+	Job *job.Controller
+	// Total is the number of users of the iterator (if known), it's reported as [job.Progress.Total] for the ETA.
+	Total int
+}
+
+// ImportResult is the outcome of the import of a single user.
+type ImportResult struct {
+	// Index is the position of the user in the iterator.
+	Index int
+	Ref   string
+	// UserID is the ID of the imported user, empty if it failed.
+	UserID   string
+	Attempts int
+	Err      error
+}
+
+// ImportReport summarizes a [Users.BulkImport].
+type ImportReport struct {
+	// Results of all handled users, ordered by their index.
+	Results   []ImportResult
+	Succeeded int
+	Failed    int
+}
+
+// Failures returns the results of the users which could not be imported.
+func (r *ImportReport) Failures() []ImportResult {
+	var failures []ImportResult
+	for _, result := range r.Results {
+		if result.Err != nil {
+			failures = append(failures, result)
+		}
+	}
+	return failures
+}
+
+func (o BulkOptions) withDefaults() BulkOptions {
+	if o.Workers <= 0 {
+		o.Workers = 4
+	}
+	if o.MaxAttempts <= 0 {
+		o.MaxAttempts = 3
+	}
+	if o.InitialBackoff <= 0 {
+		o.InitialBackoff = 500 * time.Millisecond
+	}
+	if o.RetryCodes == nil {
+		o.RetryCodes = []codes.Code{codes.Unavailable, codes.ResourceExhausted, codes.DeadlineExceeded, codes.Aborted}
+	}
+	return o
+}
+
+type importJob struct {
+	index int
+	user  *ImportUser
+}
+
+// BulkImport streams the users from the iterator and imports them using a bounded pool of workers.
+// Transient errors (e.g. rate limiting) are retried with exponential backoff.
+//
+// Failing users don't stop the import and are reported in the [ImportReport],
+// an error is only returned if the iterator fails or the context is canceled.
+// In both cases, the report contains the results of the users handled so far (in this run).
+func (u *Users) BulkImport(ctx context.Context, users Iterator, opts BulkOptions) (*ImportReport, error) {
+	opts = opts.withDefaults()
+	progress, err := opts.Job.Start(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Total > 0 {
+		_ = opts.Job.Update(ctx, func(progress *job.Progress) { progress.Total = opts.Total })
+	}
+	cursor, err := parseImportCursor(progress.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	report, err := u.bulkImport(ctx, users, opts, cursor)
+	return report, errors.Join(err, opts.Job.Finish(ctx, err))
+}
+
+func (u *Users) bulkImport(ctx context.Context, users Iterator, opts BulkOptions, cursor *importCursor) (*ImportReport, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	limiter := newRateLimiter(opts.RequestsPerSecond)
+	defer limiter.stop()
+
+	jobs := make(chan importJob)
+	results := make(chan ImportResult)
+	var workers sync.WaitGroup
+	for range opts.Workers {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for job := range jobs {
+				results <- u.importUser(ctx, job, opts, limiter)
+			}
+		}()
+	}
+
+	var iterErr error
+	go func() {
+		defer close(jobs)
+		for index := 0; ; index++ {
+			if err := opts.Job.Wait(ctx); err != nil {
+				if ctx.Err() == nil {
+					iterErr = err
+					cancel()
+				}
+				return
+			}
+			user, err := users.Next(ctx)
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			if err != nil {
+				iterErr = err
+				cancel()
+				return
+			}
+			if cursor.handled(index) {
+				continue
+			}
+			select {
+			case jobs <- importJob{index: index, user: user}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	go func() {
+		workers.Wait()
+		close(results)
+	}()
+
+	report := new(ImportReport)
+	var saveErr error
+	for result := range results {
+		if result.Err != nil {
+			report.Failed++
+		} else {
+			report.Succeeded++
+		}
+		report.Results = append(report.Results, result)
+		if opts.OnResult != nil {
+			opts.OnResult(result)
+		}
+		cursor.complete(result.Index)
+		if err := opts.Job.Update(ctx, func(progress *job.Progress) {
+			if result.Err != nil {
+				progress.Failed++
+				progress.LastError = result.Err.Error()
+			} else {
+				progress.Done++
+			}
+			progress.Cursor = cursor.String()
+		}); err != nil {
+			saveErr = err
+		}
+	}
+	slices.SortFunc(report.Results, func(a, b ImportResult) int { return a.Index - b.Index })
+	// the results channel is closed after the iterator finished, so iterErr is safe to read
+	if iterErr != nil {
+		return report, errors.Join(iterErr, saveErr)
+	}
+	return report, errors.Join(context.Cause(ctx), saveErr)
+}
+
+// importCursor is the position of a [job.Controller] of an import.
+// The workers complete the users out of order, so the completed users after the offset are tracked as well.
+type importCursor struct {
+	// Offset is the number of users handled without gap.
+	Offset int `json:"offset"`
+	// Completed are the indexes of the users handled after the offset.
+	Completed []int `json:"completed,omitempty"`
+}
+
+func parseImportCursor(cursor string) (*importCursor, error) {
+	c := new(importCursor)
+	if cursor == "" {
+		return c, nil
+	}
+	if err := json.Unmarshal([]byte(cursor), c); err != nil {
+		return nil, fmt.Errorf("invalid cursor of the import job: %w", err)
+	}
+	return c, nil
+}
+
+func (c *importCursor) handled(index int) bool {
+	return index < c.Offset || slices.Contains(c.Completed, index)
+}
+
+func (c *importCursor) complete(index int) {
+	c.Completed = append(c.Completed, index)
+	for {
+		i := slices.Index(c.Completed, c.Offset)
+		if i < 0 {
+			return
+		}
+		c.Completed = slices.Delete(c.Completed, i, i+1)
+		c.Offset++
+	}
+}
+
+func (c *importCursor) String() string {
+	data, _ := json.Marshal(c)
+	return string(data)
+}
+
+func (u *Users) importUser(ctx context.Context, job importJob, opts BulkOptions, limiter *rateLimiter) ImportResult {
+	result := ImportResult{Index: job.index, Ref: job.user.Ref}
+	mgmt := u.client.ManagementService()
+	if job.user.OrganizationID != "" {
+		mgmt = u.client.ForOrganization(job.user.OrganizationID).ManagementService()
+	}
+	req := importHumanUserRequest(job.user)
+	for {
+		result.Attempts++
+		if result.Err = limiter.wait(ctx); result.Err != nil {
+			return result
+		}
+		resp, err := mgmt.ImportHumanUser(ctx, req)
+		if err == nil {
+			result.UserID, result.Err = resp.GetUserId(), nil
+			return result
+		}
+		result.Err = err
+		if result.Attempts >= opts.MaxAttempts || !slices.Contains(opts.RetryCodes, status.Code(err)) {
+			return result
+		}
+		backoff := time.Duration(float64(opts.InitialBackoff) * math.Pow(2, float64(result.Attempts-1)))
+		if err = sleep(ctx, backoff); err != nil {
+			return result
+		}
+	}
+}
+
+func importHumanUserRequest(user *ImportUser) *management.ImportHumanUserRequest {
+	req := &management.ImportHumanUserRequest{
+		UserName: user.Username,
+		Profile: &management.ImportHumanUserRequest_Profile{
+			FirstName:         user.GivenName,
+			LastName:          user.FamilyName,
+			NickName:          user.NickName,
+			DisplayName:       user.DisplayName,
+			PreferredLanguage: user.PreferredLanguage,
+		},
+		Email: &management.ImportHumanUserRequest_Email{
+			Email:           user.Email,
+			IsEmailVerified: user.EmailVerified,
+		},
+		PasswordChangeRequired: user.PasswordChangeRequired,
+	}
+	if req.UserName == "" {
+		req.UserName = user.Email
+	}
+	if user.Phone != "" {
+		req.Phone = &management.ImportHumanUserRequest_Phone{Phone: user.Phone, IsPhoneVerified: user.PhoneVerified}
+	}
+	switch {
+	case user.HashedPassword != "":
+		req.HashedPassword = &management.ImportHumanUserRequest_HashedPassword{Value: user.HashedPassword}
+	case user.Password != "":
+		req.Password = user.Password
+	}
+	for _, link := range user.IDPLinks {
+		req.Idps = append(req.Idps, &management.ImportHumanUserRequest_IDP{
+			ConfigId:       link.IDPID,
+			ExternalUserId: link.ExternalUserID,
+			DisplayName:    link.DisplayName,
+		})
+	}
+	return req
+}
+
+// rateLimiter hands out a token per interval, a nil limiter does not limit.
+type rateLimiter struct {
+	ticker *time.Ticker
+}
+
+func newRateLimiter(perSecond float64) *rateLimiter {
+	if perSecond <= 0 {
+		return nil
+	}
+	return &rateLimiter{ticker: time.NewTicker(time.Duration(float64(time.Second) / perSecond))}
+}
+
+func (l *rateLimiter) wait(ctx context.Context) error {
+	if l == nil {
+		return ctx.Err()
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-l.ticker.C:
+		return nil
+	}
+}
+
+func (l *rateLimiter) stop() {
+	if l != nil {
+		l.ticker.Stop()
+	}
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,64 @@
+package users
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/management"
+)
+
+func Test_importHumanUserRequest(t *testing.T) {
+	got := importHumanUserRequest(&ImportUser{
+		Email:          "alice@example.com",
+		EmailVerified:  true,
+		GivenName:      "Alice",
+		FamilyName:     "Doe",
+		Password:       "ignored",
+		HashedPassword: "$2a$10$hash",
+		IDPLinks:       []IDPLink{{IDPID: "idp", ExternalUserID: "ext"}},
+	})
+	assert.True(t, proto.Equal(&management.ImportHumanUserRequest{
+		UserName: "alice@example.com",
+		Profile:  &management.ImportHumanUserRequest_Profile{FirstName: "Alice", LastName: "Doe"},
+		Email: &management.ImportHumanUserRequest_Email{
+			Email:           "alice@example.com",
+			IsEmailVerified: true,
+		},
+		HashedPassword: &management.ImportHumanUserRequest_HashedPassword{Value: "$2a$10$hash"},
+		Idps:           []*management.ImportHumanUserRequest_IDP{{ConfigId: "idp", ExternalUserId: "ext"}},
+	}, got), "got %v", got)
+}
+
+func TestSliceIterator(t *testing.T) {
+	it := SliceIterator([]ImportUser{{Ref: "1"}, {Ref: "2"}})
+	for _, ref := range []string{"1", "2"} {
+		user, err := it.Next(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, ref, user.Ref)
+	}
+	_, err := it.Next(context.Background())
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func Test_importCursor(t *testing.T) {
+	cursor, err := parseImportCursor("")
+	require.NoError(t, err)
+	cursor.complete(1)
+	cursor.complete(3)
+	assert.Equal(t, &importCursor{Completed: []int{1, 3}}, cursor)
+	cursor.complete(0)
+	assert.Equal(t, &importCursor{Offset: 2, Completed: []int{3}}, cursor)
+
+	restored, err := parseImportCursor(cursor.String())
+	require.NoError(t, err)
+	for index, want := range []bool{true, true, false, true, false} {
+		assert.Equal(t, want, restored.handled(index), "index %d", index)
+	}
+	_, err = parseImportCursor("invalid")
+	assert.Error(t, err)
+}
@@ -0,0 +1,209 @@
+package users
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/management"
+	objectV2 "github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/object/v2"
+	userV2 "github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/user/v2"
+)
+
+const listPageSize = 100
+
+// Type of a [User].
+type Type string
+
+const (
+	TypeHuman   Type = "human"
+	TypeMachine Type = "machine"
+)
+
+// Query filters the users of [Users.ListUsers]. All queries must match.
+type Query func(*listConfig)
+
+type listConfig struct {
+	queries  []*userV2.SearchQuery
+	metadata []metadataFilter
+	limit    int
+}
+
+// metadataFilter requires the metadata key to exist, and to have the value if set.
+type metadataFilter struct {
+	key   string
+	value []byte
+}
+
+func (f metadataFilter) matches(metadata map[string][]byte) bool {
+	value, ok := metadata[f.key]
+	if !ok {
+		return false
+	}
+	return f.value == nil || bytes.Equal(value, f.value)
+}
+
+// InOrganization returns the users of the organization only.
+func InOrganization(organizationID string) Query {
+	return func(c *listConfig) {
+		c.queries = append(c.queries, &userV2.SearchQuery{Query: &userV2.SearchQuery_OrganizationIdQuery{
+			OrganizationIdQuery: &userV2.OrganizationIdQuery{OrganizationId: organizationID},
+		}})
+	}
+}
+
+// WithState returns the users in the state only.
+func WithState(state State) Query {
+	return func(c *listConfig) {
+		c.queries = append(c.queries, &userV2.SearchQuery{Query: &userV2.SearchQuery_StateQuery{
+			StateQuery: &userV2.StateQuery{State: stateToProto(state)},
+		}})
+	}
+}
+
+// WithType returns the human, resp. machine users only.
+func WithType(userType Type) Query {
+	return func(c *listConfig) {
+		t := userV2.Type_TYPE_HUMAN
+		if userType == TypeMachine {
+			t = userV2.Type_TYPE_MACHINE
+		}
+		c.queries = append(c.queries, &userV2.SearchQuery{Query: &userV2.SearchQuery_TypeQuery{
+			TypeQuery: &userV2.TypeQuery{Type: t},
+		}})
+	}
+}
+
+// WithUsername returns the users with the username (case-insensitive).
+func WithUsername(username string) Query {
+	return func(c *listConfig) {
+		c.queries = append(c.queries, &userV2.SearchQuery{Query: &userV2.SearchQuery_UserNameQuery{
+			UserNameQuery: &userV2.UserNameQuery{UserName: username, Method: objectV2.TextQueryMethod_TEXT_QUERY_METHOD_EQUALS_IGNORE_CASE},
+		}})
+	}
+}
+
+// WithEmail returns the human users with the email (case-insensitive).
+func WithEmail(email string) Query {
+	return func(c *listConfig) {
+		c.queries = append(c.queries, &userV2.SearchQuery{Query: &userV2.SearchQuery_EmailQuery{
+			EmailQuery: &userV2.EmailQuery{EmailAddress: email, Method: objectV2.TextQueryMethod_TEXT_QUERY_METHOD_EQUALS_IGNORE_CASE},
+		}})
+	}
+}
+
+// WithIDs returns the users with the IDs only.
+func WithIDs(userIDs ...string) Query {
+	return func(c *listConfig) {
+		c.queries = append(c.queries, &userV2.SearchQuery{Query: &userV2.SearchQuery_InUserIdsQuery{
+			InUserIdsQuery: &userV2.InUserIDQuery{UserIds: userIDs},
+		}})
+	}
+}
+
+// WithSearchQuery adds a query of the user service, e.g. to combine queries with [userV2.OrQuery].
+func WithSearchQuery(query *userV2.SearchQuery) Query {
+	return func(c *listConfig) {
+		c.queries = append(c.queries, query)
+	}
+}
+
+// WithMetadataKey returns the users having a metadata entry with the key.
+func WithMetadataKey(key string) Query {
+	return func(c *listConfig) {
+		c.metadata = append(c.metadata, metadataFilter{key: key})
+	}
+}
+
+// WithMetadataValue returns the users having a metadata entry with the key and exactly the value,
+// e.g. WithMetadataValue("plan", []byte("enterprise")).
+func WithMetadataValue(key string, value []byte) Query {
+	if value == nil {
+		value = []byte{}
+	}
+	return func(c *listConfig) {
+		c.metadata = append(c.metadata, metadataFilter{key: key, value: value})
+	}
+}
+
+// WithLimit returns at most limit users.
+func WithLimit(limit int) Query {
+	return func(c *listConfig) {
+		c.limit = limit
+	}
+}
+
+// ListUsers returns all users matching the queries.
+//
+// The user service does not support metadata queries, so [WithMetadataKey] and [WithMetadataValue] are evaluated
+// by the client: the users matching the other queries are listed and the metadata of each one is read from the
+// management service. Combine them with other queries (e.g. [InOrganization]) to reduce the number of calls.
+func (u *Users) ListUsers(ctx context.Context, queries ...Query) ([]*User, error) {
+	c := new(listConfig)
+	for _, query := range queries {
+		query(c)
+	}
+	var users []*User
+	for offset := uint64(0); ; offset += listPageSize {
+		resp, err := u.client.UserServiceV2().ListUsers(ctx, &userV2.ListUsersRequest{
+			Query:   &objectV2.ListQuery{Offset: offset, Limit: listPageSize, Asc: true},
+			Queries: c.queries,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, user := range resp.GetResult() {
+			ok, err := u.matchesMetadata(ctx, user, c.metadata)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
+			users = append(users, userFromProto(user))
+			if c.limit > 0 && len(users) == c.limit {
+				return users, nil
+			}
+		}
+		if len(resp.GetResult()) < listPageSize || offset+listPageSize >= resp.GetDetails().GetTotalResult() {
+			return users, nil
+		}
+	}
+}
+
+func (u *Users) matchesMetadata(ctx context.Context, user *userV2.User, filters []metadataFilter) (bool, error) {
+	if len(filters) == 0 {
+		return true, nil
+	}
+	resp, err := u.client.ForOrganization(user.GetDetails().GetResourceOwner()).ManagementService().
+		ListUserMetadata(ctx, &management.ListUserMetadataRequest{Id: user.GetUserId()})
+	if err != nil {
+		return false, err
+	}
+	metadata := make(map[string][]byte, len(resp.GetResult()))
+	for _, entry := range resp.GetResult() {
+		metadata[entry.GetKey()] = entry.GetValue()
+	}
+	for _, filter := range filters {
+		if !filter.matches(metadata) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func stateToProto(state State) userV2.UserState {
+	switch state {
+	case StateInitial:
+		return userV2.UserState_USER_STATE_INITIAL
+	case StateActive:
+		return userV2.UserState_USER_STATE_ACTIVE
+	case StateInactive:
+		return userV2.UserState_USER_STATE_INACTIVE
+	case StateLocked:
+		return userV2.UserState_USER_STATE_LOCKED
+	case StateDeleted:
+		return userV2.UserState_USER_STATE_DELETED
+	default:
+		return userV2.UserState_USER_STATE_UNSPECIFIED
+	}
+}
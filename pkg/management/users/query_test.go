@@ -0,0 +1,51 @@
+package users
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+
+	userV2 "github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/user/v2"
+)
+
+func TestQuery(t *testing.T) {
+	c := new(listConfig)
+	for _, query := range []Query{InOrganization("org"), WithState(StateActive), WithType(TypeMachine), WithMetadataValue("plan", []byte("enterprise"))} {
+		query(c)
+	}
+	want := []*userV2.SearchQuery{
+		{Query: &userV2.SearchQuery_OrganizationIdQuery{OrganizationIdQuery: &userV2.OrganizationIdQuery{OrganizationId: "org"}}},
+		{Query: &userV2.SearchQuery_StateQuery{StateQuery: &userV2.StateQuery{State: userV2.UserState_USER_STATE_ACTIVE}}},
+		{Query: &userV2.SearchQuery_TypeQuery{TypeQuery: &userV2.TypeQuery{Type: userV2.Type_TYPE_MACHINE}}},
+	}
+	if assert.Len(t, c.queries, len(want)) {
+		for i := range want {
+			assert.True(t, proto.Equal(want[i], c.queries[i]), "query %d: %v", i, c.queries[i])
+		}
+	}
+	assert.Equal(t, []metadataFilter{{key: "plan", value: []byte("enterprise")}}, c.metadata)
+}
+
+func Test_metadataFilter_matches(t *testing.T) {
+	metadata := map[string][]byte{"plan": []byte("enterprise"), "flag": {}}
+	tests := []struct {
+		name   string
+		filter Query
+		want   bool
+	}{
+		{"key exists", WithMetadataKey("plan"), true},
+		{"key missing", WithMetadataKey("region"), false},
+		{"value equal", WithMetadataValue("plan", []byte("enterprise")), true},
+		{"value different", WithMetadataValue("plan", []byte("free")), false},
+		{"empty value", WithMetadataValue("flag", nil), true},
+		{"empty value mismatch", WithMetadataValue("plan", nil), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := new(listConfig)
+			tt.filter(c)
+			assert.Equal(t, tt.want, c.metadata[0].matches(metadata))
+		})
+	}
+}
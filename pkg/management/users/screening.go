@@ -0,0 +1,231 @@
+package users
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ErrEmailRejected is returned if the [EmailScreener] rejected the email of a user to be created.
+var ErrEmailRejected = errors.New("email rejected")
+
+// provider describes how a mail provider maps addresses to mailboxes.
+type provider struct {
+	// canonicalDomain replaces aliases of the domain, e.g. googlemail.com by gmail.com
+	canonicalDomain string
+	// ignoreDots removes dots of the local part, which are ignored by the provider
+	ignoreDots bool
+	// tagSeparator separates the subaddress (tag), which is removed including the separator
+	tagSeparator string
+}
+
+var providers = map[string]provider{
+	"gmail.com":      {canonicalDomain: "gmail.com", ignoreDots: true, tagSeparator: "+"},
+	"googlemail.com": {canonicalDomain: "gmail.com", ignoreDots: true, tagSeparator: "+"},
+	"outlook.com":    {tagSeparator: "+"},
+	"hotmail.com":    {tagSeparator: "+"},
+	"live.com":       {tagSeparator: "+"},
+	"msn.com":        {tagSeparator: "+"},
+	"icloud.com":     {tagSeparator: "+"},
+	"me.com":         {tagSeparator: "+"},
+	"fastmail.com":   {tagSeparator: "+"},
+	"proton.me":      {tagSeparator: "+"},
+	"protonmail.com": {tagSeparator: "+"},
+	"yahoo.com":      {tagSeparator: "-"},
+}
+
+// NormalizeEmail returns the canonical address of the mailbox of the email, so variations of the same address
+// can be detected: the domain is lower-cased and, for well-known providers (e.g. Gmail, Outlook, Yahoo),
+// the local part is lower-cased and subaddresses (e.g. `+tag`) as well as ignored dots are removed.
+// The local part of other domains is kept, as it may be case-sensitive.
+func NormalizeEmail(email string) (string, error) {
+	local, domain, err := splitEmail(email)
+	if err != nil {
+		return "", err
+	}
+	p, ok := providers[domain]
+	if !ok {
+		return local + "@" + domain, nil
+	}
+	local = strings.ToLower(local)
+	if p.tagSeparator != "" {
+		local, _, _ = strings.Cut(local, p.tagSeparator)
+	}
+	if p.ignoreDots {
+		local = strings.ReplaceAll(local, ".", "")
+	}
+	if p.canonicalDomain != "" {
+		domain = p.canonicalDomain
+	}
+	if local == "" {
+		return "", fmt.Errorf("%w: %q", ErrInvalidEmail, email)
+	}
+	return local + "@" + domain, nil
+}
+
+// splitEmail returns the local part and the lower-cased domain of the email.
+func splitEmail(email string) (local, domain string, err error) {
+	email = strings.TrimSpace(email)
+	i := strings.LastIndex(email, "@")
+	if i <= 0 || i == len(email)-1 || strings.ContainsAny(email, " \t\r\n") {
+		return "", "", fmt.Errorf("%w: %q", ErrInvalidEmail, email)
+	}
+	return email[:i], strings.TrimSuffix(strings.ToLower(email[i+1:]), "."), nil
+}
+
+// DisposableDomains reports if a domain belongs to a disposable (throwaway) email provider,
+// e.g. backed by a maintained list or an external reputation service.
+type DisposableDomains interface {
+	IsDisposable(ctx context.Context, domain string) (bool, error)
+}
+
+// DomainList is a static list of [DisposableDomains], subdomains of a listed domain are disposable as well.
+type DomainList map[string]struct{}
+
+// NewDomainList creates a [DomainList] of the domains.
+func NewDomainList(domains ...string) DomainList {
+	list := make(DomainList, len(domains))
+	for _, domain := range domains {
+		list[strings.TrimSuffix(strings.ToLower(strings.TrimSpace(domain)), ".")] = struct{}{}
+	}
+	return list
+}
+
+// ReadDomainList reads a [DomainList] with one domain per line, empty lines and lines starting with `#` are ignored.
+// This is the format of the commonly used community lists of disposable email domains.
+func ReadDomainList(r io.Reader) (DomainList, error) {
+	var domains []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		domains = append(domains, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return NewDomainList(domains...), nil
+}
+
+// IsDisposable implements [DisposableDomains].
+func (l DomainList) IsDisposable(_ context.Context, domain string) (bool, error) {
+	domain = strings.ToLower(domain)
+	for {
+		if _, ok := l[domain]; ok {
+			return true, nil
+		}
+		_, parent, ok := strings.Cut(domain, ".")
+		if !ok {
+			return false, nil
+		}
+		domain = parent
+	}
+}
+
+// Screening is the information about the email of a user to be created, passed to the [ScreeningDecision].
+type Screening struct {
+	// Email is the address as provided (trimmed, with a lower-case domain).
+	Email string
+	// Normalized is the canonical address of the mailbox (see [NormalizeEmail]),
+	// e.g. to search for existing users registered with a variation of the address.
+	Normalized string
+	Domain     string
+	// Disposable is set if the domain is disposable, always false without [WithDisposableDomains].
+	Disposable bool
+}
+
+// Decision is the outcome of the [ScreeningDecision].
+type Decision int
+
+const (
+	// DecisionAccept creates the user with the email as provided.
+	DecisionAccept Decision = iota
+	// DecisionNormalize creates the user with the normalized email.
+	DecisionNormalize
+	// DecisionReject prevents the creation of the user with [ErrEmailRejected].
+	DecisionReject
+)
+
+// ScreeningDecision decides about the email of a user to be created, e.g. rejecting disposable domains
+// or addresses whose normalized form is already registered. An error prevents the creation as well.
+type ScreeningDecision func(ctx context.Context, screening Screening) (Decision, error)
+
+// RejectDisposable is the default [ScreeningDecision], rejecting disposable domains
+// and accepting all other emails as provided.
+func RejectDisposable(_ context.Context, screening Screening) (Decision, error) {
+	if screening.Disposable {
+		return DecisionReject, nil
+	}
+	return DecisionAccept, nil
+}
+
+// EmailScreener screens the emails of users before they are created (see [WithEmailScreening]).
+type EmailScreener struct {
+	disposable DisposableDomains
+	decide     ScreeningDecision
+}
+
+// ScreenerOption allows customization of the [EmailScreener].
+type ScreenerOption func(*EmailScreener)
+
+// WithDisposableDomains checks the domain of the emails against the disposable domains.
+func WithDisposableDomains(domains DisposableDomains) ScreenerOption {
+	return func(s *EmailScreener) {
+		s.disposable = domains
+	}
+}
+
+// WithDecision sets the decision about the emails (default [RejectDisposable]).
+func WithDecision(decide ScreeningDecision) ScreenerOption {
+	return func(s *EmailScreener) {
+		s.decide = decide
+	}
+}
+
+// NewEmailScreener creates an [EmailScreener].
+func NewEmailScreener(opts ...ScreenerOption) *EmailScreener {
+	s := &EmailScreener{decide: RejectDisposable}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Screen returns the email to create the user with, or [ErrEmailRejected] if the decision rejected it.
+func (s *EmailScreener) Screen(ctx context.Context, email string) (string, error) {
+	local, domain, err := splitEmail(email)
+	if err != nil {
+		return "", err
+	}
+	normalized, err := NormalizeEmail(email)
+	if err != nil {
+		return "", err
+	}
+	screening := Screening{
+		Email:      local + "@" + domain,
+		Normalized: normalized,
+		Domain:     domain,
+	}
+	if s.disposable != nil {
+		if screening.Disposable, err = s.disposable.IsDisposable(ctx, domain); err != nil {
+			return "", err
+		}
+	}
+	decision, err := s.decide(ctx, screening)
+	if err != nil {
+		return "", err
+	}
+	switch decision {
+	case DecisionAccept:
+		return screening.Email, nil
+	case DecisionNormalize:
+		return screening.Normalized, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrEmailRejected, screening.Email)
+	}
+}
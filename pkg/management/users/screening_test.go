@@ -0,0 +1,125 @@
+package users
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeEmail(t *testing.T) {
+	tests := []struct {
+		email   string
+		want    string
+		wantErr error
+	}{
+		{email: "Alice.Liddell+news@GoogleMail.com", want: "aliceliddell@gmail.com"},
+		{email: " Bob+shop@Outlook.com ", want: "bob@outlook.com"},
+		{email: "carol-shop@yahoo.com", want: "carol@yahoo.com"},
+		{email: "Dave.Smith+x@Example.COM.", want: "Dave.Smith+x@example.com"},
+		{email: "+tag@gmail.com", wantErr: ErrInvalidEmail},
+		{email: "no-at.example.com", wantErr: ErrInvalidEmail},
+		{email: "trailing@", wantErr: ErrInvalidEmail},
+	}
+	for _, tt := range tests {
+		t.Run(tt.email, func(t *testing.T) {
+			got, err := NormalizeEmail(tt.email)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestReadDomainList(t *testing.T) {
+	list, err := ReadDomainList(strings.NewReader("# disposable\nmailinator.com\n\n Trash-Mail.com \n"))
+	require.NoError(t, err)
+	for domain, want := range map[string]bool{
+		"mailinator.com":     true,
+		"eu.mailinator.com":  true,
+		"trash-mail.com":     true,
+		"notmailinator.com":  false,
+		"example.com":        false,
+		"mailinator.com.org": false,
+	} {
+		disposable, err := list.IsDisposable(context.Background(), domain)
+		require.NoError(t, err)
+		assert.Equal(t, want, disposable, domain)
+	}
+}
+
+func TestEmailScreener_Screen(t *testing.T) {
+	disposable := NewDomainList("mailinator.com")
+	registered := map[string]bool{"aliceliddell@gmail.com": true}
+	errLookup := errors.New("lookup failed")
+	tests := []struct {
+		name     string
+		screener *EmailScreener
+		email    string
+		want     string
+		wantErr  error
+	}{
+		{
+			name:     "default accepts as provided",
+			screener: NewEmailScreener(),
+			email:    "Alice.Liddell@GMAIL.com",
+			want:     "Alice.Liddell@gmail.com",
+		},
+		{
+			name:     "default rejects disposable",
+			screener: NewEmailScreener(WithDisposableDomains(disposable)),
+			email:    "bob@mailinator.com",
+			wantErr:  ErrEmailRejected,
+		},
+		{
+			name: "normalize",
+			screener: NewEmailScreener(WithDecision(func(context.Context, Screening) (Decision, error) {
+				return DecisionNormalize, nil
+			})),
+			email: "Carol+signup@gmail.com",
+			want:  "carol@gmail.com",
+		},
+		{
+			name: "reject registered variation",
+			screener: NewEmailScreener(WithDecision(func(_ context.Context, s Screening) (Decision, error) {
+				if registered[s.Normalized] {
+					return DecisionReject, nil
+				}
+				return DecisionAccept, nil
+			})),
+			email:   "alice.liddell+2@gmail.com",
+			wantErr: ErrEmailRejected,
+		},
+		{
+			name: "decision error",
+			screener: NewEmailScreener(WithDecision(func(context.Context, Screening) (Decision, error) {
+				return DecisionAccept, errLookup
+			})),
+			email:   "dave@example.com",
+			wantErr: errLookup,
+		},
+		{
+			name:     "invalid",
+			screener: NewEmailScreener(),
+			email:    "invalid",
+			wantErr:  ErrInvalidEmail,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.screener.Screen(context.Background(), tt.email)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,234 @@
+package users
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"regexp"
+
+	"golang.org/x/text/language"
+
+	userV2 "github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/user/v2"
+)
+
+var (
+	ErrInvalidEmail    = errors.New("invalid email")
+	ErrInvalidPhone    = errors.New("invalid phone number, must be in E.164 format")
+	ErrInvalidLanguage = errors.New("invalid language tag")
+	ErrInvalidGender   = errors.New("invalid gender")
+	ErrEmptyField      = errors.New("field must not be empty")
+	// ErrNoChanges is returned by [UpdateBuilder.Do] if no field was set.
+	ErrNoChanges = errors.New("no fields to update")
+	// ErrNotHuman is returned by [UpdateBuilder.Do] if the user is a machine user.
+	ErrNotHuman = errors.New("user is not a human user")
+)
+
+// e164 matches phone numbers in the E.164 format, e.g. `+41791234567`.
+var e164 = regexp.MustCompile(`^\+[1-9][0-9]{1,14}$`)
+
+// UpdateBuilder collects the changes of a human user, created by [Users.Update].
+// Only the set fields are sent, all others keep their current value.
+// The values are validated when set, the errors are returned by [UpdateBuilder.Do].
+type UpdateBuilder struct {
+	client            userV2.UserServiceClient
+	userID            string
+	username          *string
+	givenName         *string
+	familyName        *string
+	nickName          *string
+	displayName       *string
+	preferredLanguage *string
+	gender            *Gender
+	email             *string
+	phone             *string
+	errs              []error
+}
+
+// Update returns an [UpdateBuilder] for the human user:
+//
+//	sequence, err := users.Update(userID).DisplayName("Alice").PreferredLanguage("de").Do(ctx)
+func (u *Users) Update(userID string) *UpdateBuilder {
+	return &UpdateBuilder{client: u.client.UserServiceV2(), userID: userID}
+}
+
+// Username sets the username of the user.
+func (b *UpdateBuilder) Username(username string) *UpdateBuilder {
+	b.username = b.required("username", username)
+	return b
+}
+
+// GivenName sets the given name of the user.
+func (b *UpdateBuilder) GivenName(name string) *UpdateBuilder {
+	b.givenName = b.required("given name", name)
+	return b
+}
+
+// FamilyName sets the family name of the user.
+func (b *UpdateBuilder) FamilyName(name string) *UpdateBuilder {
+	b.familyName = b.required("family name", name)
+	return b
+}
+
+// NickName sets the nickname of the user, an empty name removes it.
+func (b *UpdateBuilder) NickName(name string) *UpdateBuilder {
+	b.nickName = &name
+	return b
+}
+
+// DisplayName sets the display name of the user, ZITADEL uses the given and family name if empty.
+func (b *UpdateBuilder) DisplayName(name string) *UpdateBuilder {
+	b.displayName = &name
+	return b
+}
+
+// PreferredLanguage sets the preferred language of the user as BCP 47 tag, e.g. `de` or `en-US`.
+func (b *UpdateBuilder) PreferredLanguage(lang string) *UpdateBuilder {
+	tag, err := language.Parse(lang)
+	if err != nil {
+		b.errs = append(b.errs, fmt.Errorf("%w: %q", ErrInvalidLanguage, lang))
+		return b
+	}
+	normalized := tag.String()
+	b.preferredLanguage = &normalized
+	return b
+}
+
+// Gender sets the gender of the user.
+func (b *UpdateBuilder) Gender(gender Gender) *UpdateBuilder {
+	switch gender {
+	case GenderUnspecified, GenderFemale, GenderMale, GenderDiverse:
+		b.gender = &gender
+	default:
+		b.errs = append(b.errs, fmt.Errorf("%w: %q", ErrInvalidGender, gender))
+	}
+	return b
+}
+
+// Email sets the email of the user, ZITADEL sends a code to verify the new address.
+func (b *UpdateBuilder) Email(email string) *UpdateBuilder {
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email {
+		b.errs = append(b.errs, fmt.Errorf("%w: %q", ErrInvalidEmail, email))
+		return b
+	}
+	b.email = &email
+	return b
+}
+
+// Phone sets the phone number of the user in the E.164 format (e.g. `+41791234567`),
+// ZITADEL sends a code to verify the new number.
+func (b *UpdateBuilder) Phone(phone string) *UpdateBuilder {
+	if !e164.MatchString(phone) {
+		b.errs = append(b.errs, fmt.Errorf("%w: %q", ErrInvalidPhone, phone))
+		return b
+	}
+	b.phone = &phone
+	return b
+}
+
+func (b *UpdateBuilder) required(field, value string) *string {
+	if value == "" {
+		b.errs = append(b.errs, fmt.Errorf("%w: %s", ErrEmptyField, field))
+		return nil
+	}
+	return &value
+}
+
+// Do validates and sends the changes and returns the sequence of the user after the update,
+// which can be used to wait for projections to catch up.
+// Fields equal to the current values of the user are not sent, if none differs the current sequence is returned
+// without an update.
+func (b *UpdateBuilder) Do(ctx context.Context) (uint64, error) {
+	if err := errors.Join(b.errs...); err != nil {
+		return 0, err
+	}
+	if b.empty() {
+		return 0, ErrNoChanges
+	}
+	resp, err := b.client.GetUserByID(ctx, &userV2.GetUserByIDRequest{UserId: b.userID})
+	if err != nil {
+		return 0, err
+	}
+	current := resp.GetUser()
+	if current.GetHuman() == nil {
+		return 0, ErrNotHuman
+	}
+	req := b.request(current)
+	if req == nil {
+		return current.GetDetails().GetSequence(), nil
+	}
+	updated, err := b.client.UpdateHumanUser(ctx, req)
+	if err != nil {
+		return 0, err
+	}
+	return updated.GetDetails().GetSequence(), nil
+}
+
+func (b *UpdateBuilder) empty() bool {
+	return b.username == nil && b.givenName == nil && b.familyName == nil && b.nickName == nil &&
+		b.displayName == nil && b.preferredLanguage == nil && b.gender == nil && b.email == nil && b.phone == nil
+}
+
+// request returns the update of the fields differing from the current user, nil if none differs.
+// The profile can only be set as a whole, so its unchanged fields are filled in from the current user.
+func (b *UpdateBuilder) request(current *userV2.User) *userV2.UpdateHumanUserRequest {
+	human := current.GetHuman()
+	req := &userV2.UpdateHumanUserRequest{UserId: b.userID}
+	changed := false
+	if b.username != nil && *b.username != current.GetUsername() {
+		req.Username, changed = b.username, true
+	}
+	if profile, ok := b.profile(human.GetProfile()); ok {
+		req.Profile, changed = profile, true
+	}
+	if b.email != nil && *b.email != human.GetEmail().GetEmail() {
+		req.Email, changed = &userV2.SetHumanEmail{Email: *b.email}, true
+	}
+	if b.phone != nil && *b.phone != human.GetPhone().GetPhone() {
+		req.Phone, changed = &userV2.SetHumanPhone{Phone: *b.phone}, true
+	}
+	if !changed {
+		return nil
+	}
+	return req
+}
+
+// profile merges the set fields into the current profile and returns if any of them differs.
+func (b *UpdateBuilder) profile(current *userV2.HumanProfile) (*userV2.SetHumanProfile, bool) {
+	profile := &userV2.SetHumanProfile{
+		GivenName:         current.GetGivenName(),
+		FamilyName:        current.GetFamilyName(),
+		NickName:          optional(current.GetNickName()),
+		DisplayName:       optional(current.GetDisplayName()),
+		PreferredLanguage: optional(current.GetPreferredLanguage()),
+		Gender:            genderToProto(genderFromProto(current.GetGender())),
+	}
+	changed := false
+	set := func(target *string, value *string) {
+		if value != nil && *value != *target {
+			*target, changed = *value, true
+		}
+	}
+	setOptional := func(target **string, value *string) {
+		if value != nil && *value != deref(*target) {
+			*target, changed = optional(*value), true
+		}
+	}
+	set(&profile.GivenName, b.givenName)
+	set(&profile.FamilyName, b.familyName)
+	setOptional(&profile.NickName, b.nickName)
+	setOptional(&profile.DisplayName, b.displayName)
+	setOptional(&profile.PreferredLanguage, b.preferredLanguage)
+	if b.gender != nil && *b.gender != genderFromProto(current.GetGender()) {
+		profile.Gender, changed = genderToProto(*b.gender), true
+	}
+	return profile, changed
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
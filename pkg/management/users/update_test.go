@@ -0,0 +1,143 @@
+package users
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+
+	objectV2 "github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/object/v2"
+	userV2 "github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/user/v2"
+)
+
+type fakeUserService struct {
+	userV2.UserServiceClient
+	user    *userV2.User
+	updates []*userV2.UpdateHumanUserRequest
+}
+
+func (f *fakeUserService) GetUserByID(context.Context, *userV2.GetUserByIDRequest, ...grpc.CallOption) (*userV2.GetUserByIDResponse, error) {
+	return &userV2.GetUserByIDResponse{User: f.user}, nil
+}
+
+func (f *fakeUserService) UpdateHumanUser(_ context.Context, req *userV2.UpdateHumanUserRequest, _ ...grpc.CallOption) (*userV2.UpdateHumanUserResponse, error) {
+	f.updates = append(f.updates, req)
+	return &userV2.UpdateHumanUserResponse{Details: &objectV2.Details{Sequence: f.user.GetDetails().GetSequence() + 1}}, nil
+}
+
+func currentHuman() *userV2.User {
+	return &userV2.User{
+		UserId:   "user",
+		Username: "alice",
+		Details:  &objectV2.Details{Sequence: 10},
+		Type: &userV2.User_Human{Human: &userV2.HumanUser{
+			Profile: &userV2.HumanProfile{
+				GivenName:         "Alice",
+				FamilyName:        "Doe",
+				DisplayName:       proto.String("Alice Doe"),
+				PreferredLanguage: proto.String("en"),
+			},
+			Email: &userV2.HumanEmail{Email: "alice@example.com"},
+		}},
+	}
+}
+
+func TestUpdateBuilder_Do(t *testing.T) {
+	tests := []struct {
+		name         string
+		build        func(*UpdateBuilder) *UpdateBuilder
+		wantSequence uint64
+		wantUpdate   *userV2.UpdateHumanUserRequest
+		wantErr      error
+	}{
+		{
+			name: "profile merged with current",
+			build: func(b *UpdateBuilder) *UpdateBuilder {
+				return b.DisplayName("Ally").PreferredLanguage("de-ch")
+			},
+			wantSequence: 11,
+			wantUpdate: &userV2.UpdateHumanUserRequest{
+				UserId: "user",
+				Profile: &userV2.SetHumanProfile{
+					GivenName:         "Alice",
+					FamilyName:        "Doe",
+					DisplayName:       proto.String("Ally"),
+					PreferredLanguage: proto.String("de-CH"),
+				},
+			},
+		},
+		{
+			name: "only changed fields",
+			build: func(b *UpdateBuilder) *UpdateBuilder {
+				return b.Username("alice").Email("alice@example.com").Phone("+41791234567")
+			},
+			wantSequence: 11,
+			wantUpdate: &userV2.UpdateHumanUserRequest{
+				UserId: "user",
+				Phone:  &userV2.SetHumanPhone{Phone: "+41791234567"},
+			},
+		},
+		{
+			name: "unchanged",
+			build: func(b *UpdateBuilder) *UpdateBuilder {
+				return b.GivenName("Alice").PreferredLanguage("en")
+			},
+			wantSequence: 10,
+		},
+		{
+			name:    "no fields",
+			build:   func(b *UpdateBuilder) *UpdateBuilder { return b },
+			wantErr: ErrNoChanges,
+		},
+		{
+			name: "invalid email",
+			build: func(b *UpdateBuilder) *UpdateBuilder {
+				return b.DisplayName("Ally").Email("Alice <alice@example.com>")
+			},
+			wantErr: ErrInvalidEmail,
+		},
+		{
+			name:    "invalid phone",
+			build:   func(b *UpdateBuilder) *UpdateBuilder { return b.Phone("079 123 45 67") },
+			wantErr: ErrInvalidPhone,
+		},
+		{
+			name:    "invalid language",
+			build:   func(b *UpdateBuilder) *UpdateBuilder { return b.PreferredLanguage("not a language") },
+			wantErr: ErrInvalidLanguage,
+		},
+		{
+			name:    "empty given name",
+			build:   func(b *UpdateBuilder) *UpdateBuilder { return b.GivenName("") },
+			wantErr: ErrEmptyField,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeUserService{user: currentHuman()}
+			sequence, err := tt.build(&UpdateBuilder{client: fake, userID: "user"}).Do(context.Background())
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				assert.Empty(t, fake.updates)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantSequence, sequence)
+			if tt.wantUpdate == nil {
+				assert.Empty(t, fake.updates)
+				return
+			}
+			require.Len(t, fake.updates, 1)
+			assert.True(t, proto.Equal(tt.wantUpdate, fake.updates[0]), "got %v", fake.updates[0])
+		})
+	}
+}
+
+func TestUpdateBuilder_Do_machine(t *testing.T) {
+	fake := &fakeUserService{user: &userV2.User{UserId: "user", Type: &userV2.User_Machine{Machine: &userV2.MachineUser{}}}}
+	_, err := (&UpdateBuilder{client: fake, userID: "user"}).DisplayName("bot").Do(context.Background())
+	assert.ErrorIs(t, err, ErrNotHuman)
+}
@@ -0,0 +1,249 @@
+// Package users provides a high level facade of the user service (v2) for common user management tasks,
+// e.g. creating and inviting human users (optionally screening their emails), setting passwords, registering OTP
+// and handling the user lifecycle.
+// The operations combine the necessary calls and return simplified [User] structs instead of the protos.
+package users
+
+import (
+	"context"
+	"time"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client"
+	userV2 "github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/user/v2"
+)
+
+// State is the lifecycle state of a [User].
+type State string
+
+const (
+	StateUnspecified State = ""
+	StateInitial     State = "initial"
+	StateActive      State = "active"
+	StateInactive    State = "inactive"
+	StateLocked      State = "locked"
+	StateDeleted     State = "deleted"
+)
+
+// Gender of a human [User].
+type Gender string
+
+const (
+	GenderUnspecified Gender = ""
+	GenderFemale      Gender = "female"
+	GenderMale        Gender = "male"
+	GenderDiverse     Gender = "diverse"
+)
+
+// User is the simplified representation of a human or machine user.
+type User struct {
+	ID                 string
+	OrganizationID     string
+	State              State
+	Username           string
+	LoginNames         []string
+	PreferredLoginName string
+	CreatedAt          time.Time
+	ChangedAt          time.Time
+
+	// Human is set for human users.
+	Human *Human
+	// Machine is set for machine users.
+	Machine *Machine
+}
+
+// Human contains the profile and contact data of a human [User].
+type Human struct {
+	GivenName              string
+	FamilyName             string
+	NickName               string
+	DisplayName            string
+	PreferredLanguage      string
+	Gender                 Gender
+	AvatarURL              string
+	Email                  string
+	EmailVerified          bool
+	Phone                  string
+	PhoneVerified          bool
+	PasswordChangeRequired bool
+	PasswordChanged        time.Time
+}
+
+// Machine contains the data of a machine [User].
+type Machine struct {
+	Name        string
+	Description string
+	HasSecret   bool
+}
+
+// Users provides the user management operations.
+type Users struct {
+	client *client.Client
+}
+
+// New creates [Users] using the client.
+func New(client *client.Client) *Users {
+	return &Users{client: client}
+}
+
+// GetUser returns the user by its ID.
+func (u *Users) GetUser(ctx context.Context, userID string) (*User, error) {
+	resp, err := u.client.UserServiceV2().GetUserByID(ctx, &userV2.GetUserByIDRequest{UserId: userID})
+	if err != nil {
+		return nil, err
+	}
+	return userFromProto(resp.GetUser()), nil
+}
+
+// DeactivateUser deactivates the user, so it can no longer log in.
+// Locked users are unlocked first, as only active users can be deactivated.
+// Nothing is done if the user is already inactive.
+func (u *Users) DeactivateUser(ctx context.Context, userID string) error {
+	user, err := u.GetUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	switch user.State {
+	case StateInactive:
+		return nil
+	case StateLocked:
+		if err = u.UnlockUser(ctx, userID); err != nil {
+			return err
+		}
+	}
+	_, err = u.client.UserServiceV2().DeactivateUser(ctx, &userV2.DeactivateUserRequest{UserId: userID})
+	return err
+}
+
+// ReactivateUser reactivates a deactivated user.
+// Nothing is done if the user is not inactive.
+func (u *Users) ReactivateUser(ctx context.Context, userID string) error {
+	user, err := u.GetUser(ctx, userID)
+	if err != nil || user.State != StateInactive {
+		return err
+	}
+	_, err = u.client.UserServiceV2().ReactivateUser(ctx, &userV2.ReactivateUserRequest{UserId: userID})
+	return err
+}
+
+// LockUser locks the user, so it can no longer log in until unlocked.
+// Nothing is done if the user is already locked.
+func (u *Users) LockUser(ctx context.Context, userID string) error {
+	user, err := u.GetUser(ctx, userID)
+	if err != nil || user.State == StateLocked {
+		return err
+	}
+	_, err = u.client.UserServiceV2().LockUser(ctx, &userV2.LockUserRequest{UserId: userID})
+	return err
+}
+
+// UnlockUser unlocks a locked user.
+// Nothing is done if the user is not locked.
+func (u *Users) UnlockUser(ctx context.Context, userID string) error {
+	user, err := u.GetUser(ctx, userID)
+	if err != nil || user.State != StateLocked {
+		return err
+	}
+	_, err = u.client.UserServiceV2().UnlockUser(ctx, &userV2.UnlockUserRequest{UserId: userID})
+	return err
+}
+
+// DeleteUser deletes the user.
+func (u *Users) DeleteUser(ctx context.Context, userID string) error {
+	_, err := u.client.UserServiceV2().DeleteUser(ctx, &userV2.DeleteUserRequest{UserId: userID})
+	return err
+}
+
+func userFromProto(user *userV2.User) *User {
+	if user == nil {
+		return nil
+	}
+	u := &User{
+		ID:                 user.GetUserId(),
+		OrganizationID:     user.GetDetails().GetResourceOwner(),
+		State:              stateFromProto(user.GetState()),
+		Username:           user.GetUsername(),
+		LoginNames:         user.GetLoginNames(),
+		PreferredLoginName: user.GetPreferredLoginName(),
+	}
+	if details := user.GetDetails(); details != nil {
+		if details.GetCreationDate() != nil {
+			u.CreatedAt = details.GetCreationDate().AsTime()
+		}
+		if details.GetChangeDate() != nil {
+			u.ChangedAt = details.GetChangeDate().AsTime()
+		}
+	}
+	if human := user.GetHuman(); human != nil {
+		profile := human.GetProfile()
+		u.Human = &Human{
+			GivenName:              profile.GetGivenName(),
+			FamilyName:             profile.GetFamilyName(),
+			NickName:               profile.GetNickName(),
+			DisplayName:            profile.GetDisplayName(),
+			PreferredLanguage:      profile.GetPreferredLanguage(),
+			Gender:                 genderFromProto(profile.GetGender()),
+			AvatarURL:              profile.GetAvatarUrl(),
+			Email:                  human.GetEmail().GetEmail(),
+			EmailVerified:          human.GetEmail().GetIsVerified(),
+			Phone:                  human.GetPhone().GetPhone(),
+			PhoneVerified:          human.GetPhone().GetIsVerified(),
+			PasswordChangeRequired: human.GetPasswordChangeRequired(),
+		}
+		if human.GetPasswordChanged() != nil {
+			u.Human.PasswordChanged = human.GetPasswordChanged().AsTime()
+		}
+	}
+	if machine := user.GetMachine(); machine != nil {
+		u.Machine = &Machine{
+			Name:        machine.GetName(),
+			Description: machine.GetDescription(),
+			HasSecret:   machine.GetHasSecret(),
+		}
+	}
+	return u
+}
+
+func stateFromProto(state userV2.UserState) State {
+	switch state {
+	case userV2.UserState_USER_STATE_INITIAL:
+		return StateInitial
+	case userV2.UserState_USER_STATE_ACTIVE:
+		return StateActive
+	case userV2.UserState_USER_STATE_INACTIVE:
+		return StateInactive
+	case userV2.UserState_USER_STATE_LOCKED:
+		return StateLocked
+	case userV2.UserState_USER_STATE_DELETED:
+		return StateDeleted
+	default:
+		return StateUnspecified
+	}
+}
+
+func genderFromProto(gender userV2.Gender) Gender {
+	switch gender {
+	case userV2.Gender_GENDER_FEMALE:
+		return GenderFemale
+	case userV2.Gender_GENDER_MALE:
+		return GenderMale
+	case userV2.Gender_GENDER_DIVERSE:
+		return GenderDiverse
+	default:
+		return GenderUnspecified
+	}
+}
+
+func genderToProto(gender Gender) *userV2.Gender {
+	var g userV2.Gender
+	switch gender {
+	case GenderFemale:
+		g = userV2.Gender_GENDER_FEMALE
+	case GenderMale:
+		g = userV2.Gender_GENDER_MALE
+	case GenderDiverse:
+		g = userV2.Gender_GENDER_DIVERSE
+	default:
+		return nil
+	}
+	return &g
+}
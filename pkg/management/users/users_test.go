@@ -0,0 +1,98 @@
+package users
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	objectV2 "github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/object/v2"
+	userV2 "github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/user/v2"
+)
+
+func Test_addHumanUserRequest(t *testing.T) {
+	user := HumanUser{OrganizationID: "org", Email: "alice@example.com", GivenName: "Alice", FamilyName: "Doe"}
+	tests := []struct {
+		name string
+		opts []Option
+		want *userV2.AddHumanUserRequest
+	}{
+		{
+			name: "defaults",
+			want: &userV2.AddHumanUserRequest{
+				Organization: &objectV2.Organization{Org: &objectV2.Organization_OrgId{OrgId: "org"}},
+				Profile:      &userV2.SetHumanProfile{GivenName: "Alice", FamilyName: "Doe"},
+				Email: &userV2.SetHumanEmail{
+					Email:        "alice@example.com",
+					Verification: &userV2.SetHumanEmail_SendCode{SendCode: &userV2.SendEmailVerificationCode{}},
+				},
+			},
+		},
+		{
+			name: "options",
+			opts: []Option{
+				WithGender(GenderDiverse),
+				WithVerifiedEmail(),
+				WithPhone("+41790000000"),
+				WithReturnPhoneCode(),
+				WithPassword("Password1!", true),
+			},
+			want: &userV2.AddHumanUserRequest{
+				Organization: &objectV2.Organization{Org: &objectV2.Organization_OrgId{OrgId: "org"}},
+				Profile: &userV2.SetHumanProfile{
+					GivenName:  "Alice",
+					FamilyName: "Doe",
+					Gender:     userV2.Gender_GENDER_DIVERSE.Enum(),
+				},
+				Email: &userV2.SetHumanEmail{
+					Email:        "alice@example.com",
+					Verification: &userV2.SetHumanEmail_IsVerified{IsVerified: true},
+				},
+				Phone: &userV2.SetHumanPhone{
+					Phone:        "+41790000000",
+					Verification: &userV2.SetHumanPhone_ReturnCode{ReturnCode: &userV2.ReturnPhoneVerificationCode{}},
+				},
+				PasswordType: &userV2.AddHumanUserRequest_Password{
+					Password: &userV2.Password{Password: "Password1!", ChangeRequired: true},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := new(createConfig)
+			for _, opt := range tt.opts {
+				opt(c)
+			}
+			got := addHumanUserRequest(user, c)
+			assert.True(t, proto.Equal(tt.want, got), "got %v", got)
+		})
+	}
+}
+
+func Test_userFromProto(t *testing.T) {
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	got := userFromProto(&userV2.User{
+		UserId:  "user",
+		Details: &objectV2.Details{ResourceOwner: "org", CreationDate: timestamppb.New(created)},
+		State:   userV2.UserState_USER_STATE_LOCKED,
+		Type: &userV2.User_Human{Human: &userV2.HumanUser{
+			Profile: &userV2.HumanProfile{GivenName: "Alice", Gender: userV2.Gender_GENDER_FEMALE.Enum()},
+			Email:   &userV2.HumanEmail{Email: "alice@example.com", IsVerified: true},
+		}},
+	})
+	assert.Equal(t, &User{
+		ID:             "user",
+		OrganizationID: "org",
+		State:          StateLocked,
+		CreatedAt:      created,
+		Human: &Human{
+			GivenName:     "Alice",
+			Gender:        GenderFemale,
+			Email:         "alice@example.com",
+			EmailVerified: true,
+		},
+	}, got)
+}
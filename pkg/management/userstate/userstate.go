@@ -0,0 +1,124 @@
+// Package userstate models the lifecycle states of ZITADEL users and the allowed transitions between them.
+// [StateMachine.Transition] chooses the necessary calls of the user service (v2) to reach a target state
+// (e.g. unlock and deactivate a locked user) and rejects transitions ZITADEL does not allow with an [ErrInvalidTransition].
+//
+//	initial  -> locked
+//	active   -> inactive, locked
+//	inactive -> active
+//	locked   -> active, inactive
+package userstate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client"
+	userV2 "github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/user/v2"
+	"github.com/zitadel/zitadel-go/v3/pkg/management/users"
+)
+
+var ErrInvalidTransition = errors.New("invalid user state transition")
+
+// State is the lifecycle state of a user, see [users.State].
+type State = users.State
+
+const (
+	StateInitial  = users.StateInitial
+	StateActive   = users.StateActive
+	StateInactive = users.StateInactive
+	StateLocked   = users.StateLocked
+)
+
+// Action is a call of the user service changing the state of a user.
+type Action string
+
+const (
+	ActionDeactivate Action = "deactivate"
+	ActionReactivate Action = "reactivate"
+	ActionLock       Action = "lock"
+	ActionUnlock     Action = "unlock"
+)
+
+var transitions = map[State]map[State][]Action{
+	StateInitial: {
+		StateLocked: {ActionLock},
+	},
+	StateActive: {
+		StateInactive: {ActionDeactivate},
+		StateLocked:   {ActionLock},
+	},
+	StateInactive: {
+		StateActive: {ActionReactivate},
+	},
+	StateLocked: {
+		StateActive:   {ActionUnlock},
+		StateInactive: {ActionUnlock, ActionDeactivate},
+	},
+}
+
+// Plan returns the actions needed to change a user from the state to the target state.
+// No actions are returned if both are equal, an [ErrInvalidTransition] if the transition is not allowed.
+func Plan(from, target State) ([]Action, error) {
+	if from == target {
+		if _, ok := transitions[from]; ok {
+			return nil, nil
+		}
+	}
+	actions, ok := transitions[from][target]
+	if !ok {
+		return nil, fmt.Errorf("%w: from %q to %q", ErrInvalidTransition, from, target)
+	}
+	return actions, nil
+}
+
+// Allowed returns if the user can be changed from the state to the target state.
+func Allowed(from, target State) bool {
+	_, err := Plan(from, target)
+	return err == nil
+}
+
+// StateMachine changes the state of users.
+type StateMachine struct {
+	client *client.Client
+	users  *users.Users
+}
+
+// New creates the [StateMachine] using the client.
+func New(client *client.Client) *StateMachine {
+	return &StateMachine{client: client, users: users.New(client)}
+}
+
+// Transition changes the user to the target state using the actions of [Plan].
+// Nothing is done if the user is already in the target state.
+func (m *StateMachine) Transition(ctx context.Context, userID string, target State) error {
+	user, err := m.users.GetUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	actions, err := Plan(user.State, target)
+	if err != nil {
+		return err
+	}
+	for _, action := range actions {
+		if err = m.do(ctx, userID, action); err != nil {
+			return fmt.Errorf("%s user: %w", action, err)
+		}
+	}
+	return nil
+}
+
+func (m *StateMachine) do(ctx context.Context, userID string, action Action) (err error) {
+	service := m.client.UserServiceV2()
+	switch action {
+	case ActionDeactivate:
+		_, err = service.DeactivateUser(ctx, &userV2.DeactivateUserRequest{UserId: userID})
+	case ActionReactivate:
+		_, err = service.ReactivateUser(ctx, &userV2.ReactivateUserRequest{UserId: userID})
+	case ActionLock:
+		_, err = service.LockUser(ctx, &userV2.LockUserRequest{UserId: userID})
+	case ActionUnlock:
+		_, err = service.UnlockUser(ctx, &userV2.UnlockUserRequest{UserId: userID})
+	}
+	return err
+}
@@ -0,0 +1,40 @@
+package userstate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/management/users"
+)
+
+func TestPlan(t *testing.T) {
+	tests := []struct {
+		name    string
+		from    State
+		target  State
+		want    []Action
+		wantErr error
+	}{
+		{"active to inactive", StateActive, StateInactive, []Action{ActionDeactivate}, nil},
+		{"active to locked", StateActive, StateLocked, []Action{ActionLock}, nil},
+		{"inactive to active", StateInactive, StateActive, []Action{ActionReactivate}, nil},
+		{"locked to active", StateLocked, StateActive, []Action{ActionUnlock}, nil},
+		{"locked to inactive", StateLocked, StateInactive, []Action{ActionUnlock, ActionDeactivate}, nil},
+		{"initial to locked", StateInitial, StateLocked, []Action{ActionLock}, nil},
+		{"unchanged", StateActive, StateActive, nil, nil},
+		{"initial to active", StateInitial, StateActive, nil, ErrInvalidTransition},
+		{"initial to inactive", StateInitial, StateInactive, nil, ErrInvalidTransition},
+		{"inactive to locked", StateInactive, StateLocked, nil, ErrInvalidTransition},
+		{"active to initial", StateActive, StateInitial, nil, ErrInvalidTransition},
+		{"deleted", users.StateDeleted, users.StateDeleted, nil, ErrInvalidTransition},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Plan(tt.from, tt.target)
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr == nil, Allowed(tt.from, tt.target))
+		})
+	}
+}
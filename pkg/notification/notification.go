@@ -0,0 +1,219 @@
+// Package notification dispatches user lifecycle events of ZITADEL (e.g. created, email verified, locked, deleted)
+// to registered handlers, such as Go functions or outbound webhooks (see [NewWebhook]),
+// so external systems like a CRM can be kept in sync without a custom poller.
+package notification
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client"
+	"github.com/zitadel/zitadel-go/v3/pkg/events"
+)
+
+// Kind is the user lifecycle event a handler can be registered for.
+type Kind string
+
+const (
+	KindCreated       Kind = "created"
+	KindEmailVerified Kind = "email_verified"
+	KindLocked        Kind = "locked"
+	KindUnlocked      Kind = "unlocked"
+	KindDeactivated   Kind = "deactivated"
+	KindReactivated   Kind = "reactivated"
+	KindDeleted       Kind = "deleted"
+)
+
+// eventTypes maps the event types of ZITADEL to the lifecycle kinds.
+var eventTypes = map[string]Kind{
+	"user.human.added":          KindCreated,
+	"user.human.selfregistered": KindCreated,
+	"user.machine.added":        KindCreated,
+	"user.human.email.verified": KindEmailVerified,
+	"user.locked":               KindLocked,
+	"user.unlocked":             KindUnlocked,
+	"user.deactivated":          KindDeactivated,
+	"user.reactivated":          KindReactivated,
+	"user.removed":              KindDeleted,
+}
+
+const (
+	aggregateTypeUser = "user"
+	defaultInterval   = 10 * time.Second
+	pageSize          = 100
+)
+
+var ErrNoHandlers = errors.New("no handlers registered")
+
+// Event is a user lifecycle event.
+type Event struct {
+	Kind           Kind   `json:"kind"`
+	UserID         string `json:"userId"`
+	OrganizationID string `json:"organizationId"`
+	// EventType is the type of the event in ZITADEL, e.g. `user.human.added`.
+	EventType    string         `json:"eventType"`
+	Sequence     uint64         `json:"sequence"`
+	CreationDate time.Time      `json:"creationDate"`
+	EditorUserID string         `json:"editorUserId,omitempty"`
+	Payload      map[string]any `json:"payload,omitempty"`
+}
+
+// Handler handles the dispatched events.
+type Handler interface {
+	Handle(ctx context.Context, event *Event) error
+}
+
+// HandlerFunc allows the use of ordinary functions as [Handler].
+type HandlerFunc func(ctx context.Context, event *Event) error
+
+// Handle implements [Handler]
+func (f HandlerFunc) Handle(ctx context.Context, event *Event) error {
+	return f(ctx, event)
+}
+
+// Dispatcher polls the events of ZITADEL and dispatches the user lifecycle events to the registered handlers.
+type Dispatcher struct {
+	client   *client.Client
+	interval time.Duration
+	from     time.Time
+	onError  func(event *Event, err error)
+
+	mu       sync.RWMutex
+	handlers map[Kind][]Handler
+}
+
+// Option allows customization of the [Dispatcher].
+type Option func(*Dispatcher)
+
+// WithInterval sets the polling interval (default 10 seconds).
+func WithInterval(interval time.Duration) Option {
+	return func(d *Dispatcher) {
+		d.interval = interval
+	}
+}
+
+// WithFrom sets the creation date from which on events are dispatched (default start of [Dispatcher.Run]).
+// Use it to resume dispatching after a restart.
+func WithFrom(from time.Time) Option {
+	return func(d *Dispatcher) {
+		d.from = from
+	}
+}
+
+// WithErrorHandler sets the function called if a handler fails or the payload of an event can't be parsed
+// (default: logged using [slog]). Failed events are not retried by the [Dispatcher].
+func WithErrorHandler(onError func(event *Event, err error)) Option {
+	return func(d *Dispatcher) {
+		d.onError = onError
+	}
+}
+
+// New creates a [Dispatcher]. The client must be authorized to read the events of the instance (e.g. IAM_OWNER_VIEWER).
+func New(client *client.Client, options ...Option) *Dispatcher {
+	d := &Dispatcher{
+		client:   client,
+		interval: defaultInterval,
+		handlers: make(map[Kind][]Handler),
+		onError: func(event *Event, err error) {
+			slog.Error("notification handler failed", "kind", event.Kind, "user", event.UserID, "err", err)
+		},
+	}
+	for _, option := range options {
+		option(d)
+	}
+	return d
+}
+
+// Handle registers the handler for the kinds of events, all kinds if none is provided.
+func (d *Dispatcher) Handle(handler Handler, kinds ...Kind) {
+	if len(kinds) == 0 {
+		kinds = allKinds()
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, kind := range kinds {
+		d.handlers[kind] = append(d.handlers[kind], handler)
+	}
+}
+
+// HandleFunc registers the function for the kinds of events, all kinds if none is provided.
+func (d *Dispatcher) HandleFunc(f func(ctx context.Context, event *Event) error, kinds ...Kind) {
+	d.Handle(HandlerFunc(f), kinds...)
+}
+
+// Run polls and dispatches the events until the context is done.
+// Handlers are called sequentially in order of the events.
+func (d *Dispatcher) Run(ctx context.Context) error {
+	eventTypes := d.eventTypes()
+	if len(eventTypes) == 0 {
+		return ErrNoHandlers
+	}
+	from := d.from
+	if from.IsZero() {
+		from = time.Now()
+	}
+	subscriber := events.New(d.client, events.WithInterval(d.interval), events.WithFrom(from), events.WithPageSize(pageSize))
+	userEvents, err := subscriber.Subscribe(ctx, events.Filter{
+		AggregateTypes: []string{aggregateTypeUser},
+		EventTypes:     eventTypes,
+	})
+	if err != nil {
+		return err
+	}
+	// temporary errors are retried with the next poll
+	for e := range userEvents {
+		event, err := newEvent(e)
+		if err != nil {
+			d.onError(event, err)
+			continue
+		}
+		d.dispatch(ctx, event)
+	}
+	return ctx.Err()
+}
+
+func (d *Dispatcher) dispatch(ctx context.Context, event *Event) {
+	d.mu.RLock()
+	handlers := d.handlers[event.Kind]
+	d.mu.RUnlock()
+	for _, handler := range handlers {
+		if err := handler.Handle(ctx, event); err != nil {
+			d.onError(event, err)
+		}
+	}
+}
+
+func (d *Dispatcher) eventTypes() []string {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	types := make([]string, 0, len(eventTypes))
+	for eventType, kind := range eventTypes {
+		if len(d.handlers[kind]) > 0 {
+			types = append(types, eventType)
+		}
+	}
+	return types
+}
+
+func newEvent(e *events.Event) (*Event, error) {
+	event := &Event{
+		Kind:           eventTypes[e.Type],
+		UserID:         e.AggregateID,
+		OrganizationID: e.ResourceOwner,
+		EventType:      e.Type,
+		Sequence:       e.Sequence,
+		CreationDate:   e.CreationDate,
+		EditorUserID:   e.EditorUserID,
+	}
+	if err := e.Decode(&event.Payload); err != nil {
+		return event, err
+	}
+	return event, nil
+}
+
+func allKinds() []Kind {
+	return []Kind{KindCreated, KindEmailVerified, KindLocked, KindUnlocked, KindDeactivated, KindReactivated, KindDeleted}
+}
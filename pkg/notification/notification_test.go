@@ -0,0 +1,119 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/admin"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/event"
+	"github.com/zitadel/zitadel-go/v3/pkg/resources/resourcestest"
+)
+
+// eventStore serves the events created at or after the requested date on the HTTP/JSON endpoint of ListEvents.
+type eventStore struct {
+	mu     sync.Mutex
+	events []*event.Event
+}
+
+func (s *eventStore) add(userID, eventType string, sequence uint64, created time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	payload, _ := structpb.NewStruct(map[string]any{"userName": userID})
+	s.events = append(s.events, &event.Event{
+		Aggregate:    &event.Aggregate{Id: userID, Type: &event.AggregateType{Type: aggregateTypeUser}, ResourceOwner: "org1"},
+		Sequence:     sequence,
+		CreationDate: timestamppb.New(created),
+		Type:         &event.EventType{Type: eventType},
+		Payload:      payload,
+	})
+}
+
+func (s *eventStore) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/admin/v1/events/_search" {
+		http.NotFound(w, r)
+		return
+	}
+	body, _ := io.ReadAll(r.Body)
+	req := new(admin.ListEventsRequest)
+	if err := protojson.Unmarshal(body, req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	resp := new(admin.ListEventsResponse)
+	for _, e := range s.events {
+		if from := req.GetFrom(); from != nil && e.GetCreationDate().AsTime().Before(from.AsTime()) {
+			continue
+		}
+		if uint32(len(resp.Events)) == req.GetLimit() {
+			break
+		}
+		resp.Events = append(resp.Events, e)
+	}
+	data, _ := protojson.Marshal(resp)
+	_, _ = w.Write(data)
+}
+
+func TestDispatcher_Run(t *testing.T) {
+	start := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	store := new(eventStore)
+	// more than a page of events created at the same time, e.g. by a bulk import
+	const sameTime = 2*pageSize + 50
+	for i := 0; i < sameTime; i++ {
+		store.add(fmt.Sprintf("user%d", i), "user.human.added", 1, start)
+	}
+	store.add("user0", "user.locked", 2, start.Add(time.Second))
+
+	d := New(resourcestest.NewClient(t, store.ServeHTTP), WithInterval(10*time.Millisecond), WithFrom(start))
+	received := make(chan *Event, sameTime+1)
+	d.HandleFunc(func(_ context.Context, event *Event) error {
+		received <- event
+		return nil
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error)
+	go func() { done <- d.Run(ctx) }()
+
+	users := make(map[string]bool)
+	for i := 0; i < sameTime; i++ {
+		select {
+		case e := <-received:
+			assert.Equal(t, KindCreated, e.Kind)
+			assert.Equal(t, map[string]any{"userName": e.UserID}, e.Payload)
+			assert.False(t, users[e.UserID], "dispatched twice: %s", e.UserID)
+			users[e.UserID] = true
+		case <-time.After(5 * time.Second):
+			t.Fatalf("dispatched %d of %d events", i, sameTime)
+		}
+	}
+	select {
+	case e := <-received:
+		assert.Equal(t, KindLocked, e.Kind)
+		assert.Equal(t, "user0", e.UserID)
+		assert.Equal(t, "org1", e.OrganizationID)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the event after the events created at the same time was not dispatched")
+	}
+
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+	assert.Empty(t, received, "no event dispatched twice")
+}
+
+func TestDispatcher_Run_noHandlers(t *testing.T) {
+	d := New(resourcestest.NewClient(t, new(eventStore).ServeHTTP))
+	require.ErrorIs(t, d.Run(context.Background()), ErrNoHandlers)
+}
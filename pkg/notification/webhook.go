@@ -0,0 +1,100 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"text/template"
+)
+
+// Webhook is a [Handler] posting the events to an outbound webhook.
+// The body is rendered from a [text/template] with the [Event] as data,
+// the template functions include `json` to encode a value as JSON.
+type Webhook struct {
+	url         string
+	body        *template.Template
+	headers     http.Header
+	contentType string
+	httpClient  *http.Client
+}
+
+// WebhookOption allows customization of the [Webhook].
+type WebhookOption func(*Webhook)
+
+// WithHeader adds a header to every request, e.g. for authorization of the receiver.
+func WithHeader(key, value string) WebhookOption {
+	return func(w *Webhook) {
+		w.headers.Add(key, value)
+	}
+}
+
+// WithContentType sets the content type of the body (default `application/json`).
+func WithContentType(contentType string) WebhookOption {
+	return func(w *Webhook) {
+		w.contentType = contentType
+	}
+}
+
+// WithHTTPClient sets the client used to call the webhook (default [http.DefaultClient]).
+func WithHTTPClient(client *http.Client) WebhookOption {
+	return func(w *Webhook) {
+		w.httpClient = client
+	}
+}
+
+// DefaultWebhookTemplate sends the [Event] encoded as JSON.
+const DefaultWebhookTemplate = `{{ json . }}`
+
+// NewWebhook creates a [Webhook] posting to the url with the body rendered from the template
+// (e.g. `{"email": {{ json .Payload.email }}, "type": "{{ .Kind }}"}`), see also [DefaultWebhookTemplate].
+func NewWebhook(url, bodyTemplate string, options ...WebhookOption) (*Webhook, error) {
+	body, err := template.New("webhook").Funcs(template.FuncMap{"json": toJSON}).Option("missingkey=zero").Parse(bodyTemplate)
+	if err != nil {
+		return nil, err
+	}
+	w := &Webhook{
+		url:         url,
+		body:        body,
+		headers:     make(http.Header),
+		contentType: "application/json",
+		httpClient:  http.DefaultClient,
+	}
+	for _, option := range options {
+		option(w)
+	}
+	return w, nil
+}
+
+// Handle implements [Handler]
+func (w *Webhook) Handle(ctx context.Context, event *Event) error {
+	body := new(bytes.Buffer)
+	if err := w.body.Execute(body, event); err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, body)
+	if err != nil {
+		return err
+	}
+	for key, values := range w.headers {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", w.contentType)
+	resp, err := w.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func toJSON(v any) (string, error) {
+	data, err := json.Marshal(v)
+	return string(data), err
+}
@@ -0,0 +1,71 @@
+package notification
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhook_Handle(t *testing.T) {
+	event := &Event{
+		Kind:           KindCreated,
+		UserID:         "user1",
+		OrganizationID: "org1",
+		EventType:      "user.human.added",
+		Payload:        map[string]any{"email": "gigi@zitadel.com"},
+	}
+	tests := []struct {
+		name     string
+		template string
+		status   int
+		wantBody string
+		wantErr  bool
+	}{
+		{
+			name:     "template",
+			template: `{"email":{{ json .Payload.email }},"type":"{{ .Kind }}","user":"{{ .UserID }}"}`,
+			status:   http.StatusOK,
+			wantBody: `{"email":"gigi@zitadel.com","type":"created","user":"user1"}`,
+		},
+		{
+			name:     "missing payload key",
+			template: `{"phone":{{ json .Payload.phone }}}`,
+			status:   http.StatusOK,
+			wantBody: `{"phone":null}`,
+		},
+		{
+			name:     "error status",
+			template: DefaultWebhookTemplate,
+			status:   http.StatusInternalServerError,
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotBody, gotHeader string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				gotBody = string(body)
+				gotHeader = r.Header.Get("X-Api-Key")
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			webhook, err := NewWebhook(server.URL, tt.template, WithHeader("X-Api-Key", "secret"))
+			require.NoError(t, err)
+			err = webhook.Handle(context.Background(), event)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantBody, gotBody)
+			assert.Equal(t, "secret", gotHeader)
+		})
+	}
+}
@@ -0,0 +1,233 @@
+// Package diskcache provides an [http.RoundTripper] keeping a copy of the OIDC discovery document
+// and the JSON Web Key Set (JWKS) on disk, so token verification can be bootstrapped
+// while ZITADEL is not reachable (e.g. during an outage or in air-gapped environments).
+package diskcache
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HeaderCache is set on responses served from disk, the value contains the time the response was originally fetched.
+const HeaderCache = "X-Zitadel-Disk-Cache"
+
+var (
+	ErrNotCached = errors.New("response not cached")
+	ErrTooStale  = errors.New("cached response exceeds max staleness")
+
+	invalidFileChars = regexp.MustCompile(`[^a-zA-Z0-9.-]+`)
+)
+
+// Transport implements [http.RoundTripper] by writing every successful (unauthenticated) GET response
+// to disk. If the upstream request fails (network error or server error), the cached response will be served
+// as long as it doesn't exceed the configured max staleness.
+//
+// Authenticated requests (e.g. userinfo) and requests other than GET are passed through without caching.
+type Transport struct {
+	dir          string
+	maxStaleness time.Duration
+	base         http.RoundTripper
+	pinned       bool
+	now          func() time.Time
+	mu           sync.Mutex
+}
+
+// Option allows customization of the [Transport].
+type Option func(*Transport)
+
+// WithMaxStaleness limits the age of a cached response to be served.
+// By default, there's no limit.
+func WithMaxStaleness(maxStaleness time.Duration) Option {
+	return func(t *Transport) {
+		t.maxStaleness = maxStaleness
+	}
+}
+
+// WithBaseTransport allows a transport other than [http.DefaultTransport] for upstream requests.
+func WithBaseTransport(base http.RoundTripper) Option {
+	return func(t *Transport) {
+		t.base = base
+	}
+}
+
+// WithPinned will serve the cached responses without requesting ZITADEL at all,
+// e.g. when booting in an air-gapped environment from a provisioned directory.
+// Max staleness is still enforced if configured.
+func WithPinned() Option {
+	return func(t *Transport) {
+		t.pinned = true
+	}
+}
+
+// NewTransport creates a [Transport] storing the responses in the provided directory.
+func NewTransport(dir string, options ...Option) (*Transport, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	t := &Transport{
+		dir:  dir,
+		base: http.DefaultTransport,
+		now:  time.Now,
+	}
+	for _, option := range options {
+		option(t)
+	}
+	return t, nil
+}
+
+// NewClient is a short version of [NewTransport] directly returning an [http.Client],
+// e.g. to be passed as oauth.WithHTTPClient for the introspection or as HTTP client of the relying party.
+func NewClient(dir string, options ...Option) (*http.Client, error) {
+	transport, err := NewTransport(dir, options...)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Client{Transport: transport}, nil
+}
+
+// entry is the format a response is stored on disk.
+type entry struct {
+	URL         string          `json:"url"`
+	FetchedAt   time.Time       `json:"fetched_at"`
+	ContentType string          `json:"content_type,omitempty"`
+	Body        json.RawMessage `json:"body"`
+}
+
+// RoundTrip implements [http.RoundTripper]
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet || req.Header.Get("Authorization") != "" {
+		return t.base.RoundTrip(req)
+	}
+	if t.pinned {
+		return t.load(req)
+	}
+	resp, err := t.base.RoundTrip(req)
+	if err == nil && resp.StatusCode < http.StatusInternalServerError {
+		if resp.StatusCode == http.StatusOK {
+			return t.store(req, resp)
+		}
+		return resp, nil
+	}
+	cached, cacheErr := t.load(req)
+	if cacheErr != nil {
+		if err != nil {
+			return nil, err
+		}
+		return resp, nil
+	}
+	if resp != nil {
+		resp.Body.Close()
+	}
+	return cached, nil
+}
+
+// Pin allows to provision the directory with a response (e.g. the discovery document) for the URL,
+// so it can be served by a [Transport] using the same directory.
+func Pin(dir, url string, body []byte, fetchedAt time.Time) error {
+	t, err := NewTransport(dir)
+	if err != nil {
+		return err
+	}
+	return t.write(&entry{
+		URL:         url,
+		FetchedAt:   fetchedAt,
+		ContentType: "application/json",
+		Body:        body,
+	})
+}
+
+func (t *Transport) store(req *http.Request, resp *http.Response) (*http.Response, error) {
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	// only JSON documents (discovery, keys) are cached
+	if !json.Valid(body) {
+		return resp, nil
+	}
+	// failing to persist must not fail the request itself
+	_ = t.write(&entry{
+		URL:         req.URL.String(),
+		FetchedAt:   t.now(),
+		ContentType: resp.Header.Get("Content-Type"),
+		Body:        body,
+	})
+	return resp, nil
+}
+
+func (t *Transport) write(e *entry) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	tmp, err := os.CreateTemp(t.dir, ".entry-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), t.path(e.URL))
+}
+
+func (t *Transport) load(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	data, err := os.ReadFile(t.path(req.URL.String()))
+	t.mu.Unlock()
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrNotCached, req.URL)
+	}
+	if err != nil {
+		return nil, err
+	}
+	e := new(entry)
+	if err = json.Unmarshal(data, e); err != nil {
+		return nil, err
+	}
+	if t.maxStaleness > 0 && t.now().Sub(e.FetchedAt) > t.maxStaleness {
+		return nil, fmt.Errorf("%w: %s fetched at %s", ErrTooStale, req.URL, e.FetchedAt)
+	}
+	header := make(http.Header)
+	if e.ContentType != "" {
+		header.Set("Content-Type", e.ContentType)
+	}
+	header.Set(HeaderCache, e.FetchedAt.Format(time.RFC3339))
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}, nil
+}
+
+// path returns a readable file name for the URL, e.g.
+// https://my-instance.zitadel.cloud/oauth/v2/keys -> my-instance.zitadel.cloud_oauth_v2_keys.json
+func (t *Transport) path(url string) string {
+	name := strings.TrimPrefix(strings.TrimPrefix(url, "https://"), "http://")
+	name = strings.Trim(invalidFileChars.ReplaceAllString(name, "_"), "_")
+	return filepath.Join(t.dir, name+".json")
+}
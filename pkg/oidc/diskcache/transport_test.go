@@ -0,0 +1,112 @@
+package diskcache
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransport_RoundTrip(t *testing.T) {
+	const keys = `{"keys":[]}`
+	tests := []struct {
+		name         string
+		upstream     http.RoundTripper
+		fetchedAt    time.Time
+		options      []Option
+		header       http.Header
+		wantBody     string
+		wantCacheHit bool
+		wantErr      error
+	}{
+		{
+			name:     "upstream available",
+			upstream: upstream(http.StatusOK, keys, nil),
+			wantBody: keys,
+		},
+		{
+			name:         "upstream unavailable, cached",
+			upstream:     upstream(0, "", errors.New("connection refused")),
+			fetchedAt:    time.Now().Add(-time.Hour),
+			wantBody:     keys,
+			wantCacheHit: true,
+		},
+		{
+			name:         "upstream server error, cached",
+			upstream:     upstream(http.StatusBadGateway, "", nil),
+			fetchedAt:    time.Now().Add(-time.Hour),
+			wantBody:     keys,
+			wantCacheHit: true,
+		},
+		{
+			name:      "upstream unavailable, too stale",
+			upstream:  upstream(0, "", errors.New("connection refused")),
+			fetchedAt: time.Now().Add(-2 * time.Hour),
+			options:   []Option{WithMaxStaleness(time.Hour)},
+			wantErr:   errors.New("connection refused"),
+		},
+		{
+			name:         "pinned",
+			upstream:     upstream(http.StatusOK, `{"keys":["unexpected"]}`, nil),
+			fetchedAt:    time.Now().Add(-time.Hour),
+			options:      []Option{WithPinned()},
+			wantBody:     keys,
+			wantCacheHit: true,
+		},
+		{
+			name:     "authenticated request, not served from cache",
+			upstream: upstream(http.StatusServiceUnavailable, "", nil),
+			header:   http.Header{"Authorization": []string{"Bearer token"}},
+			wantBody: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			const url = "https://zitadel.cloud/oauth/v2/keys"
+			if !tt.fetchedAt.IsZero() {
+				require.NoError(t, Pin(dir, url, []byte(keys), tt.fetchedAt))
+			}
+			transport, err := NewTransport(dir, append(tt.options, WithBaseTransport(tt.upstream))...)
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, url, nil)
+			for key, values := range tt.header {
+				req.Header[key] = values
+			}
+			resp, err := transport.RoundTrip(req)
+			if tt.wantErr != nil {
+				assert.EqualError(t, err, tt.wantErr.Error())
+				return
+			}
+			require.NoError(t, err)
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantBody, string(body))
+			assert.Equal(t, tt.wantCacheHit, resp.Header.Get(HeaderCache) != "")
+		})
+	}
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func upstream(status int, body string, err error) http.RoundTripper {
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if err != nil {
+			return nil, err
+		}
+		rec := httptest.NewRecorder()
+		rec.WriteHeader(status)
+		rec.WriteString(body)
+		return rec.Result(), nil
+	})
+}
@@ -0,0 +1,67 @@
+package verifier
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/zitadel/oidc/v3/pkg/oidc"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/authorization"
+	"github.com/zitadel/zitadel-go/v3/pkg/authorization/oauth"
+	"github.com/zitadel/zitadel-go/v3/pkg/zitadel"
+)
+
+// WithJWT creates an [authorization.Verifier] validating JWT access tokens locally,
+// as an alternative to [oauth.WithIntrospection]. The claims are provided as [oauth.IntrospectionContext],
+// so checks (e.g. roles) work the same way.
+// Like the introspection is bound to the API, the tokens must contain the audience, e.g. the ID of the project of the API.
+// ID tokens are not accepted (see [Verifier.VerifyAccessToken]).
+// The background refresh of the keys runs until the context passed to the initialization is done.
+func WithJWT(audience string, options ...Option) authorization.VerifierInitializer[*oauth.IntrospectionContext] {
+	return func(ctx context.Context, zitadel *zitadel.Zitadel) (authorization.Verifier[*oauth.IntrospectionContext], error) {
+		v, err := New(ctx, zitadel, append([]Option{WithAudience(audience)}, options...)...)
+		if err != nil {
+			return nil, err
+		}
+		if !slices.ContainsFunc(v.audience, func(aud string) bool { return aud != "" }) {
+			return nil, ErrMissingAudience
+		}
+		return &jwtVerification{verifier: v}, nil
+	}
+}
+
+type jwtVerification struct {
+	verifier *Verifier
+}
+
+// CheckAuthorization implements the [authorization.Verifier] interface.
+func (j *jwtVerification) CheckAuthorization(ctx context.Context, authorizationToken string) (*oauth.IntrospectionContext, error) {
+	token, ok := strings.CutPrefix(authorizationToken, oidc.BearerToken)
+	if !ok {
+		return nil, oauth.ErrInvalidAuthorizationHeader
+	}
+	claims, err := j.verifier.VerifyAccessToken(ctx, strings.TrimSpace(token))
+	if err != nil {
+		return nil, err
+	}
+	return &oauth.IntrospectionContext{
+		IntrospectionResponse: oidc.IntrospectionResponse{
+			Active:                          true,
+			Scope:                           claims.Scopes,
+			ClientID:                        claims.ClientID,
+			TokenType:                       oidc.BearerToken,
+			Expiration:                      claims.Expiration,
+			IssuedAt:                        claims.IssuedAt,
+			AuthTime:                        claims.AuthTime,
+			NotBefore:                       claims.NotBefore,
+			Subject:                         claims.Subject,
+			Audience:                        claims.Audience,
+			AuthenticationMethodsReferences: claims.AuthenticationMethodsReferences,
+			Issuer:                          claims.Issuer,
+			JWTID:                           claims.JWTID,
+			Actor:                           claims.Actor,
+			Claims:                          claims.Claims,
+		},
+	}, nil
+}
@@ -0,0 +1,122 @@
+package verifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/authorization"
+)
+
+// keySet caches the keys of the JWKS endpoint.
+// Unknown key IDs trigger a refresh, which is limited to one per minRefreshInterval
+// to prevent a denial of service with tokens of random key IDs.
+type keySet struct {
+	jwksURI            string
+	httpClient         *http.Client
+	minRefreshInterval time.Duration
+	now                func() time.Time
+
+	mu          sync.RWMutex
+	keys        []jose.JSONWebKey
+	lastRefresh time.Time
+
+	// refreshing serializes the refreshes, so concurrent key misses result in a single request
+	refreshing sync.Mutex
+}
+
+func (k *keySet) key(ctx context.Context, keyID string, algorithm string) (*jose.JSONWebKey, error) {
+	if key := k.lookup(keyID, algorithm); key != nil {
+		return key, nil
+	}
+	k.mu.RLock()
+	lastRefresh := k.lastRefresh
+	k.mu.RUnlock()
+	if k.now().Sub(lastRefresh) < k.minRefreshInterval {
+		return nil, fmt.Errorf("%w: unknown key id %q", ErrInvalidSignature, keyID)
+	}
+	if err := k.refreshIfNotSince(ctx, lastRefresh); err != nil {
+		return nil, err
+	}
+	if key := k.lookup(keyID, algorithm); key != nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("%w: unknown key id %q", ErrInvalidSignature, keyID)
+}
+
+func (k *keySet) lookup(keyID, algorithm string) *jose.JSONWebKey {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	for i, key := range k.keys {
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+		if key.Algorithm != "" && key.Algorithm != algorithm {
+			continue
+		}
+		// a token without key id can only be verified if there's exactly one key
+		if key.KeyID == keyID || (keyID == "" && len(k.keys) == 1) {
+			return &k.keys[i]
+		}
+	}
+	return nil
+}
+
+// refreshIfNotSince refreshes the keys unless another refresh happened since the provided time.
+func (k *keySet) refreshIfNotSince(ctx context.Context, since time.Time) error {
+	k.refreshing.Lock()
+	defer k.refreshing.Unlock()
+	k.mu.RLock()
+	refreshed := k.lastRefresh.After(since)
+	k.mu.RUnlock()
+	if refreshed {
+		return nil
+	}
+	return k.refresh(ctx)
+}
+
+func (k *keySet) refresh(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, k.jwksURI, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := k.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %w: %v", ErrKeysUnavailable, authorization.ErrUnavailable, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: %w: status %d", ErrKeysUnavailable, authorization.ErrUnavailable, resp.StatusCode)
+	}
+	keys := new(jose.JSONWebKeySet)
+	if err = json.NewDecoder(resp.Body).Decode(keys); err != nil {
+		return fmt.Errorf("%w: %w: %v", ErrKeysUnavailable, authorization.ErrUnavailable, err)
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.keys = keys.Keys
+	k.lastRefresh = k.now()
+	return nil
+}
+
+// refreshPeriodically refreshes the keys in the interval until the context is done.
+// Failures are ignored, the previous keys are kept until the next successful refresh.
+func (k *keySet) refreshPeriodically(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			k.refreshing.Lock()
+			_ = k.refresh(ctx)
+			k.refreshing.Unlock()
+		}
+	}
+}
@@ -0,0 +1,278 @@
+// Package verifier validates JWT access, ID and logout tokens issued by ZITADEL locally,
+// using the keys of the JSON Web Key Set (JWKS) of the instance.
+// In contrast to introspection, no request to ZITADEL is needed per token,
+// but revoked tokens are accepted until they expire.
+//
+// The keys are cached and refreshed periodically in the background as well as
+// on tokens signed by an unknown key (key rotation).
+package verifier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/zitadel/oidc/v3/pkg/client"
+	"github.com/zitadel/oidc/v3/pkg/oidc"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/zitadel"
+)
+
+const (
+	// DefaultRefreshInterval is the interval the keys are refreshed in the background.
+	DefaultRefreshInterval = time.Hour
+	// DefaultMinRefreshInterval is the min duration between two refreshes caused by unknown key IDs.
+	DefaultMinRefreshInterval = 10 * time.Second
+	// DefaultClockSkew is the tolerated difference of the clocks of ZITADEL and the verifier.
+	DefaultClockSkew = 10 * time.Second
+)
+
+var (
+	ErrInvalidToken     = errors.New("invalid token")
+	ErrInvalidSignature = errors.New("invalid token signature")
+	ErrInvalidIssuer    = errors.New("invalid token issuer")
+	ErrInvalidAudience  = errors.New("invalid token audience")
+	ErrTokenExpired     = errors.New("token expired")
+	ErrTokenNotYetValid = errors.New("token not yet valid")
+	ErrInvalidNonce     = errors.New("invalid nonce")
+	ErrKeysUnavailable  = errors.New("keys could not be fetched")
+	ErrInvalidEvent     = errors.New("logout token must contain the back-channel logout event")
+	ErrNotAccessToken   = errors.New("token is not an access token")
+	ErrMissingAudience  = errors.New("audience is required")
+)
+
+// accessTokenTypes are the accepted `typ` headers of access tokens (RFC 9068), the header is optional.
+var accessTokenTypes = []string{"at+jwt", "application/at+jwt", "jwt"}
+
+// idTokenClaims are only contained in ID tokens, which must not be accepted as access tokens.
+var idTokenClaims = []string{"nonce", "at_hash", "c_hash", "s_hash"}
+
+// backChannelLogoutEvent is the event of the logout tokens sent by the OpenID Provider.
+const backChannelLogoutEvent = "http://schemas.openid.net/event/backchannel-logout"
+
+var defaultAlgorithms = []jose.SignatureAlgorithm{
+	jose.RS256, jose.RS384, jose.RS512,
+	jose.ES256, jose.ES384, jose.ES512,
+	jose.PS256, jose.PS384, jose.PS512,
+	jose.EdDSA,
+}
+
+// Verifier validates tokens issued by ZITADEL.
+type Verifier struct {
+	issuer             string
+	audience           []string
+	algorithms         []jose.SignatureAlgorithm
+	clockSkew          time.Duration
+	httpClient         *http.Client
+	refreshInterval    time.Duration
+	minRefreshInterval time.Duration
+	now                func() time.Time
+
+	keys *keySet
+}
+
+// Option allows customization of the [Verifier].
+type Option func(*Verifier)
+
+// WithAudience requires the tokens to contain one of the audiences,
+// e.g. the client ID of the application or the ID of the project.
+// Without it, the audience is not checked, so tokens of any application of the instance are accepted.
+// It's therefore required by [WithJWT].
+func WithAudience(audience ...string) Option {
+	return func(v *Verifier) {
+		v.audience = audience
+	}
+}
+
+// WithAlgorithms restricts the accepted signature algorithms (default: all asymmetric algorithms).
+func WithAlgorithms(algorithms ...jose.SignatureAlgorithm) Option {
+	return func(v *Verifier) {
+		v.algorithms = algorithms
+	}
+}
+
+// WithClockSkew sets the tolerated difference of the clocks (default 10 seconds).
+func WithClockSkew(skew time.Duration) Option {
+	return func(v *Verifier) {
+		v.clockSkew = skew
+	}
+}
+
+// WithHTTPClient allows to use a custom [http.Client] for the discovery and JWKS requests,
+// e.g. one serving them from disk during an outage (see diskcache.NewClient).
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(v *Verifier) {
+		v.httpClient = httpClient
+	}
+}
+
+// WithRefreshInterval sets the interval the keys are refreshed in the background (default 1 hour).
+// A zero interval disables the background refresh, keys are then only refreshed on unknown key IDs.
+func WithRefreshInterval(interval time.Duration) Option {
+	return func(v *Verifier) {
+		v.refreshInterval = interval
+	}
+}
+
+// WithMinRefreshInterval sets the min duration between two refreshes caused by unknown key IDs (default 10 seconds).
+func WithMinRefreshInterval(interval time.Duration) Option {
+	return func(v *Verifier) {
+		v.minRefreshInterval = interval
+	}
+}
+
+// New creates a [Verifier] for the tokens of the ZITADEL instance.
+// It discovers the JWKS endpoint and fetches the keys. The background refresh runs until the context is done.
+func New(ctx context.Context, zitadel *zitadel.Zitadel, options ...Option) (*Verifier, error) {
+	v := &Verifier{
+		issuer:             zitadel.Origin(),
+		algorithms:         defaultAlgorithms,
+		clockSkew:          DefaultClockSkew,
+		httpClient:         http.DefaultClient,
+		refreshInterval:    DefaultRefreshInterval,
+		minRefreshInterval: DefaultMinRefreshInterval,
+		now:                time.Now,
+	}
+	for _, option := range options {
+		option(v)
+	}
+	discovery, err := client.Discover(ctx, v.issuer, v.httpClient)
+	if err != nil {
+		return nil, err
+	}
+	v.issuer = discovery.Issuer
+	v.keys = &keySet{
+		jwksURI:            discovery.JwksURI,
+		httpClient:         v.httpClient,
+		minRefreshInterval: v.minRefreshInterval,
+		now:                v.now,
+	}
+	if err = v.keys.refresh(ctx); err != nil {
+		return nil, err
+	}
+	if v.refreshInterval > 0 {
+		go v.keys.refreshPeriodically(ctx, v.refreshInterval)
+	}
+	return v, nil
+}
+
+// VerifyAccessToken validates a JWT access token and returns its claims.
+// ID tokens (containing a `nonce` or hash claims or with another `typ` header) are rejected with [ErrNotAccessToken].
+func (v *Verifier) VerifyAccessToken(ctx context.Context, token string) (*oidc.AccessTokenClaims, error) {
+	claims := new(oidc.AccessTokenClaims)
+	header, err := v.verify(ctx, token, claims)
+	if err != nil {
+		return nil, err
+	}
+	if typ, ok := header.ExtraHeaders[jose.HeaderType].(string); ok && !slices.Contains(accessTokenTypes, strings.ToLower(typ)) {
+		return nil, fmt.Errorf("%w: typ %q", ErrNotAccessToken, typ)
+	}
+	for _, claim := range idTokenClaims {
+		if _, ok := claims.Claims[claim]; ok {
+			return nil, fmt.Errorf("%w: contains %s claim", ErrNotAccessToken, claim)
+		}
+	}
+	if err = v.checkClaims(&claims.TokenClaims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// VerifyIDToken validates an ID token and returns its claims.
+// If a nonce is provided, the token must contain it.
+func (v *Verifier) VerifyIDToken(ctx context.Context, token, nonce string) (*oidc.IDTokenClaims, error) {
+	claims := new(oidc.IDTokenClaims)
+	if _, err := v.verify(ctx, token, claims); err != nil {
+		return nil, err
+	}
+	// the ID token has its own nbf field shadowing the one of the token claims
+	claims.TokenClaims.NotBefore = claims.NotBefore
+	if err := v.checkClaims(&claims.TokenClaims); err != nil {
+		return nil, err
+	}
+	if nonce != "" && claims.Nonce != nonce {
+		return nil, ErrInvalidNonce
+	}
+	return claims, nil
+}
+
+// VerifyLogoutToken validates a logout token sent to the back-channel logout URI of the application
+// (see https://openid.net/specs/openid-connect-backchannel-1_0.html#Validation) and returns its claims.
+// Use [WithAudience] with the client ID of the application to check the audience.
+func (v *Verifier) VerifyLogoutToken(ctx context.Context, token string) (*oidc.LogoutTokenClaims, error) {
+	claims := new(oidc.LogoutTokenClaims)
+	if _, err := v.verify(ctx, token, claims); err != nil {
+		return nil, err
+	}
+	if err := v.checkClaims(&oidc.TokenClaims{
+		Issuer:     claims.Issuer,
+		Audience:   claims.Audience,
+		Expiration: claims.Expiration,
+		IssuedAt:   claims.IssuedAt,
+	}); err != nil {
+		return nil, err
+	}
+	if _, ok := claims.Events[backChannelLogoutEvent]; !ok {
+		return nil, ErrInvalidEvent
+	}
+	if claims.Subject == "" && claims.SessionID == "" {
+		return nil, fmt.Errorf("%w: must contain a sub or sid claim", ErrInvalidToken)
+	}
+	// prevents ID tokens from being used as logout tokens
+	if _, ok := claims.Claims["nonce"]; ok {
+		return nil, fmt.Errorf("%w: must not contain a nonce", ErrInvalidToken)
+	}
+	return claims, nil
+}
+
+// verify checks the signature of the token, decodes its claims and returns its protected header.
+func (v *Verifier) verify(ctx context.Context, token string, claims any) (header jose.Header, err error) {
+	jws, err := jose.ParseSigned(token, v.algorithms)
+	if err != nil {
+		return header, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if len(jws.Signatures) != 1 {
+		return header, fmt.Errorf("%w: must contain exactly one signature", ErrInvalidToken)
+	}
+	header = jws.Signatures[0].Protected
+	key, err := v.keys.key(ctx, header.KeyID, header.Algorithm)
+	if err != nil {
+		return header, err
+	}
+	payload, err := jws.Verify(key)
+	if err != nil {
+		return header, fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+	if err = json.Unmarshal(payload, claims); err != nil {
+		return header, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	return header, nil
+}
+
+func (v *Verifier) checkClaims(claims *oidc.TokenClaims) error {
+	if strings.TrimSuffix(claims.Issuer, "/") != strings.TrimSuffix(v.issuer, "/") {
+		return ErrInvalidIssuer
+	}
+	if len(v.audience) > 0 && !slices.ContainsFunc(v.audience, func(aud string) bool {
+		return slices.Contains(claims.Audience, aud)
+	}) {
+		return ErrInvalidAudience
+	}
+	now := v.now()
+	if claims.Expiration == 0 || now.Add(-v.clockSkew).After(claims.Expiration.AsTime()) {
+		return ErrTokenExpired
+	}
+	if claims.NotBefore != 0 && now.Add(v.clockSkew).Before(claims.NotBefore.AsTime()) {
+		return ErrTokenNotYetValid
+	}
+	if claims.IssuedAt != 0 && now.Add(v.clockSkew).Before(claims.IssuedAt.AsTime()) {
+		return ErrTokenNotYetValid
+	}
+	return nil
+}
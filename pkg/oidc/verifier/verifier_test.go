@@ -0,0 +1,300 @@
+package verifier
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/zitadel"
+)
+
+type testServer struct {
+	*httptest.Server
+	mu   sync.Mutex
+	keys []jose.JSONWebKey
+}
+
+func newTestServer(t *testing.T) *testServer {
+	s := new(testServer)
+	mux := http.NewServeMux()
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":   s.URL,
+			"jwks_uri": s.URL + "/oauth/v2/keys",
+		})
+	})
+	mux.HandleFunc("/oauth/v2/keys", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		_ = json.NewEncoder(w).Encode(jose.JSONWebKeySet{Keys: s.keys})
+	})
+	return s
+}
+
+func (s *testServer) setKeys(keys ...*ecdsa.PrivateKey) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys = s.keys[:0]
+	for i, key := range keys {
+		s.keys = append(s.keys, jose.JSONWebKey{Key: key.Public(), KeyID: keyID(i), Algorithm: string(jose.ES256), Use: "sig"})
+	}
+}
+
+func (s *testServer) zitadel(t *testing.T) *zitadel.Zitadel {
+	u, err := url.Parse(s.URL)
+	require.NoError(t, err)
+	return zitadel.New(u.Hostname(), zitadel.WithInsecure(u.Port()))
+}
+
+func keyID(i int) string {
+	return string(rune('a' + i))
+}
+
+func sign(t *testing.T, key *ecdsa.PrivateKey, kid string, claims map[string]any) string {
+	return signTyped(t, key, kid, "", claims)
+}
+
+func signTyped(t *testing.T, key *ecdsa.PrivateKey, kid, typ string, claims map[string]any) string {
+	options := new(jose.SignerOptions)
+	if typ != "" {
+		options = options.WithType(jose.ContentType(typ))
+	}
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.ES256, Key: jose.JSONWebKey{Key: key, KeyID: kid}}, options)
+	require.NoError(t, err)
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+	jws, err := signer.Sign(payload)
+	require.NoError(t, err)
+	token, err := jws.CompactSerialize()
+	require.NoError(t, err)
+	return token
+}
+
+func generateKey(t *testing.T) *ecdsa.PrivateKey {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	return key
+}
+
+func TestVerifier_VerifyAccessToken(t *testing.T) {
+	server := newTestServer(t)
+	key := generateKey(t)
+	server.setKeys(key)
+	v, err := New(context.Background(), server.zitadel(t), WithAudience("api"), WithRefreshInterval(0))
+	require.NoError(t, err)
+
+	now := time.Now()
+	claims := func(modify func(map[string]any)) map[string]any {
+		c := map[string]any{
+			"iss":                               server.URL,
+			"sub":                               "user1",
+			"aud":                               []string{"api", "client"},
+			"exp":                               now.Add(time.Hour).Unix(),
+			"iat":                               now.Unix(),
+			"urn:zitadel:iam:org:project:roles": map[string]any{"admin": map[string]any{"org1": "zitadel.cloud"}},
+		}
+		if modify != nil {
+			modify(c)
+		}
+		return c
+	}
+	tests := []struct {
+		name    string
+		token   string
+		wantErr error
+	}{
+		{
+			name:  "valid",
+			token: sign(t, key, "a", claims(nil)),
+		},
+		{
+			name:    "invalid signature",
+			token:   sign(t, generateKey(t), "a", claims(nil)),
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name:    "unknown key",
+			token:   sign(t, generateKey(t), "unknown", claims(nil)),
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name:    "invalid issuer",
+			token:   sign(t, key, "a", claims(func(c map[string]any) { c["iss"] = "https://other.zitadel.cloud" })),
+			wantErr: ErrInvalidIssuer,
+		},
+		{
+			name:    "invalid audience",
+			token:   sign(t, key, "a", claims(func(c map[string]any) { c["aud"] = []string{"other"} })),
+			wantErr: ErrInvalidAudience,
+		},
+		{
+			name:    "expired",
+			token:   sign(t, key, "a", claims(func(c map[string]any) { c["exp"] = now.Add(-time.Minute).Unix() })),
+			wantErr: ErrTokenExpired,
+		},
+		{
+			name:    "not yet valid",
+			token:   sign(t, key, "a", claims(func(c map[string]any) { c["nbf"] = now.Add(time.Minute).Unix() })),
+			wantErr: ErrTokenNotYetValid,
+		},
+		{
+			name:  "access token type",
+			token: signTyped(t, key, "a", "at+jwt", claims(nil)),
+		},
+		{
+			name:    "ID token with nonce",
+			token:   sign(t, key, "a", claims(func(c map[string]any) { c["nonce"] = "nonce" })),
+			wantErr: ErrNotAccessToken,
+		},
+		{
+			name:    "ID token with access token hash",
+			token:   sign(t, key, "a", claims(func(c map[string]any) { c["at_hash"] = "hash" })),
+			wantErr: ErrNotAccessToken,
+		},
+		{
+			name:    "other token type",
+			token:   signTyped(t, key, "a", "logout+jwt", claims(nil)),
+			wantErr: ErrNotAccessToken,
+		},
+		{
+			name:    "malformed",
+			token:   "not.a.token",
+			wantErr: ErrInvalidToken,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := v.VerifyAccessToken(context.Background(), tt.token)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "user1", got.Subject)
+			assert.Contains(t, got.Claims, "urn:zitadel:iam:org:project:roles")
+		})
+	}
+}
+
+func TestVerifier_VerifyLogoutToken(t *testing.T) {
+	server := newTestServer(t)
+	key := generateKey(t)
+	server.setKeys(key)
+	v, err := New(context.Background(), server.zitadel(t), WithAudience("client"), WithRefreshInterval(0))
+	require.NoError(t, err)
+
+	now := time.Now()
+	claims := func(modify func(map[string]any)) map[string]any {
+		c := map[string]any{
+			"iss":    server.URL,
+			"sub":    "user1",
+			"aud":    "client",
+			"exp":    now.Add(2 * time.Minute).Unix(),
+			"iat":    now.Unix(),
+			"jti":    "logout1",
+			"sid":    "session1",
+			"events": map[string]any{"http://schemas.openid.net/event/backchannel-logout": map[string]any{}},
+		}
+		if modify != nil {
+			modify(c)
+		}
+		return c
+	}
+	tests := []struct {
+		name    string
+		token   string
+		wantErr error
+	}{
+		{
+			name:  "valid",
+			token: sign(t, key, "a", claims(nil)),
+		},
+		{
+			name:    "invalid audience",
+			token:   sign(t, key, "a", claims(func(c map[string]any) { c["aud"] = "other" })),
+			wantErr: ErrInvalidAudience,
+		},
+		{
+			name:    "missing event",
+			token:   sign(t, key, "a", claims(func(c map[string]any) { delete(c, "events") })),
+			wantErr: ErrInvalidEvent,
+		},
+		{
+			name: "missing sub and sid",
+			token: sign(t, key, "a", claims(func(c map[string]any) {
+				delete(c, "sub")
+				delete(c, "sid")
+			})),
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "id token",
+			token:   sign(t, key, "a", claims(func(c map[string]any) { c["nonce"] = "nonce" })),
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "expired",
+			token:   sign(t, key, "a", claims(func(c map[string]any) { c["exp"] = now.Add(-time.Minute).Unix() })),
+			wantErr: ErrTokenExpired,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := v.VerifyLogoutToken(context.Background(), tt.token)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "user1", got.Subject)
+			assert.Equal(t, "session1", got.SessionID)
+		})
+	}
+}
+
+func TestVerifier_keyRotation(t *testing.T) {
+	server := newTestServer(t)
+	oldKey, newKey := generateKey(t), generateKey(t)
+	server.setKeys(oldKey)
+	v, err := New(context.Background(), server.zitadel(t), WithRefreshInterval(0), WithMinRefreshInterval(time.Minute))
+	require.NoError(t, err)
+	now := time.Now()
+	v.now = func() time.Time { return now }
+	v.keys.now = v.now
+
+	claims := map[string]any{"iss": server.URL, "sub": "user1", "exp": now.Add(time.Hour).Unix()}
+	server.setKeys(oldKey, newKey)
+
+	_, err = v.VerifyAccessToken(context.Background(), sign(t, newKey, "b", claims))
+	assert.ErrorIs(t, err, ErrInvalidSignature, "refresh must be rate limited")
+
+	now = now.Add(2 * time.Minute)
+	_, err = v.VerifyAccessToken(context.Background(), sign(t, newKey, "b", claims))
+	assert.NoError(t, err, "unknown key id must trigger a refresh")
+	_, err = v.VerifyAccessToken(context.Background(), sign(t, oldKey, "a", claims))
+	assert.NoError(t, err)
+}
+
+func TestWithJWT_missingAudience(t *testing.T) {
+	server := newTestServer(t)
+	server.setKeys(generateKey(t))
+	_, err := WithJWT("", WithRefreshInterval(0))(context.Background(), server.zitadel(t))
+	assert.ErrorIs(t, err, ErrMissingAudience)
+
+	_, err = WithJWT("api", WithRefreshInterval(0))(context.Background(), server.zitadel(t))
+	assert.NoError(t, err)
+}
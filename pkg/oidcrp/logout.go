@@ -0,0 +1,204 @@
+package oidcrp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// DefaultLogoutRetention is the duration the [MemoryLogoutStore] of [New] keeps the logouts.
+const DefaultLogoutRetention = 30 * 24 * time.Hour
+
+var (
+	ErrLoggedOut          = errors.New("session was logged out")
+	ErrNoEndSession       = errors.New("end_session_endpoint not supported")
+	ErrMissingLogoutToken = errors.New("missing logout_token")
+)
+
+// LogoutStore records the logouts of the back- and front-channel logout,
+// so the [RelyingParty.Middleware] rejects the logged out sessions, even though their cookies still exist.
+type LogoutStore interface {
+	// Logout records the logout of the session with the sessionID or,
+	// if no sessionID is provided, of all sessions of the subject issued until the time.
+	Logout(ctx context.Context, subject, sessionID string, at time.Time) error
+	// LoggedOut reports if the session was logged out.
+	LoggedOut(ctx context.Context, session *Session) (bool, error)
+}
+
+// WithLogoutStore sets the store of the logouts (default [MemoryLogoutStore] keeping the logouts for [DefaultLogoutRetention]).
+// If the application runs multiple replicas, use a shared store, e.g. backed by the database or cache of the application,
+// since ZITADEL calls the back-channel of only one of them.
+func WithLogoutStore(store LogoutStore) Option {
+	return func(r *RelyingParty) {
+		r.logouts = store
+	}
+}
+
+// MemoryLogoutStore is a [LogoutStore] keeping the logouts in memory for the retention,
+// which should exceed the lifetime of the sessions (e.g. of the refresh tokens).
+type MemoryLogoutStore struct {
+	retention time.Duration
+
+	mu       sync.RWMutex
+	sessions map[string]time.Time
+	subjects map[string]time.Time
+}
+
+// NewMemoryLogoutStore creates a [MemoryLogoutStore].
+func NewMemoryLogoutStore(retention time.Duration) *MemoryLogoutStore {
+	return &MemoryLogoutStore{
+		retention: retention,
+		sessions:  make(map[string]time.Time),
+		subjects:  make(map[string]time.Time),
+	}
+}
+
+// Logout implements [LogoutStore].
+func (s *MemoryLogoutStore) Logout(_ context.Context, subject, sessionID string, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.prune(at)
+	if sessionID != "" {
+		s.sessions[sessionID] = at
+		return nil
+	}
+	if subject != "" {
+		s.subjects[subject] = at
+	}
+	return nil
+}
+
+// LoggedOut implements [LogoutStore].
+func (s *MemoryLogoutStore) LoggedOut(_ context.Context, session *Session) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if _, ok := s.sessions[session.SessionID]; ok && session.SessionID != "" {
+		return true, nil
+	}
+	at, ok := s.subjects[session.Subject]
+	return ok && !session.IssuedAt.After(at), nil
+}
+
+func (s *MemoryLogoutStore) prune(now time.Time) {
+	for id, at := range s.sessions {
+		if now.Sub(at) > s.retention {
+			delete(s.sessions, id)
+		}
+	}
+	for subject, at := range s.subjects {
+		if now.Sub(at) > s.retention {
+			delete(s.subjects, subject)
+		}
+	}
+}
+
+// LogoutURL returns the URL of the end_session_endpoint of ZITADEL to end the session of the user (RP-initiated logout).
+// The state is passed to the URL set by [WithPostLogoutRedirect] and is optional.
+func (r *RelyingParty) LogoutURL(session *Session, state string) (string, error) {
+	endSession := r.relyingParty.GetEndSessionEndpoint()
+	if endSession == "" {
+		return "", ErrNoEndSession
+	}
+	params := url.Values{"client_id": {r.relyingParty.OAuthConfig().ClientID}}
+	if session != nil && session.IDToken != "" {
+		params.Set("id_token_hint", session.IDToken)
+	}
+	if r.postLogoutRedirect != "" {
+		params.Set("post_logout_redirect_uri", r.postLogoutRedirect)
+	}
+	if state != "" {
+		params.Set("state", state)
+	}
+	return endSession + "?" + params.Encode(), nil
+}
+
+// LogoutHandler deletes the [Session] and ends the session of the user in ZITADEL.
+// ZITADEL then redirects to the URL set by [WithPostLogoutRedirect].
+func (r *RelyingParty) LogoutHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		session, _ := r.Session(req)
+		r.cookies.DeleteCookie(w, r.sessionCookieName)
+		logoutURL, err := r.LogoutURL(session, "")
+		if err != nil {
+			http.Redirect(w, req, r.postLoginRedirect, http.StatusFound)
+			return
+		}
+		http.Redirect(w, req, logoutURL, http.StatusFound)
+	})
+}
+
+// BackChannelLogoutHandler handles the logout tokens ZITADEL posts to the back-channel logout URI of the application
+// when a session of a user ends (see https://openid.net/specs/openid-connect-backchannel-1_0.html).
+// The logout is recorded in the [LogoutStore].
+func (r *RelyingParty) BackChannelLogoutHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Cache-Control", "no-store")
+		if req.Method != http.MethodPost {
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		token := req.PostFormValue("logout_token")
+		if token == "" {
+			http.Error(w, ErrMissingLogoutToken.Error(), http.StatusBadRequest)
+			return
+		}
+		claims, err := r.logoutVerifier.VerifyLogoutToken(req.Context(), token)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err = r.logouts.Logout(req.Context(), claims.Subject, claims.SessionID, time.Now()); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+// FrontChannelLogoutHandler handles the front-channel logout URI of the application, which the OpenID Provider
+// loads in an iframe when a session of a user ends (see https://openid.net/specs/openid-connect-frontchannel-1_0.html).
+// If the sid parameter is present, the iss parameter is required as well.
+// The session cookie is deleted and, if the sid matches the session of the cookie, its logout is recorded in the [LogoutStore].
+// The request is not authenticated, so the logout of other sessions is never recorded (see [RelyingParty.BackChannelLogoutHandler]).
+// Since browsers might not send the cookies to iframes of other sites, prefer the back-channel logout to end sessions reliably.
+// The Content-Security-Policy of the application must allow ZITADEL to frame the handler (frame-ancestors).
+func (r *RelyingParty) FrontChannelLogoutHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Cache-Control", "no-cache, no-store")
+		w.Header().Set("Pragma", "no-cache")
+		query := req.URL.Query()
+		sessionID, iss := query.Get("sid"), query.Get("iss")
+		if (sessionID != "" && iss == "") || (iss != "" && iss != r.relyingParty.Issuer()) {
+			http.Error(w, "invalid iss", http.StatusBadRequest)
+			return
+		}
+		session, err := r.Session(req)
+		if err != nil || (sessionID != "" && sessionID != session.SessionID) {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		r.cookies.DeleteCookie(w, r.sessionCookieName)
+		if sessionID != "" {
+			if err = r.logouts.Logout(req.Context(), "", sessionID, time.Now()); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+// checkLogout returns [ErrLoggedOut] if the session was logged out by the back- or front-channel.
+func (r *RelyingParty) checkLogout(ctx context.Context, session *Session) error {
+	loggedOut, err := r.logouts.LoggedOut(ctx, session)
+	if err != nil {
+		return err
+	}
+	if loggedOut {
+		return ErrLoggedOut
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package oidcrp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRelyingParty_LogoutHandler(t *testing.T) {
+	relyingParty := newTestRelyingParty(t, newTestServer(t), WithPostLogoutRedirect("https://app.example.com/"))
+
+	recorder := httptest.NewRecorder()
+	relyingParty.LogoutHandler().ServeHTTP(recorder, requestWithSession(t, relyingParty, &Session{Subject: "user1", IDToken: "id-token"}))
+
+	require.Equal(t, http.StatusFound, recorder.Code)
+	location, err := url.Parse(recorder.Header().Get("Location"))
+	require.NoError(t, err)
+	assert.Equal(t, "/oidc/v1/end_session", location.Path)
+	assert.Equal(t, url.Values{
+		"client_id":                {"client1"},
+		"id_token_hint":            {"id-token"},
+		"post_logout_redirect_uri": {"https://app.example.com/"},
+	}, location.Query())
+	cookies := recorder.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.Equal(t, defaultSessionCookieName, cookies[0].Name)
+	assert.Empty(t, cookies[0].Value)
+}
+
+func TestRelyingParty_BackChannelLogoutHandler(t *testing.T) {
+	server := newTestServer(t)
+	relyingParty := newTestRelyingParty(t, server)
+	handler := relyingParty.Middleware(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	session := &Session{Subject: "user1", SessionID: "session1", AccessToken: "token", Expiry: time.Now().Add(time.Hour), IssuedAt: time.Now().Add(-time.Minute)}
+	other := &Session{Subject: "user1", SessionID: "session2", AccessToken: "token", Expiry: time.Now().Add(time.Hour), IssuedAt: time.Now().Add(-time.Minute)}
+
+	logout := func(claims map[string]any) int {
+		form := url.Values{}
+		if claims != nil {
+			form.Set("logout_token", server.sign(t, claims))
+		}
+		req := httptest.NewRequest(http.MethodPost, "/auth/backchannel-logout", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		recorder := httptest.NewRecorder()
+		relyingParty.BackChannelLogoutHandler().ServeHTTP(recorder, req)
+		return recorder.Code
+	}
+	serve := func(session *Session) int {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, requestWithSession(t, relyingParty, session))
+		return recorder.Code
+	}
+	claims := func(sid string) map[string]any {
+		return map[string]any{
+			"iss":    server.URL,
+			"sub":    "user1",
+			"aud":    "client1",
+			"iat":    time.Now().Unix(),
+			"exp":    time.Now().Add(2 * time.Minute).Unix(),
+			"jti":    "logout1",
+			"sid":    sid,
+			"events": map[string]any{"http://schemas.openid.net/event/backchannel-logout": map[string]any{}},
+		}
+	}
+
+	assert.Equal(t, http.StatusBadRequest, logout(nil))
+	invalid := claims("session1")
+	invalid["aud"] = "other"
+	assert.Equal(t, http.StatusBadRequest, logout(invalid))
+	assert.Equal(t, http.StatusOK, serve(session))
+
+	assert.Equal(t, http.StatusOK, logout(claims("session1")))
+	assert.Equal(t, http.StatusFound, serve(session), "logged out session must be rejected")
+	assert.Equal(t, http.StatusOK, serve(other), "other sessions must be kept")
+
+	assert.Equal(t, http.StatusOK, logout(claims("")))
+	assert.Equal(t, http.StatusFound, serve(other), "all sessions of the subject must be rejected")
+}
+
+func TestRelyingParty_FrontChannelLogoutHandler(t *testing.T) {
+	server := newTestServer(t)
+	relyingParty := newTestRelyingParty(t, server)
+	session := &Session{Subject: "user1", SessionID: "session1", IssuedAt: time.Now()}
+
+	recorder := httptest.NewRecorder()
+	relyingParty.FrontChannelLogoutHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/auth/frontchannel-logout?iss=https://other.example.com&sid=session1", nil))
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	recorder = httptest.NewRecorder()
+	relyingParty.FrontChannelLogoutHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/auth/frontchannel-logout?sid=session1", nil))
+	assert.Equal(t, http.StatusBadRequest, recorder.Code, "iss is required with sid")
+
+	// logouts of other sessions than the one of the cookie must not be recorded
+	other := &Session{Subject: "user2", SessionID: "session2", IssuedAt: time.Now()}
+	forged := httptest.NewRequest(http.MethodGet, "/auth/frontchannel-logout?"+url.Values{"iss": {server.URL}, "sid": {"session2"}}.Encode(), nil)
+	recorder = httptest.NewRecorder()
+	relyingParty.FrontChannelLogoutHandler().ServeHTTP(recorder, forged)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	req := requestWithSession(t, relyingParty, session)
+	req.URL.RawQuery = url.Values{"iss": {server.URL}, "sid": {"session2"}}.Encode()
+	recorder = httptest.NewRecorder()
+	relyingParty.FrontChannelLogoutHandler().ServeHTTP(recorder, req)
+	assert.Empty(t, recorder.Result().Cookies())
+	require.NoError(t, relyingParty.checkLogout(context.Background(), other))
+	require.NoError(t, relyingParty.checkLogout(context.Background(), session))
+
+	req = requestWithSession(t, relyingParty, session)
+	req.URL.RawQuery = url.Values{"iss": {server.URL}, "sid": {"session1"}}.Encode()
+	recorder = httptest.NewRecorder()
+	relyingParty.FrontChannelLogoutHandler().ServeHTTP(recorder, req)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "no-cache, no-store", recorder.Header().Get("Cache-Control"))
+	cookies := recorder.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.Empty(t, cookies[0].Value)
+	assert.ErrorIs(t, relyingParty.checkLogout(context.Background(), session), ErrLoggedOut)
+}
+
+func TestMemoryLogoutStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryLogoutStore(time.Hour)
+	now := time.Now()
+	before := &Session{Subject: "user1", SessionID: "session1", IssuedAt: now.Add(-time.Minute)}
+	after := &Session{Subject: "user1", SessionID: "session2", IssuedAt: now.Add(time.Minute)}
+
+	require.NoError(t, store.Logout(ctx, "user1", "", now))
+	for session, want := range map[*Session]bool{before: true, after: false} {
+		loggedOut, err := store.LoggedOut(ctx, session)
+		require.NoError(t, err)
+		assert.Equal(t, want, loggedOut, session.SessionID)
+	}
+
+	require.NoError(t, store.Logout(ctx, "", "session2", now.Add(2*time.Hour)))
+	assert.Empty(t, store.subjects, "expired logouts must be pruned")
+	loggedOut, err := store.LoggedOut(ctx, after)
+	require.NoError(t, err)
+	assert.True(t, loggedOut)
+}
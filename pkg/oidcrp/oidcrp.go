@@ -0,0 +1,387 @@
+// Package oidcrp provides a relying party for web applications to add "Login with ZITADEL" in a few lines.
+//
+// It wires the defaults for ZITADEL into the relying party of the OIDC library: the issuer is discovered
+// from the instance, the code exchange is secured by PKCE (unless a client secret is set), a refresh token
+// is requested (offline_access) and the roles of a project can be requested by [WithProjectID].
+//
+//	relyingParty, err := oidcrp.New(ctx, zitadel.New("my-instance.zitadel.cloud"), clientID, "https://app.example.com/auth/callback", key)
+//	mux.Handle("/auth/login", relyingParty.LoginHandler())
+//	mux.Handle("/auth/callback", relyingParty.CallbackHandler())
+//	mux.Handle("/auth/logout", relyingParty.LogoutHandler())
+//	mux.Handle("/", relyingParty.Middleware(app))
+//
+// The [Session] is stored in an encrypted cookie, so no server-side storage is needed.
+// Browsers limit cookies to about 4KB, so prefer opaque access tokens over JWTs for the application.
+//
+// For a complete sign-out, the [RelyingParty.LogoutHandler] also ends the session in ZITADEL (RP-initiated logout).
+// Sessions ended elsewhere, e.g. by the logout of another application, are ended by ZITADEL calling the
+// [RelyingParty.BackChannelLogoutHandler] or loading the [RelyingParty.FrontChannelLogoutHandler] in an iframe:
+//
+//	mux.Handle("/auth/backchannel-logout", relyingParty.BackChannelLogoutHandler())
+//
+// As the session cookie can't be deleted by the back-channel, the logouts are recorded in a [LogoutStore],
+// which must be shared by all replicas of the application (see [WithLogoutStore]).
+package oidcrp
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/zitadel/oidc/v3/pkg/client/rp"
+	httphelper "github.com/zitadel/oidc/v3/pkg/http"
+	"github.com/zitadel/oidc/v3/pkg/oidc"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client"
+	"github.com/zitadel/zitadel-go/v3/pkg/oidc/verifier"
+	"github.com/zitadel/zitadel-go/v3/pkg/zitadel"
+)
+
+const (
+	defaultSessionCookieName = "zitadel.rp.session"
+	returnToParam            = "return_to"
+	claimRoles               = "urn:zitadel:iam:org:project:roles"
+	scopeProjectRoles        = "urn:zitadel:iam:org:projects:roles"
+)
+
+var (
+	ErrInvalidKey   = errors.New("key must be 16, 24 or 32 bytes")
+	ErrNoSession    = errors.New("no session")
+	ErrNotRefreshed = errors.New("session expired and could not be refreshed")
+)
+
+// RelyingParty handles the login of users of a web application with ZITADEL.
+type RelyingParty struct {
+	relyingParty   rp.RelyingParty
+	cookies        *httphelper.CookieHandler
+	logoutVerifier *verifier.Verifier
+	logouts        LogoutStore
+
+	clientSecret       string
+	scopes             []string
+	sessionCookieName  string
+	loginPath          string
+	postLoginRedirect  string
+	postLogoutRedirect string
+	insecureCookies    bool
+	httpClient         *http.Client
+}
+
+// Option allows customization of the [RelyingParty].
+type Option func(*RelyingParty)
+
+// WithClientSecret authenticates the code exchange with the client secret instead of PKCE.
+func WithClientSecret(secret string) Option {
+	return func(r *RelyingParty) {
+		r.clientSecret = secret
+	}
+}
+
+// WithScopes adds scopes to the default `openid profile email offline_access`.
+func WithScopes(scopes ...string) Option {
+	return func(r *RelyingParty) {
+		r.scopes = append(r.scopes, scopes...)
+	}
+}
+
+// WithProjectID requests the project as audience of the tokens, e.g. to call the API of the project,
+// and the roles of the user in the project, which are then available as [Session.Roles].
+func WithProjectID(projectID string) Option {
+	return WithScopes(client.ScopeProjectID(projectID), scopeProjectRoles)
+}
+
+// WithSessionCookieName sets the name of the cookie storing the [Session] (default `zitadel.rp.session`).
+func WithSessionCookieName(name string) Option {
+	return func(r *RelyingParty) {
+		r.sessionCookieName = name
+	}
+}
+
+// WithLoginPath sets the path of the [RelyingParty.LoginHandler] the [RelyingParty.Middleware]
+// redirects to (default `/auth/login`).
+func WithLoginPath(path string) Option {
+	return func(r *RelyingParty) {
+		r.loginPath = path
+	}
+}
+
+// WithPostLoginRedirect sets the path the user is redirected to after the login,
+// if the login was not started with a `return_to` (default `/`).
+func WithPostLoginRedirect(path string) Option {
+	return func(r *RelyingParty) {
+		r.postLoginRedirect = path
+	}
+}
+
+// WithPostLogoutRedirect sets the URL ZITADEL redirects the user to after the logout.
+// It must be registered as post logout redirect URI of the application.
+func WithPostLogoutRedirect(uri string) Option {
+	return func(r *RelyingParty) {
+		r.postLogoutRedirect = uri
+	}
+}
+
+// WithInsecureCookies allows the cookies to be sent over plain HTTP, e.g. for local development.
+func WithInsecureCookies() Option {
+	return func(r *RelyingParty) {
+		r.insecureCookies = true
+	}
+}
+
+// WithHTTPClient allows to use a custom [http.Client] for the discovery, token, userinfo and key requests.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(r *RelyingParty) {
+		r.httpClient = httpClient
+	}
+}
+
+// New discovers the instance and creates a [RelyingParty] for the application with the clientID.
+// The redirectURI must point to the [RelyingParty.CallbackHandler] and be registered for the application.
+// The key (16, 24 or 32 bytes) signs and encrypts the cookies, it must be the same for all replicas of the application.
+func New(ctx context.Context, zitadel *zitadel.Zitadel, clientID, redirectURI string, key []byte, opts ...Option) (*RelyingParty, error) {
+	switch len(key) {
+	case 16, 24, 32:
+	default:
+		return nil, ErrInvalidKey
+	}
+	r := &RelyingParty{
+		scopes:            []string{oidc.ScopeOpenID, oidc.ScopeProfile, oidc.ScopeEmail, oidc.ScopeOfflineAccess},
+		sessionCookieName: defaultSessionCookieName,
+		loginPath:         "/auth/login",
+		postLoginRedirect: "/",
+		logouts:           NewMemoryLogoutStore(DefaultLogoutRetention),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	cookieOpts := []httphelper.CookieHandlerOpt{httphelper.WithSameSite(http.SameSiteLaxMode)}
+	if r.insecureCookies {
+		cookieOpts = append(cookieOpts, httphelper.WithUnsecure())
+	}
+	r.cookies = httphelper.NewCookieHandler(key, key, cookieOpts...)
+
+	rpOpts := []rp.Option{rp.WithCookieHandler(r.cookies)}
+	if r.clientSecret == "" {
+		rpOpts = []rp.Option{rp.WithPKCE(r.cookies)}
+	}
+	if r.httpClient != nil {
+		rpOpts = append(rpOpts, rp.WithHTTPClient(r.httpClient))
+	}
+	var err error
+	r.relyingParty, err = rp.NewRelyingPartyOIDC(ctx, zitadel.Origin(), clientID, r.clientSecret, redirectURI, slices.Compact(r.scopes), rpOpts...)
+	if err != nil {
+		return nil, err
+	}
+	// the keys are only refreshed on unknown key IDs of logout tokens, so no background refresh outlives the context
+	verifierOpts := []verifier.Option{verifier.WithAudience(clientID), verifier.WithRefreshInterval(0)}
+	if r.httpClient != nil {
+		verifierOpts = append(verifierOpts, verifier.WithHTTPClient(r.httpClient))
+	}
+	r.logoutVerifier, err = verifier.New(ctx, zitadel, verifierOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Session of a logged-in user, stored in an encrypted cookie.
+type Session struct {
+	Subject           string    `json:"sub"`
+	Name              string    `json:"name,omitempty"`
+	Email             string    `json:"email,omitempty"`
+	PreferredUsername string    `json:"preferred_username,omitempty"`
+	Roles             []string  `json:"roles,omitempty"`
+	AccessToken       string    `json:"access_token"`
+	RefreshToken      string    `json:"refresh_token,omitempty"`
+	IDToken           string    `json:"id_token,omitempty"`
+	Expiry            time.Time `json:"expiry"`
+	// SessionID is the ID of the session in ZITADEL (sid claim), used by the back- and front-channel logout.
+	SessionID string `json:"sid,omitempty"`
+	// IssuedAt is the time of the login, it's kept on refreshes.
+	IssuedAt time.Time `json:"iat"`
+}
+
+// Expired reports if the access token of the session is expired.
+func (s *Session) Expired() bool {
+	return !s.Expiry.IsZero() && time.Now().After(s.Expiry)
+}
+
+// HasRole reports if the user has the role in the project requested by [WithProjectID].
+func (s *Session) HasRole(role string) bool {
+	return slices.Contains(s.Roles, role)
+}
+
+// LoginHandler redirects the user to the login of ZITADEL.
+// The path to return to after the login can be passed as `return_to` query parameter,
+// only relative paths are accepted to prevent open redirects.
+func (r *RelyingParty) LoginHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		state, err := newState(returnTo(req.URL.Query().Get(returnToParam), r.postLoginRedirect))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		rp.AuthURLHandler(func() string { return state }, r.relyingParty)(w, req)
+	})
+}
+
+// CallbackHandler handles the redirect back from ZITADEL: it exchanges the code for the tokens,
+// retrieves the userinfo, stores the [Session] and redirects the user to the `return_to` of the login.
+func (r *RelyingParty) CallbackHandler() http.Handler {
+	return rp.CodeExchangeHandler(rp.UserinfoCallback(func(w http.ResponseWriter, req *http.Request, tokens *oidc.Tokens[*oidc.IDTokenClaims], state string, _ rp.RelyingParty, info *oidc.UserInfo) {
+		session := newSession(tokens, info)
+		if err := r.setSession(w, session); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		http.Redirect(w, req, stateReturnTo(state, r.postLoginRedirect), http.StatusFound)
+	}), r.relyingParty)
+}
+
+// Session returns the [Session] stored in the cookie of the request or [ErrNoSession].
+// The session might be expired, use [RelyingParty.Middleware] to refresh it.
+func (r *RelyingParty) Session(req *http.Request) (*Session, error) {
+	value, err := r.cookies.CheckCookie(req, r.sessionCookieName)
+	if err != nil {
+		return nil, ErrNoSession
+	}
+	session := new(Session)
+	if err := json.Unmarshal([]byte(value), session); err != nil {
+		return nil, ErrNoSession
+	}
+	return session, nil
+}
+
+// Refresh uses the refresh token of an expired session to get new tokens and stores the refreshed [Session].
+func (r *RelyingParty) Refresh(w http.ResponseWriter, req *http.Request, session *Session) (*Session, error) {
+	if session.RefreshToken == "" {
+		return nil, ErrNotRefreshed
+	}
+	tokens, err := rp.RefreshTokens[*oidc.IDTokenClaims](req.Context(), r.relyingParty, session.RefreshToken, "", "")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotRefreshed, err)
+	}
+	refreshed := *session
+	refreshed.AccessToken = tokens.AccessToken
+	refreshed.Expiry = tokens.Expiry
+	if tokens.RefreshToken != "" {
+		refreshed.RefreshToken = tokens.RefreshToken
+	}
+	if tokens.IDToken != "" {
+		refreshed.IDToken = tokens.IDToken
+	}
+	if err := r.setSession(w, &refreshed); err != nil {
+		return nil, err
+	}
+	return &refreshed, nil
+}
+
+// Middleware requires a [Session] for the next handler, which can retrieve it by [FromContext].
+// Sessions logged out by ZITADEL (see [RelyingParty.BackChannelLogoutHandler]) are rejected, expired sessions are refreshed. Without a valid session, browsers navigating to a page (GET requests)
+// are redirected to the login and return there afterward, all other requests are rejected with 401.
+func (r *RelyingParty) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		session, err := r.Session(req)
+		if err == nil {
+			err = r.checkLogout(req.Context(), session)
+		}
+		if err == nil && session.Expired() {
+			session, err = r.Refresh(w, req, session)
+		}
+		if err != nil {
+			r.cookies.DeleteCookie(w, r.sessionCookieName)
+			if req.Method != http.MethodGet {
+				http.Error(w, err.Error(), http.StatusUnauthorized)
+				return
+			}
+			http.Redirect(w, req, r.loginPath+"?"+url.Values{returnToParam: {req.URL.RequestURI()}}.Encode(), http.StatusFound)
+			return
+		}
+		next.ServeHTTP(w, req.WithContext(context.WithValue(req.Context(), sessionKey{}, session)))
+	})
+}
+
+type sessionKey struct{}
+
+// FromContext returns the [Session] set by the [RelyingParty.Middleware].
+func FromContext(ctx context.Context) (*Session, bool) {
+	session, ok := ctx.Value(sessionKey{}).(*Session)
+	return session, ok
+}
+
+func (r *RelyingParty) setSession(w http.ResponseWriter, session *Session) error {
+	value, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+	return r.cookies.SetCookie(w, r.sessionCookieName, string(value))
+}
+
+func newSession(tokens *oidc.Tokens[*oidc.IDTokenClaims], info *oidc.UserInfo) *Session {
+	session := &Session{
+		Subject:           info.Subject,
+		Name:              info.Name,
+		Email:             info.Email,
+		PreferredUsername: info.PreferredUsername,
+		AccessToken:       tokens.AccessToken,
+		RefreshToken:      tokens.RefreshToken,
+		IDToken:           tokens.IDToken,
+		Expiry:            tokens.Expiry,
+		IssuedAt:          time.Now(),
+	}
+	if tokens.IDTokenClaims != nil {
+		session.SessionID = tokens.IDTokenClaims.SessionID
+		if iat := tokens.IDTokenClaims.IssuedAt; iat != 0 {
+			session.IssuedAt = iat.AsTime()
+		}
+	}
+	if roles, ok := info.Claims[claimRoles].(map[string]any); ok {
+		for role := range roles {
+			session.Roles = append(session.Roles, role)
+		}
+		slices.Sort(session.Roles)
+	}
+	return session
+}
+
+// newState returns a random state carrying the path to return to after the login.
+func newState(returnTo string) (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b) + "." + base64.RawURLEncoding.EncodeToString([]byte(returnTo)), nil
+}
+
+// stateReturnTo returns the path to return to carried in the state.
+func stateReturnTo(state, fallback string) string {
+	_, encoded, ok := strings.Cut(state, ".")
+	if !ok {
+		return fallback
+	}
+	path, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return fallback
+	}
+	return returnTo(string(path), fallback)
+}
+
+// returnTo returns the path if it's relative to the application, otherwise the fallback.
+func returnTo(path, fallback string) string {
+	if path == "" || !strings.HasPrefix(path, "/") || strings.HasPrefix(path, "//") || strings.ContainsAny(path, "\\\r\n") {
+		return fallback
+	}
+	u, err := url.Parse(path)
+	if err != nil || u.Scheme != "" || u.Host != "" {
+		return fallback
+	}
+	return path
+}
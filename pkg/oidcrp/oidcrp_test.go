@@ -0,0 +1,162 @@
+package oidcrp
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/zitadel"
+)
+
+var testKey = []byte("0123456789abcdef0123456789abcdef")
+
+type testServer struct {
+	*httptest.Server
+	key *ecdsa.PrivateKey
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	s := &testServer{key: key}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 s.URL,
+			"authorization_endpoint": s.URL + "/oauth/v2/authorize",
+			"token_endpoint":         s.URL + "/oauth/v2/token",
+			"userinfo_endpoint":      s.URL + "/oidc/v1/userinfo",
+			"end_session_endpoint":   s.URL + "/oidc/v1/end_session",
+			"jwks_uri":               s.URL + "/oauth/v2/keys",
+		})
+	})
+	mux.HandleFunc("/oauth/v2/keys", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+			{Key: key.Public(), KeyID: "k1", Algorithm: string(jose.ES256), Use: "sig"},
+		}})
+	})
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *testServer) zitadel(t *testing.T) *zitadel.Zitadel {
+	u, err := url.Parse(s.URL)
+	require.NoError(t, err)
+	return zitadel.New(u.Hostname(), zitadel.WithInsecure(u.Port()))
+}
+
+func (s *testServer) sign(t *testing.T, claims map[string]any) string {
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.ES256, Key: jose.JSONWebKey{Key: s.key, KeyID: "k1"}}, nil)
+	require.NoError(t, err)
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+	jws, err := signer.Sign(payload)
+	require.NoError(t, err)
+	token, err := jws.CompactSerialize()
+	require.NoError(t, err)
+	return token
+}
+
+func newTestRelyingParty(t *testing.T, server *testServer, opts ...Option) *RelyingParty {
+	t.Helper()
+	relyingParty, err := New(context.Background(), server.zitadel(t), "client1", "https://app.example.com/auth/callback", testKey, opts...)
+	require.NoError(t, err)
+	return relyingParty
+}
+
+func TestNew_invalidKey(t *testing.T) {
+	_, err := New(context.Background(), zitadel.New("issuer.example.com"), "client1", "https://app.example.com/auth/callback", []byte("short"))
+	assert.ErrorIs(t, err, ErrInvalidKey)
+}
+
+func TestRelyingParty_LoginHandler(t *testing.T) {
+	relyingParty := newTestRelyingParty(t, newTestServer(t), WithProjectID("p1"))
+
+	recorder := httptest.NewRecorder()
+	relyingParty.LoginHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/auth/login?return_to=%2Forders%3Fpage%3D2", nil))
+
+	require.Equal(t, http.StatusFound, recorder.Code)
+	location, err := url.Parse(recorder.Header().Get("Location"))
+	require.NoError(t, err)
+	assert.Equal(t, "/oauth/v2/authorize", location.Path)
+	query := location.Query()
+	assert.Equal(t, "openid profile email offline_access urn:zitadel:iam:org:project:id:p1:aud urn:zitadel:iam:org:projects:roles", query.Get("scope"))
+	assert.NotEmpty(t, query.Get("code_challenge"))
+	assert.Equal(t, "/orders?page=2", stateReturnTo(query.Get("state"), "/"))
+}
+
+func TestRelyingParty_Middleware(t *testing.T) {
+	relyingParty := newTestRelyingParty(t, newTestServer(t))
+	var got *Session
+	handler := relyingParty.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ = FromContext(r.Context())
+	}))
+
+	t.Run("redirect to login", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/orders?page=2", nil))
+		assert.Equal(t, http.StatusFound, recorder.Code)
+		assert.Equal(t, "/auth/login?return_to=%2Forders%3Fpage%3D2", recorder.Header().Get("Location"))
+	})
+	t.Run("unauthorized", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/orders", nil))
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	})
+	t.Run("expired without refresh token", func(t *testing.T) {
+		req := requestWithSession(t, relyingParty, &Session{Subject: "user1", AccessToken: "token", Expiry: time.Now().Add(-time.Minute)})
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+		assert.Equal(t, http.StatusFound, recorder.Code)
+	})
+	t.Run("session", func(t *testing.T) {
+		session := &Session{Subject: "user1", Roles: []string{"reader"}, AccessToken: "token", Expiry: time.Now().Add(time.Hour).Truncate(time.Second)}
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, requestWithSession(t, relyingParty, session))
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		require.NotNil(t, got)
+		assert.Equal(t, session.Subject, got.Subject)
+		assert.True(t, got.HasRole("reader"))
+		assert.True(t, session.Expiry.Equal(got.Expiry))
+	})
+}
+
+func Test_returnTo(t *testing.T) {
+	for path, want := range map[string]string{
+		"":                      "/",
+		"/orders?page=2":        "/orders?page=2",
+		"https://evil.example":  "/",
+		"//evil.example/path":   "/",
+		"/\\evil.example":       "/",
+		"orders":                "/",
+		"/path\r\nLocation: x":  "/",
+		"javascript:alert(1)":   "/",
+		"/nested/path#fragment": "/nested/path#fragment",
+	} {
+		assert.Equal(t, want, returnTo(path, "/"), path)
+	}
+}
+
+func requestWithSession(t *testing.T, relyingParty *RelyingParty, session *Session) *http.Request {
+	t.Helper()
+	recorder := httptest.NewRecorder()
+	require.NoError(t, relyingParty.setSession(recorder, session))
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	for _, cookie := range recorder.Result().Cookies() {
+		req.AddCookie(cookie)
+	}
+	return req
+}
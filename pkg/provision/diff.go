@@ -0,0 +1,456 @@
+package provision
+
+import (
+	"context"
+	"slices"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/app"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/idp"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/management"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/object"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/project"
+	"github.com/zitadel/zitadel-go/v3/pkg/internal/pager"
+	"github.com/zitadel/zitadel-go/v3/pkg/management/projects"
+)
+
+func (r *Reconciler) planIDPs(plan *Plan, orgRef *ref, org Organization, live []*idp.Provider) {
+	byName := make(map[string]*idp.Provider, len(live))
+	for _, provider := range live {
+		byName[provider.GetName()] = provider
+	}
+	for _, desired := range org.IDPs {
+		path := org.Name + "/" + desired.Name
+		current, ok := byName[desired.Name]
+		delete(byName, desired.Name)
+		if !ok {
+			plan.add(ActionCreate, KindIDP, path, func(ctx context.Context, _ *Result) error {
+				_, err := r.management(orgRef.id).AddGenericOIDCProvider(ctx, &management.AddGenericOIDCProviderRequest{
+					Name:             desired.Name,
+					Issuer:           desired.Issuer,
+					ClientId:         desired.ClientID,
+					ClientSecret:     desired.ClientSecret,
+					Scopes:           desired.Scopes,
+					UsePkce:          desired.PKCE,
+					IsIdTokenMapping: desired.IDTokenMapping,
+				})
+				return err
+			})
+			continue
+		}
+		if diff := idpDiff(desired, current); len(diff) > 0 {
+			id := current.GetId()
+			plan.add(ActionUpdate, KindIDP, path, func(ctx context.Context, _ *Result) error {
+				_, err := r.management(orgRef.id).UpdateGenericOIDCProvider(ctx, &management.UpdateGenericOIDCProviderRequest{
+					Id:               id,
+					Name:             desired.Name,
+					Issuer:           desired.Issuer,
+					ClientId:         desired.ClientID,
+					ClientSecret:     desired.ClientSecret,
+					Scopes:           desired.Scopes,
+					ProviderOptions:  current.GetConfig().GetOptions(),
+					UsePkce:          desired.PKCE,
+					IsIdTokenMapping: desired.IDTokenMapping,
+				})
+				return err
+			}, diff...)
+		}
+	}
+	if !r.prune {
+		return
+	}
+	for _, provider := range live {
+		if _, ok := byName[provider.GetName()]; !ok {
+			continue
+		}
+		id := provider.GetId()
+		plan.add(ActionDelete, KindIDP, org.Name+"/"+provider.GetName(), func(ctx context.Context, _ *Result) error {
+			_, err := r.management(orgRef.id).DeleteProvider(ctx, &management.DeleteProviderRequest{Id: id})
+			return err
+		})
+	}
+}
+
+// idpDiff returns the names of the attributes differing from the live provider.
+// A provider of another type is compared by its (missing) OIDC config, so it's updated to a generic OIDC provider.
+func idpDiff(desired IDP, current *idp.Provider) []string {
+	config := current.GetConfig().GetOidc()
+	var diff []string
+	if config.GetIssuer() != desired.Issuer {
+		diff = append(diff, "issuer")
+	}
+	if config.GetClientId() != desired.ClientID {
+		diff = append(diff, "clientID")
+	}
+	if !equalUnordered(config.GetScopes(), desired.Scopes) {
+		diff = append(diff, "scopes")
+	}
+	if config.GetUsePkce() != desired.PKCE {
+		diff = append(diff, "pkce")
+	}
+	if config.GetIsIdTokenMapping() != desired.IDTokenMapping {
+		diff = append(diff, "idTokenMapping")
+	}
+	return diff
+}
+
+func (r *Reconciler) planProjects(ctx context.Context, plan *Plan, orgRef *ref, org Organization, live []*project.Project) error {
+	byName := make(map[string]*project.Project, len(live))
+	for _, p := range live {
+		byName[p.GetName()] = p
+	}
+	for _, desired := range org.Projects {
+		path := org.Name + "/" + desired.Name
+		current, ok := byName[desired.Name]
+		delete(byName, desired.Name)
+		if !ok {
+			projectRef := new(ref)
+			plan.add(ActionCreate, KindProject, path, func(ctx context.Context, _ *Result) error {
+				resp, err := r.management(orgRef.id).AddProject(ctx, &management.AddProjectRequest{
+					Name:                 desired.Name,
+					ProjectRoleAssertion: desired.RoleAssertion,
+					ProjectRoleCheck:     desired.RoleCheck,
+					HasProjectCheck:      desired.ProjectCheck,
+				})
+				if err != nil {
+					return err
+				}
+				projectRef.id = resp.GetId()
+				return nil
+			})
+			r.planRoles(plan, orgRef, projectRef, path, desired.Roles, nil)
+			r.planApps(plan, orgRef, projectRef, path, desired.Apps, nil)
+			continue
+		}
+		projectRef := &ref{id: current.GetId()}
+		if diff := projectDiff(desired, current); len(diff) > 0 {
+			plan.add(ActionUpdate, KindProject, path, func(ctx context.Context, _ *Result) error {
+				_, err := r.management(orgRef.id).UpdateProject(ctx, &management.UpdateProjectRequest{
+					Id:                     projectRef.id,
+					Name:                   desired.Name,
+					ProjectRoleAssertion:   desired.RoleAssertion,
+					ProjectRoleCheck:       desired.RoleCheck,
+					HasProjectCheck:        desired.ProjectCheck,
+					PrivateLabelingSetting: current.GetPrivateLabelingSetting(),
+				})
+				return err
+			}, diff...)
+		}
+		mgmt := r.management(orgRef.id)
+		liveRoles, err := listRoles(ctx, mgmt, projectRef.id)
+		if err != nil {
+			return err
+		}
+		r.planRoles(plan, orgRef, projectRef, path, desired.Roles, liveRoles)
+		liveApps, err := listApps(ctx, mgmt, projectRef.id)
+		if err != nil {
+			return err
+		}
+		r.planApps(plan, orgRef, projectRef, path, desired.Apps, liveApps)
+	}
+	if !r.prune {
+		return nil
+	}
+	for _, p := range live {
+		if _, ok := byName[p.GetName()]; !ok {
+			continue
+		}
+		id := p.GetId()
+		plan.add(ActionDelete, KindProject, org.Name+"/"+p.GetName(), func(ctx context.Context, _ *Result) error {
+			_, err := r.management(orgRef.id).RemoveProject(ctx, &management.RemoveProjectRequest{Id: id})
+			return err
+		})
+	}
+	return nil
+}
+
+func projectDiff(desired Project, current *project.Project) []string {
+	var diff []string
+	if current.GetProjectRoleAssertion() != desired.RoleAssertion {
+		diff = append(diff, "roleAssertion")
+	}
+	if current.GetProjectRoleCheck() != desired.RoleCheck {
+		diff = append(diff, "roleCheck")
+	}
+	if current.GetHasProjectCheck() != desired.ProjectCheck {
+		diff = append(diff, "projectCheck")
+	}
+	return diff
+}
+
+func (r *Reconciler) planRoles(plan *Plan, orgRef, projectRef *ref, projectPath string, desired []Role, live []*project.Role) {
+	byKey := make(map[string]*project.Role, len(live))
+	for _, role := range live {
+		byKey[role.GetKey()] = role
+	}
+	for _, role := range desired {
+		path := projectPath + "/" + role.Key
+		displayName := role.DisplayName
+		if displayName == "" {
+			displayName = role.Key
+		}
+		current, ok := byKey[role.Key]
+		delete(byKey, role.Key)
+		switch {
+		case !ok:
+			plan.add(ActionCreate, KindRole, path, func(ctx context.Context, _ *Result) error {
+				_, err := r.management(orgRef.id).AddProjectRole(ctx, &management.AddProjectRoleRequest{
+					ProjectId:   projectRef.id,
+					RoleKey:     role.Key,
+					DisplayName: displayName,
+					Group:       role.Group,
+				})
+				return err
+			})
+		case current.GetDisplayName() != displayName || current.GetGroup() != role.Group:
+			plan.add(ActionUpdate, KindRole, path, func(ctx context.Context, _ *Result) error {
+				_, err := r.management(orgRef.id).UpdateProjectRole(ctx, &management.UpdateProjectRoleRequest{
+					ProjectId:   projectRef.id,
+					RoleKey:     role.Key,
+					DisplayName: displayName,
+					Group:       role.Group,
+				})
+				return err
+			}, roleDiff(displayName, role.Group, current)...)
+		}
+	}
+	if !r.prune {
+		return
+	}
+	for _, role := range live {
+		if _, ok := byKey[role.GetKey()]; !ok {
+			continue
+		}
+		key := role.GetKey()
+		plan.add(ActionDelete, KindRole, projectPath+"/"+key, func(ctx context.Context, _ *Result) error {
+			_, err := r.management(orgRef.id).RemoveProjectRole(ctx, &management.RemoveProjectRoleRequest{ProjectId: projectRef.id, RoleKey: key})
+			return err
+		})
+	}
+}
+
+func roleDiff(displayName, group string, current *project.Role) []string {
+	var diff []string
+	if current.GetDisplayName() != displayName {
+		diff = append(diff, "displayName")
+	}
+	if current.GetGroup() != group {
+		diff = append(diff, "group")
+	}
+	return diff
+}
+
+func (r *Reconciler) planApps(plan *Plan, orgRef, projectRef *ref, projectPath string, desired []App, live []*app.App) {
+	byName := make(map[string]*app.App, len(live))
+	for _, a := range live {
+		byName[a.GetName()] = a
+	}
+	for _, a := range desired {
+		path := projectPath + "/" + a.Name
+		current, ok := byName[a.Name]
+		delete(byName, a.Name)
+		if ok {
+			update, diff, replace := appChange(a, current)
+			if !replace {
+				if update != nil {
+					plan.add(ActionUpdate, KindApp, path, func(ctx context.Context, _ *Result) error {
+						return update(ctx, r.management(orgRef.id), projectRef.id)
+					}, diff...)
+				}
+				continue
+			}
+			// the type can't be changed, the application is recreated instead
+			r.planAppDelete(plan, orgRef, projectRef, path, current.GetId())
+		}
+		plan.add(ActionCreate, KindApp, path, func(ctx context.Context, result *Result) error {
+			created, err := r.projects.AddApplication(ctx, orgRef.id, projectRef.id, a.application())
+			if err != nil {
+				return err
+			}
+			if created.ClientID != "" {
+				result.Credentials = append(result.Credentials, Credential{Path: path, ClientID: created.ClientID, ClientSecret: created.ClientSecret})
+			}
+			return nil
+		})
+	}
+	if !r.prune {
+		return
+	}
+	for _, a := range live {
+		if _, ok := byName[a.GetName()]; ok {
+			r.planAppDelete(plan, orgRef, projectRef, projectPath+"/"+a.GetName(), a.GetId())
+		}
+	}
+}
+
+func (r *Reconciler) planAppDelete(plan *Plan, orgRef, projectRef *ref, path, appID string) {
+	plan.add(ActionDelete, KindApp, path, func(ctx context.Context, _ *Result) error {
+		_, err := r.management(orgRef.id).RemoveApp(ctx, &management.RemoveAppRequest{ProjectId: projectRef.id, AppId: appID})
+		return err
+	})
+}
+
+type appUpdate func(ctx context.Context, mgmt management.ManagementServiceClient, projectID string) error
+
+// appChange compares the desired with the live application. It returns the update (nil if up to date)
+// and the differing attributes, or replace if the application has to be recreated because of a different type.
+func appChange(desired App, current *app.App) (update appUpdate, diff []string, replace bool) {
+	switch config := current.GetConfig().(type) {
+	case *app.App_OidcConfig:
+		if desired.Type == AppAPI || config.OidcConfig.GetAppType() != desired.oidcAppType() {
+			return nil, nil, true
+		}
+		return oidcAppChange(desired, current.GetId(), config.OidcConfig)
+	case *app.App_ApiConfig:
+		if desired.Type != AppAPI {
+			return nil, nil, true
+		}
+		if config.ApiConfig.GetAuthMethodType() == desired.apiAuthMethod() {
+			return nil, nil, false
+		}
+		return func(ctx context.Context, mgmt management.ManagementServiceClient, projectID string) error {
+			_, err := mgmt.UpdateAPIAppConfig(ctx, &management.UpdateAPIAppConfigRequest{
+				ProjectId:      projectID,
+				AppId:          current.GetId(),
+				AuthMethodType: desired.apiAuthMethod(),
+			})
+			return err
+		}, []string{"privateKeyJWT"}, false
+	default:
+		return nil, nil, true
+	}
+}
+
+func oidcAppChange(desired App, appID string, config *app.OIDCConfig) (appUpdate, []string, bool) {
+	var diff []string
+	if !equalUnordered(config.GetRedirectUris(), desired.RedirectURIs) {
+		diff = append(diff, "redirectURIs")
+	}
+	if !equalUnordered(config.GetPostLogoutRedirectUris(), desired.PostLogoutRedirectURIs) {
+		diff = append(diff, "postLogoutRedirectURIs")
+	}
+	if config.GetDevMode() != desired.DevMode {
+		diff = append(diff, "devMode")
+	}
+	if config.GetAuthMethodType() != desired.oidcAuthMethod() {
+		diff = append(diff, "privateKeyJWT")
+	}
+	if len(diff) == 0 {
+		return nil, nil, false
+	}
+	return func(ctx context.Context, mgmt management.ManagementServiceClient, projectID string) error {
+		_, err := mgmt.UpdateOIDCAppConfig(ctx, &management.UpdateOIDCAppConfigRequest{
+			ProjectId:                projectID,
+			AppId:                    appID,
+			RedirectUris:             desired.RedirectURIs,
+			ResponseTypes:            config.GetResponseTypes(),
+			GrantTypes:               config.GetGrantTypes(),
+			AppType:                  config.GetAppType(),
+			AuthMethodType:           desired.oidcAuthMethod(),
+			PostLogoutRedirectUris:   desired.PostLogoutRedirectURIs,
+			DevMode:                  desired.DevMode,
+			AccessTokenType:          config.GetAccessTokenType(),
+			AccessTokenRoleAssertion: config.GetAccessTokenRoleAssertion(),
+			IdTokenRoleAssertion:     config.GetIdTokenRoleAssertion(),
+			IdTokenUserinfoAssertion: config.GetIdTokenUserinfoAssertion(),
+			ClockSkew:                config.GetClockSkew(),
+			AdditionalOrigins:        config.GetAdditionalOrigins(),
+			SkipNativeAppSuccessPage: config.GetSkipNativeAppSuccessPage(),
+			BackChannelLogoutUri:     config.GetBackChannelLogoutUri(),
+			LoginVersion:             config.GetLoginVersion(),
+		})
+		return err
+	}, diff, false
+}
+
+func (a App) application() projects.App {
+	switch a.Type {
+	case AppAPI:
+		return projects.APIApp{Name: a.Name, PrivateKeyJWT: a.PrivateKeyJWT}
+	case AppSinglePage:
+		return a.oidcApp(projects.OIDCSinglePage)
+	case AppNative:
+		return a.oidcApp(projects.OIDCNative)
+	default:
+		return a.oidcApp(projects.OIDCWeb)
+	}
+}
+
+func (a App) oidcApp(appType projects.OIDCAppType) projects.OIDCApp {
+	return projects.OIDCApp{
+		Name:                   a.Name,
+		Type:                   appType,
+		RedirectURIs:           a.RedirectURIs,
+		PostLogoutRedirectURIs: a.PostLogoutRedirectURIs,
+		DevMode:                a.DevMode,
+		PrivateKeyJWT:          a.PrivateKeyJWT,
+	}
+}
+
+func (a App) oidcAppType() app.OIDCAppType {
+	switch a.Type {
+	case AppSinglePage:
+		return app.OIDCAppType_OIDC_APP_TYPE_USER_AGENT
+	case AppNative:
+		return app.OIDCAppType_OIDC_APP_TYPE_NATIVE
+	default:
+		return app.OIDCAppType_OIDC_APP_TYPE_WEB
+	}
+}
+
+func (a App) oidcAuthMethod() app.OIDCAuthMethodType {
+	switch {
+	case a.Type != AppWeb:
+		return app.OIDCAuthMethodType_OIDC_AUTH_METHOD_TYPE_NONE
+	case a.PrivateKeyJWT:
+		return app.OIDCAuthMethodType_OIDC_AUTH_METHOD_TYPE_PRIVATE_KEY_JWT
+	default:
+		return app.OIDCAuthMethodType_OIDC_AUTH_METHOD_TYPE_BASIC
+	}
+}
+
+func (a App) apiAuthMethod() app.APIAuthMethodType {
+	if a.PrivateKeyJWT {
+		return app.APIAuthMethodType_API_AUTH_METHOD_TYPE_PRIVATE_KEY_JWT
+	}
+	return app.APIAuthMethodType_API_AUTH_METHOD_TYPE_BASIC
+}
+
+func equalUnordered(a, b []string) bool {
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(a, b)
+}
+
+func listIDPs(ctx context.Context, mgmt management.ManagementServiceClient) ([]*idp.Provider, error) {
+	return pager.ListAll(func(query *object.ListQuery) ([]*idp.Provider, *object.ListDetails, error) {
+		resp, err := mgmt.ListProviders(ctx, &management.ListProvidersRequest{
+			Query: query,
+			Queries: []*management.ProviderQuery{{
+				Query: &management.ProviderQuery_OwnerTypeQuery{OwnerTypeQuery: &idp.IDPOwnerTypeQuery{OwnerType: idp.IDPOwnerType_IDP_OWNER_TYPE_ORG}},
+			}},
+		})
+		return resp.GetResult(), resp.GetDetails(), err
+	})
+}
+
+func listProjects(ctx context.Context, mgmt management.ManagementServiceClient) ([]*project.Project, error) {
+	return pager.ListAll(func(query *object.ListQuery) ([]*project.Project, *object.ListDetails, error) {
+		resp, err := mgmt.ListProjects(ctx, &management.ListProjectsRequest{Query: query})
+		return resp.GetResult(), resp.GetDetails(), err
+	})
+}
+
+func listRoles(ctx context.Context, mgmt management.ManagementServiceClient, projectID string) ([]*project.Role, error) {
+	return pager.ListAll(func(query *object.ListQuery) ([]*project.Role, *object.ListDetails, error) {
+		resp, err := mgmt.ListProjectRoles(ctx, &management.ListProjectRolesRequest{ProjectId: projectID, Query: query})
+		return resp.GetResult(), resp.GetDetails(), err
+	})
+}
+
+func listApps(ctx context.Context, mgmt management.ManagementServiceClient, projectID string) ([]*app.App, error) {
+	return pager.ListAll(func(query *object.ListQuery) ([]*app.App, *object.ListDetails, error) {
+		resp, err := mgmt.ListApps(ctx, &management.ListAppsRequest{ProjectId: projectID, Query: query})
+		return resp.GetResult(), resp.GetDetails(), err
+	})
+}
@@ -0,0 +1,92 @@
+package provision
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Action is the operation of a [Change].
+type Action string
+
+const (
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+)
+
+// Kind is the type of resource of a [Change].
+type Kind string
+
+const (
+	KindOrganization Kind = "organization"
+	KindProject      Kind = "project"
+	KindRole         Kind = "role"
+	KindApp          Kind = "app"
+	KindIDP          Kind = "idp"
+)
+
+// Change is a single operation needed to reach the desired state.
+type Change struct {
+	Action Action
+	Kind   Kind
+	// Path identifies the resource by the names of its parents and itself, e.g. `ACME/portal/web`.
+	Path string
+	// Details describes the differences of an update, e.g. `redirectURIs`.
+	Details []string
+	apply   func(ctx context.Context, result *Result) error
+}
+
+// String returns a human readable representation, e.g. `update app ACME/portal/web (redirectURIs)`.
+func (c Change) String() string {
+	s := fmt.Sprintf("%s %s %s", c.Action, c.Kind, c.Path)
+	if len(c.Details) > 0 {
+		s += " (" + strings.Join(c.Details, ", ") + ")"
+	}
+	return s
+}
+
+// Plan contains the changes needed to reach the desired state, in the order they are applied.
+// It's created by [Reconciler.Plan] and applied by [Reconciler.Apply].
+type Plan struct {
+	Changes []Change
+}
+
+// Empty returns if the live state already matches the desired state.
+func (p *Plan) Empty() bool {
+	return len(p.Changes) == 0
+}
+
+// String returns all changes, one per line.
+func (p *Plan) String() string {
+	lines := make([]string, len(p.Changes))
+	for i, change := range p.Changes {
+		lines[i] = change.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (p *Plan) add(action Action, kind Kind, path string, apply func(ctx context.Context, result *Result) error, details ...string) {
+	p.Changes = append(p.Changes, Change{Action: action, Kind: kind, Path: path, Details: details, apply: apply})
+}
+
+// Result is the outcome of [Reconciler.Apply].
+type Result struct {
+	// Applied are the successfully applied changes.
+	Applied []Change
+	// Credentials of the created applications, the client secrets are only returned once.
+	Credentials []Credential
+}
+
+// Credential is the client of a created application.
+type Credential struct {
+	// Path of the application, e.g. `ACME/portal/web`.
+	Path         string
+	ClientID     string
+	ClientSecret string
+}
+
+// ref is the ID of a resource, which is only known after its creation for resources created by the plan.
+type ref struct {
+	id string
+}
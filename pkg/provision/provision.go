@@ -0,0 +1,156 @@
+// Package provision reconciles a declarative description ([Spec]) of organizations, projects, roles,
+// applications and identity providers with the live state of ZITADEL.
+// [Reconciler.Plan] diffs the description against the management API and [Reconciler.Apply] executes only
+// the resulting changes, so running it repeatedly with the same description is idempotent:
+//
+//	spec, err := provision.LoadFile("zitadel.yaml")
+//	if err != nil {
+//		return err
+//	}
+//	reconciler := provision.New(client, provision.WithPrune())
+//	plan, err := reconciler.Plan(ctx, spec)
+//	if err != nil {
+//		return err
+//	}
+//	fmt.Println(plan)
+//	result, err := reconciler.Apply(ctx, plan)
+//
+// Resources are matched by their name within their parent. Organizations are never deleted.
+package provision
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/management"
+	objectV2 "github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/object/v2"
+	orgV2 "github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/org/v2"
+	"github.com/zitadel/zitadel-go/v3/pkg/management/projects"
+)
+
+// Reconciler diffs and applies a [Spec].
+type Reconciler struct {
+	client   *client.Client
+	projects *projects.Projects
+	prune    bool
+}
+
+// Option allows customization of the [Reconciler].
+type Option func(*Reconciler)
+
+// WithPrune deletes projects, roles, applications and identity providers of the described organizations
+// which are not part of the [Spec]. By default, they are left untouched.
+func WithPrune() Option {
+	return func(r *Reconciler) {
+		r.prune = true
+	}
+}
+
+// New creates a [Reconciler] using the client.
+// The client must be allowed to create organizations and manage their resources (e.g. IAM_OWNER).
+func New(client *client.Client, opts ...Option) *Reconciler {
+	r := &Reconciler{
+		client:   client,
+		projects: projects.New(client),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Reconcile plans and applies the spec in one step.
+func (r *Reconciler) Reconcile(ctx context.Context, spec *Spec) (*Result, error) {
+	plan, err := r.Plan(ctx, spec)
+	if err != nil {
+		return nil, err
+	}
+	return r.Apply(ctx, plan)
+}
+
+// Plan reads the live state of the resources described by the spec and returns the changes needed
+// to reach the desired state. Nothing is changed.
+func (r *Reconciler) Plan(ctx context.Context, spec *Spec) (*Plan, error) {
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+	plan := new(Plan)
+	for _, org := range spec.Organizations {
+		if err := r.planOrganization(ctx, plan, org); err != nil {
+			return nil, fmt.Errorf("plan organization %s: %w", org.Name, err)
+		}
+	}
+	return plan, nil
+}
+
+// Apply executes the changes of the plan in order and stops on the first error.
+// The returned [Result] contains the applied changes also in case of an error.
+// Since the plan reflects the live state at the time of planning, it should be applied right away.
+func (r *Reconciler) Apply(ctx context.Context, plan *Plan) (*Result, error) {
+	result := new(Result)
+	for _, change := range plan.Changes {
+		if err := change.apply(ctx, result); err != nil {
+			return result, fmt.Errorf("%s: %w", change, err)
+		}
+		result.Applied = append(result.Applied, change)
+	}
+	return result, nil
+}
+
+func (r *Reconciler) planOrganization(ctx context.Context, plan *Plan, org Organization) error {
+	orgID, err := r.findOrganization(ctx, org.Name)
+	if err != nil {
+		return err
+	}
+	orgRef := &ref{id: orgID}
+	if orgID == "" {
+		plan.add(ActionCreate, KindOrganization, org.Name, func(ctx context.Context, _ *Result) error {
+			resp, err := r.client.OrganizationServiceV2().AddOrganization(ctx, &orgV2.AddOrganizationRequest{Name: org.Name})
+			if err != nil {
+				return err
+			}
+			orgRef.id = resp.GetOrganizationId()
+			return nil
+		})
+		r.planIDPs(plan, orgRef, org, nil)
+		return r.planProjects(ctx, plan, orgRef, org, nil)
+	}
+	mgmt := r.management(orgID)
+	liveIDPs, err := listIDPs(ctx, mgmt)
+	if err != nil {
+		return err
+	}
+	r.planIDPs(plan, orgRef, org, liveIDPs)
+	liveProjects, err := listProjects(ctx, mgmt)
+	if err != nil {
+		return err
+	}
+	return r.planProjects(ctx, plan, orgRef, org, liveProjects)
+}
+
+// findOrganization returns the ID of the organization with the name or an empty string if there is none.
+func (r *Reconciler) findOrganization(ctx context.Context, name string) (string, error) {
+	resp, err := r.client.OrganizationServiceV2().ListOrganizations(ctx, &orgV2.ListOrganizationsRequest{
+		Queries: []*orgV2.SearchQuery{{
+			Query: &orgV2.SearchQuery_NameQuery{NameQuery: &orgV2.OrganizationNameQuery{
+				Name:   name,
+				Method: objectV2.TextQueryMethod_TEXT_QUERY_METHOD_EQUALS,
+			}},
+		}},
+	})
+	if err != nil {
+		return "", err
+	}
+	for _, org := range resp.GetResult() {
+		if org.GetName() == name {
+			return org.GetId(), nil
+		}
+	}
+	return "", nil
+}
+
+// management returns the management service in the context of the organization.
+func (r *Reconciler) management(orgID string) management.ManagementServiceClient {
+	return r.client.ForOrganization(orgID).ManagementService()
+}
@@ -0,0 +1,125 @@
+package provision
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/app"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/project"
+)
+
+const specYAML = `
+organizations:
+  - name: ACME
+    idps:
+      - name: corporate
+        issuer: https://idp.acme.com
+        clientID: zitadel
+        clientSecret: ${PROVISION_TEST_SECRET}
+    projects:
+      - name: portal
+        roleAssertion: true
+        roles:
+          - key: admin
+          - key: reader
+            displayName: Reader
+        apps:
+          - name: web
+            type: web
+            redirectURIs: [https://portal.acme.com/callback]
+          - name: api
+            type: api
+`
+
+func TestParse(t *testing.T) {
+	t.Setenv("PROVISION_TEST_SECRET", "secret")
+	spec, err := Parse([]byte(specYAML))
+	require.NoError(t, err)
+	require.Len(t, spec.Organizations, 1)
+	org := spec.Organizations[0]
+	assert.Equal(t, "secret", org.IDPs[0].ClientSecret)
+	assert.Equal(t, []Role{{Key: "admin"}, {Key: "reader", DisplayName: "Reader"}}, org.Projects[0].Roles)
+	assert.Equal(t, App{Name: "web", Type: AppWeb, RedirectURIs: []string{"https://portal.acme.com/callback"}}, org.Projects[0].Apps[0])
+}
+
+func TestSpec_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    Spec
+		wantErr error
+	}{
+		{
+			name:    "missing name",
+			spec:    Spec{Organizations: []Organization{{}}},
+			wantErr: ErrMissingName,
+		},
+		{
+			name: "duplicate role",
+			spec: Spec{Organizations: []Organization{{
+				Name:     "ACME",
+				Projects: []Project{{Name: "portal", Roles: []Role{{Key: "admin"}, {Key: "admin"}}}},
+			}}},
+			wantErr: ErrDuplicateName,
+		},
+		{
+			name: "unknown app type",
+			spec: Spec{Organizations: []Organization{{
+				Name:     "ACME",
+				Projects: []Project{{Name: "portal", Apps: []App{{Name: "web", Type: "saml"}}}},
+			}}},
+			wantErr: ErrUnknownApp,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, tt.spec.Validate(), tt.wantErr)
+		})
+	}
+}
+
+func TestReconciler_planRoles(t *testing.T) {
+	plan := new(Plan)
+	r := &Reconciler{prune: true}
+	r.planRoles(plan, new(ref), new(ref), "ACME/portal",
+		[]Role{{Key: "admin"}, {Key: "reader", DisplayName: "Reader"}, {Key: "writer"}},
+		[]*project.Role{
+			{Key: "admin", DisplayName: "admin"},
+			{Key: "reader", DisplayName: "reader"},
+			{Key: "obsolete", DisplayName: "obsolete"},
+		},
+	)
+	assert.Equal(t, "update role ACME/portal/reader (displayName)\n"+
+		"create role ACME/portal/writer\n"+
+		"delete role ACME/portal/obsolete", plan.String())
+}
+
+func TestReconciler_planApps(t *testing.T) {
+	plan := new(Plan)
+	r := new(Reconciler)
+	r.planApps(plan, new(ref), new(ref), "ACME/portal",
+		[]App{
+			{Name: "web", Type: AppWeb, RedirectURIs: []string{"https://b", "https://a"}},
+			{Name: "spa", Type: AppSinglePage, RedirectURIs: []string{"https://spa"}, DevMode: true},
+			{Name: "api", Type: AppAPI},
+		},
+		[]*app.App{
+			{Id: "1", Name: "web", Config: &app.App_OidcConfig{OidcConfig: &app.OIDCConfig{
+				RedirectUris:   []string{"https://a", "https://b"},
+				AppType:        app.OIDCAppType_OIDC_APP_TYPE_WEB,
+				AuthMethodType: app.OIDCAuthMethodType_OIDC_AUTH_METHOD_TYPE_BASIC,
+			}}},
+			{Id: "2", Name: "spa", Config: &app.App_OidcConfig{OidcConfig: &app.OIDCConfig{
+				RedirectUris:   []string{"https://spa"},
+				AppType:        app.OIDCAppType_OIDC_APP_TYPE_USER_AGENT,
+				AuthMethodType: app.OIDCAuthMethodType_OIDC_AUTH_METHOD_TYPE_NONE,
+			}}},
+			{Id: "3", Name: "api", Config: &app.App_OidcConfig{OidcConfig: &app.OIDCConfig{}}},
+			{Id: "4", Name: "unmanaged", Config: &app.App_ApiConfig{ApiConfig: &app.APIConfig{}}},
+		},
+	)
+	assert.Equal(t, "update app ACME/portal/spa (devMode)\n"+
+		"delete app ACME/portal/api\n"+
+		"create app ACME/portal/api", plan.String())
+}
@@ -0,0 +1,161 @@
+package provision
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	ErrMissingName   = errors.New("name is required")
+	ErrDuplicateName = errors.New("duplicate name")
+	ErrUnknownApp    = errors.New("unknown application type")
+)
+
+// Spec is the desired state of the resources.
+// All resources are identified by their name (resp. key for roles) within their parent.
+type Spec struct {
+	Organizations []Organization `yaml:"organizations" json:"organizations"`
+}
+
+// Organization is the desired state of an organization.
+type Organization struct {
+	Name     string    `yaml:"name" json:"name"`
+	Projects []Project `yaml:"projects,omitempty" json:"projects,omitempty"`
+	IDPs     []IDP     `yaml:"idps,omitempty" json:"idps,omitempty"`
+}
+
+// Project is the desired state of a project.
+type Project struct {
+	Name          string `yaml:"name" json:"name"`
+	RoleAssertion bool   `yaml:"roleAssertion,omitempty" json:"roleAssertion,omitempty"`
+	RoleCheck     bool   `yaml:"roleCheck,omitempty" json:"roleCheck,omitempty"`
+	ProjectCheck  bool   `yaml:"projectCheck,omitempty" json:"projectCheck,omitempty"`
+	Roles         []Role `yaml:"roles,omitempty" json:"roles,omitempty"`
+	Apps          []App  `yaml:"apps,omitempty" json:"apps,omitempty"`
+}
+
+// Role is the desired state of a project role.
+type Role struct {
+	Key string `yaml:"key" json:"key"`
+	// DisplayName defaults to the Key.
+	DisplayName string `yaml:"displayName,omitempty" json:"displayName,omitempty"`
+	Group       string `yaml:"group,omitempty" json:"group,omitempty"`
+}
+
+// AppType is the type of an [App].
+type AppType string
+
+const (
+	// AppWeb is an OIDC server side web application.
+	AppWeb AppType = "web"
+	// AppSinglePage is an OIDC browser application using PKCE.
+	AppSinglePage AppType = "spa"
+	// AppNative is an OIDC mobile or desktop application using PKCE.
+	AppNative AppType = "native"
+	// AppAPI is an API (resource server).
+	AppAPI AppType = "api"
+)
+
+// App is the desired state of an application.
+// The defaults of the application types are the ones of the projects package.
+type App struct {
+	Name                   string   `yaml:"name" json:"name"`
+	Type                   AppType  `yaml:"type" json:"type"`
+	RedirectURIs           []string `yaml:"redirectURIs,omitempty" json:"redirectURIs,omitempty"`
+	PostLogoutRedirectURIs []string `yaml:"postLogoutRedirectURIs,omitempty" json:"postLogoutRedirectURIs,omitempty"`
+	DevMode                bool     `yaml:"devMode,omitempty" json:"devMode,omitempty"`
+	// PrivateKeyJWT authenticates web and API applications by a key instead of a client secret.
+	PrivateKeyJWT bool `yaml:"privateKeyJWT,omitempty" json:"privateKeyJWT,omitempty"`
+}
+
+// IDP is the desired state of a generic OIDC identity provider of the organization.
+type IDP struct {
+	Name     string `yaml:"name" json:"name"`
+	Issuer   string `yaml:"issuer" json:"issuer"`
+	ClientID string `yaml:"clientID" json:"clientID"`
+	// ClientSecret is only set on creation and whenever another attribute of the provider changes,
+	// since it can't be compared to the live state.
+	ClientSecret   string   `yaml:"clientSecret" json:"clientSecret"`
+	Scopes         []string `yaml:"scopes,omitempty" json:"scopes,omitempty"`
+	PKCE           bool     `yaml:"pkce,omitempty" json:"pkce,omitempty"`
+	IDTokenMapping bool     `yaml:"idTokenMapping,omitempty" json:"idTokenMapping,omitempty"`
+}
+
+// Parse parses and validates the YAML (or JSON) description of the [Spec].
+// Environment variables (e.g. `${OIDC_CLIENT_SECRET}`) are expanded, so secrets don't have to be part of the file.
+func Parse(data []byte) (*Spec, error) {
+	spec := new(Spec)
+	if err := yaml.Unmarshal([]byte(os.ExpandEnv(string(data))), spec); err != nil {
+		return nil, err
+	}
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
+
+// LoadFile reads the file and parses it using [Parse].
+func LoadFile(path string) (*Spec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// Validate checks that all resources are named and the names are unique within their parent.
+func (s *Spec) Validate() error {
+	orgs := make(names)
+	for _, org := range s.Organizations {
+		if err := orgs.add("organization", org.Name); err != nil {
+			return err
+		}
+		idps := make(names)
+		for _, idp := range org.IDPs {
+			if err := idps.add("idp in "+org.Name, idp.Name); err != nil {
+				return err
+			}
+		}
+		projects := make(names)
+		for _, project := range org.Projects {
+			if err := projects.add("project in "+org.Name, project.Name); err != nil {
+				return err
+			}
+			path := org.Name + "/" + project.Name
+			roles := make(names)
+			for _, role := range project.Roles {
+				if err := roles.add("role in "+path, role.Key); err != nil {
+					return err
+				}
+			}
+			apps := make(names)
+			for _, app := range project.Apps {
+				if err := apps.add("app in "+path, app.Name); err != nil {
+					return err
+				}
+				switch app.Type {
+				case AppWeb, AppSinglePage, AppNative, AppAPI:
+				default:
+					return fmt.Errorf("%w %q of app %s/%s", ErrUnknownApp, app.Type, path, app.Name)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+type names map[string]struct{}
+
+func (n names) add(kind, name string) error {
+	if name == "" {
+		return fmt.Errorf("%w: %s", ErrMissingName, kind)
+	}
+	if _, ok := n[name]; ok {
+		return fmt.Errorf("%w: %s %q", ErrDuplicateName, kind, name)
+	}
+	n[name] = struct{}{}
+	return nil
+}
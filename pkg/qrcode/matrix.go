@@ -0,0 +1,240 @@
+package qrcode
+
+// builder places the function patterns and codewords on the modules of a QR code.
+type builder struct {
+	size     int
+	modules  [][]bool
+	function [][]bool
+}
+
+func (b *builder) set(x, y int, dark bool) {
+	b.modules[y][x] = dark
+	b.function[y][x] = true
+}
+
+func (b *builder) drawFunctionPatterns(version int, info versionInfo) {
+	for i := 0; i < b.size; i++ {
+		b.set(6, i, i%2 == 0)
+		b.set(i, 6, i%2 == 0)
+	}
+	b.drawFinder(3, 3)
+	b.drawFinder(b.size-4, 3)
+	b.drawFinder(3, b.size-4)
+
+	positions := info.alignmentPosition
+	last := len(positions) - 1
+	for i, x := range positions {
+		for j, y := range positions {
+			// skip the positions overlapping the finder patterns
+			if i == 0 && j == 0 || i == 0 && j == last || i == last && j == 0 {
+				continue
+			}
+			b.drawAlignment(x, y)
+		}
+	}
+	// reserve the format areas, the bits are drawn after masking
+	b.drawFormatBits(0)
+	b.drawVersion(version)
+}
+
+// drawFinder draws the finder pattern centered at x, y including the separator.
+func (b *builder) drawFinder(x, y int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			xx, yy := x+dx, y+dy
+			if xx < 0 || xx >= b.size || yy < 0 || yy >= b.size {
+				continue
+			}
+			dist := max(abs(dx), abs(dy))
+			b.set(xx, yy, dist != 2 && dist != 4)
+		}
+	}
+}
+
+func (b *builder) drawAlignment(x, y int) {
+	for dy := -2; dy <= 2; dy++ {
+		for dx := -2; dx <= 2; dx++ {
+			b.set(x+dx, y+dy, max(abs(dx), abs(dy)) != 1)
+		}
+	}
+}
+
+// drawFormatBits draws both copies of the format information of error correction level M and the mask.
+func (b *builder) drawFormatBits(mask int) {
+	data := mask // level M is 0b00
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = (rem << 1) ^ ((rem >> 9) * 0x537)
+	}
+	bits := (data<<10 | rem) ^ 0x5412
+
+	for i := 0; i <= 5; i++ {
+		b.set(8, i, bit(bits, i))
+	}
+	b.set(8, 7, bit(bits, 6))
+	b.set(8, 8, bit(bits, 7))
+	b.set(7, 8, bit(bits, 8))
+	for i := 9; i < 15; i++ {
+		b.set(14-i, 8, bit(bits, i))
+	}
+
+	for i := 0; i < 8; i++ {
+		b.set(b.size-1-i, 8, bit(bits, i))
+	}
+	for i := 8; i < 15; i++ {
+		b.set(8, b.size-15+i, bit(bits, i))
+	}
+	b.set(8, b.size-8, true)
+}
+
+// drawVersion draws both copies of the version information (version 7 and higher).
+func (b *builder) drawVersion(version int) {
+	if version < 7 {
+		return
+	}
+	rem := version
+	for i := 0; i < 12; i++ {
+		rem = (rem << 1) ^ ((rem >> 11) * 0x1F25)
+	}
+	bits := version<<12 | rem
+	for i := 0; i < 18; i++ {
+		x, y := b.size-11+i%3, i/3
+		b.set(x, y, bit(bits, i))
+		b.set(y, x, bit(bits, i))
+	}
+}
+
+// drawCodewords places the codewords in the zigzag order, starting at the bottom right corner.
+func (b *builder) drawCodewords(codewords []byte) {
+	i := 0
+	for right := b.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vert := 0; vert < b.size; vert++ {
+			for j := 0; j < 2; j++ {
+				x := right - j
+				y := vert
+				if (right+1)&2 == 0 {
+					y = b.size - 1 - vert
+				}
+				if b.function[y][x] || i >= len(codewords)*8 {
+					continue
+				}
+				b.modules[y][x] = codewords[i>>3]>>(7-i&7)&1 == 1
+				i++
+			}
+		}
+	}
+}
+
+// applyMask inverts the data modules selected by the mask, applying it twice reverts it.
+func (b *builder) applyMask(mask int) {
+	for y := 0; y < b.size; y++ {
+		for x := 0; x < b.size; x++ {
+			if b.function[y][x] {
+				continue
+			}
+			var invert bool
+			switch mask {
+			case 0:
+				invert = (x+y)%2 == 0
+			case 1:
+				invert = y%2 == 0
+			case 2:
+				invert = x%3 == 0
+			case 3:
+				invert = (x+y)%3 == 0
+			case 4:
+				invert = (x/3+y/2)%2 == 0
+			case 5:
+				invert = x*y%2+x*y%3 == 0
+			case 6:
+				invert = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				invert = ((x+y)%2+x*y%3)%2 == 0
+			}
+			b.modules[y][x] = b.modules[y][x] != invert
+		}
+	}
+}
+
+// penalty scores the modules using the rules of the specification, the mask with the lowest score is used.
+func (b *builder) penalty() int {
+	penalty := 0
+	for i := 0; i < b.size; i++ {
+		penalty += linePenalty(func(j int) bool { return b.modules[i][j] }, b.size)
+		penalty += linePenalty(func(j int) bool { return b.modules[j][i] }, b.size)
+	}
+	dark := 0
+	for y := 0; y < b.size; y++ {
+		for x := 0; x < b.size; x++ {
+			if b.modules[y][x] {
+				dark++
+			}
+			if x < b.size-1 && y < b.size-1 {
+				c := b.modules[y][x]
+				if c == b.modules[y][x+1] && c == b.modules[y+1][x] && c == b.modules[y+1][x+1] {
+					penalty += 3
+				}
+			}
+		}
+	}
+	total := b.size * b.size
+	k := (abs(dark*20-total*10)+total-1)/total - 1
+	return penalty + k*10
+}
+
+// linePenalty scores runs of the same color and finder-like patterns of a row or column.
+func linePenalty(module func(int) bool, size int) int {
+	penalty := 0
+	run := 0
+	for j := 0; j < size; j++ {
+		if j > 0 && module(j) == module(j-1) {
+			run++
+		} else {
+			run = 1
+		}
+		if run == 5 {
+			penalty += 3
+		} else if run > 5 {
+			penalty++
+		}
+	}
+	at := func(j int) bool {
+		return j >= 0 && j < size && module(j)
+	}
+	finder := []bool{true, false, true, true, true, false, true}
+	for j := 0; j+len(finder) <= size; j++ {
+		matches := true
+		for k, dark := range finder {
+			if at(j+k) != dark {
+				matches = false
+				break
+			}
+		}
+		if !matches {
+			continue
+		}
+		lightBefore, lightAfter := true, true
+		for k := 1; k <= 4; k++ {
+			lightBefore = lightBefore && !at(j-k)
+			lightAfter = lightAfter && !at(j+len(finder)-1+k)
+		}
+		if lightBefore || lightAfter {
+			penalty += 40
+		}
+	}
+	return penalty
+}
+
+func bit(value, i int) bool {
+	return value>>i&1 == 1
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,255 @@
+// Package qrcode encodes short texts (e.g. `otpauth://` URIs) as QR codes (ISO/IEC 18004)
+// and renders them as PNG images, without depending on an external library.
+//
+// Texts are encoded in byte mode with error correction level M, which supports up to 213 bytes (version 10).
+package qrcode
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+)
+
+// ErrTooLong is returned if the text exceeds the capacity of the supported versions.
+var ErrTooLong = errors.New("qrcode: text too long")
+
+const quietZone = 4
+
+// Code is an encoded QR code.
+type Code struct {
+	size    int
+	modules [][]bool
+}
+
+// Size returns the number of modules per side (without quiet zone).
+func (c *Code) Size() int {
+	return c.size
+}
+
+// Dark returns if the module at column x and row y is dark.
+func (c *Code) Dark(x, y int) bool {
+	return c.modules[y][x]
+}
+
+// Image renders the code with scale pixels per module, including the quiet zone of 4 modules.
+func (c *Code) Image(scale int) image.Image {
+	if scale < 1 {
+		scale = 1
+	}
+	dim := (c.size + 2*quietZone) * scale
+	img := image.NewPaletted(image.Rect(0, 0, dim, dim), color.Palette{color.White, color.Black})
+	for y := 0; y < c.size; y++ {
+		for x := 0; x < c.size; x++ {
+			if !c.modules[y][x] {
+				continue
+			}
+			for py := 0; py < scale; py++ {
+				for px := 0; px < scale; px++ {
+					img.SetColorIndex((x+quietZone)*scale+px, (y+quietZone)*scale+py, 1)
+				}
+			}
+		}
+	}
+	return img
+}
+
+// PNG renders the code as PNG image with scale pixels per module.
+func (c *Code) PNG(scale int) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, c.Image(scale)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Encode encodes the text using the smallest possible version.
+func Encode(text string) (*Code, error) {
+	data := []byte(text)
+	for version := 1; version < len(versions); version++ {
+		if len(data) > capacity(version) {
+			continue
+		}
+		return encode(version, data), nil
+	}
+	return nil, ErrTooLong
+}
+
+// versionInfo contains the error correction blocks of level M.
+type versionInfo struct {
+	ecPerBlock        int
+	blocks1, data1    int
+	blocks2, data2    int
+	alignmentPosition []int
+}
+
+func (v versionInfo) dataCodewords() int {
+	return v.blocks1*v.data1 + v.blocks2*v.data2
+}
+
+// countBits returns the length of the character count of the byte mode.
+func countBits(version int) int {
+	if version < 10 {
+		return 8
+	}
+	return 16
+}
+
+// capacity returns the maximal number of bytes, which fit besides the mode indicator and the character count.
+func capacity(version int) int {
+	return (versions[version].dataCodewords()*8 - 4 - countBits(version)) / 8
+}
+
+var versions = []versionInfo{
+	{},
+	{ecPerBlock: 10, blocks1: 1, data1: 16},
+	{ecPerBlock: 16, blocks1: 1, data1: 28, alignmentPosition: []int{6, 18}},
+	{ecPerBlock: 26, blocks1: 1, data1: 44, alignmentPosition: []int{6, 22}},
+	{ecPerBlock: 18, blocks1: 2, data1: 32, alignmentPosition: []int{6, 26}},
+	{ecPerBlock: 24, blocks1: 2, data1: 43, alignmentPosition: []int{6, 30}},
+	{ecPerBlock: 16, blocks1: 4, data1: 27, alignmentPosition: []int{6, 34}},
+	{ecPerBlock: 18, blocks1: 4, data1: 31, alignmentPosition: []int{6, 22, 38}},
+	{ecPerBlock: 22, blocks1: 2, data1: 38, blocks2: 2, data2: 39, alignmentPosition: []int{6, 24, 42}},
+	{ecPerBlock: 22, blocks1: 3, data1: 36, blocks2: 2, data2: 37, alignmentPosition: []int{6, 26, 46}},
+	{ecPerBlock: 26, blocks1: 4, data1: 43, blocks2: 1, data2: 44, alignmentPosition: []int{6, 28, 50}},
+}
+
+func encode(version int, data []byte) *Code {
+	info := versions[version]
+	codewords := interleave(info, dataCodewords(version, data))
+	size := version*4 + 17
+	q := &builder{
+		size:     size,
+		modules:  newGrid(size),
+		function: newGrid(size),
+	}
+	q.drawFunctionPatterns(version, info)
+	q.drawCodewords(codewords)
+
+	best, bestPenalty := 0, -1
+	for mask := 0; mask < 8; mask++ {
+		q.applyMask(mask)
+		q.drawFormatBits(mask)
+		if penalty := q.penalty(); bestPenalty < 0 || penalty < bestPenalty {
+			best, bestPenalty = mask, penalty
+		}
+		q.applyMask(mask)
+	}
+	q.applyMask(best)
+	q.drawFormatBits(best)
+	return &Code{size: size, modules: q.modules}
+}
+
+// dataCodewords encodes the data in byte mode including terminator and padding.
+func dataCodewords(version int, data []byte) []byte {
+	w := new(bitWriter)
+	w.write(0b0100, 4)
+	w.write(len(data), countBits(version))
+	for _, b := range data {
+		w.write(int(b), 8)
+	}
+	capacity := versions[version].dataCodewords() * 8
+	w.write(0, min(4, capacity-w.len))
+	w.write(0, (8-w.len%8)%8)
+	for pad := 0xEC; w.len < capacity; pad ^= 0xEC ^ 0x11 {
+		w.write(pad, 8)
+	}
+	return w.bytes
+}
+
+// interleave splits the data into the blocks, computes their error correction
+// and interleaves the codewords of all blocks.
+func interleave(info versionInfo, data []byte) []byte {
+	divisor := reedSolomonDivisor(info.ecPerBlock)
+	var dataBlocks, ecBlocks [][]byte
+	offset := 0
+	for i := 0; i < info.blocks1+info.blocks2; i++ {
+		length := info.data1
+		if i >= info.blocks1 {
+			length = info.data2
+		}
+		block := data[offset : offset+length]
+		offset += length
+		dataBlocks = append(dataBlocks, block)
+		ecBlocks = append(ecBlocks, reedSolomonRemainder(block, divisor))
+	}
+	result := make([]byte, 0, len(data)+len(ecBlocks)*info.ecPerBlock)
+	for i := 0; i < max(info.data1, info.data2); i++ {
+		for _, block := range dataBlocks {
+			if i < len(block) {
+				result = append(result, block[i])
+			}
+		}
+	}
+	for i := 0; i < info.ecPerBlock; i++ {
+		for _, block := range ecBlocks {
+			result = append(result, block[i])
+		}
+	}
+	return result
+}
+
+type bitWriter struct {
+	bytes []byte
+	len   int
+}
+
+func (w *bitWriter) write(value, bits int) {
+	for i := bits - 1; i >= 0; i-- {
+		if w.len%8 == 0 {
+			w.bytes = append(w.bytes, 0)
+		}
+		if value>>i&1 == 1 {
+			w.bytes[w.len/8] |= 1 << (7 - w.len%8)
+		}
+		w.len++
+	}
+}
+
+func reedSolomonDivisor(degree int) []byte {
+	divisor := make([]byte, degree)
+	divisor[degree-1] = 1
+	root := byte(1)
+	for i := 0; i < degree; i++ {
+		for j := range divisor {
+			divisor[j] = gfMultiply(divisor[j], root)
+			if j+1 < degree {
+				divisor[j] ^= divisor[j+1]
+			}
+		}
+		root = gfMultiply(root, 0x02)
+	}
+	return divisor
+}
+
+func reedSolomonRemainder(data, divisor []byte) []byte {
+	result := make([]byte, len(divisor))
+	for _, b := range data {
+		factor := b ^ result[0]
+		copy(result, result[1:])
+		result[len(result)-1] = 0
+		for i, coefficient := range divisor {
+			result[i] ^= gfMultiply(coefficient, factor)
+		}
+	}
+	return result
+}
+
+// gfMultiply multiplies in GF(2^8) modulo x^8 + x^4 + x^3 + x^2 + 1.
+func gfMultiply(x, y byte) byte {
+	z := 0
+	for i := 7; i >= 0; i-- {
+		z = (z << 1) ^ ((z >> 7) * 0x11D)
+		z ^= int(y>>i&1) * int(x)
+	}
+	return byte(z)
+}
+
+func newGrid(size int) [][]bool {
+	grid := make([][]bool, size)
+	for i := range grid {
+		grid[i] = make([]bool, size)
+	}
+	return grid
+}
@@ -0,0 +1,100 @@
+package qrcode
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_reedSolomonRemainder(t *testing.T) {
+	// `HELLO WORLD` as 1-M symbol (ISO/IEC 18004, annex I)
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	want := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+	assert.Equal(t, want, reedSolomonRemainder(data, reedSolomonDivisor(10)))
+}
+
+func Test_drawFormatBits(t *testing.T) {
+	tests := []struct {
+		mask int
+		want string
+	}{
+		{0, "101010000010010"},
+		{1, "101000100100101"},
+		{4, "100010111111001"},
+		{6, "100111110010111"},
+		{7, "100101010100000"},
+	}
+	for _, tt := range tests {
+		b := &builder{size: 21, modules: newGrid(21), function: newGrid(21)}
+		b.drawFormatBits(tt.mask)
+		var got strings.Builder
+		// the most significant bit is placed at the bottom of the column next to the bottom left finder
+		for i := 14; i >= 8; i-- {
+			got.WriteString(module(b.modules[b.size-15+i][8]))
+		}
+		for i := 7; i >= 0; i-- {
+			got.WriteString(module(b.modules[8][b.size-1-i]))
+		}
+		assert.Equal(t, tt.want, got.String(), "mask %d", tt.mask)
+	}
+}
+
+func Test_drawVersion(t *testing.T) {
+	b := &builder{size: 45, modules: newGrid(45), function: newGrid(45)}
+	b.drawVersion(7)
+	var got strings.Builder
+	for i := 17; i >= 0; i-- {
+		got.WriteString(module(b.modules[i/3][b.size-11+i%3]))
+	}
+	assert.Equal(t, "000111110010010100", got.String())
+}
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		name     string
+		length   int
+		wantSize int
+		wantErr  error
+	}{
+		{"version 1", 14, 21, nil},
+		{"version 2", 15, 25, nil},
+		{"version 9", 180, 53, nil},
+		{"version 10", 213, 57, nil},
+		{"too long", 214, 0, ErrTooLong},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, err := Encode(strings.Repeat("a", tt.length))
+			require.ErrorIs(t, err, tt.wantErr)
+			if tt.wantErr != nil {
+				return
+			}
+			assert.Equal(t, tt.wantSize, code.Size())
+			// finder pattern and dark module
+			assert.True(t, code.Dark(0, 0))
+			assert.False(t, code.Dark(1, 1))
+			assert.True(t, code.Dark(8, code.Size()-8))
+		})
+	}
+}
+
+func TestCode_PNG(t *testing.T) {
+	code, err := Encode("otpauth://totp/ZITADEL:alice@example.com?secret=JBSWY3DPEHPK3PXP&issuer=ZITADEL")
+	require.NoError(t, err)
+	data, err := code.PNG(4)
+	require.NoError(t, err)
+	img, err := png.Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, (code.Size()+8)*4, img.Bounds().Dx())
+}
+
+func module(dark bool) string {
+	if dark {
+		return "1"
+	}
+	return "0"
+}
@@ -0,0 +1,59 @@
+package quota
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/admin"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/milestone"
+)
+
+// MilestoneType identifies a [Milestone], e.g. `authentication_succeeded_on_instance`.
+type MilestoneType string
+
+const (
+	MilestoneInstanceCreated                      MilestoneType = "instance_created"
+	MilestoneAuthenticationSucceededOnInstance    MilestoneType = "authentication_succeeded_on_instance"
+	MilestoneProjectCreated                       MilestoneType = "project_created"
+	MilestoneApplicationCreated                   MilestoneType = "application_created"
+	MilestoneAuthenticationSucceededOnApplication MilestoneType = "authentication_succeeded_on_application"
+	MilestoneInstanceDeleted                      MilestoneType = "instance_deleted"
+)
+
+// Milestone is a step in the lifecycle of an instance, e.g. the first successful authentication.
+type Milestone struct {
+	Type MilestoneType
+	// ReachedAt is zero if the milestone is not reached yet.
+	ReachedAt time.Time
+}
+
+// Reached returns if the milestone was reached.
+func (m Milestone) Reached() bool {
+	return !m.ReachedAt.IsZero()
+}
+
+// Milestones returns the milestones of the instance of the client.
+func Milestones(ctx context.Context, client *client.Client) ([]Milestone, error) {
+	return listMilestones(ctx, client.AdminService())
+}
+
+func listMilestones(ctx context.Context, adminService admin.AdminServiceClient) ([]Milestone, error) {
+	resp, err := adminService.ListMilestones(ctx, &admin.ListMilestonesRequest{
+		SortingColumn: milestone.MilestoneFieldName_MILESTONE_FIELD_NAME_TYPE,
+	})
+	if err != nil {
+		return nil, err
+	}
+	milestones := make([]Milestone, len(resp.GetResult()))
+	for i, m := range resp.GetResult() {
+		milestones[i] = Milestone{
+			Type: MilestoneType(strings.ToLower(strings.TrimPrefix(m.GetType().String(), "MILESTONE_TYPE_"))),
+		}
+		if m.GetReachedDate() != nil {
+			milestones[i].ReachedAt = m.GetReachedDate().AsTime()
+		}
+	}
+	return milestones, nil
+}
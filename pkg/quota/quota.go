@@ -0,0 +1,340 @@
+// Package quota configures the quotas of instances and monitors their usage, so operators can alarm
+// before a quota (e.g. of authenticated requests) is exhausted and requests are limited.
+//
+// ZITADEL doesn't provide the usage by its API, instead it calls a URL as soon as a configured percentage
+// of a quota is used. The [Monitor] configures the quotas with notifications to itself for the thresholds
+// registered with [Monitor.Notify] and must be served on the call URL:
+//
+//	monitor := quota.New(systemClient, "https://ops.example.com/zitadel/quota?token=secret", quota.WithToken("secret"))
+//	monitor.Notify(80, func(ctx context.Context, usage quota.Usage) {
+//		alert("instance %s used %d%% of its quota", usage.InstanceID, usage.Threshold)
+//	})
+//	err := monitor.SetQuota(ctx, instanceID, quota.Quota{Unit: quota.UnitAuthenticatedRequests, Amount: 1_000_000, ResetInterval: 30 * 24 * time.Hour, Limit: true})
+//	http.Handle("/zitadel/quota", monitor)
+package quota
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/quota"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/system"
+)
+
+// instanceParam is the query parameter of the call URL identifying the instance of a notification.
+const instanceParam = "instance"
+
+var (
+	ErrUnknownUnit      = errors.New("unknown quota unit")
+	ErrInvalidThreshold = errors.New("threshold must be between 1 and 100")
+)
+
+// Unit is the resource limited by a [Quota].
+type Unit string
+
+const (
+	// UnitAuthenticatedRequests counts the authenticated requests to the APIs of an instance.
+	UnitAuthenticatedRequests Unit = "requests.all.authenticated"
+	// UnitActionsRunSeconds counts the seconds actions of an instance ran.
+	UnitActionsRunSeconds Unit = "actions.all.runs.seconds"
+)
+
+// Quota is the amount of a unit an instance may use in a period.
+type Quota struct {
+	Unit Unit
+	// From is the start of the first period (default now).
+	From time.Time
+	// ResetInterval is the duration of a period, after which the usage is reset.
+	ResetInterval time.Duration
+	Amount        uint64
+	// Limit blocks further usage if the amount is exhausted, otherwise only notifications are sent.
+	Limit bool
+}
+
+// Usage is the usage of a quota as reported by ZITADEL on reaching a threshold.
+type Usage struct {
+	InstanceID string
+	Unit       Unit
+	// Threshold is the reached percentage of the amount of the quota.
+	Threshold uint32
+	// Used is the usage at the time of the notification.
+	Used uint64
+	// Amount is the amount of the quota, 0 if the quota wasn't set by the [Monitor].
+	Amount      uint64
+	PeriodStart time.Time
+	ReceivedAt  time.Time
+}
+
+// Remaining returns the remaining amount of the quota, 0 if it's unknown or exhausted.
+func (u Usage) Remaining() uint64 {
+	if u.Used >= u.Amount {
+		return 0
+	}
+	return u.Amount - u.Used
+}
+
+type notification struct {
+	threshold uint32
+	handle    func(ctx context.Context, usage Usage)
+}
+
+type usageKey struct {
+	instanceID string
+	unit       Unit
+}
+
+// Monitor configures quotas and receives their notifications, see the package documentation.
+type Monitor struct {
+	system  system.SystemServiceClient
+	client  *client.Client
+	callURL string
+	token   string
+	now     func() time.Time
+
+	mu            sync.Mutex
+	notifications []notification
+	quotas        map[usageKey]Quota
+	usage         map[usageKey]Usage
+}
+
+// Option allows customization of the [Monitor].
+type Option func(*Monitor)
+
+// WithToken requires notifications to contain the token as query parameter `token` of the call URL,
+// since ZITADEL doesn't authenticate its calls.
+func WithToken(token string) Option {
+	return func(m *Monitor) {
+		m.token = token
+	}
+}
+
+// New creates a [Monitor] using the client, which must be authorized for the system API.
+// The call URL is the URL ZITADEL calls for notifications, the [Monitor] must be served on it.
+func New(client *client.Client, callURL string, options ...Option) *Monitor {
+	m := &Monitor{
+		client:  client,
+		callURL: callURL,
+		now:     time.Now,
+		quotas:  make(map[usageKey]Quota),
+		usage:   make(map[usageKey]Usage),
+	}
+	for _, option := range options {
+		option(m)
+	}
+	return m
+}
+
+func (m *Monitor) systemService() system.SystemServiceClient {
+	if m.system != nil {
+		return m.system
+	}
+	return m.client.SystemService()
+}
+
+// Notify calls the function every time the usage of a quota reaches the percentage (1-100) of its amount.
+// The thresholds must be registered before the quotas are set with [Monitor.SetQuota].
+func (m *Monitor) Notify(threshold uint32, handle func(ctx context.Context, usage Usage)) error {
+	if threshold == 0 || threshold > 100 {
+		return fmt.Errorf("%w: %d", ErrInvalidThreshold, threshold)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.notifications = append(m.notifications, notification{threshold: threshold, handle: handle})
+	return nil
+}
+
+// SetQuota creates or replaces the quota of the unit of the instance,
+// including notifications for the thresholds registered with [Monitor.Notify].
+func (m *Monitor) SetQuota(ctx context.Context, instanceID string, q Quota) error {
+	unit, err := unitToProto(q.Unit)
+	if err != nil {
+		return err
+	}
+	callURL, err := m.instanceCallURL(instanceID)
+	if err != nil {
+		return err
+	}
+	from := q.From
+	if from.IsZero() {
+		from = m.now()
+	}
+	_, err = m.systemService().SetQuota(ctx, &system.SetQuotaRequest{
+		InstanceId:    instanceID,
+		Unit:          unit,
+		From:          timestamppb.New(from),
+		ResetInterval: durationpb.New(q.ResetInterval),
+		Amount:        q.Amount,
+		Limit:         q.Limit,
+		Notifications: m.quotaNotifications(callURL),
+	})
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	m.quotas[usageKey{instanceID: instanceID, unit: q.Unit}] = q
+	m.mu.Unlock()
+	return nil
+}
+
+// Quota returns the quota of the unit of the instance set by the [Monitor] and if it was set.
+func (m *Monitor) Quota(instanceID string, unit Unit) (Quota, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	q, ok := m.quotas[usageKey{instanceID: instanceID, unit: unit}]
+	return q, ok
+}
+
+// RemoveQuota removes the quota of the unit of the instance.
+func (m *Monitor) RemoveQuota(ctx context.Context, instanceID string, unit Unit) error {
+	u, err := unitToProto(unit)
+	if err != nil {
+		return err
+	}
+	_, err = m.systemService().RemoveQuota(ctx, &system.RemoveQuotaRequest{InstanceId: instanceID, Unit: u})
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := usageKey{instanceID: instanceID, unit: unit}
+	delete(m.quotas, key)
+	delete(m.usage, key)
+	return nil
+}
+
+// Usage returns the last reported usage of the unit of the instance and if any was reported.
+func (m *Monitor) Usage(instanceID string, unit Unit) (Usage, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	usage, ok := m.usage[usageKey{instanceID: instanceID, unit: unit}]
+	return usage, ok
+}
+
+func (m *Monitor) quotaNotifications(callURL string) []*quota.Notification {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	thresholds := make([]uint32, 0, len(m.notifications))
+	for _, n := range m.notifications {
+		if !slices.Contains(thresholds, n.threshold) {
+			thresholds = append(thresholds, n.threshold)
+		}
+	}
+	slices.Sort(thresholds)
+	notifications := make([]*quota.Notification, len(thresholds))
+	for i, threshold := range thresholds {
+		notifications[i] = &quota.Notification{Percent: threshold, Repeat: true, CallUrl: callURL}
+	}
+	return notifications
+}
+
+func (m *Monitor) instanceCallURL(instanceID string) (string, error) {
+	u, err := url.Parse(m.callURL)
+	if err != nil {
+		return "", err
+	}
+	query := u.Query()
+	query.Set(instanceParam, instanceID)
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// notificationDue is the body of the notifications of ZITADEL.
+type notificationDue struct {
+	Unit        quota.Unit `json:"unit"`
+	ID          string     `json:"id"`
+	PeriodStart time.Time  `json:"periodStart"`
+	Threshold   uint32     `json:"threshold"`
+	Usage       uint64     `json:"usage"`
+}
+
+// ServeHTTP implements [http.Handler] and receives the notifications of ZITADEL.
+func (m *Monitor) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	if m.token != "" && subtle.ConstantTimeCompare([]byte(r.URL.Query().Get("token")), []byte(m.token)) != 1 {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+	var due notificationDue
+	if err := json.NewDecoder(r.Body).Decode(&due); err != nil {
+		http.Error(w, "invalid notification", http.StatusBadRequest)
+		return
+	}
+	unit, err := unitFromProto(due.Unit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	usage := Usage{
+		InstanceID:  r.URL.Query().Get(instanceParam),
+		Unit:        unit,
+		Threshold:   due.Threshold,
+		Used:        due.Usage,
+		PeriodStart: due.PeriodStart,
+		ReceivedAt:  m.now(),
+	}
+	usage, handlers := m.record(usage)
+	for _, handle := range handlers {
+		handle(r.Context(), usage)
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// record stores the usage unless a later one is known and returns it with the functions to notify.
+func (m *Monitor) record(usage Usage) (Usage, []func(ctx context.Context, usage Usage)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := usageKey{instanceID: usage.InstanceID, unit: usage.Unit}
+	if q, ok := m.quotas[key]; ok {
+		usage.Amount = q.Amount
+	}
+	current, ok := m.usage[key]
+	switch {
+	case !ok, usage.PeriodStart.After(current.PeriodStart),
+		usage.PeriodStart.Equal(current.PeriodStart) && usage.Used >= current.Used:
+		m.usage[key] = usage
+	}
+	var handlers []func(ctx context.Context, usage Usage)
+	for _, n := range m.notifications {
+		if n.threshold == usage.Threshold {
+			handlers = append(handlers, n.handle)
+		}
+	}
+	return usage, handlers
+}
+
+func unitToProto(unit Unit) (quota.Unit, error) {
+	switch unit {
+	case UnitAuthenticatedRequests:
+		return quota.Unit_UNIT_REQUESTS_ALL_AUTHENTICATED, nil
+	case UnitActionsRunSeconds:
+		return quota.Unit_UNIT_ACTIONS_ALL_RUN_SECONDS, nil
+	default:
+		return quota.Unit_UNIT_UNIMPLEMENTED, fmt.Errorf("%w: %q", ErrUnknownUnit, unit)
+	}
+}
+
+func unitFromProto(unit quota.Unit) (Unit, error) {
+	switch unit {
+	case quota.Unit_UNIT_REQUESTS_ALL_AUTHENTICATED:
+		return UnitAuthenticatedRequests, nil
+	case quota.Unit_UNIT_ACTIONS_ALL_RUN_SECONDS:
+		return UnitActionsRunSeconds, nil
+	default:
+		return "", fmt.Errorf("%w: %d", ErrUnknownUnit, unit)
+	}
+}
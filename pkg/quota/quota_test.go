@@ -0,0 +1,119 @@
+package quota
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/admin"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/milestone"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/quota"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/system"
+)
+
+type fakeSystemService struct {
+	system.SystemServiceClient
+	set *system.SetQuotaRequest
+}
+
+func (f *fakeSystemService) SetQuota(_ context.Context, req *system.SetQuotaRequest, _ ...grpc.CallOption) (*system.SetQuotaResponse, error) {
+	f.set = req
+	return &system.SetQuotaResponse{}, nil
+}
+
+func TestMonitor(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	service := &fakeSystemService{}
+	m := New(nil, "https://ops.example.com/quota?token=secret", WithToken("secret"))
+	m.system, m.now = service, func() time.Time { return now }
+
+	var notified []Usage
+	handle := func(_ context.Context, usage Usage) { notified = append(notified, usage) }
+	require.NoError(t, m.Notify(90, handle))
+	require.NoError(t, m.Notify(75, handle))
+	require.NoError(t, m.Notify(90, handle))
+	assert.ErrorIs(t, m.Notify(0, handle), ErrInvalidThreshold)
+	assert.ErrorIs(t, m.Notify(101, handle), ErrInvalidThreshold)
+
+	require.NoError(t, m.SetQuota(context.Background(), "instance1", Quota{
+		Unit:          UnitAuthenticatedRequests,
+		ResetInterval: time.Hour,
+		Amount:        1000,
+		Limit:         true,
+	}))
+	callURL := "https://ops.example.com/quota?instance=instance1&token=secret"
+	assert.True(t, proto.Equal(&system.SetQuotaRequest{
+		InstanceId:    "instance1",
+		Unit:          quota.Unit_UNIT_REQUESTS_ALL_AUTHENTICATED,
+		From:          timestamppb.New(now),
+		ResetInterval: durationpb.New(time.Hour),
+		Amount:        1000,
+		Limit:         true,
+		Notifications: []*quota.Notification{
+			{Percent: 75, Repeat: true, CallUrl: callURL},
+			{Percent: 90, Repeat: true, CallUrl: callURL},
+		},
+	}, service.set), "got %v", service.set)
+	assert.ErrorIs(t, m.SetQuota(context.Background(), "instance1", Quota{Unit: "unknown"}), ErrUnknownUnit)
+
+	send := func(target, body string) int {
+		w := httptest.NewRecorder()
+		m.ServeHTTP(w, httptest.NewRequest(http.MethodPost, target, strings.NewReader(body)))
+		return w.Code
+	}
+	assert.Equal(t, http.StatusUnauthorized, send("/quota?instance=instance1", `{}`))
+	assert.Equal(t, http.StatusBadRequest, send("/quota?instance=instance1&token=secret", `{"unit":0}`))
+	assert.Equal(t, http.StatusOK, send("/quota?instance=instance1&token=secret",
+		`{"unit":1,"id":"q1","callURL":"https://ops.example.com/quota","periodStart":"2024-06-01T00:00:00Z","threshold":90,"usage":905}`))
+
+	require.Len(t, notified, 2)
+	assert.Equal(t, Usage{
+		InstanceID:  "instance1",
+		Unit:        UnitAuthenticatedRequests,
+		Threshold:   90,
+		Used:        905,
+		Amount:      1000,
+		PeriodStart: now,
+		ReceivedAt:  now,
+	}, notified[0])
+	usage, ok := m.Usage("instance1", UnitAuthenticatedRequests)
+	require.True(t, ok)
+	assert.Equal(t, uint64(95), usage.Remaining())
+
+	// a delayed notification of a lower usage doesn't replace the current one
+	send("/quota?instance=instance1&token=secret", `{"unit":1,"periodStart":"2024-06-01T00:00:00Z","threshold":75,"usage":750}`)
+	assert.Len(t, notified, 3)
+	usage, _ = m.Usage("instance1", UnitAuthenticatedRequests)
+	assert.Equal(t, uint64(905), usage.Used)
+}
+
+type fakeAdminService struct {
+	admin.AdminServiceClient
+}
+
+func (fakeAdminService) ListMilestones(context.Context, *admin.ListMilestonesRequest, ...grpc.CallOption) (*admin.ListMilestonesResponse, error) {
+	return &admin.ListMilestonesResponse{Result: []*milestone.Milestone{
+		{Type: milestone.MilestoneType_MILESTONE_TYPE_INSTANCE_CREATED, ReachedDate: timestamppb.New(time.Unix(1, 0))},
+		{Type: milestone.MilestoneType_MILESTONE_TYPE_AUTHENTICATION_SUCCEEDED_ON_INSTANCE},
+	}}, nil
+}
+
+func Test_listMilestones(t *testing.T) {
+	milestones, err := listMilestones(context.Background(), fakeAdminService{})
+	require.NoError(t, err)
+	require.Len(t, milestones, 2)
+	assert.Equal(t, MilestoneInstanceCreated, milestones[0].Type)
+	assert.True(t, milestones[0].Reached())
+	assert.Equal(t, MilestoneAuthenticationSucceededOnInstance, milestones[1].Type)
+	assert.False(t, milestones[1].Reached())
+}
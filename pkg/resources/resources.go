@@ -0,0 +1,157 @@
+// Package resources provides the HTTP/JSON client of the resource API of ZITADEL (v3alpha),
+// which the clients of its services (e.g. [userschema] or [actions]) are built on.
+//
+// The client is created from an existing [client.Client] (see [NewFromClient]) or authenticates using
+// a [client.TokenSourceInitializer] (see [New]) and returns errors as gRPC status errors, so status.Code(err) works as with the other services.
+package resources
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"golang.org/x/oauth2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client"
+	"github.com/zitadel/zitadel-go/v3/pkg/zitadel"
+)
+
+const (
+	// BasePath of the resource API.
+	BasePath = "/resources/v3alpha"
+
+	maxErrorBody = 1 << 16
+)
+
+// Client calls the resource API of ZITADEL.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// Option allows customization of the [Client].
+type Option func(*options)
+
+type options struct {
+	httpClient *http.Client
+	basePath   string
+}
+
+// WithHTTPClient sets the [http.Client] used for the token and resource requests,
+// e.g. with a custom transport or timeout.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(o *options) {
+		o.httpClient = httpClient
+	}
+}
+
+// WithBasePath sets the path the paths of [Client.Do] are relative to (default [BasePath]),
+// e.g. to call other HTTP/JSON APIs of ZITADEL such as the feature service.
+func WithBasePath(basePath string) Option {
+	return func(o *options) {
+		o.basePath = basePath
+	}
+}
+
+// New creates a [Client] for the resource API of the instance.
+func New(ctx context.Context, zitadel *zitadel.Zitadel, auth client.TokenSourceInitializer, opts ...Option) (*Client, error) {
+	o := &options{httpClient: http.DefaultClient, basePath: BasePath}
+	for _, opt := range opts {
+		opt(o)
+	}
+	authCtx := context.WithValue(ctx, oauth2.HTTPClient, o.httpClient)
+	source, err := auth(authCtx, zitadel.Origin())
+	if err != nil {
+		return nil, err
+	}
+	return &Client{
+		baseURL:    zitadel.Origin() + o.basePath,
+		httpClient: oauth2.NewClient(authCtx, source),
+	}, nil
+}
+
+// NewFromClient creates a [Client] for the resource API using the credentials and connection settings
+// of the [client.Client] (see [client.Client.HTTPClient]), so no additional authentication is needed.
+// The [WithHTTPClient] option is ignored.
+func NewFromClient(c *client.Client, opts ...Option) *Client {
+	o := &options{basePath: BasePath}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return &Client{
+		baseURL:    c.Origin() + o.basePath,
+		httpClient: c.HTTPClient(),
+	}
+}
+
+// Do sends the body (if not nil) as JSON to the path (relative to the base path, see [WithBasePath]) and decodes the response into the result
+// (if not nil). Error responses are returned as gRPC status errors.
+func (c *Client) Do(ctx context.Context, method, path string, body, result any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		return ResponseError(resp)
+	}
+	if result == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+// ResponseError converts the error response of the gateway (a JSON encoded status) into a status error.
+// It's also used for the other HTTP APIs of ZITADEL, e.g. the asset API.
+func ResponseError(resp *http.Response) error {
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	var body struct {
+		Code    codes.Code `json:"code"`
+		Message string     `json:"message"`
+	}
+	if err != nil || json.Unmarshal(data, &body) != nil || body.Code == codes.OK {
+		return status.Errorf(httpCode(resp.StatusCode), "%s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+	return status.Error(body.Code, body.Message)
+}
+
+func httpCode(statusCode int) codes.Code {
+	switch statusCode {
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.AlreadyExists
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusServiceUnavailable:
+		return codes.Unavailable
+	}
+	return codes.Unknown
+}
@@ -0,0 +1,50 @@
+// Package resourcestest serves the HTTP/JSON APIs of ZITADEL (e.g. the resource API, see [resources]) from a handler
+// in tests of their clients:
+//
+//	c := actions.New(resourcestest.NewClient(t, func(w http.ResponseWriter, r *http.Request) {
+//		assert.Equal(t, "Bearer "+resourcestest.Token, r.Header.Get("Authorization"))
+//		w.Write([]byte(`{}`))
+//	}))
+package resourcestest
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client"
+	"github.com/zitadel/zitadel-go/v3/pkg/zitadel"
+)
+
+// Token is the personal access token the clients of [NewClient] authenticate with.
+const Token = "token"
+
+// NewInstance starts an [httptest.Server] serving the handler, which is closed when the test finishes,
+// and returns the (insecure) instance of it.
+func NewInstance(t testing.TB, handler http.HandlerFunc) *zitadel.Zitadel {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	host, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return zitadel.New(host, zitadel.WithInsecure(port))
+}
+
+// NewClient returns a [client.Client] of the instance serving the handler (see [NewInstance]),
+// authenticated with the [Token]. It uses [client.TransportREST], so all requests are plain HTTP/JSON.
+func NewClient(t testing.TB, handler http.HandlerFunc) *client.Client {
+	t.Helper()
+	c, err := client.New(context.Background(), NewInstance(t, handler),
+		client.WithAuth(client.PAT(Token)),
+		client.WithTransport(client.TransportREST),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
@@ -0,0 +1,23 @@
+module github.com/zitadel/zitadel-go/pkg/saml/v3
+
+go 1.23.7
+
+require (
+	github.com/crewjam/saml v0.5.1
+	github.com/russellhaering/goxmldsig v1.4.0
+	github.com/stretchr/testify v1.10.0
+	github.com/zitadel/zitadel-go/v3 v3.0.0
+)
+
+require (
+	github.com/beevik/etree v1.5.0 // indirect
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/golang-jwt/jwt/v4 v4.5.2 // indirect
+	github.com/jonboulle/clockwork v0.2.2 // indirect
+	github.com/mattermost/xml-roundtrip-validator v0.1.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/crypto v0.35.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/zitadel/zitadel-go/v3 => ../..
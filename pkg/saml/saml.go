@@ -0,0 +1,232 @@
+// Package saml implements a SAML 2.0 service provider (SP) for ZITADEL as identity provider (IdP).
+// It generates the SP metadata to be registered as SAML application in ZITADEL, builds the AuthnRequests
+// and validates the signed responses and assertions against the certificate of the ZITADEL IdP.
+// It's a separate module, so only applications using SAML depend on github.com/crewjam/saml.
+package saml
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/x509"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+
+	"github.com/crewjam/saml"
+	"github.com/crewjam/saml/samlsp"
+	dsig "github.com/russellhaering/goxmldsig"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/zitadel"
+)
+
+// MetadataPath is the path of the IdP metadata of ZITADEL.
+const MetadataPath = "/saml/v2/metadata"
+
+var (
+	ErrInvalidResponse = errors.New("invalid SAML response")
+	ErrMissingKeyPair  = errors.New("signing AuthnRequests requires a key pair")
+)
+
+// ServiceProvider is a SAML service provider using ZITADEL as identity provider.
+type ServiceProvider struct {
+	sp           *saml.ServiceProvider
+	httpClient   *http.Client
+	idpMetadata  *saml.EntityDescriptor
+	signRequests bool
+	logger       *slog.Logger
+}
+
+// Option allows customization of the [ServiceProvider].
+type Option func(*ServiceProvider)
+
+// WithKeyPair sets the key and certificate of the service provider, which are published in the metadata.
+// They are required to sign the AuthnRequests (see [WithSignedRequests]) and to decrypt encrypted assertions.
+func WithKeyPair(key crypto.Signer, certificate *x509.Certificate) Option {
+	return func(s *ServiceProvider) {
+		s.sp.Key = key
+		s.sp.Certificate = certificate
+	}
+}
+
+// WithSignedRequests signs the AuthnRequests, requires [WithKeyPair].
+func WithSignedRequests() Option {
+	return func(s *ServiceProvider) {
+		s.signRequests = true
+	}
+}
+
+// WithIDPMetadata uses the provided metadata of the ZITADEL IdP instead of fetching it,
+// e.g. parsed with [ParseMetadata] from a file.
+func WithIDPMetadata(metadata *saml.EntityDescriptor) Option {
+	return func(s *ServiceProvider) {
+		s.idpMetadata = metadata
+	}
+}
+
+// WithHTTPClient sets the client used to fetch the IdP metadata (default [http.DefaultClient]).
+func WithHTTPClient(client *http.Client) Option {
+	return func(s *ServiceProvider) {
+		s.httpClient = client
+	}
+}
+
+// WithLogger sets the logger of the reasons of invalid responses (default [slog.Default]).
+func WithLogger(logger *slog.Logger) Option {
+	return func(s *ServiceProvider) {
+		s.logger = logger
+	}
+}
+
+// WithMetadataURL sets the url the metadata of the service provider is served on.
+// By default, the entityID is used.
+func WithMetadataURL(metadataURL *url.URL) Option {
+	return func(s *ServiceProvider) {
+		s.sp.MetadataURL = *metadataURL
+	}
+}
+
+// WithSingleLogoutURL sets the url of the single logout endpoint of the service provider.
+func WithSingleLogoutURL(sloURL *url.URL) Option {
+	return func(s *ServiceProvider) {
+		s.sp.SloURL = *sloURL
+	}
+}
+
+// WithNameIDFormat sets the requested format of the NameID (default: unspecified).
+func WithNameIDFormat(format saml.NameIDFormat) Option {
+	return func(s *ServiceProvider) {
+		s.sp.AuthnNameIDFormat = format
+	}
+}
+
+// WithIDPInitiated allows responses not answering an AuthnRequest of the service provider (IdP-initiated login).
+func WithIDPInitiated() Option {
+	return func(s *ServiceProvider) {
+		s.sp.AllowIDPInitiated = true
+	}
+}
+
+// New creates a [ServiceProvider] with the entityID and the url of its assertion consumer service (ACS).
+// Unless provided by [WithIDPMetadata], the metadata of the ZITADEL IdP is fetched from the instance.
+func New(ctx context.Context, zitadel *zitadel.Zitadel, entityID string, acsURL *url.URL, options ...Option) (*ServiceProvider, error) {
+	metadataURL, err := url.Parse(entityID)
+	if err != nil {
+		return nil, err
+	}
+	s := &ServiceProvider{
+		sp: &saml.ServiceProvider{
+			EntityID:          entityID,
+			MetadataURL:       *metadataURL,
+			AcsURL:            *acsURL,
+			AuthnNameIDFormat: saml.UnspecifiedNameIDFormat,
+		},
+		httpClient: http.DefaultClient,
+		logger:     slog.Default(),
+	}
+	for _, option := range options {
+		option(s)
+	}
+	if s.signRequests {
+		if s.sp.Key == nil || s.sp.Certificate == nil {
+			return nil, ErrMissingKeyPair
+		}
+		s.sp.SignatureMethod = signatureMethod(s.sp.Key)
+	}
+	if s.idpMetadata == nil {
+		idpMetadataURL, err := url.Parse(zitadel.Origin() + MetadataPath)
+		if err != nil {
+			return nil, err
+		}
+		s.idpMetadata, err = samlsp.FetchMetadata(ctx, s.httpClient, *idpMetadataURL)
+		if err != nil {
+			return nil, fmt.Errorf("fetch IdP metadata: %w", err)
+		}
+	}
+	s.sp.IDPMetadata = s.idpMetadata
+	s.sp.HTTPClient = s.httpClient
+	return s, nil
+}
+
+// ParseMetadata parses the XML metadata of an IdP.
+func ParseMetadata(data []byte) (*saml.EntityDescriptor, error) {
+	return samlsp.ParseMetadata(data)
+}
+
+// Metadata returns the XML metadata of the service provider to be registered in ZITADEL.
+func (s *ServiceProvider) Metadata() ([]byte, error) {
+	return xml.MarshalIndent(s.sp.Metadata(), "", "  ")
+}
+
+// MetadataHandler serves the metadata of the service provider.
+func (s *ServiceProvider) MetadataHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		metadata, err := s.Metadata()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/samlmetadata+xml")
+		_, _ = w.Write(metadata)
+	})
+}
+
+// AuthnRequest builds an AuthnRequest using the HTTP-Redirect binding and returns the url to redirect the user to
+// and the ID of the request, which must be kept (e.g. in a cookie) to validate the response.
+func (s *ServiceProvider) AuthnRequest(relayState string) (redirectURL *url.URL, requestID string, err error) {
+	req, err := s.sp.MakeAuthenticationRequest(
+		s.sp.GetSSOBindingLocation(saml.HTTPRedirectBinding),
+		saml.HTTPRedirectBinding,
+		saml.HTTPPostBinding,
+	)
+	if err != nil {
+		return nil, "", err
+	}
+	redirectURL, err = req.Redirect(relayState, s.sp)
+	if err != nil {
+		return nil, "", err
+	}
+	return redirectURL, req.ID, nil
+}
+
+// ParseResponse validates the SAML response posted to the ACS
+// (signature, issuer, audience, validity and the ID of the AuthnRequest) and returns the authenticated [User].
+// If the validation fails, [ErrInvalidResponse] is returned and the reason is logged (see [WithLogger]),
+// so it can't be sent to the user by accident.
+func (s *ServiceProvider) ParseResponse(req *http.Request, requestIDs ...string) (*User, error) {
+	assertion, err := s.sp.ParseResponse(req, requestIDs)
+	if err != nil {
+		return nil, s.responseError(req.Context(), err)
+	}
+	return NewUser(assertion), nil
+}
+
+// ParseXMLResponse validates the decoded SAML response received on the currentURL, see [ServiceProvider.ParseResponse].
+func (s *ServiceProvider) ParseXMLResponse(response []byte, currentURL url.URL, requestIDs ...string) (*User, error) {
+	assertion, err := s.sp.ParseXMLResponse(response, requestIDs, currentURL)
+	if err != nil {
+		return nil, s.responseError(context.Background(), err)
+	}
+	return NewUser(assertion), nil
+}
+
+// responseError logs the reason of the failed validation, which is hidden in the [saml.InvalidResponseError],
+// and returns the generic [ErrInvalidResponse], as the reason may reveal details about the validation to an attacker.
+func (s *ServiceProvider) responseError(ctx context.Context, err error) error {
+	var invalid *saml.InvalidResponseError
+	if errors.As(err, &invalid) {
+		err = invalid.PrivateErr
+	}
+	s.logger.WarnContext(ctx, "invalid SAML response", "err", err)
+	return ErrInvalidResponse
+}
+
+func signatureMethod(key crypto.Signer) string {
+	if _, ok := key.Public().(*ecdsa.PublicKey); ok {
+		return dsig.ECDSASHA256SignatureMethod
+	}
+	return dsig.RSASHA256SignatureMethod
+}
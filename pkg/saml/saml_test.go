@@ -0,0 +1,115 @@
+package saml
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/crewjam/saml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/zitadel"
+)
+
+func TestServiceProvider_AuthnRequest(t *testing.T) {
+	idp := &saml.EntityDescriptor{
+		EntityID: "https://my-instance.zitadel.cloud/saml/v2/metadata",
+		IDPSSODescriptors: []saml.IDPSSODescriptor{{
+			SingleSignOnServices: []saml.Endpoint{{
+				Binding:  saml.HTTPRedirectBinding,
+				Location: "https://my-instance.zitadel.cloud/saml/v2/SSO",
+			}},
+		}},
+	}
+	acsURL, _ := url.Parse("https://app.example.com/saml/acs")
+	sp, err := New(context.Background(), zitadel.New("my-instance.zitadel.cloud"), "https://app.example.com/saml/metadata", acsURL, WithIDPMetadata(idp))
+	require.NoError(t, err)
+
+	metadata, err := sp.Metadata()
+	require.NoError(t, err)
+	assert.Contains(t, string(metadata), `entityID="https://app.example.com/saml/metadata"`)
+	assert.Contains(t, string(metadata), `Location="https://app.example.com/saml/acs"`)
+
+	redirectURL, requestID, err := sp.AuthnRequest("state")
+	require.NoError(t, err)
+	assert.NotEmpty(t, requestID)
+	assert.Equal(t, "my-instance.zitadel.cloud", redirectURL.Host)
+	assert.Equal(t, "/saml/v2/SSO", redirectURL.Path)
+	assert.Equal(t, "state", redirectURL.Query().Get("RelayState"))
+	assert.NotEmpty(t, redirectURL.Query().Get("SAMLRequest"))
+
+	_, err = New(context.Background(), zitadel.New("my-instance.zitadel.cloud"), "https://app.example.com/saml/metadata", acsURL, WithIDPMetadata(idp), WithSignedRequests())
+	assert.ErrorIs(t, err, ErrMissingKeyPair)
+}
+
+func TestServiceProvider_ParseResponse_invalid(t *testing.T) {
+	idp := &saml.EntityDescriptor{EntityID: "https://my-instance.zitadel.cloud/saml/v2/metadata"}
+	acsURL, _ := url.Parse("https://app.example.com/saml/acs")
+	logs := new(bytes.Buffer)
+	sp, err := New(context.Background(), zitadel.New("my-instance.zitadel.cloud"), "https://app.example.com/saml/metadata", acsURL,
+		WithIDPMetadata(idp),
+		WithLogger(slog.New(slog.NewTextHandler(logs, nil))),
+	)
+	require.NoError(t, err)
+	response := []byte(`<samlp:Response xmlns:samlp="urn:oasis:names:tc:SAML:2.0:protocol" ID="id1" Version="2.0" Destination="https://attacker.example.com/acs"></samlp:Response>`)
+
+	t.Run("posted", func(t *testing.T) {
+		logs.Reset()
+		form := url.Values{"SAMLResponse": {base64.StdEncoding.EncodeToString(response)}}
+		req := httptest.NewRequest(http.MethodPost, acsURL.String(), strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		_, err := sp.ParseResponse(req, "request1")
+		// only the generic error is returned, the reason is logged
+		assert.Equal(t, ErrInvalidResponse, err)
+		assert.Contains(t, logs.String(), `msg="invalid SAML response" err=`)
+	})
+	t.Run("xml", func(t *testing.T) {
+		logs.Reset()
+		_, err := sp.ParseXMLResponse(response, *acsURL, "request1")
+		assert.Equal(t, ErrInvalidResponse, err)
+		assert.Contains(t, logs.String(), "https://attacker.example.com/acs")
+	})
+}
+
+func TestNewUser(t *testing.T) {
+	sessionEnd := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	attribute := func(name string, values ...string) saml.Attribute {
+		a := saml.Attribute{Name: name}
+		for _, value := range values {
+			a.Values = append(a.Values, saml.AttributeValue{Value: value})
+		}
+		return a
+	}
+	user := NewUser(&saml.Assertion{
+		Subject: &saml.Subject{NameID: &saml.NameID{Value: "gigi@zitadel.com", Format: string(saml.EmailAddressNameIDFormat)}},
+		AuthnStatements: []saml.AuthnStatement{{
+			SessionIndex:        "session1",
+			SessionNotOnOrAfter: &sessionEnd,
+		}},
+		AttributeStatements: []saml.AttributeStatement{{
+			Attributes: []saml.Attribute{
+				attribute(AttributeUserID, "user1"),
+				attribute(AttributeEmail, "gigi@zitadel.com"),
+				attribute(AttributeFirstName, "Gigi"),
+				attribute(AttributeSurName, "Giraffe"),
+				attribute("groups", "admins", "developers"),
+			},
+		}},
+	})
+	assert.Equal(t, "user1", user.ID)
+	assert.Equal(t, "gigi@zitadel.com", user.NameID)
+	assert.Equal(t, "gigi@zitadel.com", user.Email)
+	assert.Equal(t, "Gigi", user.FirstName)
+	assert.Equal(t, "Giraffe", user.LastName)
+	assert.Equal(t, "session1", user.SessionIndex)
+	assert.Equal(t, sessionEnd, user.SessionNotOnOrAfter)
+	assert.Equal(t, []string{"admins", "developers"}, user.Attributes["groups"])
+}
@@ -0,0 +1,77 @@
+package saml
+
+import (
+	"time"
+
+	"github.com/crewjam/saml"
+)
+
+// Names of the attributes of the assertions issued by ZITADEL.
+const (
+	AttributeUserID    = "UserID"
+	AttributeUserName  = "UserName"
+	AttributeEmail     = "Email"
+	AttributeFirstName = "FirstName"
+	AttributeSurName   = "SurName"
+	AttributeFullName  = "FullName"
+)
+
+// User is the authenticated user of a validated assertion.
+type User struct {
+	ID        string
+	UserName  string
+	Email     string
+	FirstName string
+	LastName  string
+	FullName  string
+	// NameID is the subject of the assertion.
+	NameID       string
+	NameIDFormat string
+	// SessionIndex is required for a single logout.
+	SessionIndex        string
+	SessionNotOnOrAfter time.Time
+	// Attributes contains all attributes of the assertion, including custom ones (e.g. set by actions).
+	Attributes map[string][]string
+}
+
+// NewUser maps the assertion to a [User].
+func NewUser(assertion *saml.Assertion) *User {
+	user := &User{
+		Attributes: make(map[string][]string),
+	}
+	if subject := assertion.Subject; subject != nil && subject.NameID != nil {
+		user.NameID = subject.NameID.Value
+		user.NameIDFormat = subject.NameID.Format
+	}
+	for _, statement := range assertion.AuthnStatements {
+		user.SessionIndex = statement.SessionIndex
+		if statement.SessionNotOnOrAfter != nil {
+			user.SessionNotOnOrAfter = *statement.SessionNotOnOrAfter
+		}
+	}
+	for _, statement := range assertion.AttributeStatements {
+		for _, attribute := range statement.Attributes {
+			for _, value := range attribute.Values {
+				user.Attributes[attribute.Name] = append(user.Attributes[attribute.Name], value.Value)
+			}
+		}
+	}
+	user.ID = user.Attribute(AttributeUserID)
+	if user.ID == "" {
+		user.ID = user.NameID
+	}
+	user.UserName = user.Attribute(AttributeUserName)
+	user.Email = user.Attribute(AttributeEmail)
+	user.FirstName = user.Attribute(AttributeFirstName)
+	user.LastName = user.Attribute(AttributeSurName)
+	user.FullName = user.Attribute(AttributeFullName)
+	return user
+}
+
+// Attribute returns the first value of the attribute.
+func (u *User) Attribute(name string) string {
+	if values := u.Attributes[name]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
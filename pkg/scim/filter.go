@@ -0,0 +1,89 @@
+package scim
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Filter is a SCIM filter expression (RFC 7644, section 3.4.2.2), built with the functions of this package,
+// e.g. `And(Eq("userName", "alice"), Pr("emails"))`.
+type Filter string
+
+// Eq matches if the attribute equals the value.
+func Eq(attribute string, value any) Filter {
+	return compare(attribute, "eq", value)
+}
+
+// Ne matches if the attribute doesn't equal the value.
+func Ne(attribute string, value any) Filter {
+	return compare(attribute, "ne", value)
+}
+
+// Co matches if the attribute contains the value.
+func Co(attribute, value string) Filter {
+	return compare(attribute, "co", value)
+}
+
+// Sw matches if the attribute starts with the value.
+func Sw(attribute, value string) Filter {
+	return compare(attribute, "sw", value)
+}
+
+// Ew matches if the attribute ends with the value.
+func Ew(attribute, value string) Filter {
+	return compare(attribute, "ew", value)
+}
+
+// Gt matches if the attribute is greater than the value.
+func Gt(attribute string, value any) Filter {
+	return compare(attribute, "gt", value)
+}
+
+// Lt matches if the attribute is less than the value.
+func Lt(attribute string, value any) Filter {
+	return compare(attribute, "lt", value)
+}
+
+// Pr matches if the attribute has a value.
+func Pr(attribute string) Filter {
+	return Filter(attribute + " pr")
+}
+
+// And matches if all filters match.
+func And(filters ...Filter) Filter {
+	return join("and", filters)
+}
+
+// Or matches if any of the filters match.
+func Or(filters ...Filter) Filter {
+	return join("or", filters)
+}
+
+// Not matches if the filter doesn't match.
+func Not(filter Filter) Filter {
+	return Filter("not (" + filter + ")")
+}
+
+func compare(attribute, operator string, value any) Filter {
+	// values are encoded as JSON, which quotes and escapes strings as required by the filter syntax
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		encoded = []byte(fmt.Sprintf("%q", fmt.Sprint(value)))
+	}
+	return Filter(attribute + " " + operator + " " + string(encoded))
+}
+
+func join(operator string, filters []Filter) Filter {
+	switch len(filters) {
+	case 0:
+		return ""
+	case 1:
+		return filters[0]
+	}
+	parts := make([]string, len(filters))
+	for i, filter := range filters {
+		parts[i] = "(" + string(filter) + ")"
+	}
+	return Filter(strings.Join(parts, " "+operator+" "))
+}
@@ -0,0 +1,25 @@
+package scim
+
+// PatchOperation is an operation of a PATCH request (RFC 7644, section 3.5.2).
+type PatchOperation struct {
+	Op string `json:"op"`
+	// Path is the attribute path, e.g. `name.givenName` or `members[value eq "123"]`,
+	// if empty the value must be an object containing the attributes to modify.
+	Path  string `json:"path,omitempty"`
+	Value any    `json:"value,omitempty"`
+}
+
+// Add adds the value to the attribute, resp. appends it to multi-valued attributes.
+func Add(path string, value any) PatchOperation {
+	return PatchOperation{Op: "add", Path: path, Value: value}
+}
+
+// Replace replaces the value of the attribute.
+func Replace(path string, value any) PatchOperation {
+	return PatchOperation{Op: "replace", Path: path, Value: value}
+}
+
+// Remove removes the attribute, resp. the matching values of multi-valued attributes.
+func Remove(path string) PatchOperation {
+	return PatchOperation{Op: "remove", Path: path}
+}
@@ -0,0 +1,151 @@
+package scim
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	resourceUsers  = "Users"
+	resourceGroups = "Groups"
+)
+
+// User is the SCIM user resource (RFC 7643, section 4.1).
+type User struct {
+	Schemas           []string      `json:"schemas"`
+	ID                string        `json:"id,omitempty"`
+	ExternalID        string        `json:"externalId,omitempty"`
+	UserName          string        `json:"userName"`
+	Name              *Name         `json:"name,omitempty"`
+	DisplayName       string        `json:"displayName,omitempty"`
+	NickName          string        `json:"nickName,omitempty"`
+	ProfileURL        string        `json:"profileUrl,omitempty"`
+	Title             string        `json:"title,omitempty"`
+	UserType          string        `json:"userType,omitempty"`
+	PreferredLanguage string        `json:"preferredLanguage,omitempty"`
+	Locale            string        `json:"locale,omitempty"`
+	Timezone          string        `json:"timezone,omitempty"`
+	Active            *bool         `json:"active,omitempty"`
+	Password          string        `json:"password,omitempty"`
+	Emails            []MultiValued `json:"emails,omitempty"`
+	PhoneNumbers      []MultiValued `json:"phoneNumbers,omitempty"`
+	Meta              *Meta         `json:"meta,omitempty"`
+}
+
+type Name struct {
+	Formatted       string `json:"formatted,omitempty"`
+	FamilyName      string `json:"familyName,omitempty"`
+	GivenName       string `json:"givenName,omitempty"`
+	MiddleName      string `json:"middleName,omitempty"`
+	HonorificPrefix string `json:"honorificPrefix,omitempty"`
+	HonorificSuffix string `json:"honorificSuffix,omitempty"`
+}
+
+// MultiValued is an entry of a multi-valued attribute, e.g. an email address.
+type MultiValued struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+// Meta is the resource metadata set by the service provider.
+type Meta struct {
+	ResourceType string    `json:"resourceType,omitempty"`
+	Created      time.Time `json:"created,omitempty"`
+	LastModified time.Time `json:"lastModified,omitempty"`
+	Version      string    `json:"version,omitempty"`
+	Location     string    `json:"location,omitempty"`
+}
+
+// Group is the SCIM group resource (RFC 7643, section 4.2).
+type Group struct {
+	Schemas     []string `json:"schemas"`
+	ID          string   `json:"id,omitempty"`
+	ExternalID  string   `json:"externalId,omitempty"`
+	DisplayName string   `json:"displayName"`
+	Members     []Member `json:"members,omitempty"`
+	Meta        *Meta    `json:"meta,omitempty"`
+}
+
+// Member is a user or group of a [Group].
+type Member struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+	Ref     string `json:"$ref,omitempty"`
+	Type    string `json:"type,omitempty"`
+}
+
+// CreateUser creates the user, the schema is set if missing.
+func (c *Client) CreateUser(ctx context.Context, user *User) (*User, error) {
+	if len(user.Schemas) == 0 {
+		user.Schemas = []string{SchemaUser}
+	}
+	return create(ctx, c, resourceUsers, user)
+}
+
+// GetUser returns the user by its ID, use [IsNotFound] to check if it doesn't exist.
+func (c *Client) GetUser(ctx context.Context, id string) (*User, error) {
+	return get[User](ctx, c, resourceUsers, id)
+}
+
+// ReplaceUser replaces all attributes of the user, attributes not provided are cleared.
+func (c *Client) ReplaceUser(ctx context.Context, id string, user *User) (*User, error) {
+	if len(user.Schemas) == 0 {
+		user.Schemas = []string{SchemaUser}
+	}
+	return replace(ctx, c, resourceUsers, id, user)
+}
+
+// PatchUser modifies the user with the operations.
+// The returned user is nil if the endpoint doesn't return the modified resource (204 No Content).
+func (c *Client) PatchUser(ctx context.Context, id string, operations ...PatchOperation) (*User, error) {
+	return patch[User](ctx, c, resourceUsers, id, operations)
+}
+
+// DeleteUser deletes the user.
+func (c *Client) DeleteUser(ctx context.Context, id string) error {
+	return c.delete(ctx, resourceUsers, id)
+}
+
+// ListUsers returns a page of users, optionally filtered, e.g. by `Eq("userName", "alice")`.
+func (c *Client) ListUsers(ctx context.Context, opts *ListOptions) (*ListResponse[User], error) {
+	return list[User](ctx, c, resourceUsers, opts)
+}
+
+// CreateGroup creates the group, the schema is set if missing.
+func (c *Client) CreateGroup(ctx context.Context, group *Group) (*Group, error) {
+	if len(group.Schemas) == 0 {
+		group.Schemas = []string{SchemaGroup}
+	}
+	return create(ctx, c, resourceGroups, group)
+}
+
+// GetGroup returns the group by its ID, use [IsNotFound] to check if it doesn't exist.
+func (c *Client) GetGroup(ctx context.Context, id string) (*Group, error) {
+	return get[Group](ctx, c, resourceGroups, id)
+}
+
+// ReplaceGroup replaces all attributes of the group including its members.
+func (c *Client) ReplaceGroup(ctx context.Context, id string, group *Group) (*Group, error) {
+	if len(group.Schemas) == 0 {
+		group.Schemas = []string{SchemaGroup}
+	}
+	return replace(ctx, c, resourceGroups, id, group)
+}
+
+// PatchGroup modifies the group with the operations, e.g. `Add("members", []Member{{Value: userID}})`.
+// The returned group is nil if the endpoint doesn't return the modified resource (204 No Content).
+func (c *Client) PatchGroup(ctx context.Context, id string, operations ...PatchOperation) (*Group, error) {
+	return patch[Group](ctx, c, resourceGroups, id, operations)
+}
+
+// DeleteGroup deletes the group.
+func (c *Client) DeleteGroup(ctx context.Context, id string) error {
+	return c.delete(ctx, resourceGroups, id)
+}
+
+// ListGroups returns a page of groups, optionally filtered, e.g. by `Eq("displayName", "admins")`.
+func (c *Client) ListGroups(ctx context.Context, opts *ListOptions) (*ListResponse[Group], error) {
+	return list[Group](ctx, c, resourceGroups, opts)
+}
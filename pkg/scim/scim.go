@@ -0,0 +1,240 @@
+// Package scim implements a client for the SCIM 2.0 interface (RFC 7643, RFC 7644) of ZITADEL,
+// which is served per organization at `/scim/v2/{orgID}`.
+//
+// The client authenticates using the same [client.TokenSourceInitializer] as the gRPC [client.Client],
+// e.g. [client.JWTAuthentication] or [client.PAT] of a service user with the permissions to manage the users.
+package scim
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"golang.org/x/oauth2"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client"
+	"github.com/zitadel/zitadel-go/v3/pkg/zitadel"
+)
+
+const (
+	ContentType = "application/scim+json"
+
+	SchemaUser         = "urn:ietf:params:scim:schemas:core:2.0:User"
+	SchemaGroup        = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	SchemaListResponse = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	SchemaPatchOp      = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	SchemaError        = "urn:ietf:params:scim:api:messages:2.0:Error"
+
+	maxErrorBody = 1 << 16
+)
+
+// Client calls the SCIM endpoint of an organization.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// Option allows customization of the [Client].
+type Option func(*options)
+
+type options struct {
+	httpClient *http.Client
+}
+
+// WithHTTPClient sets the [http.Client] used for the token and SCIM requests,
+// e.g. with a custom transport or timeout.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(o *options) {
+		o.httpClient = httpClient
+	}
+}
+
+// New creates a [Client] for the SCIM endpoint of the organization.
+func New(ctx context.Context, zitadel *zitadel.Zitadel, organizationID string, auth client.TokenSourceInitializer, opts ...Option) (*Client, error) {
+	o := &options{httpClient: http.DefaultClient}
+	for _, opt := range opts {
+		opt(o)
+	}
+	authCtx := context.WithValue(ctx, oauth2.HTTPClient, o.httpClient)
+	source, err := auth(authCtx, zitadel.Origin())
+	if err != nil {
+		return nil, err
+	}
+	return &Client{
+		baseURL:    zitadel.Origin() + "/scim/v2/" + url.PathEscape(organizationID),
+		httpClient: oauth2.NewClient(authCtx, source),
+	}, nil
+}
+
+// Error is the error response of the SCIM endpoint (RFC 7644, section 3.12).
+type Error struct {
+	// StatusCode is the HTTP status of the response.
+	StatusCode int    `json:"-"`
+	Status     string `json:"status,omitempty"`
+	ScimType   string `json:"scimType,omitempty"`
+	Detail     string `json:"detail,omitempty"`
+}
+
+func (e *Error) Error() string {
+	if e.ScimType != "" {
+		return fmt.Sprintf("scim: %d %s: %s", e.StatusCode, e.ScimType, e.Detail)
+	}
+	return fmt.Sprintf("scim: %d: %s", e.StatusCode, e.Detail)
+}
+
+// IsNotFound returns if the error is a SCIM error with status 404.
+func IsNotFound(err error) bool {
+	var scimErr *Error
+	return errors.As(err, &scimErr) && scimErr.StatusCode == http.StatusNotFound
+}
+
+// ListOptions filter, sort and page the results of a list request.
+type ListOptions struct {
+	Filter     Filter
+	Attributes []string
+	SortBy     string
+	// Descending sorts the results in descending order, ascending if false.
+	Descending bool
+	// StartIndex is the 1-based index of the first result.
+	StartIndex int
+	Count      int
+}
+
+func (o *ListOptions) query() url.Values {
+	query := make(url.Values)
+	if o == nil {
+		return query
+	}
+	if o.Filter != "" {
+		query.Set("filter", string(o.Filter))
+	}
+	if len(o.Attributes) > 0 {
+		query.Set("attributes", strings.Join(o.Attributes, ","))
+	}
+	if o.SortBy != "" {
+		query.Set("sortBy", o.SortBy)
+		query.Set("sortOrder", "ascending")
+		if o.Descending {
+			query.Set("sortOrder", "descending")
+		}
+	}
+	if o.StartIndex > 0 {
+		query.Set("startIndex", strconv.Itoa(o.StartIndex))
+	}
+	if o.Count > 0 {
+		query.Set("count", strconv.Itoa(o.Count))
+	}
+	return query
+}
+
+// ListResponse is a page of resources.
+type ListResponse[T any] struct {
+	Schemas      []string `json:"schemas"`
+	TotalResults int      `json:"totalResults"`
+	StartIndex   int      `json:"startIndex,omitempty"`
+	ItemsPerPage int      `json:"itemsPerPage,omitempty"`
+	Resources    []T      `json:"Resources"`
+}
+
+type patchRequest struct {
+	Schemas    []string         `json:"schemas"`
+	Operations []PatchOperation `json:"Operations"`
+}
+
+func create[T any](ctx context.Context, c *Client, resource string, body *T) (*T, error) {
+	result := new(T)
+	if err := c.do(ctx, http.MethodPost, resource, nil, body, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func get[T any](ctx context.Context, c *Client, resource, id string) (*T, error) {
+	result := new(T)
+	if err := c.do(ctx, http.MethodGet, resource+"/"+url.PathEscape(id), nil, nil, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func replace[T any](ctx context.Context, c *Client, resource, id string, body *T) (*T, error) {
+	result := new(T)
+	if err := c.do(ctx, http.MethodPut, resource+"/"+url.PathEscape(id), nil, body, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// patch returns nil without an error if the endpoint responds with 204 No Content.
+func patch[T any](ctx context.Context, c *Client, resource, id string, operations []PatchOperation) (*T, error) {
+	var result *T
+	err := c.do(ctx, http.MethodPatch, resource+"/"+url.PathEscape(id), nil, &patchRequest{
+		Schemas:    []string{SchemaPatchOp},
+		Operations: operations,
+	}, &result)
+	return result, err
+}
+
+func list[T any](ctx context.Context, c *Client, resource string, opts *ListOptions) (*ListResponse[T], error) {
+	result := new(ListResponse[T])
+	if err := c.do(ctx, http.MethodGet, resource, opts.query(), nil, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *Client) delete(ctx context.Context, resource, id string) error {
+	return c.do(ctx, http.MethodDelete, resource+"/"+url.PathEscape(id), nil, nil, nil)
+}
+
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, result any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	endpoint := c.baseURL + "/" + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", ContentType)
+	if body != nil {
+		req.Header.Set("Content-Type", ContentType)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		return parseError(resp)
+	}
+	if result == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+func parseError(resp *http.Response) error {
+	scimErr := &Error{StatusCode: resp.StatusCode}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	if err != nil || json.Unmarshal(data, scimErr) != nil {
+		scimErr.Detail = strings.TrimSpace(string(data))
+	}
+	scimErr.StatusCode = resp.StatusCode
+	return scimErr
+}
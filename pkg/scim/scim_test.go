@@ -0,0 +1,84 @@
+package scim
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client"
+	"github.com/zitadel/zitadel-go/v3/pkg/resources/resourcestest"
+)
+
+func TestFilter(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter Filter
+		want   string
+	}{
+		{"eq", Eq("userName", `al"ice`), `userName eq "al\"ice"`},
+		{"bool", Eq("active", true), `active eq true`},
+		{"and", And(Eq("userName", "alice"), Pr("emails")), `(userName eq "alice") and (emails pr)`},
+		{"or not", Or(Sw("userName", "a"), Not(Co("displayName", "b"))), `(userName sw "a") or (not (displayName co "b"))`},
+		{"single", And(Pr("title")), `title pr`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, string(tt.filter))
+		})
+	}
+}
+
+func testClient(t *testing.T, handler http.HandlerFunc) *Client {
+	c, err := New(context.Background(), resourcestest.NewInstance(t, handler), "org1", client.PAT(resourcestest.Token))
+	require.NoError(t, err)
+	return c
+}
+
+func TestClient_ListUsers(t *testing.T) {
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer "+resourcestest.Token, r.Header.Get("Authorization"))
+		assert.Equal(t, "/scim/v2/org1/Users", r.URL.Path)
+		assert.Equal(t, url.Values{"filter": {`userName eq "alice"`}, "count": {"10"}}, r.URL.Query())
+		w.Header().Set("Content-Type", ContentType)
+		json.NewEncoder(w).Encode(ListResponse[User]{
+			Schemas:      []string{SchemaListResponse},
+			TotalResults: 1,
+			Resources:    []User{{ID: "1", UserName: "alice"}},
+		})
+	})
+	users, err := c.ListUsers(context.Background(), &ListOptions{Filter: Eq("userName", "alice"), Count: 10})
+	require.NoError(t, err)
+	assert.Equal(t, 1, users.TotalResults)
+	assert.Equal(t, "alice", users.Resources[0].UserName)
+}
+
+func TestClient_PatchGroup(t *testing.T) {
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPatch, r.Method)
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, map[string]any{
+			"schemas":    []any{SchemaPatchOp},
+			"Operations": []any{map[string]any{"op": "add", "path": "members", "value": []any{map[string]any{"value": "u1"}}}},
+		}, body)
+		w.WriteHeader(http.StatusNoContent)
+	})
+	group, err := c.PatchGroup(context.Background(), "g1", Add("members", []Member{{Value: "u1"}}))
+	require.NoError(t, err)
+	assert.Nil(t, group)
+}
+
+func TestClient_error(t *testing.T) {
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"schemas":["` + SchemaError + `"],"status":"404","detail":"user not found"}`))
+	})
+	_, err := c.GetUser(context.Background(), "unknown")
+	assert.True(t, IsNotFound(err))
+	assert.EqualError(t, err, "scim: 404: user not found")
+}
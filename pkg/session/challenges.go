@@ -0,0 +1,99 @@
+package session
+
+import (
+	"encoding/json"
+
+	sessionV2 "github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/session/v2"
+)
+
+// UserVerification is the user verification requirement of a [WebAuthNChallenge].
+type UserVerification int
+
+const (
+	UserVerificationUnspecified UserVerification = iota
+	UserVerificationRequired
+	UserVerificationPreferred
+	UserVerificationDiscouraged
+)
+
+// Challenge is requested by a [Step] to be able to verify a factor in a following step.
+type Challenge interface {
+	apply(*sessionV2.RequestChallenges)
+}
+
+type challengeFunc func(*sessionV2.RequestChallenges)
+
+func (f challengeFunc) apply(challenges *sessionV2.RequestChallenges) {
+	f(challenges)
+}
+
+// WebAuthNChallenge requests the options for `navigator.credentials.get()`, returned in [Challenges.WebAuthN].
+// The domain is the relying party ID of the passkeys, usually the domain of the login UI.
+func WebAuthNChallenge(domain string, userVerification UserVerification) Challenge {
+	return challengeFunc(func(challenges *sessionV2.RequestChallenges) {
+		challenges.WebAuthN = &sessionV2.RequestChallenges_WebAuthN{
+			Domain:                      domain,
+			UserVerificationRequirement: sessionV2.UserVerificationRequirement(userVerification),
+		}
+	})
+}
+
+// OTPSMSChallenge requests ZITADEL to send a code by SMS.
+// If returnCode is set, the code is not sent but returned in [Challenges.OTPSMS] to be sent by the application.
+func OTPSMSChallenge(returnCode bool) Challenge {
+	return challengeFunc(func(challenges *sessionV2.RequestChallenges) {
+		challenges.OtpSms = &sessionV2.RequestChallenges_OTPSMS{ReturnCode: returnCode}
+	})
+}
+
+// OTPEmailChallenge requests ZITADEL to send a code by email.
+// The optional urlTemplate is used for the link in the email, the default ZITADEL URL if empty.
+func OTPEmailChallenge(urlTemplate string) Challenge {
+	return challengeFunc(func(challenges *sessionV2.RequestChallenges) {
+		sendCode := new(sessionV2.RequestChallenges_OTPEmail_SendCode)
+		if urlTemplate != "" {
+			sendCode.UrlTemplate = &urlTemplate
+		}
+		challenges.OtpEmail = &sessionV2.RequestChallenges_OTPEmail{
+			DeliveryType: &sessionV2.RequestChallenges_OTPEmail_SendCode_{SendCode: sendCode},
+		}
+	})
+}
+
+// OTPEmailReturnCodeChallenge requests a code to be sent by email by the application,
+// it's returned in [Challenges.OTPEmail].
+func OTPEmailReturnCodeChallenge() Challenge {
+	return challengeFunc(func(challenges *sessionV2.RequestChallenges) {
+		challenges.OtpEmail = &sessionV2.RequestChallenges_OTPEmail{
+			DeliveryType: &sessionV2.RequestChallenges_OTPEmail_ReturnCode_{ReturnCode: new(sessionV2.RequestChallenges_OTPEmail_ReturnCode)},
+		}
+	})
+}
+
+// Challenges are the responses to the requested [Challenge]s.
+type Challenges struct {
+	// WebAuthN are the JSON encoded PublicKeyCredentialRequestOptions to be passed to `navigator.credentials.get()`.
+	WebAuthN json.RawMessage
+	// OTPSMS is the code to be sent by SMS, only if requested to be returned.
+	OTPSMS string
+	// OTPEmail is the code to be sent by email, only if requested to be returned.
+	OTPEmail string
+}
+
+func newChallenges(challenges *sessionV2.Challenges) (*Challenges, error) {
+	if challenges == nil {
+		return nil, nil
+	}
+	c := &Challenges{
+		OTPSMS:   challenges.GetOtpSms(),
+		OTPEmail: challenges.GetOtpEmail(),
+	}
+	if options := challenges.GetWebAuthN().GetPublicKeyCredentialRequestOptions(); options != nil {
+		data, err := options.MarshalJSON()
+		if err != nil {
+			return nil, err
+		}
+		c.WebAuthN = data
+	}
+	return c, nil
+}
@@ -0,0 +1,90 @@
+package session
+
+import (
+	"encoding/json"
+
+	"google.golang.org/protobuf/types/known/structpb"
+
+	sessionV2 "github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/session/v2"
+)
+
+// Check is a factor verified by a [Step].
+type Check interface {
+	apply(*sessionV2.Checks) error
+}
+
+type checkFunc func(*sessionV2.Checks) error
+
+func (f checkFunc) apply(checks *sessionV2.Checks) error {
+	return f(checks)
+}
+
+// User identifies the user of the session by its ID.
+func User(userID string) Check {
+	return checkFunc(func(checks *sessionV2.Checks) error {
+		checks.User = &sessionV2.CheckUser{Search: &sessionV2.CheckUser_UserId{UserId: userID}}
+		return nil
+	})
+}
+
+// LoginName identifies the user of the session by the login name entered by the user.
+func LoginName(loginName string) Check {
+	return checkFunc(func(checks *sessionV2.Checks) error {
+		checks.User = &sessionV2.CheckUser{Search: &sessionV2.CheckUser_LoginName{LoginName: loginName}}
+		return nil
+	})
+}
+
+// Password verifies the password of the user.
+func Password(password string) Check {
+	return checkFunc(func(checks *sessionV2.Checks) error {
+		checks.Password = &sessionV2.CheckPassword{Password: password}
+		return nil
+	})
+}
+
+// IDPIntent verifies the successful authentication at an external identity provider,
+// using the intent ID and token returned to the success URL of the intent.
+func IDPIntent(intentID, intentToken string) Check {
+	return checkFunc(func(checks *sessionV2.Checks) error {
+		checks.IdpIntent = &sessionV2.CheckIDPIntent{IdpIntentId: intentID, IdpIntentToken: intentToken}
+		return nil
+	})
+}
+
+// TOTP verifies the code of the authenticator app.
+func TOTP(code string) Check {
+	return checkFunc(func(checks *sessionV2.Checks) error {
+		checks.Totp = &sessionV2.CheckTOTP{Code: code}
+		return nil
+	})
+}
+
+// OTPSMS verifies the code sent by SMS, requested with [OTPSMSChallenge].
+func OTPSMS(code string) Check {
+	return checkFunc(func(checks *sessionV2.Checks) error {
+		checks.OtpSms = &sessionV2.CheckOTP{Code: code}
+		return nil
+	})
+}
+
+// OTPEmail verifies the code sent by email, requested with [OTPEmailChallenge].
+func OTPEmail(code string) Check {
+	return checkFunc(func(checks *sessionV2.Checks) error {
+		checks.OtpEmail = &sessionV2.CheckOTP{Code: code}
+		return nil
+	})
+}
+
+// WebAuthN verifies the assertion of the passkey or security key, i.e. the JSON encoded PublicKeyCredential
+// returned by `navigator.credentials.get()` in the browser for the options of the [WebAuthNChallenge].
+func WebAuthN(credential json.RawMessage) Check {
+	return checkFunc(func(checks *sessionV2.Checks) error {
+		data := new(structpb.Struct)
+		if err := data.UnmarshalJSON(credential); err != nil {
+			return err
+		}
+		checks.WebAuthN = &sessionV2.CheckWebAuthN{CredentialAssertionData: data}
+		return nil
+	})
+}
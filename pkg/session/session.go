@@ -0,0 +1,157 @@
+// Package session provides a high level facade of the session service (v2) for custom login UIs.
+// A [Session] is created and then verified step by step with typed [Check]s (user, password, IDP intent, OTP, WebAuthn),
+// requesting [Challenge]s where needed, instead of assembling the oneof protos of the API.
+// The session token returned by every step must be used for the next one and is tracked by the [Session].
+package session
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client"
+	sessionV2 "github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/session/v2"
+)
+
+// Sessions wraps the session service.
+type Sessions struct {
+	client *client.Client
+}
+
+// New creates the [Sessions] facade using the client, which needs the permissions to manage sessions
+// (e.g. a service user with the IAM_LOGIN_CLIENT role).
+func New(client *client.Client) *Sessions {
+	return &Sessions{client: client}
+}
+
+// Session is a session created by [Sessions.Create].
+type Session struct {
+	ID string
+	// Token is the current session token, which changes with every step.
+	// It's used to finalize the auth request of the login and to call [Sessions.Set] and [Sessions.Delete].
+	Token string
+	// Challenges are the challenges requested by the last step.
+	Challenges *Challenges
+	// ExpiresAt is the expiry of the session if a lifetime was set, zero otherwise.
+	ExpiresAt time.Time
+}
+
+// Step describes the checks, challenges and lifetime of a [Sessions.Create], resp. [Sessions.Set] call.
+type Step struct {
+	Checks     []Check
+	Challenges []Challenge
+	// Lifetime of the session from now, the session does not expire if not set on creation
+	// and the current lifetime is kept on updates.
+	Lifetime time.Duration
+	Metadata map[string][]byte
+}
+
+func (s *Step) checks() (*sessionV2.Checks, error) {
+	if len(s.Checks) == 0 {
+		return nil, nil
+	}
+	checks := new(sessionV2.Checks)
+	for _, check := range s.Checks {
+		if err := check.apply(checks); err != nil {
+			return nil, err
+		}
+	}
+	return checks, nil
+}
+
+func (s *Step) challenges() *sessionV2.RequestChallenges {
+	if len(s.Challenges) == 0 {
+		return nil
+	}
+	challenges := new(sessionV2.RequestChallenges)
+	for _, challenge := range s.Challenges {
+		challenge.apply(challenges)
+	}
+	return challenges
+}
+
+func (s *Step) lifetime() *durationpb.Duration {
+	if s.Lifetime <= 0 {
+		return nil
+	}
+	return durationpb.New(s.Lifetime)
+}
+
+// Create creates a new session, usually with the [User] or [LoginName] check.
+func (s *Sessions) Create(ctx context.Context, step Step) (*Session, error) {
+	checks, err := step.checks()
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.client.SessionServiceV2().CreateSession(ctx, &sessionV2.CreateSessionRequest{
+		Checks:     checks,
+		Metadata:   step.Metadata,
+		Challenges: step.challenges(),
+		Lifetime:   step.lifetime(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	session := &Session{ID: resp.GetSessionId()}
+	if err = session.update(resp.GetSessionToken(), resp.GetChallenges(), step.Lifetime); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// Set verifies further checks and requests challenges of the session.
+// On success, the token, challenges and expiry of the session are updated.
+func (s *Sessions) Set(ctx context.Context, session *Session, step Step) error {
+	checks, err := step.checks()
+	if err != nil {
+		return err
+	}
+	resp, err := s.client.SessionServiceV2().SetSession(ctx, &sessionV2.SetSessionRequest{
+		SessionId:    session.ID,
+		SessionToken: session.Token,
+		Checks:       checks,
+		Metadata:     step.Metadata,
+		Challenges:   step.challenges(),
+		Lifetime:     step.lifetime(),
+	})
+	if err != nil {
+		return err
+	}
+	return session.update(resp.GetSessionToken(), resp.GetChallenges(), step.Lifetime)
+}
+
+// Refresh extends the lifetime of the session, starting now.
+func (s *Sessions) Refresh(ctx context.Context, session *Session, lifetime time.Duration) error {
+	return s.Set(ctx, session, Step{Lifetime: lifetime})
+}
+
+// Get returns the current state of the session including the verified factors.
+func (s *Sessions) Get(ctx context.Context, session *Session) (*sessionV2.Session, error) {
+	resp, err := s.client.SessionServiceV2().GetSession(ctx, &sessionV2.GetSessionRequest{
+		SessionId:    session.ID,
+		SessionToken: &session.Token,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.GetSession(), nil
+}
+
+// Delete terminates the session.
+func (s *Sessions) Delete(ctx context.Context, session *Session) error {
+	_, err := s.client.SessionServiceV2().DeleteSession(ctx, &sessionV2.DeleteSessionRequest{
+		SessionId:    session.ID,
+		SessionToken: &session.Token,
+	})
+	return err
+}
+
+func (s *Session) update(token string, challenges *sessionV2.Challenges, lifetime time.Duration) (err error) {
+	s.Token = token
+	if lifetime > 0 {
+		s.ExpiresAt = time.Now().Add(lifetime)
+	}
+	s.Challenges, err = newChallenges(challenges)
+	return err
+}
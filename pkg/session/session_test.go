@@ -0,0 +1,60 @@
+package session
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	sessionV2 "github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/session/v2"
+)
+
+func TestStep(t *testing.T) {
+	step := Step{
+		Checks:     []Check{LoginName("alice@example.com"), Password("secret"), WebAuthN(json.RawMessage(`{"id":"cred"}`))},
+		Challenges: []Challenge{OTPSMSChallenge(true), OTPEmailChallenge("https://login/otp?code={{.Code}}")},
+		Lifetime:   time.Hour,
+	}
+	checks, err := step.checks()
+	require.NoError(t, err)
+	assert.True(t, proto.Equal(&sessionV2.Checks{
+		User:     &sessionV2.CheckUser{Search: &sessionV2.CheckUser_LoginName{LoginName: "alice@example.com"}},
+		Password: &sessionV2.CheckPassword{Password: "secret"},
+		WebAuthN: &sessionV2.CheckWebAuthN{CredentialAssertionData: &structpb.Struct{Fields: map[string]*structpb.Value{
+			"id": structpb.NewStringValue("cred"),
+		}}},
+	}, checks), "got %v", checks)
+
+	urlTemplate := "https://login/otp?code={{.Code}}"
+	challenges := step.challenges()
+	assert.True(t, proto.Equal(&sessionV2.RequestChallenges{
+		OtpSms: &sessionV2.RequestChallenges_OTPSMS{ReturnCode: true},
+		OtpEmail: &sessionV2.RequestChallenges_OTPEmail{DeliveryType: &sessionV2.RequestChallenges_OTPEmail_SendCode_{
+			SendCode: &sessionV2.RequestChallenges_OTPEmail_SendCode{UrlTemplate: &urlTemplate},
+		}},
+	}, challenges), "got %v", challenges)
+	assert.True(t, proto.Equal(durationpb.New(time.Hour), step.lifetime()))
+
+	_, err = (&Step{Checks: []Check{WebAuthN(json.RawMessage(`invalid`))}}).checks()
+	assert.Error(t, err)
+}
+
+func TestSession_update(t *testing.T) {
+	options, err := structpb.NewStruct(map[string]any{"challenge": "abc"})
+	require.NoError(t, err)
+	otp := "123456"
+	session := &Session{ID: "id"}
+	require.NoError(t, session.update("token", &sessionV2.Challenges{
+		WebAuthN: &sessionV2.Challenges_WebAuthN{PublicKeyCredentialRequestOptions: options},
+		OtpSms:   &otp,
+	}, 0))
+	assert.Equal(t, "token", session.Token)
+	assert.True(t, session.ExpiresAt.IsZero())
+	assert.JSONEq(t, `{"challenge":"abc"}`, string(session.Challenges.WebAuthN))
+	assert.Equal(t, otp, session.Challenges.OTPSMS)
+}
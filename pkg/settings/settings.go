@@ -0,0 +1,180 @@
+// Package settings provides an in-memory read-through cache for the settings (v2) of organizations,
+// so login UIs don't call ZITADEL on every page render:
+//
+//	cache := settings.New(client)
+//	go cache.Watch(ctx, 10*time.Second)
+//	branding, err := cache.Branding(ctx, orgID)
+//
+// The settings are cached for a TTL. [Cache.Watch] additionally invalidates them as soon as ZITADEL
+// reports a change of the underlying policies, comparing the sequences of the events with the cached ones.
+package settings
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client"
+	objectV2 "github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/object/v2"
+	settingsV2 "github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/settings/v2"
+)
+
+// DefaultTTL is the duration the settings are cached.
+const DefaultTTL = 5 * time.Minute
+
+type kind int
+
+const (
+	kindBranding kind = iota
+	kindLogin
+	kindPasswordComplexity
+	kindLockout
+)
+
+type key struct {
+	kind  kind
+	orgID string
+}
+
+type entry struct {
+	value         any
+	sequence      uint64
+	resourceOwner string
+	loadedAt      time.Time
+}
+
+// Cache caches the settings of the organizations, see the package documentation.
+type Cache struct {
+	service settingsV2.SettingsServiceClient
+	client  *client.Client
+	ttl     time.Duration
+	now     func() time.Time
+
+	mu      sync.Mutex
+	entries map[key]*entry
+}
+
+// Option allows customization of the [Cache].
+type Option func(*Cache)
+
+// WithTTL sets the duration the settings are cached (default 5 minutes).
+func WithTTL(ttl time.Duration) Option {
+	return func(c *Cache) {
+		c.ttl = ttl
+	}
+}
+
+// New creates a [Cache] using the client.
+func New(client *client.Client, opts ...Option) *Cache {
+	c := &Cache{
+		service: client.SettingsServiceV2(),
+		client:  client,
+		ttl:     DefaultTTL,
+		now:     time.Now,
+		entries: make(map[key]*entry),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Branding returns the active branding settings of the organization, the ones of the instance for an empty orgID.
+func (c *Cache) Branding(ctx context.Context, orgID string) (*settingsV2.BrandingSettings, error) {
+	return get(ctx, c, kindBranding, orgID, func(ctx context.Context, reqCtx *objectV2.RequestContext) (*settingsV2.BrandingSettings, *objectV2.Details, error) {
+		resp, err := c.service.GetBrandingSettings(ctx, &settingsV2.GetBrandingSettingsRequest{Ctx: reqCtx})
+		return resp.GetSettings(), resp.GetDetails(), err
+	})
+}
+
+// Login returns the login settings of the organization, the ones of the instance for an empty orgID.
+func (c *Cache) Login(ctx context.Context, orgID string) (*settingsV2.LoginSettings, error) {
+	return get(ctx, c, kindLogin, orgID, func(ctx context.Context, reqCtx *objectV2.RequestContext) (*settingsV2.LoginSettings, *objectV2.Details, error) {
+		resp, err := c.service.GetLoginSettings(ctx, &settingsV2.GetLoginSettingsRequest{Ctx: reqCtx})
+		return resp.GetSettings(), resp.GetDetails(), err
+	})
+}
+
+// PasswordComplexity returns the password complexity settings of the organization,
+// the ones of the instance for an empty orgID.
+func (c *Cache) PasswordComplexity(ctx context.Context, orgID string) (*settingsV2.PasswordComplexitySettings, error) {
+	return get(ctx, c, kindPasswordComplexity, orgID, func(ctx context.Context, reqCtx *objectV2.RequestContext) (*settingsV2.PasswordComplexitySettings, *objectV2.Details, error) {
+		resp, err := c.service.GetPasswordComplexitySettings(ctx, &settingsV2.GetPasswordComplexitySettingsRequest{Ctx: reqCtx})
+		return resp.GetSettings(), resp.GetDetails(), err
+	})
+}
+
+// Lockout returns the lockout settings of the organization, the ones of the instance for an empty orgID.
+func (c *Cache) Lockout(ctx context.Context, orgID string) (*settingsV2.LockoutSettings, error) {
+	return get(ctx, c, kindLockout, orgID, func(ctx context.Context, reqCtx *objectV2.RequestContext) (*settingsV2.LockoutSettings, *objectV2.Details, error) {
+		resp, err := c.service.GetLockoutSettings(ctx, &settingsV2.GetLockoutSettingsRequest{Ctx: reqCtx})
+		return resp.GetSettings(), resp.GetDetails(), err
+	})
+}
+
+// Invalidate removes the cached settings of the organization, the ones of the instance for an empty orgID.
+func (c *Cache) Invalidate(orgID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k := range c.entries {
+		if k.orgID == orgID {
+			delete(c.entries, k)
+		}
+	}
+}
+
+// InvalidateAll removes all cached settings.
+func (c *Cache) InvalidateAll() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clear(c.entries)
+}
+
+// invalidateChange removes the settings changed by an event of the aggregate (organization or instance)
+// with the sequence: the settings of the organization itself and the settings owned by the aggregate
+// (e.g. the ones of the instance inherited by organizations), unless they were loaded after the change.
+func (c *Cache) invalidateChange(aggregateID string, sequence uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k, e := range c.entries {
+		if k.orgID != aggregateID && e.resourceOwner != aggregateID {
+			continue
+		}
+		if e.resourceOwner == aggregateID && e.sequence >= sequence {
+			continue
+		}
+		delete(c.entries, k)
+	}
+}
+
+type fetchFunc[T any] func(ctx context.Context, reqCtx *objectV2.RequestContext) (T, *objectV2.Details, error)
+
+func get[T any](ctx context.Context, c *Cache, kind kind, orgID string, fetch fetchFunc[T]) (T, error) {
+	k := key{kind: kind, orgID: orgID}
+	c.mu.Lock()
+	e, ok := c.entries[k]
+	c.mu.Unlock()
+	if ok && c.now().Sub(e.loadedAt) < c.ttl {
+		return e.value.(T), nil
+	}
+	reqCtx := &objectV2.RequestContext{ResourceOwner: &objectV2.RequestContext_Instance{Instance: true}}
+	if orgID != "" {
+		reqCtx.ResourceOwner = &objectV2.RequestContext_OrgId{OrgId: orgID}
+	}
+	value, details, err := fetch(ctx, reqCtx)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	if c.ttl > 0 {
+		c.mu.Lock()
+		c.entries[k] = &entry{
+			value:         value,
+			sequence:      details.GetSequence(),
+			resourceOwner: details.GetResourceOwner(),
+			loadedAt:      c.now(),
+		}
+		c.mu.Unlock()
+	}
+	return value, nil
+}
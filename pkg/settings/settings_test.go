@@ -0,0 +1,86 @@
+package settings
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	objectV2 "github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/object/v2"
+	settingsV2 "github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/settings/v2"
+)
+
+type fakeSettingsService struct {
+	settingsV2.SettingsServiceClient
+	calls int
+	// owners maps the organizations to the owners of their settings, the instance if missing
+	owners map[string]string
+}
+
+func (f *fakeSettingsService) GetLoginSettings(_ context.Context, req *settingsV2.GetLoginSettingsRequest, _ ...grpc.CallOption) (*settingsV2.GetLoginSettingsResponse, error) {
+	f.calls++
+	owner := "instance"
+	if o, ok := f.owners[req.GetCtx().GetOrgId()]; ok {
+		owner = o
+	}
+	return &settingsV2.GetLoginSettingsResponse{
+		Details:  &objectV2.Details{Sequence: 10, ResourceOwner: owner},
+		Settings: &settingsV2.LoginSettings{AllowRegister: req.GetCtx().GetInstance()},
+	}, nil
+}
+
+func TestCache(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	service := &fakeSettingsService{owners: map[string]string{"org1": "org1"}}
+	c := &Cache{service: service, ttl: time.Minute, now: func() time.Time { return now }, entries: make(map[key]*entry)}
+	load := func(orgIDs ...string) {
+		for _, orgID := range orgIDs {
+			_, err := c.Login(ctx, orgID)
+			require.NoError(t, err)
+		}
+	}
+
+	instance, err := c.Login(ctx, "")
+	require.NoError(t, err)
+	assert.True(t, instance.GetAllowRegister())
+	load("org1", "org2", "org1", "org2")
+	assert.Equal(t, 3, service.calls)
+
+	t.Run("ttl", func(t *testing.T) {
+		now = now.Add(time.Minute)
+		load("", "org1", "org2")
+		assert.Equal(t, 6, service.calls)
+	})
+	t.Run("change already loaded", func(t *testing.T) {
+		c.invalidateChange("org1", 10)
+		load("org1")
+		assert.Equal(t, 6, service.calls)
+	})
+	t.Run("change of the organization", func(t *testing.T) {
+		c.invalidateChange("org1", 11)
+		load("org1", "org2")
+		assert.Equal(t, 7, service.calls)
+	})
+	t.Run("custom settings of an inheriting organization", func(t *testing.T) {
+		c.invalidateChange("org2", 1)
+		load("org2", "org1")
+		assert.Equal(t, 8, service.calls)
+	})
+	t.Run("change of the instance", func(t *testing.T) {
+		c.invalidateChange("instance", 11)
+		load("", "org1", "org2")
+		assert.Equal(t, 10, service.calls)
+	})
+	t.Run("invalidate", func(t *testing.T) {
+		c.Invalidate("org1")
+		load("org1", "org2")
+		assert.Equal(t, 11, service.calls)
+		c.InvalidateAll()
+		load("", "org1", "org2")
+		assert.Equal(t, 14, service.calls)
+	})
+}
@@ -0,0 +1,55 @@
+package settings
+
+import (
+	"context"
+	"time"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/events"
+)
+
+// policyEvents are the suffixes of the event types (prefixed by `org.` and `instance.`) changing the cached settings.
+var policyEvents = []string{
+	"policy.label.activated",
+	"policy.label.removed",
+	"policy.login.added",
+	"policy.login.changed",
+	"policy.login.removed",
+	"policy.login.multifactor.added",
+	"policy.login.multifactor.removed",
+	"policy.login.secondfactor.added",
+	"policy.login.secondfactor.removed",
+	"policy.password.complexity.added",
+	"policy.password.complexity.changed",
+	"policy.password.complexity.removed",
+	"policy.lockout.added",
+	"policy.lockout.changed",
+	"policy.lockout.removed",
+}
+
+func watchedEventTypes() []string {
+	types := make([]string, 0, 2*len(policyEvents))
+	for _, event := range policyEvents {
+		types = append(types, "org."+event, "instance."+event)
+	}
+	return types
+}
+
+// Watch polls the events of ZITADEL for changes of the settings in the provided interval and invalidates
+// the affected cache entries, so changes are visible within the interval instead of the TTL.
+// Changes of the instance invalidate the settings of all organizations inheriting them.
+// It blocks until the context is done and requires the client to be authorized to read the events (e.g. IAM_OWNER_VIEWER).
+func (c *Cache) Watch(ctx context.Context, interval time.Duration) error {
+	subscriber := events.New(c.client, events.WithInterval(interval), events.WithFrom(c.now()))
+	changes, err := subscriber.Subscribe(ctx, events.Filter{
+		AggregateTypes: []string{"org", "instance"},
+		EventTypes:     watchedEventTypes(),
+	})
+	if err != nil {
+		return err
+	}
+	// temporary errors are retried with the next poll
+	for e := range changes {
+		c.invalidateChange(e.AggregateID, e.Sequence)
+	}
+	return ctx.Err()
+}
@@ -0,0 +1,254 @@
+// Package snapshottest compares API responses against golden files (snapshots), making regression tests
+// of code calling ZITADEL (e.g. provisioning) practical.
+// Fields changing on every run, such as IDs, timestamps and sequences, are replaced by placeholders before comparing:
+//
+//	func TestProvision(t *testing.T) {
+//		resp, err := client.ManagementService().GetProjectByID(ctx, &management.GetProjectByIDRequest{Id: projectID})
+//		require.NoError(t, err)
+//		snapshottest.Match(t, "project", resp)
+//	}
+//
+// The snapshots are stored as JSON in testdata/snapshots/<name>.json next to the test.
+// Missing snapshots are created, existing ones are updated if the environment variable UPDATE_SNAPSHOTS is set:
+//
+//	UPDATE_SNAPSHOTS=1 go test ./...
+//
+// IDs are replaced by numbered placeholders (e.g. `<id-1>`), so references between resources are still compared:
+// the same ID is always replaced by the same placeholder.
+package snapshottest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// Dir is the directory of the snapshots, relative to the package of the test.
+	Dir = "testdata/snapshots"
+	// UpdateEnv is the environment variable which updates the snapshots if set.
+	UpdateEnv = "UPDATE_SNAPSHOTS"
+
+	// PlaceholderTimestamp, PlaceholderSequence and PlaceholderIgnored replace the volatile values.
+	PlaceholderTimestamp = "<timestamp>"
+	PlaceholderSequence  = "<sequence>"
+	PlaceholderIgnored   = "<ignored>"
+)
+
+// DefaultIDFields are the fields replaced by ID placeholders in addition to fields named `id`
+// or ending with `Id`, `ID` or `Ids`.
+var DefaultIDFields = []string{"resourceOwner", "orgId", "creator", "editorId"}
+
+// DefaultSequenceFields are the fields replaced by [PlaceholderSequence].
+var DefaultSequenceFields = []string{"sequence", "processedSequence"}
+
+// DefaultIgnoredFields are the fields replaced by [PlaceholderIgnored], since they are random on every run.
+var DefaultIgnoredFields = []string{"clientSecret", "keyDetails"}
+
+// TB is the subset of [testing.TB] used by [Match].
+type TB interface {
+	Helper()
+	Errorf(format string, args ...any)
+	Fatalf(format string, args ...any)
+}
+
+// Option allows customization of the normalization.
+type Option func(*normalizer)
+
+// WithIDFields replaces the values of the fields by ID placeholders, in addition to [DefaultIDFields].
+func WithIDFields(fields ...string) Option {
+	return func(n *normalizer) {
+		n.idFields = append(n.idFields, fields...)
+	}
+}
+
+// WithIgnoredFields replaces the values of the fields by [PlaceholderIgnored], in addition to [DefaultIgnoredFields].
+func WithIgnoredFields(fields ...string) Option {
+	return func(n *normalizer) {
+		n.ignoredFields = append(n.ignoredFields, fields...)
+	}
+}
+
+// WithKeptFields keeps the values of the fields, even if they would be replaced otherwise, e.g. a fixed external ID.
+func WithKeptFields(fields ...string) Option {
+	return func(n *normalizer) {
+		n.keptFields = append(n.keptFields, fields...)
+	}
+}
+
+// WithTimestamps keeps the timestamps, which are replaced by [PlaceholderTimestamp] by default.
+func WithTimestamps() Option {
+	return func(n *normalizer) {
+		n.keepTimestamps = true
+	}
+}
+
+// Match normalizes the value (see [Normalize]) and compares it with the snapshot of the name.
+// The snapshot is created if it does not exist yet or updated if [UpdateEnv] is set.
+func Match(t TB, name string, value any, opts ...Option) {
+	t.Helper()
+	got, err := Normalize(value, opts...)
+	if err != nil {
+		t.Fatalf("snapshot %s: %v", name, err)
+		return
+	}
+	path := filepath.Join(Dir, filepath.FromSlash(name)+".json")
+	want, err := os.ReadFile(path)
+	if os.IsNotExist(err) || (err == nil && os.Getenv(UpdateEnv) != "") {
+		if err = write(path, got); err != nil {
+			t.Fatalf("snapshot %s: %v", name, err)
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("snapshot %s: %v", name, err)
+		return
+	}
+	if !bytes.Equal(want, got) {
+		t.Errorf("snapshot %s does not match (set %s=1 to update):\n%s", name, UpdateEnv, diff(want, got))
+	}
+}
+
+// Normalize returns the indented JSON representation of the value with replaced volatile fields.
+// Proto messages are marshalled using [protojson], other values using [json.Marshal].
+func Normalize(value any, opts ...Option) ([]byte, error) {
+	var data []byte
+	var err error
+	if msg, ok := value.(proto.Message); ok {
+		data, err = protojson.Marshal(msg)
+	} else {
+		data, err = json.Marshal(value)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var decoded any
+	if err = json.Unmarshal(data, &decoded); err != nil {
+		return nil, err
+	}
+	n := &normalizer{
+		idFields:       slices.Clone(DefaultIDFields),
+		sequenceFields: DefaultSequenceFields,
+		ignoredFields:  slices.Clone(DefaultIgnoredFields),
+		ids:            make(map[string]string),
+	}
+	for _, opt := range opts {
+		opt(n)
+	}
+	normalized, err := json.MarshalIndent(n.normalize("", decoded), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(normalized, '\n'), nil
+}
+
+type normalizer struct {
+	idFields       []string
+	sequenceFields []string
+	ignoredFields  []string
+	keptFields     []string
+	keepTimestamps bool
+	// ids maps the IDs to their placeholders, numbered in order of appearance
+	ids map[string]string
+}
+
+// normalize replaces the volatile values, the keys of objects are visited in sorted order,
+// so the placeholders are stable.
+func (n *normalizer) normalize(field string, value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+		for _, key := range keys {
+			v[key] = n.normalize(key, v[key])
+		}
+		return v
+	case []any:
+		for i := range v {
+			v[i] = n.normalize(field, v[i])
+		}
+		return v
+	case string:
+		return n.normalizeString(field, v)
+	case float64:
+		if slices.Contains(n.sequenceFields, field) && !slices.Contains(n.keptFields, field) {
+			return PlaceholderSequence
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+func (n *normalizer) normalizeString(field, value string) any {
+	switch {
+	case value == "" || slices.Contains(n.keptFields, field):
+		return value
+	case slices.Contains(n.ignoredFields, field):
+		return PlaceholderIgnored
+	case slices.Contains(n.sequenceFields, field):
+		return PlaceholderSequence
+	case n.isIDField(field):
+		placeholder, ok := n.ids[value]
+		if !ok {
+			placeholder = "<id-" + strconv.Itoa(len(n.ids)+1) + ">"
+			n.ids[value] = placeholder
+		}
+		return placeholder
+	case !n.keepTimestamps && isTimestamp(value):
+		return PlaceholderTimestamp
+	default:
+		return value
+	}
+}
+
+func (n *normalizer) isIDField(field string) bool {
+	return field == "id" ||
+		strings.HasSuffix(field, "Id") ||
+		strings.HasSuffix(field, "ID") ||
+		strings.HasSuffix(field, "Ids") ||
+		slices.Contains(n.idFields, field)
+}
+
+func isTimestamp(value string) bool {
+	_, err := time.Parse(time.RFC3339Nano, value)
+	return err == nil
+}
+
+func write(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// diff returns the lines of the snapshot (-) and the value (+) starting at the first difference.
+func diff(want, got []byte) string {
+	wantLines := strings.Split(string(want), "\n")
+	gotLines := strings.Split(string(got), "\n")
+	first := 0
+	for first < len(wantLines) && first < len(gotLines) && wantLines[first] == gotLines[first] {
+		first++
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "@@ line %d @@\n", first+1)
+	for _, line := range wantLines[first:min(len(wantLines), first+10)] {
+		b.WriteString("- " + line + "\n")
+	}
+	for _, line := range gotLines[first:min(len(gotLines), first+10)] {
+		b.WriteString("+ " + line + "\n")
+	}
+	return b.String()
+}
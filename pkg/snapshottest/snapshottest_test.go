@@ -0,0 +1,98 @@
+package snapshottest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/object"
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/project"
+)
+
+func testProject(id string, sequence uint64) *project.Project {
+	return &project.Project{
+		Id:   id,
+		Name: "portal",
+		Details: &object.ObjectDetails{
+			Sequence:      sequence,
+			CreationDate:  timestamppb.New(time.Now()),
+			ResourceOwner: "org-" + id,
+		},
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	got, err := Normalize([]any{
+		map[string]any{"projectId": "p1", "userIds": []string{"u1", "u2"}, "clientSecret": "secret", "sequence": 42},
+		map[string]any{"id": "p1", "name": "kept", "externalId": "ext", "changed": "2024-01-02T03:04:05.123Z"},
+	}, WithKeptFields("externalId"))
+	require.NoError(t, err)
+	assert.JSONEq(t, `[
+		{"projectId": "<id-1>", "userIds": ["<id-2>", "<id-3>"], "clientSecret": "<ignored>", "sequence": "<sequence>"},
+		{"id": "<id-1>", "name": "kept", "externalId": "ext", "changed": "<timestamp>"}
+	]`, string(got))
+}
+
+type fakeTB struct {
+	errors []string
+}
+
+func (f *fakeTB) Helper() {}
+
+func (f *fakeTB) Errorf(format string, args ...any) {
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+}
+
+func (f *fakeTB) Fatalf(format string, args ...any) {
+	f.Errorf(format, args...)
+}
+
+func TestMatch(t *testing.T) {
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(t.TempDir()))
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	tb := new(fakeTB)
+	Match(tb, "project", testProject("1", 1))
+	assert.Empty(t, tb.errors)
+	snapshot, err := os.ReadFile(filepath.Join(Dir, "project.json"))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"id": "<id-2>",
+		"name": "portal",
+		"details": {"sequence": "<sequence>", "creationDate": "<timestamp>", "resourceOwner": "<id-1>"}
+	}`, string(snapshot))
+
+	t.Run("other run matches", func(t *testing.T) {
+		tb := new(fakeTB)
+		Match(tb, "project", testProject("2", 5))
+		assert.Empty(t, tb.errors)
+	})
+	t.Run("change detected", func(t *testing.T) {
+		tb := new(fakeTB)
+		changed := testProject("3", 7)
+		changed.Name = "changed"
+		Match(tb, "project", changed)
+		require.Len(t, tb.errors, 1)
+		assert.Contains(t, tb.errors[0], `-   "name": "portal"`)
+		assert.Contains(t, tb.errors[0], `+   "name": "changed"`)
+	})
+	t.Run("update", func(t *testing.T) {
+		t.Setenv(UpdateEnv, "1")
+		changed := testProject("4", 9)
+		changed.Name = "changed"
+		tb := new(fakeTB)
+		Match(tb, "project", changed)
+		assert.Empty(t, tb.errors)
+		snapshot, err := os.ReadFile(filepath.Join(Dir, "project.json"))
+		require.NoError(t, err)
+		assert.Contains(t, string(snapshot), `"changed"`)
+	})
+}
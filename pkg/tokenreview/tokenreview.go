@@ -0,0 +1,237 @@
+// Package tokenreview implements the Kubernetes webhook token authentication,
+// so the API server (and therefore kubectl) accepts ZITADEL tokens.
+//
+// The API server sends a TokenReview to the [Handler], which verifies the token using the [authorization.Authorizer]
+// and responds with the mapped Kubernetes user, whose permissions are then granted by (Cluster)RoleBindings.
+// Configure the API server with `--authentication-token-webhook-config-file` pointing to the handler.
+//
+// Only tokens issued for the configured audience (e.g. the ID of the project of the cluster) are authenticated.
+// Usernames and groups are prefixed with [DefaultPrefix], names of the reserved `system:` namespace are rejected,
+// so a role of a ZITADEL project can't grant e.g. the `system:masters` group.
+// DPoP-bound tokens are not authenticated, as the API server only passes the token without its proof.
+package tokenreview
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/authorization"
+	"github.com/zitadel/zitadel-go/v3/pkg/authorization/oauth"
+	"github.com/zitadel/zitadel-go/v3/pkg/dpop"
+)
+
+const (
+	apiVersion = "authentication.k8s.io/v1"
+	kind       = "TokenReview"
+	maxBody    = 1 << 20
+
+	claimRoles = "urn:zitadel:iam:org:project:roles"
+	// ExtraOrganizationID is the key of the extra attribute containing the organization of the user.
+	ExtraOrganizationID = "zitadel.com/organization-id"
+	// DefaultPrefix is the default prefix of the usernames and groups.
+	DefaultPrefix = "zitadel:"
+
+	reservedPrefix = "system:"
+)
+
+var (
+	ErrInvalidTokenReview = errors.New("invalid TokenReview")
+	ErrAudienceMismatch   = errors.New("token does not match the requested audiences")
+	ErrMissingAudience    = errors.New("audience is required")
+	ErrNoUser             = errors.New("token is not mapped to a user")
+	ErrReservedName       = errors.New("name is reserved by Kubernetes")
+	ErrBoundToken         = errors.New("DPoP-bound tokens can't be used without their proof")
+)
+
+// TokenReview is the request and response of the webhook (authentication.k8s.io/v1).
+type TokenReview struct {
+	APIVersion string            `json:"apiVersion"`
+	Kind       string            `json:"kind"`
+	Spec       TokenReviewSpec   `json:"spec"`
+	Status     TokenReviewStatus `json:"status"`
+}
+
+type TokenReviewSpec struct {
+	Token     string   `json:"token"`
+	Audiences []string `json:"audiences,omitempty"`
+}
+
+type TokenReviewStatus struct {
+	Authenticated bool     `json:"authenticated"`
+	User          *User    `json:"user,omitempty"`
+	Audiences     []string `json:"audiences,omitempty"`
+	Error         string   `json:"error,omitempty"`
+}
+
+// User is the Kubernetes user the token is mapped to.
+type User struct {
+	Username string              `json:"username"`
+	UID      string              `json:"uid,omitempty"`
+	Groups   []string            `json:"groups,omitempty"`
+	Extra    map[string][]string `json:"extra,omitempty"`
+}
+
+// Handler answers the TokenReviews of the Kubernetes API server.
+type Handler[T authorization.Ctx] struct {
+	authorizer     *authorization.Authorizer[T]
+	audience       string
+	mapUser        func(authCtx T) *User
+	usernamePrefix string
+	groupsPrefix   string
+	checks         []authorization.CheckOption
+}
+
+// Option allows customization of the [Handler].
+type Option[T authorization.Ctx] func(*Handler[T])
+
+// WithUsernamePrefix prefixes the usernames (default [DefaultPrefix]) to prevent conflicts with other authenticators.
+// An empty prefix is possible, usernames in the `system:` namespace are rejected anyway.
+func WithUsernamePrefix[T authorization.Ctx](prefix string) Option[T] {
+	return func(h *Handler[T]) {
+		h.usernamePrefix = prefix
+	}
+}
+
+// WithGroupsPrefix prefixes the groups (default [DefaultPrefix]) to prevent conflicts with the groups of other authenticators.
+// An empty prefix is possible, groups in the `system:` namespace (e.g. `system:masters`) are rejected anyway.
+func WithGroupsPrefix[T authorization.Ctx](prefix string) Option[T] {
+	return func(h *Handler[T]) {
+		h.groupsPrefix = prefix
+	}
+}
+
+// WithChecks requires the user to fulfill the checks (e.g. [authorization.WithRole]) to be authenticated.
+func WithChecks[T authorization.Ctx](checks ...authorization.CheckOption) Option[T] {
+	return func(h *Handler[T]) {
+		h.checks = append(h.checks, checks...)
+	}
+}
+
+// New creates a [Handler] mapping the authorization context to a Kubernetes user, e.g. using [IntrospectionUser].
+// Only tokens containing the audience (e.g. the ID of the project of the cluster) are authenticated,
+// the authorization context must therefore provide a `GetAudience() []string` method (e.g. oauth.IntrospectionContext).
+func New[T authorization.Ctx](authorizer *authorization.Authorizer[T], audience string, mapUser func(authCtx T) *User, options ...Option[T]) (*Handler[T], error) {
+	if audience == "" {
+		return nil, ErrMissingAudience
+	}
+	h := &Handler[T]{
+		authorizer:     authorizer,
+		audience:       audience,
+		mapUser:        mapUser,
+		usernamePrefix: DefaultPrefix,
+		groupsPrefix:   DefaultPrefix,
+	}
+	for _, option := range options {
+		option(h)
+	}
+	return h, nil
+}
+
+// ServeHTTP implements [http.Handler]
+func (h *Handler[T]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	review := new(TokenReview)
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBody)).Decode(review); err != nil || review.Kind != kind {
+		http.Error(w, ErrInvalidTokenReview.Error(), http.StatusBadRequest)
+		return
+	}
+	review.Status = h.review(r, &review.Spec)
+	review.APIVersion = apiVersion
+	review.Kind = kind
+	review.Spec.Token = ""
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(review)
+}
+
+func (h *Handler[T]) review(r *http.Request, spec *TokenReviewSpec) TokenReviewStatus {
+	authCtx, err := h.authorizer.CheckAuthorization(r.Context(), "Bearer "+spec.Token, h.checks...)
+	if err != nil {
+		return TokenReviewStatus{Error: err.Error()}
+	}
+	if dpop.ConfirmationThumbprint(authCtx) != "" {
+		return TokenReviewStatus{Error: ErrBoundToken.Error()}
+	}
+	audiences, err := matchAudiences(authCtx, h.audience, spec.Audiences)
+	if err != nil {
+		return TokenReviewStatus{Error: err.Error()}
+	}
+	user := h.mapUser(authCtx)
+	if user == nil || user.Username == "" {
+		return TokenReviewStatus{Error: ErrNoUser.Error()}
+	}
+	user.Username = h.usernamePrefix + user.Username
+	if err = checkName(user.Username); err != nil {
+		return TokenReviewStatus{Error: err.Error()}
+	}
+	for i, group := range user.Groups {
+		user.Groups[i] = h.groupsPrefix + group
+		if err = checkName(user.Groups[i]); err != nil {
+			return TokenReviewStatus{Error: err.Error()}
+		}
+	}
+	return TokenReviewStatus{
+		Authenticated: true,
+		User:          user,
+		Audiences:     audiences,
+	}
+}
+
+// checkName rejects names of the `system:` namespace reserved by Kubernetes.
+func checkName(name string) error {
+	if strings.HasPrefix(name, reservedPrefix) {
+		return fmt.Errorf("%w: %q", ErrReservedName, name)
+	}
+	return nil
+}
+
+// matchAudiences requires the token to contain the audience and returns the requested audiences it is valid for.
+func matchAudiences(authCtx any, audience string, requested []string) ([]string, error) {
+	claims, ok := authCtx.(interface{ GetAudience() []string })
+	if !ok || !slices.Contains(claims.GetAudience(), audience) {
+		return nil, ErrAudienceMismatch
+	}
+	if len(requested) == 0 {
+		return nil, nil
+	}
+	var matched []string
+	for _, audience := range requested {
+		if slices.Contains(claims.GetAudience(), audience) {
+			matched = append(matched, audience)
+		}
+	}
+	if len(matched) == 0 {
+		return nil, ErrAudienceMismatch
+	}
+	return matched, nil
+}
+
+// IntrospectionUser maps the introspection response to a Kubernetes user:
+// the username is the preferred username (or the ID if not available), the groups are the granted roles of the project.
+func IntrospectionUser(authCtx *oauth.IntrospectionContext) *User {
+	user := &User{
+		Username: authCtx.Username,
+		UID:      authCtx.UserID(),
+	}
+	if orgID := authCtx.OrganizationID(); orgID != "" {
+		user.Extra = map[string][]string{ExtraOrganizationID: {orgID}}
+	}
+	if user.Username == "" {
+		user.Username = authCtx.PreferredUsername
+	}
+	if user.Username == "" {
+		user.Username = user.UID
+	}
+	roles, _ := authCtx.Claims[claimRoles].(map[string]any)
+	for role := range roles {
+		user.Groups = append(user.Groups, role)
+	}
+	slices.Sort(user.Groups)
+	return user
+}
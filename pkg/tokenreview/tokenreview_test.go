@@ -0,0 +1,187 @@
+package tokenreview
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zitadel/oidc/v3/pkg/oidc"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/authorization"
+	"github.com/zitadel/zitadel-go/v3/pkg/authorization/oauth"
+	"github.com/zitadel/zitadel-go/v3/pkg/zitadel"
+)
+
+type testVerifier struct {
+	ctx *oauth.IntrospectionContext
+	err error
+}
+
+func (t *testVerifier) CheckAuthorization(context.Context, string) (*oauth.IntrospectionContext, error) {
+	return t.ctx, t.err
+}
+
+func TestHandler_ServeHTTP(t *testing.T) {
+	user := &oauth.IntrospectionContext{IntrospectionResponse: oidc.IntrospectionResponse{
+		Active:   true,
+		Subject:  "user1",
+		Username: "gigi@zitadel.com",
+		Audience: []string{"kubernetes", "other"},
+		Claims: map[string]any{
+			"urn:zitadel:iam:org:project:roles":     map[string]any{"viewer": map[string]any{}, "admin": map[string]any{}},
+			"urn:zitadel:iam:user:resourceowner:id": "org1",
+		},
+	}}
+	masters := &oauth.IntrospectionContext{IntrospectionResponse: oidc.IntrospectionResponse{
+		Active:   true,
+		Subject:  "user2",
+		Username: "mallory",
+		Audience: []string{"kubernetes"},
+		Claims: map[string]any{
+			"urn:zitadel:iam:org:project:roles": map[string]any{"system:masters": map[string]any{}},
+		},
+	}}
+	bound := &oauth.IntrospectionContext{IntrospectionResponse: oidc.IntrospectionResponse{
+		Active:   true,
+		Subject:  "user1",
+		Username: "gigi@zitadel.com",
+		Audience: []string{"kubernetes"},
+		Claims:   map[string]any{"cnf": map[string]any{"jkt": "thumbprint"}},
+	}}
+	otherApp := &oauth.IntrospectionContext{IntrospectionResponse: oidc.IntrospectionResponse{
+		Active:   true,
+		Subject:  "user1",
+		Username: "gigi@zitadel.com",
+		Audience: []string{"other"},
+	}}
+	tests := []struct {
+		name     string
+		verifier *testVerifier
+		mapUser  func(*oauth.IntrospectionContext) *User
+		options  []Option[*oauth.IntrospectionContext]
+		request  string
+		want     TokenReviewStatus
+	}{
+		{
+			name:     "authenticated",
+			verifier: &testVerifier{ctx: user},
+			request:  `{"apiVersion":"authentication.k8s.io/v1","kind":"TokenReview","spec":{"token":"token","audiences":["kubernetes"]}}`,
+			want: TokenReviewStatus{
+				Authenticated: true,
+				User: &User{
+					Username: "zitadel:gigi@zitadel.com",
+					UID:      "user1",
+					Groups:   []string{"zitadel:admin", "zitadel:viewer"},
+					Extra:    map[string][]string{ExtraOrganizationID: {"org1"}},
+				},
+				Audiences: []string{"kubernetes"},
+			},
+		},
+		{
+			name:     "default prefix",
+			verifier: &testVerifier{ctx: user},
+			options:  []Option[*oauth.IntrospectionContext]{},
+			request:  `{"apiVersion":"authentication.k8s.io/v1","kind":"TokenReview","spec":{"token":"token"}}`,
+			want: TokenReviewStatus{
+				Authenticated: true,
+				User: &User{
+					Username: "zitadel:gigi@zitadel.com",
+					UID:      "user1",
+					Groups:   []string{"zitadel:admin", "zitadel:viewer"},
+					Extra:    map[string][]string{ExtraOrganizationID: {"org1"}},
+				},
+			},
+		},
+		{
+			name:     "reserved group without prefix",
+			verifier: &testVerifier{ctx: masters},
+			options: []Option[*oauth.IntrospectionContext]{
+				WithUsernamePrefix[*oauth.IntrospectionContext](""),
+				WithGroupsPrefix[*oauth.IntrospectionContext](""),
+			},
+			request: `{"apiVersion":"authentication.k8s.io/v1","kind":"TokenReview","spec":{"token":"token"}}`,
+			want:    TokenReviewStatus{Error: ErrReservedName.Error() + `: "system:masters"`},
+		},
+		{
+			name:     "reserved username without prefix",
+			verifier: &testVerifier{ctx: masters},
+			mapUser:  func(*oauth.IntrospectionContext) *User { return &User{Username: "system:admin"} },
+			options:  []Option[*oauth.IntrospectionContext]{WithUsernamePrefix[*oauth.IntrospectionContext]("")},
+			request:  `{"apiVersion":"authentication.k8s.io/v1","kind":"TokenReview","spec":{"token":"token"}}`,
+			want:     TokenReviewStatus{Error: ErrReservedName.Error() + `: "system:admin"`},
+		},
+		{
+			name:     "no user",
+			verifier: &testVerifier{ctx: user},
+			mapUser:  func(*oauth.IntrospectionContext) *User { return nil },
+			request:  `{"apiVersion":"authentication.k8s.io/v1","kind":"TokenReview","spec":{"token":"token"}}`,
+			want:     TokenReviewStatus{Error: ErrNoUser.Error()},
+		},
+		{
+			name:     "token of other application",
+			verifier: &testVerifier{ctx: otherApp},
+			request:  `{"apiVersion":"authentication.k8s.io/v1","kind":"TokenReview","spec":{"token":"token"}}`,
+			want:     TokenReviewStatus{Error: ErrAudienceMismatch.Error()},
+		},
+		{
+			name:     "audience mismatch",
+			verifier: &testVerifier{ctx: user},
+			request:  `{"apiVersion":"authentication.k8s.io/v1","kind":"TokenReview","spec":{"token":"token","audiences":["cluster2"]}}`,
+			want:     TokenReviewStatus{Error: ErrAudienceMismatch.Error()},
+		},
+		{
+			name:     "DPoP-bound token",
+			verifier: &testVerifier{ctx: bound},
+			request:  `{"apiVersion":"authentication.k8s.io/v1","kind":"TokenReview","spec":{"token":"token"}}`,
+			want:     TokenReviewStatus{Error: ErrBoundToken.Error()},
+		},
+		{
+			name:     "invalid token",
+			verifier: &testVerifier{err: errors.New("expired")},
+			request:  `{"apiVersion":"authentication.k8s.io/v1","kind":"TokenReview","spec":{"token":"token"}}`,
+			want:     TokenReviewStatus{Error: "expired"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authorizer, err := authorization.New[*oauth.IntrospectionContext](context.Background(), zitadel.New("zitadel.cloud"),
+				func(context.Context, *zitadel.Zitadel) (authorization.Verifier[*oauth.IntrospectionContext], error) {
+					return tt.verifier, nil
+				},
+			)
+			require.NoError(t, err)
+			mapUser := tt.mapUser
+			if mapUser == nil {
+				mapUser = IntrospectionUser
+			}
+			options := tt.options
+			if options == nil {
+				options = []Option[*oauth.IntrospectionContext]{
+					WithUsernamePrefix[*oauth.IntrospectionContext]("zitadel:"),
+					WithGroupsPrefix[*oauth.IntrospectionContext]("zitadel:"),
+				}
+			}
+			h, err := New(authorizer, "kubernetes", mapUser, options...)
+			require.NoError(t, err)
+
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/authenticate", strings.NewReader(tt.request)))
+			require.Equal(t, http.StatusOK, rec.Code)
+			review := new(TokenReview)
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(review))
+			assert.Empty(t, review.Spec.Token)
+			assert.Equal(t, tt.want, review.Status)
+		})
+	}
+}
+
+func TestNew_missingAudience(t *testing.T) {
+	_, err := New[*oauth.IntrospectionContext](nil, "", IntrospectionUser)
+	assert.ErrorIs(t, err, ErrMissingAudience)
+}
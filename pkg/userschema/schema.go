@@ -0,0 +1,191 @@
+package userschema
+
+// Draft is the JSON schema dialect supported by ZITADEL.
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// Type is the JSON type of a value.
+type Type string
+
+const (
+	TypeObject  Type = "object"
+	TypeArray   Type = "array"
+	TypeString  Type = "string"
+	TypeInteger Type = "integer"
+	TypeNumber  Type = "number"
+	TypeBoolean Type = "boolean"
+	TypeNull    Type = "null"
+)
+
+// Access of a role to a property, see [Permission].
+type Access string
+
+const (
+	AccessNone      Access = ""
+	AccessRead      Access = "r"
+	AccessWrite     Access = "w"
+	AccessReadWrite Access = "rw"
+)
+
+// Permission defines which role can read and write a property of the user (ZITADEL extension of JSON schema):
+// the owner (managers of the organization of the user) and the user itself (self).
+type Permission struct {
+	Owner Access `json:"owner,omitempty"`
+	Self  Access `json:"self,omitempty"`
+}
+
+// Schema is a JSON schema describing the data of the users of a type.
+// Only the keywords below are supported by the local validation ([Schema.Validate]).
+type Schema struct {
+	Schema      string `json:"$schema,omitempty"`
+	Type        Type   `json:"type,omitempty"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+
+	// Properties, Required and AdditionalProperties apply to objects.
+	Properties map[string]*Schema `json:"properties,omitempty"`
+	Required   []string           `json:"required,omitempty"`
+	// AdditionalProperties defines if properties not defined are allowed, which they are if nil.
+	AdditionalProperties *bool `json:"additionalProperties,omitempty"`
+
+	// Items, MinItems and MaxItems apply to arrays.
+	Items    *Schema `json:"items,omitempty"`
+	MinItems *int    `json:"minItems,omitempty"`
+	MaxItems *int    `json:"maxItems,omitempty"`
+
+	// MinLength, MaxLength, Pattern and Format apply to strings.
+	MinLength *int   `json:"minLength,omitempty"`
+	MaxLength *int   `json:"maxLength,omitempty"`
+	Pattern   string `json:"pattern,omitempty"`
+	// Format is validated for `email`, `date`, `date-time` and `uri`, other formats are ignored.
+	Format string `json:"format,omitempty"`
+
+	// Minimum and Maximum apply to numbers and integers.
+	Minimum *float64 `json:"minimum,omitempty"`
+	Maximum *float64 `json:"maximum,omitempty"`
+
+	Enum []any `json:"enum,omitempty"`
+
+	Permission *Permission `json:"urn:zitadel:schema:permission,omitempty"`
+}
+
+// Property is a named property of an object, see [Object].
+type Property struct {
+	Name     string
+	Schema   *Schema
+	Required bool
+}
+
+// Object creates the schema of an object with the properties,
+// which is the root of a user schema, e.g.:
+//
+//	userschema.Object(
+//		userschema.Prop("name", userschema.String().WithMaxLength(200)).Require(),
+//		userschema.Prop("birthdate", userschema.String().WithFormat("date")),
+//	)
+func Object(properties ...Property) *Schema {
+	s := &Schema{Type: TypeObject, Properties: make(map[string]*Schema, len(properties))}
+	for _, property := range properties {
+		s.Properties[property.Name] = property.Schema
+		if property.Required {
+			s.Required = append(s.Required, property.Name)
+		}
+	}
+	return s
+}
+
+// Prop creates an optional [Property].
+func Prop(name string, schema *Schema) Property {
+	return Property{Name: name, Schema: schema}
+}
+
+// Require marks the property as required.
+func (p Property) Require() Property {
+	p.Required = true
+	return p
+}
+
+// Array creates the schema of an array with items of the schema.
+func Array(items *Schema) *Schema {
+	return &Schema{Type: TypeArray, Items: items}
+}
+
+// String creates the schema of a string.
+func String() *Schema {
+	return &Schema{Type: TypeString}
+}
+
+// Integer creates the schema of an integer.
+func Integer() *Schema {
+	return &Schema{Type: TypeInteger}
+}
+
+// Number creates the schema of a number.
+func Number() *Schema {
+	return &Schema{Type: TypeNumber}
+}
+
+// Boolean creates the schema of a boolean.
+func Boolean() *Schema {
+	return &Schema{Type: TypeBoolean}
+}
+
+// WithDraft sets the `$schema` of the root schema to [Draft].
+func (s *Schema) WithDraft() *Schema {
+	s.Schema = Draft
+	return s
+}
+
+// WithTitle sets the title of the schema.
+func (s *Schema) WithTitle(title string) *Schema {
+	s.Title = title
+	return s
+}
+
+// WithMinLength sets the minimal length of a string.
+func (s *Schema) WithMinLength(length int) *Schema {
+	s.MinLength = &length
+	return s
+}
+
+// WithMaxLength sets the maximal length of a string.
+func (s *Schema) WithMaxLength(length int) *Schema {
+	s.MaxLength = &length
+	return s
+}
+
+// WithPattern sets the regular expression a string must match.
+func (s *Schema) WithPattern(pattern string) *Schema {
+	s.Pattern = pattern
+	return s
+}
+
+// WithFormat sets the format of a string, e.g. `email`.
+func (s *Schema) WithFormat(format string) *Schema {
+	s.Format = format
+	return s
+}
+
+// WithRange sets the minimum and maximum (inclusive) of a number or integer.
+func (s *Schema) WithRange(minimum, maximum float64) *Schema {
+	s.Minimum, s.Maximum = &minimum, &maximum
+	return s
+}
+
+// WithEnum restricts the value to the values.
+func (s *Schema) WithEnum(values ...any) *Schema {
+	s.Enum = values
+	return s
+}
+
+// WithoutAdditionalProperties disallows properties of an object which are not defined.
+func (s *Schema) WithoutAdditionalProperties() *Schema {
+	additional := false
+	s.AdditionalProperties = &additional
+	return s
+}
+
+// WithPermission sets the access of the owner and the user itself to the property.
+func (s *Schema) WithPermission(owner, self Access) *Schema {
+	s.Permission = &Permission{Owner: owner, Self: self}
+	return s
+}
@@ -0,0 +1,168 @@
+// Package userschema implements a client for the user schema service of ZITADEL,
+// which defines the data of the users of a type (e.g. `employee` or `customer`) as JSON schema.
+//
+// The schemas are defined in Go ([Schema], [Object]), created and updated as new revisions using the [Client],
+// and the data of users can be validated locally ([Schema.Validate]) before it's submitted:
+//
+//	schema := userschema.Object(
+//		userschema.Prop("name", userschema.String().WithMaxLength(200)).Require(),
+//		userschema.Prop("department", userschema.String().WithEnum("sales", "support")),
+//	).WithDraft()
+//	id, err := c.Create(ctx, "employee", schema, userschema.AuthenticatorUsername, userschema.AuthenticatorPassword)
+//	...
+//	err = schema.Validate(employee)
+//
+// The service is part of the resource API of ZITADEL (v3alpha), which is served as HTTP/JSON (see [resources]).
+package userschema
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client"
+	"github.com/zitadel/zitadel-go/v3/pkg/resources"
+	"github.com/zitadel/zitadel-go/v3/pkg/zitadel"
+)
+
+// Path of the user schema resources, relative to [resources.BasePath].
+const Path = "/user_schemas"
+
+// Authenticator is a type of authentication possible for the users of a schema.
+type Authenticator string
+
+const (
+	AuthenticatorUsername          Authenticator = "AUTHENTICATOR_TYPE_USERNAME"
+	AuthenticatorPassword          Authenticator = "AUTHENTICATOR_TYPE_PASSWORD"
+	AuthenticatorWebAuthN          Authenticator = "AUTHENTICATOR_TYPE_WEBAUTHN"
+	AuthenticatorTOTP              Authenticator = "AUTHENTICATOR_TYPE_TOTP"
+	AuthenticatorOTPEmail          Authenticator = "AUTHENTICATOR_TYPE_OTP_EMAIL"
+	AuthenticatorOTPSMS            Authenticator = "AUTHENTICATOR_TYPE_OTP_SMS"
+	AuthenticatorAuthenticationKey Authenticator = "AUTHENTICATOR_TYPE_AUTHENTICATION_KEY"
+	AuthenticatorIdentityProvider  Authenticator = "AUTHENTICATOR_TYPE_IDENTITY_PROVIDER"
+)
+
+// State of a user schema.
+type State string
+
+const (
+	StateActive   State = "STATE_ACTIVE"
+	StateInactive State = "STATE_INACTIVE"
+)
+
+// UserSchema is a user schema of ZITADEL.
+type UserSchema struct {
+	ID    string
+	Type  string
+	State State
+	// Revision is increased by every update of the schema.
+	Revision               uint32
+	Schema                 *Schema
+	PossibleAuthenticators []Authenticator
+	ChangeDate             time.Time
+}
+
+// Client calls the user schema service of ZITADEL.
+type Client struct {
+	resources *resources.Client
+}
+
+// New creates a [Client] for the user schema service of the instance.
+func New(ctx context.Context, zitadel *zitadel.Zitadel, auth client.TokenSourceInitializer, opts ...resources.Option) (*Client, error) {
+	c, err := resources.New(ctx, zitadel, auth, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return NewFromResources(c), nil
+}
+
+// NewFromResources creates a [Client] using an existing client of the resource API.
+func NewFromResources(c *resources.Client) *Client {
+	return &Client{resources: c}
+}
+
+// schemaConfig is the JSON representation of a user schema in the requests and responses.
+type schemaConfig struct {
+	Type                   string          `json:"type,omitempty"`
+	Schema                 *Schema         `json:"schema,omitempty"`
+	PossibleAuthenticators []Authenticator `json:"possibleAuthenticators,omitempty"`
+}
+
+type details struct {
+	ID         string    `json:"id"`
+	ChangeDate time.Time `json:"changed"`
+}
+
+// Create creates the user schema of the type and returns its ID.
+func (c *Client) Create(ctx context.Context, typ string, schema *Schema, authenticators ...Authenticator) (string, error) {
+	var resp struct {
+		Details details `json:"details"`
+	}
+	err := c.resources.Do(ctx, http.MethodPost, Path, &schemaConfig{
+		Type:                   typ,
+		Schema:                 schema,
+		PossibleAuthenticators: authenticators,
+	}, &resp)
+	return resp.Details.ID, err
+}
+
+// Update creates a new revision of the user schema with the schema and authenticators,
+// the type is only changed if not empty and the authenticators only if provided.
+// The data of existing users is not migrated, so the schema should stay compatible.
+func (c *Client) Update(ctx context.Context, id, typ string, schema *Schema, authenticators ...Authenticator) error {
+	return c.resources.Do(ctx, http.MethodPatch, Path+"/"+url.PathEscape(id), &schemaConfig{
+		Type:                   typ,
+		Schema:                 schema,
+		PossibleAuthenticators: authenticators,
+	}, nil)
+}
+
+// Get returns the current revision of the user schema.
+func (c *Client) Get(ctx context.Context, id string) (*UserSchema, error) {
+	var resp struct {
+		UserSchema struct {
+			Details  details      `json:"details"`
+			Config   schemaConfig `json:"config"`
+			State    State        `json:"state"`
+			Revision uint32       `json:"revision"`
+		} `json:"userSchema"`
+	}
+	if err := c.resources.Do(ctx, http.MethodGet, Path+"/"+url.PathEscape(id), nil, &resp); err != nil {
+		return nil, err
+	}
+	return &UserSchema{
+		ID:                     resp.UserSchema.Details.ID,
+		Type:                   resp.UserSchema.Config.Type,
+		State:                  resp.UserSchema.State,
+		Revision:               resp.UserSchema.Revision,
+		Schema:                 resp.UserSchema.Config.Schema,
+		PossibleAuthenticators: resp.UserSchema.Config.PossibleAuthenticators,
+		ChangeDate:             resp.UserSchema.Details.ChangeDate,
+	}, nil
+}
+
+// Validate validates the data of a user against the current revision of the user schema,
+// see [Schema.Validate].
+func (c *Client) Validate(ctx context.Context, id string, data any) error {
+	schema, err := c.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	return schema.Schema.Validate(data)
+}
+
+// Deactivate deactivates the user schema, no users of the type can be created anymore.
+func (c *Client) Deactivate(ctx context.Context, id string) error {
+	return c.resources.Do(ctx, http.MethodPost, Path+"/"+url.PathEscape(id)+"/_deactivate", struct{}{}, nil)
+}
+
+// Reactivate reactivates a deactivated user schema.
+func (c *Client) Reactivate(ctx context.Context, id string) error {
+	return c.resources.Do(ctx, http.MethodPost, Path+"/"+url.PathEscape(id)+"/_reactivate", struct{}{}, nil)
+}
+
+// Delete deletes the user schema.
+func (c *Client) Delete(ctx context.Context, id string) error {
+	return c.resources.Do(ctx, http.MethodDelete, Path+"/"+url.PathEscape(id), nil, nil)
+}
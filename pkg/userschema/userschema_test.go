@@ -0,0 +1,158 @@
+package userschema
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/resources"
+	"github.com/zitadel/zitadel-go/v3/pkg/resources/resourcestest"
+)
+
+func employeeSchema() *Schema {
+	return Object(
+		Prop("name", String().WithMinLength(1).WithMaxLength(10)).Require(),
+		Prop("email", String().WithFormat("email")),
+		Prop("age", Integer().WithRange(18, 99)),
+		Prop("department", String().WithEnum("sales", "support")),
+		Prop("phones", Array(String().WithPattern(`^\+[0-9]+$`))),
+	).WithoutAdditionalProperties()
+}
+
+func TestSchema_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     any
+		wantErrs []FieldError
+	}{
+		{
+			name: "valid map",
+			data: map[string]any{"name": "alice", "email": "alice@example.com", "age": 30, "department": "sales", "phones": []string{"+41"}},
+		},
+		{
+			name: "valid struct",
+			data: struct {
+				Name string `json:"name"`
+				Age  int    `json:"age,omitempty"`
+			}{Name: "bob"},
+		},
+		{
+			name:     "not an object",
+			data:     "alice",
+			wantErrs: []FieldError{{Path: "", Message: "must be of type object"}},
+		},
+		{
+			name: "violations",
+			data: map[string]any{"email": "invalid", "age": 17.5, "department": "hr", "phones": []any{"+41", "041"}, "other": true},
+			wantErrs: []FieldError{
+				{Path: "/name", Message: "is required"},
+				{Path: "/age", Message: "must be of type integer"},
+				{Path: "/department", Message: "must be one of [sales support]"},
+				{Path: "/email", Message: "must be a valid email"},
+				{Path: "/other", Message: "is not allowed"},
+				{Path: "/phones/1", Message: `must match the pattern "^\\+[0-9]+$"`},
+			},
+		},
+		{
+			name: "length and range",
+			data: map[string]any{"name": "abcdefghijk", "age": 100},
+			wantErrs: []FieldError{
+				{Path: "/age", Message: "must be at most 99"},
+				{Path: "/name", Message: "must be at most 10 characters long"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := employeeSchema().Validate(tt.data)
+			if tt.wantErrs == nil {
+				require.NoError(t, err)
+				return
+			}
+			var validationErr *ValidationError
+			require.ErrorAs(t, err, &validationErr)
+			assert.Equal(t, tt.wantErrs, validationErr.Errors)
+		})
+	}
+}
+
+func TestSchema_ValidateJSON(t *testing.T) {
+	require.NoError(t, employeeSchema().ValidateJSON([]byte(`{"name":"alice","age":42}`)))
+	require.Error(t, employeeSchema().ValidateJSON([]byte(`{"age":42}`)))
+	require.Error(t, employeeSchema().ValidateJSON([]byte(`{`)))
+}
+
+func TestSchema_MarshalJSON(t *testing.T) {
+	schema := Object(Prop("name", String().WithPermission(AccessReadWrite, AccessRead)).Require()).WithDraft()
+	data, err := json.Marshal(schema)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"type": "object",
+		"properties": {"name": {"type": "string", "urn:zitadel:schema:permission": {"owner": "rw", "self": "r"}}},
+		"required": ["name"]
+	}`, string(data))
+}
+
+func testClient(t *testing.T, handler http.HandlerFunc) *Client {
+	return NewFromResources(resources.NewFromClient(resourcestest.NewClient(t, handler)))
+}
+
+func TestClient_Create(t *testing.T) {
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, resources.BasePath+Path, r.URL.Path)
+		assert.Equal(t, "Bearer "+resourcestest.Token, r.Header.Get("Authorization"))
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{
+			"type": "employee",
+			"schema": {"type": "object", "properties": {"name": {"type": "string"}}},
+			"possibleAuthenticators": ["AUTHENTICATOR_TYPE_USERNAME"]
+		}`, string(body))
+		w.Write([]byte(`{"details":{"id":"schema1"}}`))
+	})
+	id, err := c.Create(context.Background(), "employee", Object(Prop("name", String())), AuthenticatorUsername)
+	require.NoError(t, err)
+	assert.Equal(t, "schema1", id)
+}
+
+func TestClient_Get(t *testing.T) {
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		if r.URL.Path != resources.BasePath+Path+"/schema1" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"code":5,"message":"schema not found"}`))
+			return
+		}
+		w.Write([]byte(`{"userSchema":{
+			"details":{"id":"schema1"},
+			"config":{"type":"employee","schema":{"type":"object","required":["name"]},"possibleAuthenticators":["AUTHENTICATOR_TYPE_PASSWORD"]},
+			"state":"STATE_ACTIVE",
+			"revision":2
+		}}`))
+	})
+	schema, err := c.Get(context.Background(), "schema1")
+	require.NoError(t, err)
+	assert.Equal(t, &UserSchema{
+		ID:                     "schema1",
+		Type:                   "employee",
+		State:                  StateActive,
+		Revision:               2,
+		Schema:                 &Schema{Type: TypeObject, Required: []string{"name"}},
+		PossibleAuthenticators: []Authenticator{AuthenticatorPassword},
+	}, schema)
+
+	require.Error(t, c.Validate(context.Background(), "schema1", map[string]any{}))
+
+	_, err = c.Get(context.Background(), "unknown")
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	assert.Equal(t, "schema not found", status.Convert(err).Message())
+}
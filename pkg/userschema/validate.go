@@ -0,0 +1,252 @@
+package userschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// FieldError is a violation of the schema.
+type FieldError struct {
+	// Path is the JSON pointer of the invalid value, e.g. `/addresses/0/city`, empty for the root.
+	Path    string
+	Message string
+}
+
+func (e FieldError) String() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return e.Path + ": " + e.Message
+}
+
+// ValidationError is returned if the data violates the schema, it contains all violations.
+type ValidationError struct {
+	Errors []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		messages[i] = err.String()
+	}
+	return "invalid user data: " + strings.Join(messages, "; ")
+}
+
+// ValidateJSON validates the JSON encoded data against the schema, see [Schema.Validate].
+func (s *Schema) ValidateJSON(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return err
+	}
+	return s.Validate(value)
+}
+
+// Validate validates the data (e.g. a struct or map) against the schema before it's sent to ZITADEL,
+// a [*ValidationError] is returned for violations. The data is converted to JSON first,
+// so the JSON tags of structs apply.
+func (s *Schema) Validate(data any) error {
+	value, err := normalize(data)
+	if err != nil {
+		return err
+	}
+	v := &validator{}
+	v.validate(s, value, "")
+	if len(v.errors) > 0 {
+		return &ValidationError{Errors: v.errors}
+	}
+	return nil
+}
+
+// normalize converts the data into its JSON representation (map[string]any, []any, string, json.Number, bool or nil).
+func normalize(data any) (any, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var value any
+	err = decoder.Decode(&value)
+	return value, err
+}
+
+type validator struct {
+	errors []FieldError
+}
+
+func (v *validator) fail(path, format string, args ...any) {
+	v.errors = append(v.errors, FieldError{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) validate(s *Schema, value any, path string) {
+	if s == nil {
+		return
+	}
+	if s.Type != "" && !hasType(value, s.Type) {
+		v.fail(path, "must be of type %s", s.Type)
+		return
+	}
+	if len(s.Enum) > 0 && !inEnum(s.Enum, value) {
+		v.fail(path, "must be one of %v", s.Enum)
+	}
+	switch value := value.(type) {
+	case map[string]any:
+		v.validateObject(s, value, path)
+	case []any:
+		v.validateArray(s, value, path)
+	case string:
+		v.validateString(s, value, path)
+	case json.Number:
+		v.validateNumber(s, value, path)
+	}
+}
+
+func (v *validator) validateObject(s *Schema, value map[string]any, path string) {
+	for _, name := range s.Required {
+		if _, ok := value[name]; !ok {
+			v.fail(path+"/"+name, "is required")
+		}
+	}
+	names := make([]string, 0, len(value))
+	for name := range value {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		property, ok := s.Properties[name]
+		if !ok {
+			if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+				v.fail(path+"/"+name, "is not allowed")
+			}
+			continue
+		}
+		v.validate(property, value[name], path+"/"+name)
+	}
+}
+
+func (v *validator) validateArray(s *Schema, value []any, path string) {
+	if s.MinItems != nil && len(value) < *s.MinItems {
+		v.fail(path, "must have at least %d items", *s.MinItems)
+	}
+	if s.MaxItems != nil && len(value) > *s.MaxItems {
+		v.fail(path, "must have at most %d items", *s.MaxItems)
+	}
+	for i, item := range value {
+		v.validate(s.Items, item, fmt.Sprintf("%s/%d", path, i))
+	}
+}
+
+func (v *validator) validateString(s *Schema, value, path string) {
+	length := utf8.RuneCountInString(value)
+	if s.MinLength != nil && length < *s.MinLength {
+		v.fail(path, "must be at least %d characters long", *s.MinLength)
+	}
+	if s.MaxLength != nil && length > *s.MaxLength {
+		v.fail(path, "must be at most %d characters long", *s.MaxLength)
+	}
+	if s.Pattern != "" {
+		pattern, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			v.fail(path, "invalid pattern %q in schema: %v", s.Pattern, err)
+		} else if !pattern.MatchString(value) {
+			v.fail(path, "must match the pattern %q", s.Pattern)
+		}
+	}
+	if !validFormat(s.Format, value) {
+		v.fail(path, "must be a valid %s", s.Format)
+	}
+}
+
+func (v *validator) validateNumber(s *Schema, value json.Number, path string) {
+	number, err := value.Float64()
+	if err != nil {
+		v.fail(path, "must be a number")
+		return
+	}
+	if s.Minimum != nil && number < *s.Minimum {
+		v.fail(path, "must be at least %v", *s.Minimum)
+	}
+	if s.Maximum != nil && number > *s.Maximum {
+		v.fail(path, "must be at most %v", *s.Maximum)
+	}
+}
+
+func hasType(value any, typ Type) bool {
+	switch value := value.(type) {
+	case nil:
+		return typ == TypeNull
+	case map[string]any:
+		return typ == TypeObject
+	case []any:
+		return typ == TypeArray
+	case string:
+		return typ == TypeString
+	case bool:
+		return typ == TypeBoolean
+	case json.Number:
+		if typ == TypeNumber {
+			return true
+		}
+		number, err := value.Float64()
+		return typ == TypeInteger && err == nil && number == math.Trunc(number)
+	}
+	return false
+}
+
+func inEnum(enum []any, value any) bool {
+	for _, allowed := range enum {
+		normalized, err := normalize(allowed)
+		if err != nil {
+			continue
+		}
+		if a, ok := normalized.(json.Number); ok {
+			if b, ok := value.(json.Number); ok {
+				x, _ := a.Float64()
+				y, _ := b.Float64()
+				if x == y {
+					return true
+				}
+			}
+			continue
+		}
+		if reflect.DeepEqual(normalized, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func validFormat(format, value string) bool {
+	var err error
+	switch format {
+	case "email":
+		var address *mail.Address
+		address, err = mail.ParseAddress(value)
+		if err == nil && address.Address != value {
+			return false
+		}
+	case "date":
+		_, err = time.Parse(time.DateOnly, value)
+	case "date-time":
+		_, err = time.Parse(time.RFC3339, value)
+	case "uri":
+		var uri *url.URL
+		uri, err = url.Parse(value)
+		if err == nil && !uri.IsAbs() {
+			return false
+		}
+	}
+	return err == nil
+}
@@ -0,0 +1,45 @@
+package verify
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/internal/expiring"
+)
+
+// NonceStore stores the nonces issued by the [SessionExchange] until they are used or expire,
+// e.g. in a database shared by all instances of the backend.
+type NonceStore interface {
+	// Add stores the issued nonce until the expiration.
+	Add(ctx context.Context, nonce string, expiration time.Time) error
+	// Consume removes the nonce and returns whether it was stored and not expired.
+	// It must be atomic, so a nonce can only be consumed once.
+	Consume(ctx context.Context, nonce string) (bool, error)
+}
+
+// MemoryNonceStore is an in-memory [NonceStore], e.g. for tests or single process setups.
+type MemoryNonceStore struct {
+	mu     sync.Mutex
+	nonces *expiring.Set
+}
+
+// NewMemoryNonceStore creates an empty [MemoryNonceStore].
+func NewMemoryNonceStore() *MemoryNonceStore {
+	return &MemoryNonceStore{nonces: expiring.New()}
+}
+
+// Add implements [NonceStore].
+func (s *MemoryNonceStore) Add(_ context.Context, nonce string, expiration time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nonces.Add(nonce, expiration)
+	return nil
+}
+
+// Consume implements [NonceStore].
+func (s *MemoryNonceStore) Consume(_ context.Context, nonce string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.nonces.Remove(nonce), nil
+}
@@ -0,0 +1,199 @@
+// Package verify allows native apps (e.g. mobile apps), which authenticated the user directly with ZITADEL,
+// to sign in to their backend by exchanging the received ID token into a backend session.
+//
+// The backend issues a single-use nonce ([SessionExchange.Nonce]), which the app sends in its authentication request.
+// On the exchange, the nonce of the ID token is consumed, so each ID token can only be exchanged once.
+//
+// ID tokens can also be verified without a session, using [Verifier.IDTokenForAudience].
+package verify
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zitadel/oidc/v3/pkg/oidc"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/authentication"
+	openid "github.com/zitadel/zitadel-go/v3/pkg/authentication/oidc"
+	"github.com/zitadel/zitadel-go/v3/pkg/oidc/verifier"
+	"github.com/zitadel/zitadel-go/v3/pkg/zitadel"
+)
+
+// DefaultNonceLifetime is the duration an issued nonce can be used for an exchange.
+const DefaultNonceLifetime = 10 * time.Minute
+
+var (
+	ErrEmptyToken   = errors.New("token is empty")
+	ErrMissingNonce = errors.New("nonce is required")
+	// ErrInvalidNonce is returned if the nonce of the ID token was not issued by the backend, has expired or has already been used.
+	ErrInvalidNonce = errors.New("nonce is unknown or already used")
+)
+
+// Verifier verifies ID tokens issued by ZITADEL to other clients, e.g. the ones obtained by a native app.
+type Verifier struct {
+	verifier *verifier.Verifier
+}
+
+// New creates a [Verifier] for the provided ZITADEL instance, the tokens are verified locally by a [verifier.Verifier].
+// Pass e.g. [verifier.WithHTTPClient] to serve the discovery and keys from disk during an outage (see diskcache.NewClient).
+func New(ctx context.Context, zitadel *zitadel.Zitadel, options ...verifier.Option) (*Verifier, error) {
+	v, err := verifier.New(ctx, zitadel, options...)
+	if err != nil {
+		return nil, err
+	}
+	return &Verifier{verifier: v}, nil
+}
+
+type idTokenChecks struct {
+	nonce string
+}
+
+// IDTokenOption allows additional checks of the ID token, e.g. [WithNonce].
+type IDTokenOption func(*idTokenChecks)
+
+// WithNonce requires the ID token to contain the provided nonce, which the app generated when starting the authentication.
+// It prevents replay of ID tokens issued for other sign-ins.
+func WithNonce(nonce string) IDTokenOption {
+	return func(c *idTokenChecks) {
+		c.nonce = nonce
+	}
+}
+
+// IDTokenForAudience verifies the signature and claims of the ID token (issuer, expiration, ...)
+// and ensures it was issued for the provided clientID (audience).
+func (v *Verifier) IDTokenForAudience(ctx context.Context, rawIDToken, clientID string, options ...IDTokenOption) (*oidc.IDTokenClaims, error) {
+	if rawIDToken == "" {
+		return nil, ErrEmptyToken
+	}
+	checks := new(idTokenChecks)
+	for _, option := range options {
+		option(checks)
+	}
+	claims, err := v.verifier.VerifyIDToken(ctx, rawIDToken, checks.nonce)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(claims.Audience, clientID) {
+		return nil, verifier.ErrInvalidAudience
+	}
+	// the authorized party must be the client, if the token was issued for multiple audiences
+	if claims.AuthorizedParty != "" && claims.AuthorizedParty != clientID ||
+		claims.AuthorizedParty == "" && len(claims.Audience) > 1 {
+		return nil, verifier.ErrInvalidAudience
+	}
+	return claims, nil
+}
+
+// SessionContext is the authentication context created by the [SessionExchange].
+// It's the same type as used by the default authentication ([openid.DefaultAuthentication]),
+// so the session can be used with the [authentication.Authenticator] and its middleware as well.
+type SessionContext = openid.UserInfoContext[*oidc.IDTokenClaims, *oidc.UserInfo]
+
+// SessionExchange exchanges ID tokens issued to the clientID of the native app into backend sessions.
+type SessionExchange struct {
+	verifier        *verifier.Verifier
+	nonces          NonceStore
+	nonceLifetime   time.Duration
+	sessions        authentication.Sessions[*SessionContext]
+	verifierOptions []verifier.Option
+}
+
+// Option allows customization of the [SessionExchange].
+type Option func(*SessionExchange)
+
+// WithNonceStore stores the issued nonces in the provided [NonceStore] (default [NewMemoryNonceStore]),
+// e.g. one shared by all instances of the backend.
+func WithNonceStore(nonces NonceStore) Option {
+	return func(e *SessionExchange) {
+		e.nonces = nonces
+	}
+}
+
+// WithNonceLifetime sets the duration an issued nonce can be used (default 10 minutes).
+func WithNonceLifetime(lifetime time.Duration) Option {
+	return func(e *SessionExchange) {
+		e.nonceLifetime = lifetime
+	}
+}
+
+// WithVerifierOptions passes the options to the [verifier.Verifier] of the ID tokens,
+// e.g. [verifier.WithHTTPClient] to serve the discovery and keys from disk during an outage (see diskcache.NewClient).
+func WithVerifierOptions(options ...verifier.Option) Option {
+	return func(e *SessionExchange) {
+		e.verifierOptions = append(e.verifierOptions, options...)
+	}
+}
+
+// NewSessionExchange creates a [SessionExchange] accepting ID tokens issued to the clientID of the native app.
+// The ID tokens are verified locally by a [verifier.Verifier], whose background key refresh runs until the context is done.
+func NewSessionExchange(ctx context.Context, zitadel *zitadel.Zitadel, clientID string, sessions authentication.Sessions[*SessionContext], options ...Option) (*SessionExchange, error) {
+	e := &SessionExchange{
+		nonceLifetime: DefaultNonceLifetime,
+		sessions:      sessions,
+	}
+	for _, option := range options {
+		option(e)
+	}
+	if e.nonces == nil {
+		e.nonces = NewMemoryNonceStore()
+	}
+	// the audience is set last, so it can't be overwritten by the options
+	v, err := verifier.New(ctx, zitadel, append(e.verifierOptions, verifier.WithAudience(clientID))...)
+	if err != nil {
+		return nil, err
+	}
+	e.verifier = v
+	return e, nil
+}
+
+// Nonce issues a new single-use nonce, which the app must send in its authentication request.
+func (e *SessionExchange) Nonce(ctx context.Context) (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	nonce := base64.RawURLEncoding.EncodeToString(b)
+	if err := e.nonces.Add(ctx, nonce, time.Now().Add(e.nonceLifetime)); err != nil {
+		return "", err
+	}
+	return nonce, nil
+}
+
+// Exchange verifies the ID token, consumes its nonce issued by [SessionExchange.Nonce] and stores a new session.
+// The returned id can be passed back to the app to identify the session on subsequent calls.
+func (e *SessionExchange) Exchange(ctx context.Context, rawIDToken string) (id string, session *SessionContext, err error) {
+	if rawIDToken == "" {
+		return "", nil, ErrEmptyToken
+	}
+	claims, err := e.verifier.VerifyIDToken(ctx, rawIDToken, "")
+	if err != nil {
+		return "", nil, err
+	}
+	if claims.Nonce == "" {
+		return "", nil, ErrMissingNonce
+	}
+	ok, err := e.nonces.Consume(ctx, claims.Nonce)
+	if err != nil {
+		return "", nil, err
+	}
+	if !ok {
+		return "", nil, ErrInvalidNonce
+	}
+	session = &SessionContext{
+		UserInfo: claims.GetUserInfo(),
+		Tokens: &oidc.Tokens[*oidc.IDTokenClaims]{
+			IDToken:       rawIDToken,
+			IDTokenClaims: claims,
+		},
+	}
+	id = uuid.NewString()
+	if err = e.sessions.Set(id, session); err != nil {
+		return "", nil, err
+	}
+	return id, session, nil
+}
@@ -0,0 +1,261 @@
+package verify
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/authentication"
+	"github.com/zitadel/zitadel-go/v3/pkg/oidc/verifier"
+	"github.com/zitadel/zitadel-go/v3/pkg/zitadel"
+)
+
+// newTestInstance starts a test instance serving the public key of the returned key.
+func newTestInstance(t *testing.T) (*zitadel.Zitadel, string, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":   server.URL,
+			"jwks_uri": server.URL + "/oauth/v2/keys",
+		})
+	})
+	mux.HandleFunc("/oauth/v2/keys", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+			{Key: key.Public(), KeyID: "key", Algorithm: string(jose.ES256), Use: "sig"},
+		}})
+	})
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	return zitadel.New(u.Hostname(), zitadel.WithInsecure(u.Port())), server.URL, key
+}
+
+// newTestExchange creates a [SessionExchange] for the client `app` of a test instance serving the public key of the returned key.
+func newTestExchange(t *testing.T, options ...Option) (*SessionExchange, *authentication.InMemorySessions[*SessionContext], string, *ecdsa.PrivateKey) {
+	z, issuer, key := newTestInstance(t)
+	sessions := authentication.NewInMemorySessions[*SessionContext]()
+	options = append([]Option{WithVerifierOptions(verifier.WithRefreshInterval(0))}, options...)
+	e, err := NewSessionExchange(context.Background(), z, "app", sessions, options...)
+	require.NoError(t, err)
+	return e, sessions, issuer, key
+}
+
+func signIDToken(t *testing.T, key *ecdsa.PrivateKey, claims map[string]any) string {
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.ES256, Key: jose.JSONWebKey{Key: key, KeyID: "key"}}, nil)
+	require.NoError(t, err)
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+	jws, err := signer.Sign(payload)
+	require.NoError(t, err)
+	token, err := jws.CompactSerialize()
+	require.NoError(t, err)
+	return token
+}
+
+func TestVerifier_IDTokenForAudience(t *testing.T) {
+	z, issuer, key := newTestInstance(t)
+	v, err := New(context.Background(), z, verifier.WithRefreshInterval(0))
+	require.NoError(t, err)
+	now := time.Now()
+	idToken := func(modify func(map[string]any)) string {
+		c := map[string]any{
+			"iss":   issuer,
+			"sub":   "user1",
+			"aud":   []string{"app"},
+			"exp":   now.Add(time.Hour).Unix(),
+			"iat":   now.Unix(),
+			"nonce": "nonce",
+		}
+		if modify != nil {
+			modify(c)
+		}
+		return signIDToken(t, key, c)
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		options []IDTokenOption
+		wantErr error
+	}{
+		{
+			name:  "valid",
+			token: idToken(nil),
+		},
+		{
+			name:    "valid with nonce",
+			token:   idToken(nil),
+			options: []IDTokenOption{WithNonce("nonce")},
+		},
+		{
+			name:  "multiple audiences, authorized party",
+			token: idToken(func(c map[string]any) { c["aud"] = []string{"app", "project"}; c["azp"] = "app" }),
+		},
+		{
+			name:    "empty",
+			token:   "",
+			wantErr: ErrEmptyToken,
+		},
+		{
+			name:    "audience mismatch",
+			token:   idToken(func(c map[string]any) { c["aud"] = []string{"other-app"} }),
+			wantErr: verifier.ErrInvalidAudience,
+		},
+		{
+			name:    "multiple audiences, other authorized party",
+			token:   idToken(func(c map[string]any) { c["aud"] = []string{"app", "other-app"}; c["azp"] = "other-app" }),
+			wantErr: verifier.ErrInvalidAudience,
+		},
+		{
+			name:    "multiple audiences, missing authorized party",
+			token:   idToken(func(c map[string]any) { c["aud"] = []string{"app", "other-app"} }),
+			wantErr: verifier.ErrInvalidAudience,
+		},
+		{
+			name:    "nonce mismatch",
+			token:   idToken(nil),
+			options: []IDTokenOption{WithNonce("other")},
+			wantErr: verifier.ErrInvalidNonce,
+		},
+		{
+			name:    "expired",
+			token:   idToken(func(c map[string]any) { c["exp"] = now.Add(-time.Hour).Unix() }),
+			wantErr: verifier.ErrTokenExpired,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := v.IDTokenForAudience(context.Background(), tt.token, "app", tt.options...)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "user1", claims.Subject)
+		})
+	}
+}
+
+func TestSessionExchange_Exchange(t *testing.T) {
+	e, sessions, issuer, key := newTestExchange(t)
+	now := time.Now()
+	idToken := func(nonce string, modify func(map[string]any)) string {
+		c := map[string]any{
+			"iss":   issuer,
+			"sub":   "user1",
+			"aud":   []string{"app"},
+			"exp":   now.Add(time.Hour).Unix(),
+			"iat":   now.Unix(),
+			"nonce": nonce,
+			"email": "user1@example.com",
+		}
+		if modify != nil {
+			modify(c)
+		}
+		return signIDToken(t, key, c)
+	}
+	issue := func() string {
+		nonce, err := e.Nonce(context.Background())
+		require.NoError(t, err)
+		return nonce
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr error
+	}{
+		{
+			name:  "valid",
+			token: idToken(issue(), nil),
+		},
+		{
+			name:    "empty",
+			token:   "",
+			wantErr: ErrEmptyToken,
+		},
+		{
+			name:    "audience mismatch",
+			token:   idToken(issue(), func(c map[string]any) { c["aud"] = []string{"other-app"} }),
+			wantErr: verifier.ErrInvalidAudience,
+		},
+		{
+			name:    "expired",
+			token:   idToken(issue(), func(c map[string]any) { c["exp"] = now.Add(-time.Hour).Unix() }),
+			wantErr: verifier.ErrTokenExpired,
+		},
+		{
+			name:    "missing nonce",
+			token:   idToken("", nil),
+			wantErr: ErrMissingNonce,
+		},
+		{
+			name:    "nonce mismatch",
+			token:   idToken("not-issued-by-the-backend", nil),
+			wantErr: ErrInvalidNonce,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, session, err := e.Exchange(context.Background(), tt.token)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "user1", session.UserInfo.Subject)
+			assert.Equal(t, "user1@example.com", session.UserInfo.Email)
+			stored, err := sessions.Get(id)
+			require.NoError(t, err)
+			assert.Same(t, session, stored)
+		})
+	}
+}
+
+func TestSessionExchange_Exchange_replay(t *testing.T) {
+	e, _, issuer, key := newTestExchange(t)
+	nonce, err := e.Nonce(context.Background())
+	require.NoError(t, err)
+	token := signIDToken(t, key, map[string]any{
+		"iss":   issuer,
+		"sub":   "user1",
+		"aud":   []string{"app"},
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"nonce": nonce,
+	})
+
+	_, _, err = e.Exchange(context.Background(), token)
+	require.NoError(t, err)
+	_, _, err = e.Exchange(context.Background(), token)
+	assert.ErrorIs(t, err, ErrInvalidNonce, "the nonce was consumed by the first exchange")
+}
+
+func TestSessionExchange_Exchange_expiredNonce(t *testing.T) {
+	e, _, issuer, key := newTestExchange(t, WithNonceLifetime(-time.Second))
+	nonce, err := e.Nonce(context.Background())
+	require.NoError(t, err)
+	token := signIDToken(t, key, map[string]any{
+		"iss":   issuer,
+		"sub":   "user1",
+		"aud":   []string{"app"},
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"nonce": nonce,
+	})
+
+	_, _, err = e.Exchange(context.Background(), token)
+	assert.ErrorIs(t, err, ErrInvalidNonce)
+}
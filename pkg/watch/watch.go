@@ -0,0 +1,139 @@
+// Package watch polls resources of ZITADEL and notifies about their changes,
+// detected by the advancing sequence (resp. change date) of their object details.
+// This enables cache invalidation without consumers comparing the details themselves:
+//
+//	changes := watch.Resource(ctx, func(ctx context.Context) (*management.GetProjectByIDResponse, watch.Details, error) {
+//		resp, err := c.ManagementService().GetProjectByID(ctx, &management.GetProjectByIDRequest{Id: projectID})
+//		return resp, resp.GetProject().GetDetails(), err
+//	}, time.Minute)
+//	for change := range changes {
+//		cache.Invalidate(projectID)
+//	}
+package watch
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Details are the object details returned by the reads of ZITADEL,
+// e.g. the ObjectDetails of the v1 or the Details of the v2 API. They might be nil.
+type Details interface {
+	GetSequence() uint64
+	GetChangeDate() *timestamppb.Timestamp
+}
+
+// Getter reads the resource and its details.
+type Getter[T any] func(ctx context.Context) (T, Details, error)
+
+// Change is emitted by [Resource] for every detected state of the resource.
+type Change[T any] struct {
+	Value      T
+	Sequence   uint64
+	ChangeDate time.Time
+	// Initial is set for the state read when the watch started.
+	Initial bool
+	// Deleted is set if the getter returned a NotFound error, the Value is the zero value.
+	Deleted bool
+}
+
+// Option allows customization of the watch.
+type Option func(*config)
+
+type config struct {
+	skipInitial bool
+	onError     func(error)
+}
+
+// WithoutInitial does not emit the state read when the watch started, only later changes.
+func WithoutInitial() Option {
+	return func(c *config) {
+		c.skipInitial = true
+	}
+}
+
+// WithErrorHandler is called for errors of the getter (other than NotFound), which are retried with the next poll.
+func WithErrorHandler(onError func(error)) Option {
+	return func(c *config) {
+		c.onError = onError
+	}
+}
+
+// Resource polls the getter in the interval and emits a [Change] whenever the sequence or the change date
+// of the resource advances, resp. when it's deleted or (re-)created.
+// A slow consumer doesn't miss the latest state, since the resource is only polled again after a change was received.
+// The channel is closed when the context is done.
+func Resource[T any](ctx context.Context, getter Getter[T], interval time.Duration, opts ...Option) <-chan Change[T] {
+	c := &config{onError: func(error) {}}
+	for _, opt := range opts {
+		opt(c)
+	}
+	ch := make(chan Change[T])
+	go run(ctx, getter, interval, c, ch)
+	return ch
+}
+
+func run[T any](ctx context.Context, getter Getter[T], interval time.Duration, c *config, ch chan<- Change[T]) {
+	defer close(ch)
+	var last *Change[T]
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		change, err := read(ctx, getter)
+		switch {
+		case err != nil:
+			if ctx.Err() != nil {
+				return
+			}
+			c.onError(err)
+		case last == nil && c.skipInitial:
+			last = change
+		case last == nil || advanced(last, change):
+			change.Initial = last == nil
+			last = change
+			select {
+			case <-ctx.Done():
+				return
+			case ch <- *change:
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func read[T any](ctx context.Context, getter Getter[T]) (*Change[T], error) {
+	value, details, err := getter(ctx)
+	if status.Code(err) == codes.NotFound {
+		return &Change[T]{Deleted: true}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	change := &Change[T]{Value: value}
+	if details != nil {
+		change.Sequence = details.GetSequence()
+		if date := details.GetChangeDate(); date != nil {
+			change.ChangeDate = date.AsTime()
+		}
+	}
+	return change, nil
+}
+
+// advanced returns if the current state is newer than the last one.
+func advanced[T any](last, current *Change[T]) bool {
+	if last.Deleted || current.Deleted {
+		return last.Deleted != current.Deleted
+	}
+	if current.Sequence != last.Sequence {
+		return current.Sequence > last.Sequence
+	}
+	return current.ChangeDate.After(last.ChangeDate)
+}
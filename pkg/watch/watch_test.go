@@ -0,0 +1,88 @@
+package watch
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/object"
+)
+
+type state struct {
+	name     string
+	sequence uint64
+	err      error
+}
+
+// fakeResource returns the states in order, the last one repeatedly.
+type fakeResource struct {
+	mu     sync.Mutex
+	states []state
+}
+
+func (f *fakeResource) get(context.Context) (string, Details, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s := f.states[0]
+	if len(f.states) > 1 {
+		f.states = f.states[1:]
+	}
+	return s.name, &object.ObjectDetails{Sequence: s.sequence}, s.err
+}
+
+func collect(t *testing.T, ch <-chan Change[string], n int) []Change[string] {
+	t.Helper()
+	var changes []Change[string]
+	for len(changes) < n {
+		select {
+		case change := <-ch:
+			changes = append(changes, change)
+		case <-time.After(time.Second):
+			t.Fatalf("received %d of %d changes", len(changes), n)
+		}
+	}
+	return changes
+}
+
+func TestResource(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var errs []error
+	resource := &fakeResource{states: []state{
+		{name: "a", sequence: 1},
+		{name: "a", sequence: 1},
+		{err: errors.New("unavailable")},
+		{name: "b", sequence: 3},
+		{err: status.Error(codes.NotFound, "not found")},
+		{name: "c", sequence: 1},
+	}}
+	ch := Resource(ctx, resource.get, time.Millisecond, WithErrorHandler(func(err error) { errs = append(errs, err) }))
+
+	changes := collect(t, ch, 4)
+	assert.Equal(t, []Change[string]{
+		{Value: "a", Sequence: 1, Initial: true},
+		{Value: "b", Sequence: 3},
+		{Deleted: true},
+		{Value: "c", Sequence: 1},
+	}, changes)
+	require.Len(t, errs, 1)
+
+	cancel()
+	for range ch {
+	}
+}
+
+func TestResource_WithoutInitial(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	resource := &fakeResource{states: []state{{name: "a", sequence: 1}, {name: "b", sequence: 2}}}
+	changes := collect(t, Resource(ctx, resource.get, time.Millisecond, WithoutInitial()), 1)
+	assert.Equal(t, []Change[string]{{Value: "b", Sequence: 2}}, changes)
+}
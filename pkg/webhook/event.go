@@ -0,0 +1,108 @@
+package webhook
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+)
+
+// Event is the envelope of an event sent to a target of an event execution.
+type Event struct {
+	AggregateID   string    `json:"aggregateID"`
+	AggregateType string    `json:"aggregateType"`
+	ResourceOwner string    `json:"resourceOwner"`
+	InstanceID    string    `json:"instanceID"`
+	Version       string    `json:"version"`
+	Sequence      uint64    `json:"sequence"`
+	EventType     string    `json:"event_type"`
+	CreatedAt     time.Time `json:"created_at"`
+	// UserID is the ID of the user who caused the event.
+	UserID string `json:"userID"`
+	// Payload is the event specific data, use [Decode] or the typed registration (see [On]) to parse it.
+	Payload json.RawMessage `json:"event_payload"`
+}
+
+// Decode parses the payload of the event into T, e.g. [HumanAdded].
+func Decode[T any](event *Event) (*T, error) {
+	payload := new(T)
+	data, err := event.payload()
+	if err != nil || len(data) == 0 {
+		return payload, err
+	}
+	if err = json.Unmarshal(data, payload); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+// payload returns the JSON payload, which is base64 encoded by some versions of ZITADEL.
+func (e *Event) payload() ([]byte, error) {
+	if len(e.Payload) == 0 || e.Payload[0] != '"' {
+		return e.Payload, nil
+	}
+	var encoded string
+	if err := json.Unmarshal(e.Payload, &encoded); err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.DecodeString(encoded)
+}
+
+// Event types of the typed payloads.
+const (
+	EventTypeHumanAdded          = "user.human.added"
+	EventTypeHumanSelfRegistered = "user.human.selfregistered"
+	EventTypeEmailChanged        = "user.human.email.changed"
+	EventTypeEmailVerified       = "user.human.email.verified"
+	EventTypePhoneChanged        = "user.human.phone.changed"
+	EventTypeUserNameChanged     = "user.username.changed"
+	EventTypeUserLocked          = "user.locked"
+	EventTypeUserDeactivated     = "user.deactivated"
+	EventTypeUserRemoved         = "user.removed"
+	EventTypeOrgAdded            = "org.added"
+	EventTypeOrgRemoved          = "org.removed"
+)
+
+// HumanAdded is the payload of the [EventTypeHumanAdded] and [EventTypeHumanSelfRegistered] events.
+type HumanAdded struct {
+	UserName          string `json:"userName"`
+	FirstName         string `json:"firstName"`
+	LastName          string `json:"lastName"`
+	NickName          string `json:"nickName"`
+	DisplayName       string `json:"displayName"`
+	PreferredLanguage string `json:"preferredLanguage"`
+	Email             string `json:"email"`
+	IsEmailVerified   bool   `json:"isEmailVerified"`
+	Phone             string `json:"phone"`
+	IsPhoneVerified   bool   `json:"isPhoneVerified"`
+}
+
+// EmailChanged is the payload of the [EventTypeEmailChanged] event.
+type EmailChanged struct {
+	Email string `json:"email"`
+}
+
+// EmailVerified is the payload of the [EventTypeEmailVerified] event.
+type EmailVerified struct {
+	IsEmailVerified bool `json:"isEmailVerified"`
+}
+
+// PhoneChanged is the payload of the [EventTypePhoneChanged] event.
+type PhoneChanged struct {
+	Phone string `json:"phone"`
+}
+
+// UserNameChanged is the payload of the [EventTypeUserNameChanged] event.
+type UserNameChanged struct {
+	UserName    string `json:"userName"`
+	OldUserName string `json:"oldUserName"`
+}
+
+// UserRemoved is the payload of the [EventTypeUserRemoved] event.
+type UserRemoved struct {
+	UserName string `json:"userName"`
+}
+
+// OrgAdded is the payload of the [EventTypeOrgAdded] event.
+type OrgAdded struct {
+	Name string `json:"name"`
+}
@@ -0,0 +1,133 @@
+// Package webhook receives the calls of ZITADEL Actions v2 targets of event executions.
+// The [Handler] verifies the signature of the payload (see [VerifySignature]), parses the [Event]
+// and dispatches it to the registered handler funcs per event type.
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"path"
+	"sync"
+	"time"
+)
+
+const maxBodySize = 1 << 20
+
+// HandlerFunc handles an event.
+type HandlerFunc func(ctx context.Context, event *Event) error
+
+// Handler is an [http.Handler] for the target of an event execution.
+// Events are answered with 400 if the signature is invalid or the event can't be parsed,
+// with 500 if a handler fails (so ZITADEL can retry) and with 200 otherwise, even if no handler is registered.
+type Handler struct {
+	signingKey string
+	tolerance  time.Duration
+	onError    func(event *Event, err error)
+
+	mu       sync.RWMutex
+	handlers []registration
+}
+
+type registration struct {
+	pattern string
+	handle  HandlerFunc
+}
+
+// Option allows customization of the [Handler].
+type Option func(*Handler)
+
+// WithTolerance sets the max age of the signature (default 5 minutes), a zero duration disables the check.
+func WithTolerance(tolerance time.Duration) Option {
+	return func(h *Handler) {
+		h.tolerance = tolerance
+	}
+}
+
+// WithErrorHandler sets a function called if a handler fails, e.g. for logging.
+func WithErrorHandler(onError func(event *Event, err error)) Option {
+	return func(h *Handler) {
+		h.onError = onError
+	}
+}
+
+// NewHandler creates a [Handler] verifying the payloads with the signing key of the target.
+// It returns [ErrMissingSigningKey] if the signing key is empty.
+func NewHandler(signingKey string, options ...Option) (*Handler, error) {
+	if signingKey == "" {
+		return nil, ErrMissingSigningKey
+	}
+	h := &Handler{
+		signingKey: signingKey,
+		tolerance:  DefaultTolerance,
+		onError:    func(*Event, error) {},
+	}
+	for _, option := range options {
+		option(h)
+	}
+	return h, nil
+}
+
+// HandleFunc registers the function for the events matching the pattern (see [path.Match]),
+// e.g. `user.human.added` or `user.human.*`. All matching functions are called in order of registration.
+func (h *Handler) HandleFunc(pattern string, handle HandlerFunc) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.handlers = append(h.handlers, registration{pattern: pattern, handle: handle})
+}
+
+// On registers a typed function for the events matching the pattern (see [Handler.HandleFunc]),
+// the payload is decoded into T (see [Decode]).
+func On[T any](h *Handler, pattern string, handle func(ctx context.Context, event *Event, payload *T) error) {
+	h.HandleFunc(pattern, func(ctx context.Context, event *Event) error {
+		payload, err := Decode[T](event)
+		if err != nil {
+			return err
+		}
+		return handle(ctx, event, payload)
+	})
+}
+
+// ServeHTTP implements [http.Handler]
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxBodySize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err = VerifySignature(body, r.Header.Get(SignatureHeader), h.signingKey, h.tolerance); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	event := new(Event)
+	if err = json.Unmarshal(body, event); err != nil {
+		http.Error(w, "invalid event: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err = h.dispatch(r.Context(), event); err != nil {
+		h.onError(event, err)
+		http.Error(w, "event could not be handled", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *Handler) dispatch(ctx context.Context, event *Event) error {
+	h.mu.RLock()
+	handlers := h.handlers
+	h.mu.RUnlock()
+	for _, handler := range handlers {
+		if ok, _ := path.Match(handler.pattern, event.EventType); !ok {
+			continue
+		}
+		if err := handler.handle(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	signingKey   = "signing-key"
+	humanAdded   = `{"aggregateID":"user1","aggregateType":"user","resourceOwner":"org1","event_type":"user.human.added","event_payload":{"userName":"gigi","email":"gigi@zitadel.com"}}`
+	emailChanged = `{"aggregateID":"user1","aggregateType":"user","event_type":"user.human.email.changed","event_payload":"eyJlbWFpbCI6Im5ld0B6aXRhZGVsLmNvbSJ9"}`
+)
+
+func TestHandler_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		signature  func(body string) string
+		handleErr  error
+		wantStatus int
+		wantEvents []string
+	}{
+		{
+			name:       "typed payload",
+			body:       humanAdded,
+			signature:  func(body string) string { return Sign([]byte(body), signingKey, time.Now()) },
+			wantStatus: http.StatusOK,
+			wantEvents: []string{"added:gigi@zitadel.com", "any:user.human.added"},
+		},
+		{
+			name:       "base64 payload",
+			body:       emailChanged,
+			signature:  func(body string) string { return Sign([]byte(body), signingKey, time.Now()) },
+			wantStatus: http.StatusOK,
+			wantEvents: []string{"any:user.human.email.changed", "email:new@zitadel.com"},
+		},
+		{
+			name:       "missing signature",
+			body:       humanAdded,
+			signature:  func(string) string { return "" },
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "wrong key",
+			body:       humanAdded,
+			signature:  func(body string) string { return Sign([]byte(body), "other", time.Now()) },
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "expired",
+			body:       humanAdded,
+			signature:  func(body string) string { return Sign([]byte(body), signingKey, time.Now().Add(-time.Hour)) },
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "handler error",
+			body:       humanAdded,
+			signature:  func(body string) string { return Sign([]byte(body), signingKey, time.Now()) },
+			handleErr:  errors.New("failed"),
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var events []string
+			h, err := NewHandler(signingKey)
+			require.NoError(t, err)
+			On(h, EventTypeHumanAdded, func(_ context.Context, _ *Event, payload *HumanAdded) error {
+				events = append(events, "added:"+payload.Email)
+				return tt.handleErr
+			})
+			h.HandleFunc("user.*", func(_ context.Context, event *Event) error {
+				events = append(events, "any:"+event.EventType)
+				return nil
+			})
+			On(h, EventTypeEmailChanged, func(_ context.Context, _ *Event, payload *EmailChanged) error {
+				events = append(events, "email:"+payload.Email)
+				return nil
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(tt.body))
+			req.Header.Set(SignatureHeader, tt.signature(tt.body))
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			if tt.wantStatus == http.StatusOK {
+				require.Equal(t, tt.wantEvents, events)
+			}
+		})
+	}
+}
+
+func TestNewHandler_missingSigningKey(t *testing.T) {
+	_, err := NewHandler("")
+	assert.ErrorIs(t, err, ErrMissingSigningKey)
+}
+
+func TestVerifySignature_missingSigningKey(t *testing.T) {
+	body := []byte(humanAdded)
+	// a signature computed with an empty key must not be accepted
+	err := VerifySignature(body, Sign(body, "", time.Now()), "", DefaultTolerance)
+	assert.ErrorIs(t, err, ErrMissingSigningKey)
+}
@@ -0,0 +1,88 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// SignatureHeader is the header carrying the signature of the payload sent to targets.
+	SignatureHeader = "ZITADEL-Signature"
+	// DefaultTolerance is the max age of a signature.
+	DefaultTolerance = 5 * time.Minute
+
+	signingVersion = "v1"
+)
+
+var (
+	ErrMissingSigningKey = errors.New("missing signing key")
+	ErrMissingSignature  = errors.New("missing signature header")
+	ErrInvalidSignature  = errors.New("invalid signature")
+	ErrSignatureExpired  = errors.New("signature timestamp outside of tolerance")
+)
+
+// Sign computes the value of the [SignatureHeader] for the payload, e.g. to test a handler.
+func Sign(payload []byte, signingKey string, timestamp time.Time) string {
+	return fmt.Sprintf("t=%d,%s=%s", timestamp.Unix(), signingVersion, computeSignature(payload, signingKey, timestamp.Unix()))
+}
+
+// VerifySignature checks the value of the [SignatureHeader] (`t=<timestamp>,v1=<signature>`)
+// against the payload using the signing key of the target.
+// The timestamp must not differ more than the tolerance from the current time.
+// An empty signing key is rejected with [ErrMissingSigningKey], since anyone could compute a matching signature.
+func VerifySignature(payload []byte, header, signingKey string, tolerance time.Duration) error {
+	if signingKey == "" {
+		return ErrMissingSigningKey
+	}
+	if header == "" {
+		return ErrMissingSignature
+	}
+	var timestamp int64
+	var signatures []string
+	for _, part := range strings.Split(header, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return fmt.Errorf("%w: malformed header", ErrInvalidSignature)
+		}
+		switch key {
+		case "t":
+			t, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return fmt.Errorf("%w: malformed timestamp", ErrInvalidSignature)
+			}
+			timestamp = t
+		case signingVersion:
+			signatures = append(signatures, value)
+		}
+	}
+	if timestamp == 0 || len(signatures) == 0 {
+		return fmt.Errorf("%w: malformed header", ErrInvalidSignature)
+	}
+	if tolerance > 0 {
+		age := time.Since(time.Unix(timestamp, 0))
+		if age > tolerance || age < -tolerance {
+			return ErrSignatureExpired
+		}
+	}
+	expected := computeSignature(payload, signingKey, timestamp)
+	for _, signature := range signatures {
+		if hmac.Equal([]byte(signature), []byte(expected)) {
+			return nil
+		}
+	}
+	return ErrInvalidSignature
+}
+
+func computeSignature(payload []byte, signingKey string, timestamp int64) string {
+	mac := hmac.New(sha256.New, []byte(signingKey))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,166 @@
+package zerrors
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Redaction replaces a personally identifiable value (PII) such as an email or login name in an error message.
+type Redaction func(value string) string
+
+// Mask is a [Redaction] keeping the first character of the value, e.g. `a***@e***.com` for `alice@example.com`.
+func Mask(value string) string {
+	local, domain, ok := strings.Cut(value, "@")
+	if !ok {
+		return maskPart(value)
+	}
+	host, tld := domain, ""
+	if i := strings.LastIndex(domain, "."); i > 0 {
+		host, tld = domain[:i], domain[i:]
+	}
+	return maskPart(local) + "@" + maskPart(host) + tld
+}
+
+func maskPart(s string) string {
+	for _, r := range s {
+		return string(r) + "***"
+	}
+	return "***"
+}
+
+// Hash returns a [Redaction] replacing the value by a keyed hash, e.g. `pii:3f9a1c0b72de`.
+// The same value results in the same hash, so log entries can still be correlated (e.g. all errors of a user),
+// but the value can't be guessed without the key.
+func Hash(key []byte) Redaction {
+	return func(value string) string {
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(value))
+		return "pii:" + hex.EncodeToString(mac.Sum(nil))[:12]
+	}
+}
+
+// piiFields are the fields of the requests whose values are redacted in the error messages.
+var piiFields = map[protoreflect.Name]bool{
+	"email":        true,
+	"login_name":   true,
+	"user_name":    true,
+	"username":     true,
+	"phone":        true,
+	"given_name":   true,
+	"family_name":  true,
+	"nick_name":    true,
+	"display_name": true,
+}
+
+var emailPattern = regexp.MustCompile(`[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}`)
+
+// RedactedError is an error whose message has PII redacted, e.g. for logging.
+// The original error with the full details is available as Err and through errors.As,
+// e.g. to read the unredacted message of a wrapped [Error]. errors.Is and status.Code keep working.
+type RedactedError struct {
+	Err     error
+	message string
+}
+
+func (e *RedactedError) Error() string {
+	return e.message
+}
+
+func (e *RedactedError) Unwrap() error {
+	return e.Err
+}
+
+// Redact returns a [RedactedError] of err, whose message has the values and all emails replaced by the redaction.
+// Values with less than three characters are ignored. nil is returned as is.
+func Redact(err error, redaction Redaction, values ...string) error {
+	if err == nil {
+		return nil
+	}
+	values = slices.DeleteFunc(slices.Clone(values), func(value string) bool { return len(value) < 3 })
+	// replace longer values first, so a value contained in another one doesn't break the redaction of the other
+	slices.SortFunc(values, func(a, b string) int { return len(b) - len(a) })
+	message := err.Error()
+	for _, value := range values {
+		message = strings.ReplaceAll(message, value, redaction(value))
+	}
+	message = emailPattern.ReplaceAllStringFunc(message, redaction)
+	return &RedactedError{Err: err, message: message}
+}
+
+// RedactingUnaryClientInterceptor redacts the errors of the calls using [Redact]
+// with the values of the PII fields of the request (e.g. email, login_name).
+func RedactingUnaryClientInterceptor(redaction Redaction) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		if err == nil {
+			return nil
+		}
+		var values []string
+		if m, ok := req.(proto.Message); ok {
+			values = piiValues(m.ProtoReflect(), values)
+		}
+		return Redact(err, redaction, values...)
+	}
+}
+
+// RedactingStreamClientInterceptor redacts the emails in the errors of the stream creation and its messages using [Redact].
+func RedactingStreamClientInterceptor(redaction Redaction) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			return nil, Redact(err, redaction)
+		}
+		return &redactingStream{ClientStream: stream, redaction: redaction}, nil
+	}
+}
+
+type redactingStream struct {
+	grpc.ClientStream
+	redaction Redaction
+}
+
+func (s *redactingStream) SendMsg(m any) error {
+	return Redact(s.ClientStream.SendMsg(m), s.redaction)
+}
+
+func (s *redactingStream) RecvMsg(m any) error {
+	err := s.ClientStream.RecvMsg(m)
+	// the end of the stream is checked by identity
+	if err == io.EOF {
+		return err
+	}
+	return Redact(err, s.redaction)
+}
+
+// piiValues appends the values of the PII fields of the message, including nested messages.
+func piiValues(m protoreflect.Message, values []string) []string {
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		switch {
+		case fd.IsMap():
+		case fd.Kind() == protoreflect.MessageKind && fd.IsList():
+			for i := 0; i < v.List().Len(); i++ {
+				values = piiValues(v.List().Get(i).Message(), values)
+			}
+		case fd.Kind() == protoreflect.MessageKind:
+			values = piiValues(v.Message(), values)
+		case fd.Kind() == protoreflect.StringKind && piiFields[fd.Name()] && fd.IsList():
+			for i := 0; i < v.List().Len(); i++ {
+				values = append(values, v.List().Get(i).String())
+			}
+		case fd.Kind() == protoreflect.StringKind && piiFields[fd.Name()]:
+			values = append(values, v.String())
+		}
+		return true
+	})
+	return values
+}
@@ -0,0 +1,80 @@
+package zerrors
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	user "github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/user/v2"
+)
+
+func TestMask(t *testing.T) {
+	for value, want := range map[string]string{
+		"alice@example.com":    "a***@e***.com",
+		"bob@mail.example.org": "b***@m***.org",
+		"carol":                "c***",
+		"Ölaf":                 "Ö***",
+		"":                     "***",
+	} {
+		assert.Equal(t, want, Mask(value), value)
+	}
+}
+
+func TestHash(t *testing.T) {
+	hash := Hash([]byte("key"))
+	assert.Equal(t, hash("alice@example.com"), hash("alice@example.com"))
+	assert.NotEqual(t, hash("alice@example.com"), hash("bob@example.com"))
+	assert.NotEqual(t, hash("alice@example.com"), Hash([]byte("other"))("alice@example.com"))
+	assert.Regexp(t, `^pii:[0-9a-f]{12}$`, hash("alice@example.com"))
+}
+
+func TestRedact(t *testing.T) {
+	original := FromStatus(status.New(codes.AlreadyExists, "User alice.liddell with email alice@example.com already exists"))
+	err := Redact(original, Mask, "alice.liddell", "ab")
+
+	assert.Equal(t, "AlreadyExists: User a*** with email a***@e***.com already exists", err.Error())
+	assert.ErrorIs(t, err, ErrAlreadyExists)
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+	var zErr *Error
+	require.ErrorAs(t, err, &zErr)
+	assert.Equal(t, "User alice.liddell with email alice@example.com already exists", zErr.Message)
+
+	assert.NoError(t, Redact(nil, Mask))
+}
+
+func TestRedactingUnaryClientInterceptor(t *testing.T) {
+	req := &user.AddHumanUserRequest{
+		Username: ptr("aliceliddell"),
+		Profile:  &user.SetHumanProfile{GivenName: "Alice", FamilyName: "Liddell"},
+		Email:    &user.SetHumanEmail{Email: "alice@example.com"},
+	}
+	invoker := func(context.Context, string, any, any, *grpc.ClientConn, ...grpc.CallOption) error {
+		return status.Error(codes.InvalidArgument, "username aliceliddell of Alice Liddell is invalid")
+	}
+
+	err := RedactingUnaryClientInterceptor(Mask)(context.Background(), "/method", req, nil, nil, invoker)
+	assert.Equal(t, "rpc error: code = InvalidArgument desc = username a*** of A*** L*** is invalid", err.Error())
+
+	// combined with the structured errors, the original error is converted to an [Error] keeping the redacted message
+	err = Wrap(err)
+	assert.Equal(t, "rpc error: code = InvalidArgument desc = username a*** of A*** L*** is invalid", err.Error())
+	var redacted *RedactedError
+	require.ErrorAs(t, err, &redacted)
+	var zErr *Error
+	require.ErrorAs(t, err, &zErr)
+	assert.Equal(t, "username aliceliddell of Alice Liddell is invalid", zErr.Message)
+	assert.ErrorIs(t, err, ErrInvalidArgument)
+
+	err = RedactingUnaryClientInterceptor(Mask)(context.Background(), "/method", req, nil, nil,
+		func(context.Context, string, any, any, *grpc.ClientConn, ...grpc.CallOption) error { return nil })
+	assert.NoError(t, err)
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
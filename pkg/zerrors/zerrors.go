@@ -0,0 +1,179 @@
+// Package zerrors provides structured errors for the gRPC status errors returned by ZITADEL.
+//
+// An [Error] exposes the status code, the (localized) message and the error details of ZITADEL, such as its error ID.
+// It matches the typed errors using errors.Is, regardless of the message:
+//
+//	_, err := c.UserServiceV2().GetUserByID(ctx, req)
+//	if errors.Is(err, zerrors.ErrNotFound) {
+//		...
+//	}
+//	var zErr *zerrors.Error
+//	if errors.As(err, &zErr) {
+//		log.Println(zErr.ID, zErr.Message)
+//	}
+//
+// The errors of all calls of a client are converted by the interceptors (see [client.WithStructuredErrors]),
+// other errors can be converted using [FromError]. The [Error] still carries the gRPC status,
+// so existing checks such as status.Code(err) keep working.
+//
+// Error messages may contain personally identifiable information (PII), such as the email or login name of a user.
+// For logging compliance, [Redact] replaces them by a [Redaction] (e.g. [Mask] or [Hash]) in the message,
+// while the original error stays available through errors.As (see [client.WithRedactedErrors]).
+package zerrors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/message"
+)
+
+var (
+	ErrNotFound           = errors.New("not found")
+	ErrAlreadyExists      = errors.New("already exists")
+	ErrPermissionDenied   = errors.New("permission denied")
+	ErrUnauthenticated    = errors.New("unauthenticated")
+	ErrInvalidArgument    = errors.New("invalid argument")
+	ErrPreconditionFailed = errors.New("precondition failed")
+	ErrRateLimited        = errors.New("rate limited")
+	ErrUnavailable        = errors.New("unavailable")
+	ErrDeadlineExceeded   = errors.New("deadline exceeded")
+	ErrUnimplemented      = errors.New("unimplemented")
+	ErrInternal           = errors.New("internal error")
+)
+
+// sentinels maps the status codes to the typed errors.
+var sentinels = map[codes.Code]error{
+	codes.NotFound:           ErrNotFound,
+	codes.AlreadyExists:      ErrAlreadyExists,
+	codes.PermissionDenied:   ErrPermissionDenied,
+	codes.Unauthenticated:    ErrUnauthenticated,
+	codes.InvalidArgument:    ErrInvalidArgument,
+	codes.FailedPrecondition: ErrPreconditionFailed,
+	codes.ResourceExhausted:  ErrRateLimited,
+	codes.Unavailable:        ErrUnavailable,
+	codes.DeadlineExceeded:   ErrDeadlineExceeded,
+	codes.Unimplemented:      ErrUnimplemented,
+	codes.Internal:           ErrInternal,
+}
+
+// Error is a status error of ZITADEL.
+type Error struct {
+	Code codes.Code
+	// Message is the message of the status, it's localized by ZITADEL (see the Accept-Language header).
+	Message string
+	// ID identifies the error in ZITADEL, e.g. `COMMAND-2M9fs`, empty if not returned.
+	ID string
+	// DetailMessage is the message of the error detail, usually the untranslated i18n key, e.g. `Errors.User.NotFound`.
+	DetailMessage string
+	// LocalizedKey and LocalizedMessage are set if ZITADEL returned a localized message as error detail.
+	LocalizedKey     string
+	LocalizedMessage string
+
+	status *status.Status
+}
+
+// FromError converts a gRPC status error into an [Error], nil is returned for nil and non status errors.
+// An [Error] is returned as is.
+func FromError(err error) *Error {
+	if err == nil {
+		return nil
+	}
+	var zErr *Error
+	if errors.As(err, &zErr) {
+		return zErr
+	}
+	// the status is taken from the wrapped error itself, since status.FromError prefixes the message with the wrapping ones
+	var grpcErr interface{ GRPCStatus() *status.Status }
+	if !errors.As(err, &grpcErr) {
+		return nil
+	}
+	return FromStatus(grpcErr.GRPCStatus())
+}
+
+// FromStatus creates the [Error] of the status, including its error details.
+func FromStatus(s *status.Status) *Error {
+	e := &Error{Code: s.Code(), Message: s.Message(), status: s}
+	for _, detail := range s.Details() {
+		switch d := detail.(type) {
+		case *message.ErrorDetail:
+			e.ID = d.GetId()
+			e.DetailMessage = d.GetMessage()
+		case *message.LocalizedMessage:
+			e.LocalizedKey = d.GetKey()
+			e.LocalizedMessage = d.GetLocalizedMessage()
+		}
+	}
+	return e
+}
+
+// Wrap converts a gRPC status error into an [Error], other errors (including nil) are returned as is.
+// Errors of the context are kept, so errors.Is(err, context.Canceled) keeps working.
+// The original error of a [RedactedError] is converted, keeping the redacted message.
+func Wrap(err error) error {
+	if redacted, ok := err.(*RedactedError); ok {
+		return &RedactedError{Err: Wrap(redacted.Err), message: redacted.message}
+	}
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	if zErr := FromError(err); zErr != nil && zErr.Code != codes.OK {
+		return zErr
+	}
+	return err
+}
+
+func (e *Error) Error() string {
+	if e.ID != "" {
+		return fmt.Sprintf("%s: %s (%s)", e.Code, e.Message, e.ID)
+	}
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+// Is allows to check for the typed errors (e.g. [ErrNotFound]) using errors.Is.
+func (e *Error) Is(target error) bool {
+	return sentinels[e.Code] == target
+}
+
+// GRPCStatus returns the original status, so the [Error] can be used with the status package.
+func (e *Error) GRPCStatus() *status.Status {
+	if e.status == nil {
+		return status.New(e.Code, e.Message)
+	}
+	return e.status
+}
+
+// UnaryClientInterceptor converts the errors of the calls using [Wrap].
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return Wrap(invoker(ctx, method, req, reply, cc, opts...))
+	}
+}
+
+// StreamClientInterceptor converts the errors of the stream creation and its messages using [Wrap].
+func StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			return nil, Wrap(err)
+		}
+		return &clientStream{ClientStream: stream}, nil
+	}
+}
+
+type clientStream struct {
+	grpc.ClientStream
+}
+
+func (s *clientStream) SendMsg(m any) error {
+	return Wrap(s.ClientStream.SendMsg(m))
+}
+
+func (s *clientStream) RecvMsg(m any) error {
+	return Wrap(s.ClientStream.RecvMsg(m))
+}
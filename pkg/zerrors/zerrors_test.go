@@ -0,0 +1,81 @@
+package zerrors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/zitadel/zitadel-go/v3/pkg/client/zitadel/message"
+)
+
+func TestFromError(t *testing.T) {
+	s, err := status.New(codes.NotFound, "User could not be found").WithDetails(
+		&message.ErrorDetail{Id: "QUERY-Dfbg2", Message: "Errors.User.NotFound"},
+		&message.LocalizedMessage{Key: "Errors.User.NotFound", LocalizedMessage: "User could not be found"},
+	)
+	require.NoError(t, err)
+
+	zErr := FromError(fmt.Errorf("get user: %w", s.Err()))
+	require.NotNil(t, zErr)
+	assert.Equal(t, codes.NotFound, zErr.Code)
+	assert.Equal(t, "QUERY-Dfbg2", zErr.ID)
+	assert.Equal(t, "Errors.User.NotFound", zErr.DetailMessage)
+	assert.Equal(t, "User could not be found", zErr.LocalizedMessage)
+	assert.Equal(t, "NotFound: User could not be found (QUERY-Dfbg2)", zErr.Error())
+	assert.ErrorIs(t, zErr, ErrNotFound)
+	assert.NotErrorIs(t, zErr, ErrAlreadyExists)
+	assert.Equal(t, codes.NotFound, status.Code(zErr))
+
+	assert.Nil(t, FromError(nil))
+	assert.Nil(t, FromError(io.EOF))
+}
+
+func TestWrap(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantIs   error
+		wantType bool
+	}{
+		{
+			name: "nil",
+		},
+		{
+			name:   "no status",
+			err:    io.EOF,
+			wantIs: io.EOF,
+		},
+		{
+			name:   "context",
+			err:    context.Canceled,
+			wantIs: context.Canceled,
+		},
+		{
+			name:     "rate limited",
+			err:      status.Error(codes.ResourceExhausted, "quota exceeded"),
+			wantIs:   ErrRateLimited,
+			wantType: true,
+		},
+		{
+			name:     "permission denied",
+			err:      status.Error(codes.PermissionDenied, "no permission"),
+			wantIs:   ErrPermissionDenied,
+			wantType: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Wrap(tt.err)
+			assert.ErrorIs(t, err, tt.wantIs)
+			var zErr *Error
+			assert.Equal(t, tt.wantType, errors.As(err, &zErr))
+		})
+	}
+}